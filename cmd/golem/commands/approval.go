@@ -140,6 +140,11 @@ func runApprovalList(cmd *cobra.Command, args []string) error {
 			reasonStyleBase.Render(truncate(reason, wReason)),
 		)
 		fmt.Printf("  %s\n", row)
+		if req.Preview != "" {
+			for _, line := range strings.Split(req.Preview, "\n") {
+				fmt.Printf("      %s\n", line)
+			}
+		}
 	}
 
 	return nil
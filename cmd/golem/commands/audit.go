@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/audit"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// auditNow 返回当前时间，测试中可替换以固定 --since 的相对时间。
+var auditNow = time.Now
+
+// NewAuditCmd 创建审计日志查询命令。
+func NewAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Query and export the runtime audit log",
+	}
+
+	cmd.AddCommand(
+		newAuditListCmd(),
+		newAuditExportCmd(),
+	)
+
+	return cmd
+}
+
+func newAuditListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List audit events",
+		RunE:  runAuditList,
+	}
+	addAuditFilterFlags(cmd)
+	cmd.Flags().Bool("json", false, "Output events as JSON")
+	return cmd
+}
+
+func newAuditExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export audit events as CSV",
+		RunE:  runAuditExport,
+	}
+	addAuditFilterFlags(cmd)
+	cmd.Flags().StringP("output", "o", "", "Write CSV to this file instead of stdout")
+	return cmd
+}
+
+func addAuditFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("since", "", "Only events at or after this time (duration like 24h or 7d, date 2006-01-02, or RFC3339)")
+	cmd.Flags().String("type", "", "Only events of this type (e.g. policy_deny, tool_execution)")
+	cmd.Flags().String("tool", "", "Only events for this tool")
+}
+
+func runAuditList(cmd *cobra.Command, args []string) error {
+	events, err := loadAuditEvents(cmd)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		if events == nil {
+			events = []audit.Event{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	}
+
+	if len(events) == 0 {
+		fmt.Println("No audit events match. Events are recorded as the agent evaluates policy and runs tools.")
+		return nil
+	}
+
+	var (
+		wTime    = 20
+		wType    = 22
+		wTool    = 16
+		wRequest = 14
+
+		colHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#8E4EC6")). // Purple
+				Bold(true).
+				MarginRight(1)
+		cellStyle = func(width int) lipgloss.Style {
+			return lipgloss.NewStyle().Width(width).MarginRight(1)
+		}
+	)
+
+	headers := lipgloss.JoinHorizontal(lipgloss.Top,
+		colHeaderStyle.Width(wTime).Render("TIME"),
+		colHeaderStyle.Width(wType).Render("TYPE"),
+		colHeaderStyle.Width(wTool).Render("TOOL"),
+		colHeaderStyle.Width(wRequest).Render("REQUEST"),
+		colHeaderStyle.Render("RESULT"),
+	)
+	fmt.Printf("  %s\n", headers)
+
+	for _, event := range events {
+		row := lipgloss.JoinHorizontal(lipgloss.Top,
+			cellStyle(wTime).Render(event.Time.UTC().Format("2006-01-02 15:04:05")),
+			cellStyle(wType).Render(truncate(event.Type, wType)),
+			cellStyle(wTool).Render(truncate(orDash(event.Tool), wTool)),
+			cellStyle(wRequest).Render(truncate(orDash(event.RequestID), wRequest)),
+			lipgloss.NewStyle().Render(orDash(event.Result)),
+		)
+		fmt.Printf("  %s\n", row)
+	}
+	fmt.Printf("\n  %d event(s)\n", len(events))
+	return nil
+}
+
+func runAuditExport(cmd *cobra.Command, args []string) error {
+	events, err := loadAuditEvents(cmd)
+	if err != nil {
+		return err
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return writeAuditCSV(os.Stdout, events)
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create export file: %w", err)
+	}
+	if err := writeAuditCSV(file, events); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d audit event(s) to %s\n", len(events), output)
+	return nil
+}
+
+// writeAuditCSV 以 CSV 格式写出审计事件，首行为表头。
+func writeAuditCSV(w io.Writer, events []audit.Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"time", "type", "request_id", "tool", "result"}); err != nil {
+		return err
+	}
+	for _, event := range events {
+		record := []string{
+			event.Time.UTC().Format(time.RFC3339),
+			event.Type,
+			event.RequestID,
+			event.Tool,
+			event.Result,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func loadAuditEvents(cmd *cobra.Command) ([]audit.Event, error) {
+	sinceRaw, _ := cmd.Flags().GetString("since")
+	eventType, _ := cmd.Flags().GetString("type")
+	toolName, _ := cmd.Flags().GetString("tool")
+
+	since, err := parseAuditSince(sinceRaw, auditNow())
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	workspacePath, err := cfg.WorkspacePathChecked()
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace: %w", err)
+	}
+	return audit.Read(workspacePath, audit.Query{
+		Since: since,
+		Type:  eventType,
+		Tool:  toolName,
+	})
+}
+
+// parseAuditSince 解析 --since：支持相对时长（24h、30m、7d）、日期（2006-01-02）与 RFC3339 时间。
+func parseAuditSince(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * 24 * time.Hour), nil
+		}
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("--since duration must not be negative")
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", raw, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration (24h, 7d), a date (2006-01-02) or an RFC3339 time", raw)
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
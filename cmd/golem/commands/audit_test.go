@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/audit"
+)
+
+func prepareAuditWorkspace(t *testing.T) string {
+	t.Helper()
+	workspacePath := prepareApprovalWorkspace(t)
+	writer := audit.NewWriter(workspacePath)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, event := range []audit.Event{
+		{Time: base, Type: "policy_allow", Tool: "exec", RequestID: "req-1", Result: "mode=strict"},
+		{Time: base.Add(time.Hour), Type: "tool_execution", Tool: "exec", RequestID: "req-1", Result: "success"},
+		{Time: base.Add(2 * time.Hour), Type: "policy_deny", Tool: "write_file", RequestID: "req-2", Result: "blocked, by policy"},
+	} {
+		if err := writer.Append(event); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	orig := auditNow
+	auditNow = func() time.Time { return base.Add(3 * time.Hour) }
+	t.Cleanup(func() { auditNow = orig })
+	return workspacePath
+}
+
+func TestAuditList_FiltersAndPrintsJSON(t *testing.T) {
+	prepareAuditWorkspace(t)
+
+	cmd := newAuditListCmd()
+	_ = cmd.Flags().Set("since", "2h30m")
+	_ = cmd.Flags().Set("tool", "exec")
+	_ = cmd.Flags().Set("json", "true")
+	output := captureOutput(t, func() {
+		if err := runAuditList(cmd, nil); err != nil {
+			t.Fatalf("runAuditList: %v", err)
+		}
+	})
+
+	var events []audit.Event
+	if err := json.Unmarshal([]byte(output), &events); err != nil {
+		t.Fatalf("unmarshal output: %v (%s)", err, output)
+	}
+	if len(events) != 1 || events[0].Type != "tool_execution" {
+		t.Fatalf("expected only the recent exec event, got %+v", events)
+	}
+}
+
+func TestAuditList_TableOutput(t *testing.T) {
+	prepareAuditWorkspace(t)
+
+	cmd := newAuditListCmd()
+	_ = cmd.Flags().Set("type", "policy_deny")
+	output := captureOutput(t, func() {
+		if err := runAuditList(cmd, nil); err != nil {
+			t.Fatalf("runAuditList: %v", err)
+		}
+	})
+	if !strings.Contains(output, "write_file") || strings.Contains(output, "tool_execution") {
+		t.Fatalf("expected only policy_deny rows, got: %s", output)
+	}
+	if !strings.Contains(output, "1 event(s)") {
+		t.Fatalf("expected event count, got: %s", output)
+	}
+}
+
+func TestAuditExport_WritesCSV(t *testing.T) {
+	prepareAuditWorkspace(t)
+
+	out := filepath.Join(t.TempDir(), "audit.csv")
+	cmd := newAuditExportCmd()
+	_ = cmd.Flags().Set("output", out)
+	captureOutput(t, func() {
+		if err := runAuditExport(cmd, nil); err != nil {
+			t.Fatalf("runAuditExport: %v", err)
+		}
+	})
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatalf("open export: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected header + 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != "time,type,request_id,tool,result" {
+		t.Fatalf("unexpected header: %v", records[0])
+	}
+	if records[3][1] != "policy_deny" || records[3][4] != "blocked, by policy" {
+		t.Fatalf("unexpected last row: %v", records[3])
+	}
+}
+
+func TestParseAuditSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"":                     {},
+		"24h":                  now.Add(-24 * time.Hour),
+		"7d":                   now.Add(-7 * 24 * time.Hour),
+		"2026-03-01T00:00:00Z": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for raw, want := range cases {
+		got, err := parseAuditSince(raw, now)
+		if err != nil {
+			t.Fatalf("parseAuditSince(%q): %v", raw, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseAuditSince(%q) = %v, want %v", raw, got, want)
+		}
+	}
+	if _, err := parseAuditSince("yesterday", now); err == nil {
+		t.Fatal("expected error for invalid --since")
+	}
+	if _, err := parseAuditSince("-1h", now); err == nil {
+		t.Fatal("expected error for negative duration")
+	}
+}
@@ -37,6 +37,7 @@ func newAuthLoginCmd() *cobra.Command {
 	var token string
 	var browser bool
 	var deviceCode bool
+	var fixedPort bool
 
 	cmd := &cobra.Command{
 		Use:   "login",
@@ -56,6 +57,7 @@ func newAuthLoginCmd() *cobra.Command {
 				if err != nil {
 					return err
 				}
+				cfg.FixedPort = cfg.FixedPort || fixedPort
 				if browser {
 					cred, err = authLoginBrowser(cfg)
 				} else {
@@ -97,6 +99,7 @@ func newAuthLoginCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&token, "token", "t", "", "Access token to save")
 	cmd.Flags().BoolVar(&browser, "browser", false, "Use OAuth browser flow")
 	cmd.Flags().BoolVar(&deviceCode, "device-code", false, "Use OAuth device-code flow")
+	cmd.Flags().BoolVar(&fixedPort, "fixed-port", false, "Fail instead of using another callback port when the provider's default port is busy (browser flow)")
 	_ = cmd.MarkFlagRequired("provider")
 
 	return cmd
@@ -159,6 +162,11 @@ func newAuthStatusCmd() *cobra.Command {
 				fmt.Println("No authenticated providers. Use 'golem auth login --provider <name>' to authenticate.")
 				return nil
 			}
+			if store.Encrypted {
+				fmt.Println("  Storage: encrypted (AES-256-GCM)")
+			} else {
+				fmt.Printf("  Storage: plaintext (set %s to encrypt on the next save)\n", auth.KeyEnv)
+			}
 
 			providers := make([]string, 0, len(store.Credentials))
 			for provider := range store.Credentials {
@@ -266,6 +274,8 @@ func oauthConfigForProvider(provider string) (auth.OAuthProviderConfig, error) {
 	switch provider {
 	case "openai":
 		return auth.OpenAIOAuthConfig(), nil
+	case "claude":
+		return auth.AnthropicOAuthConfig(), nil
 	default:
 		return auth.OAuthProviderConfig{}, fmt.Errorf("oauth is not supported for provider: %s", provider)
 	}
@@ -136,3 +136,63 @@ func TestAuthLoginBrowser(t *testing.T) {
 		t.Fatalf("expected browser credential saved, got %+v", cred)
 	}
 }
+
+func TestAuthLoginBrowser_Claude(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	orig := authLoginBrowser
+	var gotProvider string
+	authLoginBrowser = func(cfg auth.OAuthProviderConfig) (*auth.Credential, error) {
+		gotProvider = cfg.Provider
+		return &auth.Credential{
+			AccessToken:  "claude-oauth-token",
+			RefreshToken: "claude-refresh",
+			Provider:     "claude",
+			AuthMethod:   "oauth",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil
+	}
+	defer func() { authLoginBrowser = orig }()
+
+	cmd := NewAuthCmd()
+	cmd.SetArgs([]string{"login", "--provider", "anthropic", "--browser"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("auth login --provider anthropic --browser execute: %v", err)
+	}
+	if gotProvider != "claude" {
+		t.Fatalf("expected claude oauth config, got %q", gotProvider)
+	}
+
+	cred, err := auth.GetCredential("claude")
+	if err != nil {
+		t.Fatalf("auth.GetCredential: %v", err)
+	}
+	if cred == nil || cred.AccessToken != "claude-oauth-token" || cred.RefreshToken != "claude-refresh" {
+		t.Fatalf("expected claude oauth credential saved, got %+v", cred)
+	}
+}
+
+func TestAuthLoginBrowser_FixedPortFlag(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	orig := authLoginBrowser
+	var fixed bool
+	authLoginBrowser = func(cfg auth.OAuthProviderConfig) (*auth.Credential, error) {
+		fixed = cfg.FixedPort
+		return &auth.Credential{AccessToken: "oauth-browser-token", AuthMethod: "oauth"}, nil
+	}
+	defer func() { authLoginBrowser = orig }()
+
+	cmd := NewAuthCmd()
+	cmd.SetArgs([]string{"login", "--provider", "openai", "--browser", "--fixed-port"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("auth login --fixed-port execute: %v", err)
+	}
+	if !fixed {
+		t.Fatal("expected --fixed-port to force the configured callback port")
+	}
+}
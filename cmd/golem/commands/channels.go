@@ -186,6 +186,12 @@ func runChannelsSetEnabled(channelName string, enabled bool) error {
 		cfg.Channels.DingTalk.Enabled = enabled
 	case "maixcam":
 		cfg.Channels.MaixCam.Enabled = enabled
+	case "mattermost":
+		cfg.Channels.Mattermost.Enabled = enabled
+	case "matrix":
+		cfg.Channels.Matrix.Enabled = enabled
+	case "twilio":
+		cfg.Channels.Twilio.Enabled = enabled
 	default:
 		return fmt.Errorf("unknown channel: %s", channelName)
 	}
@@ -285,6 +291,28 @@ func channelStates(cfg *config.Config) []channelState {
 			Reason:    "host/port not set",
 			AllowFrom: cfg.Channels.MaixCam.AllowFrom,
 		},
+		{
+			Name:      "mattermost",
+			Enabled:   cfg.Channels.Mattermost.Enabled,
+			Ready:     strings.TrimSpace(cfg.Channels.Mattermost.URL) != "" && strings.TrimSpace(cfg.Channels.Mattermost.Token) != "",
+			Reason:    "url/token not set",
+			AllowFrom: cfg.Channels.Mattermost.AllowFrom,
+		},
+		{
+			Name:      "matrix",
+			Enabled:   cfg.Channels.Matrix.Enabled,
+			Ready:     strings.TrimSpace(cfg.Channels.Matrix.Homeserver) != "" && strings.TrimSpace(cfg.Channels.Matrix.AccessToken) != "",
+			Reason:    "homeserver/access_token not set",
+			AllowFrom: cfg.Channels.Matrix.AllowFrom,
+		},
+		{
+			Name:    "twilio",
+			Enabled: cfg.Channels.Twilio.Enabled,
+			Ready: strings.TrimSpace(cfg.Channels.Twilio.AccountSID) != "" && strings.TrimSpace(cfg.Channels.Twilio.AuthToken) != "" &&
+				strings.TrimSpace(cfg.Channels.Twilio.FromNumber) != "" && cfg.Channels.Twilio.Port > 0,
+			Reason:    "account_sid/auth_token/from_number/port not set",
+			AllowFrom: cfg.Channels.Twilio.AllowFrom,
+		},
 	}
 }
 
@@ -42,6 +42,9 @@ func TestChannelsSetEnabled_AllSupportedChannels(t *testing.T) {
 		"qq",
 		"dingtalk",
 		"maixcam",
+		"mattermost",
+		"matrix",
+		"twilio",
 	}
 
 	for _, name := range channels {
@@ -65,7 +68,7 @@ func TestChannelsList_ContainsAllSupportedChannels(t *testing.T) {
 		}
 	})
 
-	for _, name := range []string{"telegram", "whatsapp", "feishu", "discord", "slack", "qq", "dingtalk", "maixcam"} {
+	for _, name := range []string{"telegram", "whatsapp", "feishu", "discord", "slack", "qq", "dingtalk", "maixcam", "mattermost", "matrix", "twilio"} {
 		if !strings.Contains(out, name) {
 			t.Fatalf("expected channel %q in output, got: %s", name, out)
 		}
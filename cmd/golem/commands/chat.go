@@ -11,6 +11,7 @@ import (
 	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/provider"
 	"github.com/MEKXH/golem/internal/render"
+	"github.com/MEKXH/golem/internal/tools"
 	"github.com/MEKXH/golem/internal/version"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
@@ -53,6 +54,9 @@ var (
 			Foreground(lipgloss.Color("#888888")).
 			Italic(true)
 
+	plannedToolStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#D4A017")) // 琥珀色，区分未执行的计划调用
+
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241")).
 			Padding(0, 1)
@@ -80,11 +84,13 @@ const golemArt = `
 
 // NewChatCmd 创建交互式聊天命令。
 func NewChatCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "chat [message]",
 		Short: "Chat with Golem",
 		RunE:  runChat,
 	}
+	cmd.Flags().Bool("plan", false, "Plan mode: show proposed tool calls without executing them")
+	return cmd
 }
 
 type (
@@ -117,9 +123,24 @@ func renderResponseParts(content string, r markdownRenderer) (string, string, bo
 
 // 结构化历史记录的数据结构
 type ToolLog struct {
-	Name   string
-	Result string
-	Err    error
+	Name    string
+	Args    string
+	Result  string
+	Err     error
+	Outcome tools.Outcome // 执行结果分类，为空时按 Err 推断
+	Planned bool          // 计划模式下仅提出、未执行的工具调用
+}
+
+// failedToolIcon 返回失败工具调用的图标，区分超时与策略拒绝。
+func failedToolIcon(outcome tools.Outcome) string {
+	switch outcome {
+	case tools.OutcomeTimeout:
+		return "⏱"
+	case tools.OutcomeDenied:
+		return "⊘"
+	default:
+		return "✖"
+	}
 }
 
 type ChatMessage struct {
@@ -159,6 +180,7 @@ type model struct {
 	err  error
 
 	currentTool string // 追踪当前正在运行的工具
+	streamed    string // 当前轮次已流式接收的原始文本
 
 	width int // 窗口宽度，用于重新渲染
 }
@@ -238,15 +260,25 @@ func (m model) Init() tea.Cmd {
 
 type responseMsg string
 
+// contentDeltaMsg 携带模型流式输出的增量文本
+type contentDeltaMsg string
+
 type toolStartMsg struct {
 	name string
 	args string
 }
 
 type toolFinishMsg struct {
-	name   string
-	result string
-	err    error
+	name    string
+	result  string
+	outcome tools.Outcome
+	err     error
+}
+
+// toolPlannedMsg 表示计划模式下模型提出但未执行的工具调用
+type toolPlannedMsg struct {
+	name string
+	args string
 }
 
 // renderAll 根据当前宽度重新渲染整个聊天历史
@@ -315,13 +347,28 @@ func (m model) renderMessage(msg *ChatMessage) string {
 		if len(msg.Tools) > 0 {
 			contentBuilder.WriteString("\n")
 			for _, t := range msg.Tools {
+				if t.Planned {
+					contentBuilder.WriteString(plannedToolStyle.Render(fmt.Sprintf("◇ %s (planned)", t.Name)))
+					contentBuilder.WriteString(plannedToolStyle.Italic(true).Render(" " + truncate(t.Args, 200)))
+					contentBuilder.WriteString("\n")
+					continue
+				}
+
 				contentBuilder.WriteString(toolLogStyle.Render(fmt.Sprintf("➢ %s", t.Name)))
 
-				if t.Err != nil {
+				outcome := t.Outcome
+				if outcome == "" {
+					outcome = tools.Classify(tools.Result{}, t.Err)
+				}
+				switch {
+				case outcome == tools.OutcomePendingApproval:
+					contentBuilder.WriteString(plannedToolStyle.Render(" ⏸ "))
+					contentBuilder.WriteString(plannedToolStyle.Italic(true).Render(truncate(t.Result, 200)))
+				case outcome.Failed():
 					errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-					contentBuilder.WriteString(errStyle.Render(" ✖ "))
+					contentBuilder.WriteString(errStyle.Render(" " + failedToolIcon(outcome) + " "))
 					contentBuilder.WriteString(errStyle.Italic(true).Render(fmt.Sprintf("%v", t.Err)))
-				} else {
+				default:
 					res := t.Result
 					res = truncate(res, 200)
 					successStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#2E8B57"))
@@ -490,6 +537,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case responseMsg:
 		m.currentTool = ""
+		m.streamed = ""
 		if m.thinking {
 			m.viewport.Height += 1
 		}
@@ -511,11 +559,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.SetContent(m.renderAll())
 		m.viewport.GotoBottom()
 
+	case contentDeltaMsg:
+		if m.currentHelper == nil {
+			m.currentHelper = &ChatMessage{Role: "golem"}
+		}
+		m.streamed += string(msg)
+		// 流式阶段仅展示原始文本，完整回复到达后再进行 Markdown 渲染
+		think, main, hasThink := render.SplitThink(m.streamed)
+		if hasThink {
+			m.currentHelper.Thinking = think
+		}
+		m.currentHelper.Content = main
+		m.viewport.SetContent(m.renderAll())
+		m.viewport.GotoBottom()
+
 	case toolStartMsg:
 		m.currentTool = msg.name
 		if m.currentHelper == nil {
 			m.currentHelper = &ChatMessage{Role: "golem"}
 		}
+		// 工具调用前的中间文本不属于最终回复，开始新一轮流式输出
+		m.streamed = ""
+		m.currentHelper.Content = ""
 		m.viewport.SetContent(m.renderAll())
 		m.viewport.GotoBottom()
 
@@ -525,15 +590,30 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.currentHelper = &ChatMessage{Role: "golem"}
 		}
 		m.currentHelper.Tools = append(m.currentHelper.Tools, ToolLog{
-			Name:   msg.name,
-			Result: msg.result,
-			Err:    msg.err,
+			Name:    msg.name,
+			Result:  msg.result,
+			Err:     msg.err,
+			Outcome: msg.outcome,
+		})
+
+		m.viewport.SetContent(m.renderAll())
+		m.viewport.GotoBottom()
+
+	case toolPlannedMsg:
+		if m.currentHelper == nil {
+			m.currentHelper = &ChatMessage{Role: "golem"}
+		}
+		m.currentHelper.Tools = append(m.currentHelper.Tools, ToolLog{
+			Name:    msg.name,
+			Args:    msg.args,
+			Planned: true,
 		})
 
 		m.viewport.SetContent(m.renderAll())
 		m.viewport.GotoBottom()
 
 	case errMsg:
+		m.streamed = ""
 		if m.thinking {
 			m.viewport.Height += 1
 		}
@@ -644,11 +724,19 @@ func runChat(cmd *cobra.Command, args []string) error {
 		modelProvider = nil
 	}
 
+	channelModels, err := provider.NewChannelChatModels(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("invalid channel model override: %w", err)
+	}
+
 	msgBus := bus.NewMessageBus(10)
 	loop, err := agent.NewLoop(cfg, msgBus, modelProvider)
 	if err != nil {
 		return fmt.Errorf("invalid workspace: %w", err)
 	}
+	loop.SetChannelModels(channelModels)
+	loop.SetModelFactory(sessionModelFactory(cfg))
+	configureMemoryEmbedder(ctx, cfg, loop)
 	if err := loop.RegisterDefaultTools(cfg); err != nil {
 		return fmt.Errorf("failed to register tools: %w", err)
 	}
@@ -661,6 +749,10 @@ func runChat(cmd *cobra.Command, args []string) error {
 	loop.SetRuntimeMetrics(runtimeMetrics)
 	logAndAuditRuntimePolicyStartup(ctx, loop, cfg)
 
+	if cmd != nil {
+		loop.PlanOnly, _ = cmd.Flags().GetBool("plan")
+	}
+
 	if len(args) > 0 {
 		message := strings.Join(args, " ")
 		resp, err := loop.ProcessDirect(ctx, message)
@@ -677,8 +769,14 @@ func runChat(cmd *cobra.Command, args []string) error {
 	loop.OnToolStart = func(name, args string) {
 		p.Send(toolStartMsg{name: name, args: args})
 	}
-	loop.OnToolFinish = func(name, result string, err error) {
-		p.Send(toolFinishMsg{name: name, result: result, err: err})
+	loop.OnToolFinish = func(name, result string, outcome tools.Outcome, err error) {
+		p.Send(toolFinishMsg{name: name, result: result, outcome: outcome, err: err})
+	}
+	loop.OnContentDelta = func(delta string) {
+		p.Send(contentDeltaMsg(delta))
+	}
+	loop.OnToolPlanned = func(name, args string) {
+		p.Send(toolPlannedMsg{name: name, args: args})
 	}
 
 	if _, err := p.Run(); err != nil {
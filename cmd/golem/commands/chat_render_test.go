@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/MEKXH/golem/internal/tools"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -114,6 +115,30 @@ func TestRenderMessage_ToolIcons(t *testing.T) {
 	}
 }
 
+func TestRenderMessage_ToolOutcomeIcons(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	m := model{width: 100}
+	msg := &ChatMessage{
+		Role:    "golem",
+		Content: "test",
+		Tools: []ToolLog{
+			{Name: "approval_tool", Result: "pending approval: #42", Outcome: tools.OutcomePendingApproval},
+			{Name: "slow_tool", Err: fmt.Errorf("context deadline exceeded"), Outcome: tools.OutcomeTimeout},
+			{Name: "blocked_tool", Err: fmt.Errorf("tool execution denied: blocked"), Outcome: tools.OutcomeDenied},
+		},
+	}
+
+	output := m.renderMessage(msg)
+	for _, want := range []string{"⏸", "pending approval: #42", "⏱", "⊘"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Contains(output, "✔") || strings.Contains(output, "✖") {
+		t.Error("expected classified outcomes not to use the generic success or failure icons")
+	}
+}
+
 func TestRenderMessage_ErrorState(t *testing.T) {
 	lipgloss.SetColorProfile(termenv.TrueColor)
 	m := model{width: 100}
@@ -133,3 +158,53 @@ func TestRenderMessage_ErrorState(t *testing.T) {
 		t.Error("expected output not to contain GOLEM label for error messages")
 	}
 }
+
+func TestUpdate_ContentDeltaStreamsIntoCurrentMessage(t *testing.T) {
+	m := model{
+		textarea:      textarea.New(),
+		viewport:      viewport.New(40, 10),
+		spinner:       spinner.New(),
+		thinking:      true,
+		width:         60,
+		currentHelper: &ChatMessage{Role: "golem"},
+	}
+
+	updated, _ := m.Update(contentDeltaMsg("Hello "))
+	updated, _ = updated.(model).Update(contentDeltaMsg("world"))
+	got := updated.(model)
+	if got.currentHelper == nil || got.currentHelper.Content != "Hello world" {
+		t.Fatalf("expected streamed content, got %+v", got.currentHelper)
+	}
+
+	updated, _ = got.Update(toolStartMsg{name: "read_file"})
+	got = updated.(model)
+	if got.currentHelper.Content != "" || got.streamed != "" {
+		t.Fatalf("expected tool start to reset streamed draft, got %q", got.currentHelper.Content)
+	}
+}
+
+func TestUpdate_ToolPlannedRendersAsPlannedEntry(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	m := model{
+		textarea:      textarea.New(),
+		viewport:      viewport.New(40, 10),
+		spinner:       spinner.New(),
+		thinking:      true,
+		width:         100,
+		currentHelper: &ChatMessage{Role: "golem"},
+	}
+
+	updated, _ := m.Update(toolPlannedMsg{name: "write_file", args: `{"path":"a.txt"}`})
+	got := updated.(model)
+	if len(got.currentHelper.Tools) != 1 || !got.currentHelper.Tools[0].Planned {
+		t.Fatalf("expected planned tool log entry, got %+v", got.currentHelper.Tools)
+	}
+
+	output := got.renderMessage(got.currentHelper)
+	if !strings.Contains(output, "write_file (planned)") || !strings.Contains(output, `{"path":"a.txt"}`) {
+		t.Fatalf("expected planned tool rendering, got %q", output)
+	}
+	if strings.Contains(output, "✔") || strings.Contains(output, "✖") {
+		t.Fatalf("expected planned tool not to render execution status, got %q", output)
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ func NewCronCmd() *cobra.Command {
 	cmd.AddCommand(
 		newCronListCmd(),
 		newCronAddCmd(),
+		newCronEditCmd(),
 		newCronRunCmd(),
 		newCronRemoveCmd(),
 		newCronEnableCmd(),
@@ -36,11 +38,13 @@ func NewCronCmd() *cobra.Command {
 }
 
 func newCronListCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all scheduled jobs",
 		RunE:  runCronList,
 	}
+	cmd.Flags().BoolP("verbose", "v", false, "Show recent runs and the last error of each job")
+	return cmd
 }
 
 func newCronAddCmd() *cobra.Command {
@@ -55,12 +59,34 @@ func newCronAddCmd() *cobra.Command {
 	cmd.Flags().Int64("every", 0, "Repeat interval in seconds")
 	cmd.Flags().String("cron", "", "Cron expression (e.g., '0 9 * * *')")
 	cmd.Flags().String("at", "", "One-shot timestamp (RFC3339)")
+	cmd.Flags().String("catch-up", "run_once", "Missed-run policy on startup: skip, run_once, run_all")
+	cmd.Flags().String("channel", "", "Deliver job output to this channel (e.g. telegram); requires --chat-id")
+	cmd.Flags().String("chat-id", "", "Deliver job output to this chat on --channel")
+	cmd.Flags().Bool("deliver", false, "Send the message as-is instead of running it through the agent")
 	cmd.MarkFlagRequired("name")
 	cmd.MarkFlagRequired("message")
 
 	return cmd
 }
 
+func newCronEditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit <job_id>",
+		Short: "Edit an existing scheduled job",
+		Long:  "Edit an existing scheduled job. Only the given flags are changed; the job id and run history are kept.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runCronEdit,
+	}
+
+	cmd.Flags().StringP("name", "n", "", "New job name")
+	cmd.Flags().StringP("message", "m", "", "New message to send to agent")
+	cmd.Flags().Int64("every", 0, "New repeat interval in seconds")
+	cmd.Flags().String("cron", "", "New cron expression (e.g., '0 9 * * *')")
+	cmd.Flags().String("at", "", "New one-shot timestamp (RFC3339)")
+
+	return cmd
+}
+
 func newCronRunCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "run <job_id>",
@@ -228,6 +254,10 @@ func runCronList(cmd *cobra.Command, args []string) error {
 
 	fmt.Println()
 
+	if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
+		printCronRunHistory(jobs)
+	}
+
 	return nil
 }
 
@@ -237,23 +267,107 @@ func runCronAdd(cmd *cobra.Command, args []string) error {
 	every, _ := cmd.Flags().GetInt64("every")
 	cronExpr, _ := cmd.Flags().GetString("cron")
 	at, _ := cmd.Flags().GetString("at")
+	catchUpFlag, _ := cmd.Flags().GetString("catch-up")
+	catchUp, err := cron.ParseCatchUpPolicy(catchUpFlag)
+	if err != nil {
+		return err
+	}
+	channel, _ := cmd.Flags().GetString("channel")
+	chatID, _ := cmd.Flags().GetString("chat-id")
+	deliver, _ := cmd.Flags().GetBool("deliver")
+	channel, chatID = strings.TrimSpace(channel), strings.TrimSpace(chatID)
+	if (channel == "") != (chatID == "") {
+		return fmt.Errorf("--channel and --chat-id must be set together")
+	}
 
-	var schedule cron.Schedule
+	schedule, err := cronScheduleFromFlags(every, cronExpr, at)
+	if err != nil {
+		return err
+	}
+
+	svc, err := loadCronService()
+	if err != nil {
+		return err
+	}
+	defer svc.Stop()
+
+	job, err := svc.AddJobWithOptions(name, message, schedule, cron.AddOptions{
+		Channel: channel,
+		ChatID:  chatID,
+		Deliver: deliver,
+		CatchUp: catchUp,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Job created: %s (%s, catch-up %s)\n", job.ShortID(), job.ScheduleDescription(), job.EffectiveCatchUp())
+	if channel != "" {
+		fmt.Printf("Output will be delivered to %s:%s\n", channel, chatID)
+	}
+	return nil
+}
+
+// cronScheduleFromFlags 按 --every、--cron、--at 的优先级构建调度计划。
+func cronScheduleFromFlags(every int64, cronExpr, at string) (cron.Schedule, error) {
 	switch {
 	case every > 0:
 		ms := every * 1000
-		schedule = cron.Schedule{Kind: "every", EveryMS: &ms}
+		return cron.Schedule{Kind: "every", EveryMS: &ms}, nil
 	case cronExpr != "":
-		schedule = cron.Schedule{Kind: "cron", Expr: cronExpr}
+		return cron.Schedule{Kind: "cron", Expr: cronExpr}, nil
 	case at != "":
 		ts, err := time.Parse(time.RFC3339, at)
 		if err != nil {
-			return fmt.Errorf("invalid --at timestamp (expected RFC3339): %w", err)
+			return cron.Schedule{}, fmt.Errorf("invalid --at timestamp (expected RFC3339): %w", err)
 		}
 		ms := ts.UnixMilli()
-		schedule = cron.Schedule{Kind: "at", AtMS: &ms}
+		return cron.Schedule{Kind: "at", AtMS: &ms}, nil
 	default:
-		return fmt.Errorf("one of --every, --cron, or --at is required")
+		return cron.Schedule{}, fmt.Errorf("one of --every, --cron, or --at is required")
+	}
+}
+
+func runCronEdit(cmd *cobra.Command, args []string) error {
+	jobID := strings.TrimSpace(args[0])
+	if jobID == "" {
+		return fmt.Errorf("job_id is required")
+	}
+
+	flags := cmd.Flags()
+	var opts cron.UpdateOptions
+	if flags.Changed("name") {
+		name, _ := flags.GetString("name")
+		opts.Name = &name
+	}
+	if flags.Changed("message") {
+		message, _ := flags.GetString("message")
+		opts.Message = &message
+	}
+	scheduleFlags := 0
+	for _, f := range []string{"every", "cron", "at"} {
+		if flags.Changed(f) {
+			scheduleFlags++
+		}
+	}
+	if scheduleFlags > 1 {
+		return fmt.Errorf("only one of --every, --cron, or --at may be set")
+	}
+	if scheduleFlags == 1 {
+		every, _ := flags.GetInt64("every")
+		if flags.Changed("every") && every <= 0 {
+			return fmt.Errorf("--every must be a positive number of seconds")
+		}
+		cronExpr, _ := flags.GetString("cron")
+		at, _ := flags.GetString("at")
+		schedule, err := cronScheduleFromFlags(every, cronExpr, at)
+		if err != nil {
+			return err
+		}
+		opts.Schedule = &schedule
+	}
+	if opts.Name == nil && opts.Message == nil && opts.Schedule == nil {
+		return fmt.Errorf("nothing to update: set at least one of --name, --message, --every, --cron, or --at")
 	}
 
 	svc, err := loadCronService()
@@ -262,12 +376,16 @@ func runCronAdd(cmd *cobra.Command, args []string) error {
 	}
 	defer svc.Stop()
 
-	job, err := svc.AddJob(name, message, schedule, "", "", false)
+	job, err := svc.UpdateJob(jobID, opts)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Job created: %s (%s)\n", job.ShortID(), job.ScheduleDescription())
+	nextRun := "-"
+	if job.State.NextRunAtMS != nil {
+		nextRun = time.UnixMilli(*job.State.NextRunAtMS).Format(time.RFC3339)
+	}
+	fmt.Printf("Job updated: %s (%s, next run %s)\n", job.ShortID(), job.ScheduleDescription(), nextRun)
 	return nil
 }
 
@@ -331,23 +449,14 @@ func runCronNow(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("invalid workspace: %w", err)
 	}
+	configureMemoryEmbedder(ctx, cfg, loop)
 	if err := loop.RegisterDefaultTools(cfg); err != nil {
 		return fmt.Errorf("failed to register tools: %w", err)
 	}
 
 	cronStorePath := filepath.Join(workspacePath, "cron", "jobs.json")
-	svc := cron.NewService(cronStorePath, func(job *cron.Job) error {
-		ch := strings.TrimSpace(job.Payload.Channel)
-		if ch == "" {
-			ch = "cron"
-		}
-		chatID := strings.TrimSpace(job.Payload.ChatID)
-		if chatID == "" {
-			chatID = "default"
-		}
-		_, err := loop.ProcessForChannel(ctx, ch, chatID, "cron", job.Payload.Message)
-		return err
-	})
+	// 单独运行时没有启动通道，结果只记录在任务状态中。
+	svc := cron.NewService(cronStorePath, newCronJobHandler(ctx, loop.ProcessForChannel, nil, nil))
 	if err := svc.Start(); err != nil {
 		return err
 	}
@@ -368,9 +477,88 @@ func runCronNow(cmd *cobra.Command, args []string) error {
 		status = "unknown"
 	}
 	fmt.Printf("Job %s (%s) executed, status=%s.\n", job.ShortID(), job.Name, status)
+	if status == "error" && job.State.LastError != "" {
+		fmt.Printf("Error: %s\n", job.State.LastError)
+	}
 	return nil
 }
 
+// cronProcessFunc 以指定通道与聊天的身份让 Agent 处理一条消息并返回回复。
+type cronProcessFunc func(ctx context.Context, channel, chatID, senderID, content string) (string, error)
+
+// newCronJobHandler 构建定时任务的执行回调：deliver 任务直接使用任务消息，否则交给 Agent 处理；
+// 任务指定了通道与聊天时，将结果作为出站消息发布到总线。目标通道未注册（未启用或未就绪）时只记录警告。
+func newCronJobHandler(ctx context.Context, process cronProcessFunc, msgBus *bus.MessageBus, channelRegistered func(string) bool) cron.JobHandler {
+	return func(job *cron.Job) error {
+		ch := strings.TrimSpace(job.Payload.Channel)
+		chatID := strings.TrimSpace(job.Payload.ChatID)
+
+		content := job.Payload.Message
+		if !job.Payload.Deliver {
+			sessionChannel, sessionChat := ch, chatID
+			if sessionChannel == "" {
+				sessionChannel = "cron"
+			}
+			if sessionChat == "" {
+				sessionChat = "default"
+			}
+			resp, err := process(ctx, sessionChannel, sessionChat, "cron", job.Payload.Message)
+			if err != nil {
+				return err
+			}
+			content = resp
+		}
+
+		if ch == "" || chatID == "" || strings.TrimSpace(content) == "" {
+			return nil
+		}
+		if msgBus == nil || channelRegistered == nil || !channelRegistered(ch) {
+			slog.Warn("cron: target channel not enabled; result not delivered", "id", job.ID, "name", job.Name, "channel", ch, "chat_id", chatID)
+			return nil
+		}
+		msgBus.PublishOutbound(&bus.OutboundMessage{
+			Channel:   ch,
+			ChatID:    chatID,
+			Content:   content,
+			RequestID: bus.NewRequestID(),
+			Metadata:  map[string]any{"cron_job_id": job.ID},
+		})
+		return nil
+	}
+}
+
+// printCronRunHistory 按任务输出最近的运行记录（最新的在前）与最近一次失败的错误信息。
+func printCronRunHistory(jobs []*cron.Job) {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#E5484D"))
+
+	for _, j := range jobs {
+		fmt.Printf("  %s %s  %s\n", titleStyle.Render(j.ShortID()), j.Name, dimStyle.Render("catch-up "+string(j.EffectiveCatchUp())))
+		if failure, ok := j.State.LastFailure(); ok {
+			at := time.UnixMilli(failure.StartedAtMS).Format("2006-01-02 15:04:05")
+			fmt.Printf("    %s\n", errStyle.Render(fmt.Sprintf("last error (%s): %s", at, failure.Error)))
+		}
+		if len(j.State.History) == 0 {
+			fmt.Printf("    %s\n\n", dimStyle.Render("no runs recorded"))
+			continue
+		}
+		for i := len(j.State.History) - 1; i >= 0; i-- {
+			r := j.State.History[i]
+			line := fmt.Sprintf("%s  %-7s  %s",
+				time.UnixMilli(r.StartedAtMS).Format("2006-01-02 15:04:05"),
+				r.Status,
+				(time.Duration(r.DurationMS) * time.Millisecond).String(),
+			)
+			if r.Error != "" {
+				line += "  " + truncate(r.Error, 80)
+			}
+			fmt.Printf("    %s\n", line)
+		}
+		fmt.Println()
+	}
+}
+
 // truncate trims a string to maxLen runes (not bytes).
 // It avoids O(N) memory allocation by iterating over runes rather than casting to []rune.
 func truncate(s string, maxLen int) string {
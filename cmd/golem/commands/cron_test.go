@@ -1,10 +1,12 @@
 package commands
 
 import (
+	"context"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/cron"
 )
@@ -53,3 +55,110 @@ func TestCronRun_ExecutesJobNow(t *testing.T) {
 		t.Fatalf("expected executed output, got: %s", out)
 	}
 }
+
+func TestCronJobHandler_PublishesResultToTargetChannel(t *testing.T) {
+	msgBus := bus.NewMessageBus(4)
+	var gotChannel, gotChat string
+	process := func(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+		gotChannel, gotChat = channel, chatID
+		return "daily summary: all good", nil
+	}
+	handler := newCronJobHandler(context.Background(), process, msgBus, func(name string) bool { return name == "telegram" })
+
+	job := cron.NewJob("summary", cron.Schedule{Kind: "cron", Expr: "0 9 * * *"}, cron.Payload{
+		Kind: "agent_turn", Message: "summarize", Channel: "telegram", ChatID: "42",
+	})
+	if err := handler(job); err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	if gotChannel != "telegram" || gotChat != "42" {
+		t.Fatalf("agent processed as %s:%s", gotChannel, gotChat)
+	}
+	select {
+	case out := <-msgBus.Outbound():
+		if out.Channel != "telegram" || out.ChatID != "42" || out.Content != "daily summary: all good" || out.Metadata["cron_job_id"] != job.ID {
+			t.Fatalf("unexpected outbound: %+v", out)
+		}
+	default:
+		t.Fatal("expected outbound message")
+	}
+}
+
+func TestCronJobHandler_SkipsUnregisteredChannelAndDeliversAsIs(t *testing.T) {
+	msgBus := bus.NewMessageBus(4)
+	processed := false
+	process := func(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+		processed = true
+		return "reply", nil
+	}
+	handler := newCronJobHandler(context.Background(), process, msgBus, func(string) bool { return false })
+
+	job := cron.NewJob("reminder", cron.Schedule{Kind: "cron", Expr: "0 9 * * *"}, cron.Payload{
+		Kind: "agent_turn", Message: "stand up", Channel: "slack", ChatID: "C1", Deliver: true,
+	})
+	if err := handler(job); err != nil {
+		t.Fatalf("handler: %v", err)
+	}
+	if processed {
+		t.Fatal("deliver job should not run through the agent")
+	}
+	select {
+	case out := <-msgBus.Outbound():
+		t.Fatalf("unexpected outbound for unregistered channel: %+v", out)
+	default:
+	}
+}
+
+func TestCronEdit_UpdatesScheduleAndKeepsJobID(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	if err := runInit(nil, nil); err != nil {
+		t.Fatalf("runInit: %v", err)
+	}
+
+	svc, err := loadCronService()
+	if err != nil {
+		t.Fatalf("load cron service: %v", err)
+	}
+	everyMS := int64(60000)
+	job, err := svc.AddJob("hourly", "status report", cron.Schedule{Kind: "every", EveryMS: &everyMS}, "", "", false)
+	svc.Stop()
+	if err != nil {
+		t.Fatalf("add job: %v", err)
+	}
+
+	cmd := newCronEditCmd()
+	cmd.SetArgs([]string{job.ID, "--cron", "0 9 * * *", "--name", "daily"})
+	out := captureOutput(t, func() {
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("cron edit: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Job updated: "+job.ShortID()) || !strings.Contains(out, "cron: 0 9 * * *") {
+		t.Fatalf("unexpected output: %s", out)
+	}
+
+	svc, err = loadCronService()
+	if err != nil {
+		t.Fatalf("reload cron service: %v", err)
+	}
+	defer svc.Stop()
+	got, ok := svc.GetJob(job.ID)
+	if !ok || got.Name != "daily" || got.Payload.Message != "status report" || got.Schedule.Kind != "cron" {
+		t.Fatalf("unexpected job after edit: %+v", got)
+	}
+
+	bad := newCronEditCmd()
+	bad.SetArgs([]string{job.ID, "--cron", "61 * * * *"})
+	bad.SilenceUsage, bad.SilenceErrors = true, true
+	if err := bad.Execute(); err == nil {
+		t.Fatal("expected invalid cron expression to be rejected")
+	}
+	empty := newCronEditCmd()
+	empty.SetArgs([]string{job.ID})
+	empty.SilenceUsage, empty.SilenceErrors = true, true
+	if err := empty.Execute(); err == nil || !strings.Contains(err.Error(), "nothing to update") {
+		t.Fatalf("expected nothing-to-update error, got %v", err)
+	}
+}
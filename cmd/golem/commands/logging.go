@@ -5,16 +5,17 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/logrotate"
 )
 
 var (
 	loggerMu      sync.Mutex
-	activeLogFile *os.File
+	activeLogFile *logrotate.File
+	activeLogOpts logrotate.Options
 )
 
 func configureLogger(cfg *config.Config, overrideLevel string, tuiMode bool) error {
@@ -29,21 +30,20 @@ func configureLogger(cfg *config.Config, overrideLevel string, tuiMode bool) err
 	loggerMu.Lock()
 	defer loggerMu.Unlock()
 
-	if activeLogFile != nil && (logFilePath == "" || activeLogFile.Name() != logFilePath) {
+	rotation := logrotate.OptionsFromConfig(cfg.Log.Rotation)
+	if activeLogFile != nil && (logFilePath == "" || activeLogFile.Name() != logFilePath || activeLogOpts != rotation) {
 		_ = activeLogFile.Close()
 		activeLogFile = nil
 	}
 
 	if logFilePath != "" {
-		if err := os.MkdirAll(filepath.Dir(logFilePath), 0755); err != nil {
-			return fmt.Errorf("create log directory: %w", err)
-		}
 		if activeLogFile == nil {
-			f, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+			f, err := logrotate.Open(logFilePath, rotation)
 			if err != nil {
-				return fmt.Errorf("open log file: %w", err)
+				return err
 			}
 			activeLogFile = f
+			activeLogOpts = rotation
 		}
 		writer = activeLogFile
 	} else if tuiMode {
@@ -1,8 +1,12 @@
 package commands
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 	"time"
@@ -13,9 +17,18 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const mcpProbeTimeout = 8 * time.Second // MCP 服务器探测超时时间
+const (
+	mcpProbeTimeout            = 8 * time.Second  // MCP 服务器探测超时时间
+	mcpGatewayReconnectTimeout = 30 * time.Second // 通过网关重连的超时时间（含服务端退避重试）
+)
+
+var (
+	mcpProbeServer      = probeMCPServer
+	mcpGatewayReconnect = reconnectViaGateway
+	mcpFetchLogs        = fetchGatewayMCPLogs
 
-var mcpProbeServer = probeMCPServer
+	errGatewayUnavailable = errors.New("gateway unavailable")
+)
 
 // NewMCPCmd 创建 MCP 服务器管理命令。
 func NewMCPCmd() *cobra.Command {
@@ -34,20 +47,24 @@ func NewMCPCmd() *cobra.Command {
 }
 
 func newMCPStatusCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show MCP server health and degraded reasons",
 		RunE:  runMCPStatus,
 	}
+	cmd.Flags().Bool("logs", false, "Also show recent stderr output of stdio servers from the running gateway")
+	return cmd
 }
 
 func newMCPReconnectCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "reconnect <server>",
-		Short: "Probe and reconnect one MCP server",
-		Args:  cobra.ExactArgs(1),
+	cmd := &cobra.Command{
+		Use:   "reconnect [server]",
+		Short: "Reconnect one MCP server, or every degraded server with --all",
+		Args:  cobra.MaximumNArgs(1),
 		RunE:  runMCPReconnect,
 	}
+	cmd.Flags().Bool("all", false, "Reconnect every degraded MCP server")
+	return cmd
 }
 
 func newMCPDisableCmd() *cobra.Command {
@@ -69,6 +86,10 @@ func runMCPStatus(cmd *cobra.Command, args []string) error {
 		fmt.Println("No MCP servers configured. Add them in ~/.golem/config.json under 'mcp.servers'.")
 		return nil
 	}
+	showLogs := false
+	if cmd != nil {
+		showLogs, _ = cmd.Flags().GetBool("logs")
+	}
 
 	var (
 		wName   = 20
@@ -155,7 +176,7 @@ func runMCPStatus(cmd *cobra.Command, args []string) error {
 			} else {
 				sStatus = "connected"
 				sTools = fmt.Sprintf("%d", status.ToolCount)
-				sMsg = ""
+				sMsg = describeMCPServerInfo(status.ServerInfo)
 			}
 		}
 
@@ -169,38 +190,148 @@ func runMCPStatus(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  %s\n", row)
 	}
 
+	if showLogs {
+		printMCPLogs(cfg.Gateway)
+	}
 	return nil
 }
 
+// printMCPLogs 输出运行中网关保留的各 stdio 服务器 stderr 尾部。
+func printMCPLogs(gw config.GatewayConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusGatewayTimeout)
+	defer cancel()
+
+	fmt.Println()
+	logs, err := mcpFetchLogs(ctx, gw)
+	if err != nil {
+		fmt.Printf("stderr logs unavailable: %v\n", err)
+		fmt.Println("Logs are kept by the running gateway; startup failures also include the stderr tail in the message above.")
+		return
+	}
+	if len(logs) == 0 {
+		fmt.Println("No stdio MCP servers in the running gateway.")
+		return
+	}
+	for _, entry := range logs {
+		suffix := ""
+		if !entry.Live {
+			suffix = " (captured before disconnect)"
+		}
+		fmt.Printf("%s stderr%s:\n", entry.Server, suffix)
+		text := strings.TrimRight(entry.Stderr, "\n")
+		if strings.TrimSpace(text) == "" {
+			fmt.Println("  (no output)")
+			continue
+		}
+		for _, line := range strings.Split(text, "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+// describeMCPServerInfo 将服务器在 initialize 中上报的名称、版本与协议版本格式化为一行，如 "fs 2.1.0, mcp 2025-03-26"。
+func describeMCPServerInfo(info mcp.ServerInfo) string {
+	parts := make([]string, 0, 2)
+	if ident := strings.TrimSpace(info.Name + " " + info.Version); ident != "" {
+		parts = append(parts, ident)
+	}
+	if info.ProtocolVersion != "" {
+		parts = append(parts, "mcp "+info.ProtocolVersion)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func runMCPReconnect(cmd *cobra.Command, args []string) error {
-	serverName := strings.TrimSpace(args[0])
+	all := false
+	if cmd != nil {
+		all, _ = cmd.Flags().GetBool("all")
+	}
+	serverName := ""
+	if len(args) > 0 {
+		serverName = strings.TrimSpace(args[0])
+	}
+	if all == (serverName != "") {
+		return fmt.Errorf("specify exactly one of <server> or --all")
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	serverCfg, ok := cfg.MCP.Servers[serverName]
-	if !ok {
-		return fmt.Errorf("mcp server not found: %s", serverName)
+	if !all {
+		serverCfg, ok := cfg.MCP.Servers[serverName]
+		if !ok {
+			return fmt.Errorf("mcp server not found: %s", serverName)
+		}
+		if !isConfigMCPServerEnabled(serverCfg) {
+			return fmt.Errorf("mcp server %s is disabled in config", serverName)
+		}
 	}
-	if !isConfigMCPServerEnabled(serverCfg) {
-		return fmt.Errorf("mcp server %s is disabled in config", serverName)
+
+	// 优先让运行中的服务重连，使恢复立即作用于正在处理请求的进程；网关不可达时退回本地探测。
+	ctx, cancel := context.WithTimeout(context.Background(), mcpGatewayReconnectTimeout)
+	defer cancel()
+	statuses, err := mcpGatewayReconnect(ctx, cfg.Gateway, serverName, all)
+	switch {
+	case err == nil:
+		return reportMCPReconnect(statuses, serverName, all, "via gateway")
+	case !errors.Is(err, errGatewayUnavailable):
+		return err
 	}
 
-	status, probeErr := probeServerWithTimeout(serverName, serverCfg)
-	if probeErr != nil {
-		return fmt.Errorf("reconnect %s failed: %w", serverName, probeErr)
+	names := []string{serverName}
+	if all {
+		names = names[:0]
+		for _, name := range sortedMCPServerNames(cfg.MCP.Servers) {
+			if isConfigMCPServerEnabled(cfg.MCP.Servers[name]) {
+				names = append(names, name)
+			}
+		}
 	}
-	if status.Degraded || !status.Connected {
-		msg := strings.TrimSpace(status.Message)
-		if msg == "" {
-			msg = "unknown error"
+
+	statuses = make([]mcp.ServerStatus, 0, len(names))
+	for _, name := range names {
+		status, probeErr := probeServerWithTimeout(name, cfg.MCP.Servers[name])
+		if probeErr != nil {
+			if !all {
+				return fmt.Errorf("reconnect %s failed: %w", name, probeErr)
+			}
+			status = mcp.ServerStatus{Name: name, Degraded: true, Message: probeErr.Error()}
 		}
-		return fmt.Errorf("mcp server %s is still degraded: %s", serverName, msg)
+		statuses = append(statuses, status)
 	}
+	return reportMCPReconnect(statuses, serverName, all, "")
+}
 
-	fmt.Printf("MCP server %s reconnected (tools=%d).\n", serverName, status.ToolCount)
+// reportMCPReconnect 输出重连结果；单个服务器仍处于降级状态时返回错误。
+func reportMCPReconnect(statuses []mcp.ServerStatus, serverName string, all bool, via string) error {
+	suffix := ""
+	if via != "" {
+		suffix = " " + via
+	}
+	if all && len(statuses) == 0 {
+		fmt.Println("No degraded MCP servers to reconnect.")
+		return nil
+	}
+
+	for _, status := range statuses {
+		if status.Degraded || !status.Connected {
+			msg := strings.TrimSpace(status.Message)
+			if msg == "" {
+				msg = "unknown error"
+			}
+			if !all {
+				return fmt.Errorf("mcp server %s is still degraded: %s", status.Name, msg)
+			}
+			fmt.Printf("MCP server %s is still degraded: %s\n", status.Name, msg)
+			continue
+		}
+		fmt.Printf("MCP server %s reconnected%s (tools=%d).\n", status.Name, suffix, status.ToolCount)
+	}
+	if !all && len(statuses) == 0 {
+		return fmt.Errorf("mcp server %s returned no status", serverName)
+	}
 	return nil
 }
 
@@ -228,6 +359,74 @@ func runMCPDisable(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// reconnectViaGateway 请求运行中的网关重连 MCP 服务器；无法连上网关时返回 errGatewayUnavailable。
+func reconnectViaGateway(ctx context.Context, gw config.GatewayConfig, serverName string, all bool) ([]mcp.ServerStatus, error) {
+	reqBody, err := json.Marshal(map[string]any{"server": serverName, "all": all})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gatewayBaseURL(gw)+"/mcp/reconnect", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: reconnect endpoint not available", errGatewayUnavailable)
+	}
+
+	var body struct {
+		Servers []mcp.ServerStatus `json:"servers"`
+		Message string             `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode gateway response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(body.Message)
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("gateway reconnect failed: %s", msg)
+	}
+	return body.Servers, nil
+}
+
+func fetchGatewayMCPLogs(ctx context.Context, gw config.GatewayConfig) ([]mcp.ServerLogs, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gatewayBaseURL(gw)+"/mcp/logs", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Servers []mcp.ServerLogs `json:"servers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode mcp logs: %w", err)
+	}
+	return body.Servers, nil
+}
+
 func probeServerWithTimeout(serverName string, cfg config.MCPServerConfig) (mcp.ServerStatus, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), mcpProbeTimeout)
 	defer cancel()
@@ -80,6 +80,129 @@ func TestMCPReconnect_UsesProbeAndReportsHealthy(t *testing.T) {
 	}
 }
 
+func TestMCPStatus_ShowsServerInfo(t *testing.T) {
+	prepareMCPWorkspace(t)
+	seedMCPServerConfig(t)
+
+	origProbe := mcpProbeServer
+	mcpProbeServer = func(ctx context.Context, serverName string, cfg config.MCPServerConfig) (mcp.ServerStatus, error) {
+		return mcp.ServerStatus{
+			Name:       serverName,
+			Connected:  true,
+			ToolCount:  2,
+			ServerInfo: mcp.ServerInfo{Name: "fs", Version: "2.1.0", ProtocolVersion: "2025-03-26"},
+		}, nil
+	}
+	defer func() { mcpProbeServer = origProbe }()
+
+	output := captureOutput(t, func() {
+		if err := runMCPStatus(nil, nil); err != nil {
+			t.Fatalf("runMCPStatus: %v", err)
+		}
+	})
+	if !strings.Contains(output, "fs 2.1.0, mcp 2025-03-26") {
+		t.Fatalf("expected server info in status output, got: %s", output)
+	}
+}
+
+func TestMCPStatus_LogsFlagPrintsStderrTails(t *testing.T) {
+	prepareMCPWorkspace(t)
+	seedMCPServerConfig(t)
+
+	origProbe, origLogs := mcpProbeServer, mcpFetchLogs
+	mcpProbeServer = func(ctx context.Context, serverName string, cfg config.MCPServerConfig) (mcp.ServerStatus, error) {
+		return mcp.ServerStatus{Name: serverName, Connected: true, ToolCount: 1}, nil
+	}
+	mcpFetchLogs = func(ctx context.Context, gw config.GatewayConfig) ([]mcp.ServerLogs, error) {
+		return []mcp.ServerLogs{{Server: "localfs", Stderr: "loading\nready\n", Live: false}}, nil
+	}
+	defer func() { mcpProbeServer, mcpFetchLogs = origProbe, origLogs }()
+
+	cmd := newMCPStatusCmd()
+	if err := cmd.Flags().Set("logs", "true"); err != nil {
+		t.Fatalf("set --logs: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runMCPStatus(cmd, nil); err != nil {
+			t.Fatalf("runMCPStatus: %v", err)
+		}
+	})
+	if !strings.Contains(output, "localfs stderr (captured before disconnect):") {
+		t.Fatalf("expected stderr section header, got: %s", output)
+	}
+	if !strings.Contains(output, "  loading\n  ready\n") {
+		t.Fatalf("expected indented stderr lines, got: %s", output)
+	}
+}
+
+func TestMCPReconnect_PrefersRunningGateway(t *testing.T) {
+	prepareMCPWorkspace(t)
+	seedMCPServerConfig(t)
+
+	origGateway := mcpGatewayReconnect
+	mcpGatewayReconnect = func(ctx context.Context, gw config.GatewayConfig, serverName string, all bool) ([]mcp.ServerStatus, error) {
+		return []mcp.ServerStatus{{Name: serverName, Connected: true, ToolCount: 4}}, nil
+	}
+	defer func() { mcpGatewayReconnect = origGateway }()
+
+	origProbe := mcpProbeServer
+	mcpProbeServer = func(ctx context.Context, serverName string, cfg config.MCPServerConfig) (mcp.ServerStatus, error) {
+		t.Fatal("expected probe not to run when the gateway is reachable")
+		return mcp.ServerStatus{}, nil
+	}
+	defer func() { mcpProbeServer = origProbe }()
+
+	output := captureOutput(t, func() {
+		if err := runMCPReconnect(nil, []string{"localfs"}); err != nil {
+			t.Fatalf("runMCPReconnect: %v", err)
+		}
+	})
+	if !strings.Contains(output, "via gateway") || !strings.Contains(output, "tools=4") {
+		t.Fatalf("expected gateway reconnect output, got: %s", output)
+	}
+}
+
+func TestMCPReconnect_AllFallsBackToProbeWithoutGateway(t *testing.T) {
+	prepareMCPWorkspace(t)
+	seedMCPServerConfig(t)
+
+	origGateway := mcpGatewayReconnect
+	mcpGatewayReconnect = func(ctx context.Context, gw config.GatewayConfig, serverName string, all bool) ([]mcp.ServerStatus, error) {
+		if !all || serverName != "" {
+			t.Fatalf("expected --all request, got server=%q all=%v", serverName, all)
+		}
+		return nil, errGatewayUnavailable
+	}
+	defer func() { mcpGatewayReconnect = origGateway }()
+
+	origProbe := mcpProbeServer
+	mcpProbeServer = func(ctx context.Context, serverName string, cfg config.MCPServerConfig) (mcp.ServerStatus, error) {
+		return mcp.ServerStatus{Name: serverName, Degraded: true, Message: "connect failed"}, nil
+	}
+	defer func() { mcpProbeServer = origProbe }()
+
+	cmd := newMCPReconnectCmd()
+	if err := cmd.Flags().Set("all", "true"); err != nil {
+		t.Fatalf("set --all: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runMCPReconnect(cmd, nil); err != nil {
+			t.Fatalf("runMCPReconnect: %v", err)
+		}
+	})
+	if !strings.Contains(output, "localfs is still degraded: connect failed") {
+		t.Fatalf("expected degraded probe result, got: %s", output)
+	}
+}
+
+func TestMCPReconnect_RequiresServerOrAll(t *testing.T) {
+	prepareMCPWorkspace(t)
+
+	if err := runMCPReconnect(nil, nil); err == nil {
+		t.Fatal("expected error without server or --all")
+	}
+}
+
 func TestMCPCommand_RegisteredInRoot(t *testing.T) {
 	root := NewRootCmd()
 	found, _, err := root.Find([]string{"mcp", "status"})
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+// diaryPruneInterval 是 golem run 自动归档旧日记的间隔。
+const diaryPruneInterval = 24 * time.Hour
+
+// memoryNow 返回当前时间，测试中可替换。
+var memoryNow = time.Now
+
+// NewMemoryCmd 创建记忆管理子命令。
+func NewMemoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "memory",
+		Short: "Manage workspace memory",
+	}
+	cmd.AddCommand(newMemoryPruneCmd())
+	return cmd
+}
+
+func newMemoryPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Archive old diaries into memory/archive/",
+		Long: `Archive diaries older than --days, or beyond the newest --max days, into
+memory/archive/YYYY-MM-DD.md.gz. Archived diaries are left out of memory recall
+but can still be read with /memory diary or the read_diary tool. Today's diary
+is never archived. Limits default to agents.memory.retention_days and
+agents.memory.max_diaries.`,
+		Args: cobra.NoArgs,
+		RunE: runMemoryPrune,
+	}
+	cmd.Flags().Int("days", -1, "Archive diaries older than this many days (default agents.memory.retention_days)")
+	cmd.Flags().Int("max", -1, "Keep at most this many diary days (default agents.memory.max_diaries)")
+	cmd.Flags().Bool("dry-run", false, "Only list the diaries that would be archived")
+	return cmd
+}
+
+func runMemoryPrune(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	workspacePath, err := cfg.WorkspacePathChecked()
+	if err != nil {
+		return fmt.Errorf("invalid workspace: %w", err)
+	}
+
+	opts := diaryPruneOptions(cfg.Agents.Memory)
+	if days, _ := cmd.Flags().GetInt("days"); days >= 0 {
+		opts.MaxAgeDays = days
+	}
+	if maxDiaries, _ := cmd.Flags().GetInt("max"); maxDiaries >= 0 {
+		opts.MaxDiaries = maxDiaries
+	}
+	opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+	if opts.MaxAgeDays == 0 && opts.MaxDiaries == 0 {
+		return fmt.Errorf("no retention limit: pass --days or --max, or set agents.memory.retention_days / max_diaries")
+	}
+
+	result, err := memory.NewManager(workspacePath).PruneDiaries(opts)
+	if err != nil {
+		return err
+	}
+	if len(result.Archived) == 0 {
+		fmt.Printf("Nothing to archive (%d diaries kept).\n", result.Kept)
+		return nil
+	}
+	verb := "Archived"
+	if opts.DryRun {
+		verb = "Would archive"
+	}
+	fmt.Printf("%s %d diaries (%s); %d kept.\n", verb, len(result.Archived), strings.Join(result.Archived, ", "), result.Kept)
+	return nil
+}
+
+func diaryPruneOptions(c config.MemoryConfig) memory.PruneOptions {
+	return memory.PruneOptions{
+		MaxAgeDays: c.RetentionDays,
+		MaxDiaries: c.MaxDiaries,
+		Now:        memoryNow(),
+	}
+}
+
+// runDiaryPruner 在配置了日记保留策略时，于启动时及之后每 24 小时归档一次旧日记，直到 ctx 结束。
+func runDiaryPruner(ctx context.Context, workspacePath string, c config.MemoryConfig) {
+	if c.RetentionDays == 0 && c.MaxDiaries == 0 {
+		return
+	}
+	mgr := memory.NewManager(workspacePath)
+	prune := func() {
+		result, err := mgr.PruneDiaries(diaryPruneOptions(c))
+		if err != nil {
+			slog.Warn("diary pruning failed", "error", err)
+			return
+		}
+		if len(result.Archived) > 0 {
+			slog.Info("archived old diaries", "count", len(result.Archived), "kept", result.Kept)
+		}
+	}
+
+	prune()
+	ticker := time.NewTicker(diaryPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			prune()
+		}
+	}
+}
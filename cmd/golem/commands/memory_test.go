@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryPrune_ArchivesOldDiaries(t *testing.T) {
+	workspacePath := prepareApprovalWorkspace(t)
+	memDir := filepath.Join(workspacePath, "memory")
+	for _, d := range []string{"2026-01-01", "2026-02-27"} {
+		if err := os.WriteFile(filepath.Join(memDir, d+".md"), []byte("- [09:00:00] note\n"), 0o644); err != nil {
+			t.Fatalf("write diary: %v", err)
+		}
+	}
+	orig := memoryNow
+	memoryNow = func() time.Time { return time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { memoryNow = orig })
+
+	cmd := newMemoryPruneCmd()
+	if err := runMemoryPrune(cmd, nil); err == nil || !strings.Contains(err.Error(), "no retention limit") {
+		t.Fatalf("expected missing limit error, got %v", err)
+	}
+
+	_ = cmd.Flags().Set("days", "30")
+	output := captureOutput(t, func() {
+		if err := runMemoryPrune(cmd, nil); err != nil {
+			t.Fatalf("runMemoryPrune: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Archived 1 diaries (2026-01-01); 1 kept.") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(memDir, "archive", "2026-01-01.md.gz")); err != nil {
+		t.Fatalf("expected archived diary: %v", err)
+	}
+}
@@ -1,15 +1,20 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/MEKXH/golem/internal/audit"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/policy"
 	"github.com/spf13/cobra"
 )
 
+// policyReloadGateway 通知运行中的网关重新加载配置，测试中可替换。
+var policyReloadGateway = requestGatewayReload
+
 func NewPolicyCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "policy",
@@ -21,6 +26,7 @@ func NewPolicyCmd() *cobra.Command {
 		newPolicyStrictCmd(),
 		newPolicyRelaxedCmd(),
 		newPolicyStatusCmd(),
+		newPolicySetCmd(),
 	)
 
 	return cmd
@@ -55,11 +61,22 @@ func newPolicyRelaxedCmd() *cobra.Command {
 func newPolicyStatusCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
-		Short: "Show current policy mode and risk hint",
+		Short: "Show base and effective policy mode, off-mode countdown and risk hint",
 		RunE:  runPolicyStatus,
 	}
 }
 
+func newPolicySetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <strict|relaxed|off>",
+		Short: "Set policy mode and apply it to the running gateway",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPolicySet,
+	}
+	cmd.Flags().String("ttl", "", "For off mode: revert to strict after this duration, for example 30m")
+	return cmd
+}
+
 func runPolicyOff(cmd *cobra.Command, args []string) error {
 	ttlRaw, _ := cmd.Flags().GetString("ttl")
 	ttlRaw = strings.TrimSpace(ttlRaw)
@@ -125,35 +142,192 @@ func runPolicyRelaxed(cmd *cobra.Command, args []string) error {
 }
 
 func runPolicyStatus(cmd *cobra.Command, args []string) error {
-	cfg, _, err := loadPolicyConfig()
+	cfg, workspacePath, err := loadPolicyConfig()
 	if err != nil {
 		return err
 	}
+	report := buildPolicyStatus(cfg, workspacePath, time.Now().UTC())
 
-	mode := strings.TrimSpace(cfg.Policy.Mode)
-	offTTL := strings.TrimSpace(cfg.Policy.OffTTL)
-	if offTTL == "" {
-		offTTL = "none"
+	fmt.Println("Policy status:")
+	if report.Source == policySourceRuntime {
+		fmt.Printf("  source: runtime (updated %s)\n", report.UpdatedAt)
+	} else {
+		fmt.Println("  source: config (no running agent has recorded its policy yet)")
 	}
-	approvalList := "none"
-	if len(cfg.Policy.RequireApproval) > 0 {
-		approvalList = strings.Join(cfg.Policy.RequireApproval, ", ")
+	if report.StateError != "" {
+		fmt.Printf("  state_error: %s\n", report.StateError)
+	}
+	fmt.Printf("  mode: %s\n", report.BaseMode)
+	if report.OffExpired {
+		fmt.Printf("  effective_mode: %s (off_ttl expired)\n", report.EffectiveMode)
+	} else {
+		fmt.Printf("  effective_mode: %s\n", report.EffectiveMode)
+	}
+	switch {
+	case report.OffUntil != "" && report.OffExpired:
+		fmt.Printf("  off_until: %s (expired)\n", report.OffUntil)
+	case report.OffUntil != "":
+		fmt.Printf("  off_until: %s (remaining %s)\n", report.OffUntil, report.OffRemaining)
+	default:
+		fmt.Println("  off_until: none")
+	}
+	fmt.Printf("  off_ttl: %s\n", orNone(report.OffTTL))
+	fmt.Printf("  allow_persistent_off: %t\n", report.AllowPersistentOff)
+	fmt.Printf("  require_approval: %s\n", orNone(strings.Join(report.RequireApproval, ", ")))
+	if report.ConfigMode != report.BaseMode {
+		fmt.Printf("  note: config has mode=%s; it applies once the running agent reloads its config\n", report.ConfigMode)
 	}
 
-	fmt.Println("Policy status:")
-	fmt.Printf("  mode: %s\n", mode)
-	fmt.Printf("  off_ttl: %s\n", offTTL)
-	fmt.Printf("  allow_persistent_off: %t\n", cfg.Policy.AllowPersistentOff)
-	fmt.Printf("  require_approval: %s\n", approvalList)
-
-	if warning, ok := persistentOffWarningMessage(cfg.Policy.Mode, cfg.Policy.OffTTL); ok {
+	switch {
+	case report.EffectiveMode != string(policy.ModeOff):
+		fmt.Println("  risk: normal")
+	case report.persistentOff():
+		warning, _ := persistentOffWarningMessage("off", "")
 		fmt.Printf("  risk: HIGH-RISK (%s)\n", warning)
-	} else if strings.EqualFold(mode, "off") {
+	default:
 		fmt.Println("  risk: limited (ttl-based off mode)")
+	}
+
+	return nil
+}
+
+const (
+	policySourceRuntime = "runtime" // 来自运行中 Agent 写入的策略快照
+	policySourceConfig  = "config"  // 仅来自配置文件
+)
+
+// policyStatusReport 汇总基础模式、实际生效模式与 off 模式倒计时，供 policy status 与 status --json 使用。
+type policyStatusReport struct {
+	Source             string   `json:"source"`
+	UpdatedAt          string   `json:"updated_at,omitempty"`
+	StateError         string   `json:"state_error,omitempty"`
+	ConfigMode         string   `json:"config_mode"`
+	BaseMode           string   `json:"base_mode"`
+	EffectiveMode      string   `json:"effective_mode"`
+	OffTTL             string   `json:"off_ttl,omitempty"`
+	OffUntil           string   `json:"off_until,omitempty"`
+	OffRemaining       string   `json:"off_remaining,omitempty"`
+	OffExpired         bool     `json:"off_expired"`
+	AllowPersistentOff bool     `json:"allow_persistent_off"`
+	RequireApproval    []string `json:"require_approval"`
+}
+
+// persistentOff 报告当前是否处于没有截止时间的 off 模式。
+func (r policyStatusReport) persistentOff() bool {
+	if r.EffectiveMode != string(policy.ModeOff) {
+		return false
+	}
+	if r.Source == policySourceRuntime {
+		return r.OffUntil == ""
+	}
+	return r.OffTTL == ""
+}
+
+// buildPolicyStatus 优先使用运行中 Agent 写入的策略快照（off_ttl 的截止时间只有它知道），
+// 没有快照时退回配置文件。
+func buildPolicyStatus(cfg *config.Config, workspacePath string, now time.Time) policyStatusReport {
+	configMode := strings.ToLower(strings.TrimSpace(cfg.Policy.Mode))
+	if configMode == "" {
+		configMode = string(policy.ModeStrict)
+	}
+	report := policyStatusReport{
+		Source:             policySourceConfig,
+		ConfigMode:         configMode,
+		BaseMode:           configMode,
+		EffectiveMode:      configMode,
+		OffTTL:             strings.TrimSpace(cfg.Policy.OffTTL),
+		AllowPersistentOff: cfg.Policy.AllowPersistentOff,
+		RequireApproval:    append([]string{}, cfg.Policy.RequireApproval...),
+	}
+
+	state, ok, err := policy.ReadState(workspacePath)
+	if err != nil {
+		report.StateError = err.Error()
+		return report
+	}
+	if !ok {
+		return report
+	}
+
+	report.Source = policySourceRuntime
+	report.UpdatedAt = state.UpdatedAt.UTC().Format(time.RFC3339)
+	report.BaseMode = strings.ToLower(strings.TrimSpace(string(state.BaseMode)))
+	if report.BaseMode == "" {
+		report.BaseMode = string(policy.ModeStrict)
+	}
+	effective, expired := state.Effective(now)
+	report.EffectiveMode = strings.ToLower(strings.TrimSpace(string(effective)))
+	if report.EffectiveMode == "" {
+		report.EffectiveMode = string(policy.ModeStrict)
+	}
+	report.OffExpired = expired
+	if report.BaseMode == string(policy.ModeOff) && !state.OffUntil.IsZero() {
+		report.OffUntil = state.OffUntil.UTC().Format(time.RFC3339)
+		if !expired {
+			report.OffRemaining = state.Remaining(now).Round(time.Second).String()
+		}
+	}
+	report.RequireApproval = append([]string{}, state.RequireApproval...)
+	return report
+}
+
+func orNone(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "none"
+	}
+	return s
+}
+
+func runPolicySet(cmd *cobra.Command, args []string) error {
+	mode := strings.ToLower(strings.TrimSpace(args[0]))
+	switch mode {
+	case "strict", "relaxed", "off":
+	default:
+		return fmt.Errorf("policy mode must be one of strict, relaxed, off; got %q", args[0])
+	}
+
+	ttlRaw, _ := cmd.Flags().GetString("ttl")
+	ttlRaw = strings.TrimSpace(ttlRaw)
+	offTTL := ""
+	if ttlRaw != "" {
+		if mode != "off" {
+			return fmt.Errorf("--ttl only applies to off mode")
+		}
+		ttl, err := time.ParseDuration(ttlRaw)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid --ttl duration: %q", ttlRaw)
+		}
+		offTTL = ttl.String()
+	}
+
+	cfg, workspacePath, err := loadPolicyConfig()
+	if err != nil {
+		return err
+	}
+	if mode == "off" && offTTL == "" && !cfg.Policy.AllowPersistentOff {
+		return fmt.Errorf("off mode without --ttl requires policy.allow_persistent_off=true")
+	}
+
+	cfg.Policy.Mode = mode
+	cfg.Policy.OffTTL = offTTL
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	appendPolicySwitchAudit(workspacePath, cfg)
+
+	if offTTL != "" {
+		fmt.Printf("Policy set to off (ttl=%s).\n", offTTL)
 	} else {
-		fmt.Println("  risk: normal")
+		fmt.Printf("Policy set to %s.\n", mode)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), statusGatewayTimeout)
+	defer cancel()
+	if err := policyReloadGateway(ctx, cfg.Gateway); err != nil {
+		fmt.Printf("Saved to config; running gateway not updated (%v). The change applies on next start.\n", err)
+		return nil
+	}
+	fmt.Println("Applied to the running gateway.")
 	return nil
 }
 
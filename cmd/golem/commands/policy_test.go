@@ -2,14 +2,18 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MEKXH/golem/internal/audit"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/policy"
 )
 
 func TestPolicyOff_RequiresTTL(t *testing.T) {
@@ -139,6 +143,128 @@ func TestPolicyStatus_ShowsRiskForPersistentOff(t *testing.T) {
 	}
 }
 
+func TestPolicyStatus_ShowsRuntimeOffCountdown(t *testing.T) {
+	workspacePath := preparePolicyWorkspace(t)
+
+	offUntil := time.Now().UTC().Add(10 * time.Minute)
+	if err := policy.WriteState(workspacePath, policy.State{
+		BaseMode:        policy.ModeOff,
+		OffUntil:        offUntil,
+		RequireApproval: []string{"exec"},
+		UpdatedAt:       time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("WriteState: %v", err)
+	}
+
+	output := captureOutput(t, func() {
+		if err := runPolicyStatus(nil, nil); err != nil {
+			t.Fatalf("runPolicyStatus: %v", err)
+		}
+	})
+	for _, want := range []string{"source: runtime", "mode: off", "effective_mode: off", "remaining", "require_approval: exec", "risk: limited"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output, got: %s", want, output)
+		}
+	}
+}
+
+func TestBuildPolicyStatus_ExpiredOffFallsBackToStrict(t *testing.T) {
+	workspacePath := preparePolicyWorkspace(t)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	now := time.Date(2026, 2, 15, 1, 0, 0, 0, time.UTC)
+	if err := policy.WriteState(workspacePath, policy.State{
+		BaseMode:  policy.ModeOff,
+		OffUntil:  now.Add(-time.Minute),
+		UpdatedAt: now.Add(-time.Hour),
+	}); err != nil {
+		t.Fatalf("WriteState: %v", err)
+	}
+
+	report := buildPolicyStatus(cfg, workspacePath, now)
+	if report.Source != policySourceRuntime || report.BaseMode != "off" {
+		t.Fatalf("unexpected source/base mode: %+v", report)
+	}
+	if report.EffectiveMode != "strict" || !report.OffExpired || report.OffRemaining != "" {
+		t.Fatalf("expected expired off window to fall back to strict, got %+v", report)
+	}
+	if report.OffUntil != "2026-02-15T00:59:00Z" {
+		t.Fatalf("unexpected off_until: %q", report.OffUntil)
+	}
+}
+
+func TestPolicySet_SavesConfigAndReloadsGateway(t *testing.T) {
+	workspacePath := preparePolicyWorkspace(t)
+
+	reloads := 0
+	orig := policyReloadGateway
+	policyReloadGateway = func(ctx context.Context, gw config.GatewayConfig) error {
+		reloads++
+		return nil
+	}
+	t.Cleanup(func() { policyReloadGateway = orig })
+
+	cmd := newPolicySetCmd()
+	if err := cmd.Flags().Set("ttl", "45m"); err != nil {
+		t.Fatalf("set --ttl: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runPolicySet(cmd, []string{"off"}); err != nil {
+			t.Fatalf("runPolicySet: %v", err)
+		}
+	})
+	if !strings.Contains(output, "Policy set to off (ttl=45m0s)") || !strings.Contains(output, "Applied to the running gateway") {
+		t.Fatalf("unexpected output: %s", output)
+	}
+	if reloads != 1 {
+		t.Fatalf("expected one gateway reload, got %d", reloads)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	if cfg.Policy.Mode != "off" || cfg.Policy.OffTTL != "45m0s" {
+		t.Fatalf("expected off with ttl saved, got mode=%q off_ttl=%q", cfg.Policy.Mode, cfg.Policy.OffTTL)
+	}
+	events := readPolicyAuditEvents(t, workspacePath)
+	if len(events) == 0 || events[len(events)-1].Type != "policy_cli_switch" {
+		t.Fatalf("expected policy_cli_switch audit event, got %+v", events)
+	}
+}
+
+func TestPolicySet_PersistentOffRequiresAllowFlag(t *testing.T) {
+	preparePolicyWorkspace(t)
+
+	orig := policyReloadGateway
+	policyReloadGateway = func(ctx context.Context, gw config.GatewayConfig) error {
+		return errors.New("connection refused")
+	}
+	t.Cleanup(func() { policyReloadGateway = orig })
+
+	if err := runPolicySet(newPolicySetCmd(), []string{"off"}); err == nil || !strings.Contains(err.Error(), "allow_persistent_off") {
+		t.Fatalf("expected allow_persistent_off error, got %v", err)
+	}
+
+	cmd := newPolicySetCmd()
+	_ = cmd.Flags().Set("ttl", "10m")
+	if err := runPolicySet(cmd, []string{"relaxed"}); err == nil {
+		t.Fatal("expected --ttl to be rejected for relaxed mode")
+	}
+
+	output := captureOutput(t, func() {
+		if err := runPolicySet(newPolicySetCmd(), []string{"relaxed"}); err != nil {
+			t.Fatalf("runPolicySet: %v", err)
+		}
+	})
+	if !strings.Contains(output, "applies on next start") {
+		t.Fatalf("expected unreachable gateway note, got: %s", output)
+	}
+}
+
 func TestPolicyCommand_RegisteredInRoot(t *testing.T) {
 	root := NewRootCmd()
 	found, _, err := root.Find([]string{"policy", "status"})
@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/agent"
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/heartbeat"
+	"github.com/MEKXH/golem/internal/provider"
+	"github.com/MEKXH/golem/internal/voice"
+)
+
+// configReloader 在服务运行期间重新加载配置，并将可热更新的字段应用到各运行组件。
+// 可热更新：channels.outbound、log、heartbeat、policy 以及各通道的 enabled 开关；
+// 其余字段（agents、providers、gateway、mcp、tools 及通道凭据）需要重启后生效。
+type configReloader struct {
+	mu          sync.Mutex
+	current     *config.Config
+	load        func() (*config.Config, error)
+	runCtx      context.Context // 服务生命周期上下文，新启动的通道绑定到它而非触发重载的请求
+	loop        *agent.Loop
+	chanMgr     *channel.Manager
+	heartbeat   *heartbeat.Service
+	msgBus      *bus.MessageBus
+	transcriber voice.Transcriber
+}
+
+// reloadResult 汇总一次配置重载实际应用和需要重启的变更。
+type reloadResult struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+	ChannelsStarted []string `json:"channels_started"`
+	ChannelsStopped []string `json:"channels_stopped"`
+}
+
+// Reload 重新读取并校验配置；任何校验失败都会直接返回，正在运行的配置保持不变。
+func (r *configReloader) Reload(ctx context.Context) (*reloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if err := provider.ValidateChannelModels(next); err != nil {
+		return nil, err
+	}
+	if _, err := parseLogLevel(next.Log.Level, logLevelOverride); err != nil {
+		return nil, err
+	}
+
+	prev := r.current
+	result := &reloadResult{
+		Applied:         []string{},
+		RestartRequired: restartRequiredChanges(prev, next),
+		ChannelsStarted: []string{},
+		ChannelsStopped: []string{},
+	}
+
+	// 先应用可能失败的变更，失败时回滚已应用的部分，保证重载的原子性。
+	logChanged := !reflect.DeepEqual(prev.Log, next.Log)
+	if logChanged {
+		if err := configureLogger(next, logLevelOverride, false); err != nil {
+			return nil, fmt.Errorf("apply log config: %w", err)
+		}
+		result.Applied = append(result.Applied, "log")
+	}
+	if !reflect.DeepEqual(prev.Policy, next.Policy) || !reflect.DeepEqual(prev.Channels.ToolPolicies(), next.Channels.ToolPolicies()) {
+		if err := r.loop.ReloadPolicy(next); err != nil {
+			if logChanged {
+				_ = configureLogger(prev, logLevelOverride, false)
+			}
+			return nil, fmt.Errorf("apply policy config: %w", err)
+		}
+		result.Applied = append(result.Applied, "policy")
+	}
+
+	if !reflect.DeepEqual(prev.Channels.Outbound, next.Channels.Outbound) {
+		r.chanMgr.SetDeliveryPolicy(buildOutboundDeliveryPolicy(next))
+		result.Applied = append(result.Applied, "channels.outbound")
+	}
+	if !reflect.DeepEqual(prev.Channels.Inbound, next.Channels.Inbound) {
+		r.loop.ReloadInbound(next.Channels.Inbound)
+		result.Applied = append(result.Applied, "channels.inbound")
+	}
+	if !reflect.DeepEqual(prev.Channels.Reactions, next.Channels.Reactions) {
+		r.loop.ReloadReactions(next.Channels.Reactions)
+		result.Applied = append(result.Applied, "channels.reactions")
+	}
+	if !reflect.DeepEqual(prev.Heartbeat, next.Heartbeat) && r.heartbeat != nil {
+		if err := r.heartbeat.UpdateConfig(heartbeatConfig(next)); err != nil {
+			slog.Warn("heartbeat service failed to restart after reload", "error", err)
+		}
+		result.Applied = append(result.Applied, "heartbeat")
+	}
+	r.reconcileChannels(ctx, next, result)
+
+	r.current = runningConfig(prev, next, result.RestartRequired)
+	slog.Info("config reloaded",
+		"applied", result.Applied,
+		"restart_required", result.RestartRequired,
+		"channels_started", result.ChannelsStarted,
+		"channels_stopped", result.ChannelsStopped,
+	)
+	return result, nil
+}
+
+// reconcileChannels 按新配置启动新启用的通道，并停止已禁用的通道。
+func (r *configReloader) reconcileChannels(ctx context.Context, next *config.Config, result *reloadResult) {
+	desired := map[string]channel.Channel{}
+	for _, ch := range buildEnabledChannels(next, r.msgBus, r.transcriber) {
+		desired[ch.Name()] = ch
+	}
+
+	running := r.chanMgr.Names()
+	slices.Sort(running)
+	for _, name := range running {
+		if _, ok := desired[name]; ok {
+			continue
+		}
+		stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := r.chanMgr.Unregister(stopCtx, name); err != nil {
+			slog.Warn("failed to stop channel during reload", "name", name, "error", err)
+		}
+		cancel()
+		result.ChannelsStopped = append(result.ChannelsStopped, name)
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if slices.Contains(running, name) {
+			continue
+		}
+		r.chanMgr.Register(desired[name])
+		if err := r.chanMgr.Start(r.runCtx, name); err != nil {
+			slog.Warn("failed to start channel during reload", "name", name, "error", err)
+			continue
+		}
+		result.ChannelsStarted = append(result.ChannelsStarted, name)
+	}
+}
+
+// restartRequiredChanges 列出发生变化但只能在重启后生效的配置段。
+func restartRequiredChanges(prev, next *config.Config) []string {
+	changed := []string{}
+	sections := []struct {
+		name       string
+		prev, next any
+	}{
+		{"agents", prev.Agents, next.Agents},
+		{"providers", prev.Providers, next.Providers},
+		{"gateway", prev.Gateway, next.Gateway},
+		{"http", prev.HTTP, next.HTTP},
+		{"mcp", prev.MCP, next.MCP},
+		{"tools", prev.Tools, next.Tools},
+	}
+	for _, s := range sections {
+		if !reflect.DeepEqual(s.prev, s.next) {
+			changed = append(changed, s.name)
+		}
+	}
+
+	// 通道启停可以热更新，但已运行通道的凭据或连接参数变化需要重启。
+	prevChannels, nextChannels := channelSettings(prev), channelSettings(next)
+	names := make([]string, 0, len(prevChannels))
+	for name := range prevChannels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		before, after := prevChannels[name], nextChannels[name]
+		if before.enabled && after.enabled && !reflect.DeepEqual(before.settings, after.settings) {
+			changed = append(changed, "channels."+name)
+		}
+	}
+	return changed
+}
+
+// runningConfig 返回重载后实际生效的配置：需要重启的配置段保留 prev 中正在运行的值，
+// 使下一次重载仍与运行中的配置比较，在重启前持续报告这些变更。
+func runningConfig(prev, next *config.Config, restartRequired []string) *config.Config {
+	running := *next
+	for _, section := range restartRequired {
+		switch section {
+		case "agents":
+			running.Agents = prev.Agents
+		case "providers":
+			running.Providers = prev.Providers
+		case "gateway":
+			running.Gateway = prev.Gateway
+		case "http":
+			running.HTTP = prev.HTTP
+		case "mcp":
+			running.MCP = prev.MCP
+		case "tools":
+			running.Tools = prev.Tools
+		default:
+			if name, ok := strings.CutPrefix(section, "channels."); ok {
+				keepChannelSettings(&running.Channels, prev.Channels, name)
+			}
+		}
+	}
+	return &running
+}
+
+// keepChannelSettings 将 running 中名为 name 的通道配置恢复为 prev 的值；tool_policy 已热更新，保留新值。
+func keepChannelSettings(running *config.ChannelsConfig, prev config.ChannelsConfig, name string) {
+	rv := reflect.ValueOf(running).Elem()
+	pv := reflect.ValueOf(prev)
+	for i := 0; i < rv.NumField(); i++ {
+		if rv.Type().Field(i).Tag.Get("mapstructure") != name {
+			continue
+		}
+		field := rv.Field(i)
+		toolPolicy := field.FieldByName("ToolPolicy").Interface()
+		field.Set(pv.Field(i))
+		field.FieldByName("ToolPolicy").Set(reflect.ValueOf(toolPolicy))
+		return
+	}
+}
+
+type channelSetting struct {
+	enabled  bool
+	settings any
+}
+
+func channelSettings(cfg *config.Config) map[string]channelSetting {
+	c := cfg.Channels
+	// tool_policy 随 policy 一起热更新，不计入需要重启的通道设置。
+	c.Telegram.ToolPolicy = config.ToolPolicyConfig{}
+	c.WhatsApp.ToolPolicy = config.ToolPolicyConfig{}
+	c.Feishu.ToolPolicy = config.ToolPolicyConfig{}
+	c.Discord.ToolPolicy = config.ToolPolicyConfig{}
+	c.Slack.ToolPolicy = config.ToolPolicyConfig{}
+	c.QQ.ToolPolicy = config.ToolPolicyConfig{}
+	c.DingTalk.ToolPolicy = config.ToolPolicyConfig{}
+	c.MaixCam.ToolPolicy = config.ToolPolicyConfig{}
+	c.Mattermost.ToolPolicy = config.ToolPolicyConfig{}
+	c.Matrix.ToolPolicy = config.ToolPolicyConfig{}
+	c.Twilio.ToolPolicy = config.ToolPolicyConfig{}
+	return map[string]channelSetting{
+		"telegram":   {c.Telegram.Enabled, c.Telegram},
+		"whatsapp":   {c.WhatsApp.Enabled, c.WhatsApp},
+		"feishu":     {c.Feishu.Enabled, c.Feishu},
+		"discord":    {c.Discord.Enabled, c.Discord},
+		"slack":      {c.Slack.Enabled, c.Slack},
+		"qq":         {c.QQ.Enabled, c.QQ},
+		"dingtalk":   {c.DingTalk.Enabled, c.DingTalk},
+		"maixcam":    {c.MaixCam.Enabled, c.MaixCam},
+		"mattermost": {c.Mattermost.Enabled, c.Mattermost},
+		"matrix":     {c.Matrix.Enabled, c.Matrix},
+		"twilio":     {c.Twilio.Enabled, c.Twilio},
+	}
+}
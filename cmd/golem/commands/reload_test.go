@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/agent"
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+type reloadStubChannel struct {
+	channel.BaseChannel
+	name    string
+	stopped bool
+}
+
+func (c *reloadStubChannel) Name() string                    { return c.name }
+func (c *reloadStubChannel) Start(ctx context.Context) error { return nil }
+func (c *reloadStubChannel) Stop(ctx context.Context) error  { c.stopped = true; return nil }
+func (c *reloadStubChannel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
+	return nil
+}
+
+func newTestReloader(t *testing.T, cfg *config.Config, next func() (*config.Config, error)) (*configReloader, *channel.Manager) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	msgBus := bus.NewMessageBus(10)
+	loop, err := agent.NewLoop(cfg, msgBus, nil)
+	if err != nil {
+		t.Fatalf("NewLoop error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools error: %v", err)
+	}
+	chanMgr := channel.NewManagerWithPolicy(msgBus, buildOutboundDeliveryPolicy(cfg))
+	return &configReloader{
+		current: cfg,
+		load:    next,
+		runCtx:  context.Background(),
+		loop:    loop,
+		chanMgr: chanMgr,
+		msgBus:  msgBus,
+	}, chanMgr
+}
+
+func TestConfigReloader_AppliesOutboundPolicyAndStopsDisabledChannels(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.Enabled = true
+	cfg.Channels.Telegram.Token = "token"
+
+	next := config.DefaultConfig()
+	next.Channels.Outbound.RetryMaxAttempts = 7
+	next.Agents.Defaults.Model = "openai/gpt-4o"
+
+	reloader, chanMgr := newTestReloader(t, cfg, func() (*config.Config, error) { return next, nil })
+	stub := &reloadStubChannel{name: "telegram"}
+	chanMgr.Register(stub)
+
+	result, err := reloader.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+	if got := chanMgr.Policy().RetryMaxAttempts; got != 7 {
+		t.Fatalf("expected retry attempts 7, got %d", got)
+	}
+	if !slices.Contains(result.Applied, "channels.outbound") {
+		t.Fatalf("expected outbound policy to be applied, got %v", result.Applied)
+	}
+	if !stub.stopped || !slices.Equal(result.ChannelsStopped, []string{"telegram"}) {
+		t.Fatalf("expected telegram to be stopped, got %v", result.ChannelsStopped)
+	}
+	if !slices.Contains(result.RestartRequired, "agents") {
+		t.Fatalf("expected agents change to require restart, got %v", result.RestartRequired)
+	}
+	if reloader.current.Channels.Outbound.RetryMaxAttempts != 7 {
+		t.Fatal("expected applied sections to become current")
+	}
+	if reloader.current.Agents.Defaults.Model != cfg.Agents.Defaults.Model {
+		t.Fatalf("expected restart-required agents to keep the running value, got %q", reloader.current.Agents.Defaults.Model)
+	}
+}
+
+func TestConfigReloader_KeepsReportingRestartRequiredChanges(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.Enabled = true
+	cfg.Channels.Telegram.Token = "old-token"
+
+	next := config.DefaultConfig()
+	next.Channels.Telegram.Enabled = true
+	next.Channels.Telegram.Token = "new-token"
+	next.Channels.Telegram.ToolPolicy.Deny = []string{"exec"}
+	next.Gateway.Port = cfg.Gateway.Port + 1
+
+	reloader, chanMgr := newTestReloader(t, cfg, func() (*config.Config, error) { return next, nil })
+	chanMgr.Register(&reloadStubChannel{name: "telegram"})
+
+	for i := 0; i < 2; i++ {
+		result, err := reloader.Reload(context.Background())
+		if err != nil {
+			t.Fatalf("Reload %d error: %v", i+1, err)
+		}
+		if !slices.Equal(result.RestartRequired, []string{"gateway", "channels.telegram"}) {
+			t.Fatalf("reload %d: expected pending restart changes to be reported, got %v", i+1, result.RestartRequired)
+		}
+	}
+	running := reloader.current.Channels.Telegram
+	if running.Token != "old-token" || !slices.Equal(running.ToolPolicy.Deny, []string{"exec"}) {
+		t.Fatalf("expected running telegram settings with the reloaded tool policy, got %+v", running)
+	}
+}
+
+func TestConfigReloader_ValidationFailureKeepsRunningConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Channels.Telegram.Enabled = true
+	cfg.Channels.Telegram.Token = "token"
+
+	reloader, chanMgr := newTestReloader(t, cfg, func() (*config.Config, error) {
+		return nil, errors.New("channels.outbound.retry_max_attempts must not be negative")
+	})
+	stub := &reloadStubChannel{name: "telegram"}
+	chanMgr.Register(stub)
+	before := chanMgr.Policy()
+
+	if _, err := reloader.Reload(context.Background()); err == nil {
+		t.Fatal("expected reload to fail")
+	}
+	if reloader.current != cfg {
+		t.Fatal("expected running config to stay untouched")
+	}
+	if chanMgr.Policy() != before || stub.stopped {
+		t.Fatal("expected no live changes after a failed reload")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +26,7 @@ func NewRootCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			httpclient.Configure(cfg.HTTP)
 			return configureLogger(cfg, logLevelOverride, cmd.Name() == "chat")
 		},
 	}
@@ -41,8 +43,11 @@ func NewRootCmd() *cobra.Command {
 		NewMCPCmd(),
 		NewChannelsCmd(),
 		NewApprovalCmd(),
+		NewAuditCmd(),
 		NewCronCmd(),
 		NewSkillsCmd(),
+		NewMemoryCmd(),
+		NewUsageCmd(),
 		NewAuthCmd(),
 		NewVersionCmd(),
 	)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
@@ -20,19 +21,24 @@ import (
 	"github.com/MEKXH/golem/internal/channel/discord"
 	"github.com/MEKXH/golem/internal/channel/feishu"
 	"github.com/MEKXH/golem/internal/channel/maixcam"
+	"github.com/MEKXH/golem/internal/channel/matrix"
+	"github.com/MEKXH/golem/internal/channel/mattermost"
 	"github.com/MEKXH/golem/internal/channel/qq"
 	"github.com/MEKXH/golem/internal/channel/slack"
 	"github.com/MEKXH/golem/internal/channel/telegram"
+	"github.com/MEKXH/golem/internal/channel/twilio"
 	"github.com/MEKXH/golem/internal/channel/whatsapp"
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/cron"
 	"github.com/MEKXH/golem/internal/gateway"
 	"github.com/MEKXH/golem/internal/heartbeat"
+	"github.com/MEKXH/golem/internal/mcp"
 	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/provider"
 	"github.com/MEKXH/golem/internal/state"
 	"github.com/MEKXH/golem/internal/tools"
 	"github.com/MEKXH/golem/internal/voice"
+	einomodel "github.com/cloudwego/eino/components/model"
 	"github.com/spf13/cobra"
 )
 
@@ -68,11 +74,18 @@ func runServer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		slog.Warn("no model configured", "error", err)
 	}
+	channelModels, err := provider.NewChannelChatModels(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("invalid channel model override: %w", err)
+	}
 
 	loop, err := agent.NewLoop(cfg, msgBus, model)
 	if err != nil {
 		return fmt.Errorf("invalid workspace: %w", err)
 	}
+	loop.SetChannelModels(channelModels)
+	loop.SetModelFactory(sessionModelFactory(cfg))
+	configureMemoryEmbedder(ctx, cfg, loop)
 	// 注册默认工具集
 	if err := loop.RegisterDefaultTools(cfg); err != nil {
 		return err
@@ -83,20 +96,13 @@ func runServer(cmd *cobra.Command, args []string) error {
 	loop.SetRuntimeMetrics(runtimeMetrics)
 	logAndAuditRuntimePolicyStartup(ctx, loop, cfg)
 
+	// 通道管理器先于定时任务创建，任务结果按运行时已注册的通道投递
+	chanMgr := channel.NewManagerWithPolicy(msgBus, buildOutboundDeliveryPolicy(cfg))
+	chanMgr.SetRuntimeMetrics(runtimeMetrics)
+
 	// 1. 初始化定时任务服务 (Cron Service)
 	cronStorePath := filepath.Join(workspacePath, "cron", "jobs.json")
-	cronService := cron.NewService(cronStorePath, func(job *cron.Job) error {
-		ch := job.Payload.Channel
-		if ch == "" {
-			ch = "cron"
-		}
-		chatID := job.Payload.ChatID
-		if chatID == "" {
-			chatID = "default"
-		}
-		_, err := loop.ProcessForChannel(ctx, ch, chatID, "cron", job.Payload.Message)
-		return err
-	})
+	cronService := cron.NewService(cronStorePath, newCronJobHandler(ctx, loop.ProcessForChannel, msgBus, chanMgr.Has))
 	// 注册 Cron 管理工具
 	cronTool, err := tools.NewCronTool(cronService)
 	if err != nil {
@@ -116,6 +122,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 	if err := heartbeatService.Start(); err != nil {
 		slog.Warn("heartbeat service failed to start", "error", err)
 	}
+	go runDiaryPruner(ctx, workspacePath, cfg.Agents.Memory)
 
 	errCh := make(chan error, 2)
 	// 3. 运行 Agent 主循环
@@ -127,15 +134,64 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	// 4. 初始化消息通道 (Channels)
 	voiceTranscriber := buildVoiceTranscriber(cfg)
-	chanMgr := channel.NewManagerWithPolicy(msgBus, buildOutboundDeliveryPolicy(cfg))
-	chanMgr.SetRuntimeMetrics(runtimeMetrics)
+	loop.SetTypingNotifier(chanMgr.SendTyping)
+	loop.SetReactionNotifier(chanMgr.React)
+	loop.SetAttachmentLimit(chanMgr.AttachmentLimit)
+	loop.SetApprovalNotifier(func(msg *bus.OutboundMessage) {
+		if chanMgr.Has(msg.Channel) {
+			msgBus.PublishOutbound(msg)
+		}
+	})
 	registerEnabledChannels(cfg, msgBus, chanMgr, voiceTranscriber)
 
 	chanMgr.StartAll(ctx)
 	go chanMgr.RouteOutbound(ctx)
 
-	// 5. 启动网关服务器 (Gateway Server)
-	gatewayServer := gateway.New(cfg.Gateway, loop)
+	// 5. 配置热重载：SIGHUP 或网关 POST /reload 触发
+	reloader := &configReloader{
+		current:     cfg,
+		load:        config.Load,
+		runCtx:      ctx,
+		loop:        loop,
+		chanMgr:     chanMgr,
+		heartbeat:   heartbeatService,
+		msgBus:      msgBus,
+		transcriber: voiceTranscriber,
+	}
+	go watchReloadSignal(ctx, reloader)
+
+	// 6. 启动网关服务器 (Gateway Server)
+	gatewayServer := gateway.NewWithOptions(cfg.Gateway, loop, gateway.HandlerOptions{
+		Metrics: runtimeMetrics.WritePrometheus,
+		Ready: func() []gateway.ComponentStatus {
+			return runtimeReadiness(loop, chanMgr.Statuses())
+		},
+		Reload: func(ctx context.Context) (any, error) {
+			return reloader.Reload(ctx)
+		},
+		MCPStatus: func() any {
+			return loop.MCPStatuses()
+		},
+		MCPReconnect: func(ctx context.Context, server string, all bool) (any, error) {
+			if all {
+				return loop.ReconnectDegradedMCP(ctx)
+			}
+			status, err := loop.ReconnectMCP(ctx, server)
+			if err != nil {
+				return nil, err
+			}
+			return []mcp.ServerStatus{status}, nil
+		},
+		MCPLogs: func() any {
+			return loop.MCPLogs()
+		},
+		ToolStatus: func() any {
+			return loop.ToolBreakers()
+		},
+		SkillsReload: func(ctx context.Context) (any, error) {
+			return loop.ReloadSkills(), nil
+		},
+	})
 	go func() {
 		if err := gatewayServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- fmt.Errorf("gateway server failed: %w", err)
@@ -167,13 +223,76 @@ func runServer(cmd *cobra.Command, args []string) error {
 	return runErr
 }
 
+// sessionModelFactory 返回 /model 切换会话模型时使用的工厂；供应商配置的变更需要重启才会生效。
+func sessionModelFactory(cfg *config.Config) agent.ModelFactory {
+	return func(ctx context.Context, modelName string) (einomodel.ChatModel, error) {
+		return provider.NewChatModelFor(ctx, cfg, modelName)
+	}
+}
+
+// configureMemoryEmbedder 在 agents.memory.semantic_recall 启用时为记忆召回创建向量模型；
+// 创建失败只记录告警，召回退回关键词匹配。
+func configureMemoryEmbedder(ctx context.Context, cfg *config.Config, loop *agent.Loop) {
+	if !cfg.Agents.Memory.SemanticRecall {
+		return
+	}
+	embedder, err := provider.NewEmbedder(ctx, cfg)
+	if err != nil {
+		slog.Warn("semantic memory recall disabled; using keyword recall", "error", err)
+		return
+	}
+	loop.SetMemoryEmbedder(embedder)
+	slog.Info("semantic memory recall enabled", "embedding_model", cfg.Agents.Memory.EmbeddingModel)
+}
+
+// runtimeReadiness 汇总网关 /readyz 的组件状态：至少配置一个模型、Agent 主循环正在消费消息总线、
+// 所有已启用通道均未报告启动失败。通道错误详情只写入日志，避免未鉴权的探针接口泄露凭据。
+func runtimeReadiness(loop interface {
+	HasModel() bool
+	Running() bool
+}, channels []channel.Status) []gateway.ComponentStatus {
+	components := []gateway.ComponentStatus{
+		{Name: "provider", Ready: loop.HasModel()},
+		{Name: "agent_loop", Ready: loop.Running()},
+	}
+	if !components[0].Ready {
+		components[0].Message = "no provider configured"
+	}
+	if !components[1].Ready {
+		components[1].Message = "agent loop is not consuming the message bus"
+	}
+	for _, ch := range channels {
+		status := gateway.ComponentStatus{Name: "channel:" + ch.Name, Ready: ch.Ready}
+		if !ch.Ready {
+			status.Message = "start failed; see logs"
+		}
+		components = append(components, status)
+	}
+	return components
+}
+
+// watchReloadSignal 在收到 SIGHUP 时重新加载配置，直到服务退出。
+func watchReloadSignal(ctx context.Context, reloader *configReloader) {
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupCh:
+			slog.Info("received SIGHUP; reloading config")
+			if _, err := reloader.Reload(ctx); err != nil {
+				slog.Error("config reload failed; keeping running config", "error", err)
+			}
+		}
+	}
+}
+
 func buildHeartbeatService(cfg *config.Config, msgBus *bus.MessageBus, cronService *cron.Service, stateManager *state.Manager) *heartbeat.Service {
 	return heartbeat.NewService(
-		heartbeat.Config{
-			Enabled:  cfg.Heartbeat.Enabled,
-			Interval: time.Duration(cfg.Heartbeat.Interval) * time.Minute,
-			MaxIdle:  time.Duration(cfg.Heartbeat.MaxIdleMinutes) * time.Minute,
-		},
+		heartbeatConfig(cfg),
 		func(ctx context.Context) (string, error) {
 			status := cronService.Status()
 
@@ -198,6 +317,14 @@ func buildHeartbeatService(cfg *config.Config, msgBus *bus.MessageBus, cronServi
 	)
 }
 
+func heartbeatConfig(cfg *config.Config) heartbeat.Config {
+	return heartbeat.Config{
+		Enabled:  cfg.Heartbeat.Enabled,
+		Interval: time.Duration(cfg.Heartbeat.Interval) * time.Minute,
+		MaxIdle:  time.Duration(cfg.Heartbeat.MaxIdleMinutes) * time.Minute,
+	}
+}
+
 func buildVoiceTranscriber(cfg *config.Config) voice.Transcriber {
 	if cfg == nil || !cfg.Tools.Voice.Enabled {
 		return nil
@@ -207,29 +334,82 @@ func buildVoiceTranscriber(cfg *config.Config) voice.Transcriber {
 	if provider == "" {
 		provider = "openai"
 	}
-	if provider != "openai" {
-		slog.Warn("voice transcription provider is not supported", "provider", provider)
+	apiKey, baseURL, ok := resolveVoiceEndpoint(cfg, provider)
+	if !ok {
+		slog.Warn("voice transcription enabled but openai credentials are missing")
+		return nil
+	}
+	opts := voice.Options{
+		Provider: provider,
+		APIKey:   apiKey,
+		BaseURL:  baseURL,
+		Model:    cfg.Tools.Voice.Model,
+		Timeout:  time.Duration(cfg.Tools.Voice.TimeoutSeconds) * time.Second,
+	}
+
+	tr, err := voice.New(opts)
+	if err != nil {
+		slog.Warn("failed to initialize voice transcriber", "provider", provider, "error", err)
+		return nil
+	}
+	return tr
+}
+
+// buildVoiceSynthesizer 为开启了 voice_replies 的通道构建语音合成客户端，与转录共用 tools.voice 的服务与凭据。
+func buildVoiceSynthesizer(cfg *config.Config) voice.Synthesizer {
+	if cfg == nil || !cfg.Tools.Voice.Enabled {
+		return nil
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(cfg.Tools.Voice.Provider))
+	if provider == "" {
+		provider = "openai"
+	}
+	apiKey, baseURL, ok := resolveVoiceEndpoint(cfg, provider)
+	if !ok {
+		slog.Warn("voice replies enabled but openai credentials are missing")
 		return nil
 	}
 
-	apiKey := strings.TrimSpace(cfg.Providers.OpenAI.APIKey)
+	synth, err := voice.NewSynthesizer(voice.SpeechOptions{
+		Provider: provider,
+		APIKey:   apiKey,
+		BaseURL:  baseURL,
+		Model:    cfg.Tools.Voice.TTSModel,
+		Voice:    cfg.Tools.Voice.TTSVoice,
+		Timeout:  time.Duration(cfg.Tools.Voice.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		slog.Warn("failed to initialize voice synthesizer", "provider", provider, "error", err)
+		return nil
+	}
+	return synth
+}
+
+// resolveVoiceEndpoint 返回语音服务的 API Key 与 base_url。openai 未单独配置时依次回退到
+// providers.openai 与认证存储中的凭据；找不到凭据时 ok 为 false。local 的 api_key 可为空。
+func resolveVoiceEndpoint(cfg *config.Config, provider string) (apiKey, baseURL string, ok bool) {
+	apiKey = strings.TrimSpace(cfg.Tools.Voice.APIKey)
+	baseURL = strings.TrimSpace(cfg.Tools.Voice.BaseURL)
+	if provider != "openai" {
+		return apiKey, baseURL, true
+	}
+
+	if apiKey == "" {
+		apiKey = strings.TrimSpace(cfg.Providers.OpenAI.APIKey)
+	}
 	if apiKey == "" {
 		if cred, err := auth.GetCredential("openai"); err == nil && cred != nil {
 			apiKey = strings.TrimSpace(cred.AccessToken)
 		}
 	}
 	if apiKey == "" {
-		slog.Warn("voice transcription enabled but openai credentials are missing")
-		return nil
+		return "", "", false
 	}
-
-	timeout := time.Duration(cfg.Tools.Voice.TimeoutSeconds) * time.Second
-	tr, err := voice.NewOpenAITranscriber(apiKey, cfg.Providers.OpenAI.BaseURL, cfg.Tools.Voice.Model, timeout)
-	if err != nil {
-		slog.Warn("failed to initialize voice transcriber", "error", err)
-		return nil
+	if baseURL == "" {
+		baseURL = cfg.Providers.OpenAI.BaseURL
 	}
-	return tr
+	return apiKey, baseURL, true
 }
 
 func buildOutboundDeliveryPolicy(cfg *config.Config) channel.DeliveryPolicy {
@@ -248,19 +428,35 @@ func buildOutboundDeliveryPolicy(cfg *config.Config) channel.DeliveryPolicy {
 }
 
 func registerEnabledChannels(cfg *config.Config, msgBus *bus.MessageBus, chanMgr *channel.Manager, transcriber voice.Transcriber) {
-	register := func(ch channel.Channel) {
+	for _, ch := range buildEnabledChannels(cfg, msgBus, transcriber) {
 		chanMgr.Register(ch)
 		slog.Info("channel registered", "name", ch.Name())
 	}
+}
+
+// buildEnabledChannels 根据配置构造所有已启用且凭据齐全的通道实例。
+func buildEnabledChannels(cfg *config.Config, msgBus *bus.MessageBus, transcriber voice.Transcriber) []channel.Channel {
+	var channels []channel.Channel
+	register := func(ch channel.Channel) {
+		channels = append(channels, ch)
+	}
 	skip := func(name, reason string) {
 		slog.Warn("channel enabled but not ready; skipping registration", "name", name, "reason", reason)
 	}
+	var synthesizer voice.Synthesizer
+	if cfg.Channels.Telegram.VoiceReplies || cfg.Channels.Discord.VoiceReplies {
+		synthesizer = buildVoiceSynthesizer(cfg)
+	}
 
 	if cfg.Channels.Telegram.Enabled {
 		if cfg.Channels.Telegram.Token == "" {
 			skip("telegram", "token not set")
 		} else {
-			register(telegram.New(&cfg.Channels.Telegram, msgBus, transcriber))
+			ch := telegram.New(&cfg.Channels.Telegram, msgBus, transcriber)
+			if cfg.Channels.Telegram.VoiceReplies && synthesizer != nil {
+				ch.SetSynthesizer(synthesizer)
+			}
+			register(ch)
 		}
 	}
 
@@ -284,7 +480,11 @@ func registerEnabledChannels(cfg *config.Config, msgBus *bus.MessageBus, chanMgr
 		if cfg.Channels.Discord.Token == "" {
 			skip("discord", "token not set")
 		} else {
-			register(discord.New(&cfg.Channels.Discord, msgBus, transcriber))
+			ch := discord.New(&cfg.Channels.Discord, msgBus, transcriber)
+			if cfg.Channels.Discord.VoiceReplies && synthesizer != nil {
+				ch.SetSynthesizer(synthesizer)
+			}
+			register(ch)
 		}
 	}
 
@@ -319,4 +519,31 @@ func registerEnabledChannels(cfg *config.Config, msgBus *bus.MessageBus, chanMgr
 			register(maixcam.New(&cfg.Channels.MaixCam, msgBus))
 		}
 	}
+
+	if cfg.Channels.Mattermost.Enabled {
+		if cfg.Channels.Mattermost.URL == "" || cfg.Channels.Mattermost.Token == "" {
+			skip("mattermost", "url/token not set")
+		} else {
+			register(mattermost.New(&cfg.Channels.Mattermost, msgBus))
+		}
+	}
+
+	if cfg.Channels.Matrix.Enabled {
+		if cfg.Channels.Matrix.Homeserver == "" || cfg.Channels.Matrix.AccessToken == "" {
+			skip("matrix", "homeserver/access_token not set")
+		} else {
+			register(matrix.New(&cfg.Channels.Matrix, msgBus))
+		}
+	}
+
+	if cfg.Channels.Twilio.Enabled {
+		tw := cfg.Channels.Twilio
+		if tw.AccountSID == "" || tw.AuthToken == "" || tw.FromNumber == "" || tw.Port <= 0 {
+			skip("twilio", "account_sid/auth_token/from_number/port not set")
+		} else {
+			register(twilio.New(&cfg.Channels.Twilio, msgBus))
+		}
+	}
+
+	return channels
 }
@@ -172,6 +172,50 @@ func TestBuildVoiceTranscriber_UsesAuthStoreWhenAPIKeyMissing(t *testing.T) {
 	}
 }
 
+func TestBuildVoiceTranscriber_LocalProviderNeedsNoCredentials(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.Voice.Enabled = true
+	cfg.Tools.Voice.Provider = "local"
+	cfg.Tools.Voice.BaseURL = "http://127.0.0.1:8080/inference"
+	cfg.Providers.OpenAI.APIKey = ""
+
+	if got := buildVoiceTranscriber(cfg); got == nil {
+		t.Fatal("expected non-nil local transcriber without openai credentials")
+	}
+
+	cfg.Tools.Voice.BaseURL = ""
+	if got := buildVoiceTranscriber(cfg); got != nil {
+		t.Fatal("expected nil transcriber for local provider without base_url")
+	}
+}
+
+func TestBuildVoiceSynthesizer_SharesVoiceCredentials(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.Voice.Enabled = false
+	cfg.Providers.OpenAI.APIKey = "test-key"
+	if got := buildVoiceSynthesizer(cfg); got != nil {
+		t.Fatal("expected nil synthesizer when voice is disabled")
+	}
+
+	cfg.Tools.Voice.Enabled = true
+	if got := buildVoiceSynthesizer(cfg); got == nil {
+		t.Fatal("expected non-nil synthesizer with openai api key")
+	}
+
+	cfg.Providers.OpenAI.APIKey = ""
+	if got := buildVoiceSynthesizer(cfg); got != nil {
+		t.Fatal("expected nil synthesizer without openai credentials")
+	}
+}
+
 func TestBuildOutboundDeliveryPolicy_FromConfig(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Channels.Outbound.MaxConcurrentSends = 9
@@ -202,3 +246,31 @@ func TestBuildOutboundDeliveryPolicy_FromConfig(t *testing.T) {
 		t.Fatalf("expected DedupWindow=42s, got %s", policy.DedupWindow)
 	}
 }
+
+type readinessLoop struct{ model, running bool }
+
+func (l readinessLoop) HasModel() bool { return l.model }
+func (l readinessLoop) Running() bool  { return l.running }
+
+func TestRuntimeReadiness_ReportsComponents(t *testing.T) {
+	components := runtimeReadiness(readinessLoop{model: false, running: true}, []channel.Status{
+		{Name: "slack", Ready: true},
+		{Name: "telegram", Ready: false, Error: "telegram init failed: bot123:secret"},
+	})
+	if len(components) != 4 {
+		t.Fatalf("expected 4 components, got %+v", components)
+	}
+	if components[0].Name != "provider" || components[0].Ready || components[0].Message == "" {
+		t.Fatalf("expected provider not ready, got %+v", components[0])
+	}
+	if components[1].Name != "agent_loop" || !components[1].Ready {
+		t.Fatalf("expected agent loop ready, got %+v", components[1])
+	}
+	if components[2].Name != "channel:slack" || !components[2].Ready {
+		t.Fatalf("expected slack ready, got %+v", components[2])
+	}
+	bad := components[3]
+	if bad.Name != "channel:telegram" || bad.Ready || strings.Contains(bad.Message, "secret") {
+		t.Fatalf("expected telegram not ready without error details, got %+v", bad)
+	}
+}
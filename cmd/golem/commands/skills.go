@@ -2,7 +2,11 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -12,6 +16,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// skillsReloadTimeout 是通过网关重新加载技能的超时时间。
+const skillsReloadTimeout = 10 * time.Second
+
+var skillsGatewayReload = reloadSkillsViaGateway
+
 // NewSkillsCmd 创建技能管理子命令。
 func NewSkillsCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -25,6 +34,8 @@ func NewSkillsCmd() *cobra.Command {
 		newSkillsRemoveCmd(),
 		newSkillsShowCmd(),
 		newSkillsSearchCmd(),
+		newSkillsNewCmd(),
+		newSkillsReloadCmd(),
 	)
 
 	return cmd
@@ -64,7 +75,7 @@ func newSkillsRemoveCmd() *cobra.Command {
 func newSkillsShowCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "show <name>",
-		Short: "Show skill content",
+		Short: "Show skill manifest and content",
 		Args:  cobra.ExactArgs(1),
 		RunE:  runSkillsShow,
 	}
@@ -79,6 +90,26 @@ func newSkillsSearchCmd() *cobra.Command {
 	}
 }
 
+func newSkillsNewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new <name>",
+		Short: "Create a workspace skill from a template",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSkillsNew,
+	}
+	cmd.Flags().StringP("description", "d", "", "Skill description written to the manifest")
+	return cmd
+}
+
+func newSkillsReloadCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reload",
+		Short: "Reload skills in the running server",
+		Args:  cobra.NoArgs,
+		RunE:  runSkillsReload,
+	}
+}
+
 func loadSkillsLoader() (*skills.Loader, error) {
 	cfg, err := config.Load()
 	if err != nil {
@@ -203,15 +234,113 @@ func runSkillsShow(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	content, err := loader.LoadSkill(args[0])
+	info, ok := loader.FindSkill(args[0])
+	if !ok {
+		return fmt.Errorf("skill not found: %s", args[0])
+	}
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		return fmt.Errorf("read skill: %w", err)
+	}
+
+	fmt.Printf("Name:        %s\n", info.Name)
+	fmt.Printf("Source:      %s\n", info.Source)
+	fmt.Printf("Path:        %s\n", info.Path)
+	fmt.Printf("Description: %s\n", info.Description)
+	fmt.Println()
+	fmt.Println(string(data))
+	return nil
+}
+
+func runSkillsNew(cmd *cobra.Command, args []string) error {
+	description := ""
+	if cmd != nil {
+		description, _ = cmd.Flags().GetString("description")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	workspacePath, err := cfg.WorkspacePathChecked()
+	if err != nil {
+		return fmt.Errorf("invalid workspace: %w", err)
+	}
+
+	path, err := skills.NewInstaller(workspacePath).Create(args[0], description)
 	if err != nil {
 		return err
 	}
+	fmt.Printf("Skill '%s' created at %s\n", strings.TrimSpace(args[0]), path)
+	fmt.Println("Edit the file, then run 'golem skills reload' to load it into a running server.")
+	return nil
+}
+
+func runSkillsReload(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
 
-	fmt.Println(content)
+	// 运行中的服务缓存了系统提示词，需要通过网关让其重新扫描；网关不可达时只报告本地发现的技能。
+	ctx, cancel := context.WithTimeout(context.Background(), skillsReloadTimeout)
+	defer cancel()
+	list, err := skillsGatewayReload(ctx, cfg.Gateway)
+	switch {
+	case err == nil:
+		fmt.Printf("Reloaded %d skill(s) via gateway.\n", len(list))
+	case errors.Is(err, errGatewayUnavailable):
+		workspacePath, wsErr := cfg.WorkspacePathChecked()
+		if wsErr != nil {
+			return fmt.Errorf("invalid workspace: %w", wsErr)
+		}
+		list = skills.NewLoader(workspacePath).ListSkills()
+		fmt.Printf("No running gateway reachable; found %d skill(s). They will be loaded when the server or chat starts.\n", len(list))
+	default:
+		return err
+	}
+	for _, s := range list {
+		fmt.Printf("  - %s (%s)\n", s.Name, s.Source)
+	}
 	return nil
 }
 
+// reloadSkillsViaGateway 请求运行中的网关重新加载技能；无法连上网关时返回 errGatewayUnavailable。
+func reloadSkillsViaGateway(ctx context.Context, gw config.GatewayConfig) ([]skills.SkillInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gatewayBaseURL(gw)+"/skills/reload", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: skills reload endpoint not available", errGatewayUnavailable)
+	}
+
+	var body struct {
+		Skills  []skills.SkillInfo `json:"skills"`
+		Message string             `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode gateway response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(body.Message)
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("gateway skills reload failed: %s", msg)
+	}
+	return body.Skills, nil
+}
+
 func runSkillsSearch(cmd *cobra.Command, args []string) error {
 	cfg, err := config.Load()
 	if err != nil {
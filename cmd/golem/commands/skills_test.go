@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/skills"
 )
 
 func TestSkillsList_IncludesBuiltinSource(t *testing.T) {
@@ -178,3 +180,67 @@ description: "Demo skill from test server"
 		t.Fatalf("did not expect removed skill in list output, got: %s", outListAfterRemove)
 	}
 }
+
+func TestSkillsNewThenShowPrintsManifest(t *testing.T) {
+	workspacePath := prepareApprovalWorkspace(t)
+	t.Setenv("GOLEM_BUILTIN_SKILLS_DIR", t.TempDir())
+
+	cmd := newSkillsNewCmd()
+	if err := cmd.Flags().Set("description", "Summarize pull requests"); err != nil {
+		t.Fatalf("set description: %v", err)
+	}
+	captureOutput(t, func() {
+		if err := runSkillsNew(cmd, []string{"pr-digest"}); err != nil {
+			t.Fatalf("runSkillsNew: %v", err)
+		}
+	})
+	if _, err := os.Stat(filepath.Join(workspacePath, "skills", "pr-digest", "SKILL.md")); err != nil {
+		t.Fatalf("expected SKILL.md to be created: %v", err)
+	}
+	if err := runSkillsNew(newSkillsNewCmd(), []string{"pr-digest"}); err == nil {
+		t.Fatal("expected error when the skill already exists")
+	}
+
+	out := captureOutput(t, func() {
+		if err := runSkillsShow(nil, []string{"pr-digest"}); err != nil {
+			t.Fatalf("runSkillsShow: %v", err)
+		}
+	})
+	for _, want := range []string{"Source:      workspace", "Description: Summarize pull requests", "## When to use"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in show output, got: %s", want, out)
+		}
+	}
+}
+
+func TestSkillsReload_FallsBackWithoutGateway(t *testing.T) {
+	prepareApprovalWorkspace(t)
+	t.Setenv("GOLEM_BUILTIN_SKILLS_DIR", t.TempDir())
+
+	orig := skillsGatewayReload
+	skillsGatewayReload = func(ctx context.Context, gw config.GatewayConfig) ([]skills.SkillInfo, error) {
+		return nil, errGatewayUnavailable
+	}
+	defer func() { skillsGatewayReload = orig }()
+
+	out := captureOutput(t, func() {
+		if err := runSkillsReload(nil, nil); err != nil {
+			t.Fatalf("runSkillsReload: %v", err)
+		}
+	})
+	if !strings.Contains(out, "No running gateway reachable") {
+		t.Fatalf("expected fallback message, got: %s", out)
+	}
+
+	skillsGatewayReload = func(ctx context.Context, gw config.GatewayConfig) ([]skills.SkillInfo, error) {
+		return []skills.SkillInfo{{Name: "weather", Source: "builtin"}}, nil
+	}
+	out = captureOutput(t, func() {
+		if err := runSkillsReload(nil, nil); err != nil {
+			t.Fatalf("runSkillsReload: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Reloaded 1 skill(s) via gateway.") || !strings.Contains(out, "weather (builtin)") {
+		t.Fatalf("expected gateway reload output, got: %s", out)
+	}
+}
@@ -1,22 +1,35 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/cron"
+	"github.com/MEKXH/golem/internal/mcp"
 	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/skills"
+	"github.com/MEKXH/golem/internal/tools"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
 
+const statusGatewayTimeout = 2 * time.Second // 查询运行中网关状态的超时时间
+
+var (
+	statusFetchMCP          = fetchGatewayMCPStatus
+	statusFetchToolBreakers = fetchGatewayToolBreakers
+)
+
 func NewStatusCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "status",
@@ -102,10 +115,11 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("  %s: %s\n", keyStyle.Render("updated_at"), valStyle.Render(runtimeSnapshot.UpdatedAt.Format(time.RFC3339)))
 		fmt.Printf(
-			"  tool_total=%d tool_error_ratio=%.3f tool_timeout_ratio=%.3f tool_p95_proxy_ms=%d tool_avg_ms=%.1f\n",
+			"  tool_total=%d tool_error_ratio=%.3f tool_timeout_ratio=%.3f tool_invalid_args=%d tool_p95_proxy_ms=%d tool_avg_ms=%.1f\n",
 			runtimeSnapshot.Tool.Total,
 			runtimeSnapshot.Tool.ErrorRatio(),
 			runtimeSnapshot.Tool.TimeoutRatio(),
+			runtimeSnapshot.Tool.InvalidArgs,
 			runtimeSnapshot.Tool.P95ProxyLatencyMs,
 			runtimeSnapshot.Tool.AvgLatencyMs(),
 		)
@@ -115,6 +129,14 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			runtimeSnapshot.Channel.SendFailures,
 			runtimeSnapshot.Channel.FailureRatio(),
 		)
+		fmt.Printf(
+			"  model_calls=%d prompt_tokens=%d completion_tokens=%d total_tokens=%d avg_tokens_per_call=%.1f\n",
+			runtimeSnapshot.Model.Calls,
+			runtimeSnapshot.Model.PromptTokens,
+			runtimeSnapshot.Model.CompletionTokens,
+			runtimeSnapshot.Model.TotalTokens,
+			runtimeSnapshot.Model.AvgTokensPerCall(),
+		)
 	}
 
 	fmt.Println(sectionStyle.Render("Providers"))
@@ -145,8 +167,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// Tools
 	fmt.Println(sectionStyle.Render("Tools"))
 	tools := []string{
-		"read_file", "write_file", "edit_file", "append_file",
-		"list_dir", "read_memory", "write_memory", "append_diary",
+		"read_file", "write_file", "edit_file", "append_file", "move_file", "delete_file",
+		"list_dir", "search_files", "read_memory", "write_memory", "append_diary",
 		"web_fetch", "manage_cron", "workflow",
 	}
 	for _, t := range tools {
@@ -154,11 +176,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  %s: %s\n", keyStyle.Render("exec"), okStyle.Render(fmt.Sprintf("ready (timeout=%ds, restrict_to_workspace=%v)", cfg.Tools.Exec.Timeout, cfg.Tools.Exec.RestrictToWorkspace)))
 
-	webSearchStatus := okStyle.Render("enabled (DuckDuckGo fallback)")
-	if strings.TrimSpace(cfg.Tools.Web.Search.APIKey) != "" {
-		webSearchStatus = okStyle.Render("enabled (Brave + DuckDuckGo fallback)")
-	}
-	fmt.Printf("  %s: %s\n", keyStyle.Render("web_search"), webSearchStatus)
+	fmt.Printf("  %s: %s\n", keyStyle.Render("web_search"), okStyle.Render(webSearchStatus(cfg)))
 
 	voiceStatus := dimStyle.Render("disabled")
 	if cfg.Tools.Voice.Enabled {
@@ -171,6 +189,28 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  %s: %s\n", keyStyle.Render("voice_transcription"), voiceStatus)
 
+	breakerStatus := dimStyle.Render("disabled")
+	if cb := cfg.Tools.CircuitBreaker; cb.Enabled {
+		breakerStatus = okStyle.Render(circuitBreakerSummary(cb))
+	}
+	fmt.Printf("  %s: %s\n", keyStyle.Render("circuit_breaker"), breakerStatus)
+
+	// Policy
+	fmt.Println(sectionStyle.Render("Policy"))
+	policyReport := buildPolicyStatus(cfg, workspacePath, time.Now().UTC())
+	fmt.Printf("  %s: %s\n", keyStyle.Render("Mode"), valStyle.Render(policyReport.BaseMode))
+	effective := policyReport.EffectiveMode
+	if policyReport.OffRemaining != "" {
+		effective += fmt.Sprintf(" (%s remaining)", policyReport.OffRemaining)
+	} else if policyReport.OffExpired {
+		effective += " (off_ttl expired)"
+	}
+	if policyReport.EffectiveMode == "off" {
+		fmt.Printf("  %s: %s\n", keyStyle.Render("Effective"), warnStyle.Render(effective))
+	} else {
+		fmt.Printf("  %s: %s\n", keyStyle.Render("Effective"), okStyle.Render(effective))
+	}
+
 	// Channels
 	fmt.Println(sectionStyle.Render("Channels"))
 	for _, state := range channelStates(cfg) {
@@ -256,7 +296,10 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 		"write_file":          "ready",
 		"edit_file":           "ready",
 		"append_file":         "ready",
+		"move_file":           "ready",
+		"delete_file":         "ready",
 		"list_dir":            "ready",
+		"search_files":        "ready",
 		"read_memory":         "ready",
 		"write_memory":        "ready",
 		"append_diary":        "ready",
@@ -278,9 +321,10 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 		cfg.Tools.Exec.Timeout,
 		cfg.Tools.Exec.RestrictToWorkspace,
 	)
-	toolsState["web_search"] = "enabled (DuckDuckGo fallback)"
-	if strings.TrimSpace(cfg.Tools.Web.Search.APIKey) != "" {
-		toolsState["web_search"] = "enabled (Brave + DuckDuckGo fallback)"
+	toolsState["web_search"] = webSearchStatus(cfg)
+	toolsState["circuit_breaker"] = "disabled"
+	if cb := cfg.Tools.CircuitBreaker; cb.Enabled {
+		toolsState["circuit_breaker"] = circuitBreakerSummary(cb)
 	}
 
 	cronStorePath := filepath.Join(workspacePath, "cron", "jobs.json")
@@ -288,6 +332,7 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 	cronTotal := 0
 	cronEnabled := 0
 	cronStatus := "ok"
+	cronJobs := []map[string]any{}
 	if err := cronSvc.Start(); err == nil {
 		jobs := cronSvc.ListJobs(true)
 		cronTotal = len(jobs)
@@ -295,6 +340,7 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 			if j.Enabled {
 				cronEnabled++
 			}
+			cronJobs = append(cronJobs, cronJobStatus(j))
 		}
 		cronSvc.Stop()
 	} else {
@@ -333,6 +379,7 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 			"status":  cronStatus,
 			"total":   cronTotal,
 			"enabled": cronEnabled,
+			"jobs":    cronJobs,
 		},
 		"skills": map[string]any{
 			"installed": len(skillList),
@@ -342,8 +389,189 @@ func printStatusJSON(cfg *config.Config, workspacePath string, runtimeSnapshot m
 	if runtimeErr != nil {
 		payload["runtime_metrics_error"] = runtimeErr.Error()
 	}
+	payload["mcp"] = mcpStatusPayload(cfg)
+	payload["tool_breakers"] = toolBreakersPayload(cfg)
+	payload["policy"] = buildPolicyStatus(cfg, workspacePath, time.Now().UTC())
 
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(payload)
 }
+
+// cronJobStatus 汇总单个定时任务的状态、最近一次错误与运行历史。
+func cronJobStatus(j *cron.Job) map[string]any {
+	history := j.State.History
+	if history == nil {
+		history = []cron.RunRecord{}
+	}
+	status := map[string]any{
+		"id":          j.ShortID(),
+		"name":        j.Name,
+		"enabled":     j.Enabled,
+		"schedule":    j.ScheduleDescription(),
+		"catch_up":    string(j.EffectiveCatchUp()),
+		"last_status": j.State.LastStatus,
+		"last_error":  j.State.LastError,
+		"history":     history,
+	}
+	if j.State.NextRunAtMS != nil {
+		status["next_run"] = time.UnixMilli(*j.State.NextRunAtMS).Format(time.RFC3339)
+	}
+	if j.State.LastRunAtMS != nil {
+		status["last_run"] = time.UnixMilli(*j.State.LastRunAtMS).Format(time.RFC3339)
+	}
+	// last_error 在成功运行后会被清空，last_failure 保留历史中最近一次失败。
+	if failure, ok := j.State.LastFailure(); ok {
+		status["last_failure"] = failure
+	}
+	return status
+}
+
+// mcpStatusPayload 从运行中的网关读取 MCP 服务器实时状态；网关不可达时附带错误说明。
+func mcpStatusPayload(cfg *config.Config) map[string]any {
+	if len(cfg.MCP.Servers) == 0 {
+		return map[string]any{"servers": []mcp.ServerStatus{}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusGatewayTimeout)
+	defer cancel()
+
+	statuses, err := statusFetchMCP(ctx, cfg.Gateway)
+	if err != nil {
+		return map[string]any{
+			"servers": []mcp.ServerStatus{},
+			"error":   fmt.Sprintf("gateway unavailable: %v", err),
+		}
+	}
+	return map[string]any{"servers": statuses}
+}
+
+func fetchGatewayMCPStatus(ctx context.Context, gw config.GatewayConfig) ([]mcp.ServerStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gatewayBaseURL(gw)+"/mcp/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Servers []mcp.ServerStatus `json:"servers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode mcp status: %w", err)
+	}
+	return body.Servers, nil
+}
+
+// circuitBreakerSummary 描述工具熔断配置。
+func circuitBreakerSummary(cb config.CircuitBreakerConfig) string {
+	return fmt.Sprintf("enabled (failure_threshold=%d, window=%ds, cooldown=%ds)",
+		cb.FailureThreshold, cb.WindowSeconds, cb.CooldownSeconds)
+}
+
+// toolBreakersPayload 从运行中的网关读取工具熔断器实时状态；网关不可达时附带错误说明。
+func toolBreakersPayload(cfg *config.Config) map[string]any {
+	if !cfg.Tools.CircuitBreaker.Enabled {
+		return map[string]any{"breakers": []tools.BreakerStatus{}}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), statusGatewayTimeout)
+	defer cancel()
+
+	breakers, err := statusFetchToolBreakers(ctx, cfg.Gateway)
+	if err != nil {
+		return map[string]any{
+			"breakers": []tools.BreakerStatus{},
+			"error":    fmt.Sprintf("gateway unavailable: %v", err),
+		}
+	}
+	return map[string]any{"breakers": breakers}
+}
+
+func fetchGatewayToolBreakers(ctx context.Context, gw config.GatewayConfig) ([]tools.BreakerStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gatewayBaseURL(gw)+"/tools/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var body struct {
+		Breakers []tools.BreakerStatus `json:"breakers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode tool status: %w", err)
+	}
+	return body.Breakers, nil
+}
+
+// requestGatewayReload 调用网关 POST /reload，使已保存的配置在运行中的服务上生效。
+func requestGatewayReload(ctx context.Context, gw config.GatewayConfig) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gatewayBaseURL(gw)+"/reload", nil)
+	if err != nil {
+		return err
+	}
+	if token := strings.TrimSpace(gw.Token); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// gatewayBaseURL 返回本机访问网关的地址；监听全部地址时改用回环地址。
+func gatewayBaseURL(gw config.GatewayConfig) string {
+	host := strings.TrimSpace(gw.Host)
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	port := gw.Port
+	if port <= 0 {
+		port = 18790
+	}
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// webSearchStatus 描述 web_search 当前使用的搜索服务。
+func webSearchStatus(cfg *config.Config) string {
+	search := cfg.Tools.Web.Search
+	switch search.Provider {
+	case config.WebSearchProviderSearXNG:
+		return fmt.Sprintf("enabled (SearXNG %s)", search.SearXNGURL)
+	case config.WebSearchProviderDuckDuckGo:
+		return "enabled (DuckDuckGo)"
+	case config.WebSearchProviderGoogle:
+		return "enabled (Google + DuckDuckGo fallback)"
+	}
+	if strings.TrimSpace(search.APIKey) != "" {
+		return "enabled (Brave + DuckDuckGo fallback)"
+	}
+	return "enabled (DuckDuckGo fallback)"
+}
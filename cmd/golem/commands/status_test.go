@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -10,7 +12,10 @@ import (
 	"time"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/cron"
+	"github.com/MEKXH/golem/internal/mcp"
 	"github.com/MEKXH/golem/internal/metrics"
+	"github.com/MEKXH/golem/internal/tools"
 )
 
 var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
@@ -101,9 +106,10 @@ func TestStatusCommand_PrintsRuntimeMetricsSnapshot(t *testing.T) {
 
 	workspacePath := filepath.Join(tmpDir, ".golem", "workspace")
 	recorder := metrics.NewRuntimeMetrics(workspacePath)
-	_, _ = recorder.RecordToolExecution(123*time.Millisecond, "", nil)
-	_, _ = recorder.RecordToolExecution(2*time.Second, "", os.ErrDeadlineExceeded)
+	_, _ = recorder.RecordToolExecution(123*time.Millisecond, "", nil, false)
+	_, _ = recorder.RecordToolExecution(2*time.Second, "", os.ErrDeadlineExceeded, true)
 	_, _ = recorder.RecordChannelSend(false)
+	_, _ = recorder.RecordModelUsage(1200, 300)
 	recorder.Close()
 
 	output := captureOutput(t, func() {
@@ -123,6 +129,9 @@ func TestStatusCommand_PrintsRuntimeMetricsSnapshot(t *testing.T) {
 	if !strings.Contains(cleanOutput, "channel_send_failure_ratio=1.000") {
 		t.Fatalf("expected channel failure ratio in runtime metrics output, got: %s", cleanOutput)
 	}
+	if !strings.Contains(cleanOutput, "total_tokens=1500") {
+		t.Fatalf("expected model token usage in runtime metrics output, got: %s", cleanOutput)
+	}
 }
 
 func TestStatusCommand_JSONOutputIncludesRuntimeMetrics(t *testing.T) {
@@ -132,11 +141,12 @@ func TestStatusCommand_JSONOutputIncludesRuntimeMetrics(t *testing.T) {
 
 	workspacePath := filepath.Join(tmpDir, ".golem", "workspace")
 	recorder := metrics.NewRuntimeMetrics(workspacePath)
-	_, _ = recorder.RecordToolExecution(80*time.Millisecond, "", nil)
+	_, _ = recorder.RecordToolExecution(80*time.Millisecond, "", nil, false)
 	_, _ = recorder.RecordChannelSend(true)
 	_, _ = recorder.RecordMemoryRecall(2, map[string]int{
 		"diary_recent": 2,
 	})
+	_, _ = recorder.RecordModelUsage(40, 10)
 	recorder.Close()
 
 	cmd := NewStatusCmd()
@@ -174,6 +184,120 @@ func TestStatusCommand_JSONOutputIncludesRuntimeMetrics(t *testing.T) {
 	if !ok || toFloat64(memorySection["total_items"]) < 1 {
 		t.Fatalf("expected memory metrics in json output, got: %#v", runtimeMetrics["memory"])
 	}
+	modelSection, ok := runtimeMetrics["model"].(map[string]any)
+	if !ok || toFloat64(modelSection["total_tokens"]) != 50 {
+		t.Fatalf("expected model usage in json output, got: %#v", runtimeMetrics["model"])
+	}
+	policySection, ok := payload["policy"].(map[string]any)
+	if !ok || toString(policySection["effective_mode"]) == "" || toString(policySection["source"]) != "config" {
+		t.Fatalf("expected policy status in json output, got: %#v", payload["policy"])
+	}
+}
+
+func TestStatusCommand_JSONOutputIncludesMCPServers(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.MCP.Servers = map[string]config.MCPServerConfig{
+		"localfs": {Transport: "stdio", Command: "npx"},
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	reconnectedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	origFetch := statusFetchMCP
+	statusFetchMCP = func(ctx context.Context, gw config.GatewayConfig) ([]mcp.ServerStatus, error) {
+		return []mcp.ServerStatus{{
+			Name:              "localfs",
+			Transport:         "stdio",
+			Degraded:          true,
+			Message:           "reconnect failed",
+			LastReconnectAt:   reconnectedAt,
+			ReconnectAttempts: 3,
+		}}, nil
+	}
+	defer func() { statusFetchMCP = origFetch }()
+
+	cmd := NewStatusCmd()
+	if err := cmd.Flags().Set("json", "true"); err != nil {
+		t.Fatalf("set --json: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runStatus(cmd, nil); err != nil {
+			t.Fatalf("runStatus error: %v", err)
+		}
+	})
+
+	var payload struct {
+		MCP struct {
+			Servers []mcp.ServerStatus `json:"servers"`
+		} `json:"mcp"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid json output: %v, output=%s", err, output)
+	}
+	if len(payload.MCP.Servers) != 1 {
+		t.Fatalf("expected one mcp server, got %+v", payload.MCP.Servers)
+	}
+	got := payload.MCP.Servers[0]
+	if !got.Degraded || got.ReconnectAttempts != 3 || !got.LastReconnectAt.Equal(reconnectedAt) {
+		t.Fatalf("unexpected mcp status: %+v", got)
+	}
+}
+
+func TestStatusCommand_JSONOutputIncludesToolBreakers(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Tools.CircuitBreaker.Enabled = true
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	openedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	retryAt := openedAt.Add(30 * time.Second)
+	origFetch := statusFetchToolBreakers
+	statusFetchToolBreakers = func(ctx context.Context, gw config.GatewayConfig) ([]tools.BreakerStatus, error) {
+		return []tools.BreakerStatus{{
+			Tool:                "exec",
+			State:               tools.BreakerOpen,
+			ConsecutiveFailures: 5,
+			OpenedAt:            &openedAt,
+			RetryAt:             &retryAt,
+		}}, nil
+	}
+	defer func() { statusFetchToolBreakers = origFetch }()
+
+	cmd := NewStatusCmd()
+	if err := cmd.Flags().Set("json", "true"); err != nil {
+		t.Fatalf("set --json: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runStatus(cmd, nil); err != nil {
+			t.Fatalf("runStatus error: %v", err)
+		}
+	})
+
+	var payload struct {
+		ToolBreakers struct {
+			Breakers []tools.BreakerStatus `json:"breakers"`
+		} `json:"tool_breakers"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid json output: %v, output=%s", err, output)
+	}
+	if len(payload.ToolBreakers.Breakers) != 1 {
+		t.Fatalf("expected one tool breaker, got %+v", payload.ToolBreakers.Breakers)
+	}
+	got := payload.ToolBreakers.Breakers[0]
+	if got.Tool != "exec" || got.State != tools.BreakerOpen || got.RetryAt == nil || !got.RetryAt.Equal(retryAt) {
+		t.Fatalf("unexpected tool breaker status: %+v", got)
+	}
 }
 
 func toString(v any) string {
@@ -195,3 +319,57 @@ func toFloat64(v any) float64 {
 		return 0
 	}
 }
+
+func TestStatusCommand_JSONOutputIncludesCronRunHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	workspacePath := filepath.Join(tmpDir, ".golem", "workspace")
+	cronSvc := cron.NewService(filepath.Join(workspacePath, "cron", "jobs.json"), func(job *cron.Job) error {
+		return errors.New("model unavailable")
+	})
+	every := int64(3600000)
+	job, err := cronSvc.AddJob("daily-summary", "summarize", cron.Schedule{Kind: "every", EveryMS: &every}, "", "", false)
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	if _, err := cronSvc.RunJob(job.ID); err != nil {
+		t.Fatalf("RunJob: %v", err)
+	}
+
+	cmd := NewStatusCmd()
+	if err := cmd.Flags().Set("json", "true"); err != nil {
+		t.Fatalf("set --json: %v", err)
+	}
+	output := captureOutput(t, func() {
+		if err := runStatus(cmd, nil); err != nil {
+			t.Fatalf("runStatus error: %v", err)
+		}
+	})
+
+	var payload struct {
+		Cron struct {
+			Jobs []struct {
+				Name        string           `json:"name"`
+				LastStatus  string           `json:"last_status"`
+				LastError   string           `json:"last_error"`
+				History     []cron.RunRecord `json:"history"`
+				LastFailure *cron.RunRecord  `json:"last_failure"`
+			} `json:"jobs"`
+		} `json:"cron"`
+	}
+	if err := json.Unmarshal([]byte(output), &payload); err != nil {
+		t.Fatalf("invalid json output: %v, output=%s", err, output)
+	}
+	if len(payload.Cron.Jobs) != 1 {
+		t.Fatalf("expected 1 cron job, got %+v", payload.Cron.Jobs)
+	}
+	got := payload.Cron.Jobs[0]
+	if got.Name != "daily-summary" || got.LastStatus != "error" || got.LastError != "model unavailable" {
+		t.Fatalf("unexpected cron job status: %+v", got)
+	}
+	if len(got.History) != 1 || got.LastFailure == nil || got.LastFailure.Error != "model unavailable" {
+		t.Fatalf("expected run history with the failure, got %+v", got)
+	}
+}
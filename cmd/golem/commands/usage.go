@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/session"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// NewUsageCmd 创建 Token 用量查询命令。
+func NewUsageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "usage [session-key]",
+		Short: "Show token usage and estimated cost per session",
+		Long: `Show the tokens each session has used, split by model into prompt and
+completion tokens, read from the session files in <workspace>/sessions/.
+Costs are estimated from agents.defaults.model_prices. Pass a session key
+(for example cli:direct or telegram:123) to show a single session.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runUsage,
+	}
+	cmd.Flags().Bool("json", false, "Output usage as JSON")
+	return cmd
+}
+
+// usageRow 是 golem usage 输出中的一行：某个会话中某个模型的用量。
+type usageRow struct {
+	Session          string   `json:"session"`
+	Model            string   `json:"model"`
+	PromptTokens     int64    `json:"prompt_tokens"`
+	CompletionTokens int64    `json:"completion_tokens"`
+	Calls            int64    `json:"calls"`
+	Cost             *float64 `json:"estimated_cost,omitempty"` // 未配置价格时为空
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	workspacePath, err := cfg.WorkspacePathChecked()
+	if err != nil {
+		return fmt.Errorf("invalid workspace: %w", err)
+	}
+
+	sessions, err := session.NewManager(workspacePath).ListUsage()
+	if err != nil {
+		return err
+	}
+	rows := []usageRow{}
+	for _, s := range sessions {
+		if len(args) == 1 && s.Key != args[0] {
+			continue
+		}
+		models := make([]string, 0, len(s.Usage))
+		for model := range s.Usage {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+		for _, model := range models {
+			u := s.Usage[model]
+			row := usageRow{Session: s.Key, Model: model, PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, Calls: u.Calls}
+			if price, ok := cfg.Agents.Defaults.PriceFor(model); ok {
+				cost := price.Cost(u.PromptTokens, u.CompletionTokens)
+				row.Cost = &cost
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	}
+
+	if len(rows) == 0 {
+		if len(args) == 1 {
+			fmt.Printf("No usage recorded for session %s.\n", args[0])
+		} else {
+			fmt.Println("No usage recorded yet. Token counts are saved with each session as the agent replies.")
+		}
+		return nil
+	}
+
+	var (
+		wSession = 24
+		wModel   = 32
+		wNum     = 12
+
+		colHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#8E4EC6")). // Purple
+				Bold(true).
+				MarginRight(1)
+		cellStyle = func(width int) lipgloss.Style {
+			return lipgloss.NewStyle().Width(width).MarginRight(1)
+		}
+	)
+
+	headers := lipgloss.JoinHorizontal(lipgloss.Top,
+		colHeaderStyle.Width(wSession).Render("SESSION"),
+		colHeaderStyle.Width(wModel).Render("MODEL"),
+		colHeaderStyle.Width(wNum).Render("PROMPT"),
+		colHeaderStyle.Width(wNum).Render("COMPLETION"),
+		colHeaderStyle.Width(wNum/2).Render("CALLS"),
+		colHeaderStyle.Render("EST. COST"),
+	)
+	fmt.Printf("  %s\n", headers)
+
+	var total usageRow
+	var totalCost float64
+	priced := 0
+	for _, row := range rows {
+		cost := "-"
+		if row.Cost != nil {
+			cost = fmt.Sprintf("%.4f", *row.Cost)
+			totalCost += *row.Cost
+			priced++
+		}
+		line := lipgloss.JoinHorizontal(lipgloss.Top,
+			cellStyle(wSession).Render(truncate(row.Session, wSession)),
+			cellStyle(wModel).Render(truncate(row.Model, wModel)),
+			cellStyle(wNum).Render(strconv.FormatInt(row.PromptTokens, 10)),
+			cellStyle(wNum).Render(strconv.FormatInt(row.CompletionTokens, 10)),
+			cellStyle(wNum/2).Render(strconv.FormatInt(row.Calls, 10)),
+			lipgloss.NewStyle().Render(cost),
+		)
+		fmt.Printf("  %s\n", line)
+		total.PromptTokens += row.PromptTokens
+		total.CompletionTokens += row.CompletionTokens
+	}
+
+	fmt.Printf("\n  Total: %d prompt + %d completion = %d tokens", total.PromptTokens, total.CompletionTokens, total.PromptTokens+total.CompletionTokens)
+	if priced > 0 {
+		fmt.Printf(", estimated cost %.4f", totalCost)
+	}
+	fmt.Println()
+	if priced < len(rows) {
+		fmt.Println("  Models without a price show \"-\"; add them to agents.defaults.model_prices to estimate their cost.")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/session"
+)
+
+func TestUsage_ListsSessionTokens(t *testing.T) {
+	workspacePath := prepareApprovalWorkspace(t)
+
+	cmd := NewUsageCmd()
+	output := captureOutput(t, func() {
+		if err := runUsage(cmd, nil); err != nil {
+			t.Fatalf("runUsage: %v", err)
+		}
+	})
+	if !strings.Contains(output, "No usage recorded yet.") {
+		t.Fatalf("expected empty usage message, got: %s", output)
+	}
+
+	mgr := session.NewManager(workspacePath)
+	sess := mgr.GetOrCreate("cli:direct")
+	sess.AddUsage("openai/gpt-4o-mini", 1200, 300)
+	if err := mgr.Append(sess.Key, &session.Message{Role: "user", Content: "hi"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	output = captureOutput(t, func() {
+		if err := runUsage(cmd, []string{"cli:direct"}); err != nil {
+			t.Fatalf("runUsage: %v", err)
+		}
+	})
+	for _, want := range []string{"cli:direct", "openai/gpt-4o-mini", "1200", "Total: 1200 prompt + 300 completion = 1500 tokens", "agents.defaults.model_prices"} {
+		if !strings.Contains(output, want) {
+			t.Fatalf("expected %q in output:\n%s", want, output)
+		}
+	}
+
+	output = captureOutput(t, func() {
+		if err := runUsage(cmd, []string{"telegram:1"}); err != nil {
+			t.Fatalf("runUsage: %v", err)
+		}
+	})
+	if !strings.Contains(output, "No usage recorded for session telegram:1.") {
+		t.Fatalf("expected no usage for other session, got: %s", output)
+	}
+}
@@ -0,0 +1,186 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MEKXH/golem/internal/tools"
+)
+
+const (
+	maxAttachmentChars      = 20000            // 单个附件注入上下文的最大字符数
+	maxAttachmentTotalChars = 60000            // 单条消息所有附件注入上下文的总字符数
+	maxAttachmentFileBytes  = 1024 * 1024      // 读取工作区文件的最大字节数
+	attachmentFetchTimeout  = 15 * time.Second // 抓取单个 URL 附件的超时
+)
+
+// fileReferencePattern 匹配消息中以 @ 开头的工作区文件引用，如 "@docs/spec.md"。
+var fileReferencePattern = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// fetchAttachment 抓取 URL 附件的文本，测试中可替换。
+var fetchAttachment = tools.FetchText
+
+// AttachmentOptions 配置附件内容的读取。
+type AttachmentOptions struct {
+	AllowPrivate bool // 抓取 URL 附件时允许访问非公网地址，对应 tools.web.allow_private
+}
+
+// SetAttachmentOptions 设置附件内容的读取选项。
+func (c *ContextBuilder) SetAttachmentOptions(opts AttachmentOptions) {
+	c.attachmentOpts = opts
+}
+
+// buildAttachmentContext 读取消息附件（URL 或工作区文件）与正文中 @path 引用的工作区文件，
+// 返回带来源标记的文本块，以及无法作为文本读取、只列出名称的附件。
+// 文本附件单个不超过 maxAttachmentChars，总量不超过 maxAttachmentTotalChars。
+func (c *ContextBuilder) buildAttachmentContext(ctx context.Context, content string, media []string) (string, []string) {
+	refs := make([]string, 0, len(media))
+	seen := make(map[string]bool) // 引用 -> 是否来自消息附件
+	for _, item := range media {
+		if item = strings.TrimSpace(item); item != "" {
+			if _, dup := seen[item]; !dup {
+				refs = append(refs, item)
+			}
+			seen[item] = true
+		}
+	}
+	for _, m := range fileReferencePattern.FindAllStringSubmatch(content, -1) {
+		ref := strings.TrimRight(m[1], ".,;:!?)")
+		if _, dup := seen[ref]; dup {
+			continue
+		}
+		if _, ok := c.resolveWorkspaceFile(ref); ok {
+			seen[ref] = false
+			refs = append(refs, ref)
+		}
+	}
+
+	var blocks []string
+	var listed []string
+	budget := maxAttachmentTotalChars
+	for _, ref := range refs {
+		isMedia := seen[ref]
+		if budget <= 0 {
+			if isMedia {
+				listed = append(listed, ref+" (not included: attachment context limit reached)")
+			}
+			continue
+		}
+
+		text, truncated, err := c.readAttachment(ctx, ref)
+		if err != nil {
+			if isMedia {
+				if errors.Is(err, tools.ErrNotText) || errors.Is(err, os.ErrNotExist) {
+					listed = append(listed, ref)
+				} else {
+					listed = append(listed, fmt.Sprintf("%s (could not be read: %v)", ref, err))
+				}
+			}
+			slog.Debug("attachment not included as text", "source", ref, "error", err)
+			continue
+		}
+
+		limit := min(maxAttachmentChars, budget)
+		if utf8.RuneCountInString(text) > limit {
+			text = string([]rune(text)[:limit])
+			truncated = true
+		}
+		budget -= utf8.RuneCountInString(text)
+
+		header := "--- Begin attachment: " + ref
+		if truncated {
+			header += " (truncated)"
+		}
+		blocks = append(blocks, header+" ---\n"+text+"\n--- End attachment: "+ref+" ---")
+	}
+	return strings.Join(blocks, "\n\n"), listed
+}
+
+// readAttachment 读取单个附件的文本：http(s) URL 通过网络抓取，其他引用视为工作区内的文件。
+func (c *ContextBuilder) readAttachment(ctx context.Context, ref string) (string, bool, error) {
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		if isBinaryExtension(path.Ext(u.Path)) {
+			return "", false, tools.ErrNotText
+		}
+		fetchCtx, cancel := context.WithTimeout(ctx, attachmentFetchTimeout)
+		defer cancel()
+		return fetchAttachment(fetchCtx, ref, tools.FetchOptions{
+			Timeout:      attachmentFetchTimeout,
+			AllowPrivate: c.attachmentOpts.AllowPrivate,
+		})
+	}
+
+	filePath, ok := c.resolveWorkspaceFile(ref)
+	if !ok {
+		return "", false, os.ErrNotExist
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxAttachmentFileBytes+1))
+	if err != nil {
+		return "", false, err
+	}
+	truncated := len(data) > maxAttachmentFileBytes
+	if truncated {
+		data = data[:maxAttachmentFileBytes]
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", false, tools.ErrNotText
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(string(data), "")), truncated, nil
+}
+
+// resolveWorkspaceFile 将引用解析为工作区内的普通文件路径；路径（包括符号链接指向）位于工作区外时拒绝。
+func (c *ContextBuilder) resolveWorkspaceFile(ref string) (string, bool) {
+	if strings.TrimSpace(c.workspacePath) == "" || ref == "" {
+		return "", false
+	}
+	root, err := filepath.EvalSymlinks(c.workspacePath)
+	if err != nil {
+		return "", false
+	}
+	candidate := ref
+	if !filepath.IsAbs(candidate) {
+		candidate = filepath.Join(c.workspacePath, candidate)
+	}
+	resolved, err := filepath.EvalSymlinks(candidate)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	info, err := os.Stat(resolved)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return resolved, true
+}
+
+// isBinaryExtension 报告 URL 路径的扩展名是否明显是图片、音视频或压缩包，这类附件不抓取。
+func isBinaryExtension(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".bmp", ".svg", ".ico",
+		".mp3", ".wav", ".ogg", ".oga", ".m4a", ".flac", ".opus",
+		".mp4", ".mov", ".webm", ".mkv", ".avi",
+		".zip", ".gz", ".tar", ".7z", ".rar":
+		return true
+	}
+	return false
+}
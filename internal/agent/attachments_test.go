@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestBuildMessages_IncludesTextAttachmentsWithProvenance(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "docs"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "docs", "spec.md"), []byte("# Spec\nthe widget is blue"), 0644); err != nil {
+		t.Fatalf("WriteFile spec: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("top secret"), 0644); err != nil {
+		t.Fatalf("WriteFile secret: %v", err)
+	}
+
+	var fetched []string
+	orig := fetchAttachment
+	fetchAttachment = func(ctx context.Context, rawURL string, opts tools.FetchOptions) (string, bool, error) {
+		fetched = append(fetched, rawURL)
+		if strings.HasSuffix(rawURL, "/notes.txt") {
+			return "remote notes", false, nil
+		}
+		return "", false, tools.ErrNotText
+	}
+	t.Cleanup(func() { fetchAttachment = orig })
+
+	cb := NewContextBuilder(workspace)
+	msgs := cb.BuildMessages(nil, "compare @docs/spec.md with the notes, ignore @alice", []string{
+		"https://example.com/notes.txt",
+		"https://example.com/photo.png",
+		"https://example.com/download",
+		outside,
+	})
+	content := msgs[len(msgs)-1].Content
+
+	for _, want := range []string{
+		"--- Begin attachment: https://example.com/notes.txt ---\nremote notes\n--- End attachment: https://example.com/notes.txt ---",
+		"--- Begin attachment: docs/spec.md ---\n# Spec\nthe widget is blue\n--- End attachment: docs/spec.md ---",
+		"compare @docs/spec.md with the notes",
+		"Attached media:\n- [image: https://example.com/photo.png]\n- https://example.com/download\n- " + outside,
+	} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("expected %q in user message, got:\n%s", want, content)
+		}
+	}
+	if !strings.HasPrefix(content, "Attached context:") {
+		t.Fatalf("expected attachments before the user input, got:\n%s", content)
+	}
+	if strings.Contains(content, "top secret") {
+		t.Fatalf("files outside the workspace must not be read, got:\n%s", content)
+	}
+	if len(fetched) != 2 {
+		t.Fatalf("expected image URLs to be skipped without fetching, fetched %v", fetched)
+	}
+}
+
+func TestBuildMessages_TruncatesLargeAttachments(t *testing.T) {
+	workspace := t.TempDir()
+	big := strings.Repeat("字", maxAttachmentChars+10)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		if err := os.WriteFile(filepath.Join(workspace, name), []byte(big), 0644); err != nil {
+			t.Fatalf("WriteFile %s: %v", name, err)
+		}
+	}
+
+	cb := NewContextBuilder(workspace)
+	content := cb.BuildMessages(nil, "summarize", []string{"a.txt", "b.txt", "c.txt", "d.txt"})[1].Content
+
+	if !strings.Contains(content, "--- Begin attachment: a.txt (truncated) ---") {
+		t.Fatalf("expected truncation marker, got prefix:\n%s", content[:200])
+	}
+	if got := strings.Count(content, "字"); got != maxAttachmentTotalChars {
+		t.Fatalf("expected attachments capped at %d characters, got %d", maxAttachmentTotalChars, got)
+	}
+	if !strings.Contains(content, "- d.txt (not included: attachment context limit reached)") {
+		t.Fatalf("expected the attachment beyond the budget to be listed, got suffix:\n%s", content[len(content)-200:])
+	}
+}
+
+func TestBuildTurnMessages_AttachesImagesForVisionModels(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "chart.png"), []byte("\x89PNG fake"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	orig := fetchImage
+	fetchImage = func(ctx context.Context, rawURL string, opts tools.FetchOptions) ([]byte, string, error) {
+		if strings.HasSuffix(rawURL, "/cat.jpg") {
+			return []byte("jpeg bytes"), "image/jpeg", nil
+		}
+		return nil, "", tools.ErrNotImage
+	}
+	t.Cleanup(func() { fetchImage = orig })
+
+	cb := NewContextBuilder(workspace)
+	media := []string{"https://example.com/cat.jpg", "chart.png", "https://example.com/broken.png"}
+
+	msgs := cb.BuildTurnMessages(context.Background(), TurnInput{Current: "what is this?", Media: media, Vision: true})
+	user := msgs[len(msgs)-1]
+	if user.Content != "" {
+		t.Fatalf("expected content to move into parts, got %q", user.Content)
+	}
+	if len(user.UserInputMultiContent) != 3 {
+		t.Fatalf("expected text part plus two images, got %d parts", len(user.UserInputMultiContent))
+	}
+	text := user.UserInputMultiContent[0]
+	if text.Type != schema.ChatMessagePartTypeText || !strings.Contains(text.Text, "what is this?") ||
+		!strings.Contains(text.Text, "- [image: https://example.com/broken.png] (could not be loaded: ") {
+		t.Fatalf("unexpected text part: %+v", text)
+	}
+	jpeg := user.UserInputMultiContent[1].Image
+	if jpeg == nil || jpeg.MIMEType != "image/jpeg" || *jpeg.Base64Data != "anBlZyBieXRlcw==" {
+		t.Fatalf("unexpected first image part: %+v", jpeg)
+	}
+	if png := user.UserInputMultiContent[2].Image; png == nil || png.MIMEType != "image/png" {
+		t.Fatalf("unexpected workspace image part: %+v", png)
+	}
+	if got := estimateMessageTokens(user); got < 2*imageTokenEstimate {
+		t.Fatalf("expected images to count toward the token estimate, got %d", got)
+	}
+
+	plain := cb.BuildTurnMessages(context.Background(), TurnInput{Current: "what is this?", Media: media})
+	user = plain[len(plain)-1]
+	if len(user.UserInputMultiContent) != 0 {
+		t.Fatalf("text-only models must not receive image parts")
+	}
+	want := "Attached media:\n- [image: https://example.com/cat.jpg]\n- [image: chart.png]\n- [image: https://example.com/broken.png]"
+	if !strings.Contains(user.Content, want) {
+		t.Fatalf("expected image markers %q, got:\n%s", want, user.Content)
+	}
+}
@@ -1,12 +1,16 @@
 package agent
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/MEKXH/golem/internal/geocodebook"
 	"github.com/MEKXH/golem/internal/geopipeline"
@@ -27,6 +31,11 @@ var watchedBaseFiles = []string{
 type ContextBuilder struct {
 	workspacePath   string                  // 工作区根路径
 	runtimeMetrics  *metrics.RuntimeMetrics // 运行时指标记录器
+	maxTokens       int                     // 上下文估算 Token 上限，超出时丢弃最早的历史；0 表示不限制
+	memoryOpts      memory.Options          // 记忆召回选项；Embedder 非空时启用语义召回
+	systemPrompt    string                  // 替换内置身份说明的系统提示词，空表示使用内置提示词
+	channelPrompts  map[string]string       // 按通道覆盖的系统提示词
+	attachmentOpts  AttachmentOptions       // 附件内容的读取选项
 	mu              sync.RWMutex
 	cachedBaseParts []string // 缓存的基础 Prompt 片段
 }
@@ -41,6 +50,23 @@ func (c *ContextBuilder) SetRuntimeMetrics(recorder *metrics.RuntimeMetrics) {
 	c.runtimeMetrics = recorder
 }
 
+// SetMemoryOptions 设置记忆召回选项，用于启用语义召回。
+func (c *ContextBuilder) SetMemoryOptions(opts memory.Options) {
+	c.memoryOpts = opts
+}
+
+// SetMaxHistoryTokens 设置上下文的估算 Token 上限；0 表示不限制。
+func (c *ContextBuilder) SetMaxHistoryTokens(limit int) {
+	c.maxTokens = limit
+}
+
+// SetSystemPrompts 设置替换内置身份说明的系统提示词及按通道的覆盖；值以 "file:" 开头时从该文件读取
+// （相对路径基于工作区），每轮对话重新读取。工作区文件、技能与记忆等上下文仍追加在其后。
+func (c *ContextBuilder) SetSystemPrompts(defaultPrompt string, channelPrompts map[string]string) {
+	c.systemPrompt = defaultPrompt
+	c.channelPrompts = channelPrompts
+}
+
 // InvalidateCache 根据发生变化的文件路径使缓存失效。
 // 如果 changedPath 为空，则强制使所有基础缓存失效。
 func (c *ContextBuilder) InvalidateCache(changedPath string) {
@@ -105,11 +131,15 @@ func (c *ContextBuilder) InvalidateCache(changedPath string) {
 // BuildSystemPrompt 组装完整的系统提示词 (System Prompt)。
 func (c *ContextBuilder) BuildSystemPrompt() string {
 	parts := c.buildBaseSystemPromptParts()
+	parts[0] = c.identityFor("")
 
 	// 注入长期记忆
 	if mem := c.readWorkspaceFile(filepath.Join("memory", "MEMORY.md")); mem != "" {
 		parts = append(parts, "## Long-term Memory\n"+mem)
 	}
+	if sections := c.buildMemorySectionsNote(); sections != "" {
+		parts = append(parts, sections)
+	}
 
 	// 注入最近的日记
 	if diary := c.buildRecentDiarySection(); diary != "" {
@@ -123,8 +153,9 @@ func (c *ContextBuilder) BuildSystemPrompt() string {
 	return strings.Join(parts, "\n\n")
 }
 
-func (c *ContextBuilder) buildSystemPromptForInput(query string) string {
+func (c *ContextBuilder) buildSystemPromptForInput(channel, query string) string {
 	parts := c.buildBaseSystemPromptParts()
+	parts[0] = c.identityFor(channel)
 	if relevantPipelines := c.buildRelevantLearnedGeoPipelinesSection(query); relevantPipelines != "" {
 		parts = append(parts, relevantPipelines)
 	}
@@ -194,6 +225,35 @@ You have access to tools for file operations, shell commands, and more.
 Be helpful, concise, and proactive. Use tools when needed to accomplish tasks.`
 }
 
+// identityFor 返回通道使用的身份说明：通道覆盖优先，其次是默认的自定义提示词，都未配置或文件读取失败时使用内置提示词。
+// 提示词中的 {{date}}、{{workspace}} 与 {{channel}} 会被替换为当天日期、工作区路径与通道名。
+func (c *ContextBuilder) identityFor(channel string) string {
+	raw := strings.TrimSpace(c.channelPrompts[channel])
+	if raw == "" {
+		raw = strings.TrimSpace(c.systemPrompt)
+	}
+	if raw == "" {
+		return c.coreIdentity()
+	}
+	if path, ok := strings.CutPrefix(raw, "file:"); ok {
+		path = strings.TrimSpace(path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(c.workspacePath, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || strings.TrimSpace(string(data)) == "" {
+			slog.Warn("failed to read system prompt file; using built-in prompt", "path", path, "channel", channel, "error", err)
+			return c.coreIdentity()
+		}
+		raw = strings.TrimSpace(string(data))
+	}
+	return strings.NewReplacer(
+		"{{date}}", time.Now().Format("2006-01-02"),
+		"{{workspace}}", c.workspacePath,
+		"{{channel}}", channel,
+	).Replace(raw)
+}
+
 func (c *ContextBuilder) readWorkspaceFile(name string) string {
 	path := filepath.Join(c.workspacePath, name)
 	data, err := os.ReadFile(path)
@@ -203,6 +263,16 @@ func (c *ContextBuilder) readWorkspaceFile(name string) string {
 	return strings.TrimSpace(string(data))
 }
 
+// buildMemorySectionsNote 列出默认分区以外的长期记忆分区；分区内容不注入，由模型按需读取。
+func (c *ContextBuilder) buildMemorySectionsNote() string {
+	sections, err := memory.NewManager(c.workspacePath).ListSections()
+	if err != nil || len(sections) <= 1 {
+		return ""
+	}
+	return "## Memory Sections\nOther long-term memory sections (use read_memory or recall_memory with `section`): " +
+		strings.Join(sections[1:], ", ")
+}
+
 func (c *ContextBuilder) buildRecentDiarySection() string {
 	memMgr := memory.NewManager(c.workspacePath)
 	entries, err := memMgr.ReadRecentDiaries(3)
@@ -268,7 +338,7 @@ func (c *ContextBuilder) buildRelevantSkillsSection(query string) string {
 }
 
 func (c *ContextBuilder) buildMemoryRecallSection(query string) string {
-	memMgr := memory.NewManager(c.workspacePath)
+	memMgr := memory.NewManagerWithOptions(c.workspacePath, c.memoryOpts)
 	recall, err := memMgr.RecallContext(query, 3, 3)
 	if err != nil || recall.RecallCount == 0 {
 		return ""
@@ -317,13 +387,40 @@ func formatSourceHits(sourceHits map[string]int) string {
 
 // BuildMessages 根据历史记录、当前输入及媒体附件构建发送给 LLM 的完整消息列表。
 func (c *ContextBuilder) BuildMessages(history []*session.Message, current string, media []string) []*schema.Message {
+	return c.BuildMessagesWithSummary("", history, current, media)
+}
+
+// BuildMessagesWithSummary 与 BuildMessages 相同，并将会话早期对话的摘要附加到系统提示词末尾。
+func (c *ContextBuilder) BuildMessagesWithSummary(summary string, history []*session.Message, current string, media []string) []*schema.Message {
+	return c.BuildTurnMessages(context.Background(), TurnInput{Summary: summary, History: history, Current: current, Media: media})
+}
+
+// TurnInput 描述构建一轮对话消息所需的输入。
+type TurnInput struct {
+	Channel string             // 来源通道，用于选择系统提示词
+	Summary string             // 会话早期对话的摘要
+	History []*session.Message // 会话历史
+	Current string             // 当前用户输入
+	Media   []string           // 当前消息的附件（URL 或工作区文件）
+	Vision  bool               // 本轮使用的模型是否支持图片输入
+}
+
+// BuildTurnMessages 与 BuildMessagesWithSummary 相同，并使用 channel 配置的系统提示词。
+// 文本类附件（URL 或工作区文件）及正文中 @path 引用的工作区文件会带来源标记插入到当前输入之前；
+// 图片附件在模型支持视觉时作为图片片段附加到用户消息，否则以 [image: url] 文本标记代替。
+func (c *ContextBuilder) BuildTurnMessages(ctx context.Context, in TurnInput) []*schema.Message {
+	history := in.History
 	messages := make([]*schema.Message, 0, len(history)+2)
-	currentContent := strings.TrimSpace(current)
+	currentContent := strings.TrimSpace(in.Current)
 
 	// 注入动态构建的系统提示词
+	systemPrompt := c.buildSystemPromptForInput(in.Channel, currentContent)
+	if summary := strings.TrimSpace(in.Summary); summary != "" {
+		systemPrompt += "\n\n## Conversation So Far\nSummary of earlier messages in this conversation, which are no longer shown:\n" + summary
+	}
 	messages = append(messages, &schema.Message{
 		Role:    schema.System,
-		Content: c.buildSystemPromptForInput(currentContent),
+		Content: systemPrompt,
 	})
 
 	// 注入会话历史
@@ -338,29 +435,110 @@ func (c *ContextBuilder) BuildMessages(history []*session.Message, current strin
 		})
 	}
 
-	// 注入当前用户输入及媒体信息
+	// 注入附件文本、当前用户输入、图片及其余媒体信息
 	content := currentContent
-	if len(media) > 0 {
+	images, media := splitImageMedia(in.Media)
+	attached, listed := c.buildAttachmentContext(ctx, currentContent, media)
+	var imageParts []schema.MessageInputPart
+	if in.Vision {
+		var markers []string
+		imageParts, markers = c.buildImageParts(ctx, images)
+		listed = append(markers, listed...)
+	} else {
+		markers := make([]string, 0, len(images))
+		for _, ref := range images {
+			markers = append(markers, imageMarker(ref))
+		}
+		listed = append(markers, listed...)
+	}
+	if attached != "" {
+		content = "Attached context:\n\n" + attached + "\n\n" + content
+	}
+	if len(listed) > 0 {
 		var mb strings.Builder
-		for _, item := range media {
-			item = strings.TrimSpace(item)
-			if item == "" {
-				continue
-			}
+		for _, item := range listed {
 			mb.WriteString("- " + item + "\n")
 		}
-		if mb.Len() > 0 {
-			if content != "" {
-				content += "\n\n"
-			}
-			content += "Attached media:\n" + strings.TrimRight(mb.String(), "\n")
+		if content != "" {
+			content += "\n\n"
 		}
+		content += "Attached media:\n" + strings.TrimRight(mb.String(), "\n")
 	}
 
-	messages = append(messages, &schema.Message{
-		Role:    schema.User,
-		Content: content,
-	})
+	user := &schema.Message{Role: schema.User, Content: content}
+	if len(imageParts) > 0 {
+		// 使用多模态片段时 Content 必须为空，文本改为第一个片段
+		user.Content = ""
+		user.UserInputMultiContent = append([]schema.MessageInputPart{{
+			Type: schema.ChatMessagePartTypeText,
+			Text: content,
+		}}, imageParts...)
+	}
+	messages = append(messages, user)
+
+	return c.trimToBudget(messages)
+}
+
+// trimToBudget 在估算 Token 数超过上限时从最早的历史开始丢弃，始终保留系统提示与当前输入；
+// 丢弃后历史不以 assistant 消息开头，避免留下没有提问的回复。
+func (c *ContextBuilder) trimToBudget(messages []*schema.Message) []*schema.Message {
+	if c.maxTokens <= 0 || len(messages) <= 2 {
+		return messages
+	}
+	total := 0
+	for _, msg := range messages {
+		total += estimateMessageTokens(msg)
+	}
+	if total <= c.maxTokens {
+		return messages
+	}
+
+	before := total
+	history := messages[1 : len(messages)-1]
+	drop := 0
+	for drop < len(history) && total > c.maxTokens {
+		total -= estimateMessageTokens(history[drop])
+		drop++
+	}
+	for drop < len(history) && history[drop].Role == schema.Assistant {
+		total -= estimateMessageTokens(history[drop])
+		drop++
+	}
+
+	slog.Info("trimmed conversation history to fit token budget",
+		"dropped_messages", drop,
+		"kept_messages", len(history)-drop,
+		"estimated_tokens_before", before,
+		"estimated_tokens_after", total,
+		"max_history_tokens", c.maxTokens,
+	)
+	trimmed := make([]*schema.Message, 0, len(messages)-drop)
+	trimmed = append(trimmed, messages[0])
+	trimmed = append(trimmed, history[drop:]...)
+	return append(trimmed, messages[len(messages)-1])
+}
 
-	return messages
+// estimateMessageTokens 粗略估算消息的 Token 数：ASCII 约 4 字节一个 Token，
+// 其他字符（如中文）按每字一个 Token 计，每张图片按 imageTokenEstimate 计，另加每条消息的固定开销。
+func estimateMessageTokens(msg *schema.Message) int {
+	const perMessageOverhead = 4
+	ascii, other, images := 0, 0, 0
+	count := func(text string) {
+		for _, r := range text {
+			if r < utf8.RuneSelf {
+				ascii++
+			} else {
+				other++
+			}
+		}
+	}
+	count(msg.Content)
+	for _, part := range msg.UserInputMultiContent {
+		if part.Type == schema.ChatMessagePartTypeImageURL {
+			images++
+		} else {
+			count(part.Text)
+		}
+	}
+	return perMessageOverhead + (ascii+3)/4 + other + images*imageTokenEstimate
 }
@@ -1,12 +1,16 @@
 package agent
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/MEKXH/golem/internal/session"
 	"github.com/MEKXH/golem/internal/skills"
+	"github.com/cloudwego/eino/schema"
 )
 
 func TestBuildSystemPrompt_IncludesRecentDiaries(t *testing.T) {
@@ -44,6 +48,45 @@ func TestBuildSystemPrompt_IncludesRecentDiaries(t *testing.T) {
 	}
 }
 
+func TestBuildTurnMessages_UsesConfiguredSystemPrompts(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "USER.md"), []byte("user profile"), 0644); err != nil {
+		t.Fatalf("WriteFile USER: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "support.md"), []byte("You are the support desk for {{channel}}."), 0644); err != nil {
+		t.Fatalf("WriteFile support: %v", err)
+	}
+
+	cb := NewContextBuilder(workspace)
+	cb.SetSystemPrompts("You are Ada. Today is {{date}}; files live in {{workspace}}.", map[string]string{
+		"slack":   "file:support.md",
+		"discord": "file:missing.md",
+	})
+
+	systemPrompt := func(channel string) string {
+		t.Helper()
+		return cb.BuildTurnMessages(context.Background(), TurnInput{Channel: channel, Current: "hello"})[0].Content
+	}
+
+	def := systemPrompt("telegram")
+	want := "You are Ada. Today is " + time.Now().Format("2006-01-02") + "; files live in " + workspace + "."
+	if !strings.HasPrefix(def, want) || strings.Contains(def, "You are Golem") {
+		t.Fatalf("expected custom prompt with expanded variables, got: %s", def)
+	}
+	if !strings.Contains(def, "## USER\nuser profile") {
+		t.Fatalf("expected workspace context after the custom prompt, got: %s", def)
+	}
+	if got := systemPrompt("slack"); !strings.HasPrefix(got, "You are the support desk for slack.") {
+		t.Fatalf("expected channel prompt read from file, got: %s", got)
+	}
+	if got := systemPrompt("discord"); !strings.HasPrefix(got, "You are Golem") {
+		t.Fatalf("expected built-in prompt when the prompt file is missing, got: %s", got)
+	}
+	if got := NewContextBuilder(workspace).BuildSystemPrompt(); !strings.HasPrefix(got, "You are Golem") {
+		t.Fatalf("expected built-in prompt by default, got: %s", got)
+	}
+}
+
 func TestBuildMessages_IncludesMediaList(t *testing.T) {
 	cb := NewContextBuilder(t.TempDir())
 	msgs := cb.BuildMessages(nil, "analyze this", []string{"a.png", "b.txt"})
@@ -59,6 +102,54 @@ func TestBuildMessages_IncludesMediaList(t *testing.T) {
 	}
 }
 
+func TestBuildMessages_TrimsOldestHistoryToTokenBudget(t *testing.T) {
+	cb := NewContextBuilder(t.TempDir())
+	base := cb.BuildMessages(nil, "latest question", nil)
+	fixed := estimateMessageTokens(base[0]) + estimateMessageTokens(base[1])
+
+	turn := strings.Repeat("word ", 80) // 约 100 token
+	history := []*session.Message{
+		{Role: "user", Content: "oldest " + turn},
+		{Role: "assistant", Content: "reply-1 " + turn},
+		{Role: "user", Content: "middle " + turn},
+		{Role: "assistant", Content: "reply-2 " + turn},
+	}
+	// 预算只容得下最近约两条历史
+	cb.SetMaxHistoryTokens(fixed + 2*estimateMessageTokens(&schema.Message{Content: "middle " + turn}) + 10)
+	msgs := cb.BuildMessages(history, "latest question", nil)
+
+	if len(msgs) != 4 {
+		t.Fatalf("expected system + 2 history + current, got %d messages", len(msgs))
+	}
+	if msgs[0].Role != schema.System || msgs[len(msgs)-1].Content != "latest question" {
+		t.Fatalf("expected system prompt and current input kept, got %+v", msgs)
+	}
+	if !strings.HasPrefix(msgs[1].Content, "middle") || !strings.HasPrefix(msgs[2].Content, "reply-2") {
+		t.Fatalf("expected the most recent turn kept, got %q / %q", msgs[1].Content, msgs[2].Content)
+	}
+
+	// 裁剪后历史不以 assistant 回复开头
+	cb.SetMaxHistoryTokens(fixed + estimateMessageTokens(&schema.Message{Content: "reply-2 " + turn}) + 10)
+	msgs = cb.BuildMessages(history, "latest question", nil)
+	if len(msgs) != 2 {
+		t.Fatalf("expected orphaned assistant reply dropped, got %d messages", len(msgs))
+	}
+
+	cb.SetMaxHistoryTokens(0)
+	if msgs := cb.BuildMessages(history, "latest question", nil); len(msgs) != 6 {
+		t.Fatalf("expected no trimming without a budget, got %d messages", len(msgs))
+	}
+}
+
+func TestEstimateMessageTokens_CountsCJKPerCharacter(t *testing.T) {
+	if got := estimateMessageTokens(&schema.Message{Content: "abcdefgh"}); got != 4+2 {
+		t.Fatalf("expected 6 tokens for 8 ASCII chars, got %d", got)
+	}
+	if got := estimateMessageTokens(&schema.Message{Content: "你好世界"}); got != 4+4 {
+		t.Fatalf("expected 8 tokens for 4 CJK chars, got %d", got)
+	}
+}
+
 func TestBuildSystemPrompt_IncludesBuiltinSkillsSummary(t *testing.T) {
 	workspace := t.TempDir()
 	builtin := filepath.Join(t.TempDir(), "builtin-skills")
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+const (
+	defaultInboundDedupWindow = 5 * time.Minute
+	inboundDedupMaxEntries    = 10000 // 去重记录上限，超出时淘汰最早的记录
+)
+
+// inboundDeduper 记录窗口期内见过的入站消息，丢弃通道重连后平台重复投递的同一事件。
+type inboundDeduper struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]*list.Element
+	order  *list.List // 按首次出现时间排列的 dedupEntry
+	now    func() time.Time
+}
+
+type dedupEntry struct {
+	key    string
+	seenAt time.Time
+}
+
+func newInboundDeduper(window time.Duration) *inboundDeduper {
+	if window <= 0 {
+		window = defaultInboundDedupWindow
+	}
+	return &inboundDeduper{
+		window: window,
+		seen:   make(map[string]*list.Element),
+		order:  list.New(),
+		now:    time.Now,
+	}
+}
+
+func (d *inboundDeduper) setWindow(window time.Duration) {
+	if window <= 0 {
+		window = defaultInboundDedupWindow
+	}
+	d.mu.Lock()
+	d.window = window
+	d.mu.Unlock()
+}
+
+// duplicate 报告 key 是否已在窗口期内出现过；未出现过时记录下来。
+func (d *inboundDeduper) duplicate(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		entry := front.Value.(dedupEntry)
+		if now.Sub(entry.seenAt) <= d.window && d.order.Len() < inboundDedupMaxEntries {
+			break
+		}
+		d.order.Remove(front)
+		delete(d.seen, entry.key)
+	}
+
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = d.order.PushBack(dedupEntry{key: key, seenAt: now})
+	return false
+}
+
+// inboundDedupKey 由通道、聊天与平台消息 ID 组成；消息未携带平台 ID 时返回空，表示不去重。
+func inboundDedupKey(msg *bus.InboundMessage) string {
+	id := msg.PlatformMessageID()
+	if id == "" {
+		return ""
+	}
+	return msg.Channel + ":" + msg.ChatID + ":" + id
+}
+
+// isDuplicateInbound 报告消息是否为平台重复投递的事件。
+func (l *Loop) isDuplicateInbound(msg *bus.InboundMessage) bool {
+	key := inboundDedupKey(msg)
+	if key == "" || l.inboundDedup == nil {
+		return false
+	}
+	if !l.inboundDedup.duplicate(key) {
+		return false
+	}
+	slog.Info("dropped duplicate inbound message",
+		"request_id", msg.RequestID,
+		"channel", msg.Channel,
+		"chat_id", msg.ChatID,
+		"platform_message_id", msg.PlatformMessageID(),
+	)
+	return true
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+func TestInboundDeduper_DropsRedeliveryWithinWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := newInboundDeduper(time.Minute)
+	d.now = func() time.Time { return now }
+
+	if d.duplicate("telegram:1:100") {
+		t.Fatal("first delivery must not be a duplicate")
+	}
+	if !d.duplicate("telegram:1:100") {
+		t.Fatal("redelivery within the window must be a duplicate")
+	}
+	if d.duplicate("telegram:2:100") {
+		t.Fatal("same message id in another chat is a different message")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if d.duplicate("telegram:1:100") {
+		t.Fatal("expected entry to expire after the window")
+	}
+}
+
+func TestInboundDeduper_BoundedMemory(t *testing.T) {
+	d := newInboundDeduper(time.Hour)
+	for i := 0; i < inboundDedupMaxEntries+10; i++ {
+		d.duplicate(fmt.Sprintf("k%d", i))
+	}
+	if len(d.seen) > inboundDedupMaxEntries || d.order.Len() != len(d.seen) {
+		t.Fatalf("expected at most %d entries, got map=%d list=%d", inboundDedupMaxEntries, len(d.seen), d.order.Len())
+	}
+	if d.duplicate("k0") {
+		t.Fatal("expected oldest entry to be evicted")
+	}
+}
+
+func TestIsDuplicateInbound_UsesPlatformMessageID(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	loop.inboundDedup = newInboundDeduper(time.Minute)
+
+	msg := &bus.InboundMessage{Channel: "slack", ChatID: "C1", Metadata: map[string]any{"message_ts": "1700000000.1"}}
+	if loop.isDuplicateInbound(msg) {
+		t.Fatal("first delivery must pass")
+	}
+	redelivered := &bus.InboundMessage{Channel: "slack", ChatID: "C1", Metadata: map[string]any{"message_ts": "1700000000.1"}}
+	if !loop.isDuplicateInbound(redelivered) {
+		t.Fatal("redelivered event must be dropped")
+	}
+
+	noID := &bus.InboundMessage{Channel: "slack", ChatID: "C1", Content: "/golem help"}
+	if loop.isDuplicateInbound(noID) || loop.isDuplicateInbound(noID) {
+		t.Fatal("messages without a platform id are never deduplicated")
+	}
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	maxImageBytes       = 5 * 1024 * 1024 // 单张图片附件的最大字节数
+	maxImagesPerMessage = 4               // 单条消息最多内联的图片数
+	imageTokenEstimate  = 1000            // 单张图片的估算 Token 数，用于上下文预算
+)
+
+// fetchImage 抓取 URL 图片附件，测试中可替换。
+var fetchImage = tools.FetchImage
+
+// isImageExtension 报告扩展名是否为模型可以直接读取的位图格式。
+func isImageExtension(ext string) bool {
+	switch strings.ToLower(ext) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return true
+	}
+	return false
+}
+
+// isImageRef 报告附件引用（URL 或工作区文件路径）是否指向图片。
+func isImageRef(ref string) bool {
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return isImageExtension(path.Ext(u.Path))
+	}
+	return isImageExtension(filepath.Ext(ref))
+}
+
+// splitImageMedia 将媒体附件分为图片与其他附件。
+func splitImageMedia(media []string) (images, others []string) {
+	for _, item := range media {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if isImageRef(item) {
+			images = append(images, item)
+		} else {
+			others = append(others, item)
+		}
+	}
+	return images, others
+}
+
+// buildImageParts 读取图片附件并以 base64 内联为消息片段；内联而不是传递 URL，
+// 可以避免把带令牌的链接交给模型提供方，也兼容只接受内联数据的本地模型。
+// 无法读取或超出数量上限的图片改为文本标记返回。
+func (c *ContextBuilder) buildImageParts(ctx context.Context, images []string) ([]schema.MessageInputPart, []string) {
+	var parts []schema.MessageInputPart
+	var markers []string
+	for _, ref := range images {
+		if len(parts) >= maxImagesPerMessage {
+			markers = append(markers, imageMarker(ref)+" (not included: image limit reached)")
+			continue
+		}
+		data, mimeType, err := c.readImage(ctx, ref)
+		if err != nil {
+			slog.Debug("image attachment not included", "source", ref, "error", err)
+			markers = append(markers, fmt.Sprintf("%s (could not be loaded: %v)", imageMarker(ref), err))
+			continue
+		}
+		encoded := base64.StdEncoding.EncodeToString(data)
+		parts = append(parts, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeImageURL,
+			Image: &schema.MessageInputImage{
+				MessagePartCommon: schema.MessagePartCommon{
+					Base64Data: &encoded,
+					MIMEType:   mimeType,
+				},
+			},
+		})
+	}
+	return parts, markers
+}
+
+// readImage 读取单张图片：http(s) URL 通过网络抓取，其他引用视为工作区内的文件。
+func (c *ContextBuilder) readImage(ctx context.Context, ref string) ([]byte, string, error) {
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		fetchCtx, cancel := context.WithTimeout(ctx, attachmentFetchTimeout)
+		defer cancel()
+		return fetchImage(fetchCtx, ref, tools.FetchOptions{
+			Timeout:      attachmentFetchTimeout,
+			MaxBytes:     maxImageBytes,
+			AllowPrivate: c.attachmentOpts.AllowPrivate,
+		})
+	}
+
+	filePath, ok := c.resolveWorkspaceFile(ref)
+	if !ok {
+		return nil, "", os.ErrNotExist
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxImageBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageBytes {
+		return nil, "", fmt.Errorf("image is larger than %d bytes", maxImageBytes)
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(filePath)))
+	if mimeType == "" {
+		mimeType = "image/png"
+	}
+	return data, mimeType, nil
+}
+
+// imageMarker 返回不支持视觉的模型看到的图片文本标记。
+func imageMarker(ref string) string {
+	return "[image: " + ref + "]"
+}
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+const (
+	// oversizeRejectedReply 是 reject 策略下回复给用户的提示，参数为消息长度与上限。
+	oversizeRejectedReply = "Your message is too long (%d characters; the limit is %d). Please shorten it and try again."
+	// oversizeTruncatedReply 是 truncate 策略下回复给用户的提示，参数为消息长度与保留的长度。
+	oversizeTruncatedReply = "Your message was too long (%d characters), so only the first %d characters were processed."
+	// oversizeTruncatedMarker 附加在截断后的正文末尾，让模型知道内容不完整。
+	oversizeTruncatedMarker = "\n\n[Message truncated: only the first %d of %d characters are shown.]"
+)
+
+// limitInboundSize 对渠道消息执行 channels.inbound.max_message_chars 长度上限。计入长度的是正文，
+// 包括通道写入正文的语音转录与附件标记。超限时按 oversize_policy 截断正文或拒绝处理，两种情况都会回复提示；
+// 返回 false 表示消息应被丢弃。
+func (l *Loop) limitInboundSize(msg *bus.InboundMessage) bool {
+	reply, tooLong := l.applyInboundSizeLimit(msg)
+	if reply == "" && tooLong == nil {
+		return true
+	}
+	if tooLong != nil {
+		reply = fmt.Sprintf(oversizeRejectedReply, tooLong.Length, tooLong.Limit)
+	}
+	l.bus.PublishOutbound(replyInThread(&bus.OutboundMessage{
+		Channel:   msg.Channel,
+		ChatID:    msg.ChatID,
+		Content:   reply,
+		RequestID: msg.RequestID,
+	}, msg))
+	return tooLong == nil
+}
+
+// applyInboundSizeLimit 检查 msg 的正文长度：未超限时返回空字符串；truncate 策略下截断正文并返回给用户的提示；
+// reject 策略下不修改消息，返回 *bus.MessageTooLongError。
+func (l *Loop) applyInboundSizeLimit(msg *bus.InboundMessage) (string, *bus.MessageTooLongError) {
+	l.inboundMu.RLock()
+	limit, policy := l.inboundMaxChars, l.inboundOversize
+	l.inboundMu.RUnlock()
+
+	if limit <= 0 {
+		return "", nil
+	}
+	length := utf8.RuneCountInString(msg.Content)
+	if length <= limit {
+		return "", nil
+	}
+	slog.Warn("inbound message exceeds size limit",
+		"request_id", msg.RequestID,
+		"channel", msg.Channel,
+		"sender_id", msg.SenderID,
+		"chars", length,
+		"limit", limit,
+		"policy", policy,
+	)
+
+	if policy == config.OversizeReject {
+		return "", &bus.MessageTooLongError{Length: length, Limit: limit}
+	}
+	msg.Content = string([]rune(msg.Content)[:limit]) + fmt.Sprintf(oversizeTruncatedMarker, limit, length)
+	return fmt.Sprintf(oversizeTruncatedReply, length, limit), nil
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+func TestLimitInboundSize_TruncatesWithNotice(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	loop.ReloadInbound(config.ChannelInboundConfig{MaxMessageChars: 5, OversizePolicy: config.OversizeTruncate})
+
+	msg := &bus.InboundMessage{Channel: "slack", ChatID: "C1", Content: "héllo world", RequestID: "req-1",
+		Metadata: map[string]any{bus.MetadataThreadID: "1.0"}}
+	if !loop.limitInboundSize(msg) {
+		t.Fatal("truncated message should still be processed")
+	}
+	if !strings.HasPrefix(msg.Content, "héllo\n\n[Message truncated: only the first 5 of 11 characters") {
+		t.Fatalf("unexpected truncated content: %q", msg.Content)
+	}
+
+	out := <-loop.bus.Outbound()
+	if !strings.Contains(out.Content, "only the first 5 characters were processed") || out.ThreadID() != "1.0" {
+		t.Fatalf("unexpected notice: %+v", out)
+	}
+}
+
+func TestLimitInboundSize_RejectsOversizedMessage(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	loop.ReloadInbound(config.ChannelInboundConfig{MaxMessageChars: 5, OversizePolicy: config.OversizeReject})
+
+	if !loop.limitInboundSize(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "hello"}) {
+		t.Fatal("message at the limit should pass")
+	}
+	msg := &bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "hello!"}
+	if loop.limitInboundSize(msg) {
+		t.Fatal("oversized message should be rejected")
+	}
+	if msg.Content != "hello!" {
+		t.Fatalf("rejected message should not be modified, got %q", msg.Content)
+	}
+	out := <-loop.bus.Outbound()
+	if out.Content != "Your message is too long (6 characters; the limit is 5). Please shorten it and try again." {
+		t.Fatalf("unexpected rejection reply: %q", out.Content)
+	}
+}
+
+func TestProcessForChannel_LimitsGatewayRequests(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	loop.ReloadInbound(config.ChannelInboundConfig{MaxMessageChars: 5, OversizePolicy: config.OversizeReject})
+	gatewayCtx := bus.WithUnverifiedSender(context.Background())
+
+	_, err := loop.ProcessForChannel(gatewayCtx, "gateway", "c1", "api", "hello world")
+	var tooLong *bus.MessageTooLongError
+	if !errors.As(err, &tooLong) || tooLong.Length != 11 || tooLong.Limit != 5 {
+		t.Fatalf("expected MessageTooLongError, got %v", err)
+	}
+	if history := loop.sessions.GetOrCreate("gateway:c1").GetHistory(10); len(history) != 0 {
+		t.Fatalf("rejected request should not reach the session, got %d messages", len(history))
+	}
+
+	loop.ReloadInbound(config.ChannelInboundConfig{MaxMessageChars: 5, OversizePolicy: config.OversizeTruncate})
+	resp, err := loop.ProcessForChannel(gatewayCtx, "gateway", "c1", "api", "hello world")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.HasPrefix(resp, "Your message was too long (11 characters), so only the first 5 characters were processed.") {
+		t.Fatalf("expected truncation notice at the start of the reply, got %q", resp)
+	}
+	history := loop.sessions.GetOrCreate("gateway:c1").GetHistory(10)
+	if len(history) == 0 || !strings.HasPrefix(history[0].Content, "hello\n\n[Message truncated") {
+		t.Fatalf("expected truncated content in history, got %+v", history)
+	}
+
+	// 本地 CLI 等内部调用不受渠道消息长度限制。
+	if _, err := loop.ProcessForChannel(context.Background(), "cli", "direct", "user", "hello world"); err != nil {
+		t.Fatalf("expected internal calls to skip the limit, got %v", err)
+	}
+}
@@ -4,11 +4,14 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/MEKXH/golem/internal/bus"
@@ -16,10 +19,12 @@ import (
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/geopipeline"
 	"github.com/MEKXH/golem/internal/mcp"
+	"github.com/MEKXH/golem/internal/memory"
 	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/session"
 	"github.com/MEKXH/golem/internal/skills"
 	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/components/embedding"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
@@ -27,28 +32,66 @@ import (
 
 // Loop 负责管理 Agent 的主处理循环，包括消息处理、模型生成及工具执行。
 type Loop struct {
-	bus           *bus.MessageBus         // 消息总线，用于收发通道消息
-	model         model.ChatModel         // 绑定的聊天模型实现
-	tools         *tools.Registry         // 工具注册表，管理所有可用工具
-	commands      *command.Registry       // 命令注册表，管理斜杠命令
-	mcpManager    *mcp.Manager            // MCP（Model Context Protocol）连接管理器
-	runtimeGuard  *runtimeGuard           // 运行时安全防护，用于权限控制
-	subagents     *SubagentManager        // 子 Agent 管理器，支持任务委派
-	sessions      *session.Manager        // 会话管理器，维护用户历史记录
-	context       *ContextBuilder         // 上下文构建器，准备模型所需的 Prompt
-	config        *config.Config          // 项目全局配置
-	maxIterations int                     // 单条消息允许的最大工具调用迭代次数
-	workspacePath string                  // 工作空间根目录路径
-	now           func() time.Time        // 获取当前时间的函数（方便测试）
-	runtimeMetric *metrics.RuntimeMetrics // 运行时指标收集器
+	bus           *bus.MessageBus            // 消息总线，用于收发通道消息
+	model         model.ChatModel            // 绑定的聊天模型实现
+	channelModels map[string]model.ChatModel // 按通道覆盖的聊天模型
+	tools         *tools.Registry            // 工具注册表，管理所有可用工具
+	commands      *command.Registry          // 命令注册表，管理斜杠命令
+	mcpManager    *mcp.Manager               // MCP（Model Context Protocol）连接管理器
+	runtimeGuard  *runtimeGuard              // 运行时安全防护，用于权限控制
+	guardMu       sync.RWMutex               // 保护 runtimeGuard 的热更新
+	subagents     *SubagentManager           // 子 Agent 管理器，支持任务委派
+	sessions      *session.Manager           // 会话管理器，维护用户历史记录
+	context       *ContextBuilder            // 上下文构建器，准备模型所需的 Prompt
+	config        *config.Config             // 项目全局配置
+	maxIterations int                        // 单条消息允许的最大工具调用迭代次数
+	turnTimeout   time.Duration              // 单轮对话（含全部模型与工具调用）的总时限，0 表示不限制
+	maxParallel   int                        // 单次模型回复中并行执行的工具调用数上限，<= 0 时使用默认值
+	workspacePath string                     // 工作空间根目录路径
+	now           func() time.Time           // 获取当前时间的函数（方便测试）
+	runtimeMetric *metrics.RuntimeMetrics    // 运行时指标收集器
+	modelRetry    modelRetryPolicy           // 模型调用的重试与退避策略
+	turns         turnTracker                // 按会话跟踪进行中的对话轮次，用于取消被新消息取代的轮次
+	turnSem       chan struct{}              // 会话并发信号量，限制同时处理消息的会话数；为空时不限制
+	running       atomic.Bool                // 主循环是否正在消费入站消息
+	summarizing   sync.Map                   // 正在后台压缩历史的会话键
+	summaries     sync.WaitGroup             // 进行中的历史摘要任务
+
+	inboundMu       sync.RWMutex
+	inboundLimiter  *senderRateLimiter // 按 channel:sender 的入站限流器，为空时不限流
+	inboundDedup    *inboundDeduper    // 入站消息去重，丢弃平台重复投递的事件
+	inboundMaxChars int                // 入站消息正文的字符数上限，0 表示不限制
+	inboundOversize string             // 超出上限时的处理策略，见 config.OversizeTruncate
+
+	reactionsMu sync.RWMutex
+	reactions   config.ChannelReactionsConfig // 标记处理进度的表情反应设置
+
+	sessionModelsMu sync.RWMutex
+	sessionModels   map[string]sessionModel // 按会话键通过 /model 切换的聊天模型
+	modelFactory    ModelFactory            // 按模型名创建聊天模型，为空时不支持 /model 切换
 
 	// OnToolStart 工具开始执行时的回调函数
 	OnToolStart func(name, args string)
-	// OnToolFinish 工具执行完成后的回调函数
-	OnToolFinish func(name, result string, err error)
+	// OnToolFinish 工具执行完成后的回调函数；outcome 区分成功、失败、超时、拒绝与等待审批
+	OnToolFinish func(name, result string, outcome tools.Outcome, err error)
+	// OnContentDelta 模型流式输出增量文本时的回调函数；设置后模型调用改为流式
+	OnContentDelta func(delta string)
+	// OnToolPlanned 计划模式下模型提出工具调用时的回调函数（工具不会被执行）
+	OnToolPlanned func(name, args string)
+
+	// PlanOnly 为 true 时进入计划模式：模型提出的工具调用不会执行，而是连同参数作为回复返回
+	PlanOnly bool
 
 	// activityRecorder 记录最近活跃的通道与聊天 ID 的回调
 	activityRecorder func(channel, chatID string)
+	// typingNotifier 在处理消息期间周期性触发“正在输入”提示的回调
+	typingNotifier func(ctx context.Context, channel, chatID string)
+	// reactionNotifier 在入站消息上设置表情反应的回调，emoji 为空表示移除
+	reactionNotifier func(ctx context.Context, channel, chatID, messageID, emoji string)
+	// approvalNotifier 在创建审批请求时向来源聊天推送通知的回调
+	approvalNotifier func(msg *bus.OutboundMessage)
+	// attachmentLimit 返回通道单文件上传上限的回调，供 send_file 在发送前检查文件大小
+	attachmentLimit func(channel string) (int64, bool)
 }
 
 // NewLoop 根据配置、消息总线和聊天模型创建一个新的 Loop 实例。
@@ -59,37 +102,197 @@ func NewLoop(cfg *config.Config, msgBus *bus.MessageBus, chatModel model.ChatMod
 	}
 	cmdRegistry := command.NewRegistry()
 	cmdRegistry.Register(&command.NewSessionCommand{})
+	cmdRegistry.Register(&command.ClearSessionCommand{})
 	cmdRegistry.Register(&command.HelpCommand{})
 	cmdRegistry.Register(&command.VersionCommand{})
 	cmdRegistry.Register(&command.StatusCommand{})
 	cmdRegistry.Register(&command.CronCommand{})
 	cmdRegistry.Register(&command.SkillsCommand{})
 	cmdRegistry.Register(&command.MemoryCommand{})
+	cmdRegistry.Register(&command.ApproveCommand{})
+	cmdRegistry.Register(&command.RejectCommand{})
+	cmdRegistry.Register(&command.PolicyCommand{})
+	cmdRegistry.Register(&command.ModelCommand{})
+	cmdRegistry.Register(&command.ToolsCommand{})
+	cmdRegistry.Register(&command.UsageCommand{})
+
+	contextBuilder := NewContextBuilder(workspacePath)
+	contextBuilder.SetMaxHistoryTokens(cfg.Agents.Defaults.MaxHistoryTokens)
+	contextBuilder.SetSystemPrompts(cfg.Agents.Defaults.SystemPrompt, cfg.Agents.Defaults.ChannelSystemPrompts)
+	contextBuilder.SetAttachmentOptions(AttachmentOptions{AllowPrivate: cfg.Tools.Web.AllowPrivate})
+
+	toolRegistry := tools.NewRegistry()
+	toolRegistry.SetBreaker(toolBreakerConfig(cfg.Tools.CircuitBreaker))
 
 	return &Loop{
 		bus:           msgBus,
 		model:         chatModel,
-		tools:         tools.NewRegistry(),
+		tools:         toolRegistry,
 		commands:      cmdRegistry,
-		sessions:      session.NewManager(workspacePath),
-		context:       NewContextBuilder(workspacePath),
+		sessions:      session.NewManagerWithLimit(workspacePath, cfg.Agents.Defaults.MaxSessionHistory),
+		context:       contextBuilder,
 		config:        cfg,
 		maxIterations: cfg.Agents.Defaults.MaxToolIterations,
+		turnTimeout:   time.Duration(cfg.Agents.Defaults.TurnTimeoutSeconds) * time.Second,
+		maxParallel:   cfg.Agents.Defaults.MaxParallelTools,
 		workspacePath: workspacePath,
 		now:           time.Now,
+		modelRetry:    newModelRetryPolicy(cfg.Agents.Defaults),
+		turnSem:       newTurnSemaphore(cfg.Agents.Defaults.MaxConcurrentSessions),
+		inboundLimiter: newSenderRateLimiter(
+			cfg.Channels.Inbound.RateLimitPerMinute,
+			cfg.Channels.Inbound.RateLimitBurst,
+		),
+		inboundDedup:    newInboundDeduper(time.Duration(cfg.Channels.Inbound.DedupWindowSeconds) * time.Second),
+		inboundMaxChars: cfg.Channels.Inbound.MaxMessageChars,
+		inboundOversize: cfg.Channels.Inbound.OversizePolicy,
+		reactions:       cfg.Channels.Reactions,
 	}, nil
 }
 
+// toolBreakerConfig 将配置转换为工具注册表的熔断策略；未启用时返回的阈值为 0，即关闭熔断。
+func toolBreakerConfig(cfg config.CircuitBreakerConfig) tools.BreakerConfig {
+	if !cfg.Enabled {
+		return tools.BreakerConfig{}
+	}
+	return tools.BreakerConfig{
+		FailureThreshold: cfg.FailureThreshold,
+		Window:           time.Duration(cfg.WindowSeconds) * time.Second,
+		Cooldown:         time.Duration(cfg.CooldownSeconds) * time.Second,
+	}
+}
+
+// Running 报告主循环是否正在消费消息总线的入站消息。
+func (l *Loop) Running() bool {
+	return l.running.Load()
+}
+
+// HasModel 报告是否至少配置了一个可用的聊天模型（默认模型或通道覆盖模型）。
+func (l *Loop) HasModel() bool {
+	if l.model != nil {
+		return true
+	}
+	for _, chatModel := range l.channelModels {
+		if chatModel != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // Tools 返回工具注册表。
 func (l *Loop) Tools() *tools.Registry {
 	return l.tools
 }
 
+// MCPStatuses 返回各 MCP 服务器的实时连接状态；未配置 MCP 时返回空列表。
+func (l *Loop) MCPStatuses() []mcp.ServerStatus {
+	if l.mcpManager == nil {
+		return []mcp.ServerStatus{}
+	}
+	return l.mcpManager.Statuses()
+}
+
+// MCPLogs 返回各 stdio MCP 服务器最近的 stderr 输出。
+func (l *Loop) MCPLogs() []mcp.ServerLogs {
+	if l.mcpManager == nil {
+		return []mcp.ServerLogs{}
+	}
+	return l.mcpManager.StderrTails()
+}
+
+// ToolBreakers 返回工具熔断器的实时状态。
+func (l *Loop) ToolBreakers() []tools.BreakerStatus {
+	return l.tools.BreakerStatuses()
+}
+
+// ReconnectMCP 立即重连指定的 MCP 服务器，并补注册重连后新发现的工具。
+func (l *Loop) ReconnectMCP(ctx context.Context, serverName string) (mcp.ServerStatus, error) {
+	if l.mcpManager == nil {
+		return mcp.ServerStatus{}, fmt.Errorf("no mcp servers configured")
+	}
+	status, err := l.mcpManager.Reconnect(ctx, serverName)
+	if err == nil {
+		err = l.mcpManager.RegisterTools(l.tools)
+	}
+	return status, err
+}
+
+// ReconnectDegradedMCP 重连所有降级的 MCP 服务器，返回它们重连后的状态。
+func (l *Loop) ReconnectDegradedMCP(ctx context.Context) ([]mcp.ServerStatus, error) {
+	if l.mcpManager == nil {
+		return []mcp.ServerStatus{}, nil
+	}
+	statuses := l.mcpManager.ReconnectDegraded(ctx)
+	return statuses, l.mcpManager.RegisterTools(l.tools)
+}
+
+// ReloadSkills 丢弃缓存的系统提示词，使下一次请求重新扫描技能目录，并返回当前发现的技能列表。
+func (l *Loop) ReloadSkills() []skills.SkillInfo {
+	if l.context != nil {
+		l.context.InvalidateCache("")
+	}
+	list := skills.NewLoader(l.workspacePath).ListSkills()
+	if list == nil {
+		list = []skills.SkillInfo{}
+	}
+	return list
+}
+
+// SetChannelModels 设置按通道覆盖的聊天模型；未覆盖的通道继续使用默认模型。
+func (l *Loop) SetChannelModels(models map[string]model.ChatModel) {
+	l.channelModels = models
+}
+
+// modelFor 返回指定会话应使用的聊天模型：会话内切换的模型优先，其次是通道覆盖模型，最后是默认模型。
+func (l *Loop) modelFor(channel, sessionKey string) model.ChatModel {
+	l.sessionModelsMu.RLock()
+	selected, ok := l.sessionModels[sessionKey]
+	l.sessionModelsMu.RUnlock()
+	if ok {
+		return selected.model
+	}
+	if chatModel, ok := l.channelModels[channel]; ok && chatModel != nil {
+		return chatModel
+	}
+	return l.model
+}
+
+// SetMemoryEmbedder 为记忆召回设置向量模型，按 agents.memory 配置启用语义召回；传入 nil 时只使用关键词召回。
+func (l *Loop) SetMemoryEmbedder(embedder embedding.Embedder) {
+	if l.context == nil {
+		return
+	}
+	opts := memory.Options{Embedder: embedder}
+	if l.config != nil {
+		recall := l.config.Agents.Memory
+		opts.EmbeddingModel = recall.EmbeddingModel
+		opts.SemanticLimit = recall.SemanticLimit
+		opts.MaxIndexChunks = recall.MaxIndexChunks
+	}
+	l.context.SetMemoryOptions(opts)
+}
+
 // SetActivityRecorder 附加一个回调函数，用于跟踪最新活跃的通道/聊天。
 func (l *Loop) SetActivityRecorder(recorder func(channel, chatID string)) {
 	l.activityRecorder = recorder
 }
 
+// SetTypingNotifier 设置“正在输入”提示回调；处理消息期间会立即触发一次并周期性刷新。
+func (l *Loop) SetTypingNotifier(notifier func(ctx context.Context, channel, chatID string)) {
+	l.typingNotifier = notifier
+}
+
+// SetApprovalNotifier 设置审批通知回调；工具调用进入待审批状态时，会将通知发往发起调用的聊天。
+func (l *Loop) SetApprovalNotifier(notifier func(msg *bus.OutboundMessage)) {
+	l.approvalNotifier = notifier
+}
+
+// SetAttachmentLimit 设置查询通道单文件上传上限的回调；send_file 发送超限文件时直接向模型返回错误。
+func (l *Loop) SetAttachmentLimit(limit func(channel string) (int64, bool)) {
+	l.attachmentLimit = limit
+}
+
 // SetRuntimeMetrics 附加一个运行时指标记录器，用于工具执行统计。
 func (l *Loop) SetRuntimeMetrics(recorder *metrics.RuntimeMetrics) {
 	l.runtimeMetric = recorder
@@ -105,20 +308,47 @@ func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
 		func() (tool.InvokableTool, error) { return tools.NewWriteFileTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewEditFileTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewAppendFileTool(l.workspacePath) },
+		func() (tool.InvokableTool, error) { return tools.NewMoveFileTool(l.workspacePath) },
+		func() (tool.InvokableTool, error) { return tools.NewDeleteFileTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewListDirTool(l.workspacePath) },
+		func() (tool.InvokableTool, error) { return tools.NewSearchFilesTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewReadMemoryTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewWriteMemoryTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) { return tools.NewAppendDiaryTool(l.workspacePath) },
+		func() (tool.InvokableTool, error) { return tools.NewReadDiaryTool(l.workspacePath) },
 		func() (tool.InvokableTool, error) {
-			return tools.NewExecTool(
-				cfg.Tools.Exec.Timeout,
-				cfg.Tools.Exec.RestrictToWorkspace,
-				l.workspacePath,
-			)
+			var opts memory.Options
+			if l.context != nil {
+				opts = l.context.memoryOpts
+			}
+			return tools.NewRecallMemoryToolWithOptions(l.workspacePath, opts)
+		},
+		func() (tool.InvokableTool, error) {
+			return tools.NewExecToolWithOptions(tools.ExecToolOptions{
+				TimeoutSeconds:      cfg.Tools.Exec.Timeout,
+				RestrictToWorkspace: cfg.Tools.Exec.RestrictToWorkspace,
+				WorkspaceDir:        l.workspacePath,
+				AllowedCommands:     cfg.Tools.Exec.AllowedCommands,
+				BlockedCommands:     cfg.Tools.Exec.BlockedCommands,
+			})
 		},
-		func() (tool.InvokableTool, error) { return tools.NewWebFetchTool() },
 		func() (tool.InvokableTool, error) {
-			return tools.NewWebSearchTool(cfg.Tools.Web.Search.APIKey, cfg.Tools.Web.Search.MaxResults)
+			return tools.NewWebFetchToolWithOptions(tools.WebFetchOptions{
+				CacheTTL:         time.Duration(cfg.Tools.Web.Fetch.CacheTTLSeconds) * time.Second,
+				CacheMaxBytes:    cfg.Tools.Web.Fetch.CacheMaxBytes,
+				AllowPrivate:     cfg.Tools.Web.AllowPrivate,
+				RespectRobotsTxt: cfg.Tools.Web.Fetch.RespectRobotsTxt,
+				MaxRedirects:     cfg.Tools.Web.Fetch.MaxRedirects,
+			})
+		},
+		func() (tool.InvokableTool, error) {
+			return tools.NewWebSearchToolWithOptions(tools.WebSearchOptions{
+				Provider:   cfg.Tools.Web.Search.Provider,
+				APIKey:     cfg.Tools.Web.Search.APIKey,
+				EngineID:   cfg.Tools.Web.Search.EngineID,
+				MaxResults: cfg.Tools.Web.Search.MaxResults,
+				SearXNGURL: cfg.Tools.Web.Search.SearXNGURL,
+			})
 		},
 	}
 
@@ -148,10 +378,27 @@ func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
 		registered = append(registered, info.Name)
 	}
 
+	sendFileTool, err := tools.NewSendFileTool(l.bus, l.workspacePath, func(channel string) (int64, bool) {
+		if l.attachmentLimit == nil {
+			return 0, false
+		}
+		return l.attachmentLimit(channel)
+	})
+	if err != nil {
+		return err
+	}
+	if err := l.tools.Register(sendFileTool); err != nil {
+		return err
+	}
+	if info, err := sendFileTool.Info(context.Background()); err == nil && info != nil && info.Name != "" {
+		registered = append(registered, info.Name)
+	}
+
 	l.subagents = NewSubagentManagerWithOptions(l.bus, l, SubagentManagerOptions{
 		Timeout:        time.Duration(cfg.Agents.Subagent.TimeoutSeconds) * time.Second,
 		Retry:          cfg.Agents.Subagent.Retry,
 		MaxConcurrency: cfg.Agents.Subagent.MaxConcurrency,
+		MaxDepth:       cfg.Agents.Subagent.MaxDepth,
 	})
 	spawnTool, err := tools.NewSpawnTool(l.subagents)
 	if err != nil {
@@ -262,6 +509,8 @@ func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
 		)
 	}
 
+	registered = append(registered, l.registerSkillTools(cfg)...)
+
 	if len(cfg.MCP.Servers) > 0 {
 		mgr := mcp.NewManager(cfg.MCP.Servers, mcp.DefaultConnectors())
 		if err := mgr.Connect(context.Background()); err != nil {
@@ -303,29 +552,79 @@ func (l *Loop) RegisterDefaultTools(cfg *config.Config) error {
 	return nil
 }
 
+// registerSkillTools 注册技能清单中声明的工具，返回成功注册的工具名。无效声明或与已有工具重名时
+// 记录警告并跳过，不影响启动。这些工具与内置工具一样经过运行时策略守卫。
+func (l *Loop) registerSkillTools(cfg *config.Config) []string {
+	specs, errs := skills.NewLoader(l.workspacePath).LoadTools()
+	for _, err := range errs {
+		slog.Warn("skill tool declaration skipped", "error", err)
+	}
+
+	opts := tools.SkillToolOptions{
+		Exec: tools.ExecToolOptions{
+			RestrictToWorkspace: cfg.Tools.Exec.RestrictToWorkspace,
+			WorkspaceDir:        l.workspacePath,
+			AllowedCommands:     cfg.Tools.Exec.AllowedCommands,
+			BlockedCommands:     cfg.Tools.Exec.BlockedCommands,
+		},
+		AllowPrivate: cfg.Tools.Web.AllowPrivate,
+	}
+	registered := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		t, err := tools.NewSkillTool(spec, opts)
+		if err == nil {
+			err = l.tools.Register(t)
+		}
+		if err != nil {
+			slog.Warn("skill tool not registered", "skill", spec.Skill, "tool", spec.Name, "error", err)
+			continue
+		}
+		registered = append(registered, spec.Name)
+	}
+	return registered
+}
+
 func (l *Loop) bindTools(ctx context.Context) error {
-	if l.model == nil {
+	if l.model == nil && len(l.channelModels) == 0 {
 		return nil
 	}
 	toolInfos, err := l.tools.GetToolInfos(ctx)
 	if err != nil {
 		return err
 	}
-	if binder, ok := l.model.(interface {
-		BindTools([]*schema.ToolInfo) error
-	}); ok {
-		return binder.BindTools(toolInfos)
+	chatModels := make([]model.ChatModel, 0, len(l.channelModels)+1)
+	if l.model != nil {
+		chatModels = append(chatModels, l.model)
+	}
+	for _, chatModel := range l.channelModels {
+		if chatModel != nil {
+			chatModels = append(chatModels, chatModel)
+		}
+	}
+	for _, chatModel := range chatModels {
+		if binder, ok := chatModel.(interface {
+			BindTools([]*schema.ToolInfo) error
+		}); ok {
+			if err := binder.BindTools(toolInfos); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-// Run 启动 Agent 循环
+// Run 启动 Agent 循环。不同会话的消息并发处理，并发数受 agents.defaults.max_concurrent_sessions 限制；
+// 同一会话内按顺序处理，收到新消息时进行中的上一轮会被取消，其结果不再发送。
+// 系统消息在其来源会话的当前轮次结束后发布。退出前等待所有进行中的轮次结束。
 func (l *Loop) Run(ctx context.Context) error {
 	if err := l.bindTools(ctx); err != nil {
 		return err
 	}
 
 	slog.Info("agent loop started")
+	l.running.Store(true)
+	defer l.running.Store(false)
+	defer l.turns.wg.Wait()
 
 	for {
 		select {
@@ -343,23 +642,13 @@ func (l *Loop) Run(ctx context.Context) error {
 				msg.RequestID = bus.NewRequestID()
 			}
 			if msg.Channel == bus.SystemChannel {
-				l.processSystemMessage(msg)
+				l.dispatchSystemMessage(msg)
 				continue
 			}
-			resp, err := l.processMessage(ctx, msg)
-			if err != nil {
-				slog.Error("process message failed", "request_id", msg.RequestID, "channel", msg.Channel, "chat_id", msg.ChatID, "session_key", msg.SessionKey(), "error", err)
-				l.bus.PublishOutbound(&bus.OutboundMessage{
-					Channel:   msg.Channel,
-					ChatID:    msg.ChatID,
-					Content:   "Error: " + err.Error(),
-					RequestID: msg.RequestID,
-				})
+			if l.isDuplicateInbound(msg) || !l.allowInbound(msg) || !l.limitInboundSize(msg) {
 				continue
 			}
-			if resp != nil {
-				l.bus.PublishOutbound(resp)
-			}
+			l.dispatchMessage(ctx, msg)
 		}
 	}
 }
@@ -375,14 +664,8 @@ func (l *Loop) processSystemMessage(msg *bus.InboundMessage) {
 		return
 	}
 
-	originChannel := strings.TrimSpace(fmt.Sprint(msg.Metadata[bus.SystemMetaOriginChannel]))
-	if originChannel == "" {
-		originChannel = "cli"
-	}
-	originChatID := strings.TrimSpace(fmt.Sprint(msg.Metadata[bus.SystemMetaOriginChatID]))
-	if originChatID == "" {
-		originChatID = "direct"
-	}
+	originChannel, originChatID := systemMessageOrigin(msg)
+	originThread, _ := msg.Metadata[bus.SystemMetaOriginThread].(string)
 
 	label := strings.TrimSpace(fmt.Sprint(msg.Metadata[bus.SystemMetaTaskLabel]))
 	content := strings.TrimSpace(msg.Content)
@@ -393,19 +676,49 @@ func (l *Loop) processSystemMessage(msg *bus.InboundMessage) {
 		content = "Subagent completed."
 	}
 
+	metadata := map[string]any{
+		bus.SystemMetaType:   bus.SystemTypeSubagentResult,
+		bus.SystemMetaTaskID: msg.Metadata[bus.SystemMetaTaskID],
+		bus.SystemMetaStatus: msg.Metadata[bus.SystemMetaStatus],
+	}
+	if thread := strings.TrimSpace(originThread); thread != "" {
+		metadata[bus.MetadataThreadID] = thread
+	}
 	l.bus.PublishOutbound(&bus.OutboundMessage{
 		Channel:   originChannel,
 		ChatID:    originChatID,
 		Content:   content,
 		RequestID: msg.RequestID,
-		Metadata: map[string]any{
-			bus.SystemMetaType:   bus.SystemTypeSubagentResult,
-			bus.SystemMetaTaskID: msg.Metadata[bus.SystemMetaTaskID],
-			bus.SystemMetaStatus: msg.Metadata[bus.SystemMetaStatus],
-		},
+		Metadata:  metadata,
 	})
 }
 
+// replyInThread 让回复沿用入站消息所在的线程（见 bus.MetadataThreadID），使其发到原线程而不是频道根部。
+func replyInThread(resp *bus.OutboundMessage, msg *bus.InboundMessage) *bus.OutboundMessage {
+	thread := msg.ThreadID()
+	if resp == nil || thread == "" {
+		return resp
+	}
+	if resp.Metadata == nil {
+		resp.Metadata = map[string]any{}
+	}
+	resp.Metadata[bus.MetadataThreadID] = thread
+	return resp
+}
+
+// systemMessageOrigin 返回系统消息需要回送的原始通道与聊天 ID，缺省为 CLI 直连会话。
+func systemMessageOrigin(msg *bus.InboundMessage) (channel, chatID string) {
+	channel = strings.TrimSpace(fmt.Sprint(msg.Metadata[bus.SystemMetaOriginChannel]))
+	if channel == "" {
+		channel = "cli"
+	}
+	chatID = strings.TrimSpace(fmt.Sprint(msg.Metadata[bus.SystemMetaOriginChatID]))
+	if chatID == "" {
+		chatID = "direct"
+	}
+	return channel, chatID
+}
+
 func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bus.OutboundMessage, error) {
 	slog.Info("processing message", "request_id", msg.RequestID, "channel", msg.Channel, "chat_id", msg.ChatID, "sender", msg.SenderID, "session_key", msg.SessionKey())
 	if l.activityRecorder != nil {
@@ -413,17 +726,31 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 	}
 
 	// Slash command interception — execute directly, skip LLM.
-	if cmd, args, ok := l.commands.Lookup(msg.Content); ok {
+	// 对待审批请求的 "approve <id>" / "reject <id>" 回复按对应斜杠命令处理。
+	content := msg.Content
+	if reply, ok := l.approvalReplyCommand(content); ok {
+		content = reply
+	}
+	if cmd, args, ok := l.commands.Lookup(content); ok {
 		result := cmd.Execute(ctx, args, command.Env{
-			Channel:       msg.Channel,
-			ChatID:        msg.ChatID,
-			SenderID:      msg.SenderID,
-			SessionKey:    msg.SessionKey(),
-			Sessions:      l.sessions,
-			WorkspacePath: l.workspacePath,
-			Config:        l.config,
-			Metrics:       l.runtimeMetric,
-			ListCommands:  l.commands.List,
+			Channel:          msg.Channel,
+			ChatID:           msg.ChatID,
+			SenderID:         msg.SenderID,
+			SenderUnverified: bus.SenderUnverified(ctx),
+			SessionKey:       msg.SessionKey(),
+			Sessions:         l.sessions,
+			WorkspacePath:    l.workspacePath,
+			Config:           l.config,
+			Metrics:          l.runtimeMetric,
+			ListCommands:     l.commands.List,
+			IsAdmin:          l.IsPolicyAdmin,
+			PolicyState:      l.PolicyState,
+			SetPolicyMode:    l.SetPolicyMode,
+			SessionModel:     l.SessionModel,
+			SetSessionModel: func(ctx context.Context, modelName string) error {
+				return l.SetSessionModel(ctx, msg.SessionKey(), modelName)
+			},
+			ListTools: l.ListTools,
 		})
 		return &bus.OutboundMessage{
 			Channel:   msg.Channel,
@@ -441,23 +768,53 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 		selectedSkillName = selectedSkills[0].Name
 	}
 
-	messages := l.context.BuildMessages(sess.GetHistory(50), msg.Content, msg.Media)
+	stopTyping := l.startTyping(ctx, msg.Channel, msg.ChatID)
+	defer stopTyping()
+
+	messages := l.context.BuildTurnMessages(ctx, TurnInput{
+		Channel: msg.Channel,
+		Summary: sess.Summary(),
+		History: sess.GetHistory(50),
+		Current: msg.Content,
+		Media:   msg.Media,
+		Vision:  l.sessionSupportsVision(msg.SessionKey(), msg.Channel),
+	})
 
 	var finalContent string
+	planned := false
 	learnedGeoSteps := make([]geopipeline.Step, 0)
 	hasGeoActivity := false
 	hasGeoFailure := false
+	chatModel := l.modelFor(msg.Channel, msg.SessionKey())
+	observer := bus.StreamObserverFromContext(ctx)
+	timedOut := false
+
+	// 整轮对话的时限覆盖所有模型与工具调用，超时后停止迭代并返回已有的部分结果。
+	turnCtx, cancelTurn := l.withTurnTimeout(ctx)
+	defer cancelTurn()
 
 	for i := 0; i < l.maxIterations; i++ {
-		if l.model == nil {
+		if chatModel == nil {
 			finalContent = "No model configured"
 			break
 		}
+		if turnTimedOut(turnCtx) {
+			timedOut = true
+			break
+		}
 
-		resp, err := l.model.Generate(ctx, messages)
+		resp, err := l.generateWithRetry(turnCtx, msg.RequestID, chatModel, messages)
 		if err != nil {
+			if turnTimedOut(turnCtx) {
+				timedOut = true
+				break
+			}
+			if ctx.Err() != nil && !planned {
+				l.recordCancelledTurn(sess, msg)
+			}
 			return nil, err
 		}
+		l.recordModelUsage(msg, resp)
 
 		// Always capture the latest content from the LLM response,
 		// even when tool calls are present.
@@ -469,6 +826,18 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 			break
 		}
 
+		if l.PlanOnly {
+			for _, tc := range resp.ToolCalls {
+				if l.OnToolPlanned != nil {
+					l.OnToolPlanned(tc.Function.Name, tc.Function.Arguments)
+				}
+				l.appendAuditEvent(ctx, "tool_planned", msg.RequestID, tc.Function.Name, "planned")
+			}
+			finalContent = formatToolPlan(resp.Content, resp.ToolCalls)
+			planned = true
+			break
+		}
+
 		messages = append(messages, resp)
 
 		type toolResult struct {
@@ -480,41 +849,65 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 
 		resultChan := make(chan toolResult, len(resp.ToolCalls))
 		var wg sync.WaitGroup
+		// 限制同时执行的工具数，避免模型一次返回大量调用时耗尽 exec/web 资源；结果仍按 index 还原顺序。
+		toolSlots := newToolSlots(l.maxParallel)
 
 		for i, tc := range resp.ToolCalls {
 			wg.Add(1)
+			toolSlots <- struct{}{}
 			go func(i int, tc schema.ToolCall) {
 				defer wg.Done()
+				defer func() { <-toolSlots }()
 				toolStart := time.Now()
 				slog.Debug("executing tool", "request_id", msg.RequestID, "name", tc.Function.Name)
 
 				if l.OnToolStart != nil {
 					l.OnToolStart(tc.Function.Name, tc.Function.Arguments)
 				}
+				if observer != nil && observer.OnToolStart != nil {
+					observer.OnToolStart(tc.Function.Name, tc.Function.Arguments)
+				}
 
-				toolCtx := tools.WithInvocationContext(ctx, tools.InvocationContext{
+				toolCtx := tools.WithInvocationContext(turnCtx, tools.InvocationContext{
 					Channel:   msg.Channel,
 					ChatID:    msg.ChatID,
+					ThreadID:  msg.ThreadID(),
 					SenderID:  msg.SenderID,
 					RequestID: msg.RequestID,
 					SessionID: msg.SessionKey(),
 				})
 
-				result, err := l.tools.Execute(toolCtx, tc.Function.Name, tc.Function.Arguments)
-				if err != nil {
-					result = "Error: " + err.Error()
-				}
-
-				if err == nil && (tc.Function.Name == "write_file" || tc.Function.Name == "edit_file" || tc.Function.Name == "append_file") {
-					var fileArg struct {
-						Path string `json:"path"`
+				execResult, err := l.tools.ExecuteResult(toolCtx, tc.Function.Name, tc.Function.Arguments)
+				result := tools.ModelContent(execResult, err)
+				outcome := tools.Classify(execResult, err)
+				l.tools.RecordOutcome(tc.Function.Name, outcome, err)
+
+				switch tc.Function.Name {
+				case "write_file", "edit_file", "append_file", "delete_file":
+					if err == nil {
+						var fileArg struct {
+							Path string `json:"path"`
+						}
+						// Best effort parsing; if fails, we pass empty string which forces invalidation
+						_ = json.Unmarshal([]byte(tc.Function.Arguments), &fileArg)
+						l.context.InvalidateCache(fileArg.Path)
+					}
+				case "move_file":
+					if err == nil {
+						var moveArg struct {
+							Source      string `json:"source"`
+							Destination string `json:"destination"`
+						}
+						if json.Unmarshal([]byte(tc.Function.Arguments), &moveArg) != nil {
+							l.context.InvalidateCache("")
+						} else {
+							l.context.InvalidateCache(moveArg.Source)
+							l.context.InvalidateCache(moveArg.Destination)
+						}
 					}
-					// Best effort parsing; if fails, we pass empty string which forces invalidation
-					_ = json.Unmarshal([]byte(tc.Function.Arguments), &fileArg)
-					l.context.InvalidateCache(fileArg.Path)
 				}
 
-				l.auditToolExecution(toolCtx, tc.Function.Name, result, err)
+				l.auditToolExecution(toolCtx, tc.Function.Name, outcome, err)
 				toolDuration := time.Since(toolStart)
 				logAttrs := []any{
 					"request_id", msg.RequestID,
@@ -524,9 +917,12 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 					"tool_duration", toolDuration.String(),
 					"duration_ms", toolDuration.Milliseconds(),
 					"success", err == nil,
+					"outcome", string(outcome),
 				}
 				if l.runtimeMetric != nil {
-					snapshot, metricErr := l.runtimeMetric.RecordToolExecution(toolDuration, result, err)
+					// 工具上下文超过截止时间（整轮时限或工具自身时限）时明确标记为超时，而不是从错误文本猜测。
+					timedOut := outcome == tools.OutcomeTimeout || errors.Is(toolCtx.Err(), context.DeadlineExceeded)
+					snapshot, metricErr := l.runtimeMetric.RecordToolExecution(toolDuration, result, err, timedOut)
 					if metricErr != nil {
 						slog.Warn("record runtime metrics failed", "scope", "tool", "error", metricErr)
 					}
@@ -540,7 +936,13 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 				slog.Info("tool execution finished", logAttrs...)
 
 				if l.OnToolFinish != nil {
-					l.OnToolFinish(tc.Function.Name, result, err)
+					l.OnToolFinish(tc.Function.Name, result, outcome, err)
+				}
+				if observer != nil && observer.OnToolFinish != nil {
+					observer.OnToolFinish(tc.Function.Name, result, err)
+				}
+				if observer != nil && observer.OnToolOutput != nil && err == nil && len(execResult.Data) > 0 {
+					observer.OnToolOutput(bus.ToolOutput{CallID: tc.ID, Name: tc.Function.Name, Data: execResult.Data})
 				}
 
 				resultChan <- toolResult{
@@ -592,22 +994,162 @@ func (l *Loop) processMessage(ctx context.Context, msg *bus.InboundMessage) (*bu
 		_ = skills.NewTelemetryRecorder(l.workspacePath).RecordOutcome(selectedSkillName, !hasGeoFailure)
 	}
 
+	if timedOut {
+		slog.Warn("agent turn exceeded agents.defaults.turn_timeout_seconds", "request_id", msg.RequestID, "channel", msg.Channel, "chat_id", msg.ChatID, "session_key", msg.SessionKey(), "timeout", l.turnTimeout.String())
+		l.appendAuditEvent(ctx, "turn_timeout", msg.RequestID, "", "timed out after "+l.turnTimeout.String())
+		finalContent = turnTimeoutMessage(finalContent, l.turnTimeout)
+	}
+
 	if finalContent == "" {
 		finalContent = "Processing complete."
 	}
 
-	userMsg := sess.AddMessage("user", msg.Content)
-	asstMsg := sess.AddMessage("assistant", finalContent)
-	if err := l.sessions.Append(sess.Key, userMsg, asstMsg); err != nil {
-		slog.Warn("failed to append session messages", "error", err)
+	// 已被取消的轮次丢弃回复，只记下用户消息和取消说明，由取代它的新消息继续对话。
+	if err := ctx.Err(); err != nil {
+		if !planned {
+			l.recordCancelledTurn(sess, msg)
+		}
+		return nil, err
+	}
+
+	// 计划结果不写入会话历史，避免后续对话误以为这些工具已经执行过。
+	if !planned {
+		userMsg := sess.AddMessage("user", msg.Content)
+		asstMsg := sess.AddMessage("assistant", finalContent)
+		if err := l.sessions.Append(sess.Key, userMsg, asstMsg); err != nil {
+			slog.Warn("failed to append session messages", "error", err)
+		}
+		l.maybeSummarize(msg, sess, chatModel)
 	}
 
-	return &bus.OutboundMessage{
+	resp := &bus.OutboundMessage{
 		Channel:   msg.Channel,
 		ChatID:    msg.ChatID,
 		Content:   finalContent,
 		RequestID: msg.RequestID,
-	}, nil
+	}
+	// 用户发来的是语音时请求语音回复，是否合成由通道的 voice_replies 设置决定。
+	if transcribed, _ := msg.Metadata["transcribed_audio"].(bool); transcribed {
+		resp.Metadata = map[string]any{bus.MetadataVoiceReply: true}
+	}
+	return resp, nil
+}
+
+// cancelledTurnNote 是被取消轮次在会话历史中代替回复的说明。
+const cancelledTurnNote = "[This turn was cancelled before a reply was sent.]"
+
+// recordCancelledTurn 把被取消轮次的用户消息和一条取消说明写入会话历史，
+// 使后续轮次仍能看到用户说过的话，又不会把未发出的回复当作已答复。
+func (l *Loop) recordCancelledTurn(sess *session.Session, msg *bus.InboundMessage) {
+	userMsg := sess.AddMessage("user", msg.Content)
+	noteMsg := sess.AddMessage("assistant", cancelledTurnNote)
+	if err := l.sessions.Append(sess.Key, userMsg, noteMsg); err != nil {
+		slog.Warn("failed to append cancelled turn to session", "error", err)
+	}
+}
+
+// formatToolPlan 将模型提出但未执行的工具调用整理为可读的计划文本。
+func formatToolPlan(content string, calls []schema.ToolCall) string {
+	var sb strings.Builder
+	if text := strings.TrimSpace(content); text != "" {
+		sb.WriteString(text)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Planned tool calls (not executed):\n")
+	for i, tc := range calls {
+		fmt.Fprintf(&sb, "%d. %s %s\n", i+1, tc.Function.Name, normalizeArgsJSON(tc.Function.Arguments))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// generate 调用模型生成一轮回复。设置了 OnContentDelta 或 context 中带有增量文本观察者时使用流式接口，
+// 边接收边转发增量文本，最终合并为完整消息（包括穿插的工具调用）。
+func (l *Loop) generate(ctx context.Context, chatModel model.ChatModel, messages []*schema.Message) (*schema.Message, error) {
+	onDelta := l.contentDeltaHandler(ctx)
+	if onDelta == nil {
+		return chatModel.Generate(ctx, messages)
+	}
+
+	reader, err := chatModel.Stream(ctx, messages)
+	if err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		// 模型未实现流式输出，回退到一次性生成
+		return chatModel.Generate(ctx, messages)
+	}
+	defer reader.Close()
+
+	chunks := make([]*schema.Message, 0, 32)
+	for {
+		chunk, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if len(chunks) > 0 {
+				// 已有增量文本推送给调用方，重试会导致重复输出
+				return nil, &partialStreamError{err: err}
+			}
+			return nil, err
+		}
+		if chunk == nil {
+			continue
+		}
+		chunks = append(chunks, chunk)
+		if chunk.Content != "" {
+			onDelta(chunk.Content)
+		}
+	}
+	if len(chunks) == 0 {
+		return &schema.Message{Role: schema.Assistant}, nil
+	}
+	return schema.ConcatMessages(chunks)
+}
+
+// contentDeltaHandler 合并 Loop 级与请求级（context 中的 StreamObserver）增量文本回调，均未设置时返回 nil。
+func (l *Loop) contentDeltaHandler(ctx context.Context) func(string) {
+	var observerDelta func(string)
+	if observer := bus.StreamObserverFromContext(ctx); observer != nil {
+		observerDelta = observer.OnContentDelta
+	}
+	switch {
+	case l.OnContentDelta == nil:
+		return observerDelta
+	case observerDelta == nil:
+		return l.OnContentDelta
+	default:
+		return func(delta string) {
+			l.OnContentDelta(delta)
+			observerDelta(delta)
+		}
+	}
+}
+
+// recordModelUsage 从模型响应元数据中提取 Token 用量，按模型累加到会话，并写入运行时指标。
+func (l *Loop) recordModelUsage(msg *bus.InboundMessage, resp *schema.Message) {
+	if resp == nil || resp.ResponseMeta == nil || resp.ResponseMeta.Usage == nil {
+		return
+	}
+	usage := resp.ResponseMeta.Usage
+	modelName, _ := l.SessionModel(msg.SessionKey(), msg.Channel)
+	l.sessions.GetOrCreate(msg.SessionKey()).AddUsage(modelName, usage.PromptTokens, usage.CompletionTokens)
+
+	if l.runtimeMetric == nil {
+		return
+	}
+	snapshot, err := l.runtimeMetric.RecordModelUsage(usage.PromptTokens, usage.CompletionTokens)
+	if err != nil {
+		slog.Warn("record runtime metrics failed", "scope", "model", "error", err)
+		return
+	}
+	slog.Debug("model usage recorded",
+		"request_id", msg.RequestID,
+		"channel", msg.Channel,
+		"prompt_tokens", usage.PromptTokens,
+		"completion_tokens", usage.CompletionTokens,
+		"model_total_tokens", snapshot.Model.TotalTokens,
+	)
 }
 
 // ProcessForChannel 为给定的通道/会话直接处理消息。
@@ -642,10 +1184,22 @@ func (l *Loop) ProcessForChannelWithSession(ctx context.Context, channel, chatID
 		msg.RequestID = bus.NewRequestID()
 	}
 
+	// 网关 API 请求与渠道消息一样受 max_message_chars 限制；截断提示放在回复开头。
+	var notice string
+	if bus.SenderUnverified(ctx) {
+		var tooLong *bus.MessageTooLongError
+		if notice, tooLong = l.applyInboundSizeLimit(msg); tooLong != nil {
+			return "", tooLong
+		}
+	}
+
 	resp, err := l.processMessage(ctx, msg)
 	if err != nil {
 		return "", err
 	}
+	if notice != "" {
+		return notice + "\n\n" + resp.Content, nil
+	}
 	return resp.Content, nil
 }
 
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// streamingMockModel streams a tool call split across chunks on the first turn,
+// then streams the final answer in several text deltas.
+type streamingMockModel struct {
+	streamCalls   int
+	generateCalls int
+}
+
+func (m *streamingMockModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.generateCalls++
+	return &schema.Message{Role: schema.Assistant, Content: "generated"}, nil
+}
+
+func (m *streamingMockModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	m.streamCalls++
+	index := 0
+	if m.streamCalls == 1 {
+		return schema.StreamReaderFromArray([]*schema.Message{
+			{Role: schema.Assistant, Content: "Let me check. "},
+			{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{
+				Index:    &index,
+				ID:       "call_1",
+				Function: schema.FunctionCall{Name: "mock_tool", Arguments: `{"input":`},
+			}}},
+			{Role: schema.Assistant, ToolCalls: []schema.ToolCall{{
+				Index:    &index,
+				Function: schema.FunctionCall{Arguments: `"test"}`},
+			}}},
+		}), nil
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{
+		{Role: schema.Assistant, Content: "Final "},
+		{Role: schema.Assistant, Content: "streamed "},
+		{Role: schema.Assistant, Content: "answer"},
+	}), nil
+}
+
+func (m *streamingMockModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestProcessDirect_StreamsContentDeltasAcrossToolCalls(t *testing.T) {
+	mockModel := &streamingMockModel{}
+	loop := newTestLoop(t, mockModel, 5)
+	if err := loop.tools.Register(&testTool{}); err != nil {
+		t.Fatalf("failed to register mock tool: %v", err)
+	}
+
+	var deltas []string
+	var toolArgs string
+	loop.OnContentDelta = func(delta string) {
+		deltas = append(deltas, delta)
+	}
+	loop.OnToolStart = func(name, args string) {
+		toolArgs = args
+	}
+
+	result, err := loop.ProcessDirect(context.Background(), "stream please")
+	if err != nil {
+		t.Fatalf("ProcessDirect returned error: %v", err)
+	}
+	if result != "Final streamed answer" {
+		t.Fatalf("expected concatenated final answer, got %q", result)
+	}
+	if mockModel.streamCalls != 2 || mockModel.generateCalls != 0 {
+		t.Fatalf("expected 2 stream calls and no generate calls, got stream=%d generate=%d", mockModel.streamCalls, mockModel.generateCalls)
+	}
+	if toolArgs != `{"input":"test"}` {
+		t.Fatalf("expected merged tool call arguments, got %q", toolArgs)
+	}
+	if got := strings.Join(deltas, "|"); got != "Let me check. |Final |streamed |answer" {
+		t.Fatalf("unexpected deltas: %q", got)
+	}
+}
+
+func TestProcessDirect_WithoutDeltaHandlerUsesGenerate(t *testing.T) {
+	mockModel := &streamingMockModel{}
+	loop := newTestLoop(t, mockModel, 5)
+
+	result, err := loop.ProcessDirect(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("ProcessDirect returned error: %v", err)
+	}
+	if result != "generated" {
+		t.Fatalf("expected generate result, got %q", result)
+	}
+	if mockModel.streamCalls != 0 {
+		t.Fatalf("expected no stream calls, got %d", mockModel.streamCalls)
+	}
+}
+
+func TestProcessForChannel_StreamsToContextObserver(t *testing.T) {
+	mockModel := &streamingMockModel{}
+	loop := newTestLoop(t, mockModel, 5)
+	if err := loop.tools.Register(&testTool{}); err != nil {
+		t.Fatalf("failed to register mock tool: %v", err)
+	}
+
+	var deltas []string
+	var started, finished []string
+	ctx := bus.WithStreamObserver(context.Background(), &bus.StreamObserver{
+		OnContentDelta: func(delta string) { deltas = append(deltas, delta) },
+		OnToolStart:    func(name, args string) { started = append(started, name) },
+		OnToolFinish:   func(name, result string, err error) { finished = append(finished, name) },
+	})
+
+	result, err := loop.ProcessForChannel(ctx, "gateway", "web", "api", "stream please")
+	if err != nil {
+		t.Fatalf("ProcessForChannel returned error: %v", err)
+	}
+	if result != "Final streamed answer" {
+		t.Fatalf("unexpected result %q", result)
+	}
+	if mockModel.streamCalls != 2 || mockModel.generateCalls != 0 {
+		t.Fatalf("expected streaming calls only, got stream=%d generate=%d", mockModel.streamCalls, mockModel.generateCalls)
+	}
+	if got := strings.Join(deltas, ""); got != "Let me check. Final streamed answer" {
+		t.Fatalf("unexpected deltas %q", got)
+	}
+	if len(started) != 1 || started[0] != "mock_tool" || len(finished) != 1 {
+		t.Fatalf("unexpected tool events: started=%v finished=%v", started, finished)
+	}
+}
+
+type structuredTestTool struct{}
+
+func (t *structuredTestTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "mock_tool", Desc: "A structured test tool"}, nil
+}
+
+func (t *structuredTestTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	return `{"status":"ok","count":2}`, nil
+}
+
+func TestProcessForChannel_ReportsStructuredToolOutputs(t *testing.T) {
+	mockModel := &streamingMockModel{}
+	loop := newTestLoop(t, mockModel, 5)
+	if err := loop.tools.Register(tools.AsStructured(&structuredTestTool{})); err != nil {
+		t.Fatalf("failed to register structured tool: %v", err)
+	}
+
+	var outputs []bus.ToolOutput
+	var finishedResult string
+	ctx := bus.WithStreamObserver(context.Background(), &bus.StreamObserver{
+		OnContentDelta: func(string) {},
+		OnToolFinish:   func(name, result string, err error) { finishedResult = result },
+		OnToolOutput:   func(output bus.ToolOutput) { outputs = append(outputs, output) },
+	})
+
+	if _, err := loop.ProcessForChannel(ctx, "gateway", "web", "api", "hello"); err != nil {
+		t.Fatalf("ProcessForChannel returned error: %v", err)
+	}
+	if finishedResult != `{"status":"ok","count":2}` {
+		t.Fatalf("tool result text should be unchanged, got %q", finishedResult)
+	}
+	if len(outputs) != 1 || outputs[0].Name != "mock_tool" || outputs[0].CallID == "" || string(outputs[0].Data) != `{"status":"ok","count":2}` {
+		t.Fatalf("unexpected structured outputs: %+v", outputs)
+	}
+}
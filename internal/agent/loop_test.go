@@ -12,6 +12,7 @@ import (
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/session"
 	"github.com/MEKXH/golem/internal/skills"
 	"github.com/MEKXH/golem/internal/tools"
@@ -341,6 +342,58 @@ func TestProcessDirect_MaxIterations(t *testing.T) {
 	}
 }
 
+type countingTool struct {
+	calls int
+}
+
+func (t *countingTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "mock_tool", Desc: "A counting test tool"}, nil
+}
+
+func (t *countingTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	t.calls++
+	return "tool executed successfully", nil
+}
+
+func TestProcessDirect_PlanOnlyDoesNotExecuteTools(t *testing.T) {
+	mockModel := &alwaysToolCallModel{}
+	loop := newTestLoop(t, mockModel, 5)
+	loop.PlanOnly = true
+
+	counter := &countingTool{}
+	if err := loop.tools.Register(counter); err != nil {
+		t.Fatalf("failed to register mock tool: %v", err)
+	}
+
+	var planned []string
+	loop.OnToolPlanned = func(name, args string) {
+		planned = append(planned, name+" "+args)
+	}
+
+	result, err := loop.ProcessDirect(context.Background(), "test message")
+	if err != nil {
+		t.Fatalf("ProcessDirect returned error: %v", err)
+	}
+
+	if counter.calls != 0 {
+		t.Fatalf("expected tool not to be executed in plan mode, got %d calls", counter.calls)
+	}
+	if mockModel.callCount != 1 {
+		t.Fatalf("expected a single model call in plan mode, got %d", mockModel.callCount)
+	}
+	if !strings.Contains(result, "Planned tool calls (not executed)") || !strings.Contains(result, `1. mock_tool {"input":"loop"}`) {
+		t.Fatalf("expected plan listing in result, got %q", result)
+	}
+	if len(planned) != 1 || planned[0] != `mock_tool {"input":"loop"}` {
+		t.Fatalf("expected OnToolPlanned callback, got %v", planned)
+	}
+
+	history := loop.sessions.GetOrCreate("cli:direct").GetHistory(0)
+	if len(history) != 0 {
+		t.Fatalf("expected plan mode not to persist session history, got %d messages", len(history))
+	}
+}
+
 func TestRun_IgnoresNilInboundMessage(t *testing.T) {
 	loop := newTestLoop(t, nil, 1)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -408,7 +461,8 @@ func TestRegisterDefaultTools_WithoutWebSearchKey(t *testing.T) {
 	if !slices.Contains(names, "web_fetch") {
 		t.Fatalf("expected web_fetch to be registered, got: %v", names)
 	}
-	if !slices.Contains(names, "read_memory") || !slices.Contains(names, "write_memory") || !slices.Contains(names, "append_diary") {
+	if !slices.Contains(names, "read_memory") || !slices.Contains(names, "write_memory") || !slices.Contains(names, "append_diary") ||
+		!slices.Contains(names, "read_diary") || !slices.Contains(names, "recall_memory") {
 		t.Fatalf("expected memory tools to be registered, got: %v", names)
 	}
 	if !slices.Contains(names, "web_search") {
@@ -440,6 +494,43 @@ func TestRegisterDefaultTools_WithWebSearchKey(t *testing.T) {
 	}
 }
 
+func TestRegisterDefaultTools_RegistersSkillDeclaredTools(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	t.Setenv("GOLEM_BUILTIN_SKILLS_DIR", filepath.Join(tmpDir, "builtin"))
+
+	cfg := config.DefaultConfig()
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), nil)
+	if err != nil {
+		t.Fatalf("NewLoop error: %v", err)
+	}
+	manifest := "---\nname: notes\ndescription: Notes\ntools:\n" +
+		"  - name: notes_count\n    description: Count notes\n    exec:\n      command: ls notes | wc -l\n" +
+		"  - name: exec\n    description: Shadows the builtin exec tool\n    exec:\n      command: true\n---\n"
+	skillDir := filepath.Join(loop.workspacePath, "skills", "notes")
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools error: %v", err)
+	}
+	if !slices.Contains(loop.tools.Names(), "notes_count") {
+		t.Fatalf("expected skill tool to be registered, got: %v", loop.tools.Names())
+	}
+	execTool, ok := loop.tools.Get("exec")
+	if !ok {
+		t.Fatal("expected builtin exec tool to stay registered")
+	}
+	if info, err := execTool.Info(context.Background()); err != nil || strings.Contains(info.Desc, "skill") {
+		t.Fatalf("expected builtin exec tool to win over the skill declaration, got %+v (%v)", info, err)
+	}
+}
+
 func TestRegisterDefaultTools_GeoSpatialQuerySkippedWithoutDSN(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Tools.Geo.Enabled = true
@@ -592,6 +683,34 @@ func TestRun_HandlesSubagentSystemMessage(t *testing.T) {
 	}
 }
 
+func TestProcessSystemMessage_RepliesInOriginThread(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+
+	msg := bus.NewSubagentResultInbound("subagent-1", "", "slack", "C1", "alice", "done", "req-1", nil)
+	msg.Metadata[bus.SystemMetaOriginThread] = "1700000000.1"
+	loop.processSystemMessage(msg)
+
+	select {
+	case out := <-loop.bus.Outbound():
+		if out.Channel != "slack" || out.ChatID != "C1" || out.ThreadID() != "1700000000.1" {
+			t.Fatalf("expected reply routed to the origin thread, got %+v", out)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for outbound system relay")
+	}
+}
+
+func TestReplyInThread_CopiesInboundThread(t *testing.T) {
+	in := &bus.InboundMessage{Metadata: map[string]any{bus.MetadataThreadID: "t1"}}
+	resp := replyInThread(&bus.OutboundMessage{Metadata: map[string]any{bus.MetadataVoiceReply: true}}, in)
+	if resp.ThreadID() != "t1" || !resp.WantsVoiceReply() {
+		t.Fatalf("expected thread added alongside existing metadata, got %+v", resp.Metadata)
+	}
+	if got := replyInThread(&bus.OutboundMessage{}, &bus.InboundMessage{}); got.Metadata != nil {
+		t.Fatalf("expected no metadata outside threads, got %+v", got.Metadata)
+	}
+}
+
 func TestProcessForChannel_RecordsActivity(t *testing.T) {
 	loop := newTestLoop(t, nil, 1)
 
@@ -720,3 +839,209 @@ func TestProcessDirect_RecordsSkillTelemetryFailureForSingleMatchedGeoSkill(t *t
 		t.Fatalf("expected failure counter to be recorded, got %+v", snapshot.Skills["spatial-analysis"])
 	}
 }
+
+type fixedReplyModel struct {
+	reply string
+	usage *schema.TokenUsage
+	calls int
+}
+
+func (m *fixedReplyModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	msg := &schema.Message{Role: schema.Assistant, Content: m.reply}
+	if m.usage != nil {
+		msg.ResponseMeta = &schema.ResponseMeta{Usage: m.usage}
+	}
+	return msg, nil
+}
+
+func (m *fixedReplyModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *fixedReplyModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestProcessForChannel_UsesChannelModelOverride(t *testing.T) {
+	defaultModel := &fixedReplyModel{reply: "default"}
+	telegramModel := &fixedReplyModel{reply: "telegram"}
+	loop := newTestLoop(t, defaultModel, 3)
+	loop.SetChannelModels(map[string]model.ChatModel{"telegram": telegramModel})
+
+	got, err := loop.ProcessForChannel(context.Background(), "telegram", "chat-1", "u1", "hi")
+	if err != nil {
+		t.Fatalf("ProcessForChannel error: %v", err)
+	}
+	if got != "telegram" || telegramModel.calls != 1 || defaultModel.calls != 0 {
+		t.Fatalf("expected telegram override model, got %q (default=%d telegram=%d)", got, defaultModel.calls, telegramModel.calls)
+	}
+
+	got, err = loop.ProcessForChannel(context.Background(), "slack", "chat-2", "u1", "hi")
+	if err != nil {
+		t.Fatalf("ProcessForChannel error: %v", err)
+	}
+	if got != "default" || defaultModel.calls != 1 {
+		t.Fatalf("expected default model for slack, got %q", got)
+	}
+}
+
+func TestModelCommand_SwitchesModelForSessionOnly(t *testing.T) {
+	defaultModel := &fixedReplyModel{reply: "default"}
+	switched := &fixedReplyModel{reply: "switched"}
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	loop, err := NewLoop(config.DefaultConfig(), bus.NewMessageBus(1), defaultModel)
+	if err != nil {
+		t.Fatalf("NewLoop error: %v", err)
+	}
+	var requested []string
+	loop.SetModelFactory(func(ctx context.Context, modelName string) (model.ChatModel, error) {
+		requested = append(requested, modelName)
+		if modelName == "openai/missing" {
+			return nil, fmt.Errorf("provider %q referenced by model %q is not configured", "openai", modelName)
+		}
+		return switched, nil
+	})
+
+	ask := func(chatID, content string) string {
+		t.Helper()
+		got, err := loop.ProcessForChannel(context.Background(), "telegram", chatID, "u1", content)
+		if err != nil {
+			t.Fatalf("ProcessForChannel(%q) error: %v", content, err)
+		}
+		return got
+	}
+
+	if got := ask("chat-1", "/model openai/missing"); !strings.Contains(got, "Cannot switch") || !strings.Contains(got, "not configured") {
+		t.Fatalf("expected rejection for unconfigured model, got %q", got)
+	}
+	if got := ask("chat-1", "/model deepseek/deepseek-chat"); !strings.Contains(got, "Switched this session to `deepseek/deepseek-chat`") {
+		t.Fatalf("expected switch confirmation, got %q", got)
+	}
+	if got := ask("chat-1", "hi"); got != "switched" {
+		t.Fatalf("expected switched model for chat-1, got %q", got)
+	}
+	if got := ask("chat-2", "hi"); got != "default" {
+		t.Fatalf("expected default model for another session, got %q", got)
+	}
+	if got := ask("chat-1", "/model"); !strings.Contains(got, "deepseek/deepseek-chat") || !strings.Contains(got, "switched for this session") {
+		t.Fatalf("expected current session model, got %q", got)
+	}
+
+	ask("chat-1", "/model reset")
+	if got := ask("chat-1", "hi"); got != "default" {
+		t.Fatalf("expected default model after reset, got %q", got)
+	}
+	if len(requested) != 2 {
+		t.Fatalf("expected factory to be called twice, got %v", requested)
+	}
+}
+
+func TestClearCommand_DropsHistoryButKeepsSessionSettings(t *testing.T) {
+	defaultModel := &fixedReplyModel{reply: "default"}
+	switched := &fixedReplyModel{reply: "switched"}
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	loop, err := NewLoop(config.DefaultConfig(), bus.NewMessageBus(1), defaultModel)
+	if err != nil {
+		t.Fatalf("NewLoop error: %v", err)
+	}
+	loop.SetModelFactory(func(ctx context.Context, modelName string) (model.ChatModel, error) {
+		return switched, nil
+	})
+
+	ask := func(content string) string {
+		t.Helper()
+		got, err := loop.ProcessForChannel(context.Background(), "telegram", "chat-1", "u1", content)
+		if err != nil {
+			t.Fatalf("ProcessForChannel(%q) error: %v", content, err)
+		}
+		return got
+	}
+	inbound := bus.InboundMessage{Channel: "telegram", ChatID: "chat-1"}
+	sessionKey := inbound.SessionKey()
+
+	if got := ask("/clear"); !strings.Contains(got, "Nothing to clear") {
+		t.Fatalf("expected empty session notice, got %q", got)
+	}
+	ask("/model deepseek/deepseek-chat")
+	ask("hello")
+	if got := ask("/clear"); !strings.Contains(got, "Cleared 2 messages") {
+		t.Fatalf("expected cleared count, got %q", got)
+	}
+	if history := loop.sessions.GetOrCreate(sessionKey).GetHistory(0); len(history) != 0 {
+		t.Fatalf("expected empty history after /clear, got %d messages", len(history))
+	}
+	if got := ask("hi"); got != "switched" {
+		t.Fatalf("expected /clear to keep the session model, got %q", got)
+	}
+
+	ask("/new")
+	if got := ask("hi"); got != "default" {
+		t.Fatalf("expected /new to reset the session model, got %q", got)
+	}
+}
+
+func TestProcessDirect_RecordsModelTokenUsage(t *testing.T) {
+	chatModel := &fixedReplyModel{
+		reply: "ok",
+		usage: &schema.TokenUsage{PromptTokens: 120, CompletionTokens: 30, TotalTokens: 150},
+	}
+	loop := newTestLoop(t, chatModel, 3)
+	recorder := metrics.NewRuntimeMetrics(loop.workspacePath)
+	defer recorder.Close()
+	loop.SetRuntimeMetrics(recorder)
+
+	for i := 0; i < 2; i++ {
+		if _, err := loop.ProcessDirect(context.Background(), "hi"); err != nil {
+			t.Fatalf("ProcessDirect error: %v", err)
+		}
+	}
+
+	snap := recorder.Snapshot()
+	if snap.Model.Calls != 2 || snap.Model.PromptTokens != 240 || snap.Model.CompletionTokens != 60 || snap.Model.TotalTokens != 300 {
+		t.Fatalf("unexpected model usage: %+v", snap.Model)
+	}
+}
+
+func TestUsageCommand_ReportsSessionTokensAndCost(t *testing.T) {
+	chatModel := &fixedReplyModel{
+		reply: "ok",
+		usage: &schema.TokenUsage{PromptTokens: 1000, CompletionTokens: 200, TotalTokens: 1200},
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	cfg := config.DefaultConfig()
+	cfg.Agents.Defaults.ModelPrices = map[string]config.ModelPrice{
+		cfg.Agents.Defaults.Model: {InputPerMillion: 3, OutputPerMillion: 15},
+	}
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), chatModel)
+	if err != nil {
+		t.Fatalf("NewLoop error: %v", err)
+	}
+
+	if got, _ := loop.ProcessDirect(context.Background(), "/usage"); !strings.Contains(got, "No model calls recorded yet.") {
+		t.Fatalf("expected empty usage, got %q", got)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := loop.ProcessDirect(context.Background(), "hi"); err != nil {
+			t.Fatalf("ProcessDirect error: %v", err)
+		}
+	}
+
+	got, err := loop.ProcessDirect(context.Background(), "/usage")
+	if err != nil {
+		t.Fatalf("ProcessDirect(/usage) error: %v", err)
+	}
+	want := fmt.Sprintf("- `%s`: 2000 prompt + 400 completion = 2400 tokens (2 calls), ~0.0120", cfg.Agents.Defaults.Model)
+	if !strings.Contains(got, want) || !strings.Contains(got, "estimated cost ~0.0120") {
+		t.Fatalf("expected per-model usage and cost, got %q", got)
+	}
+	if got, _ := loop.ProcessForChannel(context.Background(), "telegram", "chat-9", "u1", "/usage"); !strings.Contains(got, "No model calls recorded yet.") {
+		t.Fatalf("expected usage to be tracked per session, got %q", got)
+	}
+}
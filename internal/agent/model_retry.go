@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// modelRetryPolicy 控制模型调用遇到瞬时错误时的重试行为；零值表示不重试。
+type modelRetryPolicy struct {
+	maxAttempts int           // 最大尝试次数（含首次）
+	baseBackoff time.Duration // 首次重试前的基础退避
+	maxBackoff  time.Duration // 单次退避上限
+}
+
+func newModelRetryPolicy(d config.AgentDefaults) modelRetryPolicy {
+	return modelRetryPolicy{
+		maxAttempts: d.ModelRetryMaxAttempts,
+		baseBackoff: time.Duration(d.ModelRetryBaseBackoffMs) * time.Millisecond,
+		maxBackoff:  time.Duration(d.ModelRetryMaxBackoffMs) * time.Millisecond,
+	}
+}
+
+// backoff 返回第 attempt 次失败后的等待时间：指数增长并封顶，再取 [d/2, d] 区间内的随机值。
+func (p modelRetryPolicy) backoff(attempt int) time.Duration {
+	d := p.baseBackoff
+	if d <= 0 {
+		return 0
+	}
+	for i := 1; i < attempt && (p.maxBackoff <= 0 || d < p.maxBackoff); i++ {
+		d *= 2
+	}
+	if p.maxBackoff > 0 && d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// partialStreamError 表示流式输出中途失败且已推送过增量文本，此类错误不再重试。
+type partialStreamError struct {
+	err error
+}
+
+func (e *partialStreamError) Error() string { return e.err.Error() }
+func (e *partialStreamError) Unwrap() error { return e.err }
+
+// generateWithRetry 调用模型生成一轮回复，对超时、限流和 5xx 等瞬时错误按退避策略重试。
+func (l *Loop) generateWithRetry(ctx context.Context, requestID string, chatModel model.ChatModel, messages []*schema.Message) (*schema.Message, error) {
+	maxAttempts := l.modelRetry.maxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := l.generate(ctx, chatModel, messages)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= maxAttempts || !isRetryableModelError(ctx, err) {
+			return nil, err
+		}
+
+		delay := l.modelRetry.backoff(attempt)
+		slog.Warn("model call failed, retrying",
+			"request_id", requestID,
+			"attempt", attempt,
+			"max_attempts", maxAttempts,
+			"backoff", delay,
+			"error", err,
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+var modelErrorStatusPattern = regexp.MustCompile(`(?i)(?:status(?:\s*code)?\s*[:=]?\s*|":\s*)(\d{3})\b`)
+
+var retryableModelErrorHints = []string{
+	"rate limit",
+	"too many requests",
+	"overloaded",
+	"timeout",
+	"timed out",
+	"temporarily unavailable",
+	"service unavailable",
+	"bad gateway",
+	"connection reset",
+	"connection refused",
+}
+
+// isRetryableModelError 判断模型错误是否为瞬时错误。调用方 ctx 已结束、流式输出中断，
+// 以及请求校验类（4xx）错误均不重试。
+func isRetryableModelError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var partial *partialStreamError
+	if errors.As(err, &partial) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	if m := modelErrorStatusPattern.FindStringSubmatch(msg); m != nil {
+		if code, convErr := strconv.Atoi(m[1]); convErr == nil && code >= 400 {
+			return code == 408 || code == 429 || code >= 500
+		}
+	}
+	for _, hint := range retryableModelErrorHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// flakyModel returns the queued errors in order before replying successfully.
+type flakyModel struct {
+	errs  []error
+	calls int
+}
+
+func (m *flakyModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return nil, m.errs[m.calls-1]
+	}
+	return &schema.Message{Role: schema.Assistant, Content: "recovered"}, nil
+}
+
+func (m *flakyModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *flakyModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestProcessDirect_RetriesTransientModelErrors(t *testing.T) {
+	mockModel := &flakyModel{errs: []error{
+		errors.New("error, status code: 429, message: rate limit reached"),
+		errors.New("error, status code: 503, message: upstream unavailable"),
+	}}
+	loop := newTestLoop(t, mockModel, 2)
+	loop.modelRetry = modelRetryPolicy{maxAttempts: 3, baseBackoff: time.Millisecond, maxBackoff: 2 * time.Millisecond}
+
+	result, err := loop.ProcessDirect(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("ProcessDirect returned error: %v", err)
+	}
+	if result != "recovered" || mockModel.calls != 3 {
+		t.Fatalf("expected recovery after 3 calls, got %q after %d", result, mockModel.calls)
+	}
+}
+
+func TestProcessDirect_DoesNotRetryValidationErrors(t *testing.T) {
+	mockModel := &flakyModel{errs: []error{
+		errors.New("error, status code: 400, message: invalid tool schema"),
+	}}
+	loop := newTestLoop(t, mockModel, 2)
+	loop.modelRetry = modelRetryPolicy{maxAttempts: 3, baseBackoff: time.Millisecond}
+
+	if _, err := loop.ProcessDirect(context.Background(), "hello"); err == nil {
+		t.Fatal("expected validation error to be returned")
+	}
+	if mockModel.calls != 1 {
+		t.Fatalf("expected no retry for validation error, got %d calls", mockModel.calls)
+	}
+}
+
+func TestGenerateWithRetry_AbortsOnContextCancel(t *testing.T) {
+	mockModel := &flakyModel{errs: []error{
+		errors.New("status code: 500"),
+		errors.New("status code: 500"),
+	}}
+	loop := newTestLoop(t, mockModel, 1)
+	loop.modelRetry = modelRetryPolicy{maxAttempts: 3, baseBackoff: time.Hour, maxBackoff: time.Hour}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := loop.generateWithRetry(ctx, "req-1", mockModel, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context error, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected retry wait to abort promptly, took %s", time.Since(start))
+	}
+	if mockModel.calls != 1 {
+		t.Fatalf("expected a single call before cancellation, got %d", mockModel.calls)
+	}
+}
+
+func TestIsRetryableModelError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("error, status code: 429, message: slow down"), true},
+		{errors.New(`POST "https://api.anthropic.com/v1/messages": 529 Overloaded`), true},
+		{errors.New("status code: 502"), true},
+		{errors.New("status code: 401, message: invalid api key"), false},
+		{errors.New("invalid request: messages must not be empty"), false},
+		{fmt.Errorf("request failed: %w", context.DeadlineExceeded), true},
+		{context.Canceled, false},
+		{errors.New("read tcp: connection reset by peer"), true},
+		{&partialStreamError{err: errors.New("status code: 500")}, false},
+	}
+	for _, tc := range cases {
+		if got := isRetryableModelError(context.Background(), tc.err); got != tc.want {
+			t.Errorf("isRetryableModelError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if isRetryableModelError(ctx, errors.New("status code: 503")) {
+		t.Error("expected cancelled caller context to disable retry")
+	}
+}
+
+func TestModelRetryPolicy_BackoffIsCappedWithJitter(t *testing.T) {
+	p := modelRetryPolicy{maxAttempts: 5, baseBackoff: 100 * time.Millisecond, maxBackoff: 300 * time.Millisecond}
+	for i := 0; i < 20; i++ {
+		if d := p.backoff(1); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("attempt 1 backoff out of range: %s", d)
+		}
+		if d := p.backoff(4); d < 150*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("attempt 4 backoff should be capped, got %s", d)
+		}
+	}
+}
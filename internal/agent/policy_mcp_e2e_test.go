@@ -2,6 +2,8 @@ package agent
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"testing"
@@ -10,6 +12,8 @@ import (
 	"github.com/MEKXH/golem/internal/approval"
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/policy"
+	"github.com/MEKXH/golem/internal/tools"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 )
@@ -78,6 +82,11 @@ func TestE2E_StrictMode_BlocksExecWithoutApproval(t *testing.T) {
 		t.Fatalf("RegisterDefaultTools() error: %v", err)
 	}
 
+	var outcome tools.Outcome
+	loop.OnToolFinish = func(name, result string, o tools.Outcome, err error) {
+		outcome = o
+	}
+
 	resp, err := loop.ProcessDirect(context.Background(), "trigger strict policy")
 	if err != nil {
 		t.Fatalf("ProcessDirect() error: %v", err)
@@ -85,6 +94,9 @@ func TestE2E_StrictMode_BlocksExecWithoutApproval(t *testing.T) {
 	if !strings.Contains(resp, "approval required") {
 		t.Fatalf("expected approval path response, got: %s", resp)
 	}
+	if outcome != tools.OutcomePendingApproval {
+		t.Fatalf("expected pending approval outcome, got %q", outcome)
+	}
 }
 
 func TestE2E_StrictMode_ApprovedRequestExecutes(t *testing.T) {
@@ -226,3 +238,452 @@ func TestE2E_MCPUnavailableServer_DoesNotCrashLoop(t *testing.T) {
 		t.Fatalf("expected loop to stay operational without model, got: %s", resp)
 	}
 }
+
+func TestReloadPolicy_SwapsGuardAndKeepsOldOnError(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "strict"
+	cfg.Policy.RequireApproval = []string{"exec"}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), &policyE2EModel{})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	relaxed := config.DefaultConfig()
+	relaxed.Policy.Mode = "relaxed"
+	if err := loop.ReloadPolicy(relaxed); err != nil {
+		t.Fatalf("ReloadPolicy() error: %v", err)
+	}
+	result, err := loop.evaluateToolGuard(context.Background(), "exec", `{"command":"echo hi"}`)
+	if err != nil {
+		t.Fatalf("evaluateToolGuard() error: %v", err)
+	}
+	if result.Action != tools.GuardAllow {
+		t.Fatalf("expected relaxed policy to allow exec, got %q", result.Action)
+	}
+
+	broken := config.DefaultConfig()
+	broken.Policy.Mode = "strict"
+	broken.Policy.RequireApproval = []string{"exec"}
+	broken.Policy.OffTTL = "not-a-duration"
+	if err := loop.ReloadPolicy(broken); err == nil {
+		t.Fatal("expected invalid off_ttl to fail reload")
+	}
+	if mode, _ := loop.guard().effectiveMode(time.Now()); mode != "relaxed" {
+		t.Fatalf("expected previous relaxed policy to stay active, got %q", mode)
+	}
+}
+
+func TestReloadPolicy_UpdatesAdmins(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "strict"
+	cfg.Policy.Admins = []string{"telegram:alice"}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), nil)
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	ctx := context.Background()
+
+	resp, err := loop.ProcessForChannel(ctx, "telegram", "42", "alice", "/policy relaxed")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if strings.Contains(resp, "requires an admin") {
+		t.Fatalf("expected configured admin to switch the mode, got: %s", resp)
+	}
+
+	// 重载后撤销的管理员立即失去权限，新增的管理员立即获得权限
+	reloaded := config.DefaultConfig()
+	reloaded.Policy.Mode = "strict"
+	reloaded.Policy.Admins = []string{"telegram:bob"}
+	if err := loop.ReloadPolicy(reloaded); err != nil {
+		t.Fatalf("ReloadPolicy() error: %v", err)
+	}
+	resp, err = loop.ProcessForChannel(ctx, "telegram", "42", "alice", "/policy relaxed")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "requires an admin") {
+		t.Fatalf("expected revoked admin to be refused, got: %s", resp)
+	}
+	if !loop.IsPolicyAdmin("telegram", "bob") || loop.IsPolicyAdmin("telegram", "alice") {
+		t.Fatal("expected reloaded admin list to replace the previous one")
+	}
+}
+
+func TestChannelToolPolicy_DeniesBeforeGlobalPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "off"
+	cfg.Channels.Discord.ToolPolicy = config.ToolPolicyConfig{Deny: []string{"exec", "write_file"}}
+	cfg.Channels.Slack.ToolPolicy = config.ToolPolicyConfig{Allow: []string{"read_file"}}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), &policyE2EModel{})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	cases := []struct {
+		channel string
+		tool    string
+		want    tools.GuardAction
+	}{
+		{"discord", "exec", tools.GuardDeny},
+		{"discord", "read_file", tools.GuardAllow},
+		{"slack", "read_file", tools.GuardAllow},
+		{"slack", "web_fetch", tools.GuardDeny},
+		{"telegram", "exec", tools.GuardAllow},
+	}
+	for _, tc := range cases {
+		ctx := tools.WithInvocationContext(context.Background(), tools.InvocationContext{Channel: tc.channel})
+		result, err := loop.evaluateToolGuard(ctx, tc.tool, `{}`)
+		if err != nil {
+			t.Fatalf("evaluateToolGuard(%s, %s) error: %v", tc.channel, tc.tool, err)
+		}
+		if result.Action != tc.want {
+			t.Fatalf("evaluateToolGuard(%s, %s) = %q, want %q", tc.channel, tc.tool, result.Action, tc.want)
+		}
+		if tc.want == tools.GuardDeny && !strings.Contains(result.Message, tc.channel) {
+			t.Fatalf("expected deny message to name channel %q, got %q", tc.channel, result.Message)
+		}
+	}
+}
+
+func TestToolsCommand_MarksToolsDisabledByChannelPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Channels.Discord.ToolPolicy = config.ToolPolicyConfig{Deny: []string{"exec"}}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), &policyE2EModel{})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	ask := func(channel, content string) string {
+		t.Helper()
+		got, err := loop.ProcessForChannel(context.Background(), channel, "chat-1", "u1", content)
+		if err != nil {
+			t.Fatalf("ProcessForChannel(%q) error: %v", content, err)
+		}
+		return got
+	}
+
+	got := ask("discord", "/tools")
+	if !strings.Contains(got, "- `exec` — ") || !strings.Contains(got, "- `read_file` — ") {
+		t.Fatalf("expected registered tools to be listed, got %q", got)
+	}
+	if !strings.Contains(got, "_(disabled on this channel)_") || !strings.Contains(got, "1 disabled by `channels.discord.tool_policy`") {
+		t.Fatalf("expected exec to be marked disabled on discord, got %q", got)
+	}
+	if strings.Contains(got, "```json") {
+		t.Fatalf("expected no schemas without --verbose, got %q", got)
+	}
+	if got := ask("telegram", "/tools"); strings.Contains(got, "disabled") {
+		t.Fatalf("expected no disabled tools on telegram, got %q", got)
+	}
+
+	got = ask("discord", "/tools read_file")
+	if !strings.Contains(got, "```json") || !strings.Contains(got, `"path"`) {
+		t.Fatalf("expected read_file input schema, got %q", got)
+	}
+	if got := ask("discord", "/tools --verbose exec"); !strings.Contains(got, "Disabled: tool \"exec\" is denied on channel \"discord\"") {
+		t.Fatalf("expected deny reason in verbose output, got %q", got)
+	}
+	if got := ask("discord", "/tools nope"); !strings.Contains(got, "No tool named `nope`") {
+		t.Fatalf("expected unknown tool message, got %q", got)
+	}
+}
+
+func TestChannelToolPolicy_RejectsUnknownToolNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Channels.Discord.ToolPolicy = config.ToolPolicyConfig{Deny: []string{"exec", "rm_rf"}}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), &policyE2EModel{})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	err = loop.RegisterDefaultTools(cfg)
+	if err == nil || !strings.Contains(err.Error(), `channels.discord.tool_policy.deny references unknown tool "rm_rf"`) {
+		t.Fatalf("expected unknown tool validation error, got %v", err)
+	}
+}
+
+func TestE2E_ExecAllowedCommandsBlocksAndAudits(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "relaxed"
+	cfg.Tools.Exec.AllowedCommands = []string{"echo"}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), &policyE2EModel{
+		toolName: "exec",
+		argsJSON: `{"command":"curl http://example.com"}`,
+	})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	resp, err := loop.ProcessDirect(context.Background(), "fetch something")
+	if err != nil {
+		t.Fatalf("ProcessDirect() error: %v", err)
+	}
+	if !strings.Contains(resp, `command "curl" is not allowed`) {
+		t.Fatalf("expected descriptive exec rejection, got: %s", resp)
+	}
+
+	auditPath := filepath.Join(loop.workspacePath, "state", "audit.jsonl")
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if !strings.Contains(string(data), `"exec_command_blocked"`) {
+		t.Fatalf("expected exec_command_blocked audit event, got: %s", data)
+	}
+}
+
+func TestE2E_StrictMode_EditFileApprovalIncludesDiffPreview(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "strict"
+	cfg.Policy.RequireApproval = []string{"edit_file"}
+
+	msgBus := bus.NewMessageBus(1)
+	loop, err := NewLoop(cfg, msgBus, nil)
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	target := filepath.Join(loop.workspacePath, "notes.md")
+	if err := os.WriteFile(target, []byte("title\nold line\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	result, err := loop.tools.Execute(context.Background(), "edit_file", `{"path":"notes.md","old_text":"old line","new_text":"new line"}`)
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if !strings.Contains(result, "approval required") || !strings.Contains(result, "-old line") || !strings.Contains(result, "+new line") {
+		t.Fatalf("expected approval message with diff preview, got: %s", result)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "title\nold line\n" {
+		t.Fatalf("expected file to stay unchanged before approval, got %q", data)
+	}
+
+	reqs, err := approval.NewService(loop.workspacePath).List(approval.Query{Status: approval.StatusPending})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(reqs) != 1 || !strings.Contains(reqs[0].Preview, "+new line") {
+		t.Fatalf("expected pending request to store diff preview, got %+v", reqs)
+	}
+}
+
+func TestE2E_StrictMode_ApprovalNotifiesOriginChatAndAcceptsReply(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "strict"
+	cfg.Policy.RequireApproval = []string{"exec"}
+	cfg.Policy.Admins = []string{"telegram:alice"}
+	cfg.Tools.Exec.RestrictToWorkspace = false
+
+	msgBus := bus.NewMessageBus(1)
+	loop, err := NewLoop(cfg, msgBus, &policyE2EModel{
+		toolName: "exec",
+		argsJSON: `{"command":"echo from-chat"}`,
+	})
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+	var notices []*bus.OutboundMessage
+	loop.SetApprovalNotifier(func(msg *bus.OutboundMessage) { notices = append(notices, msg) })
+
+	if _, err := loop.ProcessForChannel(context.Background(), "telegram", "42", "bob", "run it"); err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if len(notices) != 1 {
+		t.Fatalf("expected one approval notice, got %d", len(notices))
+	}
+	notice := notices[0]
+	if notice.Channel != "telegram" || notice.ChatID != "42" {
+		t.Fatalf("expected notice routed to origin chat, got %s:%s", notice.Channel, notice.ChatID)
+	}
+	for _, want := range []string{"`exec`", "id: 1", "echo from-chat", "approve 1"} {
+		if !strings.Contains(notice.Content, want) {
+			t.Fatalf("expected notice to contain %q, got: %s", want, notice.Content)
+		}
+	}
+	card := notice.Card()
+	if card == nil || len(card.Actions) != 2 || card.Actions[0].Value != "approve 1" || card.Actions[1].Value != "reject 1" {
+		t.Fatalf("expected approval card with approve/reject actions, got %+v", card)
+	}
+
+	// 其他聊天不能代为审批
+	resp, err := loop.ProcessForChannel(context.Background(), "telegram", "99", "mallory", "approve 1")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "another chat") {
+		t.Fatalf("expected approval from another chat to be refused, got: %s", resp)
+	}
+
+	// 同一聊天中的非管理员（包括触发调用的用户）不能审批
+	resp, err = loop.ProcessForChannel(context.Background(), "telegram", "42", "bob", "approve 1")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "requires an admin") {
+		t.Fatalf("expected approval from a non-admin to be refused, got: %s", resp)
+	}
+	if reqs, err := approval.NewService(loop.workspacePath).List(approval.Query{ID: "1"}); err != nil || len(reqs) != 1 || reqs[0].Status != approval.StatusPending {
+		t.Fatalf("expected request to stay pending, got %+v, %v", reqs, err)
+	}
+
+	resp, err = loop.ProcessForChannel(context.Background(), "telegram", "42", "alice", "approve 1 looks fine")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "approved") {
+		t.Fatalf("expected approval confirmation, got: %s", resp)
+	}
+	reqs, err := approval.NewService(loop.workspacePath).List(approval.Query{ID: "1"})
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("List() = %v, %v", reqs, err)
+	}
+	if reqs[0].Status != approval.StatusApproved || reqs[0].DecidedBy != "telegram:alice" || reqs[0].DecisionNote != "looks fine" {
+		t.Fatalf("unexpected decided request: %+v", reqs[0])
+	}
+
+	// 已无待审批请求时，普通的 "approve ..." 文本不会被拦截
+	if _, ok := loop.approvalReplyCommand("approve 1"); ok {
+		t.Fatal("expected reply for a decided request to fall through to the model")
+	}
+}
+
+func TestE2E_PolicyChatCommand_AdminSwitchesModeAtRuntime(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "strict"
+	cfg.Policy.RequireApproval = []string{"exec"}
+	cfg.Policy.Admins = []string{"telegram:alice"}
+	cfg.Tools.Exec.RestrictToWorkspace = false
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), nil)
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+	ctx := context.Background()
+
+	resp, err := loop.ProcessForChannel(ctx, "telegram", "42", "mallory", "/policy off 30m")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "requires an admin") {
+		t.Fatalf("expected non-admin to be refused, got: %s", resp)
+	}
+
+	// 网关请求自行声明的身份即使与管理员相同也不被信任
+	resp, err = loop.ProcessForChannel(bus.WithUnverifiedSender(ctx), "telegram", "42", "alice", "/policy off 30m")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "gateway API requests cannot act as one") {
+		t.Fatalf("expected gateway-supplied admin identity to be refused, got: %s", resp)
+	}
+
+	resp, err = loop.ProcessForChannel(ctx, "telegram", "42", "alice", "/policy off")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "allow_persistent_off") {
+		t.Fatalf("expected persistent off to be refused, got: %s", resp)
+	}
+
+	resp, err = loop.ProcessForChannel(ctx, "telegram", "42", "alice", "/policy off 30m")
+	if err != nil {
+		t.Fatalf("ProcessForChannel() error: %v", err)
+	}
+	if !strings.Contains(resp, "reverts to strict in 30m0s") {
+		t.Fatalf("expected off-mode countdown, got: %s", resp)
+	}
+
+	result, err := loop.tools.Execute(ctx, "exec", `{"command":"echo policy-off"}`)
+	if err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	if strings.Contains(result, "approval required") {
+		t.Fatalf("expected off mode to allow exec, got: %s", result)
+	}
+
+	state, ok, err := policy.ReadState(loop.workspacePath)
+	if err != nil || !ok || state.BaseMode != policy.ModeOff {
+		t.Fatalf("expected off mode in policy state, got %+v ok=%v err=%v", state, ok, err)
+	}
+	saved, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error: %v", err)
+	}
+	if saved.Policy.Mode != "off" || saved.Policy.OffTTL != "30m0s" {
+		t.Fatalf("expected switch to be persisted, got mode=%q off_ttl=%q", saved.Policy.Mode, saved.Policy.OffTTL)
+	}
+
+	found := false
+	for _, evt := range readAuditEvents(t, loop.workspacePath) {
+		if evt.Type == "policy_runtime_switch" && strings.Contains(evt.Result, "mode=off") && strings.Contains(evt.Result, "by=telegram:alice") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected policy_runtime_switch audit event")
+	}
+}
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// rateLimitedReply 是发送者超出入站频率限制时的提示，每次超限只提示一次。
+const rateLimitedReply = "You're sending messages too quickly. Please wait a moment and try again."
+
+// senderRateLimiter 为每个 channel:sender 维护一个令牌桶，限制单个用户驱动 Agent 的频率。
+type senderRateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // 每秒补充的令牌数
+	burst     float64 // 桶容量
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	notified bool // 本轮超限是否已回复过提示
+}
+
+// newSenderRateLimiter 按每分钟请求数创建限流器；perMinute <= 0 时返回 nil 表示不限流。
+// burst <= 0 时桶容量等于 perMinute。
+func newSenderRateLimiter(perMinute, burst int) *senderRateLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &senderRateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow 消耗 key 的一个令牌。超限时 notify 仅在本轮首次超限时为 true，避免刷屏。
+func (r *senderRateLimiter) allow(key string) (ok, notify bool) {
+	if r == nil {
+		return true, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+
+	b, exists := r.buckets[key]
+	if !exists {
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = b
+	}
+	b.tokens = min(r.burst, b.tokens+now.Sub(b.last).Seconds()*r.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.notified = false
+		return true, false
+	}
+	notify = !b.notified
+	b.notified = true
+	return false, notify
+}
+
+// sweep 定期清理已回满的桶：回满的桶与新建的桶等价，删除后内存占用只与近期活跃的发送者数量相关。
+func (r *senderRateLimiter) sweep(now time.Time) {
+	refill := time.Duration(r.burst / r.rate * float64(time.Second))
+	if now.Sub(r.lastSweep) < refill {
+		return
+	}
+	r.lastSweep = now
+	for key, b := range r.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+// ReloadInbound 热更新入站限流、长度上限与去重设置；限流器按新参数重建，已有的去重记录保留。
+func (l *Loop) ReloadInbound(cfg config.ChannelInboundConfig) {
+	l.inboundMu.Lock()
+	l.inboundLimiter = newSenderRateLimiter(cfg.RateLimitPerMinute, cfg.RateLimitBurst)
+	l.inboundMaxChars = cfg.MaxMessageChars
+	l.inboundOversize = cfg.OversizePolicy
+	l.inboundMu.Unlock()
+	if l.inboundDedup != nil {
+		l.inboundDedup.setWindow(time.Duration(cfg.DedupWindowSeconds) * time.Second)
+	}
+}
+
+// allowInbound 对渠道消息执行按发送者的限流；超限时丢弃消息，并在首次超限时回复提示。
+func (l *Loop) allowInbound(msg *bus.InboundMessage) bool {
+	l.inboundMu.RLock()
+	limiter := l.inboundLimiter
+	l.inboundMu.RUnlock()
+
+	ok, notify := limiter.allow(msg.Channel + ":" + msg.SenderID)
+	if ok {
+		return true
+	}
+	slog.Warn("inbound message rate limited",
+		"request_id", msg.RequestID,
+		"channel", msg.Channel,
+		"sender_id", msg.SenderID,
+	)
+	if notify {
+		l.bus.PublishOutbound(replyInThread(&bus.OutboundMessage{
+			Channel:   msg.Channel,
+			ChatID:    msg.ChatID,
+			Content:   rateLimitedReply,
+			RequestID: msg.RequestID,
+		}, msg))
+	}
+	return false
+}
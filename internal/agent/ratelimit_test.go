@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+func TestSenderRateLimiter_RefillsOverTime(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newSenderRateLimiter(6, 2) // 每 10 秒补充一个令牌
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("telegram:u1"); !ok {
+			t.Fatalf("request %d within burst should be allowed", i+1)
+		}
+	}
+	ok, notify := limiter.allow("telegram:u1")
+	if ok || !notify {
+		t.Fatalf("expected first rejection to notify, got ok=%v notify=%v", ok, notify)
+	}
+	if ok, notify := limiter.allow("telegram:u1"); ok || notify {
+		t.Fatalf("expected repeated rejection without notify, got ok=%v notify=%v", ok, notify)
+	}
+	if ok, _ := limiter.allow("telegram:u2"); !ok {
+		t.Fatal("other senders should have their own bucket")
+	}
+
+	now = now.Add(10 * time.Second)
+	if ok, _ := limiter.allow("telegram:u1"); !ok {
+		t.Fatal("expected a token after refill interval")
+	}
+}
+
+func TestSenderRateLimiter_DisabledAndSweep(t *testing.T) {
+	if limiter := newSenderRateLimiter(0, 5); limiter != nil {
+		t.Fatal("expected nil limiter when rate is 0")
+	}
+	var disabled *senderRateLimiter
+	if ok, _ := disabled.allow("any"); !ok {
+		t.Fatal("nil limiter must allow everything")
+	}
+
+	now := time.Unix(0, 0)
+	limiter := newSenderRateLimiter(60, 1)
+	limiter.now = func() time.Time { return now }
+	limiter.allow("a")
+	limiter.allow("b")
+	now = now.Add(time.Minute)
+	limiter.allow("c")
+	if len(limiter.buckets) != 1 {
+		t.Fatalf("expected idle buckets to be swept, got %d", len(limiter.buckets))
+	}
+}
+
+func TestAllowInbound_RepliesOnceWhenLimited(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	loop.bus = bus.NewMessageBus(4)
+	loop.ReloadInbound(config.ChannelInboundConfig{RateLimitPerMinute: 1, RateLimitBurst: 1})
+
+	msg := &bus.InboundMessage{Channel: "telegram", SenderID: "u1", ChatID: "c1", Content: "hi"}
+	if !loop.allowInbound(msg) {
+		t.Fatal("first message should pass")
+	}
+	if loop.allowInbound(msg) || loop.allowInbound(msg) {
+		t.Fatal("later messages should be rate limited")
+	}
+
+	select {
+	case out := <-loop.bus.Outbound():
+		if out.Content != rateLimitedReply || out.Channel != "telegram" || out.ChatID != "c1" {
+			t.Fatalf("unexpected reply: %+v", out)
+		}
+	default:
+		t.Fatal("expected a slow-down reply")
+	}
+	select {
+	case out := <-loop.bus.Outbound():
+		t.Fatalf("expected a single reply, got extra %+v", out)
+	default:
+	}
+
+	loop.ReloadInbound(config.ChannelInboundConfig{})
+	if !loop.allowInbound(msg) {
+		t.Fatal("disabling the limit should allow messages again")
+	}
+}
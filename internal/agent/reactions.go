@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// reactionTimeout 是单次设置表情反应的时限，避免平台接口变慢拖住消息处理。
+const reactionTimeout = 5 * time.Second
+
+// SetReactionNotifier 设置表情反应回调；开启 channels.reactions 时，处理入站消息期间在原消息上标记进度。
+func (l *Loop) SetReactionNotifier(notifier func(ctx context.Context, channel, chatID, messageID, emoji string)) {
+	l.reactionNotifier = notifier
+}
+
+// ReloadReactions 热更新表情反应设置，对之后开始处理的消息生效。
+func (l *Loop) ReloadReactions(cfg config.ChannelReactionsConfig) {
+	l.reactionsMu.Lock()
+	l.reactions = cfg
+	l.reactionsMu.Unlock()
+}
+
+// startReaction 在入站消息上添加“处理中”表情，返回的 finish 按处理结果换成完成或失败表情，
+// 轮次被取消（err 为 context.Canceled）时移除表情。未开启、没有回调或消息缺少平台消息 ID 时为空操作。
+func (l *Loop) startReaction(ctx context.Context, msg *bus.InboundMessage) (finish func(ctx context.Context, err error)) {
+	l.reactionsMu.RLock()
+	cfg := l.reactions
+	l.reactionsMu.RUnlock()
+
+	notify := l.reactionNotifier
+	messageID := msg.PlatformMessageID()
+	if !cfg.Enabled || notify == nil || msg.ChatID == "" || messageID == "" {
+		return func(context.Context, error) {}
+	}
+
+	react := func(ctx context.Context, emoji string) {
+		// 轮次取消后仍需更新表情，因此不继承 ctx 的取消。
+		reactCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), reactionTimeout)
+		defer cancel()
+		notify(reactCtx, msg.Channel, msg.ChatID, messageID, emoji)
+	}
+	react(ctx, cfg.Processing)
+
+	return func(ctx context.Context, err error) {
+		switch {
+		case errors.Is(err, context.Canceled):
+			react(ctx, "")
+		case err != nil:
+			react(ctx, cfg.Failed)
+		default:
+			react(ctx, cfg.Done)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/cloudwego/eino/components/model"
+)
+
+func newReactingLoop(t *testing.T, chatModel model.ChatModel) (*Loop, func() []string) {
+	t.Helper()
+	loop := newTestLoop(t, chatModel, 2)
+	loop.bus = bus.NewMessageBus(4)
+	loop.ReloadReactions(config.ChannelReactionsConfig{Enabled: true, Processing: "👀", Done: "👍", Failed: "👎"})
+
+	var mu sync.Mutex
+	var calls []string
+	loop.SetReactionNotifier(func(ctx context.Context, channel, chatID, messageID, emoji string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, channel+":"+chatID+"/"+messageID+" "+emoji)
+	})
+	return loop, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(calls)
+	}
+}
+
+func TestDispatchMessage_ReactsAroundProcessing(t *testing.T) {
+	loop, calls := newReactingLoop(t, &fixedReplyModel{reply: "ok"})
+	loop.dispatchMessage(context.Background(), &bus.InboundMessage{
+		Channel: "telegram", ChatID: "42", Content: "hi", RequestID: "req-1",
+		Metadata: map[string]any{"message_id": 7},
+	})
+	loop.turns.wg.Wait()
+
+	want := []string{"telegram:42/7 👀", "telegram:42/7 👍"}
+	if got := calls(); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDispatchMessage_ReactsFailedOnError(t *testing.T) {
+	loop, calls := newReactingLoop(t, &flakyModel{errs: []error{errors.New("invalid request")}})
+	loop.dispatchMessage(context.Background(), &bus.InboundMessage{
+		Channel: "slack", ChatID: "C1", Content: "hi", RequestID: "req-1",
+		Metadata: map[string]any{"message_ts": "1700000000.0001"},
+	})
+	loop.turns.wg.Wait()
+
+	want := []string{"slack:C1/1700000000.0001 👀", "slack:C1/1700000000.0001 👎"}
+	if got := calls(); !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDispatchMessage_NoReactionWithoutMessageIDOrWhenDisabled(t *testing.T) {
+	loop, calls := newReactingLoop(t, &fixedReplyModel{reply: "ok"})
+	loop.dispatchMessage(context.Background(), &bus.InboundMessage{Channel: "telegram", ChatID: "42", Content: "hi"})
+	loop.turns.wg.Wait()
+
+	loop.ReloadReactions(config.ChannelReactionsConfig{})
+	loop.dispatchMessage(context.Background(), &bus.InboundMessage{
+		Channel: "telegram", ChatID: "42", Content: "hi", Metadata: map[string]any{"message_id": 8},
+	})
+	loop.turns.wg.Wait()
+
+	if got := calls(); len(got) != 0 {
+		t.Fatalf("expected no reactions, got %v", got)
+	}
+}
@@ -4,14 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/MEKXH/golem/internal/approval"
 	"github.com/MEKXH/golem/internal/audit"
+	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/logrotate"
 	"github.com/MEKXH/golem/internal/policy"
 	"github.com/MEKXH/golem/internal/tools"
 )
@@ -21,8 +25,18 @@ type runtimeGuard struct {
 	baseMode        policy.Mode       // 基础运行模式（strict, relaxed, off）
 	requireApproval []string          // 需要审批的工具列表
 	offUntil        time.Time         // 策略关闭的截止时间（用于 TTL 自动恢复）
+	allowPersistent bool              // 是否允许无 TTL 的 off 模式
+	admins          []string          // policy.admins，可在聊天中切换策略、审批工具调用的发送者
 	approvalService *approval.Service // 审批服务
 	auditWriter     *audit.Writer     // 审计日志写入器
+
+	channelPolicies map[string]channelToolPolicy // 按通道名索引的工具访问控制
+}
+
+// channelToolPolicy 是规范化（小写）后的通道级工具白名单/黑名单。
+type channelToolPolicy struct {
+	allow map[string]struct{}
+	deny  map[string]struct{}
 }
 
 func (l *Loop) configureRuntimeGuard(cfg *config.Config) error {
@@ -33,9 +47,17 @@ func (l *Loop) configureRuntimeGuard(cfg *config.Config) error {
 	guard := &runtimeGuard{
 		baseMode:        policy.Mode(strings.TrimSpace(cfg.Policy.Mode)),
 		requireApproval: append([]string(nil), cfg.Policy.RequireApproval...),
+		allowPersistent: cfg.Policy.AllowPersistentOff,
+		admins:          append([]string(nil), cfg.Policy.Admins...),
 		approvalService: approval.NewService(l.workspacePath),
-		auditWriter:     audit.NewWriter(l.workspacePath),
+		auditWriter:     audit.NewWriterWithOptions(l.workspacePath, logrotate.OptionsFromConfig(cfg.Log.Audit)),
+	}
+
+	channelPolicies, err := l.buildChannelToolPolicies(cfg.Channels.ToolPolicies())
+	if err != nil {
+		return err
 	}
+	guard.channelPolicies = channelPolicies
 
 	if ttlRaw := strings.TrimSpace(cfg.Policy.OffTTL); ttlRaw != "" {
 		ttl, err := time.ParseDuration(ttlRaw)
@@ -45,11 +67,107 @@ func (l *Loop) configureRuntimeGuard(cfg *config.Config) error {
 		guard.offUntil = l.nowUTC().Add(ttl)
 	}
 
+	l.guardMu.Lock()
 	l.runtimeGuard = guard
+	l.guardMu.Unlock()
 	l.tools.SetGuard(l.evaluateToolGuard)
+	l.writePolicyState(guard)
 	return nil
 }
 
+// writePolicyState 将当前生效的策略写入工作区快照，供 golem policy status 查询 off 模式剩余时间。
+func (l *Loop) writePolicyState(guard *runtimeGuard) {
+	if strings.TrimSpace(l.workspacePath) == "" || guard == nil {
+		return
+	}
+	if err := policy.WriteState(l.workspacePath, guard.state(l.nowUTC())); err != nil {
+		slog.Warn("failed to write policy state", "error", err)
+	}
+}
+
+// ReloadPolicy 使用新配置中的 policy 段替换运行时安全策略；解析失败时保留原有策略。
+func (l *Loop) ReloadPolicy(cfg *config.Config) error {
+	if err := l.configureRuntimeGuard(cfg); err != nil {
+		return err
+	}
+	slog.Info("runtime policy reloaded", "mode", strings.TrimSpace(cfg.Policy.Mode))
+	return nil
+}
+
+// SetPolicyMode 在运行时切换策略模式：ttl > 0 时 off 模式到期后回落为 strict。
+// 未开启 policy.allow_persistent_off 时拒绝无 TTL 的 off 模式。切换会写入审计日志与策略快照，
+// 但不修改配置文件；配置重载会以配置中的模式覆盖运行时切换。
+func (l *Loop) SetPolicyMode(ctx context.Context, mode policy.Mode, ttl time.Duration, actor string) (policy.State, error) {
+	mode = policy.Mode(strings.ToLower(strings.TrimSpace(string(mode))))
+	switch mode {
+	case policy.ModeStrict, policy.ModeRelaxed, policy.ModeOff:
+	default:
+		return policy.State{}, fmt.Errorf("policy mode must be one of strict, relaxed, off; got %q", mode)
+	}
+	if ttl < 0 {
+		return policy.State{}, fmt.Errorf("policy ttl must not be negative")
+	}
+	if ttl > 0 && mode != policy.ModeOff {
+		return policy.State{}, fmt.Errorf("ttl only applies to off mode")
+	}
+
+	l.guardMu.Lock()
+	current := l.runtimeGuard
+	if current == nil {
+		l.guardMu.Unlock()
+		return policy.State{}, fmt.Errorf("runtime policy is not configured")
+	}
+	if mode == policy.ModeOff && ttl == 0 && !current.allowPersistent {
+		l.guardMu.Unlock()
+		return policy.State{}, fmt.Errorf("off mode without a ttl requires policy.allow_persistent_off=true")
+	}
+	next := *current
+	next.baseMode = mode
+	next.offUntil = time.Time{}
+	if ttl > 0 {
+		next.offUntil = l.nowUTC().Add(ttl)
+	}
+	l.runtimeGuard = &next
+	l.guardMu.Unlock()
+
+	l.writePolicyState(&next)
+	ttlResult := "none"
+	if ttl > 0 {
+		ttlResult = ttl.String()
+	}
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		actor = "-"
+	}
+	l.appendAuditEvent(ctx, "policy_runtime_switch", "", "", fmt.Sprintf("mode=%s off_ttl=%s by=%s", mode, ttlResult, actor))
+	slog.Info("runtime policy mode changed", "mode", mode, "off_ttl", ttlResult, "by", actor)
+	return next.state(l.nowUTC()), nil
+}
+
+// PolicyState 返回当前运行时策略的快照；策略尚未配置时第二个返回值为 false。
+func (l *Loop) PolicyState() (policy.State, bool) {
+	guard := l.guard()
+	if guard == nil {
+		return policy.State{}, false
+	}
+	return guard.state(l.nowUTC()), true
+}
+
+// IsPolicyAdmin 报告 channel 上的 sender 是否在当前生效的 policy.admins 中；配置重载后立即生效。
+func (l *Loop) IsPolicyAdmin(channel, sender string) bool {
+	guard := l.guard()
+	if guard == nil {
+		return false
+	}
+	return config.PolicyConfig{Admins: guard.admins}.IsAdmin(channel, sender)
+}
+
+func (l *Loop) guard() *runtimeGuard {
+	l.guardMu.RLock()
+	defer l.guardMu.RUnlock()
+	return l.runtimeGuard
+}
+
 // AuditRuntimePolicyStartup 记录 Agent 启动时的策略审计事件。
 func (l *Loop) AuditRuntimePolicyStartup(ctx context.Context, cfg *config.Config) {
 	if cfg == nil {
@@ -88,11 +206,19 @@ func (l *Loop) AuditRuntimePolicyStartup(ctx context.Context, cfg *config.Config
 
 // evaluateToolGuard 是工具执行前的守卫函数，负责根据策略进行拦截、放行或发起审批。
 func (l *Loop) evaluateToolGuard(ctx context.Context, name, argsJSON string) (tools.GuardResult, error) {
-	guard := l.runtimeGuard
+	guard := l.guard()
 	if guard == nil {
 		return tools.GuardResult{Action: tools.GuardAllow}, nil
 	}
 
+	// 通道级工具策略先于全局策略生效
+	if channel := tools.InvocationFromContext(ctx).Channel; channel != "" {
+		if msg, denied := guard.channelDenial(channel, name); denied {
+			l.appendAuditEvent(ctx, "policy_deny", "", name, msg)
+			return tools.GuardResult{Action: tools.GuardDeny, Message: msg}, nil
+		}
+	}
+
 	now := l.nowUTC()
 	mode, ttlExpired := guard.effectiveMode(now)
 	evaluator := policy.NewEvaluator(policy.Config{
@@ -140,16 +266,25 @@ func (l *Loop) evaluateToolGuard(ctx context.Context, name, argsJSON string) (to
 		}
 
 		// 创建新的审批请求
+		preview := l.approvalPreview(name, argsJSON)
+		inv := tools.InvocationFromContext(ctx)
 		req, err := guard.approvalService.Create(approval.CreateInput{
 			ToolName: strings.TrimSpace(name),
 			ArgsJSON: normalizedArgs,
 			Reason:   reason,
+			Preview:  preview,
+			Channel:  inv.Channel,
+			ChatID:   inv.ChatID,
 		})
 		if err != nil {
 			return tools.GuardResult{}, err
 		}
+		l.notifyApproval(inv, req)
 
 		msg := fmt.Sprintf("approval required: id=%s (run: golem approval approve %s --by <name>)", req.ID, req.ID)
+		if preview != "" {
+			msg += "\n" + preview
+		}
 		l.appendAuditEvent(ctx, "approval_pending", req.ID, name, reason)
 		return tools.GuardResult{Action: tools.GuardRequireApproval, Message: msg}, nil
 	default:
@@ -159,23 +294,109 @@ func (l *Loop) evaluateToolGuard(ctx context.Context, name, argsJSON string) (to
 	}
 }
 
-func (l *Loop) auditToolExecution(ctx context.Context, toolName, result string, err error) {
-	if l.runtimeGuard == nil || l.runtimeGuard.auditWriter == nil {
+// notifyApproval 将新建的审批请求推送到发起调用的聊天，便于只能通过聊天操作的用户直接回复决策。
+func (l *Loop) notifyApproval(inv tools.InvocationContext, req approval.Request) {
+	if l.approvalNotifier == nil || inv.Channel == "" || inv.ChatID == "" {
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Approval required for `%s` (id: %s)\n", req.ToolName, req.ID)
+	fmt.Fprintf(&sb, "Args: %s\n", summarizeApprovalArgs(req.ArgsJSON))
+	if req.Preview != "" {
+		fmt.Fprintf(&sb, "\n%s\n\n", req.Preview)
+	}
+	fmt.Fprintf(&sb, "An admin (`policy.admins`) can reply `approve %s` or `reject %s` to decide.", req.ID, req.ID)
+
+	// 支持卡片的通道改为展示带批准/拒绝按钮的卡片，按钮回传与文本回复相同的命令。
+	var body strings.Builder
+	fmt.Fprintf(&body, "**Tool:** `%s`\n**Args:** `%s`", req.ToolName, summarizeApprovalArgs(req.ArgsJSON))
+	if req.Preview != "" {
+		fmt.Fprintf(&body, "\n\n%s", req.Preview)
+	}
+	fmt.Fprintf(&body, "\n\nOr reply `approve %s` / `reject %s`.", req.ID, req.ID)
+	card := &bus.Card{
+		Title: fmt.Sprintf("Approval required (id: %s)", req.ID),
+		Body:  body.String(),
+		Actions: []bus.CardAction{
+			{Label: "Approve", Value: "approve " + req.ID, Style: "primary"},
+			{Label: "Reject", Value: "reject " + req.ID, Style: "danger"},
+		},
+	}
+	metadata := map[string]any{bus.MetadataCard: card}
+	if inv.ThreadID != "" {
+		metadata[bus.MetadataThreadID] = inv.ThreadID
+	}
+	l.approvalNotifier(&bus.OutboundMessage{
+		Channel:   inv.Channel,
+		ChatID:    inv.ChatID,
+		Content:   sb.String(),
+		RequestID: inv.RequestID,
+		Metadata:  metadata,
+	})
+}
+
+// summarizeApprovalArgs 截断过长的参数 JSON，用于审批通知。
+func summarizeApprovalArgs(argsJSON string) string {
+	const maxArgsChars = 300
+	args := strings.TrimSpace(argsJSON)
+	if args == "" {
+		return "{}"
+	}
+	if r := []rune(args); len(r) > maxArgsChars {
+		return string(r[:maxArgsChars]) + "..."
+	}
+	return args
+}
+
+// approvalReplyRe 匹配聊天中的 "approve <id> [note]" / "reject <id> [note]" 回复。
+var approvalReplyRe = regexp.MustCompile(`(?is)^\s*(approve|reject)\s+(\S+)(\s+.*)?$`)
+
+// approvalReplyCommand 将对待审批请求的纯文本回复转换为 /approve 或 /reject 命令。
+// 仅当 id 对应一条待审批请求时才转换，避免拦截普通对话。
+func (l *Loop) approvalReplyCommand(content string) (string, bool) {
+	m := approvalReplyRe.FindStringSubmatch(content)
+	if m == nil {
+		return "", false
+	}
+	guard := l.guard()
+	if guard == nil || guard.approvalService == nil {
+		return "", false
+	}
+	pending, err := guard.approvalService.List(approval.Query{ID: m[2], Status: approval.StatusPending})
+	if err != nil || len(pending) == 0 {
+		return "", false
+	}
+	return "/" + strings.ToLower(m[1]) + " " + m[2] + m[3], true
+}
+
+// approvalPreview 为待审批的工具调用生成改动预览，帮助审批人了解将要发生什么。
+// 目前仅支持 edit_file；无法预览时返回空字符串。
+func (l *Loop) approvalPreview(name, argsJSON string) string {
+	if strings.TrimSpace(name) != "edit_file" {
+		return ""
+	}
+	preview, err := tools.PreviewEditFile(l.workspacePath, argsJSON)
+	if err != nil {
+		return ""
+	}
+	return preview
+}
+
+func (l *Loop) auditToolExecution(ctx context.Context, toolName string, outcome tools.Outcome, err error) {
+	if guard := l.guard(); guard == nil || guard.auditWriter == nil {
 		return
 	}
 
-	status := "success"
-	normalizedResult := strings.ToLower(strings.TrimSpace(result))
-	if strings.HasPrefix(normalizedResult, "pending approval") {
-		status = "pending_approval"
-	} else if err != nil || strings.HasPrefix(result, "Error:") {
-		status = "error"
+	var notAllowed *tools.CommandNotAllowedError
+	if errors.As(err, &notAllowed) {
+		l.appendAuditEvent(ctx, "exec_command_blocked", "", toolName, notAllowed.Error())
 	}
-	l.appendAuditEvent(ctx, "tool_execution", "", toolName, status)
+	l.appendAuditEvent(ctx, "tool_execution", "", toolName, string(outcome))
 }
 
 func (l *Loop) appendAuditEvent(ctx context.Context, eventType, requestID, toolName, result string) {
-	if l.runtimeGuard == nil || l.runtimeGuard.auditWriter == nil {
+	guard := l.guard()
+	if guard == nil || guard.auditWriter == nil {
 		return
 	}
 
@@ -192,7 +413,7 @@ func (l *Loop) appendAuditEvent(ctx context.Context, eventType, requestID, toolN
 		Result:    strings.TrimSpace(result),
 	}
 
-	if err := l.runtimeGuard.auditWriter.Append(event); err != nil {
+	if err := guard.auditWriter.Append(event); err != nil {
 		slog.Warn("failed to append audit event", "type", event.Type, "tool", event.Tool, "error", err)
 	}
 }
@@ -204,12 +425,76 @@ func (l *Loop) nowUTC() time.Time {
 	return time.Now().UTC()
 }
 
-func (g *runtimeGuard) effectiveMode(now time.Time) (policy.Mode, bool) {
-	mode := g.baseMode
-	if mode == policy.ModeOff && !g.offUntil.IsZero() && !now.Before(g.offUntil) {
-		return policy.ModeStrict, true
+// buildChannelToolPolicies 规范化通道工具策略，并校验其中引用的工具均已注册。
+// MCP 工具（mcp.*）可能因服务器暂时不可用而尚未注册，因此不做存在性校验。
+func (l *Loop) buildChannelToolPolicies(raw map[string]config.ToolPolicyConfig) (map[string]channelToolPolicy, error) {
+	policies := make(map[string]channelToolPolicy, len(raw))
+	for channel, cfg := range raw {
+		p := channelToolPolicy{}
+		var err error
+		if p.allow, err = l.toolNameSet(channel, "allow", cfg.Allow); err != nil {
+			return nil, err
+		}
+		if p.deny, err = l.toolNameSet(channel, "deny", cfg.Deny); err != nil {
+			return nil, err
+		}
+		policies[channel] = p
+	}
+	return policies, nil
+}
+
+func (l *Loop) toolNameSet(channel, list string, names []string) (map[string]struct{}, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	known := make(map[string]struct{})
+	for _, name := range l.tools.Names() {
+		known[strings.ToLower(name)] = struct{}{}
 	}
-	return mode, false
+
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		normalized := strings.ToLower(strings.TrimSpace(name))
+		if normalized == "" {
+			continue
+		}
+		if _, ok := known[normalized]; !ok && !strings.HasPrefix(normalized, "mcp.") {
+			return nil, fmt.Errorf("channels.%s.tool_policy.%s references unknown tool %q", channel, list, name)
+		}
+		set[normalized] = struct{}{}
+	}
+	return set, nil
+}
+
+// channelDenial 检查通道级策略是否禁止该工具；deny 优先于 allow，allow 非空时视为白名单。
+func (g *runtimeGuard) channelDenial(channel, toolName string) (string, bool) {
+	p, ok := g.channelPolicies[strings.ToLower(channel)]
+	if !ok {
+		return "", false
+	}
+	normalized := strings.ToLower(strings.TrimSpace(toolName))
+	if _, denied := p.deny[normalized]; denied {
+		return fmt.Sprintf("tool %q is denied on channel %q by channels.%s.tool_policy", toolName, channel, channel), true
+	}
+	if len(p.allow) > 0 {
+		if _, allowed := p.allow[normalized]; !allowed {
+			return fmt.Sprintf("tool %q is not in the allow list for channel %q (channels.%s.tool_policy)", toolName, channel, channel), true
+		}
+	}
+	return "", false
+}
+
+func (g *runtimeGuard) state(now time.Time) policy.State {
+	return policy.State{
+		BaseMode:        g.baseMode,
+		OffUntil:        g.offUntil,
+		RequireApproval: append([]string{}, g.requireApproval...),
+		UpdatedAt:       now,
+	}
+}
+
+func (g *runtimeGuard) effectiveMode(now time.Time) (policy.Mode, bool) {
+	return policy.EffectiveMode(g.baseMode, g.offUntil, now)
 }
 
 func (g *runtimeGuard) findMatchingRequests(toolName, argsJSON string) (*approval.Request, *approval.Request, error) {
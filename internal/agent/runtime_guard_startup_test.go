@@ -8,10 +8,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MEKXH/golem/internal/audit"
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/policy"
 )
 
 func TestAuditRuntimePolicyStartup_PersistentOffWritesWarningEvent(t *testing.T) {
@@ -108,3 +110,38 @@ func readAuditEvents(t *testing.T, workspacePath string) []audit.Event {
 	}
 	return events
 }
+
+func TestConfigureRuntimeGuard_WritesPolicyState(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Mode = "off"
+	cfg.Policy.OffTTL = "30m"
+	cfg.Policy.RequireApproval = []string{"exec"}
+
+	loop, err := NewLoop(cfg, bus.NewMessageBus(1), nil)
+	if err != nil {
+		t.Fatalf("NewLoop() error: %v", err)
+	}
+	now := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	loop.now = func() time.Time { return now }
+	if err := loop.RegisterDefaultTools(cfg); err != nil {
+		t.Fatalf("RegisterDefaultTools() error: %v", err)
+	}
+
+	state, ok, err := policy.ReadState(loop.workspacePath)
+	if err != nil || !ok {
+		t.Fatalf("ReadState() = ok %v, err %v", ok, err)
+	}
+	if state.BaseMode != policy.ModeOff {
+		t.Fatalf("expected base mode off, got %q", state.BaseMode)
+	}
+	if !state.OffUntil.Equal(now.Add(30 * time.Minute)) {
+		t.Fatalf("expected off_until now+30m, got %v", state.OffUntil)
+	}
+	if len(state.RequireApproval) != 1 || state.RequireApproval[0] != "exec" {
+		t.Fatalf("unexpected require_approval: %v", state.RequireApproval)
+	}
+}
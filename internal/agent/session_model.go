@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/provider"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ModelFactory 按模型名创建聊天模型，用于 /model 在会话内切换模型；模型名无效或供应商未配置时返回错误。
+type ModelFactory func(ctx context.Context, modelName string) (model.ChatModel, error)
+
+// sessionModel 是会话内切换后使用的模型。
+type sessionModel struct {
+	name  string
+	model model.ChatModel
+}
+
+// SetModelFactory 设置 /model 切换模型时使用的工厂函数。
+func (l *Loop) SetModelFactory(factory ModelFactory) {
+	l.modelFactory = factory
+}
+
+// SessionModel 返回会话当前使用的模型名；overridden 为 true 表示该会话通过 /model 切换过模型。
+func (l *Loop) SessionModel(sessionKey, channel string) (name string, overridden bool) {
+	l.sessionModelsMu.RLock()
+	selected, ok := l.sessionModels[sessionKey]
+	l.sessionModelsMu.RUnlock()
+	if ok {
+		return selected.name, true
+	}
+	if l.config == nil {
+		return "", false
+	}
+	if name := strings.TrimSpace(l.config.Agents.Defaults.ChannelModels[channel]); name != "" {
+		return name, false
+	}
+	return l.config.Agents.Defaults.Model, false
+}
+
+// sessionSupportsVision 报告会话当前使用的模型是否接受图片输入。
+func (l *Loop) sessionSupportsVision(sessionKey, channel string) bool {
+	name, _ := l.SessionModel(sessionKey, channel)
+	var extra []string
+	if l.config != nil {
+		extra = l.config.Agents.Defaults.VisionModels
+	}
+	return provider.SupportsVision(name, extra)
+}
+
+// SetSessionModel 为会话切换聊天模型，只影响该会话的后续消息，重启后恢复默认。modelName 为空时清除切换。
+// 新模型会绑定当前注册的全部工具。
+func (l *Loop) SetSessionModel(ctx context.Context, sessionKey, modelName string) error {
+	modelName = strings.TrimSpace(modelName)
+	if modelName == "" {
+		l.sessionModelsMu.Lock()
+		delete(l.sessionModels, sessionKey)
+		l.sessionModelsMu.Unlock()
+		return nil
+	}
+	if l.modelFactory == nil {
+		return fmt.Errorf("model switching is not available")
+	}
+
+	chatModel, err := l.modelFactory(ctx, modelName)
+	if err != nil {
+		return err
+	}
+	if binder, ok := chatModel.(interface {
+		BindTools([]*schema.ToolInfo) error
+	}); ok {
+		toolInfos, err := l.tools.GetToolInfos(ctx)
+		if err != nil {
+			return err
+		}
+		if err := binder.BindTools(toolInfos); err != nil {
+			return fmt.Errorf("bind tools to %s: %w", modelName, err)
+		}
+	}
+
+	l.sessionModelsMu.Lock()
+	defer l.sessionModelsMu.Unlock()
+	if l.sessionModels == nil {
+		l.sessionModels = make(map[string]sessionModel)
+	}
+	l.sessionModels[sessionKey] = sessionModel{name: modelName, model: chatModel}
+	return nil
+}
@@ -19,8 +19,10 @@ type SubagentTaskRequest struct {
 	Label          string // 任务标签
 	OriginChannel  string // 原始请求通道
 	OriginChatID   string // 原始请求聊天 ID
+	OriginThreadID string // 原始请求所在线程，结果回复到该线程
 	OriginSenderID string // 原始发送者 ID
 	RequestID      string // 请求追踪 ID
+	Depth          int    // 子代理嵌套深度，主代理直接委派时为 1
 }
 
 // SubagentManagerOptions 配置委派任务的超时、重试和并发限制。
@@ -28,6 +30,7 @@ type SubagentManagerOptions struct {
 	Timeout        time.Duration // 任务执行超时
 	Retry          int           // 失败重试次数
 	MaxConcurrency int           // 最大并发子任务数
+	MaxDepth       int           // 子代理最大嵌套深度，<= 0 时使用默认值 2
 }
 
 // subagentProcessor 是子代理使用的最小处理契约接口。
@@ -41,6 +44,7 @@ type SubagentManager struct {
 	processor subagentProcessor // 执行任务的处理引擎
 	timeout   time.Duration     // 默认超时时间
 	retry     int               // 默认重试次数
+	maxDepth  int               // 子代理最大嵌套深度
 	nextID    uint64            // 用于生成唯一的任务 ID
 	semaphore chan struct{}     // 信号量，用于并发控制
 	mu        sync.RWMutex
@@ -52,6 +56,7 @@ func NewSubagentManager(msgBus *bus.MessageBus, processor subagentProcessor, tim
 		Timeout:        timeout,
 		Retry:          1,
 		MaxConcurrency: 3,
+		MaxDepth:       2,
 	})
 }
 
@@ -69,11 +74,16 @@ func NewSubagentManagerWithOptions(msgBus *bus.MessageBus, processor subagentPro
 	if maxConcurrency <= 0 {
 		maxConcurrency = 3
 	}
+	maxDepth := options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = 2
+	}
 	return &SubagentManager{
 		msgBus:    msgBus,
 		processor: processor,
 		timeout:   timeout,
 		retry:     retry,
+		maxDepth:  maxDepth,
 		semaphore: make(chan struct{}, maxConcurrency),
 	}
 }
@@ -101,7 +111,8 @@ func (m *SubagentManager) RunSync(ctx context.Context, req tools.SubagentRequest
 	return m.executeWithRetry(runCtx, taskID, normalized)
 }
 
-// RunWorkflow 以顺序或并行模式执行子代理工作流，并返回汇总摘要。
+// RunWorkflow 以顺序、并行或依赖图 (dag) 模式执行子代理工作流，并返回汇总摘要。
+// dag 模式下每个步骤在其依赖全部成功后启动，依赖失败的步骤会被跳过并计为失败。
 func (m *SubagentManager) RunWorkflow(ctx context.Context, req tools.WorkflowRequest) (string, error) {
 	normalized, err := m.normalizeWorkflow(req)
 	if err != nil {
@@ -112,41 +123,93 @@ func (m *SubagentManager) RunWorkflow(ctx context.Context, req tools.WorkflowReq
 	defer cancel()
 
 	type stepResult struct {
+		id     string // 仅 dag 模式
 		task   string
 		output string
 		err    error
 	}
 
 	results := make([]stepResult, len(normalized.Subtasks))
+	if normalized.Mode == "dag" {
+		results = make([]stepResult, len(normalized.Steps))
+	}
 	workflowID := m.nextTaskID()
 
-	runStep := func(index int, task string) {
+	// runStep 以 prompt 执行步骤，摘要中仍展示原始 task。
+	runStep := func(index int, task, prompt string) {
 		stepReq := SubagentTaskRequest{
-			Task:           task,
+			Task:           prompt,
 			Label:          normalized.Label,
 			OriginChannel:  normalized.OriginChannel,
 			OriginChatID:   normalized.OriginChatID,
 			OriginSenderID: normalized.OriginSenderID,
 			RequestID:      normalized.RequestID,
+			Depth:          normalized.Depth,
 		}
 		stepTaskID := fmt.Sprintf("%s-step-%d", workflowID, index+1)
 		output, stepErr := m.executeWithRetry(runCtx, stepTaskID, stepReq)
-		results[index] = stepResult{task: task, output: output, err: stepErr}
+		results[index].task, results[index].output, results[index].err = task, output, stepErr
 	}
 
-	if normalized.Mode == "parallel" {
+	switch normalized.Mode {
+	case "dag":
+		indexByID := make(map[string]int, len(normalized.Steps))
+		done := make([]chan struct{}, len(normalized.Steps))
+		for i, step := range normalized.Steps {
+			indexByID[step.ID] = i
+			done[i] = make(chan struct{})
+			results[i].id = step.ID
+		}
+		var wg sync.WaitGroup
+		for i, step := range normalized.Steps {
+			wg.Add(1)
+			go func(idx int, step tools.WorkflowStep) {
+				defer wg.Done()
+				defer close(done[idx])
+				direct := make([]priorOutput, 0, len(step.DependsOn))
+				for _, dep := range step.DependsOn {
+					depIdx := indexByID[dep]
+					<-done[depIdx]
+					if results[depIdx].err != nil {
+						results[idx].task = step.Task
+						results[idx].err = fmt.Errorf("skipped: dependency %q did not succeed", dep)
+						return
+					}
+					direct = append(direct, priorOutput{id: dep, output: results[depIdx].output})
+				}
+				prompt := step.Task
+				if normalized.PassOutputs {
+					// 依赖成功意味着其全部祖先步骤均已完成，占位符可引用任一祖先。
+					var available []priorOutput
+					for _, ref := range stepOutputRefs(step.Task) {
+						r := results[indexByID[ref]]
+						available = append(available, priorOutput{id: ref, output: r.output, err: r.err})
+					}
+					prompt = injectStepOutputs(step.Task, available, direct)
+				}
+				runStep(idx, step.Task, prompt)
+			}(i, step)
+		}
+		wg.Wait()
+	case "parallel":
 		var wg sync.WaitGroup
 		for i, task := range normalized.Subtasks {
 			wg.Add(1)
 			go func(idx int, subtask string) {
 				defer wg.Done()
-				runStep(idx, subtask)
+				runStep(idx, subtask, subtask)
 			}(i, task)
 		}
 		wg.Wait()
-	} else {
+	default:
+		var prior []priorOutput
 		for i, task := range normalized.Subtasks {
-			runStep(i, task)
+			prompt := task
+			if normalized.PassOutputs {
+				prompt = injectStepOutputs(task, prior, prior)
+			}
+			runStep(i, task, prompt)
+			prior = append(prior, priorOutput{id: fmt.Sprintf("%d", i+1), output: results[i].output, err: results[i].err})
 		}
 	}
 
@@ -155,14 +218,21 @@ func (m *SubagentManager) RunWorkflow(ctx context.Context, req tools.WorkflowReq
 	var b strings.Builder
 	fmt.Fprintf(&b, "Workflow goal: %s\n", normalized.Goal)
 	fmt.Fprintf(&b, "Mode: %s total=%d succeeded=%d failed=%d\n", normalized.Mode, len(results), 0, 0)
+	if normalized.Mode == "dag" {
+		fmt.Fprintf(&b, "Execution order: %s\n", formatWorkflowStages(normalized.Steps))
+	}
 	for i, result := range results {
+		label := fmt.Sprintf("%d", i+1)
+		if result.id != "" {
+			label = result.id
+		}
 		if result.err != nil {
 			failed++
-			fmt.Fprintf(&b, "[%d] FAIL %s -> %v\n", i+1, result.task, result.err)
+			fmt.Fprintf(&b, "[%s] FAIL %s -> %v\n", label, result.task, result.err)
 			continue
 		}
 		succeeded++
-		fmt.Fprintf(&b, "[%d] OK %s\n%s\n", i+1, result.task, strings.TrimSpace(result.output))
+		fmt.Fprintf(&b, "[%s] OK %s\n%s\n", label, result.task, strings.TrimSpace(result.output))
 	}
 	summary := strings.TrimSpace(b.String())
 	summary = strings.Replace(summary, "succeeded=0 failed=0", fmt.Sprintf("succeeded=%d failed=%d", succeeded, failed), 1)
@@ -186,6 +256,10 @@ func (m *SubagentManager) normalize(req tools.SubagentRequest) (SubagentTaskRequ
 	if task == "" {
 		return SubagentTaskRequest{}, fmt.Errorf("task is required")
 	}
+	depth, err := m.checkDepth(req.Depth)
+	if err != nil {
+		return SubagentTaskRequest{}, err
+	}
 
 	channel := strings.TrimSpace(req.OriginChannel)
 	if channel == "" {
@@ -205,11 +279,25 @@ func (m *SubagentManager) normalize(req tools.SubagentRequest) (SubagentTaskRequ
 		Label:          strings.TrimSpace(req.Label),
 		OriginChannel:  channel,
 		OriginChatID:   chatID,
+		OriginThreadID: strings.TrimSpace(req.OriginThreadID),
 		OriginSenderID: sender,
 		RequestID:      strings.TrimSpace(req.RequestID),
+		Depth:          depth,
 	}, nil
 }
 
+// checkDepth 在占用并发槽位之前拒绝超出 maxDepth 的嵌套委派，
+// 否则持有槽位的上层子代理会与等待槽位的下层子代理互相阻塞。未设置深度时视为 1。
+func (m *SubagentManager) checkDepth(depth int) (int, error) {
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > m.maxDepth {
+		return 0, fmt.Errorf("subagent depth limit reached: depth %d exceeds agents.subagent.max_depth=%d; complete this task directly instead of delegating", depth, m.maxDepth)
+	}
+	return depth, nil
+}
+
 func (m *SubagentManager) run(taskID string, req SubagentTaskRequest) {
 	baseCtx := context.Background()
 	if req.RequestID != "" {
@@ -223,7 +311,7 @@ func (m *SubagentManager) run(taskID string, req SubagentTaskRequest) {
 		return
 	}
 
-	m.msgBus.PublishInbound(bus.NewSubagentResultInbound(
+	inbound := bus.NewSubagentResultInbound(
 		taskID,
 		req.Label,
 		req.OriginChannel,
@@ -232,7 +320,11 @@ func (m *SubagentManager) run(taskID string, req SubagentTaskRequest) {
 		result,
 		req.RequestID,
 		err,
-	))
+	)
+	if req.OriginThreadID != "" {
+		inbound.Metadata[bus.SystemMetaOriginThread] = req.OriginThreadID
+	}
+	m.msgBus.PublishInbound(inbound)
 }
 
 func (m *SubagentManager) executeOnce(ctx context.Context, taskID string, req SubagentTaskRequest) (string, error) {
@@ -246,7 +338,7 @@ func (m *SubagentManager) executeOnce(ctx context.Context, taskID string, req Su
 	sessionID := "subagent:" + taskID
 	senderID := "subagent:" + taskID
 	return processor.ProcessForChannelWithSession(
-		ctx,
+		tools.WithSubagentDepth(ctx, req.Depth),
 		req.OriginChannel,
 		req.OriginChatID,
 		senderID,
@@ -331,35 +423,64 @@ func (m *SubagentManager) normalizeWorkflow(req tools.WorkflowRequest) (tools.Wo
 	if goal == "" {
 		return tools.WorkflowRequest{}, fmt.Errorf("goal is required")
 	}
+	depth, err := m.checkDepth(req.Depth)
+	if err != nil {
+		return tools.WorkflowRequest{}, err
+	}
 
 	mode := strings.ToLower(strings.TrimSpace(req.Mode))
 	switch mode {
-	case "", "sequential", "parallel":
+	case "", "sequential", "parallel", "dag":
 	default:
-		return tools.WorkflowRequest{}, fmt.Errorf("workflow mode must be one of sequential, parallel; got %q", req.Mode)
+		return tools.WorkflowRequest{}, fmt.Errorf("workflow mode must be one of sequential, parallel, dag; got %q", req.Mode)
 	}
 
-	subtasks := make([]string, 0, len(req.Subtasks))
-	for _, subtask := range req.Subtasks {
-		task := strings.TrimSpace(subtask)
-		if task != "" {
-			subtasks = append(subtasks, task)
+	var steps []tools.WorkflowStep
+	if len(req.Steps) > 0 {
+		if mode != "" && mode != "dag" {
+			return tools.WorkflowRequest{}, fmt.Errorf("workflow steps with depends_on run in dag mode; got mode %q", req.Mode)
+		}
+		ordered, err := orderWorkflowSteps(req.Steps)
+		if err != nil {
+			return tools.WorkflowRequest{}, err
+		}
+		steps, mode = ordered, "dag"
+	} else if mode == "dag" {
+		return tools.WorkflowRequest{}, fmt.Errorf("dag mode requires steps with id and depends_on")
+	}
+
+	var subtasks []string
+	if len(steps) == 0 {
+		subtasks = make([]string, 0, len(req.Subtasks))
+		for _, subtask := range req.Subtasks {
+			task := strings.TrimSpace(subtask)
+			if task != "" {
+				subtasks = append(subtasks, task)
+			}
+		}
+		if len(subtasks) == 0 {
+			subtasks = splitWorkflowGoal(goal)
+		}
+		if len(subtasks) == 0 {
+			subtasks = []string{goal}
 		}
-	}
-	if len(subtasks) == 0 {
-		subtasks = splitWorkflowGoal(goal)
-	}
-	if len(subtasks) == 0 {
-		subtasks = []string{goal}
 	}
 
 	if mode == "" {
-		if len(subtasks) > 1 {
+		if len(subtasks) > 1 && !req.PassOutputs {
 			mode = "parallel"
 		} else {
 			mode = "sequential"
 		}
 	}
+	if req.PassOutputs {
+		if mode == "parallel" {
+			return tools.WorkflowRequest{}, fmt.Errorf("pass_outputs requires sequential or dag mode")
+		}
+		if err := validateStepOutputRefs(mode, subtasks, steps); err != nil {
+			return tools.WorkflowRequest{}, err
+		}
+	}
 
 	channel := strings.TrimSpace(req.OriginChannel)
 	if channel == "" {
@@ -378,11 +499,14 @@ func (m *SubagentManager) normalizeWorkflow(req tools.WorkflowRequest) (tools.Wo
 		Goal:           goal,
 		Mode:           mode,
 		Subtasks:       subtasks,
+		Steps:          steps,
+		PassOutputs:    req.PassOutputs,
 		Label:          strings.TrimSpace(req.Label),
 		OriginChannel:  channel,
 		OriginChatID:   chatID,
 		OriginSenderID: senderID,
 		RequestID:      strings.TrimSpace(req.RequestID),
+		Depth:          depth,
 	}, nil
 }
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/components/tool"
 )
 
 type fakeSubagentProcessor struct {
@@ -246,3 +247,399 @@ func TestSubagentManager_RunWorkflow_ReportsPerTaskFailures(t *testing.T) {
 		t.Fatalf("expected failed subtask name in summary, got: %s", out)
 	}
 }
+
+// dagTestProcessor 记录每个任务的开始与结束顺序，用于校验依赖约束。
+type dagTestProcessor struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (p *dagTestProcessor) ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error) {
+	task := strings.TrimSpace(content)
+	p.mu.Lock()
+	p.events = append(p.events, "start:"+task)
+	p.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	p.mu.Lock()
+	p.events = append(p.events, "end:"+task)
+	p.mu.Unlock()
+	if strings.Contains(task, "fail") {
+		return "", fmt.Errorf("failed subtask: %s", task)
+	}
+	return "done:" + task, nil
+}
+
+func (p *dagTestProcessor) position(event string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, e := range p.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSubagentManager_RunWorkflow_DAGRespectsDependencies(t *testing.T) {
+	processor := &dagTestProcessor{}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{
+		Timeout:        2 * time.Second,
+		MaxConcurrency: 3,
+	})
+
+	out, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal: "compare vendors",
+		Steps: []tools.WorkflowStep{
+			{ID: "report", Task: "write report", DependsOn: []string{"compare"}},
+			{ID: "compare", Task: "compare prices", DependsOn: []string{"a", "b"}},
+			{ID: "a", Task: "research vendor a"},
+			{ID: "b", Task: "research vendor b"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+
+	if !strings.Contains(out, "Mode: dag total=4 succeeded=4 failed=0") {
+		t.Fatalf("unexpected mode line: %s", out)
+	}
+	if !strings.Contains(out, "Execution order: [a, b] -> [compare] -> [report]") {
+		t.Fatalf("expected execution order in summary, got: %s", out)
+	}
+	if strings.Index(out, "[a] OK") > strings.Index(out, "[compare] OK") || strings.Index(out, "[compare] OK") > strings.Index(out, "[report] OK") {
+		t.Fatalf("expected results in topological order, got: %s", out)
+	}
+
+	compareStart := processor.position("start:compare prices")
+	for _, dep := range []string{"end:research vendor a", "end:research vendor b"} {
+		if pos := processor.position(dep); pos < 0 || pos > compareStart {
+			t.Fatalf("compare started before %s: %v", dep, processor.events)
+		}
+	}
+	if processor.position("end:compare prices") > processor.position("start:write report") {
+		t.Fatalf("report started before compare finished: %v", processor.events)
+	}
+	// 互不依赖的 a 与 b 应并行执行。
+	if processor.position("start:research vendor b") > processor.position("end:research vendor a") &&
+		processor.position("start:research vendor a") > processor.position("end:research vendor b") {
+		t.Fatalf("expected independent steps to overlap: %v", processor.events)
+	}
+}
+
+func TestSubagentManager_RunWorkflow_DAGSkipsStepsAfterFailedDependency(t *testing.T) {
+	processor := &dagTestProcessor{}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{Timeout: 2 * time.Second})
+
+	out, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal: "release",
+		Mode: "dag",
+		Steps: []tools.WorkflowStep{
+			{ID: "build", Task: "fail build"},
+			{ID: "lint", Task: "run lint"},
+			{ID: "deploy", Task: "deploy release", DependsOn: []string{"build", "lint"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+	if !strings.Contains(out, "succeeded=1 failed=2") {
+		t.Fatalf("expected skipped dependent to count as failed, got: %s", out)
+	}
+	if !strings.Contains(out, `[deploy] FAIL deploy release -> skipped: dependency "build" did not succeed`) {
+		t.Fatalf("expected deploy to be skipped, got: %s", out)
+	}
+	if processor.position("start:deploy release") >= 0 {
+		t.Fatalf("deploy should not have run: %v", processor.events)
+	}
+}
+
+func TestSubagentManager_RunWorkflow_DAGRejectsInvalidGraphs(t *testing.T) {
+	manager := NewSubagentManagerWithOptions(nil, &dagTestProcessor{}, SubagentManagerOptions{Timeout: time.Second})
+
+	cases := []struct {
+		name    string
+		mode    string
+		steps   []tools.WorkflowStep
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			steps: []tools.WorkflowStep{
+				{ID: "start", Task: "kick off"},
+				{ID: "a", Task: "task a", DependsOn: []string{"start", "b"}},
+				{ID: "b", Task: "task b", DependsOn: []string{"a"}},
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+		{
+			name:    "self dependency",
+			steps:   []tools.WorkflowStep{{ID: "a", Task: "task a", DependsOn: []string{"a"}}},
+			wantErr: "dependency cycle: a -> a",
+		},
+		{
+			name:    "unknown dependency",
+			steps:   []tools.WorkflowStep{{ID: "a", Task: "task a", DependsOn: []string{"missing"}}},
+			wantErr: `depends on unknown step "missing"`,
+		},
+		{
+			name:    "duplicate id",
+			steps:   []tools.WorkflowStep{{ID: "a", Task: "one"}, {ID: "a", Task: "two"}},
+			wantErr: `duplicate workflow step id "a"`,
+		},
+		{
+			name:    "steps with parallel mode",
+			mode:    "parallel",
+			steps:   []tools.WorkflowStep{{ID: "a", Task: "one"}},
+			wantErr: "run in dag mode",
+		},
+		{
+			name:    "dag without steps",
+			mode:    "dag",
+			wantErr: "dag mode requires steps",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{Goal: "g", Mode: tc.mode, Steps: tc.steps})
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// promptRecordingProcessor 记录每个步骤实际收到的提示词，输出由 outputs 按任务前缀决定。
+type promptRecordingProcessor struct {
+	mu      sync.Mutex
+	prompts []string
+	outputs map[string]string
+}
+
+func (p *promptRecordingProcessor) ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error) {
+	p.mu.Lock()
+	p.prompts = append(p.prompts, content)
+	p.mu.Unlock()
+	for prefix, out := range p.outputs {
+		if strings.HasPrefix(content, prefix) {
+			return out, nil
+		}
+	}
+	return "done", nil
+}
+
+func (p *promptRecordingProcessor) promptFor(prefix string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, prompt := range p.prompts {
+		if strings.HasPrefix(prompt, prefix) {
+			return prompt
+		}
+	}
+	return ""
+}
+
+func TestSubagentManager_RunWorkflow_PassOutputsSequential(t *testing.T) {
+	processor := &promptRecordingProcessor{outputs: map[string]string{
+		"collect": "finding: disk is full",
+		"check":   "finding: queue is stuck",
+	}}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{Timeout: 2 * time.Second})
+
+	out, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal:        "incident review",
+		Subtasks:    []string{"collect logs", "check queues", "summarize: {{step_1_output}} / {{ step_2_output }}", "write the postmortem"},
+		PassOutputs: true,
+	})
+	if err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+	if !strings.Contains(out, "Mode: sequential") {
+		t.Fatalf("pass_outputs should default to sequential mode, got: %s", out)
+	}
+	if got := processor.promptFor("summarize"); got != "summarize: finding: disk is full / finding: queue is stuck" {
+		t.Fatalf("unexpected templated prompt: %q", got)
+	}
+	postmortem := processor.promptFor("write the postmortem")
+	for _, want := range []string{"Results from earlier steps:", "[step 1]\nfinding: disk is full", "[step 3]\ndone"} {
+		if !strings.Contains(postmortem, want) {
+			t.Fatalf("expected %q in appended prompt, got: %q", want, postmortem)
+		}
+	}
+	if !strings.Contains(out, "[3] OK summarize: {{step_1_output}}") {
+		t.Fatalf("summary should show the original task, got: %s", out)
+	}
+}
+
+func TestSubagentManager_RunWorkflow_PassOutputsDAGUsesDependencies(t *testing.T) {
+	processor := &promptRecordingProcessor{outputs: map[string]string{
+		"research a": "vendor a costs 10",
+		"research b": "vendor b costs 12",
+		"unrelated":  "noise",
+	}}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{Timeout: 2 * time.Second})
+
+	_, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal: "vendor review",
+		Steps: []tools.WorkflowStep{
+			{ID: "a", Task: "research a"},
+			{ID: "b", Task: "research b"},
+			{ID: "other", Task: "unrelated check"},
+			{ID: "compare", Task: "compare vendors", DependsOn: []string{"a", "b"}},
+			{ID: "report", Task: "report on {{step_a_output}} using {{step_compare_output}}", DependsOn: []string{"compare"}},
+		},
+		PassOutputs: true,
+	})
+	if err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+
+	compare := processor.promptFor("compare vendors")
+	if !strings.Contains(compare, "[step a]\nvendor a costs 10") || !strings.Contains(compare, "[step b]\nvendor b costs 12") {
+		t.Fatalf("expected dependency outputs in prompt, got: %q", compare)
+	}
+	if strings.Contains(compare, "noise") {
+		t.Fatalf("non-dependency output should not be injected: %q", compare)
+	}
+	if got := processor.promptFor("report on"); got != "report on vendor a costs 10 using done" {
+		t.Fatalf("expected ancestor outputs to be templated, got: %q", got)
+	}
+}
+
+func TestSubagentManager_RunWorkflow_PassOutputsIsBounded(t *testing.T) {
+	huge := strings.Repeat("x", 3*maxStepOutputChars)
+	processor := &promptRecordingProcessor{outputs: map[string]string{"step": huge}}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{Timeout: 2 * time.Second})
+
+	subtasks := []string{"step one", "step two", "step three", "step four", "final"}
+	if _, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal:        "big outputs",
+		Subtasks:    subtasks,
+		PassOutputs: true,
+	}); err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+
+	final := processor.promptFor("final")
+	if n := len(final); n > maxInjectedOutputChars+1000 {
+		t.Fatalf("injected context not bounded: %d chars", n)
+	}
+	if !strings.Contains(final, "...(truncated)") || !strings.Contains(final, "context limit reached") {
+		t.Fatalf("expected truncation markers, got prompt of %d chars", len(final))
+	}
+}
+
+func TestSubagentManager_RunWorkflow_PassOutputsValidatesReferences(t *testing.T) {
+	manager := NewSubagentManagerWithOptions(nil, &promptRecordingProcessor{}, SubagentManagerOptions{Timeout: time.Second})
+
+	cases := []struct {
+		name    string
+		req     tools.WorkflowRequest
+		wantErr string
+	}{
+		{
+			name:    "parallel mode",
+			req:     tools.WorkflowRequest{Mode: "parallel", Subtasks: []string{"a", "b"}},
+			wantErr: "requires sequential or dag mode",
+		},
+		{
+			name:    "forward reference",
+			req:     tools.WorkflowRequest{Subtasks: []string{"use {{step_2_output}}", "b"}},
+			wantErr: "not an earlier step",
+		},
+		{
+			name: "dag reference to non-dependency",
+			req: tools.WorkflowRequest{Steps: []tools.WorkflowStep{
+				{ID: "a", Task: "a"},
+				{ID: "b", Task: "use {{step_a_output}}"},
+			}},
+			wantErr: `does not depend on step "a"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.req.Goal, tc.req.PassOutputs = "g", true
+			_, err := manager.RunWorkflow(context.Background(), tc.req)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSubagentManager_RunWorkflow_PlaceholdersUntouchedWithoutPassOutputs(t *testing.T) {
+	processor := &promptRecordingProcessor{}
+	manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{Timeout: time.Second})
+
+	if _, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{
+		Goal:     "g",
+		Mode:     "sequential",
+		Subtasks: []string{"first", "echo {{step_1_output}}"},
+	}); err != nil {
+		t.Fatalf("RunWorkflow: %v", err)
+	}
+	if got := processor.promptFor("echo"); got != "echo {{step_1_output}}" {
+		t.Fatalf("expected task unchanged, got %q", got)
+	}
+}
+
+// delegatingProcessor 在任务以 "delegate" 开头时通过 subagent 工具继续委派，模拟子代理递归。
+type delegatingProcessor struct {
+	tool interface {
+		InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error)
+	}
+}
+
+func (p *delegatingProcessor) ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error) {
+	if !strings.HasPrefix(content, "delegate") {
+		return "leaf done", nil
+	}
+	out, err := p.tool.InvokableRun(ctx, `{"task":"leaf task"}`)
+	if err != nil {
+		return "nested error: " + err.Error(), nil
+	}
+	return "nested result: " + out, nil
+}
+
+func TestSubagentManager_MaxDepthRejectsNestedDelegation(t *testing.T) {
+	for _, tc := range []struct {
+		maxDepth int
+		want     string
+	}{
+		{maxDepth: 1, want: "subagent depth limit reached: depth 2 exceeds agents.subagent.max_depth=1"},
+		{maxDepth: 2, want: "nested result: leaf done"},
+	} {
+		processor := &delegatingProcessor{}
+		manager := NewSubagentManagerWithOptions(nil, processor, SubagentManagerOptions{
+			Timeout:        2 * time.Second,
+			MaxConcurrency: 2,
+			MaxDepth:       tc.maxDepth,
+		})
+		subagentTool, err := tools.NewSubagentTool(manager)
+		if err != nil {
+			t.Fatalf("NewSubagentTool: %v", err)
+		}
+		processor.tool = subagentTool
+
+		out, err := subagentTool.InvokableRun(context.Background(), `{"task":"delegate the research"}`)
+		if err != nil {
+			t.Fatalf("max_depth=%d: top-level subagent should run, got %v", tc.maxDepth, err)
+		}
+		if !strings.Contains(out, tc.want) {
+			t.Fatalf("max_depth=%d: expected %q, got %q", tc.maxDepth, tc.want, out)
+		}
+	}
+}
+
+func TestSubagentManager_MaxDepthAppliesToSpawnAndWorkflow(t *testing.T) {
+	manager := NewSubagentManagerWithOptions(nil, &fakeSubagentProcessor{response: "ok"}, SubagentManagerOptions{MaxDepth: 1})
+
+	if _, err := manager.Spawn(context.Background(), tools.SubagentRequest{Task: "t", Depth: 2}); err == nil || !strings.Contains(err.Error(), "depth limit reached") {
+		t.Fatalf("expected spawn to be rejected, got %v", err)
+	}
+	if _, err := manager.RunWorkflow(context.Background(), tools.WorkflowRequest{Goal: "g", Depth: 2}); err == nil || !strings.Contains(err.Error(), "depth limit reached") {
+		t.Fatalf("expected workflow to be rejected, got %v", err)
+	}
+	if _, err := manager.RunSync(context.Background(), tools.SubagentRequest{Task: "t"}); err != nil {
+		t.Fatalf("request without depth should count as depth 1: %v", err)
+	}
+}
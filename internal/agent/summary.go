@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/session"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// summaryTimeout 是单次摘要模型调用的时限。
+	summaryTimeout = 2 * time.Minute
+	// summaryMaxMessageRunes 是送入摘要的单条消息的最大字符数，超出部分截断。
+	summaryMaxMessageRunes = 4000
+)
+
+const summarySystemPrompt = `You compress chat history. Summarize the conversation below so the assistant can continue it later without the original messages.
+Keep facts, decisions, user preferences, open tasks, names, numbers and file paths. Merge the previous summary, if any, into the new one.
+Write in the language of the conversation. Output only the summary, at most a few short paragraphs or bullet points.`
+
+// maybeSummarize 在会话消息数超过 agents.summary.trigger_messages 时，于后台用模型把较早的消息
+// 总结为摘要并替换原始消息，只保留最近 keep_recent 条。同一会话同时只进行一次压缩。
+func (l *Loop) maybeSummarize(msg *bus.InboundMessage, sess *session.Session, chatModel model.ChatModel) {
+	if l.config == nil || chatModel == nil {
+		return
+	}
+	cfg := l.config.Agents.Summary
+	if !cfg.Enabled || cfg.TriggerMessages <= 0 || cfg.KeepRecent >= cfg.TriggerMessages {
+		return
+	}
+	history := sess.GetHistory(0)
+	if len(history) <= cfg.TriggerMessages {
+		return
+	}
+	if _, busy := l.summarizing.LoadOrStore(sess.Key, struct{}{}); busy {
+		return
+	}
+
+	older := history[:len(history)-cfg.KeepRecent]
+	previous := sess.Summary()
+	l.summaries.Add(1)
+	go func() {
+		defer l.summaries.Done()
+		defer l.summarizing.Delete(sess.Key)
+
+		ctx, cancel := context.WithTimeout(bus.WithRequestID(context.Background(), msg.RequestID), summaryTimeout)
+		defer cancel()
+		resp, err := chatModel.Generate(ctx, buildSummaryMessages(previous, older))
+		if err == nil && (resp == nil || strings.TrimSpace(resp.Content) == "") {
+			err = fmt.Errorf("model returned an empty summary")
+		}
+		if err != nil {
+			slog.Warn("conversation summary failed; keeping full history", "request_id", msg.RequestID, "session_key", sess.Key, "error", err)
+			return
+		}
+		l.recordModelUsage(msg, resp)
+
+		summary := strings.TrimSpace(resp.Content)
+		compacted, err := l.sessions.Compact(sess, summary, older[len(older)-1])
+		if err != nil {
+			slog.Warn("failed to save conversation summary", "request_id", msg.RequestID, "session_key", sess.Key, "error", err)
+		}
+		if compacted {
+			slog.Info("conversation history summarized",
+				"request_id", msg.RequestID,
+				"session_key", sess.Key,
+				"summarized_messages", len(older),
+				"summary_chars", len([]rune(summary)),
+			)
+		}
+	}()
+}
+
+// buildSummaryMessages 构建摘要请求：系统提示加上旧摘要与待压缩的消息。
+func buildSummaryMessages(previous string, older []*session.Message) []*schema.Message {
+	var sb strings.Builder
+	if previous = strings.TrimSpace(previous); previous != "" {
+		sb.WriteString("Previous summary:\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("Messages:\n")
+	for _, m := range older {
+		content := strings.TrimSpace(m.Content)
+		if runes := []rune(content); len(runes) > summaryMaxMessageRunes {
+			content = string(runes[:summaryMaxMessageRunes]) + "…"
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", m.Role, content)
+	}
+	return []*schema.Message{
+		{Role: schema.System, Content: summarySystemPrompt},
+		{Role: schema.User, Content: sb.String()},
+	}
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// summarizingModel answers summary requests with a fixed summary and records
+// the system prompt of regular turns.
+type summarizingModel struct {
+	mu             sync.Mutex
+	summaryCalls   int
+	summaryInput   string
+	lastTurnSystem string
+}
+
+func (m *summarizingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if input[0].Content == summarySystemPrompt {
+		m.summaryCalls++
+		m.summaryInput = input[1].Content
+		return &schema.Message{Role: schema.Assistant, Content: "User is planning a trip to Kyoto."}, nil
+	}
+	m.lastTurnSystem = input[0].Content
+	return &schema.Message{Role: schema.Assistant, Content: "ok"}, nil
+}
+
+func (m *summarizingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *summarizingModel) BindTools(tools []*schema.ToolInfo) error { return nil }
+
+func TestLoop_SummarizesOldHistoryWhenEnabled(t *testing.T) {
+	chatModel := &summarizingModel{}
+	loop := newTestLoop(t, chatModel, 3)
+	cfg := config.DefaultConfig()
+	cfg.Agents.Summary = config.HistorySummaryConfig{Enabled: true, TriggerMessages: 6, KeepRecent: 2}
+	loop.config = cfg
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if _, err := loop.ProcessDirect(ctx, "message about kyoto"); err != nil {
+			t.Fatalf("ProcessDirect: %v", err)
+		}
+		loop.summaries.Wait()
+	}
+
+	sess := loop.sessions.GetOrCreate("cli:direct")
+	if chatModel.summaryCalls != 1 {
+		t.Fatalf("expected one summary call, got %d", chatModel.summaryCalls)
+	}
+	if !strings.Contains(chatModel.summaryInput, "user: message about kyoto") {
+		t.Fatalf("expected older messages in summary request, got %q", chatModel.summaryInput)
+	}
+	if sess.Summary() != "User is planning a trip to Kyoto." || len(sess.GetHistory(0)) != 2 {
+		t.Fatalf("expected summary with 2 recent messages, got %q and %d messages", sess.Summary(), len(sess.GetHistory(0)))
+	}
+
+	if _, err := loop.ProcessDirect(ctx, "next"); err != nil {
+		t.Fatalf("ProcessDirect: %v", err)
+	}
+	if !strings.Contains(chatModel.lastTurnSystem, "## Conversation So Far") || !strings.Contains(chatModel.lastTurnSystem, "trip to Kyoto") {
+		t.Fatalf("expected summary in system prompt, got %q", chatModel.lastTurnSystem)
+	}
+}
+
+func TestLoop_SummaryDisabledKeepsHistory(t *testing.T) {
+	chatModel := &summarizingModel{}
+	loop := newTestLoop(t, chatModel, 3)
+	loop.config = config.DefaultConfig()
+	loop.config.Agents.Summary.TriggerMessages = 2
+	loop.config.Agents.Summary.KeepRecent = 1
+
+	for i := 0; i < 3; i++ {
+		if _, err := loop.ProcessDirect(context.Background(), "hello"); err != nil {
+			t.Fatalf("ProcessDirect: %v", err)
+		}
+	}
+	loop.summaries.Wait()
+	if chatModel.summaryCalls != 0 || len(loop.sessions.GetOrCreate("cli:direct").GetHistory(0)) != 6 {
+		t.Fatalf("expected no summarization when disabled, got %d calls", chatModel.summaryCalls)
+	}
+}
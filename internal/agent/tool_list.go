@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/MEKXH/golem/internal/command"
+)
+
+// ListTools 返回已注册工具的名称、描述与输入参数 Schema，按名称排序；
+// 被 channel 的 tool_policy 禁止的工具会在 Disabled 中给出原因。
+func (l *Loop) ListTools(ctx context.Context, channel string) ([]command.ToolSummary, error) {
+	infos, err := l.tools.GetToolInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	guard := l.guard()
+
+	list := make([]command.ToolSummary, 0, len(infos))
+	for _, info := range infos {
+		summary := command.ToolSummary{Name: info.Name, Description: info.Desc}
+		if info.ParamsOneOf != nil {
+			if js, err := info.ParamsOneOf.ToJSONSchema(); err == nil && js != nil {
+				if data, err := json.MarshalIndent(js, "", "  "); err == nil {
+					summary.Schema = string(data)
+				}
+			}
+		}
+		if guard != nil && channel != "" {
+			if reason, denied := guard.channelDenial(channel, info.Name); denied {
+				summary.Disabled = reason
+			}
+		}
+		list = append(list, summary)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+const (
+	defaultMaxConcurrentSessions = 4
+	defaultMaxParallelTools      = 8
+)
+
+// errTurnTimeout 是单轮对话超过 agents.defaults.turn_timeout_seconds 时的取消原因，
+// 用于与新消息取代、进程退出等其他取消原因区分。
+var errTurnTimeout = errors.New("agent turn timed out")
+
+// withTurnTimeout 为一轮对话设置总时限；未配置时限时原样返回 ctx。
+func (l *Loop) withTurnTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.turnTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, l.turnTimeout, errTurnTimeout)
+}
+
+// turnTimedOut 报告 ctx 是否因整轮时限到期而结束。
+func turnTimedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errTurnTimeout)
+}
+
+// turnTimeoutMessage 生成超时后的回复：保留模型已给出的部分内容，并附上超时说明。
+func turnTimeoutMessage(partial string, timeout time.Duration) string {
+	notice := fmt.Sprintf("Stopped: this request exceeded the %s time limit.", timeout)
+	if partial == "" {
+		return notice
+	}
+	return partial + "\n\n" + notice
+}
+
+// newTurnSemaphore 创建容量为 size 的会话并发信号量，size <= 0 时使用默认值。
+func newTurnSemaphore(size int) chan struct{} {
+	if size <= 0 {
+		size = defaultMaxConcurrentSessions
+	}
+	return make(chan struct{}, size)
+}
+
+// newToolSlots 创建容量为 size 的工具并行信号量，size <= 0 时使用默认值。
+func newToolSlots(size int) chan struct{} {
+	if size <= 0 {
+		size = defaultMaxParallelTools
+	}
+	return make(chan struct{}, size)
+}
+
+// activeTurn 记录某个会话正在处理中的一轮对话。
+type activeTurn struct {
+	requestID string
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// turnTracker 按会话键跟踪进行中的对话轮次，同一会话的新消息会取消上一轮。
+type turnTracker struct {
+	mu    sync.Mutex
+	turns map[string]*activeTurn
+	wg    sync.WaitGroup
+}
+
+// begin 为 key 登记新的一轮对话并返回其上下文；若该会话已有进行中的轮次则将其取消并返回，
+// 调用方需等待 prev.done 关闭后再开始处理，保证同一会话的历史按顺序写入。
+func (t *turnTracker) begin(ctx context.Context, key, requestID string) (turnCtx context.Context, turn, prev *activeTurn) {
+	turnCtx, cancel := context.WithCancel(ctx)
+	turn = &activeTurn{requestID: requestID, cancel: cancel, done: make(chan struct{})}
+
+	t.mu.Lock()
+	if t.turns == nil {
+		t.turns = make(map[string]*activeTurn)
+	}
+	prev = t.turns[key]
+	t.turns[key] = turn
+	t.mu.Unlock()
+
+	if prev != nil {
+		prev.cancel()
+	}
+	return turnCtx, turn, prev
+}
+
+// current 返回 key 对应会话进行中的轮次，没有时返回 nil。
+func (t *turnTracker) current(key string) *activeTurn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.turns[key]
+}
+
+// finish 释放 turn 占用的资源；仅当它仍是该会话的当前轮次时才移除登记。
+func (t *turnTracker) finish(key string, turn *activeTurn) {
+	t.mu.Lock()
+	if t.turns[key] == turn {
+		delete(t.turns, key)
+	}
+	t.mu.Unlock()
+	turn.cancel()
+	close(turn.done)
+}
+
+// dispatchMessage 在后台处理一条入站消息。同一会话的新消息会取消仍在进行中的上一轮，
+// 并在上一轮结束后才开始处理，保证同一会话的出站消息按顺序发布；
+// 被取消轮次的回复或错误会被丢弃，不会发送给用户。
+// 等待上一轮结束时不占用并发名额，避免同一会话的排队消息挤占其他会话。
+func (l *Loop) dispatchMessage(ctx context.Context, msg *bus.InboundMessage) {
+	key := msg.SessionKey()
+	turnCtx, turn, prev := l.turns.begin(ctx, key, msg.RequestID)
+	if prev != nil {
+		slog.Info("cancelling in-flight turn superseded by new message",
+			"session_key", key, "cancelled_request_id", prev.requestID, "request_id", msg.RequestID)
+	}
+
+	l.turns.wg.Add(1)
+	go func() {
+		defer l.turns.wg.Done()
+		defer l.turns.finish(key, turn)
+		if prev != nil {
+			<-prev.done
+		}
+		// 排队期间已被取代的消息不再处理；已取消时 select 会随机选择分支，因此先单独检查。
+		if turnCtx.Err() != nil {
+			l.discardQueuedTurn(msg)
+			return
+		}
+		if l.turnSem != nil {
+			select {
+			case l.turnSem <- struct{}{}:
+				defer func() { <-l.turnSem }()
+			case <-turnCtx.Done():
+				l.discardQueuedTurn(msg)
+				return
+			}
+		}
+
+		reaction := l.startReaction(turnCtx, msg)
+		resp, err := l.processMessage(turnCtx, msg)
+		if turnCtx.Err() != nil {
+			slog.Info("discarded result of cancelled turn", "request_id", msg.RequestID, "session_key", key)
+			reaction(turnCtx, turnCtx.Err())
+			return
+		}
+		if err != nil {
+			slog.Error("process message failed", "request_id", msg.RequestID, "channel", msg.Channel, "chat_id", msg.ChatID, "session_key", key, "error", err)
+			l.bus.PublishOutbound(replyInThread(&bus.OutboundMessage{
+				Channel:   msg.Channel,
+				ChatID:    msg.ChatID,
+				Content:   "Error: " + err.Error(),
+				RequestID: msg.RequestID,
+			}, msg))
+			reaction(turnCtx, err)
+			return
+		}
+		if resp != nil {
+			l.bus.PublishOutbound(replyInThread(resp, msg))
+		}
+		reaction(turnCtx, nil)
+	}()
+}
+
+// discardQueuedTurn 丢弃排队期间被取消的消息，并像处理中被取消的轮次一样记下用户消息和取消说明。
+// 斜杠命令与审批回复不写入会话历史，计划模式同样不写入。
+func (l *Loop) discardQueuedTurn(msg *bus.InboundMessage) {
+	slog.Info("discarded message cancelled while waiting to be processed", "request_id", msg.RequestID, "session_key", msg.SessionKey())
+	if l.PlanOnly {
+		return
+	}
+	content := msg.Content
+	if reply, ok := l.approvalReplyCommand(content); ok {
+		content = reply
+	}
+	if _, _, ok := l.commands.Lookup(content); ok {
+		return
+	}
+	l.recordCancelledTurn(l.sessions.GetOrCreate(msg.SessionKey()), msg)
+}
+
+// dispatchSystemMessage 处理系统消息（如子代理结果）。来源会话仍有进行中的轮次时，
+// 等该轮结束后再发布，避免子代理结果先于发起它的那条回复送达；等待不会取消该轮次。
+func (l *Loop) dispatchSystemMessage(msg *bus.InboundMessage) {
+	channel, chatID := systemMessageOrigin(msg)
+	prev := l.turns.current(channel + ":" + chatID)
+	if prev == nil {
+		l.processSystemMessage(msg)
+		return
+	}
+
+	l.turns.wg.Add(1)
+	go func() {
+		defer l.turns.wg.Done()
+		<-prev.done
+		l.processSystemMessage(msg)
+	}()
+}
@@ -0,0 +1,442 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/audit"
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// blockingFirstModel blocks the first Generate call until its context is
+// cancelled and echoes the latest user message on later calls.
+type blockingFirstModel struct {
+	calls     atomic.Int32
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (m *blockingFirstModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	if m.calls.Add(1) == 1 {
+		close(m.started)
+		<-ctx.Done()
+		close(m.cancelled)
+		return nil, ctx.Err()
+	}
+	return &schema.Message{Role: schema.Assistant, Content: "reply to " + input[len(input)-1].Content}, nil
+}
+
+func (m *blockingFirstModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *blockingFirstModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestRun_NewMessageCancelsInFlightTurn(t *testing.T) {
+	chatModel := &blockingFirstModel{started: make(chan struct{}), cancelled: make(chan struct{})}
+	loop := newTestLoop(t, chatModel, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- loop.Run(ctx) }()
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "first", RequestID: "req-1"})
+	select {
+	case <-chatModel.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for first turn to start")
+	}
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "correction", RequestID: "req-2"})
+	select {
+	case <-chatModel.cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected in-flight turn to be cancelled")
+	}
+
+	out := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if out.RequestID != "req-2" || out.Content != "reply to correction" {
+		t.Fatalf("expected reply to the new message only, got %+v", out)
+	}
+	select {
+	case extra := <-loop.bus.Outbound():
+		t.Fatalf("cancelled turn should not publish, got %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 被取消的轮次保留用户消息和取消说明，但不保留未发出的回复。
+	history := loop.sessions.GetOrCreate("telegram:1").GetHistory(10)
+	want := []string{"first", cancelledTurnNote, "correction", "reply to correction"}
+	if len(history) != len(want) {
+		t.Fatalf("expected %d messages in history, got %d", len(want), len(history))
+	}
+	for i, content := range want {
+		if history[i].Content != content {
+			t.Fatalf("history[%d] = %q, want %q", i, history[i].Content, content)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "context canceled") {
+			t.Fatalf("expected context canceled from Run, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+}
+
+func TestTurnTracker_OtherSessionsUnaffected(t *testing.T) {
+	var tracker turnTracker
+	ctxA, turnA, _ := tracker.begin(context.Background(), "a", "req-a")
+	ctxB, turnB, prev := tracker.begin(context.Background(), "b", "req-b")
+	if prev != nil {
+		t.Fatal("expected no previous turn for a different session")
+	}
+
+	_, turnA2, prev := tracker.begin(context.Background(), "a", "req-a2")
+	if prev != turnA || ctxA.Err() == nil {
+		t.Fatal("expected previous turn of the same session to be cancelled")
+	}
+	if ctxB.Err() != nil {
+		t.Fatal("turn of another session must not be cancelled")
+	}
+
+	// 已被取代的轮次结束时不应移除新轮次的登记。
+	tracker.finish("a", turnA)
+	tracker.mu.Lock()
+	current := tracker.turns["a"]
+	tracker.mu.Unlock()
+	if current != turnA2 {
+		t.Fatal("finishing a superseded turn removed the current one")
+	}
+	tracker.finish("a", turnA2)
+	tracker.finish("b", turnB)
+	if len(tracker.turns) != 0 {
+		t.Fatalf("expected all turns released, got %d", len(tracker.turns))
+	}
+}
+
+// gatedModel blocks requests whose latest message is "slow" until release is
+// closed and answers everything else immediately.
+type gatedModel struct {
+	release chan struct{}
+	started chan struct{}
+}
+
+func (m *gatedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	content := input[len(input)-1].Content
+	if content == "slow" {
+		m.started <- struct{}{}
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &schema.Message{Role: schema.Assistant, Content: "reply to " + content}, nil
+}
+
+func (m *gatedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *gatedModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestRun_SlowSessionDoesNotBlockOthers(t *testing.T) {
+	chatModel := &gatedModel{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	loop := newTestLoop(t, chatModel, 2)
+	loop.turnSem = newTurnSemaphore(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "slow", Content: "slow", RequestID: "req-slow"})
+	<-chatModel.started
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "fast", Content: "fast", RequestID: "req-fast"})
+
+	out := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if out.RequestID != "req-fast" {
+		t.Fatalf("expected the other session to reply first, got %+v", out)
+	}
+
+	close(chatModel.release)
+	out = waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if out.RequestID != "req-slow" {
+		t.Fatalf("expected slow session reply, got %+v", out)
+	}
+}
+
+func TestRun_LimitsConcurrentSessions(t *testing.T) {
+	chatModel := &gatedModel{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	loop := newTestLoop(t, chatModel, 2)
+	loop.turnSem = newTurnSemaphore(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "slow", Content: "slow", RequestID: "req-slow"})
+	<-chatModel.started
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "fast", Content: "fast", RequestID: "req-fast"})
+
+	select {
+	case out := <-loop.bus.Outbound():
+		t.Fatalf("expected second session to wait for a free slot, got %+v", out)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(chatModel.release)
+	first := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	second := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if first.RequestID != "req-slow" || second.RequestID != "req-fast" {
+		t.Fatalf("unexpected reply order: %q then %q", first.RequestID, second.RequestID)
+	}
+}
+
+func TestRun_MessageSupersededWhileQueuedKeepsUserText(t *testing.T) {
+	chatModel := &gatedModel{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	loop := newTestLoop(t, chatModel, 2)
+	loop.turnSem = newTurnSemaphore(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "slow", Content: "slow", RequestID: "req-slow"})
+	<-chatModel.started
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "queued", RequestID: "req-1"})
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "correction", RequestID: "req-2"})
+
+	close(chatModel.release)
+	first := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	second := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if first.RequestID != "req-slow" || second.RequestID != "req-2" || second.Content != "reply to correction" {
+		t.Fatalf("unexpected replies: %+v then %+v", first, second)
+	}
+	select {
+	case extra := <-loop.bus.Outbound():
+		t.Fatalf("superseded queued message should not publish, got %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 排队时被取代的消息同样保留用户消息和取消说明。
+	history := loop.sessions.GetOrCreate("telegram:1").GetHistory(10)
+	want := []string{"queued", cancelledTurnNote, "correction", "reply to correction"}
+	if len(history) != len(want) {
+		t.Fatalf("expected %d messages in history, got %d", len(want), len(history))
+	}
+	for i, content := range want {
+		if history[i].Content != content {
+			t.Fatalf("history[%d] = %q, want %q", i, history[i].Content, content)
+		}
+	}
+}
+
+func TestRun_SubagentResultWaitsForOriginTurn(t *testing.T) {
+	chatModel := &gatedModel{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	loop := newTestLoop(t, chatModel, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = loop.Run(ctx) }()
+
+	loop.bus.PublishInbound(&bus.InboundMessage{Channel: "telegram", ChatID: "1", Content: "slow", RequestID: "req-1"})
+	<-chatModel.started
+	loop.bus.PublishInbound(bus.NewSubagentResultInbound("task-1", "", "telegram", "1", "user", "sub result", "req-1", nil))
+
+	select {
+	case out := <-loop.bus.Outbound():
+		t.Fatalf("subagent result must wait for the origin turn, got %+v", out)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(chatModel.release)
+	first := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	second := waitOutbound(t, loop.bus.Outbound(), 2*time.Second, func(*bus.OutboundMessage) bool { return true })
+	if first.Content != "reply to slow" || !strings.Contains(second.Content, "sub result") {
+		t.Fatalf("unexpected order: %q then %q", first.Content, second.Content)
+	}
+}
+
+// ctxWaitTool blocks until its context ends, like a long exec or web call.
+type ctxWaitTool struct{}
+
+func (t *ctxWaitTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "wait_tool", Desc: "Waits for cancellation"}, nil
+}
+
+func (t *ctxWaitTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// partialThenWaitModel answers with some text and a call to wait_tool.
+type partialThenWaitModel struct {
+	calls atomic.Int32
+}
+
+func (m *partialThenWaitModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls.Add(1)
+	return &schema.Message{
+		Role:      schema.Assistant,
+		Content:   "Partial answer",
+		ToolCalls: []schema.ToolCall{{ID: "call_1", Function: schema.FunctionCall{Name: "wait_tool", Arguments: "{}"}}},
+	}, nil
+}
+
+func (m *partialThenWaitModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *partialThenWaitModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestProcessForChannel_TurnTimeoutReturnsPartialReply(t *testing.T) {
+	chatModel := &partialThenWaitModel{}
+	loop := newTestLoop(t, chatModel, 5)
+	loop.turnTimeout = 50 * time.Millisecond
+	loop.runtimeGuard = &runtimeGuard{auditWriter: audit.NewWriter(loop.workspacePath)}
+	if err := loop.tools.Register(&ctxWaitTool{}); err != nil {
+		t.Fatalf("register tool: %v", err)
+	}
+
+	start := time.Now()
+	reply, err := loop.ProcessForChannel(context.Background(), "telegram", "1", "user", "long task")
+	if err != nil {
+		t.Fatalf("expected timeout to produce a reply, got error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("turn did not stop at the deadline, took %s", elapsed)
+	}
+	if !strings.HasPrefix(reply, "Partial answer\n\nStopped:") || !strings.Contains(reply, "50ms") {
+		t.Fatalf("unexpected timeout reply %q", reply)
+	}
+	if got := chatModel.calls.Load(); got != 1 {
+		t.Fatalf("expected no model calls after the deadline, got %d calls", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(loop.workspacePath, "state", "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if !strings.Contains(string(data), `"turn_timeout"`) {
+		t.Fatalf("expected turn_timeout audit event, got %s", data)
+	}
+}
+
+func TestProcessForChannel_TurnTimeoutDuringModelCall(t *testing.T) {
+	chatModel := &gatedModel{release: make(chan struct{}), started: make(chan struct{}, 1)}
+	loop := newTestLoop(t, chatModel, 2)
+	loop.turnTimeout = 30 * time.Millisecond
+
+	reply, err := loop.ProcessForChannel(context.Background(), "telegram", "1", "user", "slow")
+	if err != nil {
+		t.Fatalf("expected timeout reply, got error: %v", err)
+	}
+	if !strings.HasPrefix(reply, "Stopped: this request exceeded the 30ms time limit") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+}
+
+func TestProcessForChannel_NoTurnTimeoutByDefault(t *testing.T) {
+	loop := newTestLoop(t, &slowReplyModel{delay: 20 * time.Millisecond}, 2)
+	reply, err := loop.ProcessForChannel(context.Background(), "telegram", "1", "user", "hi")
+	if err != nil || reply != "done" {
+		t.Fatalf("unexpected result %q, %v", reply, err)
+	}
+}
+
+// fanOutModel returns calls parallel tool calls on the first Generate call
+// and echoes the tool results in order on the second.
+type fanOutModel struct {
+	calls int
+	turn  int
+}
+
+func (m *fanOutModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.turn++
+	if m.turn == 1 {
+		toolCalls := make([]schema.ToolCall, m.calls)
+		for i := range toolCalls {
+			toolCalls[i] = schema.ToolCall{
+				ID:       fmt.Sprintf("call_%d", i),
+				Function: schema.FunctionCall{Name: "slow_tool", Arguments: fmt.Sprintf(`{"n":%d}`, i)},
+			}
+		}
+		return &schema.Message{Role: schema.Assistant, ToolCalls: toolCalls}, nil
+	}
+	var ids []string
+	for _, msg := range input {
+		if msg.Role == schema.Tool {
+			ids = append(ids, msg.ToolCallID)
+		}
+	}
+	return &schema.Message{Role: schema.Assistant, Content: strings.Join(ids, ",")}, nil
+}
+
+func (m *fanOutModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *fanOutModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+// concurrencyTool records the highest number of overlapping invocations.
+type concurrencyTool struct {
+	active atomic.Int32
+	peak   atomic.Int32
+}
+
+func (t *concurrencyTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "slow_tool", Desc: "A tool that tracks concurrency"}, nil
+}
+
+func (t *concurrencyTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	n := t.active.Add(1)
+	defer t.active.Add(-1)
+	for {
+		peak := t.peak.Load()
+		if n <= peak || t.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return "ok", nil
+}
+
+func TestProcessDirect_LimitsParallelToolCalls(t *testing.T) {
+	chatModel := &fanOutModel{calls: 10}
+	loop := newTestLoop(t, chatModel, 3)
+	loop.maxParallel = 3
+	slowTool := &concurrencyTool{}
+	if err := loop.tools.Register(slowTool); err != nil {
+		t.Fatalf("failed to register slow_tool: %v", err)
+	}
+
+	result, err := loop.ProcessDirect(context.Background(), "fan out")
+	if err != nil {
+		t.Fatalf("ProcessDirect returned error: %v", err)
+	}
+	if peak := slowTool.peak.Load(); peak > 3 || peak < 1 {
+		t.Fatalf("expected at most 3 concurrent tool calls, got %d", peak)
+	}
+	want := "call_0,call_1,call_2,call_3,call_4,call_5,call_6,call_7,call_8,call_9"
+	if result != want {
+		t.Fatalf("expected tool results in call order %q, got %q", want, result)
+	}
+}
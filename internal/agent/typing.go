@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// typingRefreshInterval 是“正在输入”提示的刷新间隔，需短于各平台提示的自动消失时间（Telegram 约 5 秒）。
+var typingRefreshInterval = 4 * time.Second
+
+// startTyping 立即发送一次“正在输入”提示，并在后台周期性刷新，直到返回的 stop 函数被调用或 ctx 结束。
+func (l *Loop) startTyping(ctx context.Context, channel, chatID string) (stop func()) {
+	notify := l.typingNotifier
+	if notify == nil || channel == "" || chatID == "" {
+		return func() {}
+	}
+
+	typingCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	notify(typingCtx, channel, chatID)
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(typingRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-typingCtx.Done():
+				return
+			case <-ticker.C:
+				notify(typingCtx, channel, chatID)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
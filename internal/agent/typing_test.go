@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// slowReplyModel replies after a fixed delay to simulate a long model call.
+type slowReplyModel struct {
+	delay time.Duration
+}
+
+func (m *slowReplyModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	time.Sleep(m.delay)
+	return &schema.Message{Role: schema.Assistant, Content: "done"}, nil
+}
+
+func (m *slowReplyModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, nil
+}
+
+func (m *slowReplyModel) BindTools(toolInfos []*schema.ToolInfo) error {
+	return nil
+}
+
+func TestProcessForChannel_SendsTypingWhileProcessing(t *testing.T) {
+	prev := typingRefreshInterval
+	typingRefreshInterval = 10 * time.Millisecond
+	defer func() { typingRefreshInterval = prev }()
+
+	loop := newTestLoop(t, &slowReplyModel{delay: 60 * time.Millisecond}, 2)
+
+	var mu sync.Mutex
+	var calls []string
+	loop.SetTypingNotifier(func(ctx context.Context, channel, chatID string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, channel+":"+chatID)
+	})
+
+	if _, err := loop.ProcessForChannel(context.Background(), "telegram", "42", "user", "hello"); err != nil {
+		t.Fatalf("ProcessForChannel error: %v", err)
+	}
+
+	mu.Lock()
+	count := len(calls)
+	first := ""
+	if count > 0 {
+		first = calls[0]
+	}
+	mu.Unlock()
+	if count < 2 {
+		t.Fatalf("expected initial and periodic typing notifications, got %d", count)
+	}
+	if first != "telegram:42" {
+		t.Fatalf("unexpected typing target %q", first)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	mu.Lock()
+	after := len(calls)
+	mu.Unlock()
+	if after != count {
+		t.Fatalf("expected typing to stop after processing, got %d more notifications", after-count)
+	}
+}
+
+func TestStartTyping_NoopWithoutNotifier(t *testing.T) {
+	loop := newTestLoop(t, nil, 1)
+	stop := loop.startTyping(context.Background(), "telegram", "42")
+	stop()
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MEKXH/golem/internal/tools"
+)
+
+const (
+	// maxStepOutputChars 限制注入后续步骤的单个步骤输出长度（按字符计）。
+	maxStepOutputChars = 4000
+	// maxInjectedOutputChars 限制单个步骤提示词中注入的前序输出总长度，避免撑爆上下文。
+	maxInjectedOutputChars = 12000
+)
+
+// stepOutputRe 匹配 {{step_<id>_output}} 占位符；顺序模式下 id 为从 1 开始的步骤序号。
+var stepOutputRe = regexp.MustCompile(`\{\{\s*step_([A-Za-z0-9_.-]+?)_output\s*\}\}`)
+
+// priorOutput 是可注入后续步骤的前序步骤结果。
+type priorOutput struct {
+	id     string
+	output string
+	err    error
+}
+
+// stepOutputRefs 返回任务中通过占位符引用的步骤 ID。
+func stepOutputRefs(task string) []string {
+	var refs []string
+	for _, m := range stepOutputRe.FindAllStringSubmatch(task, -1) {
+		refs = append(refs, m[1])
+	}
+	return refs
+}
+
+// injectStepOutputs 将前序步骤输出注入任务提示词。任务含占位符时就地替换（available 中查找）；
+// 否则把 direct 中的输出附加在任务之后。注入内容按单步与总量上限截断。
+func injectStepOutputs(task string, available, direct []priorOutput) string {
+	budget := maxInjectedOutputChars
+	render := func(p priorOutput) string {
+		text := strings.TrimSpace(p.output)
+		if p.err != nil {
+			text = fmt.Sprintf("(step %s failed: %v)", p.id, p.err)
+		}
+		if budget <= 0 {
+			return "(output omitted: workflow context limit reached)"
+		}
+		text = truncateChars(text, min(maxStepOutputChars, budget))
+		budget -= utf8.RuneCountInString(text)
+		return text
+	}
+
+	if stepOutputRe.MatchString(task) {
+		byID := make(map[string]priorOutput, len(available))
+		for _, p := range available {
+			byID[p.id] = p
+		}
+		return stepOutputRe.ReplaceAllStringFunc(task, func(m string) string {
+			p, ok := byID[stepOutputRe.FindStringSubmatch(m)[1]]
+			if !ok {
+				return m
+			}
+			return render(p)
+		})
+	}
+	if len(direct) == 0 {
+		return task
+	}
+
+	var b strings.Builder
+	b.WriteString(task)
+	b.WriteString("\n\nResults from earlier steps:")
+	for _, p := range direct {
+		fmt.Fprintf(&b, "\n\n[step %s]\n%s", p.id, render(p))
+	}
+	return b.String()
+}
+
+func truncateChars(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit]) + "\n...(truncated)"
+}
+
+// validateStepOutputRefs 校验占位符只引用在当前步骤之前必然完成的步骤：
+// 顺序模式下为更早的序号，dag 模式下为（直接或间接的）依赖。
+func validateStepOutputRefs(mode string, subtasks []string, steps []tools.WorkflowStep) error {
+	if mode == "dag" {
+		ancestors := make(map[string]map[string]bool, len(steps))
+		for _, step := range steps {
+			set := make(map[string]bool)
+			for _, dep := range step.DependsOn {
+				set[dep] = true
+				for a := range ancestors[dep] {
+					set[a] = true
+				}
+			}
+			ancestors[step.ID] = set
+			for _, ref := range stepOutputRefs(step.Task) {
+				if !set[ref] {
+					return fmt.Errorf("workflow step %q references {{step_%s_output}} but does not depend on step %q", step.ID, ref, ref)
+				}
+			}
+		}
+		return nil
+	}
+	for i, task := range subtasks {
+		for _, ref := range stepOutputRefs(task) {
+			n, err := strconv.Atoi(ref)
+			if err != nil || n < 1 || n > i {
+				return fmt.Errorf("workflow subtask %d references {{step_%s_output}}, which is not an earlier step", i+1, ref)
+			}
+		}
+	}
+	return nil
+}
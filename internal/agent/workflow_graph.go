@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/tools"
+)
+
+// orderWorkflowSteps 校验 dag 模式的步骤并按拓扑序返回：每个步骤都排在其依赖之后，
+// 互不依赖的步骤保持输入顺序。ID 缺失或重复、依赖未知步骤以及存在环时返回错误。
+func orderWorkflowSteps(steps []tools.WorkflowStep) ([]tools.WorkflowStep, error) {
+	byID := make(map[string]tools.WorkflowStep, len(steps))
+	normalized := make([]tools.WorkflowStep, 0, len(steps))
+	for i, raw := range steps {
+		id := strings.TrimSpace(raw.ID)
+		if id == "" {
+			return nil, fmt.Errorf("workflow step %d: id is required", i+1)
+		}
+		if _, exists := byID[id]; exists {
+			return nil, fmt.Errorf("duplicate workflow step id %q", id)
+		}
+		task := strings.TrimSpace(raw.Task)
+		if task == "" {
+			return nil, fmt.Errorf("workflow step %q: task is required", id)
+		}
+		step := tools.WorkflowStep{ID: id, Task: task}
+		seen := make(map[string]bool, len(raw.DependsOn))
+		for _, dep := range raw.DependsOn {
+			dep = strings.TrimSpace(dep)
+			if dep == "" || seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			step.DependsOn = append(step.DependsOn, dep)
+		}
+		byID[id] = step
+		normalized = append(normalized, step)
+	}
+	for _, step := range normalized {
+		for _, dep := range step.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				return nil, fmt.Errorf("workflow step %q depends on unknown step %q", step.ID, dep)
+			}
+		}
+	}
+
+	ordered := make([]tools.WorkflowStep, 0, len(normalized))
+	placed := make(map[string]bool, len(normalized))
+	for len(ordered) < len(normalized) {
+		progressed := false
+		for _, step := range normalized {
+			if placed[step.ID] || !depsPlaced(step, placed) {
+				continue
+			}
+			placed[step.ID] = true
+			ordered = append(ordered, step)
+			progressed = true
+		}
+		if !progressed {
+			cycle := findWorkflowCycle(normalized, placed)
+			return nil, fmt.Errorf("workflow steps contain a dependency cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return ordered, nil
+}
+
+func depsPlaced(step tools.WorkflowStep, placed map[string]bool) bool {
+	for _, dep := range step.DependsOn {
+		if !placed[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+// findWorkflowCycle 在尚未排序的步骤中找出一条依赖环，返回首尾相同的步骤 ID 路径。
+func findWorkflowCycle(steps []tools.WorkflowStep, placed map[string]bool) []string {
+	deps := make(map[string][]string, len(steps))
+	for _, step := range steps {
+		deps[step.ID] = step.DependsOn
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(steps))
+	var stack, cycle []string
+	var visit func(id string) bool
+	visit = func(id string) bool {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps[id] {
+			if placed[dep] {
+				continue
+			}
+			switch state[dep] {
+			case visiting:
+				for i, sid := range stack {
+					if sid == dep {
+						cycle = append(append([]string{}, stack[i:]...), dep)
+						return true
+					}
+				}
+			case 0:
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+		return false
+	}
+	for _, step := range steps {
+		if !placed[step.ID] && state[step.ID] == 0 && visit(step.ID) {
+			break
+		}
+	}
+	return cycle
+}
+
+// workflowStages 将拓扑序的步骤按依赖深度分层：同一层的步骤之间没有依赖，可并行执行。
+func workflowStages(ordered []tools.WorkflowStep) [][]string {
+	level := make(map[string]int, len(ordered))
+	var stages [][]string
+	for _, step := range ordered {
+		l := 0
+		for _, dep := range step.DependsOn {
+			l = max(l, level[dep]+1)
+		}
+		level[step.ID] = l
+		if l == len(stages) {
+			stages = append(stages, nil)
+		}
+		stages[l] = append(stages[l], step.ID)
+	}
+	return stages
+}
+
+// formatWorkflowStages 将执行层级格式化为 "[a, b] -> [c]" 形式，用于工作流摘要。
+func formatWorkflowStages(ordered []tools.WorkflowStep) string {
+	stages := workflowStages(ordered)
+	parts := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		parts = append(parts, "["+strings.Join(stage, ", ")+"]")
+	}
+	return strings.Join(parts, " -> ")
+}
@@ -56,6 +56,9 @@ func (s *Service) Create(input CreateInput) (Request, error) {
 		ToolName:    toolName,
 		ArgsJSON:    argsJSON,
 		Reason:      reason,
+		Preview:     input.Preview,
+		Channel:     strings.TrimSpace(input.Channel),
+		ChatID:      strings.TrimSpace(input.ChatID),
 		Status:      StatusPending,
 		RequestedAt: now,
 		ExpiresAt:   now.Add(ttl),
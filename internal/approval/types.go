@@ -18,6 +18,9 @@ type Request struct {
 	ToolName     string        `json:"tool_name"`               // 待执行工具的名称
 	ArgsJSON     string        `json:"args_json"`               // 工具执行的参数（JSON 格式）
 	Reason       string        `json:"reason,omitempty"`        // 触发审批的原因
+	Preview      string        `json:"preview,omitempty"`       // 待执行操作的预览（如 edit_file 的 diff）
+	Channel      string        `json:"channel,omitempty"`       // 发起请求的通道（聊天中发起时）
+	ChatID       string        `json:"chat_id,omitempty"`       // 发起请求的聊天 ID
 	DecisionNote string        `json:"decision_note,omitempty"` // 审批决策时的备注说明
 	Status       RequestStatus `json:"status"`                  // 当前审批状态
 	RequestedAt  time.Time     `json:"requested_at"`            // 请求发起时间
@@ -31,6 +34,9 @@ type CreateInput struct {
 	ToolName string        // 工具名称
 	ArgsJSON string        // 参数 JSON
 	Reason   string        // 申请原因
+	Preview  string        // 操作预览（可选）
+	Channel  string        // 来源通道（可选）
+	ChatID   string        // 来源聊天 ID（可选）
 	TTL      time.Duration // 有效期时长
 }
 
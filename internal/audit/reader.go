@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/logrotate"
+)
+
+// Query 定义读取审计日志时的过滤条件，零值字段表示不过滤。
+type Query struct {
+	Since time.Time // 仅返回该时间及之后的事件
+	Type  string    // 按事件类型过滤（不区分大小写）
+	Tool  string    // 按工具名过滤（不区分大小写）
+}
+
+// LogPath 返回工作区审计日志文件路径。
+func LogPath(workspace string) string {
+	return filepath.Join(workspace, "state", "audit.jsonl")
+}
+
+// Read 读取工作区审计日志（含轮转出的历史分段）并按查询条件过滤，事件按写入顺序返回。
+// 日志文件不存在时返回空结果；无法解析的行（如写入中断留下的残缺尾行）会被跳过。
+func Read(workspace string, query Query) ([]Event, error) {
+	path := LogPath(workspace)
+	backups, err := logrotate.Backups(path)
+	if err != nil {
+		return nil, fmt.Errorf("list audit segments: %w", err)
+	}
+	segments := make([]string, 0, len(backups)+1)
+	for i := len(backups) - 1; i >= 0; i-- {
+		segments = append(segments, backups[i].Path)
+	}
+	segments = append(segments, path)
+
+	var events []Event
+	for _, segment := range segments {
+		data, err := readSegment(segment)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read audit file: %w", err)
+		}
+		segmentEvents, err := parseEvents(data, query)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, segmentEvents...)
+	}
+	return events, nil
+}
+
+// readSegment 读取一个审计日志分段，.gz 分段会被解压；压缩分段残缺时返回已解压的部分。
+func readSegment(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, ".gz") {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil
+	}
+	defer zr.Close()
+	data, _ := io.ReadAll(zr)
+	return data, nil
+}
+
+func parseEvents(data []byte, query Query) ([]Event, error) {
+	eventType := strings.TrimSpace(query.Type)
+	toolName := strings.TrimSpace(query.Tool)
+
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+		if !query.Since.IsZero() && event.Time.Before(query.Since) {
+			continue
+		}
+		if eventType != "" && !strings.EqualFold(event.Type, eventType) {
+			continue
+		}
+		if toolName != "" && !strings.EqualFold(event.Tool, toolName) {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan audit file: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/logrotate"
+)
+
+func TestRead_FiltersEventsAndSkipsPartialLines(t *testing.T) {
+	workspace := t.TempDir()
+	writer := NewWriter(workspace)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []Event{
+		{Time: base, Type: "policy_allow", Tool: "exec", Result: "mode=strict"},
+		{Time: base.Add(time.Hour), Type: "tool_execution", Tool: "exec", Result: "success"},
+		{Time: base.Add(2 * time.Hour), Type: "policy_deny", Tool: "write_file", Result: "blocked by policy"},
+	}
+	for _, event := range events {
+		if err := writer.Append(event); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	// 模拟写入中断留下的残缺尾行
+	f, err := os.OpenFile(LogPath(workspace), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("open audit file: %v", err)
+	}
+	_, _ = f.WriteString(`{"time":"2026-03-01T15:00:00Z","type":"tool_exe`)
+	_ = f.Close()
+
+	all, err := Read(workspace, Query{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("expected 3 complete events, got %d", len(all))
+	}
+
+	got, err := Read(workspace, Query{Since: base.Add(30 * time.Minute), Tool: "EXEC"})
+	if err != nil {
+		t.Fatalf("Read filtered: %v", err)
+	}
+	if len(got) != 1 || got[0].Type != "tool_execution" {
+		t.Fatalf("expected only the later exec event, got %+v", got)
+	}
+
+	got, err = Read(workspace, Query{Type: "policy_deny"})
+	if err != nil {
+		t.Fatalf("Read by type: %v", err)
+	}
+	if len(got) != 1 || got[0].Tool != "write_file" {
+		t.Fatalf("expected the policy_deny event, got %+v", got)
+	}
+}
+
+func TestRead_MissingFileReturnsNoEvents(t *testing.T) {
+	events, err := Read(t.TempDir(), Query{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events, got %+v", events)
+	}
+}
+
+func TestWriterWithOptions_RotatesAndReadIncludesSegments(t *testing.T) {
+	workspace := t.TempDir()
+	writer := NewWriterWithOptions(workspace, logrotate.Options{MaxSize: 200, MaxBackups: 10, Compress: true})
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		event := Event{Time: base.Add(time.Duration(i) * time.Minute), Type: "tool_execution", Tool: "exec", Result: "success"}
+		if err := writer.Append(event); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	backups, err := logrotate.Backups(LogPath(workspace))
+	if err != nil {
+		t.Fatalf("Backups: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Fatal("expected audit log to be rotated")
+	}
+
+	events, err := Read(workspace, Query{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("expected all 10 events across segments, got %d", len(events))
+	}
+	for i, event := range events {
+		if !event.Time.Equal(base.Add(time.Duration(i) * time.Minute)) {
+			t.Fatalf("expected events in write order, event %d has time %v", i, event.Time)
+		}
+	}
+}
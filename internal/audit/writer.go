@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/MEKXH/golem/internal/logrotate"
 )
 
 const (
@@ -26,18 +28,31 @@ type Event struct {
 
 // Writer 负责将审计事件异步追加到工作区内的 <workspace>/state/audit.jsonl 文件中。
 type Writer struct {
-	path string     // 审计文件路径
-	mu   sync.Mutex // 确保并发写入的线程安全
+	path     string            // 审计文件路径
+	mu       *sync.Mutex       // 按文件路径共享的锁，确保并发写入与轮转的线程安全
+	rotation logrotate.Options // 轮转与保留策略（零值表示不轮转）
 }
 
-// NewWriter 在工作区的 state 目录下创建一个新的审计日志写入器。
+// pathLocks 为同一审计文件的所有 Writer 提供同一把锁，配置重载后新旧 Writer 也不会交错轮转。
+var pathLocks sync.Map
+
+// NewWriter 在工作区的 state 目录下创建一个新的审计日志写入器（不轮转）。
 func NewWriter(workspace string) *Writer {
+	return NewWriterWithOptions(workspace, logrotate.Options{})
+}
+
+// NewWriterWithOptions 创建按给定策略轮转的审计日志写入器。
+func NewWriterWithOptions(workspace string, rotation logrotate.Options) *Writer {
+	path := LogPath(workspace)
+	mu, _ := pathLocks.LoadOrStore(filepath.Clean(path), &sync.Mutex{})
 	return &Writer{
-		path: filepath.Join(workspace, "state", "audit.jsonl"),
+		path:     path,
+		mu:       mu.(*sync.Mutex),
+		rotation: rotation,
 	}
 }
 
-// Append 将一个审计事件作为一行 JSON 数据追加到文件中。
+// Append 将一个审计事件作为一行 JSON 数据追加到文件中；文件将超出大小上限时先轮转。
 func (w *Writer) Append(event Event) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -46,18 +61,26 @@ func (w *Writer) Append(event Event) error {
 		return fmt.Errorf("create audit dir: %w", err)
 	}
 
-	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, auditFileMode)
-	if err != nil {
-		return fmt.Errorf("open audit file: %w", err)
-	}
-	defer file.Close()
-
 	encoded, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("marshal audit event: %w", err)
 	}
 	encoded = append(encoded, '\n')
 
+	if w.rotation.MaxSize > 0 {
+		if info, err := os.Stat(w.path); err == nil && info.Size() > 0 && info.Size()+int64(len(encoded)) > w.rotation.MaxSize {
+			if err := logrotate.Rotate(w.path, w.rotation); err != nil {
+				return fmt.Errorf("rotate audit file: %w", err)
+			}
+		}
+	}
+
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, auditFileMode)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	defer file.Close()
+
 	if _, err := file.Write(encoded); err != nil {
 		return fmt.Errorf("append audit event: %w", err)
 	}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	// KeyEnv 是凭据存储加密口令所在的环境变量；值为 KeychainKey 时改从系统钥匙串读取。
+	KeyEnv = "GOLEM_AUTH_KEY"
+	// KeychainKey 表示从系统钥匙串（service=golem, account=auth-key）读取加密口令。
+	KeychainKey = "keychain"
+
+	keychainService = "golem"
+	keychainAccount = "auth-key"
+
+	storeCipher     = "aes-256-gcm"
+	storeKDF        = "pbkdf2-sha256"
+	storeIterations = 210000
+	storeSaltSize   = 16
+)
+
+// ErrKeyRequired 表示凭据存储已加密但未配置解密口令。
+var ErrKeyRequired = errors.New("auth store is encrypted; set " + KeyEnv + " to decrypt it")
+
+// keychainLookup 从系统钥匙串读取口令，测试中可替换。
+var keychainLookup = lookupKeychain
+
+// derivedKeys 缓存 PBKDF2 派生结果，避免每次读取凭据都重复计算。
+var derivedKeys sync.Map
+
+// encryptedStore 是加密后凭据存储的磁盘格式。
+type encryptedStore struct {
+	Version    int    `json:"version"`
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// storePassphrase 返回加密口令；未配置时返回空字符串。
+func storePassphrase() (string, error) {
+	raw := os.Getenv(KeyEnv)
+	if strings.TrimSpace(raw) != KeychainKey {
+		return raw, nil
+	}
+	secret, err := keychainLookup(keychainService, keychainAccount)
+	if err != nil {
+		return "", fmt.Errorf("read %s from OS keychain: %w", KeyEnv, err)
+	}
+	if secret == "" {
+		return "", fmt.Errorf("OS keychain entry %s/%s is empty", keychainService, keychainAccount)
+	}
+	return secret, nil
+}
+
+func encryptStore(plaintext []byte, passphrase string) (*encryptedStore, error) {
+	salt := make([]byte, storeSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := storeAEAD(passphrase, salt, storeIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &encryptedStore{
+		Version:    1,
+		Cipher:     storeCipher,
+		KDF:        storeKDF,
+		Iterations: storeIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, []byte(storeCipher)),
+	}, nil
+}
+
+func decryptStore(enc *encryptedStore, passphrase string) ([]byte, error) {
+	if enc.Cipher != storeCipher || enc.KDF != storeKDF || enc.Iterations <= 0 {
+		return nil, fmt.Errorf("unsupported auth store encryption %s/%s", enc.Cipher, enc.KDF)
+	}
+	gcm, err := storeAEAD(passphrase, enc.Salt, enc.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(enc.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid auth store nonce")
+	}
+	plaintext, err := gcm.Open(nil, enc.Nonce, enc.Ciphertext, []byte(storeCipher))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt auth store: wrong %s or corrupted file", KeyEnv)
+	}
+	return plaintext, nil
+}
+
+func storeAEAD(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	cacheKey := fmt.Sprintf("%x:%d:%x", salt, iterations, sha256.Sum256([]byte(passphrase)))
+	key, ok := derivedKeys.Load(cacheKey)
+	if !ok {
+		derived, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+		if err != nil {
+			return nil, err
+		}
+		key, _ = derivedKeys.LoadOrStore(cacheKey, derived)
+	}
+	block, err := aes.NewCipher(key.([]byte))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// lookupKeychain 通过系统自带命令读取钥匙串条目：macOS 使用 security，Linux 使用 secret-tool。
+func lookupKeychain(service, account string) (string, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", fmt.Errorf("OS keychain is not supported on %s", runtime.GOOS)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
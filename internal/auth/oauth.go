@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
@@ -18,22 +19,112 @@ import (
 	"time"
 )
 
+// AnthropicClientIDEnv 是 Claude OAuth 客户端 ID 所在的环境变量。
+const AnthropicClientIDEnv = "GOLEM_CLAUDE_OAUTH_CLIENT_ID"
+
 // OAuthProviderConfig 定义了单个 OAuth 供应商的元数据。
 type OAuthProviderConfig struct {
-	Issuer   string // 供应商的发行者 URL
-	ClientID string // OAuth 客户端 ID
-	Scopes   string // 请求的权限范围
-	Port     int    // 本地回调服务器监听的端口
+	Provider         string      // 凭据所属的供应商名称
+	Issuer           string      // 供应商的发行者 URL
+	ClientID         string      // OAuth 客户端 ID
+	Scopes           string      // 请求的权限范围
+	Port             int         // 本地回调服务器优先监听的端口
+	FixedPort        bool        // 只使用 Port，不在端口被占用时改用临时端口（用于预注册回调地址的供应商）
+	AuthorizeURL     string      // 授权端点，为空时使用 Issuer + "/authorize"
+	TokenURL         string      // 令牌端点，为空时使用 Issuer + "/oauth/token"
+	CallbackPath     string      // 本地回调路径，为空时使用 /auth/callback
+	JSONTokenRequest bool        // 令牌端点要求 JSON 请求体（默认使用表单）
+	RefreshScope     string      // 刷新令牌时附带的 scope，为空时不发送
+	DeviceCode       bool        // 是否支持设备码登录
+	TokenFields      TokenFields // 令牌响应的字段映射
+}
+
+// TokenFields 定义令牌响应中各字段的名称，零值字段使用 OAuth 2.0 标准字段名。
+type TokenFields struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    string
+}
+
+func (f TokenFields) withDefaults() TokenFields {
+	if f.AccessToken == "" {
+		f.AccessToken = "access_token"
+	}
+	if f.RefreshToken == "" {
+		f.RefreshToken = "refresh_token"
+	}
+	if f.ExpiresIn == "" {
+		f.ExpiresIn = "expires_in"
+	}
+	return f
 }
 
 // OpenAIOAuthConfig 返回 OpenAI OAuth 的默认配置。
 func OpenAIOAuthConfig() OAuthProviderConfig {
 	return OAuthProviderConfig{
-		Issuer:   "https://auth.openai.com",
-		ClientID: "app_EMoamEEZ73f0CkXaXp7hrann",
-		Scopes:   "openid profile email offline_access",
-		Port:     1455,
+		Provider:     "openai",
+		Issuer:       "https://auth.openai.com",
+		ClientID:     "app_EMoamEEZ73f0CkXaXp7hrann",
+		Scopes:       "openid profile email offline_access",
+		Port:         1455,
+		RefreshScope: "openid profile email",
+		DeviceCode:   true,
+	}
+}
+
+// AnthropicOAuthConfig 返回 Claude（Anthropic）OAuth 的配置。Golem 没有内置客户端 ID，
+// 需通过 GOLEM_CLAUDE_OAUTH_CLIENT_ID 提供已注册的客户端；令牌端点使用 JSON 请求体，且不支持设备码登录。
+func AnthropicOAuthConfig() OAuthProviderConfig {
+	return OAuthProviderConfig{
+		Provider:         "claude",
+		Issuer:           "https://console.anthropic.com",
+		ClientID:         strings.TrimSpace(os.Getenv(AnthropicClientIDEnv)),
+		Scopes:           "org:create_api_key user:profile user:inference",
+		Port:             54545,
+		AuthorizeURL:     "https://claude.ai/oauth/authorize",
+		TokenURL:         "https://console.anthropic.com/v1/oauth/token",
+		CallbackPath:     "/callback",
+		JSONTokenRequest: true,
+	}
+}
+
+func (cfg OAuthProviderConfig) authorizeURL() string {
+	if cfg.AuthorizeURL != "" {
+		return cfg.AuthorizeURL
+	}
+	return cfg.Issuer + "/authorize"
+}
+
+func (cfg OAuthProviderConfig) tokenURL() string {
+	if cfg.TokenURL != "" {
+		return cfg.TokenURL
 	}
+	return cfg.Issuer + "/oauth/token"
+}
+
+func (cfg OAuthProviderConfig) callbackPath() string {
+	if cfg.CallbackPath != "" {
+		return cfg.CallbackPath
+	}
+	return "/auth/callback"
+}
+
+func (cfg OAuthProviderConfig) providerName() string {
+	if cfg.Provider != "" {
+		return cfg.Provider
+	}
+	return "openai"
+}
+
+// validate 检查发起 OAuth 请求所需的最少配置。
+func (cfg OAuthProviderConfig) validate() error {
+	if strings.TrimSpace(cfg.ClientID) == "" {
+		if cfg.providerName() == "claude" {
+			return fmt.Errorf("oauth client id for claude is not configured; set %s", AnthropicClientIDEnv)
+		}
+		return fmt.Errorf("oauth client id for %s is not configured", cfg.providerName())
+	}
+	return nil
 }
 
 func generateState() (string, error) {
@@ -51,6 +142,9 @@ type callbackResult struct {
 
 // LoginBrowser 通过启动本地临时 HTTP 服务器并在浏览器中打开授权页面来完成 OAuth 登录。
 func LoginBrowser(cfg OAuthProviderConfig) (*Credential, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	pkce, err := GeneratePKCE()
 	if err != nil {
 		return nil, fmt.Errorf("generate pkce: %w", err)
@@ -60,12 +154,17 @@ func LoginBrowser(cfg OAuthProviderConfig) (*Credential, error) {
 		return nil, fmt.Errorf("generate state: %w", err)
 	}
 
-	redirectURI := fmt.Sprintf("http://localhost:%d/auth/callback", cfg.Port)
+	listener, err := listenCallback(cfg)
+	if err != nil {
+		return nil, err
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	redirectURI := fmt.Sprintf("http://localhost:%d%s", port, cfg.callbackPath())
 	authURL := BuildAuthorizeURL(cfg, pkce, state, redirectURI)
 	resultCh := make(chan callbackResult, 1)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/auth/callback", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(cfg.callbackPath(), func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Query().Get("state") != state {
 			resultCh <- callbackResult{err: fmt.Errorf("state mismatch")}
 			http.Error(w, "state mismatch", http.StatusBadRequest)
@@ -82,11 +181,6 @@ func LoginBrowser(cfg OAuthProviderConfig) (*Credential, error) {
 		resultCh <- callbackResult{code: code}
 	})
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", cfg.Port))
-	if err != nil {
-		return nil, fmt.Errorf("listen callback port %d: %w", cfg.Port, err)
-	}
-
 	server := &http.Server{Handler: mux}
 	go func() { _ = server.Serve(listener) }()
 	defer func() {
@@ -111,6 +205,24 @@ func LoginBrowser(cfg OAuthProviderConfig) (*Credential, error) {
 	}
 }
 
+// listenCallback 监听本地回调端口：优先使用 cfg.Port，被占用时改用系统分配的临时端口；
+// FixedPort 为 true 时不回退。
+func listenCallback(cfg OAuthProviderConfig) (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", cfg.Port))
+	if err == nil {
+		return listener, nil
+	}
+	if cfg.FixedPort || cfg.Port == 0 {
+		return nil, fmt.Errorf("listen callback port %d: %w", cfg.Port, err)
+	}
+	fallback, fallbackErr := net.Listen("tcp", "127.0.0.1:0")
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("listen callback port %d: %w (fallback: %v)", cfg.Port, err, fallbackErr)
+	}
+	fmt.Printf("Callback port %d is busy; using port %d instead.\n", cfg.Port, fallback.Addr().(*net.TCPAddr).Port)
+	return fallback, nil
+}
+
 type deviceCodeResponse struct {
 	DeviceAuthID string
 	UserCode     string
@@ -166,6 +278,12 @@ func parseFlexibleInt(raw json.RawMessage) (int, error) {
 
 // LoginDeviceCode completes OAuth login via device-code flow.
 func LoginDeviceCode(cfg OAuthProviderConfig) (*Credential, error) {
+	if !cfg.DeviceCode {
+		return nil, fmt.Errorf("device-code login is not supported for %s; use --browser", cfg.providerName())
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
 	reqBody, _ := json.Marshal(map[string]string{
 		"client_id": cfg.ClientID,
 	})
@@ -252,28 +370,32 @@ func RefreshAccessToken(cred *Credential, cfg OAuthProviderConfig) (*Credential,
 		return nil, fmt.Errorf("no refresh token available")
 	}
 
-	data := url.Values{
-		"client_id":     {cfg.ClientID},
-		"grant_type":    {"refresh_token"},
-		"refresh_token": {cred.RefreshToken},
-		"scope":         {"openid profile email"},
+	if err := cfg.validate(); err != nil {
+		return nil, err
 	}
 
-	resp, err := http.PostForm(cfg.Issuer+"/oauth/token", data)
-	if err != nil {
-		return nil, fmt.Errorf("refreshing token: %w", err)
+	params := map[string]string{
+		"client_id":     cfg.ClientID,
+		"grant_type":    "refresh_token",
+		"refresh_token": cred.RefreshToken,
+	}
+	if cfg.RefreshScope != "" {
+		params["scope"] = cfg.RefreshScope
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token refresh failed: %s", string(body))
+	body, err := postTokenRequest(cfg, params)
+	if err != nil {
+		return nil, fmt.Errorf("token refresh failed: %w", err)
 	}
 
-	refreshed, err := parseTokenResponse(body, cred.Provider)
+	refreshed, err := parseTokenResponse(body, cred.Provider, cfg.TokenFields)
 	if err != nil {
 		return nil, err
 	}
+	// 部分供应商刷新时不轮换刷新令牌，此时沿用原令牌
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = cred.RefreshToken
+	}
 	if refreshed.Provider == "" {
 		refreshed.Provider = cred.Provider
 	}
@@ -291,53 +413,80 @@ func BuildAuthorizeURL(cfg OAuthProviderConfig, pkce PKCECodes, state, redirectU
 		"code_challenge_method": {"S256"},
 		"state":                 {state},
 	}
-	return cfg.Issuer + "/authorize?" + params.Encode()
+	return cfg.authorizeURL() + "?" + params.Encode()
 }
 
 func exchangeCodeForTokens(cfg OAuthProviderConfig, code, codeVerifier, redirectURI string) (*Credential, error) {
-	data := url.Values{
-		"grant_type":    {"authorization_code"},
-		"code":          {code},
-		"redirect_uri":  {redirectURI},
-		"client_id":     {cfg.ClientID},
-		"code_verifier": {codeVerifier},
+	body, err := postTokenRequest(cfg, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  redirectURI,
+		"client_id":     cfg.ClientID,
+		"code_verifier": codeVerifier,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
 	}
+	return parseTokenResponse(body, cfg.providerName(), cfg.TokenFields)
+}
 
-	resp, err := http.PostForm(cfg.Issuer+"/oauth/token", data)
+// postTokenRequest 向令牌端点提交请求，按供应商要求使用表单或 JSON 请求体；非 200 响应返回错误。
+func postTokenRequest(cfg OAuthProviderConfig, params map[string]string) ([]byte, error) {
+	var resp *http.Response
+	var err error
+	if cfg.JSONTokenRequest {
+		payload, _ := json.Marshal(params)
+		resp, err = http.Post(cfg.tokenURL(), "application/json", strings.NewReader(string(payload)))
+	} else {
+		data := url.Values{}
+		for k, v := range params {
+			data.Set(k, v)
+		}
+		resp, err = http.PostForm(cfg.tokenURL(), data)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("exchange code for token: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token exchange failed: %s", string(body))
+		return nil, fmt.Errorf("%s", string(body))
 	}
-
-	return parseTokenResponse(body, "openai")
+	return body, nil
 }
 
-func parseTokenResponse(body []byte, provider string) (*Credential, error) {
-	var tokenResp struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		ExpiresIn    int    `json:"expires_in"`
-	}
-	if err := json.Unmarshal(body, &tokenResp); err != nil {
+// parseTokenResponse 按字段映射解析令牌响应；expires_in 兼容数字与字符串。
+func parseTokenResponse(body []byte, provider string, fields TokenFields) (*Credential, error) {
+	fields = fields.withDefaults()
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return nil, fmt.Errorf("parse token response: %w", err)
 	}
-	if strings.TrimSpace(tokenResp.AccessToken) == "" {
+
+	var accessToken, refreshToken string
+	if v, ok := raw[fields.AccessToken]; ok {
+		_ = json.Unmarshal(v, &accessToken)
+	}
+	if v, ok := raw[fields.RefreshToken]; ok {
+		_ = json.Unmarshal(v, &refreshToken)
+	}
+	if strings.TrimSpace(accessToken) == "" {
 		return nil, fmt.Errorf("no access token in response")
 	}
+	expiresIn, err := parseFlexibleInt(raw[fields.ExpiresIn])
+	if err != nil {
+		return nil, fmt.Errorf("parse token response: %s: %w", fields.ExpiresIn, err)
+	}
 
 	var expiresAt time.Time
-	if tokenResp.ExpiresIn > 0 {
-		expiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	if expiresIn > 0 {
+		expiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
 	}
 
 	return &Credential{
-		AccessToken:  tokenResp.AccessToken,
-		RefreshToken: tokenResp.RefreshToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 		Provider:     provider,
 		AuthMethod:   "oauth",
 		ExpiresAt:    expiresAt,
@@ -1,6 +1,14 @@
 package auth
 
-import "testing"
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestOpenAIOAuthConfig(t *testing.T) {
 	cfg := OpenAIOAuthConfig()
@@ -9,6 +17,25 @@ func TestOpenAIOAuthConfig(t *testing.T) {
 	}
 }
 
+func TestAnthropicOAuthConfig(t *testing.T) {
+	t.Setenv(AnthropicClientIDEnv, "")
+	cfg := AnthropicOAuthConfig()
+	if cfg.Provider != "claude" || cfg.TokenURL == "" || cfg.AuthorizeURL == "" {
+		t.Fatalf("invalid AnthropicOAuthConfig: %+v", cfg)
+	}
+	if _, err := LoginBrowser(cfg); err == nil || !strings.Contains(err.Error(), AnthropicClientIDEnv) {
+		t.Fatalf("expected missing client id error, got %v", err)
+	}
+	if _, err := LoginDeviceCode(cfg); err == nil || !strings.Contains(err.Error(), "--browser") {
+		t.Fatalf("expected device-code unsupported error, got %v", err)
+	}
+
+	t.Setenv(AnthropicClientIDEnv, "client-claude")
+	if got := AnthropicOAuthConfig().ClientID; got != "client-claude" {
+		t.Fatalf("expected client id from env, got %q", got)
+	}
+}
+
 func TestBuildAuthorizeURL(t *testing.T) {
 	cfg := OAuthProviderConfig{
 		Issuer:   "https://auth.example.com",
@@ -22,7 +49,87 @@ func TestBuildAuthorizeURL(t *testing.T) {
 	}
 
 	url := BuildAuthorizeURL(cfg, pkce, "state-1", "http://localhost:1455/auth/callback")
-	if url == "" {
-		t.Fatal("expected authorize url")
+	if !strings.HasPrefix(url, "https://auth.example.com/authorize?") {
+		t.Fatalf("expected issuer authorize endpoint, got %q", url)
+	}
+
+	cfg.AuthorizeURL = "https://login.example.com/oauth/authorize"
+	url = BuildAuthorizeURL(cfg, pkce, "state-1", "http://localhost:1455/callback")
+	if !strings.HasPrefix(url, "https://login.example.com/oauth/authorize?") {
+		t.Fatalf("expected custom authorize endpoint, got %q", url)
+	}
+}
+
+func TestParseTokenResponse_FieldMapping(t *testing.T) {
+	body := []byte(`{"token":"at-1","renew":"rt-1","ttl":"3600"}`)
+	cred, err := parseTokenResponse(body, "claude", TokenFields{AccessToken: "token", RefreshToken: "renew", ExpiresIn: "ttl"})
+	if err != nil {
+		t.Fatalf("parseTokenResponse: %v", err)
+	}
+	if cred.AccessToken != "at-1" || cred.RefreshToken != "rt-1" || cred.Provider != "claude" {
+		t.Fatalf("unexpected credential: %+v", cred)
+	}
+	if remaining := time.Until(cred.ExpiresAt); remaining < 59*time.Minute || remaining > time.Hour {
+		t.Fatalf("unexpected expiry: %v", cred.ExpiresAt)
+	}
+
+	if _, err := parseTokenResponse([]byte(`{"access_token":"x"}`), "claude", TokenFields{AccessToken: "token"}); err == nil {
+		t.Fatal("expected missing mapped access token to fail")
+	}
+}
+
+func TestRefreshAccessToken_JSONTokenRequest(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("expected JSON token request, got %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"access_token":"new-token","expires_in":600}`))
+	}))
+	defer srv.Close()
+
+	cfg := OAuthProviderConfig{
+		Provider:         "claude",
+		ClientID:         "client-claude",
+		TokenURL:         srv.URL + "/v1/oauth/token",
+		JSONTokenRequest: true,
+	}
+	cred, err := RefreshAccessToken(&Credential{Provider: "claude", AuthMethod: "oauth", RefreshToken: "old-refresh"}, cfg)
+	if err != nil {
+		t.Fatalf("RefreshAccessToken: %v", err)
+	}
+	if got["grant_type"] != "refresh_token" || got["refresh_token"] != "old-refresh" || got["client_id"] != "client-claude" {
+		t.Fatalf("unexpected token request: %+v", got)
+	}
+	if _, ok := got["scope"]; ok {
+		t.Fatalf("expected no scope without RefreshScope, got %+v", got)
+	}
+	if cred.AccessToken != "new-token" || cred.RefreshToken != "old-refresh" {
+		t.Fatalf("expected new access token and retained refresh token, got %+v", cred)
+	}
+}
+
+func TestListenCallback_FallsBackWhenPortBusy(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	listener, err := listenCallback(OAuthProviderConfig{Port: busyPort})
+	if err != nil {
+		t.Fatalf("expected fallback listener, got %v", err)
+	}
+	defer listener.Close()
+	if got := listener.Addr().(*net.TCPAddr).Port; got == busyPort || got == 0 {
+		t.Fatalf("expected a different bound port, got %d", got)
+	}
+
+	if _, err := listenCallback(OAuthProviderConfig{Port: busyPort, FixedPort: true}); err == nil || !strings.Contains(err.Error(), "listen callback port") {
+		t.Fatalf("expected fixed port to fail when busy, got %v", err)
 	}
 }
@@ -2,9 +2,12 @@ package auth
 
 import (
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -20,8 +23,18 @@ type Credential struct {
 // Store 是身份验证凭据的磁盘存储容器。
 type Store struct {
 	Credentials map[string]*Credential `json:"credentials"` // 供应商名称到凭据的映射
+	Encrypted   bool                   `json:"-"`           // 磁盘上的文件是否已加密
 }
 
+// storeFile 是凭据文件的磁盘格式：明文存储使用 credentials，加密存储使用 encrypted。
+type storeFile struct {
+	Credentials map[string]*Credential `json:"credentials,omitempty"`
+	Encrypted   *encryptedStore        `json:"encrypted,omitempty"`
+}
+
+// plaintextWarning 确保每个进程只提示一次明文存储。
+var plaintextWarning sync.Once
+
 // IsExpired 检查当前凭据是否已过期。
 func (c *Credential) IsExpired() bool {
 	if c == nil || c.ExpiresAt.IsZero() {
@@ -45,6 +58,7 @@ func FilePath() string {
 }
 
 // LoadStore 从磁盘加载身份验证存储。如果文件不存在，则返回一个空的存储实例。
+// 加密的存储使用 GOLEM_AUTH_KEY 透明解密；旧版明文存储仍可读取，但会提示尽快加密。
 func LoadStore() (*Store, error) {
 	path := FilePath()
 	data, err := os.ReadFile(path)
@@ -55,17 +69,40 @@ func LoadStore() (*Store, error) {
 		return nil, err
 	}
 
-	var store Store
-	if err := json.Unmarshal(data, &store); err != nil {
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
 		return nil, err
 	}
+
+	store := Store{Credentials: file.Credentials}
+	if file.Encrypted != nil {
+		passphrase, err := storePassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if passphrase == "" {
+			return nil, ErrKeyRequired
+		}
+		plaintext, err := decryptStore(file.Encrypted, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(plaintext, &store); err != nil {
+			return nil, fmt.Errorf("decode decrypted auth store: %w", err)
+		}
+		store.Encrypted = true
+	} else if len(store.Credentials) > 0 {
+		plaintextWarning.Do(func() {
+			slog.Warn("auth store holds credentials in plaintext; set "+KeyEnv+" to encrypt it on the next save", "path", path)
+		})
+	}
 	if store.Credentials == nil {
 		store.Credentials = map[string]*Credential{}
 	}
 	return &store, nil
 }
 
-// SaveStore 将身份验证存储持久化到磁盘。
+// SaveStore 将身份验证存储持久化到磁盘；配置了 GOLEM_AUTH_KEY 时以 AES-256-GCM 加密写入。
 func SaveStore(store *Store) error {
 	if store == nil {
 		store = &Store{Credentials: map[string]*Credential{}}
@@ -79,11 +116,32 @@ func SaveStore(store *Store) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(store, "", "  ")
+	passphrase, err := storePassphrase()
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0600)
+	file := storeFile{Credentials: store.Credentials}
+	if passphrase != "" {
+		plaintext, err := json.Marshal(storeFile{Credentials: store.Credentials})
+		if err != nil {
+			return err
+		}
+		enc, err := encryptStore(plaintext, passphrase)
+		if err != nil {
+			return fmt.Errorf("encrypt auth store: %w", err)
+		}
+		file = storeFile{Encrypted: enc}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	store.Encrypted = passphrase != ""
+	return nil
 }
 
 func normalizeProvider(provider string) string {
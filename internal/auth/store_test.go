@@ -1,6 +1,10 @@
 package auth
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -95,3 +99,103 @@ func TestDeleteAllCredentials(t *testing.T) {
 		t.Fatalf("expected empty credentials, got %d", len(store.Credentials))
 	}
 }
+
+func TestStore_EncryptedWithKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	t.Setenv(KeyEnv, "correct horse battery staple")
+
+	cred := &Credential{AccessToken: "tok-secret", RefreshToken: "refresh-secret", AuthMethod: "oauth"}
+	if err := SetCredential("openai", cred); err != nil {
+		t.Fatalf("SetCredential: %v", err)
+	}
+
+	raw, err := os.ReadFile(FilePath())
+	if err != nil {
+		t.Fatalf("read auth file: %v", err)
+	}
+	if strings.Contains(string(raw), "refresh-secret") || strings.Contains(string(raw), "tok-secret") {
+		t.Fatalf("expected tokens to be encrypted at rest, got: %s", raw)
+	}
+
+	got, err := GetCredential("openai")
+	if err != nil {
+		t.Fatalf("GetCredential: %v", err)
+	}
+	if got == nil || got.RefreshToken != "refresh-secret" {
+		t.Fatalf("expected decrypted credential, got %+v", got)
+	}
+	store, err := LoadStore()
+	if err != nil || !store.Encrypted {
+		t.Fatalf("expected encrypted store, got %+v err=%v", store, err)
+	}
+
+	t.Setenv(KeyEnv, "wrong")
+	if _, err := GetCredential("openai"); err == nil || !strings.Contains(err.Error(), "wrong") {
+		t.Fatalf("expected wrong key error, got %v", err)
+	}
+	t.Setenv(KeyEnv, "")
+	if _, err := GetCredential("openai"); !errors.Is(err, ErrKeyRequired) {
+		t.Fatalf("expected ErrKeyRequired, got %v", err)
+	}
+}
+
+func TestStore_PlaintextFallbackMigratesOnSave(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	legacy := `{"credentials":{"openai":{"access_token":"tok-legacy","provider":"openai","auth_method":"token"}}}`
+	if err := os.MkdirAll(filepath.Dir(FilePath()), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(FilePath(), []byte(legacy), 0600); err != nil {
+		t.Fatalf("write legacy store: %v", err)
+	}
+
+	t.Setenv(KeyEnv, "s3cret")
+	got, err := GetCredential("openai")
+	if err != nil || got == nil || got.AccessToken != "tok-legacy" {
+		t.Fatalf("expected plaintext credential to load, got %+v err=%v", got, err)
+	}
+
+	if err := SetCredential("claude", &Credential{AccessToken: "tok-claude", AuthMethod: "token"}); err != nil {
+		t.Fatalf("SetCredential: %v", err)
+	}
+	raw, err := os.ReadFile(FilePath())
+	if err != nil {
+		t.Fatalf("read auth file: %v", err)
+	}
+	if strings.Contains(string(raw), "tok-legacy") {
+		t.Fatalf("expected store to be encrypted after save, got: %s", raw)
+	}
+	if got, err := GetCredential("openai"); err != nil || got == nil || got.AccessToken != "tok-legacy" {
+		t.Fatalf("expected migrated credential, got %+v err=%v", got, err)
+	}
+}
+
+func TestStore_KeychainKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+	t.Setenv(KeyEnv, KeychainKey)
+
+	orig := keychainLookup
+	keychainLookup = func(service, account string) (string, error) {
+		if service != "golem" || account != "auth-key" {
+			t.Fatalf("unexpected keychain entry %s/%s", service, account)
+		}
+		return "from-keychain", nil
+	}
+	t.Cleanup(func() { keychainLookup = orig })
+
+	if err := SetCredential("openai", &Credential{AccessToken: "tok-kc"}); err != nil {
+		t.Fatalf("SetCredential: %v", err)
+	}
+	t.Setenv(KeyEnv, "from-keychain")
+	got, err := GetCredential("openai")
+	if err != nil || got == nil || got.AccessToken != "tok-kc" {
+		t.Fatalf("expected keychain passphrase to decrypt, got %+v err=%v", got, err)
+	}
+}
@@ -2,8 +2,13 @@
 package bus
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -12,6 +17,10 @@ import (
 
 type requestIDContextKey struct{}
 
+type streamObserverContextKey struct{}
+
+type unverifiedSenderContextKey struct{}
+
 // InboundMessage 表示从外部通道（如 Telegram、飞书等）接收到的入站消息。
 type InboundMessage struct {
 	Channel   string         // 消息来源通道（如 "telegram"）
@@ -33,6 +42,60 @@ func (m *InboundMessage) SessionKey() string {
 	return m.Channel + ":" + m.ChatID
 }
 
+// ThreadID 返回消息所在线程的平台标识（见 MetadataThreadID），不在线程中时返回空字符串。
+func (m *InboundMessage) ThreadID() string {
+	return metadataThreadID(m.Metadata)
+}
+
+// platformMessageIDKeys 是各通道在元数据中记录平台消息 ID 所用的键。
+var platformMessageIDKeys = []string{"message_id", "message_ts", "post_id", "event_id", "message_sid"}
+
+// PlatformMessageID 返回通道记录在元数据中的平台消息 ID（Telegram message_id、Slack message_ts 等），
+// 没有时返回空字符串。该 ID 只保证在所属聊天内唯一。
+func (m *InboundMessage) PlatformMessageID() string {
+	for _, key := range platformMessageIDKeys {
+		v, ok := m.Metadata[key]
+		if !ok || v == nil {
+			continue
+		}
+		if id := strings.TrimSpace(fmt.Sprint(v)); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// MetadataEdit 是出站消息的元数据键：值为 true 时，支持编辑的通道会更新同一 RequestID
+// 先前发送的消息而不是发送新消息；通道不支持或编辑失败时按新消息发送。
+const MetadataEdit = "edit"
+
+// MetadataVoiceReply 是出站消息的元数据键：值为 true 时，开启了语音回复的通道会把回复合成为语音发送，
+// 合成失败时按文本发送。Agent 对转录自语音的入站消息（元数据 transcribed_audio=true）设置该键。
+const MetadataVoiceReply = "voice_reply"
+
+// MetadataThreadID 是入站与出站消息共用的元数据键，值为消息所在线程在平台上的标识：
+// Slack 为 thread_ts，Discord 为子区的频道 ID，飞书为话题根消息 ID。通道在入站消息中记录该键，
+// Agent 把它带到回复上（包括子代理等异步结果），通道的 Send 据此把回复发到原线程而不是频道根部。
+const MetadataThreadID = "thread_id"
+
+// MetadataCard 是出站消息的元数据键：值为 *Card 时，支持交互卡片的通道（如飞书）以卡片形式发送，
+// 其余通道忽略该键并按 Content 发送，因此 Content 仍应包含完整的文本内容。
+const MetadataCard = "card"
+
+// Card 是与平台无关的结构化卡片内容。
+type Card struct {
+	Title   string       // 卡片标题
+	Body    string       // Markdown 正文
+	Actions []CardAction // 卡片底部的按钮
+}
+
+// CardAction 是卡片上的按钮；点击后通道把 Value 作为该用户发送的消息回传给 Agent。
+type CardAction struct {
+	Label string // 按钮文字
+	Value string // 回传的消息文本，如 "approve 42"
+	Style string // primary、danger 或空（默认样式）
+}
+
 // OutboundMessage 表示发送给外部通道的出站消息。
 type OutboundMessage struct {
 	Channel   string         // 目标通道
@@ -42,6 +105,84 @@ type OutboundMessage struct {
 	Media     []string       // 待发送的媒体文件列表
 	Metadata  map[string]any // 随消息携带的元数据
 	RequestID string         // 关联的请求 ID
+
+	Attachments []OutboundAttachment // 随消息上传的文件
+}
+
+// OutboundAttachment 表示随出站消息上传的文件。Data 非空时直接使用内存数据，否则读取 Path 指向的本地文件。
+type OutboundAttachment struct {
+	Path     string // 本地文件路径
+	Data     []byte // 文件内容（优先于 Path）
+	FileName string // 上传时使用的文件名（为空时取 Path 的文件名）
+	MIMEType string // MIME 类型（可选）
+}
+
+// Name 返回附件的上传文件名。
+func (a OutboundAttachment) Name() string {
+	if name := strings.TrimSpace(a.FileName); name != "" {
+		return name
+	}
+	if strings.TrimSpace(a.Path) != "" {
+		return filepath.Base(a.Path)
+	}
+	return "attachment"
+}
+
+// Size 返回附件的字节数；基于路径的附件会读取文件元信息。
+func (a OutboundAttachment) Size() (int64, error) {
+	if a.Data != nil {
+		return int64(len(a.Data)), nil
+	}
+	if strings.TrimSpace(a.Path) == "" {
+		return 0, fmt.Errorf("attachment %q has neither data nor path", a.Name())
+	}
+	info, err := os.Stat(a.Path)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return 0, fmt.Errorf("attachment path %q is a directory", a.Path)
+	}
+	return info.Size(), nil
+}
+
+// Open 返回附件内容的读取器，调用方负责关闭。
+func (a OutboundAttachment) Open() (io.ReadCloser, error) {
+	if a.Data != nil {
+		return io.NopCloser(bytes.NewReader(a.Data)), nil
+	}
+	if strings.TrimSpace(a.Path) == "" {
+		return nil, fmt.Errorf("attachment %q has neither data nor path", a.Name())
+	}
+	return os.Open(a.Path)
+}
+
+// IsEdit 报告该出站消息是否请求编辑先前发送的消息。
+func (m *OutboundMessage) IsEdit() bool {
+	edit, _ := m.Metadata[MetadataEdit].(bool)
+	return edit
+}
+
+// Card 返回该出站消息请求的卡片内容，未请求时返回 nil。
+func (m *OutboundMessage) Card() *Card {
+	card, _ := m.Metadata[MetadataCard].(*Card)
+	return card
+}
+
+// ThreadID 返回该出站消息应发往的线程标识（见 MetadataThreadID），未指定时返回空字符串。
+func (m *OutboundMessage) ThreadID() string {
+	return metadataThreadID(m.Metadata)
+}
+
+func metadataThreadID(metadata map[string]any) string {
+	id, _ := metadata[MetadataThreadID].(string)
+	return strings.TrimSpace(id)
+}
+
+// WantsVoiceReply 报告该出站消息是否请求以语音形式发送。
+func (m *OutboundMessage) WantsVoiceReply() bool {
+	voice, _ := m.Metadata[MetadataVoiceReply].(bool)
+	return voice
 }
 
 // NewRequestID 生成一个新的 UUID 用于请求追踪。
@@ -67,6 +208,58 @@ func RequestIDFromContext(ctx context.Context) string {
 	return ""
 }
 
+// StreamObserver 接收单次请求处理过程中的流式事件（增量文本与工具执行），各回调均可为空。
+// 同一轮的多个工具并行执行，工具回调可能并发触发，实现方需自行保证并发安全。
+type StreamObserver struct {
+	OnContentDelta func(delta string)
+	OnToolStart    func(name, args string)
+	OnToolFinish   func(name, result string, err error)
+	OnToolOutput   func(output ToolOutput) // 结构化工具成功执行后调用，在 OnToolFinish 之后
+}
+
+// ToolOutput 是单轮对话中一次结构化工具调用的 JSON 结果，字段名作为对外接口保持稳定。
+type ToolOutput struct {
+	CallID string          `json:"call_id"`
+	Name   string          `json:"name"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// WithStreamObserver 将流式事件观察者注入到 context 中。
+func WithStreamObserver(ctx context.Context, observer *StreamObserver) context.Context {
+	if observer == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, streamObserverContextKey{}, observer)
+}
+
+// StreamObserverFromContext 从 context 中读取流式事件观察者，未设置时返回 nil。
+func StreamObserverFromContext(ctx context.Context) *StreamObserver {
+	observer, _ := ctx.Value(streamObserverContextKey{}).(*StreamObserver)
+	return observer
+}
+
+// WithUnverifiedSender 标记 context 中处理的消息，其通道与发送者由调用方自行声明（如网关 API 请求），
+// 未经聊天平台验证。此类发送者不会被视为 policy.admins 中的管理员，也不能在聊天中审批工具调用。
+func WithUnverifiedSender(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unverifiedSenderContextKey{}, true)
+}
+
+// SenderUnverified 报告 context 是否被 WithUnverifiedSender 标记。
+func SenderUnverified(ctx context.Context) bool {
+	v, _ := ctx.Value(unverifiedSenderContextKey{}).(bool)
+	return v
+}
+
+// MessageTooLongError 表示直接提交的消息超过 channels.inbound.max_message_chars，且 oversize_policy 为 reject。
+type MessageTooLongError struct {
+	Length int // 消息的字符数
+	Limit  int // 允许的最大字符数
+}
+
+func (e *MessageTooLongError) Error() string {
+	return fmt.Sprintf("message is too long (%d characters; the limit is %d)", e.Length, e.Limit)
+}
+
 const (
 	SystemChannel            = "system"          // 系统内部专用通道
 	SystemTypeSubagentResult = "subagent_result" // 系统消息类型：子 Agent 执行结果
@@ -77,6 +270,7 @@ const (
 	SystemMetaOriginChannel = "origin_channel"   // 元数据键：原始请求通道
 	SystemMetaOriginChatID  = "origin_chat_id"   // 元数据键：原始请求聊天 ID
 	SystemMetaOriginSender  = "origin_sender_id" // 元数据键：原始发送者 ID
+	SystemMetaOriginThread  = "origin_thread_id" // 元数据键：原始请求所在线程（见 MetadataThreadID）
 	SystemMetaStatus        = "status"           // 元数据键：执行状态
 )
 
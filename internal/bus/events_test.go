@@ -58,3 +58,56 @@ func TestRequestIDContext(t *testing.T) {
 		t.Fatalf("expected req-123, got %q", got)
 	}
 }
+
+func TestUnverifiedSenderContext(t *testing.T) {
+	if SenderUnverified(context.Background()) {
+		t.Fatal("expected senders to be verified by default")
+	}
+	if !SenderUnverified(WithUnverifiedSender(context.Background())) {
+		t.Fatal("expected marked context to report an unverified sender")
+	}
+}
+
+func TestInboundMessage_PlatformMessageID(t *testing.T) {
+	cases := []struct {
+		meta map[string]any
+		want string
+	}{
+		{map[string]any{"message_id": 42}, "42"},
+		{map[string]any{"message_ts": "1700000000.1", "channel_id": "C1"}, "1700000000.1"},
+		{map[string]any{"event_id": "$abc"}, "$abc"},
+		{map[string]any{"message_id": ""}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		msg := &InboundMessage{Metadata: c.meta}
+		if got := msg.PlatformMessageID(); got != c.want {
+			t.Fatalf("PlatformMessageID(%v) = %q, want %q", c.meta, got, c.want)
+		}
+	}
+}
+
+func TestOutboundMessage_Card(t *testing.T) {
+	if card := (&OutboundMessage{Content: "hi"}).Card(); card != nil {
+		t.Fatalf("expected no card without metadata, got %+v", card)
+	}
+	want := &Card{Title: "t", Actions: []CardAction{{Label: "OK", Value: "ok"}}}
+	msg := &OutboundMessage{Metadata: map[string]any{MetadataCard: want}}
+	if got := msg.Card(); got != want {
+		t.Fatalf("expected card from metadata, got %+v", got)
+	}
+}
+
+func TestThreadID(t *testing.T) {
+	in := &InboundMessage{Metadata: map[string]any{MetadataThreadID: " 1700000000.1 "}}
+	if got := in.ThreadID(); got != "1700000000.1" {
+		t.Fatalf("InboundMessage.ThreadID() = %q", got)
+	}
+	if got := (&OutboundMessage{}).ThreadID(); got != "" {
+		t.Fatalf("expected empty thread without metadata, got %q", got)
+	}
+	out := &OutboundMessage{Metadata: map[string]any{MetadataThreadID: "t1"}}
+	if got := out.ThreadID(); got != "t1" {
+		t.Fatalf("OutboundMessage.ThreadID() = %q", got)
+	}
+}
@@ -0,0 +1,183 @@
+package channel
+
+import "strings"
+
+// Sender 描述入站消息的发送者，供允许名单匹配使用。
+type Sender struct {
+	ID    string   // 发送者标识，支持 "id|username" 复合形式
+	Guild string   // 所属服务器或工作区 ID（Discord guild、Slack team），无则为空
+	Roles []string // 发送者拥有的角色（Discord）：角色 ID，以及 "服务器ID/角色名" 形式的名称；无则为空
+}
+
+type allowRuleKind int
+
+const (
+	ruleExact allowRuleKind = iota
+	ruleAny
+	rulePrefix
+	ruleSuffix
+	ruleContains
+	ruleGuild
+	ruleRole
+)
+
+type allowRule struct {
+	kind  allowRuleKind
+	value string
+}
+
+// AllowMatcher 是由 allow_from 条目编译得到的匹配器。支持的条目：
+//
+//	"alice" / "@alice" 精确匹配（默认）
+//	"*"                允许所有人
+//	"ali*"、"*@corp"、"*bot*" 前缀、后缀、包含通配
+//	"guild:123"        该服务器/工作区内的任何人
+//	"role:987"         拥有该角色 ID 的任何人
+//	"role:123/admins"  在服务器 123 中拥有名为 admins 的角色的任何人（名称不区分大小写）
+//	"!entry"           拒绝规则，可与以上任意形式组合
+//
+// 优先级：名单为空时不限制；拒绝规则先于允许规则判断，命中任一拒绝规则即拒绝；
+// 否则命中任一允许规则即允许（允许规则之间无先后之分）；名单只含拒绝规则时，其余发送者均允许。
+//
+// 角色名称由服务器成员自行创建，任何服务器里都可能有同名角色，因此按名称匹配时必须带上服务器 ID；
+// 不带服务器的 role: 条目只匹配全局唯一的角色 ID。
+type AllowMatcher struct {
+	allow []allowRule
+	deny  []allowRule
+}
+
+// NewAllowMatcher 编译 allow_from 条目，忽略空白条目。
+func NewAllowMatcher(entries []string) *AllowMatcher {
+	m := &AllowMatcher{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		deny := false
+		if strings.HasPrefix(entry, "!") {
+			deny = true
+			entry = strings.TrimSpace(entry[1:])
+		}
+		if entry == "" {
+			continue
+		}
+		rule := parseAllowRule(entry)
+		if deny {
+			m.deny = append(m.deny, rule)
+		} else {
+			m.allow = append(m.allow, rule)
+		}
+	}
+	return m
+}
+
+func parseAllowRule(entry string) allowRule {
+	lower := strings.ToLower(entry)
+	switch {
+	case entry == "*":
+		return allowRule{kind: ruleAny}
+	case strings.HasPrefix(lower, "guild:"):
+		return allowRule{kind: ruleGuild, value: strings.TrimSpace(entry[len("guild:"):])}
+	case strings.HasPrefix(lower, "role:"):
+		return allowRule{kind: ruleRole, value: strings.TrimSpace(entry[len("role:"):])}
+	}
+
+	entry = strings.TrimPrefix(entry, "@")
+	leading := strings.HasPrefix(entry, "*")
+	trailing := strings.HasSuffix(entry, "*")
+	core := strings.Trim(entry, "*")
+	switch {
+	case core == "":
+		return allowRule{kind: ruleAny}
+	case leading && trailing:
+		return allowRule{kind: ruleContains, value: core}
+	case leading:
+		return allowRule{kind: ruleSuffix, value: core}
+	case trailing:
+		return allowRule{kind: rulePrefix, value: core}
+	default:
+		return allowRule{kind: ruleExact, value: entry}
+	}
+}
+
+// Empty 报告名单是否没有任何规则（即不限制发送者）。
+func (m *AllowMatcher) Empty() bool {
+	return m == nil || (len(m.allow) == 0 && len(m.deny) == 0)
+}
+
+// Allows 按上述优先级判断发送者是否被允许。
+func (m *AllowMatcher) Allows(s Sender) bool {
+	if m.Empty() {
+		return true
+	}
+	candidates := senderCandidates(s.ID)
+	for _, rule := range m.deny {
+		if rule.matches(s, candidates) {
+			return false
+		}
+	}
+	if len(m.allow) == 0 {
+		return true
+	}
+	for _, rule := range m.allow {
+		if rule.matches(s, candidates) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r allowRule) matches(s Sender, candidates []string) bool {
+	switch r.kind {
+	case ruleAny:
+		return true
+	case ruleGuild:
+		return s.Guild != "" && s.Guild == r.value
+	case ruleRole:
+		guild, name, scoped := strings.Cut(r.value, "/")
+		for _, role := range s.Roles {
+			roleGuild, roleName, named := strings.Cut(role, "/")
+			if scoped && named && roleGuild == guild && strings.EqualFold(roleName, name) {
+				return true
+			}
+			if !scoped && !named && role == r.value {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range candidates {
+		var ok bool
+		switch r.kind {
+		case ruleExact:
+			ok = c == r.value
+		case rulePrefix:
+			ok = strings.HasPrefix(c, r.value)
+		case ruleSuffix:
+			ok = strings.HasSuffix(c, r.value)
+		case ruleContains:
+			ok = strings.Contains(c, r.value)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// senderCandidates 返回参与 ID 匹配的形式：完整 ID、"|" 两侧的 ID 与用户名，以及去掉 "@" 的变体。
+func senderCandidates(senderID string) []string {
+	parts := []string{senderID}
+	if idx := strings.Index(senderID, "|"); idx > 0 {
+		parts = append(parts, senderID[:idx])
+		if user := senderID[idx+1:]; user != "" {
+			parts = append(parts, user)
+		}
+	}
+	out := make([]string, 0, len(parts)*2)
+	for _, p := range parts {
+		out = append(out, p)
+		if trimmed := strings.TrimPrefix(p, "@"); trimmed != p {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
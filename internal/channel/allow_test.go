@@ -0,0 +1,39 @@
+package channel
+
+import "testing"
+
+func TestAllowMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		sender  Sender
+		want    bool
+	}{
+		{"empty allows all", nil, Sender{ID: "u1"}, true},
+		{"exact", []string{"u1"}, Sender{ID: "u1"}, true},
+		{"exact miss", []string{"u1"}, Sender{ID: "u10"}, false},
+		{"exact username in compound", []string{"@alice"}, Sender{ID: "42|alice"}, true},
+		{"star", []string{"*"}, Sender{ID: "anyone"}, true},
+		{"prefix", []string{"ali*"}, Sender{ID: "42|alice"}, true},
+		{"suffix", []string{"*:example.org"}, Sender{ID: "@bob:example.org"}, true},
+		{"suffix miss", []string{"*:example.org"}, Sender{ID: "@bob:evil.org"}, false},
+		{"contains", []string{"*admin*"}, Sender{ID: "team-admin-1"}, true},
+		{"guild", []string{"guild:123"}, Sender{ID: "u1", Guild: "123"}, true},
+		{"guild miss", []string{"guild:123"}, Sender{ID: "u1"}, false},
+		{"role by id", []string{"role:r9"}, Sender{ID: "u1", Roles: []string{"r9", "g1/admins"}}, true},
+		{"role by guild and name", []string{"role:g1/Admins"}, Sender{ID: "u1", Roles: []string{"r9", "g1/admins"}}, true},
+		{"role name needs guild", []string{"role:admins"}, Sender{ID: "u1", Roles: []string{"r9", "g1/admins"}}, false},
+		{"role name from another guild", []string{"role:g1/admins"}, Sender{ID: "u1", Roles: []string{"r7", "g2/admins"}}, false},
+		{"deny wins over allow", []string{"*", "!u2"}, Sender{ID: "u2"}, false},
+		{"deny wins over guild", []string{"guild:123", "!spam*"}, Sender{ID: "spammer", Guild: "123"}, false},
+		{"deny only allows others", []string{"!u2"}, Sender{ID: "u1"}, true},
+		{"deny only rejects listed", []string{"!u2"}, Sender{ID: "u2"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewAllowMatcher(tt.entries).Allows(tt.sender); got != tt.want {
+				t.Fatalf("Allows(%+v) with %v = %v, want %v", tt.sender, tt.entries, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package channel
+
+import (
+	"fmt"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// AttachmentTooLargeError 表示附件超过了平台的单文件上传上限。
+type AttachmentTooLargeError struct {
+	Channel string // 通道名称
+	Name    string // 附件文件名
+	Size    int64  // 附件字节数
+	Limit   int64  // 平台上限（字节）
+}
+
+func (e *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachment %q is %s, exceeds %s upload limit of %s",
+		e.Name, formatBytes(e.Size), e.Channel, formatBytes(e.Limit))
+}
+
+// CheckAttachments 在上传前校验所有附件均可读取且不超过 limit 字节，
+// 避免只发出部分附件后才失败。limit <= 0 表示不限制。
+func CheckAttachments(channelName string, attachments []bus.OutboundAttachment, limit int64) error {
+	for _, att := range attachments {
+		size, err := att.Size()
+		if err != nil {
+			return fmt.Errorf("attachment %q: %w", att.Name(), err)
+		}
+		if limit > 0 && size > limit {
+			return &AttachmentTooLargeError{Channel: channelName, Name: att.Name(), Size: size, Limit: limit}
+		}
+	}
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const mb = 1024 * 1024
+	if n >= mb {
+		return fmt.Sprintf("%.1f MB", float64(n)/mb)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -0,0 +1,39 @@
+package channel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+func TestCheckAttachments_RejectsOversizedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart.png")
+	if err := os.WriteFile(path, make([]byte, 64), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	atts := []bus.OutboundAttachment{{Data: []byte("ok"), FileName: "a.txt"}, {Path: path}}
+
+	if err := CheckAttachments("telegram", atts, 100); err != nil {
+		t.Fatalf("expected attachments within limit to pass, got %v", err)
+	}
+
+	err := CheckAttachments("telegram", atts, 32)
+	var tooLarge *AttachmentTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected AttachmentTooLargeError, got %v", err)
+	}
+	if tooLarge.Name != "chart.png" || tooLarge.Size != 64 || !strings.Contains(err.Error(), "telegram upload limit") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckAttachments_MissingFile(t *testing.T) {
+	err := CheckAttachments("slack", []bus.OutboundAttachment{{Path: filepath.Join(t.TempDir(), "missing.csv")}}, 0)
+	if err == nil || !strings.Contains(err.Error(), "missing.csv") {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+}
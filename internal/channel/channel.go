@@ -3,7 +3,6 @@ package channel
 
 import (
 	"context"
-	"strings"
 
 	"github.com/MEKXH/golem/internal/bus"
 )
@@ -17,36 +16,47 @@ type Channel interface {
 	IsAllowed(senderID string) bool                           // 检查指定的发送者是否有权限使用此通道
 }
 
+// TypingSender 是可选接口：支持“正在输入”提示的通道实现它，Manager 通过类型断言调用。
+type TypingSender interface {
+	SendTyping(ctx context.Context, chatID string) error
+}
+
+// Reactor 是可选接口：支持表情反应的通道实现它，Manager 通过类型断言调用。
+// React 把机器人在 messageID 消息上的反应设置为 emoji（Unicode 表情），替换此前由机器人添加的反应；
+// emoji 为空时移除机器人的反应。
+type Reactor interface {
+	React(ctx context.Context, chatID, messageID, emoji string) error
+}
+
+// AttachmentLimiter 是可选接口：支持发送附件的通道实现它，报告平台的单文件上传上限（字节），
+// 供工具在发送前检查文件大小。
+type AttachmentLimiter interface {
+	MaxAttachmentBytes() int64
+}
+
 // BaseChannel 提供跨不同通道共享的基础功能。
 type BaseChannel struct {
 	Bus       *bus.MessageBus // 关联的消息总线，用于转发入站消息
-	AllowList map[string]bool // 允许访问此通道的用户 ID 列表（为空则不限制）
+	AllowList map[string]bool // allow_from 条目集合（为空则不限制），支持通配与 guild:/role: 规则
 }
 
-// IsAllowed 检查发送者 ID 是否在允许名单中。
+// IsAllowed 检查发送者 ID 是否在允许名单中，匹配规则见 AllowMatcher。
 func (b *BaseChannel) IsAllowed(senderID string) bool {
+	return b.AllowsSender(Sender{ID: senderID})
+}
+
+// AllowsSender 与 IsAllowed 相同，但额外提供服务器与角色信息，用于匹配 guild:/role: 条目。
+func (b *BaseChannel) AllowsSender(s Sender) bool {
 	if len(b.AllowList) == 0 {
 		return true
 	}
-
-	idPart := senderID
-	userPart := ""
-	if idx := strings.Index(senderID, "|"); idx > 0 {
-		idPart = senderID[:idx]
-		userPart = senderID[idx+1:]
-	}
-
-	for allowed := range b.AllowList {
-		normalized := strings.TrimSpace(allowed)
-		trimmed := strings.TrimPrefix(normalized, "@")
-		if normalized == senderID || trimmed == senderID ||
-			normalized == idPart || trimmed == idPart ||
-			(userPart != "" && (normalized == userPart || trimmed == userPart)) {
-			return true
+	entries := make([]string, 0, len(b.AllowList))
+	for entry, ok := range b.AllowList {
+		if ok {
+			entries = append(entries, entry)
 		}
 	}
-
-	return false
+	return NewAllowMatcher(entries).Allows(s)
 }
 
 // PublishInbound 将接收到的原始平台消息发布到消息总线中。
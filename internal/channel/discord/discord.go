@@ -14,26 +14,68 @@ import (
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/channel"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/MEKXH/golem/internal/voice"
 	"github.com/bwmarrin/discordgo"
 )
 
 const (
 	defaultTranscriptionTimeout = 30 * time.Second
-	maxAudioBytes               = 25 * 1024 * 1024
+	maxMessageLength            = 2000             // Discord 单条消息的字符数上限
+	maxUploadBytes              = 10 * 1024 * 1024 // 未加成服务器的单文件上传上限 (10MB)
+	interactionTokenTTL         = 15 * time.Minute // 交互令牌的有效期，过期后无法再发送 follow-up
 )
 
+// slashCommands 是开启 slash_commands 时在 Start 中注册的应用命令。
+var slashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "ask",
+		Description: "Ask Golem a question",
+		Options: []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "prompt",
+			Description: "What you want to ask",
+			Required:    true,
+		}},
+	},
+	{Name: "status", Description: "Show Golem runtime status"},
+}
+
+// interactionAPI 是应答斜杠命令所需的 Discord 接口，由 *discordgo.Session 实现，测试中可替换。
+type interactionAPI interface {
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+	FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// reactionAPI 是设置表情反应所需的 Discord 接口，由 *discordgo.Session 实现，测试中可替换。
+type reactionAPI interface {
+	ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error
+	MessageReactionRemove(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error
+}
+
+// pendingInteraction 是已延迟确认、等待回复的斜杠命令。
+type pendingInteraction struct {
+	interaction *discordgo.Interaction
+	expires     time.Time
+}
+
 // Channel implements Discord bot channel.
 type Channel struct {
 	channel.BaseChannel
 	cfg                  *config.DiscordConfig
 	session              *discordgo.Session
 	transcriber          voice.Transcriber
+	synthesizer          voice.Synthesizer // 为 nil 时不发送语音回复
 	downloadAudio        func(ctx context.Context, url, fileName, mimeType string) (voice.Input, error)
 	httpClient           *http.Client
 	transcriptionTimeout time.Duration
+	interactions         interactionAPI
+	reactions            reactionAPI
+	now                  func() time.Time
 	mu                   sync.RWMutex
 	running              bool
+	pending              map[string]pendingInteraction // 按 request_id 索引的待回复斜杠命令
 }
 
 // New creates a Discord channel.
@@ -46,13 +88,20 @@ func New(cfg *config.DiscordConfig, msgBus *bus.MessageBus, transcriber voice.Tr
 		BaseChannel:          channel.BaseChannel{Bus: msgBus, AllowList: allowList},
 		cfg:                  cfg,
 		transcriber:          transcriber,
-		httpClient:           &http.Client{Timeout: 45 * time.Second},
+		httpClient:           httpclient.New(httpclient.Timeout(45 * time.Second)),
 		transcriptionTimeout: defaultTranscriptionTimeout,
+		now:                  time.Now,
+		pending:              make(map[string]pendingInteraction),
 	}
 	ch.downloadAudio = ch.downloadDiscordAudio
 	return ch
 }
 
+// SetSynthesizer 开启语音回复：请求语音回复的出站消息将合成为音频文件上传，须在 Start 之前调用。
+func (c *Channel) SetSynthesizer(synth voice.Synthesizer) {
+	c.synthesizer = synth
+}
+
 func (c *Channel) Name() string { return "discord" }
 
 func (c *Channel) Start(ctx context.Context) error {
@@ -68,6 +117,9 @@ func (c *Channel) Start(ctx context.Context) error {
 		return fmt.Errorf("create discord session: %w", err)
 	}
 	s.AddHandler(c.handleMessage)
+	if c.cfg.SlashCommands {
+		s.AddHandler(c.handleInteraction)
+	}
 
 	if err := s.Open(); err != nil {
 		return fmt.Errorf("open discord session: %w", err)
@@ -75,15 +127,34 @@ func (c *Channel) Start(ctx context.Context) error {
 
 	c.mu.Lock()
 	c.session = s
+	c.interactions = s
+	c.reactions = s
 	c.running = true
 	c.mu.Unlock()
 
 	if me, err := s.User("@me"); err == nil {
 		slog.Info("discord bot connected", "username", me.Username, "id", me.ID)
 	}
+	if c.cfg.SlashCommands {
+		c.registerCommands(s)
+	}
 	return nil
 }
 
+// registerCommands 以全局应用命令注册 /ask 与 /status，覆盖该应用此前注册的命令。
+// 注册失败只记录日志，普通消息仍可使用。
+func (c *Channel) registerCommands(s *discordgo.Session) {
+	if s.State == nil || s.State.User == nil {
+		slog.Warn("discord slash commands not registered: application id unknown")
+		return
+	}
+	if _, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", slashCommands); err != nil {
+		slog.Warn("discord slash command registration failed", "error", err)
+		return
+	}
+	slog.Info("discord slash commands registered", "count", len(slashCommands))
+}
+
 func (c *Channel) Stop(ctx context.Context) error {
 	c.mu.Lock()
 	s := c.session
@@ -96,6 +167,66 @@ func (c *Channel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// SendTyping 触发 Discord 的输入提示，持续约 10 秒或直到机器人发送消息。
+func (c *Channel) SendTyping(ctx context.Context, chatID string) error {
+	c.mu.RLock()
+	s := c.session
+	running := c.running
+	c.mu.RUnlock()
+	if !running || s == nil {
+		return fmt.Errorf("discord channel not running")
+	}
+	if strings.TrimSpace(chatID) == "" {
+		return fmt.Errorf("discord chat id is empty")
+	}
+	return s.ChannelTyping(targetChannel(chatID), discordgo.WithContext(ctx))
+}
+
+// React 把机器人在消息上的反应换成 emoji：先移除机器人已添加的其他反应，再添加新反应；emoji 为空时只移除。
+func (c *Channel) React(ctx context.Context, chatID, messageID, emoji string) error {
+	c.mu.RLock()
+	api := c.reactions
+	running := c.running
+	c.mu.RUnlock()
+	if !running || api == nil {
+		return fmt.Errorf("discord channel not running")
+	}
+	if strings.TrimSpace(chatID) == "" || strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("discord chat id or message id is empty")
+	}
+
+	channelID := targetChannel(chatID)
+	m, err := api.ChannelMessage(channelID, messageID, discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get discord message: %w", err)
+	}
+	present := false
+	for _, r := range m.Reactions {
+		if r == nil || r.Emoji == nil || !r.Me {
+			continue
+		}
+		if name := r.Emoji.APIName(); name != emoji {
+			if err := api.MessageReactionRemove(channelID, messageID, name, "@me", discordgo.WithContext(ctx)); err != nil {
+				return fmt.Errorf("remove discord reaction: %w", err)
+			}
+			continue
+		}
+		present = true
+	}
+	if emoji == "" || present {
+		return nil
+	}
+	if err := api.MessageReactionAdd(channelID, messageID, emoji, discordgo.WithContext(ctx)); err != nil {
+		return fmt.Errorf("add discord reaction: %w", err)
+	}
+	return nil
+}
+
+// MaxAttachmentBytes 返回未加成服务器的单文件上传上限。
+func (c *Channel) MaxAttachmentBytes() int64 { return maxUploadBytes }
+
+// Send 发送出站消息，超过单条长度上限时拆分为多条依次发送，附件在文本之后上传。
+// 请求语音回复且已开启语音回复时改为上传合成的音频文件，合成或上传失败时回退为文本。
 func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	c.mu.RLock()
 	s := c.session
@@ -108,10 +239,41 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 		return fmt.Errorf("discord chat id is empty")
 	}
 
+	if err := channel.CheckAttachments("discord", msg.Attachments, maxUploadBytes); err != nil {
+		return err
+	}
+	if interaction, ok := c.lookupInteraction(msg.RequestID); ok {
+		return c.sendFollowup(ctx, interaction, msg)
+	}
+	target := targetChannel(msg.ChatID)
+	if thread := msg.ThreadID(); thread != "" {
+		target = thread
+	}
+	if speech, ok := channel.SynthesizeVoiceReply(ctx, c.synthesizer, msg); ok {
+		err := sendFile(s, target, speech)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("discord voice reply failed, sending text", "request_id", msg.RequestID, "error", err)
+	}
+
 	done := make(chan error, 1)
 	go func() {
-		_, err := s.ChannelMessageSend(msg.ChatID, msg.Content)
-		done <- err
+		if strings.TrimSpace(msg.Content) != "" || len(msg.Attachments) == 0 {
+			for _, chunk := range channel.SplitMessage(msg.Content, maxMessageLength) {
+				if _, err := s.ChannelMessageSend(target, chunk); err != nil {
+					done <- err
+					return
+				}
+			}
+		}
+		for _, att := range msg.Attachments {
+			if err := sendFile(s, target, att); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
 	}()
 
 	select {
@@ -125,6 +287,67 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	}
 }
 
+// sendFollowup 以 follow-up 消息回复斜杠命令：第一条替换延迟确认时的“思考中”提示，其余依次追加。
+func (c *Channel) sendFollowup(ctx context.Context, interaction *discordgo.Interaction, msg *bus.OutboundMessage) error {
+	c.mu.RLock()
+	api := c.interactions
+	c.mu.RUnlock()
+	if api == nil {
+		return fmt.Errorf("discord interactions unavailable")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if strings.TrimSpace(msg.Content) != "" || len(msg.Attachments) == 0 {
+			for _, chunk := range channel.SplitMessage(msg.Content, maxMessageLength) {
+				if _, err := api.FollowupMessageCreate(interaction, true, &discordgo.WebhookParams{Content: chunk}); err != nil {
+					done <- err
+					return
+				}
+			}
+		}
+		for _, att := range msg.Attachments {
+			reader, err := att.Open()
+			if err != nil {
+				done <- fmt.Errorf("open attachment %q: %w", att.Name(), err)
+				return
+			}
+			_, err = api.FollowupMessageCreate(interaction, true, &discordgo.WebhookParams{
+				Files: []*discordgo.File{{Name: att.Name(), Reader: reader}},
+			})
+			reader.Close()
+			if err != nil {
+				done <- fmt.Errorf("upload %q: %w", att.Name(), err)
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("send discord follow-up: %w", err)
+		}
+		return nil
+	}
+}
+
+// sendFile 将附件上传到 Discord 频道。
+func sendFile(s *discordgo.Session, channelID string, att bus.OutboundAttachment) error {
+	reader, err := att.Open()
+	if err != nil {
+		return fmt.Errorf("open attachment %q: %w", att.Name(), err)
+	}
+	defer reader.Close()
+	if _, err := s.ChannelFileSend(channelID, att.Name(), reader); err != nil {
+		return fmt.Errorf("upload %q: %w", att.Name(), err)
+	}
+	return nil
+}
+
 func (c *Channel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if m == nil || m.Author == nil {
 		return
@@ -138,11 +361,24 @@ func (c *Channel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 	if m.Author.Username != "" {
 		senderCompound = senderID + "|" + m.Author.Username
 	}
-	if !c.IsAllowed(senderCompound) {
+	if !c.AllowsSender(channel.Sender{ID: senderCompound, Guild: m.GuildID, Roles: memberRoles(s, m.GuildID, m.Member)}) {
 		return
 	}
 
+	// 服务器频道内的消息按 respond_when 过滤，私聊始终响应。
 	content := strings.TrimSpace(m.Content)
+	mentioned := false
+	if m.GuildID != "" {
+		botID := botUserID(s)
+		mentioned = mentionsBot(m, botID)
+		if !mentioned && c.cfg.RespondWhen == config.RespondMention {
+			return
+		}
+		if mentioned {
+			content = stripMention(content, botID)
+		}
+	}
+
 	media := make([]string, 0, len(m.Attachments))
 	transcribedCount := 0
 	for _, att := range m.Attachments {
@@ -171,11 +407,19 @@ func (c *Channel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 		content = appendLine(content, fmt.Sprintf("[attachment: %s]", att.URL))
 	}
 
+	chatID := chatIDFor(s, m.ChannelID)
+	channelID, threadID := parseChatID(chatID)
 	metadata := map[string]any{
 		"message_id": m.ID,
 		"username":   m.Author.Username,
 		"guild_id":   m.GuildID,
-		"channel_id": m.ChannelID,
+		"channel_id": channelID,
+	}
+	if threadID != "" {
+		metadata[bus.MetadataThreadID] = threadID
+	}
+	if mentioned {
+		metadata["is_mention"] = true
 	}
 	if transcribedCount > 0 {
 		metadata["transcribed_audio"] = true
@@ -188,7 +432,7 @@ func (c *Channel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 	c.PublishInbound(&bus.InboundMessage{
 		Channel:   c.Name(),
 		SenderID:  senderID,
-		ChatID:    m.ChannelID,
+		ChatID:    chatID,
 		Content:   content,
 		Timestamp: time.Now(),
 		Media:     media,
@@ -197,6 +441,121 @@ func (c *Channel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate
 	})
 }
 
+// handleInteraction 处理斜杠命令：3 秒内先延迟确认，再将命令作为入站消息发布，
+// 回复通过 follow-up 发送（见 Send）。/ask 的 prompt 即消息内容，其余命令映射为同名的 Golem 命令（如 /status）。
+func (c *Channel) handleInteraction(s *discordgo.Session, ic *discordgo.InteractionCreate) {
+	if ic == nil || ic.Interaction == nil || ic.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	i := ic.Interaction
+	user := i.User
+	if i.Member != nil && i.Member.User != nil {
+		user = i.Member.User
+	}
+	if user == nil || user.ID == "" {
+		return
+	}
+	c.mu.RLock()
+	api := c.interactions
+	c.mu.RUnlock()
+	if api == nil {
+		return
+	}
+
+	senderCompound := user.ID
+	if user.Username != "" {
+		senderCompound = user.ID + "|" + user.Username
+	}
+	if !c.AllowsSender(channel.Sender{ID: senderCompound, Guild: i.GuildID, Roles: memberRoles(s, i.GuildID, i.Member)}) {
+		// 仅调用者可见的拒绝提示，避免 Discord 显示“应用未响应”。
+		err := api.InteractionRespond(i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: "You are not allowed to use this bot.", Flags: discordgo.MessageFlagsEphemeral},
+		})
+		if err != nil {
+			slog.Warn("discord interaction reject failed", "error", err, "interaction_id", i.ID)
+		}
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	content := commandContent(data)
+	if err := api.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		slog.Warn("discord interaction ack failed", "error", err, "interaction_id", i.ID, "command", data.Name)
+		return
+	}
+
+	requestID := bus.NewRequestID()
+	c.trackInteraction(requestID, i)
+	chatID := chatIDFor(s, i.ChannelID)
+	channelID, threadID := parseChatID(chatID)
+	metadata := map[string]any{
+		"is_command":     true,
+		"command":        "/" + data.Name,
+		"interaction_id": i.ID,
+		"username":       user.Username,
+		"guild_id":       i.GuildID,
+		"channel_id":     channelID,
+	}
+	if threadID != "" {
+		metadata[bus.MetadataThreadID] = threadID
+	}
+	c.PublishInbound(&bus.InboundMessage{
+		Channel:   c.Name(),
+		SenderID:  user.ID,
+		ChatID:    chatID,
+		Content:   content,
+		Timestamp: time.Now(),
+		Metadata:  metadata,
+		RequestID: requestID,
+	})
+}
+
+// commandContent 将斜杠命令转换为消息内容：/ask 取 prompt 参数，其他命令转换为 "/<name>"。
+func commandContent(data discordgo.ApplicationCommandInteractionData) string {
+	if data.Name == "ask" {
+		for _, opt := range data.Options {
+			if opt != nil && opt.Name == "prompt" && opt.Type == discordgo.ApplicationCommandOptionString {
+				if prompt := strings.TrimSpace(opt.StringValue()); prompt != "" {
+					return prompt
+				}
+			}
+		}
+		return "/help"
+	}
+	return "/" + data.Name
+}
+
+// trackInteraction 记录等待回复的斜杠命令，并顺带清理令牌已过期的记录。
+func (c *Channel) trackInteraction(requestID string, interaction *discordgo.Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for id, p := range c.pending {
+		if !now.Before(p.expires) {
+			delete(c.pending, id)
+		}
+	}
+	c.pending[requestID] = pendingInteraction{interaction: interaction, expires: now.Add(interactionTokenTTL)}
+}
+
+// lookupInteraction 返回 request_id 对应且令牌仍有效的斜杠命令。同一请求的多条出站消息
+// （如审批提示与最终回复）都以 follow-up 发送，因此查找后不删除记录。
+func (c *Channel) lookupInteraction(requestID string) (*discordgo.Interaction, bool) {
+	if requestID == "" {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	p, ok := c.pending[requestID]
+	if !ok || !c.now().Before(p.expires) {
+		return nil, false
+	}
+	return p.interaction, true
+}
+
 func (c *Channel) tryTranscribeAttachment(ctx context.Context, att *discordgo.MessageAttachment) (string, error) {
 	if c.transcriber == nil || c.downloadAudio == nil || att == nil {
 		return "", nil
@@ -236,7 +595,7 @@ func (c *Channel) downloadDiscordAudio(ctx context.Context, url, fileName, mimeT
 		return voice.Input{}, fmt.Errorf("download discord media failed: status %d", resp.StatusCode)
 	}
 
-	data, err := readLimited(resp.Body, maxAudioBytes)
+	data, err := readLimited(resp.Body, voice.MaxAudioBytes)
 	if err != nil {
 		return voice.Input{}, err
 	}
@@ -283,3 +642,82 @@ func readLimited(r io.Reader, maxBytes int64) ([]byte, error) {
 	}
 	return data, nil
 }
+
+// botUserID 返回机器人自身的用户 ID，会话状态不可用时返回空字符串。
+func botUserID(s *discordgo.Session) string {
+	if s == nil || s.State == nil || s.State.User == nil {
+		return ""
+	}
+	return s.State.User.ID
+}
+
+// mentionsBot 判断消息是否提及了机器人或回复了机器人的消息。
+func mentionsBot(m *discordgo.MessageCreate, botID string) bool {
+	if botID == "" {
+		return false
+	}
+	for _, u := range m.Mentions {
+		if u != nil && u.ID == botID {
+			return true
+		}
+	}
+	ref := m.ReferencedMessage
+	return ref != nil && ref.Author != nil && ref.Author.ID == botID
+}
+
+// stripMention 去掉内容中对机器人的 <@id> 或 <@!id> 提及。
+func stripMention(content, botID string) string {
+	content = strings.ReplaceAll(content, "<@"+botID+">", "")
+	content = strings.ReplaceAll(content, "<@!"+botID+">", "")
+	return strings.TrimSpace(content)
+}
+
+// chatIDFor 返回消息所在位置的 ChatID：子区（thread）内的消息编码为 "<父频道>/<子区>"，使每个子区成为独立会话，
+// 其余为频道 ID。子区信息取自会话的状态缓存，未命中时按普通频道处理。
+func chatIDFor(s *discordgo.Session, channelID string) string {
+	if s == nil || s.State == nil || channelID == "" {
+		return channelID
+	}
+	ch, err := s.State.Channel(channelID)
+	if err != nil || ch == nil || !ch.IsThread() || ch.ParentID == "" {
+		return channelID
+	}
+	return ch.ParentID + "/" + channelID
+}
+
+// parseChatID 拆分 ChatID，返回父频道与子区 ID；不在子区内时 threadID 为空。
+func parseChatID(chatID string) (channelID, threadID string) {
+	parts := strings.SplitN(chatID, "/", 2)
+	channelID = parts[0]
+	if len(parts) > 1 {
+		threadID = parts[1]
+	}
+	return
+}
+
+// targetChannel 返回 ChatID 对应的发送目标：子区内的会话发往子区本身。
+func targetChannel(chatID string) string {
+	channelID, threadID := parseChatID(chatID)
+	if threadID != "" {
+		return threadID
+	}
+	return channelID
+}
+
+// memberRoles 返回发送者在服务器内的角色 ID，并在状态缓存可用时补充 "服务器ID/角色名" 形式的名称。
+// 名称带上服务器 ID，避免其他服务器中同名角色的成员通过 role: 条目。
+func memberRoles(s *discordgo.Session, guildID string, member *discordgo.Member) []string {
+	if member == nil || len(member.Roles) == 0 {
+		return nil
+	}
+	roles := append([]string(nil), member.Roles...)
+	if s == nil || s.State == nil || guildID == "" {
+		return roles
+	}
+	for _, id := range member.Roles {
+		if role, err := s.State.Role(guildID, id); err == nil && role != nil && role.Name != "" {
+			roles = append(roles, guildID+"/"+role.Name)
+		}
+	}
+	return roles
+}
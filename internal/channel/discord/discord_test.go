@@ -2,10 +2,14 @@ package discord
 
 import (
 	"context"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/voice"
 	"github.com/bwmarrin/discordgo"
@@ -172,3 +176,318 @@ func TestHandleMessage_AudioOnlyFailureKeepsAudioPlaceholder(t *testing.T) {
 		t.Fatal("expected inbound message")
 	}
 }
+
+func TestHandleMessage_AllowFromMatchesGuildAndRole(t *testing.T) {
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&config.DiscordConfig{AllowFrom: []string{"guild:g1", "!role:muted"}}, msgBus, nil)
+
+	send := func(guildID string, roles ...string) bool {
+		ch.handleMessage(nil, &discordgo.MessageCreate{
+			Message: &discordgo.Message{
+				ID:        "m1",
+				GuildID:   guildID,
+				ChannelID: "c1",
+				Content:   "hi",
+				Author:    &discordgo.User{ID: "u1", Username: "alice"},
+				Member:    &discordgo.Member{Roles: roles},
+			},
+		})
+		select {
+		case <-msgBus.Inbound():
+			return true
+		default:
+			return false
+		}
+	}
+
+	if !send("g1") {
+		t.Fatal("expected member of g1 to be allowed")
+	}
+	if send("g2") {
+		t.Fatal("expected member of another guild to be rejected")
+	}
+	if send("g1", "muted") {
+		t.Fatal("expected deny rule to take precedence over guild rule")
+	}
+}
+
+type fakeInteractions struct {
+	mu        sync.Mutex
+	responses []*discordgo.InteractionResponse
+	followups []*discordgo.WebhookParams
+}
+
+func (f *fakeInteractions) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func (f *fakeInteractions) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.followups = append(f.followups, data)
+	return &discordgo.Message{}, nil
+}
+
+func commandInteraction(name string, options ...*discordgo.ApplicationCommandInteractionDataOption) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:        "i1",
+		Type:      discordgo.InteractionApplicationCommand,
+		GuildID:   "g1",
+		ChannelID: "c1",
+		Token:     "token",
+		Member:    &discordgo.Member{User: &discordgo.User{ID: "u1", Username: "alice"}},
+		Data:      discordgo.ApplicationCommandInteractionData{Name: name, Options: options},
+	}}
+}
+
+func TestHandleInteraction_AskDefersAndRepliesWithFollowup(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.DiscordConfig{SlashCommands: true}, msgBus, nil)
+	api := &fakeInteractions{}
+	ch.interactions = api
+	ch.session = &discordgo.Session{}
+	ch.running = true
+
+	ch.handleInteraction(nil, commandInteraction("ask", &discordgo.ApplicationCommandInteractionDataOption{
+		Name: "prompt", Type: discordgo.ApplicationCommandOptionString, Value: "what is the weather?",
+	}))
+
+	if len(api.responses) != 1 || api.responses[0].Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		t.Fatalf("expected a deferred acknowledgement, got %+v", api.responses)
+	}
+	var msg *bus.InboundMessage
+	select {
+	case msg = <-msgBus.Inbound():
+	default:
+		t.Fatal("expected inbound message")
+	}
+	if msg.Content != "what is the weather?" || msg.SenderID != "u1" || msg.ChatID != "c1" {
+		t.Fatalf("unexpected inbound message: %+v", msg)
+	}
+	if msg.Metadata["is_command"] != true || msg.Metadata["command"] != "/ask" || msg.Metadata["interaction_id"] != "i1" {
+		t.Fatalf("unexpected metadata: %+v", msg.Metadata)
+	}
+
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "c1", Content: "sunny", RequestID: msg.RequestID}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(api.followups) != 1 || api.followups[0].Content != "sunny" {
+		t.Fatalf("expected reply as follow-up, got %+v", api.followups)
+	}
+
+	// 令牌过期后回退为普通频道消息，不再使用 follow-up。
+	ch.now = func() time.Time { return time.Now().Add(interactionTokenTTL + time.Minute) }
+	if _, ok := ch.lookupInteraction(msg.RequestID); ok {
+		t.Fatal("expected expired interaction to be ignored")
+	}
+}
+
+func TestHandleInteraction_StatusMapsToCommand(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.DiscordConfig{SlashCommands: true}, msgBus, nil)
+	ch.interactions = &fakeInteractions{}
+
+	ch.handleInteraction(nil, commandInteraction("status"))
+
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Content != "/status" {
+			t.Fatalf("expected /status content, got %q", msg.Content)
+		}
+	default:
+		t.Fatal("expected inbound message")
+	}
+}
+
+func TestHandleInteraction_RejectsDisallowedSender(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.DiscordConfig{SlashCommands: true, AllowFrom: []string{"u2"}}, msgBus, nil)
+	api := &fakeInteractions{}
+	ch.interactions = api
+
+	ch.handleInteraction(nil, commandInteraction("status"))
+
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("expected disallowed sender to be dropped, got %+v", msg)
+	default:
+	}
+	if len(api.responses) != 1 || api.responses[0].Data == nil || api.responses[0].Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Fatalf("expected an ephemeral rejection, got %+v", api.responses)
+	}
+}
+
+func TestParseChatID(t *testing.T) {
+	channelID, threadID := parseChatID("c1/t1")
+	if channelID != "c1" || threadID != "t1" {
+		t.Fatalf("unexpected parse result: channel=%q thread=%q", channelID, threadID)
+	}
+	if target := targetChannel("c1/t1"); target != "t1" {
+		t.Fatalf("expected thread messages to target the thread, got %q", target)
+	}
+}
+
+func TestParseChatID_ChannelOnly(t *testing.T) {
+	channelID, threadID := parseChatID("c1")
+	if channelID != "c1" || threadID != "" {
+		t.Fatalf("unexpected parse result: channel=%q thread=%q", channelID, threadID)
+	}
+	if target := targetChannel("c1"); target != "c1" {
+		t.Fatalf("expected channel target, got %q", target)
+	}
+}
+
+func TestHandleMessage_ThreadGetsOwnSession(t *testing.T) {
+	state := discordgo.NewState()
+	if err := state.GuildAdd(&discordgo.Guild{ID: "g1"}); err != nil {
+		t.Fatalf("GuildAdd: %v", err)
+	}
+	if err := state.ChannelAdd(&discordgo.Channel{ID: "t1", GuildID: "g1", ParentID: "c1", Type: discordgo.ChannelTypeGuildPublicThread}); err != nil {
+		t.Fatalf("ChannelAdd: %v", err)
+	}
+	s := &discordgo.Session{State: state}
+
+	msgBus := bus.NewMessageBus(2)
+	ch := New(&config.DiscordConfig{}, msgBus, nil)
+	post := func(channelID string) *bus.InboundMessage {
+		ch.handleMessage(s, &discordgo.MessageCreate{Message: &discordgo.Message{
+			ID:        "m-" + channelID,
+			GuildID:   "g1",
+			ChannelID: channelID,
+			Content:   "hi",
+			Author:    &discordgo.User{ID: "u1", Username: "alice"},
+		}})
+		select {
+		case msg := <-msgBus.Inbound():
+			return msg
+		default:
+			t.Fatal("expected inbound message")
+			return nil
+		}
+	}
+
+	inThread := post("t1")
+	inChannel := post("c1")
+	if inThread.ChatID != "c1/t1" || inThread.Metadata["channel_id"] != "c1" || inThread.Metadata["thread_id"] != "t1" {
+		t.Fatalf("unexpected thread message: chat=%q metadata=%+v", inThread.ChatID, inThread.Metadata)
+	}
+	if inChannel.ChatID != "c1" {
+		t.Fatalf("expected parent channel chat id, got %q", inChannel.ChatID)
+	}
+	if _, ok := inChannel.Metadata["thread_id"]; ok {
+		t.Fatalf("expected no thread_id outside threads, got %+v", inChannel.Metadata)
+	}
+	if inThread.SessionKey() == inChannel.SessionKey() {
+		t.Fatalf("expected thread and channel to use separate sessions, both got %q", inThread.SessionKey())
+	}
+}
+
+func TestHandleMessage_MentionModeFiltersGuildMessages(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "bot", Username: "golem"}
+	s := &discordgo.Session{State: state}
+
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&config.DiscordConfig{RespondWhen: config.RespondMention}, msgBus, nil)
+	alice := &discordgo.User{ID: "u1", Username: "alice"}
+	deliver := func(m *discordgo.Message) *bus.InboundMessage {
+		ch.handleMessage(s, &discordgo.MessageCreate{Message: m})
+		select {
+		case msg := <-msgBus.Inbound():
+			return msg
+		default:
+			return nil
+		}
+	}
+
+	if msg := deliver(&discordgo.Message{ID: "m1", GuildID: "g1", ChannelID: "c1", Content: "hi all", Author: alice}); msg != nil {
+		t.Fatalf("expected unaddressed guild message to be ignored, got %+v", msg)
+	}
+
+	msg := deliver(&discordgo.Message{
+		ID: "m2", GuildID: "g1", ChannelID: "c1", Content: "<@bot> summarize this", Author: alice,
+		Mentions: []*discordgo.User{state.User},
+	})
+	if msg == nil || msg.Content != "summarize this" || msg.Metadata["is_mention"] != true {
+		t.Fatalf("expected mention to be delivered without the bot tag, got %+v", msg)
+	}
+
+	msg = deliver(&discordgo.Message{
+		ID: "m3", GuildID: "g1", ChannelID: "c1", Content: "thanks, and more?", Author: alice,
+		ReferencedMessage: &discordgo.Message{ID: "r1", Author: state.User},
+	})
+	if msg == nil {
+		t.Fatal("expected reply to the bot to be delivered")
+	}
+
+	if msg := deliver(&discordgo.Message{ID: "m4", ChannelID: "dm1", Content: "hello", Author: alice}); msg == nil {
+		t.Fatal("expected direct messages to always be delivered")
+	}
+}
+
+type fakeReactions struct {
+	existing []*discordgo.MessageReactions
+	calls    []string
+}
+
+func (f *fakeReactions) ChannelMessage(channelID, messageID string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return &discordgo.Message{ID: messageID, ChannelID: channelID, Reactions: f.existing}, nil
+}
+
+func (f *fakeReactions) MessageReactionAdd(channelID, messageID, emojiID string, options ...discordgo.RequestOption) error {
+	f.calls = append(f.calls, "add "+channelID+"/"+messageID+" "+emojiID)
+	return nil
+}
+
+func (f *fakeReactions) MessageReactionRemove(channelID, messageID, emojiID, userID string, options ...discordgo.RequestOption) error {
+	f.calls = append(f.calls, "remove "+channelID+"/"+messageID+" "+emojiID+" "+userID)
+	return nil
+}
+
+func TestReact_SwapsOwnReactionInThread(t *testing.T) {
+	ch := New(&config.DiscordConfig{}, bus.NewMessageBus(1), nil)
+	api := &fakeReactions{existing: []*discordgo.MessageReactions{
+		{Count: 1, Me: true, Emoji: &discordgo.Emoji{Name: "👀"}},
+		{Count: 2, Me: false, Emoji: &discordgo.Emoji{Name: "🎉"}},
+	}}
+	ch.reactions = api
+	ch.running = true
+
+	if err := ch.React(context.Background(), "parent/t1", "m1", "👍"); err != nil {
+		t.Fatalf("React: %v", err)
+	}
+	want := []string{"remove t1/m1 👀 @me", "add t1/m1 👍"}
+	if strings.Join(api.calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, api.calls)
+	}
+
+	api.calls = nil
+	if err := ch.React(context.Background(), "c1", "m1", "👀"); err != nil {
+		t.Fatalf("React: %v", err)
+	}
+	if len(api.calls) != 0 {
+		t.Fatalf("expected existing reaction to be kept as is, got %v", api.calls)
+	}
+}
+
+func TestMemberRoles_ScopesNamesToGuild(t *testing.T) {
+	state := discordgo.NewState()
+	if err := state.GuildAdd(&discordgo.Guild{ID: "g1", Roles: []*discordgo.Role{{ID: "r1", Name: "Admins"}}}); err != nil {
+		t.Fatalf("GuildAdd: %v", err)
+	}
+	s := &discordgo.Session{State: state}
+
+	roles := memberRoles(s, "g1", &discordgo.Member{Roles: []string{"r1"}})
+	if !slices.Equal(roles, []string{"r1", "g1/Admins"}) {
+		t.Fatalf("unexpected roles: %v", roles)
+	}
+	if !channel.NewAllowMatcher([]string{"role:g1/admins"}).Allows(channel.Sender{ID: "u1", Guild: "g1", Roles: roles}) {
+		t.Fatal("expected guild-scoped role name to match")
+	}
+	if channel.NewAllowMatcher([]string{"role:admins"}).Allows(channel.Sender{ID: "u1", Guild: "g1", Roles: roles}) {
+		t.Fatal("expected a bare role name not to match")
+	}
+}
@@ -1,4 +1,6 @@
 // Package feishu 实现飞书机器人的接入，支持通过 WebSocket 模式接收和发送消息。
+// 出站消息默认以纯文本发送；元数据带有 bus.MetadataCard 时以交互卡片发送，
+// 卡片按钮的点击（card.action.trigger 回调）作为该用户的消息回传给 Agent。
 package feishu
 
 import (
@@ -15,6 +17,7 @@ import (
 	"github.com/MEKXH/golem/internal/config"
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkdispatcher "github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	larkcallback "github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 	larkws "github.com/larksuite/oapi-sdk-go/v3/ws"
 )
@@ -58,7 +61,8 @@ func (c *Channel) Start(ctx context.Context) error {
 
 	// 注册消息接收处理器
 	dispatcher := larkdispatcher.NewEventDispatcher(c.cfg.VerificationToken, c.cfg.EncryptKey).
-		OnP2MessageReceiveV1(c.handleMessageReceive)
+		OnP2MessageReceiveV1(c.handleMessageReceive).
+		OnP2CardActionTrigger(c.handleCardAction)
 
 	runCtx, cancel := context.WithCancel(ctx)
 	client := larkws.NewClient(
@@ -96,7 +100,7 @@ func (c *Channel) Stop(ctx context.Context) error {
 	return nil
 }
 
-// Send 向飞书聊天发送文本消息。
+// Send 向飞书聊天发送消息：请求了卡片时发送交互卡片，卡片发送失败或未请求时发送文本。
 func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	c.mu.Lock()
 	running := c.run
@@ -108,17 +112,60 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 		return fmt.Errorf("feishu chat id is empty")
 	}
 
+	if card := msg.Card(); card != nil {
+		content, err := buildCardContent(card)
+		if err == nil {
+			err = c.postMessage(ctx, msg, larkim.MsgTypeInteractive, content)
+		}
+		if err == nil {
+			return nil
+		}
+		slog.Warn("feishu card send failed, falling back to text", "chat_id", msg.ChatID, "error", err)
+	}
+
 	payload, err := json.Marshal(map[string]string{"text": msg.Content})
 	if err != nil {
 		return fmt.Errorf("marshal feishu content: %w", err)
 	}
+	return c.postMessage(ctx, msg, larkim.MsgTypeText, string(payload))
+}
+
+// postMessage 发送消息：指定了线程（bus.MetadataThreadID）时以话题回复的形式发到该话题，否则发到聊天。
+func (c *Channel) postMessage(ctx context.Context, msg *bus.OutboundMessage, msgType, content string) error {
+	if root := msg.ThreadID(); root != "" {
+		return c.replyInThread(ctx, root, msgType, content)
+	}
+	return c.createMessage(ctx, msg.ChatID, msgType, content)
+}
 
+func (c *Channel) replyInThread(ctx context.Context, rootID, msgType, content string) error {
+	req := larkim.NewReplyMessageReqBuilder().
+		MessageId(rootID).
+		Body(larkim.NewReplyMessageReqBodyBuilder().
+			MsgType(msgType).
+			Content(content).
+			ReplyInThread(true).
+			Uuid(fmt.Sprintf("golem-%d", time.Now().UnixNano())).
+			Build()).
+		Build()
+
+	resp, err := c.client.Im.V1.Message.Reply(ctx, req)
+	if err != nil {
+		return fmt.Errorf("reply feishu thread: %w", err)
+	}
+	if !resp.Success() {
+		return fmt.Errorf("feishu api error: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+func (c *Channel) createMessage(ctx context.Context, chatID, msgType, content string) error {
 	req := larkim.NewCreateMessageReqBuilder().
 		ReceiveIdType(larkim.ReceiveIdTypeChatId).
 		Body(larkim.NewCreateMessageReqBodyBuilder().
-			ReceiveId(msg.ChatID).
-			MsgType(larkim.MsgTypeText).
-			Content(string(payload)).
+			ReceiveId(chatID).
+			MsgType(msgType).
+			Content(content).
 			Uuid(fmt.Sprintf("golem-%d", time.Now().UnixNano())).
 			Build()).
 		Build()
@@ -133,6 +180,91 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	return nil
 }
 
+// buildCardContent 将通用卡片转换为飞书消息卡片 JSON：Markdown 正文加一行按钮，
+// 按钮的 value.text 即点击后回传的消息文本。
+func buildCardContent(card *bus.Card) (string, error) {
+	elements := []map[string]any{}
+	if body := strings.TrimSpace(card.Body); body != "" {
+		elements = append(elements, map[string]any{"tag": "markdown", "content": body})
+	}
+	if len(card.Actions) > 0 {
+		buttons := make([]map[string]any, 0, len(card.Actions))
+		for _, action := range card.Actions {
+			style := action.Style
+			if style != "primary" && style != "danger" {
+				style = "default"
+			}
+			buttons = append(buttons, map[string]any{
+				"tag":   "button",
+				"text":  map[string]string{"tag": "plain_text", "content": action.Label},
+				"type":  style,
+				"value": map[string]string{"text": action.Value},
+			})
+		}
+		elements = append(elements, map[string]any{"tag": "action", "actions": buttons})
+	}
+
+	content := map[string]any{
+		"config":   map[string]any{"wide_screen_mode": true},
+		"elements": elements,
+	}
+	if title := strings.TrimSpace(card.Title); title != "" {
+		content["header"] = map[string]any{
+			"title":    map[string]string{"tag": "plain_text", "content": title},
+			"template": "blue",
+		}
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("marshal feishu card: %w", err)
+	}
+	return string(data), nil
+}
+
+// handleCardAction 把卡片按钮点击转换为点击者发送的入站消息，并以 toast 提示已提交。
+func (c *Channel) handleCardAction(_ context.Context, event *larkcallback.CardActionTriggerEvent) (*larkcallback.CardActionTriggerResponse, error) {
+	if event == nil || event.Event == nil || event.Event.Action == nil || event.Event.Context == nil {
+		return nil, nil
+	}
+	text, _ := event.Event.Action.Value["text"].(string)
+	chatID := event.Event.Context.OpenChatID
+	if strings.TrimSpace(text) == "" || chatID == "" {
+		return nil, nil
+	}
+
+	senderID := ""
+	if operator := event.Event.Operator; operator != nil {
+		senderID = stringPtrValue(operator.UserID)
+		if senderID == "" {
+			senderID = operator.OpenID
+		}
+	}
+	if senderID == "" {
+		senderID = "unknown"
+	}
+	if !c.IsAllowed(senderID) {
+		return &larkcallback.CardActionTriggerResponse{
+			Toast: &larkcallback.Toast{Type: "error", Content: "You are not allowed to use this bot."},
+		}, nil
+	}
+
+	c.PublishInbound(&bus.InboundMessage{
+		Channel:   c.Name(),
+		SenderID:  senderID,
+		ChatID:    chatID,
+		Content:   text,
+		Timestamp: time.Now(),
+		Metadata: map[string]any{
+			"card_action":     true,
+			"card_message_id": event.Event.Context.OpenMessageID,
+		},
+		RequestID: bus.NewRequestID(),
+	})
+	return &larkcallback.CardActionTriggerResponse{
+		Toast: &larkcallback.Toast{Type: "info", Content: "Submitted: " + text},
+	}, nil
+}
+
 func (c *Channel) handleMessageReceive(_ context.Context, event *larkim.P2MessageReceiveV1) error {
 	if event == nil || event.Event == nil || event.Event.Message == nil {
 		return nil
@@ -169,6 +301,12 @@ func (c *Channel) handleMessageReceive(_ context.Context, event *larkim.P2Messag
 	if chatType := stringPtrValue(message.ChatType); chatType != "" {
 		metadata["chat_type"] = chatType
 	}
+	// 话题内的消息以话题根消息 ID 作为线程标识，回复时对根消息发起话题回复。
+	if stringPtrValue(message.ThreadId) != "" {
+		if root := stringPtrValue(message.RootId); root != "" {
+			metadata[bus.MetadataThreadID] = root
+		}
+	}
 
 	c.PublishInbound(&bus.InboundMessage{
 		Channel:   c.Name(),
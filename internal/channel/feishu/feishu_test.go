@@ -0,0 +1,121 @@
+package feishu
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	larkcallback "github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+func TestBuildCardContent(t *testing.T) {
+	content, err := buildCardContent(&bus.Card{
+		Title: "Approval required (id: 7)",
+		Body:  "**Tool:** `exec`",
+		Actions: []bus.CardAction{
+			{Label: "Approve", Value: "approve 7", Style: "primary"},
+			{Label: "Later", Value: "later"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("buildCardContent: %v", err)
+	}
+
+	var card struct {
+		Header struct {
+			Title struct {
+				Content string `json:"content"`
+			} `json:"title"`
+		} `json:"header"`
+		Elements []struct {
+			Tag     string `json:"tag"`
+			Content string `json:"content"`
+			Actions []struct {
+				Type  string            `json:"type"`
+				Value map[string]string `json:"value"`
+			} `json:"actions"`
+		} `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(content), &card); err != nil {
+		t.Fatalf("card is not valid json: %v", err)
+	}
+	if card.Header.Title.Content != "Approval required (id: 7)" {
+		t.Fatalf("unexpected card title: %q", card.Header.Title.Content)
+	}
+	if len(card.Elements) != 2 || card.Elements[0].Tag != "markdown" || card.Elements[1].Tag != "action" {
+		t.Fatalf("expected markdown body and action row, got %+v", card.Elements)
+	}
+	buttons := card.Elements[1].Actions
+	if len(buttons) != 2 || buttons[0].Type != "primary" || buttons[0].Value["text"] != "approve 7" || buttons[1].Type != "default" {
+		t.Fatalf("unexpected buttons: %+v", buttons)
+	}
+}
+
+func TestHandleCardAction_PublishesClickAsMessage(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.FeishuConfig{AllowFrom: []string{"ou_alice"}}, msgBus)
+
+	event := func(openID string) *larkcallback.CardActionTriggerEvent {
+		return &larkcallback.CardActionTriggerEvent{Event: &larkcallback.CardActionTriggerRequest{
+			Operator: &larkcallback.Operator{OpenID: openID},
+			Action:   &larkcallback.CallBackAction{Value: map[string]any{"text": "approve 7"}},
+			Context:  &larkcallback.Context{OpenChatID: "oc_chat", OpenMessageID: "om_card"},
+		}}
+	}
+
+	resp, err := ch.handleCardAction(context.Background(), event("ou_mallory"))
+	if err != nil || resp == nil || resp.Toast == nil || resp.Toast.Type != "error" {
+		t.Fatalf("expected disallowed click to be refused with a toast, got %+v, %v", resp, err)
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("expected no inbound message for a disallowed sender, got %+v", msg)
+	default:
+	}
+
+	resp, err = ch.handleCardAction(context.Background(), event("ou_alice"))
+	if err != nil || resp == nil || resp.Toast == nil || resp.Toast.Type != "info" {
+		t.Fatalf("expected confirmation toast, got %+v, %v", resp, err)
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Content != "approve 7" || msg.SenderID != "ou_alice" || msg.ChatID != "oc_chat" {
+			t.Fatalf("unexpected inbound message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected card click to be published as an inbound message")
+	}
+}
+
+func TestHandleMessageReceive_RecordsThreadRoot(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.FeishuConfig{}, msgBus)
+	str := func(s string) *string { return &s }
+
+	err := ch.handleMessageReceive(context.Background(), &larkim.P2MessageReceiveV1{Event: &larkim.P2MessageReceiveV1Data{
+		Sender: &larkim.EventSender{SenderId: &larkim.UserId{OpenId: str("ou_alice")}},
+		Message: &larkim.EventMessage{
+			MessageId:   str("om_reply"),
+			RootId:      str("om_root"),
+			ThreadId:    str("omt_1"),
+			ChatId:      str("oc_chat"),
+			MessageType: str("text"),
+			Content:     str(`{"text":"and then?"}`),
+		},
+	}})
+	if err != nil {
+		t.Fatalf("handleMessageReceive: %v", err)
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.ChatID != "oc_chat" || msg.ThreadID() != "om_root" {
+			t.Fatalf("expected thread root in metadata, got chat=%q metadata=%+v", msg.ChatID, msg.Metadata)
+		}
+	default:
+		t.Fatal("expected inbound message")
+	}
+}
@@ -2,8 +2,10 @@ package channel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -23,9 +25,18 @@ type Manager struct {
 	dedupSeenAt   map[string]time.Time // 消息去重记录，防止重复发送
 	rateMu        sync.Mutex
 	lastSendAt    time.Time // 记录上次消息发送时间，用于速率限制
+	statusMu      sync.Mutex
+	startErrs     map[string]error // 启动失败的通道及其错误，重新启动或注销时清除
 	mu            sync.RWMutex
 }
 
+// Status 描述单个已注册通道的运行状态。
+type Status struct {
+	Name  string // 通道名称
+	Ready bool   // 通道已启动且未报告启动失败
+	Error string // 启动失败的原因，Ready 为 true 时为空
+}
+
 const defaultMaxConcurrentSends = 16
 
 // DeliveryPolicy 定义了出站消息的投递规则，包括重试次数、退避策略、速率限制和去重窗口。
@@ -68,6 +79,7 @@ func NewManagerWithPolicy(msgBus *bus.MessageBus, policy DeliveryPolicy) *Manage
 		sendSem:     make(chan struct{}, normalized.MaxConcurrentSends),
 		policy:      normalized,
 		dedupSeenAt: make(map[string]time.Time),
+		startErrs:   make(map[string]error),
 	}
 }
 
@@ -97,19 +109,115 @@ func (m *Manager) Names() []string {
 	return names
 }
 
+// Has 报告指定名称的通道当前是否已注册。
+func (m *Manager) Has(name string) bool {
+	_, _, ok := m.resolveChannel(name)
+	return ok
+}
+
+// SetDeliveryPolicy 在运行时替换出站投递策略；正在进行的发送仍按旧的并发上限完成。
+func (m *Manager) SetDeliveryPolicy(policy DeliveryPolicy) {
+	normalized := normalizeDeliveryPolicy(policy)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if normalized.MaxConcurrentSends != m.policy.MaxConcurrentSends {
+		m.sendSem = make(chan struct{}, normalized.MaxConcurrentSends)
+	}
+	m.policy = normalized
+}
+
+// Policy 返回当前生效的出站投递策略。
+func (m *Manager) Policy() DeliveryPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.policy
+}
+
 // StartAll 启动管理器下属的所有消息通道，使其开始接收外部平台的入站消息。
 func (m *Manager) StartAll(ctx context.Context) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	for name, ch := range m.channels {
-		go func(n string, c Channel) {
-			slog.Info("正在启动消息通道", "name", n)
-			if err := c.Start(ctx); err != nil {
-				slog.Error("消息通道运行出错", "name", n, "error", err)
+		m.startChannel(ctx, name, ch)
+	}
+}
+
+// Start 启动单个已注册的消息通道。
+func (m *Manager) Start(ctx context.Context, name string) error {
+	m.mu.RLock()
+	ch, ok := m.channels[name]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("channel %q is not registered", name)
+	}
+	m.startChannel(ctx, name, ch)
+	return nil
+}
+
+// Unregister 停止并移除指定名称的消息通道；通道不存在时不做任何处理。
+func (m *Manager) Unregister(ctx context.Context, name string) error {
+	m.mu.Lock()
+	ch, ok := m.channels[name]
+	delete(m.channels, name)
+	m.mu.Unlock()
+	m.setStartErr(name, nil)
+	if !ok {
+		return nil
+	}
+	slog.Info("正在停止消息通道", "name", name)
+	return ch.Stop(ctx)
+}
+
+// startChannel 在后台启动通道；Start 返回错误时记录下来供 Statuses 查询。
+func (m *Manager) startChannel(ctx context.Context, name string, ch Channel) {
+	m.setStartErr(name, nil)
+	go func() {
+		slog.Info("正在启动消息通道", "name", name)
+		if err := ch.Start(ctx); err != nil {
+			slog.Error("消息通道运行出错", "name", name, "error", err)
+			if ctx.Err() == nil {
+				m.setStartErr(name, err)
 			}
-		}(name, ch)
+		}
+	}()
+}
+
+func (m *Manager) setStartErr(name string, err error) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	if err == nil {
+		delete(m.startErrs, name)
+		return
+	}
+	if m.startErrs == nil {
+		m.startErrs = make(map[string]error)
+	}
+	m.startErrs[name] = err
+}
+
+// Statuses 返回所有已注册通道的运行状态，按名称排序。
+func (m *Manager) Statuses() []Status {
+	m.mu.RLock()
+	names := make([]string, 0, len(m.channels))
+	for name := range m.channels {
+		names = append(names, name)
+	}
+	m.mu.RUnlock()
+	sort.Strings(names)
+
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	statuses := make([]Status, 0, len(names))
+	for _, name := range names {
+		status := Status{Name: name, Ready: true}
+		if err := m.startErrs[name]; err != nil {
+			status.Ready = false
+			status.Error = err.Error()
+		}
+		statuses = append(statuses, status)
 	}
+	return statuses
 }
 
 // RouteOutbound 持续监控消息总线的出站队列，并将消息分发到对应的通道进行发送。
@@ -135,10 +243,12 @@ func (m *Manager) RouteOutbound(ctx context.Context) {
 			if !ok {
 				continue
 			}
+			// 捕获当前信号量，确保策略热更新后释放的仍是获取时的那一个。
+			sem := m.sendSemaphore()
 			select {
-			case m.sendSem <- struct{}{}:
+			case sem <- struct{}{}:
 				go func(c Channel, outbound *bus.OutboundMessage, metricRecorder *metrics.RuntimeMetrics) {
-					defer func() { <-m.sendSem }()
+					defer func() { <-sem }()
 					if err := m.sendWithPolicy(ctx, c, outbound, metricRecorder); err != nil {
 						slog.Error("消息发送失败", "request_id", outbound.RequestID, "channel", outbound.Channel, "chat_id", outbound.ChatID, "error", err)
 					}
@@ -160,6 +270,55 @@ func (m *Manager) StopAll(ctx context.Context) {
 	}
 }
 
+func (m *Manager) sendSemaphore() chan struct{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sendSem
+}
+
+// SendTyping 向指定通道的聊天发送“正在输入”提示；通道未注册或不支持时静默忽略。
+func (m *Manager) SendTyping(ctx context.Context, channelName, chatID string) {
+	ch, _, ok := m.resolveChannel(channelName)
+	if !ok {
+		return
+	}
+	typing, ok := ch.(TypingSender)
+	if !ok {
+		return
+	}
+	if err := typing.SendTyping(ctx, chatID); err != nil {
+		slog.Debug("send typing indicator failed", "channel", channelName, "chat_id", chatID, "error", err)
+	}
+}
+
+// React 设置指定通道消息上的表情反应；通道未注册或不支持时静默忽略。
+func (m *Manager) React(ctx context.Context, channelName, chatID, messageID, emoji string) {
+	ch, _, ok := m.resolveChannel(channelName)
+	if !ok {
+		return
+	}
+	reactor, ok := ch.(Reactor)
+	if !ok {
+		return
+	}
+	if err := reactor.React(ctx, chatID, messageID, emoji); err != nil {
+		slog.Debug("set message reaction failed", "channel", channelName, "chat_id", chatID, "message_id", messageID, "emoji", emoji, "error", err)
+	}
+}
+
+// AttachmentLimit 返回通道的单文件上传上限；通道未注册或未声明上限时 ok 为 false。
+func (m *Manager) AttachmentLimit(channelName string) (limit int64, ok bool) {
+	ch, _, found := m.resolveChannel(channelName)
+	if !found {
+		return 0, false
+	}
+	limiter, ok := ch.(AttachmentLimiter)
+	if !ok {
+		return 0, false
+	}
+	return limiter.MaxAttachmentBytes(), true
+}
+
 func (m *Manager) resolveChannel(name string) (Channel, *metrics.RuntimeMetrics, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -190,7 +349,7 @@ func (m *Manager) sendWithPolicy(ctx context.Context, c Channel, outbound *bus.O
 		}
 	}()
 
-	attempts := m.policy.RetryMaxAttempts
+	attempts := m.Policy().RetryMaxAttempts
 	if attempts <= 0 {
 		attempts = 1
 	}
@@ -224,19 +383,25 @@ func (m *Manager) shouldRetry(channelName string, attempt, maxAttempts int, err
 	if err == nil || attempt >= maxAttempts {
 		return false
 	}
+	// 附件超限属于确定性失败，重试没有意义。
+	var tooLarge *AttachmentTooLargeError
+	if errors.As(err, &tooLarge) {
+		return false
+	}
 	name := strings.ToLower(strings.TrimSpace(channelName))
-	return name == "telegram" || name == "discord" || name == "slack"
+	return name == "telegram" || name == "discord" || name == "slack" || name == "mattermost" || name == "matrix"
 }
 
 func (m *Manager) waitBackoff(ctx context.Context, attempt int) error {
-	backoff := m.policy.RetryBaseBackoff
+	policy := m.Policy()
+	backoff := policy.RetryBaseBackoff
 	if backoff <= 0 {
 		return nil
 	}
 	if attempt > 1 {
 		backoff = backoff * time.Duration(1<<(attempt-1))
 	}
-	if max := m.policy.RetryMaxBackoff; max > 0 && backoff > max {
+	if max := policy.RetryMaxBackoff; max > 0 && backoff > max {
 		backoff = max
 	}
 	timer := time.NewTimer(backoff)
@@ -250,7 +415,7 @@ func (m *Manager) waitBackoff(ctx context.Context, attempt int) error {
 }
 
 func (m *Manager) waitRateLimit(ctx context.Context) error {
-	rate := m.policy.RateLimitPerSecond
+	rate := m.Policy().RateLimitPerSecond
 	if rate <= 0 {
 		return nil
 	}
@@ -297,20 +462,21 @@ func (m *Manager) dedupKey(outbound *bus.OutboundMessage) string {
 
 func (m *Manager) beginDedup(outbound *bus.OutboundMessage) (string, bool) {
 	key := m.dedupKey(outbound)
-	if key == "" || m.policy.DedupWindow <= 0 {
+	window := m.Policy().DedupWindow
+	if key == "" || window <= 0 {
 		return "", false
 	}
 
 	now := time.Now()
 	m.dedupMu.Lock()
 	defer m.dedupMu.Unlock()
-	cutoff := now.Add(-m.policy.DedupWindow)
+	cutoff := now.Add(-window)
 	for k, ts := range m.dedupSeenAt {
 		if ts.Before(cutoff) {
 			delete(m.dedupSeenAt, k)
 		}
 	}
-	if ts, ok := m.dedupSeenAt[key]; ok && now.Sub(ts) <= m.policy.DedupWindow {
+	if ts, ok := m.dedupSeenAt[key]; ok && now.Sub(ts) <= window {
 		return key, true
 	}
 	m.dedupSeenAt[key] = now
@@ -318,7 +484,7 @@ func (m *Manager) beginDedup(outbound *bus.OutboundMessage) (string, bool) {
 }
 
 func (m *Manager) confirmDedup(key string) {
-	if key == "" || m.policy.DedupWindow <= 0 {
+	if key == "" {
 		return
 	}
 	m.dedupMu.Lock()
@@ -327,7 +493,7 @@ func (m *Manager) confirmDedup(key string) {
 }
 
 func (m *Manager) releaseDedup(key string) {
-	if key == "" || m.policy.DedupWindow <= 0 {
+	if key == "" {
 		return
 	}
 	m.dedupMu.Lock()
@@ -369,3 +369,160 @@ func TestManager_RouteOutbound_DedupDoesNotBlockRetryAfterFailure(t *testing.T)
 		t.Fatalf("expected second publish to send after first failure, got sends=%d", got)
 	}
 }
+
+func TestManager_SetDeliveryPolicy_ReplacesPolicy(t *testing.T) {
+	mgr := NewManager(bus.NewMessageBus(1))
+
+	mgr.SetDeliveryPolicy(DeliveryPolicy{
+		MaxConcurrentSends: 4,
+		RetryMaxAttempts:   5,
+		RateLimitPerSecond: 100,
+	})
+
+	policy := mgr.Policy()
+	if policy.MaxConcurrentSends != 4 || policy.RetryMaxAttempts != 5 || policy.RateLimitPerSecond != 100 {
+		t.Fatalf("unexpected policy after update: %+v", policy)
+	}
+	if policy.DedupWindow != 30*time.Second {
+		t.Fatalf("expected unset fields to be normalized, got dedup window %s", policy.DedupWindow)
+	}
+	if got := cap(mgr.sendSemaphore()); got != 4 {
+		t.Fatalf("expected send semaphore capacity 4, got %d", got)
+	}
+}
+
+func TestManager_UnregisterStopsAndRemovesChannel(t *testing.T) {
+	mgr := NewManager(bus.NewMessageBus(1))
+	ch := &mockManagerChannel{name: "mock"}
+	mgr.Register(ch)
+
+	if err := mgr.Unregister(context.Background(), "mock"); err != nil {
+		t.Fatalf("Unregister error: %v", err)
+	}
+	if !ch.stopped {
+		t.Fatal("expected channel to be stopped")
+	}
+	if names := mgr.Names(); len(names) != 0 {
+		t.Fatalf("expected no registered channels, got %v", names)
+	}
+	if err := mgr.Unregister(context.Background(), "mock"); err != nil {
+		t.Fatalf("expected unregistering unknown channel to be a no-op, got %v", err)
+	}
+	if err := mgr.Start(context.Background(), "mock"); err == nil {
+		t.Fatal("expected starting an unregistered channel to fail")
+	}
+}
+
+type typingManagerChannel struct {
+	mockManagerChannel
+	typingChats []string
+}
+
+func (m *typingManagerChannel) SendTyping(ctx context.Context, chatID string) error {
+	m.typingChats = append(m.typingChats, chatID)
+	return nil
+}
+
+func TestManager_SendTypingUsesOptionalInterface(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	mgr := NewManager(msgBus)
+
+	typing := &typingManagerChannel{mockManagerChannel: mockManagerChannel{name: "typing"}}
+	plain := &mockManagerChannel{name: "plain"}
+	mgr.Register(typing)
+	mgr.Register(plain)
+
+	mgr.SendTyping(context.Background(), "typing", "chat-1")
+	mgr.SendTyping(context.Background(), "plain", "chat-2")
+	mgr.SendTyping(context.Background(), "missing", "chat-3")
+
+	if len(typing.typingChats) != 1 || typing.typingChats[0] != "chat-1" {
+		t.Fatalf("expected typing indicator for chat-1, got %v", typing.typingChats)
+	}
+	if plain.sent.Load() != 0 {
+		t.Fatal("expected channels without typing support to be left alone")
+	}
+}
+
+type reactingManagerChannel struct {
+	mockManagerChannel
+	reactions []string
+}
+
+func (m *reactingManagerChannel) React(ctx context.Context, chatID, messageID, emoji string) error {
+	m.reactions = append(m.reactions, chatID+"/"+messageID+":"+emoji)
+	return nil
+}
+
+func TestManager_ReactUsesOptionalInterface(t *testing.T) {
+	mgr := NewManager(bus.NewMessageBus(1))
+	reacting := &reactingManagerChannel{mockManagerChannel: mockManagerChannel{name: "reacting"}}
+	mgr.Register(reacting)
+	mgr.Register(&mockManagerChannel{name: "plain"})
+
+	mgr.React(context.Background(), "reacting", "chat-1", "m1", "👀")
+	mgr.React(context.Background(), "plain", "chat-2", "m2", "👀")
+	mgr.React(context.Background(), "missing", "chat-3", "m3", "👀")
+
+	if len(reacting.reactions) != 1 || reacting.reactions[0] != "chat-1/m1:👀" {
+		t.Fatalf("expected a single reaction on chat-1/m1, got %v", reacting.reactions)
+	}
+}
+
+type limitedManagerChannel struct {
+	mockManagerChannel
+}
+
+func (m *limitedManagerChannel) MaxAttachmentBytes() int64 { return 1024 }
+
+func TestManager_AttachmentLimitUsesOptionalInterface(t *testing.T) {
+	mgr := NewManager(bus.NewMessageBus(1))
+	mgr.Register(&limitedManagerChannel{mockManagerChannel: mockManagerChannel{name: "limited"}})
+	mgr.Register(&mockManagerChannel{name: "plain"})
+
+	if limit, ok := mgr.AttachmentLimit("limited"); !ok || limit != 1024 {
+		t.Fatalf("expected limit 1024, got %d, %v", limit, ok)
+	}
+	for _, name := range []string{"plain", "missing"} {
+		if _, ok := mgr.AttachmentLimit(name); ok {
+			t.Fatalf("expected no known limit for %s", name)
+		}
+	}
+}
+
+type failingStartChannel struct {
+	mockManagerChannel
+	startErr error
+}
+
+func (f *failingStartChannel) Start(ctx context.Context) error { return f.startErr }
+
+func TestManager_StatusesReportStartFailures(t *testing.T) {
+	mgr := NewManager(bus.NewMessageBus(1))
+	mgr.Register(&mockManagerChannel{name: "ok"})
+	mgr.Register(&failingStartChannel{mockManagerChannel: mockManagerChannel{name: "bad"}, startErr: errors.New("invalid token")})
+	mgr.StartAll(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	var statuses []Status
+	for time.Now().Before(deadline) {
+		statuses = mgr.Statuses()
+		if len(statuses) == 2 && !statuses[0].Ready {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(statuses) != 2 || statuses[0].Name != "bad" || statuses[0].Ready || statuses[0].Error != "invalid token" {
+		t.Fatalf("expected bad channel to report start failure, got %+v", statuses)
+	}
+	if statuses[1].Name != "ok" || !statuses[1].Ready {
+		t.Fatalf("expected ok channel ready, got %+v", statuses[1])
+	}
+
+	if err := mgr.Unregister(context.Background(), "bad"); err != nil {
+		t.Fatalf("Unregister error: %v", err)
+	}
+	if statuses := mgr.Statuses(); len(statuses) != 1 || !statuses[0].Ready {
+		t.Fatalf("expected only ready channel after unregister, got %+v", statuses)
+	}
+}
@@ -0,0 +1,389 @@
+// Package matrix 通过 Matrix Client-Server API 接入 Matrix 房间。
+//
+// 当前仅支持未加密房间：端到端加密（E2EE）房间中的 m.room.encrypted 事件无法解密，
+// 会被记录日志后忽略，机器人也不会向加密房间发送加密消息。需要在加密房间使用时，
+// 请为机器人单独创建未启用加密的房间。
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+const (
+	maxMessageLength  = 16000 // 单个事件上限为 64KB，按最坏的 4 字节 UTF-8 留足余量
+	syncTimeout       = 30 * time.Second
+	typingTimeout     = 5 * time.Second
+	retryMinBackoff   = time.Second
+	retryMaxBackoff   = 30 * time.Second
+	maxResponseBytes  = 16 << 20
+	clientAPIBasePath = "/_matrix/client/v3"
+)
+
+// Channel implements a Matrix channel backed by the /sync long-poll API.
+type Channel struct {
+	channel.BaseChannel
+	cfg        *config.MatrixConfig
+	httpClient *http.Client
+	txnSeq     atomic.Uint64
+
+	mu        sync.RWMutex
+	running   bool
+	baseURL   string
+	userID    string
+	since     string
+	cancelRun context.CancelFunc
+	done      chan struct{}
+}
+
+// New creates a Matrix channel instance.
+func New(cfg *config.MatrixConfig, msgBus *bus.MessageBus) *Channel {
+	allowList := make(map[string]bool)
+	for _, id := range cfg.AllowFrom {
+		allowList[id] = true
+	}
+	return &Channel{
+		BaseChannel: channel.BaseChannel{Bus: msgBus, AllowList: allowList},
+		cfg:         cfg,
+		// 长轮询本身最长持续 syncTimeout，客户端超时需要留出余量。
+		httpClient: httpclient.New(syncTimeout + 30*time.Second),
+	}
+}
+
+func (c *Channel) Name() string { return "matrix" }
+
+// Start 校验令牌并做一次初始同步以跳过历史消息，随后在后台持续 /sync。
+func (c *Channel) Start(ctx context.Context) error {
+	if c.cfg == nil {
+		return fmt.Errorf("missing matrix config")
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(c.cfg.Homeserver), "/")
+	if baseURL == "" {
+		return fmt.Errorf("matrix homeserver is empty")
+	}
+	if strings.TrimSpace(c.cfg.AccessToken) == "" {
+		return fmt.Errorf("matrix access_token is empty")
+	}
+
+	c.mu.Lock()
+	c.baseURL = baseURL
+	c.mu.Unlock()
+
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.api(ctx, http.MethodGet, "/account/whoami", nil, &whoami); err != nil {
+		return fmt.Errorf("matrix auth failed: %w", err)
+	}
+
+	// 初始同步只取 next_batch，不处理启动前积压的消息。
+	initial, err := c.sync(ctx, "", 0)
+	if err != nil {
+		return fmt.Errorf("matrix initial sync failed: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	c.mu.Lock()
+	c.userID = whoami.UserID
+	c.since = initial.NextBatch
+	c.running = true
+	c.cancelRun = cancel
+	c.done = done
+	c.mu.Unlock()
+
+	go c.syncLoop(runCtx, done)
+	slog.Info("matrix client connected", "user_id", whoami.UserID)
+	return nil
+}
+
+func (c *Channel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if c.cancelRun != nil {
+		c.cancelRun()
+		c.cancelRun = nil
+	}
+	done := c.done
+	c.done = nil
+	c.running = false
+	c.mu.Unlock()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// SendTyping 在房间中显示“正在输入”提示。
+func (c *Channel) SendTyping(ctx context.Context, chatID string) error {
+	c.mu.RLock()
+	running := c.running
+	userID := c.userID
+	c.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("matrix channel not running")
+	}
+	if strings.TrimSpace(chatID) == "" {
+		return fmt.Errorf("invalid matrix room id: %q", chatID)
+	}
+
+	path := "/rooms/" + url.PathEscape(chatID) + "/typing/" + url.PathEscape(userID)
+	payload := map[string]any{"typing": true, "timeout": typingTimeout.Milliseconds()}
+	return c.api(ctx, http.MethodPut, path, payload, nil)
+}
+
+// Send 以 m.text 事件发送出站消息，超过单条长度上限时拆分为多条依次发送。附件暂不支持。
+func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
+	c.mu.RLock()
+	running := c.running
+	c.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("matrix channel not running")
+	}
+
+	roomID := strings.TrimSpace(msg.ChatID)
+	if roomID == "" {
+		return fmt.Errorf("invalid matrix room id: %q", msg.ChatID)
+	}
+
+	for _, chunk := range channel.SplitMessage(msg.Content, maxMessageLength) {
+		path := "/rooms/" + url.PathEscape(roomID) + "/send/m.room.message/" + url.PathEscape(c.nextTxnID())
+		payload := map[string]string{"msgtype": "m.text", "body": chunk}
+		if err := c.api(ctx, http.MethodPut, path, payload, nil); err != nil {
+			return fmt.Errorf("send matrix message: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextTxnID 生成本进程内唯一的事务 ID，服务端据此对重复提交去重。
+func (c *Channel) nextTxnID() string {
+	return "golem-" + strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(c.txnSeq.Add(1), 10)
+}
+
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []roomEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]struct {
+			InviteState struct {
+				Events []roomEvent `json:"events"`
+			} `json:"invite_state"`
+		} `json:"invite"`
+	} `json:"rooms"`
+}
+
+type roomEvent struct {
+	Type     string `json:"type"`
+	EventID  string `json:"event_id"`
+	Sender   string `json:"sender"`
+	StateKey string `json:"state_key"`
+	Content  struct {
+		MsgType    string `json:"msgtype"`
+		Body       string `json:"body"`
+		URL        string `json:"url"`
+		Membership string `json:"membership"`
+	} `json:"content"`
+}
+
+func (c *Channel) sync(ctx context.Context, since string, timeout time.Duration) (*syncResponse, error) {
+	query := url.Values{}
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+	if since != "" {
+		query.Set("since", since)
+	} else {
+		// 初始同步不需要时间线内容。
+		query.Set("filter", `{"room":{"timeline":{"limit":0}}}`)
+	}
+	var resp syncResponse
+	if err := c.api(ctx, http.MethodGet, "/sync?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// syncLoop 持续长轮询 /sync；请求失败时按指数退避重试，直到 ctx 被取消。
+func (c *Channel) syncLoop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	backoff := retryMinBackoff
+	for ctx.Err() == nil {
+		c.mu.RLock()
+		since := c.since
+		c.mu.RUnlock()
+
+		resp, err := c.sync(ctx, since, syncTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("matrix sync failed", "error", err)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			backoff = min(backoff*2, retryMaxBackoff)
+			continue
+		}
+		backoff = retryMinBackoff
+
+		c.mu.Lock()
+		c.since = resp.NextBatch
+		c.mu.Unlock()
+		c.handleSync(ctx, resp)
+	}
+}
+
+func (c *Channel) handleSync(ctx context.Context, resp *syncResponse) {
+	c.mu.RLock()
+	userID := c.userID
+	c.mu.RUnlock()
+
+	// 仅接受允许名单内用户发来的邀请，避免机器人被拉进任意房间。
+	for roomID, room := range resp.Rooms.Invite {
+		inviter := ""
+		for _, ev := range room.InviteState.Events {
+			if ev.Type == "m.room.member" && ev.StateKey == userID && ev.Content.Membership == "invite" {
+				inviter = ev.Sender
+			}
+		}
+		if inviter == "" || !c.IsAllowed(inviter) {
+			continue
+		}
+		if err := c.api(ctx, http.MethodPost, "/rooms/"+url.PathEscape(roomID)+"/join", map[string]any{}, nil); err != nil {
+			slog.Warn("matrix join failed", "room_id", roomID, "error", err)
+		}
+	}
+
+	for roomID, room := range resp.Rooms.Join {
+		for _, ev := range room.Timeline.Events {
+			c.handleRoomEvent(roomID, userID, ev)
+		}
+	}
+}
+
+func (c *Channel) handleRoomEvent(roomID, selfID string, ev roomEvent) {
+	if ev.Sender == "" || ev.Sender == selfID {
+		return
+	}
+	if ev.Type == "m.room.encrypted" {
+		slog.Debug("matrix encrypted event ignored (E2EE not supported)", "room_id", roomID, "event_id", ev.EventID)
+		return
+	}
+	if ev.Type != "m.room.message" {
+		return
+	}
+	if !c.IsAllowed(ev.Sender) {
+		return
+	}
+
+	content := strings.TrimSpace(ev.Content.Body)
+	media := make([]string, 0)
+	switch ev.Content.MsgType {
+	case "m.text", "m.notice", "m.emote":
+	case "m.image", "m.file", "m.audio", "m.video":
+		if ev.Content.URL != "" {
+			media = append(media, ev.Content.URL)
+			content = fmt.Sprintf("[attachment: %s]", ev.Content.URL)
+		}
+	default:
+		return
+	}
+	if content == "" {
+		return
+	}
+
+	c.PublishInbound(&bus.InboundMessage{
+		Channel:   c.Name(),
+		SenderID:  ev.Sender,
+		ChatID:    roomID,
+		Content:   content,
+		Timestamp: time.Now(),
+		Media:     media,
+		Metadata: map[string]any{
+			"event_id": ev.EventID,
+			"room_id":  roomID,
+			"msgtype":  ev.Content.MsgType,
+		},
+		RequestID: bus.NewRequestID(),
+	})
+}
+
+func (c *Channel) api(ctx context.Context, method, path string, payload, out any) error {
+	c.mu.RLock()
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+clientAPIBasePath+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.AccessToken)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	endpoint, _, _ := strings.Cut(path, "?")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			ErrCode string `json:"errcode"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.ErrCode != "" {
+			return fmt.Errorf("%s %s: status %d: %s: %s", method, endpoint, resp.StatusCode, apiErr.ErrCode, apiErr.Error)
+		}
+		return fmt.Errorf("%s %s: status %d", method, endpoint, resp.StatusCode)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode %s response: %w", endpoint, err)
+		}
+	}
+	return nil
+}
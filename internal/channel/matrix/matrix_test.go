@@ -0,0 +1,166 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+type fakeHomeserver struct {
+	*httptest.Server
+	mu      sync.Mutex
+	batches []string // 依次作为增量 /sync 的响应体
+	sent    []map[string]string
+	joined  []string
+}
+
+func newFakeHomeserver(t *testing.T, batches ...string) *fakeHomeserver {
+	t.Helper()
+	hs := &fakeHomeserver{batches: batches}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_matrix/client/v3/account/whoami", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"errcode":"M_UNKNOWN_TOKEN","error":"Invalid access token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"user_id":"@golem:example.org"}`))
+	})
+	mux.HandleFunc("/_matrix/client/v3/sync", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "" {
+			// 初始同步带有历史消息，应当被跳过。
+			_, _ = w.Write([]byte(`{"next_batch":"s0","rooms":{"join":{"!old:example.org":{"timeline":{"events":[
+				{"type":"m.room.message","event_id":"$old","sender":"@alice:example.org","content":{"msgtype":"m.text","body":"backlog"}}]}}}}}`))
+			return
+		}
+		hs.mu.Lock()
+		var body string
+		if len(hs.batches) > 0 {
+			body, hs.batches = hs.batches[0], hs.batches[1:]
+		}
+		hs.mu.Unlock()
+		if body == "" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			_, _ = w.Write([]byte(`{"next_batch":"` + r.URL.Query().Get("since") + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	mux.HandleFunc("/_matrix/client/v3/rooms/", func(w http.ResponseWriter, r *http.Request) {
+		hs.mu.Lock()
+		defer hs.mu.Unlock()
+		switch {
+		case strings.Contains(r.URL.Path, "/send/m.room.message/"):
+			var payload map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			hs.sent = append(hs.sent, payload)
+			_, _ = w.Write([]byte(`{"event_id":"$sent"}`))
+		case strings.HasSuffix(r.URL.Path, "/join"):
+			hs.joined = append(hs.joined, r.URL.Path)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	})
+	hs.Server = httptest.NewServer(mux)
+	t.Cleanup(hs.Close)
+	return hs
+}
+
+func startChannel(t *testing.T, hs *fakeHomeserver, cfg *config.MatrixConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	msgBus := bus.NewMessageBus(4)
+	cfg.Homeserver = hs.URL
+	cfg.AccessToken = "tok"
+	ch := New(cfg, msgBus)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, msgBus
+}
+
+func TestStart_InvalidTokenFails(t *testing.T) {
+	hs := newFakeHomeserver(t)
+	ch := New(&config.MatrixConfig{Homeserver: hs.URL, AccessToken: "bad"}, bus.NewMessageBus(1))
+	err := ch.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "M_UNKNOWN_TOKEN") {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+}
+
+func TestSync_PublishesRoomMessages(t *testing.T) {
+	hs := newFakeHomeserver(t, `{"next_batch":"s1","rooms":{"join":{"!room:example.org":{"timeline":{"events":[
+		{"type":"m.room.message","event_id":"$self","sender":"@golem:example.org","content":{"msgtype":"m.text","body":"echo"}},
+		{"type":"m.room.encrypted","event_id":"$enc","sender":"@alice:example.org","content":{}},
+		{"type":"m.room.message","event_id":"$bob","sender":"@bob:example.org","content":{"msgtype":"m.text","body":"blocked"}},
+		{"type":"m.room.message","event_id":"$1","sender":"@alice:example.org","content":{"msgtype":"m.text","body":"hello"}}]}}}}}`)
+	_, msgBus := startChannel(t, hs, &config.MatrixConfig{AllowFrom: []string{"@alice:example.org"}})
+
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Channel != "matrix" || msg.ChatID != "!room:example.org" || msg.SenderID != "@alice:example.org" || msg.Content != "hello" {
+			t.Fatalf("unexpected inbound: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for inbound message")
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("unexpected extra inbound: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSync_JoinsInvitesFromAllowedUsers(t *testing.T) {
+	hs := newFakeHomeserver(t, `{"next_batch":"s1","rooms":{"invite":{
+		"!ok:example.org":{"invite_state":{"events":[{"type":"m.room.member","sender":"@alice:example.org","state_key":"@golem:example.org","content":{"membership":"invite"}}]}},
+		"!spam:example.org":{"invite_state":{"events":[{"type":"m.room.member","sender":"@eve:example.org","state_key":"@golem:example.org","content":{"membership":"invite"}}]}}}}}`)
+	startChannel(t, hs, &config.MatrixConfig{AllowFrom: []string{"@alice:example.org"}})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		hs.mu.Lock()
+		joined := append([]string(nil), hs.joined...)
+		hs.mu.Unlock()
+		if len(joined) > 0 {
+			if len(joined) != 1 || !strings.Contains(joined[0], "!ok:example.org") {
+				t.Fatalf("unexpected joins: %v", joined)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for join")
+}
+
+func TestSend_SplitsIntoTextEvents(t *testing.T) {
+	hs := newFakeHomeserver(t)
+	ch, _ := startChannel(t, hs, &config.MatrixConfig{})
+
+	content := strings.Repeat("a", maxMessageLength+1)
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "!room:example.org", Content: content}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if len(hs.sent) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(hs.sent))
+	}
+	for _, ev := range hs.sent {
+		if ev["msgtype"] != "m.text" {
+			t.Fatalf("unexpected event: %v", ev)
+		}
+	}
+}
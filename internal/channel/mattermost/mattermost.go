@@ -0,0 +1,538 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	maxMessageLength    = 16383             // Mattermost 服务端默认的单条消息字符上限
+	maxUploadBytes      = 100 * 1024 * 1024 // 服务端默认 MaxFileSize (100MB)
+	maxFilesPerPost     = 5                 // 单条消息可携带的附件数
+	reconnectMinBackoff = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// Channel implements a Mattermost bot channel over the WebSocket event stream and REST API v4.
+type Channel struct {
+	channel.BaseChannel
+	cfg        *config.MattermostConfig
+	httpClient *http.Client
+	dialer     *websocket.Dialer
+
+	mu          sync.RWMutex
+	running     bool
+	baseURL     string
+	botUserID   string
+	botUsername string
+	conn        *websocket.Conn
+	cancelRun   context.CancelFunc
+}
+
+// New creates a Mattermost channel instance.
+func New(cfg *config.MattermostConfig, msgBus *bus.MessageBus) *Channel {
+	allowList := make(map[string]bool)
+	for _, id := range cfg.AllowFrom {
+		allowList[id] = true
+	}
+	return &Channel{
+		BaseChannel: channel.BaseChannel{Bus: msgBus, AllowList: allowList},
+		cfg:         cfg,
+		httpClient:  httpclient.New(httpclient.Timeout(45 * time.Second)),
+		dialer:      &websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: httpclient.Proxy},
+	}
+}
+
+func (c *Channel) Name() string { return "mattermost" }
+
+// Start 校验凭据、获取机器人自身信息并建立 WebSocket 连接；断线后在后台自动重连。
+func (c *Channel) Start(ctx context.Context) error {
+	if c.cfg == nil {
+		return fmt.Errorf("missing mattermost config")
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(c.cfg.URL), "/")
+	if baseURL == "" {
+		return fmt.Errorf("mattermost url is empty")
+	}
+	if strings.TrimSpace(c.cfg.Token) == "" {
+		return fmt.Errorf("mattermost token is empty")
+	}
+
+	c.mu.Lock()
+	c.baseURL = baseURL
+	c.mu.Unlock()
+
+	var me struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := c.apiJSON(ctx, http.MethodGet, "/users/me", nil, &me); err != nil {
+		return fmt.Errorf("mattermost auth failed: %w", err)
+	}
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to mattermost websocket: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.botUserID = me.ID
+	c.botUsername = me.Username
+	c.conn = conn
+	c.running = true
+	c.cancelRun = cancel
+	c.mu.Unlock()
+
+	go c.run(runCtx, conn)
+	slog.Info("mattermost bot connected", "username", me.Username)
+	return nil
+}
+
+func (c *Channel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if c.cancelRun != nil {
+		c.cancelRun()
+		c.cancelRun = nil
+	}
+	conn := c.conn
+	c.conn = nil
+	c.running = false
+	c.mu.Unlock()
+
+	if conn != nil {
+		_ = conn.Close()
+	}
+	return nil
+}
+
+// SendTyping 在频道（或线程）中显示“正在输入”提示。
+func (c *Channel) SendTyping(ctx context.Context, chatID string) error {
+	c.mu.RLock()
+	running := c.running
+	botUserID := c.botUserID
+	c.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("mattermost channel not running")
+	}
+
+	channelID, rootID := parseChatID(chatID)
+	if strings.TrimSpace(channelID) == "" {
+		return fmt.Errorf("invalid mattermost chat id: %q", chatID)
+	}
+	payload := map[string]string{"channel_id": channelID, "parent_id": rootID}
+	return c.apiJSON(ctx, http.MethodPost, "/users/"+url.PathEscape(botUserID)+"/typing", payload, nil)
+}
+
+// MaxAttachmentBytes 返回服务端默认的 MaxFileSize；服务端调小该设置时上传仍可能被拒绝。
+func (c *Channel) MaxAttachmentBytes() int64 { return maxUploadBytes }
+
+// Send 发送出站消息，超过单条长度上限时拆分为多条依次发送；附件先上传，再随最后一条消息发出。
+func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
+	c.mu.RLock()
+	running := c.running
+	c.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("mattermost channel not running")
+	}
+
+	channelID, rootID := parseChatID(msg.ChatID)
+	if strings.TrimSpace(channelID) == "" {
+		return fmt.Errorf("invalid mattermost chat id: %q", msg.ChatID)
+	}
+
+	if err := channel.CheckAttachments("mattermost", msg.Attachments, maxUploadBytes); err != nil {
+		return err
+	}
+
+	fileIDs := make([]string, 0, len(msg.Attachments))
+	for _, att := range msg.Attachments {
+		id, err := c.uploadFile(ctx, channelID, att)
+		if err != nil {
+			return err
+		}
+		fileIDs = append(fileIDs, id)
+	}
+
+	chunks := []string{}
+	if strings.TrimSpace(msg.Content) != "" || len(fileIDs) == 0 {
+		chunks = channel.SplitMessage(msg.Content, maxMessageLength)
+	}
+	for i, chunk := range chunks {
+		var attach []string
+		if i == len(chunks)-1 {
+			attach, fileIDs = takeFileIDs(fileIDs)
+		}
+		if err := c.createPost(ctx, channelID, rootID, chunk, attach); err != nil {
+			return err
+		}
+	}
+	for len(fileIDs) > 0 {
+		var attach []string
+		attach, fileIDs = takeFileIDs(fileIDs)
+		if err := c.createPost(ctx, channelID, rootID, "", attach); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func takeFileIDs(ids []string) (batch, rest []string) {
+	if len(ids) <= maxFilesPerPost {
+		return ids, nil
+	}
+	return ids[:maxFilesPerPost], ids[maxFilesPerPost:]
+}
+
+func (c *Channel) createPost(ctx context.Context, channelID, rootID, message string, fileIDs []string) error {
+	payload := map[string]any{
+		"channel_id": channelID,
+		"message":    message,
+	}
+	if rootID != "" {
+		payload["root_id"] = rootID
+	}
+	if len(fileIDs) > 0 {
+		payload["file_ids"] = fileIDs
+	}
+	if err := c.apiJSON(ctx, http.MethodPost, "/posts", payload, nil); err != nil {
+		return fmt.Errorf("send mattermost message: %w", err)
+	}
+	return nil
+}
+
+// uploadFile 通过 POST /files 上传附件，返回供消息引用的文件 ID。
+func (c *Channel) uploadFile(ctx context.Context, channelID string, att bus.OutboundAttachment) (string, error) {
+	reader, err := att.Open()
+	if err != nil {
+		return "", fmt.Errorf("open attachment %q: %w", att.Name(), err)
+	}
+	defer reader.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("channel_id", channelID); err != nil {
+		return "", err
+	}
+	part, err := writer.CreateFormFile("files", att.Name())
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, reader); err != nil {
+		return "", fmt.Errorf("read attachment %q: %w", att.Name(), err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		FileInfos []struct {
+			ID string `json:"id"`
+		} `json:"file_infos"`
+	}
+	if err := c.api(ctx, http.MethodPost, "/files", writer.FormDataContentType(), &body, &resp); err != nil {
+		return "", fmt.Errorf("upload mattermost file %q: %w", att.Name(), err)
+	}
+	if len(resp.FileInfos) == 0 || resp.FileInfos[0].ID == "" {
+		return "", fmt.Errorf("upload mattermost file %q: empty file_infos", att.Name())
+	}
+	return resp.FileInfos[0].ID, nil
+}
+
+func (c *Channel) apiJSON(ctx context.Context, method, path string, payload, out any) error {
+	var body io.Reader
+	contentType := ""
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	return c.api(ctx, method, path, contentType, body, out)
+}
+
+func (c *Channel) api(ctx context.Context, method, path, contentType string, body io.Reader, out any) error {
+	c.mu.RLock()
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+"/api/v4"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode %s response: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (c *Channel) dial(ctx context.Context) (*websocket.Conn, error) {
+	c.mu.RLock()
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+
+	wsURL, err := websocketURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+c.cfg.Token)
+	conn, _, err := c.dialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+func websocketURL(baseURL string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid mattermost url: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("invalid mattermost url scheme: %q", u.Scheme)
+	}
+	u.Path = strings.TrimRight(u.Path, "/") + "/api/v4/websocket"
+	return u.String(), nil
+}
+
+// run 读取事件直到连接断开，随后按指数退避重连，直到 ctx 被取消。
+func (c *Channel) run(ctx context.Context, conn *websocket.Conn) {
+	backoff := reconnectMinBackoff
+	for {
+		c.readLoop(ctx, conn)
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			backoff = min(backoff*2, reconnectMaxBackoff)
+
+			next, err := c.dial(ctx)
+			if err != nil {
+				slog.Warn("mattermost reconnect failed", "error", err)
+				continue
+			}
+			c.mu.Lock()
+			if ctx.Err() != nil {
+				c.mu.Unlock()
+				_ = next.Close()
+				return
+			}
+			c.conn = next
+			c.mu.Unlock()
+			conn = next
+			backoff = reconnectMinBackoff
+			slog.Info("mattermost websocket reconnected")
+			break
+		}
+	}
+}
+
+func (c *Channel) readLoop(ctx context.Context, conn *websocket.Conn) {
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("mattermost read failed", "error", err)
+			}
+			return
+		}
+		c.handleEvent(raw)
+	}
+}
+
+type wsEvent struct {
+	Event string `json:"event"`
+	Data  struct {
+		ChannelType string `json:"channel_type"`
+		Post        string `json:"post"`
+		SenderName  string `json:"sender_name"`
+		Mentions    string `json:"mentions"`
+	} `json:"data"`
+}
+
+type post struct {
+	ID        string   `json:"id"`
+	UserID    string   `json:"user_id"`
+	ChannelID string   `json:"channel_id"`
+	RootID    string   `json:"root_id"`
+	Message   string   `json:"message"`
+	Type      string   `json:"type"`
+	FileIDs   []string `json:"file_ids"`
+}
+
+// handleEvent 处理 posted 事件：私聊消息全部接收，频道消息仅在 @ 机器人时接收并在线程中回复。
+func (c *Channel) handleEvent(raw []byte) {
+	var evt wsEvent
+	if err := json.Unmarshal(raw, &evt); err != nil {
+		slog.Warn("mattermost decode failed", "error", err)
+		return
+	}
+	if evt.Event != "posted" || evt.Data.Post == "" {
+		return
+	}
+	var p post
+	if err := json.Unmarshal([]byte(evt.Data.Post), &p); err != nil {
+		slog.Warn("mattermost decode post failed", "error", err)
+		return
+	}
+
+	c.mu.RLock()
+	botUserID := c.botUserID
+	botUsername := c.botUsername
+	baseURL := c.baseURL
+	c.mu.RUnlock()
+
+	// 忽略机器人自己的消息和系统消息（加入频道等）。
+	if p.UserID == "" || p.UserID == botUserID || p.Type != "" {
+		return
+	}
+
+	isDirect := evt.Data.ChannelType == "D"
+	mentioned := mentionsUser(evt.Data.Mentions, botUserID)
+	if !isDirect && !mentioned {
+		return
+	}
+
+	senderID := p.UserID
+	if username := strings.TrimPrefix(strings.TrimSpace(evt.Data.SenderName), "@"); username != "" {
+		senderID = p.UserID + "|" + username
+	}
+	if !c.IsAllowed(senderID) {
+		return
+	}
+
+	content := strings.TrimSpace(stripMention(p.Message, botUsername))
+	media := make([]string, 0, len(p.FileIDs))
+	for _, id := range p.FileIDs {
+		fileURL := baseURL + "/api/v4/files/" + id
+		media = append(media, fileURL)
+		content = appendLine(content, fmt.Sprintf("[attachment: %s]", fileURL))
+	}
+	if content == "" {
+		return
+	}
+
+	chatID := p.ChannelID
+	if p.RootID != "" {
+		chatID = p.ChannelID + "/" + p.RootID
+	} else if !isDirect {
+		chatID = p.ChannelID + "/" + p.ID
+	}
+
+	c.PublishInbound(&bus.InboundMessage{
+		Channel:   c.Name(),
+		SenderID:  senderID,
+		ChatID:    chatID,
+		Content:   content,
+		Timestamp: time.Now(),
+		Media:     media,
+		Metadata: map[string]any{
+			"post_id":      p.ID,
+			"channel_id":   p.ChannelID,
+			"root_id":      p.RootID,
+			"channel_type": evt.Data.ChannelType,
+			"is_mention":   mentioned,
+		},
+		RequestID: bus.NewRequestID(),
+	})
+}
+
+func mentionsUser(mentions, userID string) bool {
+	if mentions == "" || userID == "" {
+		return false
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(mentions), &ids); err != nil {
+		return false
+	}
+	for _, id := range ids {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func stripMention(text, username string) string {
+	if username == "" {
+		return strings.TrimSpace(text)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(text, "@"+username, ""))
+}
+
+func parseChatID(chatID string) (channelID, rootID string) {
+	parts := strings.SplitN(chatID, "/", 2)
+	channelID = parts[0]
+	if len(parts) > 1 {
+		rootID = parts[1]
+	}
+	return channelID, rootID
+}
+
+func appendLine(base, suffix string) string {
+	if base == "" {
+		return suffix
+	}
+	return base + "\n" + suffix
+}
@@ -0,0 +1,222 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+type fakeServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	posts  []map[string]any
+	events chan []byte
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	fs := &fakeServer{events: make(chan []byte, 8)}
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"invalid token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"bot1","username":"golem"}`))
+	})
+	mux.HandleFunc("/api/v4/websocket", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for raw := range fs.events {
+			if err := conn.WriteMessage(websocket.TextMessage, raw); err != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/api/v4/posts", func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		fs.mu.Lock()
+		fs.posts = append(fs.posts, payload)
+		fs.mu.Unlock()
+		_, _ = w.Write([]byte(`{"id":"p1"}`))
+	})
+	mux.HandleFunc("/api/v4/files", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("channel_id") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"file_infos":[{"id":"f1"}]}`))
+	})
+	fs.Server = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(fs.events)
+		fs.Close()
+	})
+	return fs
+}
+
+func (fs *fakeServer) sentPosts() []map[string]any {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([]map[string]any(nil), fs.posts...)
+}
+
+func postedEvent(t *testing.T, channelType, mentions string, p post) []byte {
+	t.Helper()
+	postJSON, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(map[string]any{
+		"event": "posted",
+		"data": map[string]any{
+			"channel_type": channelType,
+			"post":         string(postJSON),
+			"sender_name":  "@alice",
+			"mentions":     mentions,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func startChannel(t *testing.T, fs *fakeServer, cfg *config.MattermostConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	msgBus := bus.NewMessageBus(4)
+	cfg.URL = fs.URL
+	cfg.Token = "tok"
+	ch := New(cfg, msgBus)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, msgBus
+}
+
+func waitInbound(t *testing.T, msgBus *bus.MessageBus) *bus.InboundMessage {
+	t.Helper()
+	select {
+	case msg := <-msgBus.Inbound():
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for inbound message")
+		return nil
+	}
+}
+
+func TestStart_InvalidTokenFails(t *testing.T) {
+	fs := newFakeServer(t)
+	ch := New(&config.MattermostConfig{URL: fs.URL, Token: "bad"}, bus.NewMessageBus(1))
+	err := ch.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Fatalf("expected auth error, got %v", err)
+	}
+}
+
+func TestDirectMessagePublishesInbound(t *testing.T) {
+	fs := newFakeServer(t)
+	_, msgBus := startChannel(t, fs, &config.MattermostConfig{})
+
+	fs.events <- postedEvent(t, "O", "", post{ID: "x0", UserID: "u1", ChannelID: "town", Message: "not for the bot"})
+	fs.events <- postedEvent(t, "D", "", post{ID: "bot-post", UserID: "bot1", ChannelID: "dm1", Message: "echo"})
+	fs.events <- postedEvent(t, "D", "", post{ID: "x1", UserID: "u1", ChannelID: "dm1", Message: "hello"})
+
+	msg := waitInbound(t, msgBus)
+	if msg.Channel != "mattermost" || msg.ChatID != "dm1" || msg.Content != "hello" {
+		t.Fatalf("unexpected inbound: %+v", msg)
+	}
+	if msg.SenderID != "u1|alice" {
+		t.Fatalf("sender = %q", msg.SenderID)
+	}
+}
+
+func TestMentionInChannelRepliesInThread(t *testing.T) {
+	fs := newFakeServer(t)
+	_, msgBus := startChannel(t, fs, &config.MattermostConfig{AllowFrom: []string{"alice"}})
+
+	fs.events <- postedEvent(t, "O", `["bot1"]`, post{ID: "root1", UserID: "u1", ChannelID: "town", Message: "@golem what time is it"})
+	msg := waitInbound(t, msgBus)
+	if msg.ChatID != "town/root1" || msg.Content != "what time is it" {
+		t.Fatalf("unexpected inbound: %+v", msg)
+	}
+
+	fs.events <- postedEvent(t, "O", `["bot1"]`, post{ID: "x2", UserID: "u1", ChannelID: "town", RootID: "root1", Message: "@golem and now?"})
+	msg = waitInbound(t, msgBus)
+	if msg.ChatID != "town/root1" {
+		t.Fatalf("thread reply chat id = %q", msg.ChatID)
+	}
+}
+
+func TestSendSplitsLongMessagesIntoThread(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := startChannel(t, fs, &config.MattermostConfig{})
+
+	content := strings.Repeat("a", maxMessageLength+10)
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "town/root1", Content: content}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	posts := fs.sentPosts()
+	if len(posts) != 2 {
+		t.Fatalf("expected 2 posts, got %d", len(posts))
+	}
+	for _, p := range posts {
+		if p["channel_id"] != "town" || p["root_id"] != "root1" {
+			t.Fatalf("unexpected post: %v", p)
+		}
+	}
+}
+
+func TestSendUploadsAttachments(t *testing.T) {
+	fs := newFakeServer(t)
+	ch, _ := startChannel(t, fs, &config.MattermostConfig{})
+
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:      "dm1",
+		Content:     "here you go",
+		Attachments: []bus.OutboundAttachment{{Path: path}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	posts := fs.sentPosts()
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+	ids, _ := posts[0]["file_ids"].([]any)
+	if len(ids) != 1 || ids[0] != "f1" || posts[0]["message"] != "here you go" {
+		t.Fatalf("unexpected post: %v", posts[0])
+	}
+}
+
+func TestWebsocketURL(t *testing.T) {
+	got, err := websocketURL("https://chat.example.com/mm")
+	if err != nil || got != "wss://chat.example.com/mm/api/v4/websocket" {
+		t.Fatalf("websocketURL = %q, %v", got, err)
+	}
+	if _, err := websocketURL("chat.example.com"); err == nil {
+		t.Fatal("expected error for url without scheme")
+	}
+}
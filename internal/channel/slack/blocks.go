@@ -0,0 +1,137 @@
+package slack
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/render"
+	"github.com/slack-go/slack"
+)
+
+const (
+	maxBlocksPerMessage = 50   // 单条消息的 Block 数量上限
+	maxBlockText        = 3000 // section 与 context 文本的字符数上限
+	maxHeaderText       = 150  // header 纯文本的字符数上限
+)
+
+var (
+	headingRe    = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*$`)
+	listItemRe   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	ruleRe       = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,})$`)
+	mdBoldRe     = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdStrikeRe   = regexp.MustCompile(`~~(.+?)~~`)
+	mdLinkRe     = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	mrkdwnEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// blockMessage 是一条 Block Kit 消息：blocks 为正文，text 为通知与不支持 Block 的客户端显示的纯文本。
+type blockMessage struct {
+	blocks []slack.Block
+	text   string
+}
+
+// blockBuilder 按顺序收集 Block 及其对应的源文本，用于分组成多条消息。
+type blockBuilder struct {
+	blocks  []slack.Block
+	sources []string
+}
+
+func (b *blockBuilder) add(block slack.Block, source string) {
+	b.blocks = append(b.blocks, block)
+	b.sources = append(b.sources, source)
+}
+
+func (b *blockBuilder) section(text string) {
+	for _, chunk := range channel.SplitMessage(text, maxBlockText) {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		b.add(slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil), chunk)
+	}
+}
+
+// buildBlockMessages 将 Markdown 回复转换为 Block Kit 消息：标题转为 header，代码块、段落与列表转为 section，
+// 分隔线转为 divider，<think> 思考过程转为置于开头的 context 块。超过单条消息 Block 数量上限时拆分为多条，
+// 过长的文本按 section 文本上限拆分。
+func buildBlockMessages(content string) []blockMessage {
+	var b blockBuilder
+	think, main, hasThink := render.SplitThink(content)
+	if hasThink && think != "" {
+		for _, chunk := range channel.SplitMessage("_Thinking:_ "+toMrkdwn(think), maxBlockText) {
+			b.add(slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false)), chunk)
+		}
+	}
+
+	lines := strings.Split(main, "\n")
+	var para []string
+	flush := func() {
+		if text := strings.TrimSpace(strings.Join(para, "\n")); text != "" {
+			b.section(toMrkdwn(text))
+		}
+		para = nil
+	}
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			if body := strings.Join(code, "\n"); strings.TrimSpace(body) != "" {
+				b.section("```\n" + mrkdwnEscape.Replace(body) + "\n```")
+			}
+		case headingRe.MatchString(trimmed):
+			flush()
+			heading := headingRe.FindStringSubmatch(trimmed)[1]
+			if utf8.RuneCountInString(heading) > maxHeaderText {
+				b.section("*" + mrkdwnEscape.Replace(heading) + "*")
+				continue
+			}
+			b.add(slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, heading, false, false)), heading)
+		case ruleRe.MatchString(trimmed):
+			flush()
+			b.add(slack.NewDividerBlock(), "")
+		case trimmed == "":
+			flush()
+		default:
+			para = append(para, lines[i])
+		}
+	}
+	flush()
+
+	var messages []blockMessage
+	for start := 0; start < len(b.blocks); start += maxBlocksPerMessage {
+		end := min(start+maxBlocksPerMessage, len(b.blocks))
+		text := strings.TrimSpace(strings.Join(b.sources[start:end], "\n"))
+		if utf8.RuneCountInString(text) > maxMessageLength {
+			text = string([]rune(text)[:maxMessageLength-1]) + "…"
+		}
+		messages = append(messages, blockMessage{blocks: b.blocks[start:end], text: text})
+	}
+	return messages
+}
+
+// toMrkdwn 将常见 Markdown 行内语法转换为 Slack mrkdwn：粗体、删除线、链接与无序列表符号；
+// 行内代码保持原样，其余文本转义 &、<、>。
+func toMrkdwn(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = listItemRe.ReplaceAllString(line, "$1• ")
+	}
+	parts := strings.Split(strings.Join(lines, "\n"), "`")
+	for i := range parts {
+		if i%2 == 1 && i < len(parts)-1 {
+			continue // 行内代码
+		}
+		s := mrkdwnEscape.Replace(parts[i])
+		s = mdLinkRe.ReplaceAllString(s, "<$2|$1>")
+		s = mdBoldRe.ReplaceAllString(s, "*$1$2*")
+		s = mdStrikeRe.ReplaceAllString(s, "~$1~")
+		parts[i] = s
+	}
+	return strings.Join(parts, "`")
+}
@@ -0,0 +1,102 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/slack-go/slack"
+)
+
+func TestBuildBlockMessages_MapsMarkdownStructure(t *testing.T) {
+	content := "<think>check the logs first</think>\n" +
+		"# Summary\n\nThe **deploy** failed, see [runbook](https://example.com/rb).\n\n" +
+		"- restart `api`\n- check <db>\n\n---\n\n```go\nfmt.Println(\"a < b\")\n```"
+
+	messages := buildBlockMessages(content)
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(messages))
+	}
+	blocks := messages[0].blocks
+	wantTypes := []slack.MessageBlockType{slack.MBTContext, slack.MBTHeader, slack.MBTSection, slack.MBTSection, slack.MBTDivider, slack.MBTSection}
+	if len(blocks) != len(wantTypes) {
+		t.Fatalf("expected %d blocks, got %d: %+v", len(wantTypes), len(blocks), blocks)
+	}
+	for i, want := range wantTypes {
+		if got := blocks[i].BlockType(); got != want {
+			t.Fatalf("block %d: expected %s, got %s", i, want, got)
+		}
+	}
+
+	if text := blocks[2].(*slack.SectionBlock).Text.Text; text != "The *deploy* failed, see <https://example.com/rb|runbook>." {
+		t.Fatalf("unexpected paragraph mrkdwn: %q", text)
+	}
+	if text := blocks[3].(*slack.SectionBlock).Text.Text; text != "• restart `api`\n• check &lt;db&gt;" {
+		t.Fatalf("unexpected list mrkdwn: %q", text)
+	}
+	if text := blocks[5].(*slack.SectionBlock).Text.Text; text != "```\nfmt.Println(\"a &lt; b\")\n```" {
+		t.Fatalf("unexpected code block: %q", text)
+	}
+	if !strings.Contains(messages[0].text, "Summary") {
+		t.Fatalf("expected plain-text fallback to include the content, got %q", messages[0].text)
+	}
+}
+
+func TestBuildBlockMessages_SplitsAtBlockAndTextLimits(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 60; i++ {
+		sb.WriteString("## Heading\n\n")
+	}
+	messages := buildBlockMessages(sb.String())
+	if len(messages) != 2 || len(messages[0].blocks) != maxBlocksPerMessage || len(messages[1].blocks) != 10 {
+		t.Fatalf("expected 50+10 blocks across two messages, got %d messages", len(messages))
+	}
+
+	long := strings.Repeat("word ", 1500)
+	messages = buildBlockMessages(long)
+	if len(messages) != 1 || len(messages[0].blocks) != 3 {
+		t.Fatalf("expected a long paragraph split into three sections, got %+v", messages)
+	}
+	for _, block := range messages[0].blocks {
+		if n := len([]rune(block.(*slack.SectionBlock).Text.Text)); n > maxBlockText {
+			t.Fatalf("section text exceeds limit: %d", n)
+		}
+	}
+}
+
+func TestSend_BlockKitFallsBackToPlainText(t *testing.T) {
+	var mu sync.Mutex
+	var posts []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mu.Lock()
+		posts = append(posts, map[string]string{"blocks": r.Form.Get("blocks"), "text": r.Form.Get("text")})
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Form.Get("blocks") != "" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_blocks"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": "C1", "ts": "1.1"})
+	}))
+	defer srv.Close()
+
+	ch := New(&config.SlackConfig{BlockKit: true}, bus.NewMessageBus(1), nil)
+	ch.api = slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))
+	ch.running = true
+
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "C1", Content: "# Done\n\nall **good**"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(posts) != 2 || posts[0]["blocks"] == "" || posts[1]["blocks"] != "" || posts[1]["text"] != "# Done\n\nall **good**" {
+		t.Fatalf("expected a rejected block post followed by plain text, got %+v", posts)
+	}
+}
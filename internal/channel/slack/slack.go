@@ -7,13 +7,16 @@ import (
 	"log/slog"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/channel"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/MEKXH/golem/internal/voice"
 	"github.com/slack-go/slack"
 	"github.com/slack-go/slack/slackevents"
@@ -22,7 +25,8 @@ import (
 
 const (
 	defaultTranscriptionTimeout = 30 * time.Second
-	maxAudioBytes               = 25 * 1024 * 1024
+	maxMessageLength            = 4000               // Slack 建议的单条消息字符数上限，超出部分会被截断
+	maxUploadBytes              = 1024 * 1024 * 1024 // Slack 单文件上传上限 (1GB)
 )
 
 // Channel implements Slack Socket Mode channel.
@@ -32,6 +36,7 @@ type Channel struct {
 	api                  *slack.Client
 	socketClient         *socketmode.Client
 	botUserID            string
+	teamID               string
 	transcriber          voice.Transcriber
 	downloadAudio        func(ctx context.Context, url, fileName, mimeType string) (voice.Input, error)
 	httpClient           *http.Client
@@ -53,7 +58,7 @@ func New(cfg *config.SlackConfig, msgBus *bus.MessageBus, transcriber voice.Tran
 		BaseChannel:          channel.BaseChannel{Bus: msgBus, AllowList: allowList},
 		cfg:                  cfg,
 		transcriber:          transcriber,
-		httpClient:           &http.Client{Timeout: 45 * time.Second},
+		httpClient:           httpclient.New(httpclient.Timeout(45 * time.Second)),
 		transcriptionTimeout: defaultTranscriptionTimeout,
 	}
 	ch.downloadAudio = ch.downloadSlackAudio
@@ -83,6 +88,7 @@ func (c *Channel) Start(ctx context.Context) error {
 	c.api = api
 	c.socketClient = socketClient
 	c.botUserID = authResp.UserID
+	c.teamID = authResp.TeamID
 	c.running = true
 	c.ctx = runCtx
 	c.cancel = cancel
@@ -112,6 +118,113 @@ func (c *Channel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// SendTyping 通过助手线程状态显示处理中提示。Socket Mode 不支持传统的输入提示，
+// 因此仅对线程内的会话生效，其余情况为空操作；状态会在机器人回复后自动清除。
+func (c *Channel) SendTyping(ctx context.Context, chatID string) error {
+	c.mu.RLock()
+	api := c.api
+	running := c.running
+	c.mu.RUnlock()
+	if !running || api == nil {
+		return fmt.Errorf("slack channel not running")
+	}
+
+	channelID, threadTS := parseChatID(chatID)
+	if strings.TrimSpace(channelID) == "" || threadTS == "" {
+		return nil
+	}
+	return api.SetAssistantThreadsStatusContext(ctx, slack.AssistantThreadsSetStatusParameters{
+		ChannelID: channelID,
+		ThreadTS:  threadTS,
+		Status:    "is thinking...",
+	})
+}
+
+// slackEmojiNames 把常用的 Unicode 表情映射为 Slack 的表情名称。
+var slackEmojiNames = map[string]string{
+	"👀":  "eyes",
+	"👍":  "+1",
+	"👎":  "-1",
+	"👌":  "ok_hand",
+	"✅":  "white_check_mark",
+	"❌":  "x",
+	"⏳":  "hourglass_flowing_sand",
+	"🤔":  "thinking_face",
+	"🔥":  "fire",
+	"🎉":  "tada",
+	"💯":  "100",
+	"🙏":  "pray",
+	"⚠️": "warning",
+}
+
+// slackEmojiName 返回 emoji 对应的 Slack 表情名称；emoji 本身是名称（可带冒号）时原样使用。
+func slackEmojiName(emoji string) (string, error) {
+	if name, ok := slackEmojiNames[emoji]; ok {
+		return name, nil
+	}
+	name := strings.Trim(emoji, ":")
+	for _, r := range name {
+		if r > unicode.MaxASCII {
+			return "", fmt.Errorf("no slack emoji name for %q", emoji)
+		}
+	}
+	return name, nil
+}
+
+// React 把机器人在消息上的反应换成 emoji：先移除机器人已添加的其他反应，再添加新反应；emoji 为空时只移除。
+// messageID 为消息的 ts。需要 reactions:read 与 reactions:write 权限。
+func (c *Channel) React(ctx context.Context, chatID, messageID, emoji string) error {
+	c.mu.RLock()
+	api := c.api
+	running := c.running
+	c.mu.RUnlock()
+	if !running || api == nil {
+		return fmt.Errorf("slack channel not running")
+	}
+	channelID, _ := parseChatID(chatID)
+	if strings.TrimSpace(channelID) == "" || strings.TrimSpace(messageID) == "" {
+		return fmt.Errorf("slack chat id or message ts is empty")
+	}
+	name := ""
+	if emoji != "" {
+		var err error
+		if name, err = slackEmojiName(emoji); err != nil {
+			return err
+		}
+	}
+
+	item := slack.NewRefToMessage(channelID, messageID)
+	reactions, err := api.GetReactionsContext(ctx, item, slack.GetReactionsParameters{Full: true})
+	if err != nil {
+		return fmt.Errorf("get slack reactions: %w", err)
+	}
+	present := false
+	for _, r := range reactions {
+		if !slices.Contains(r.Users, c.botUserID) {
+			continue
+		}
+		if r.Name != name {
+			if err := api.RemoveReactionContext(ctx, r.Name, item); err != nil {
+				return fmt.Errorf("remove slack reaction: %w", err)
+			}
+			continue
+		}
+		present = true
+	}
+	if name == "" || present {
+		return nil
+	}
+	if err := api.AddReactionContext(ctx, name, item); err != nil {
+		return fmt.Errorf("add slack reaction: %w", err)
+	}
+	return nil
+}
+
+// MaxAttachmentBytes 返回 Slack 的单文件上传上限。
+func (c *Channel) MaxAttachmentBytes() int64 { return maxUploadBytes }
+
+// Send 发送出站消息，超过单条长度上限时拆分为多条依次发送，附件在文本之后上传。
+// 开启 block_kit 时正文以 Block Kit 发送，Slack 拒绝 Block 时回退为纯文本。
 func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	c.mu.RLock()
 	api := c.api
@@ -121,19 +234,87 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 		return fmt.Errorf("slack channel not running")
 	}
 
-	channelID, threadTS := parseChatID(msg.ChatID)
+	channelID, threadTS := sendTarget(msg)
 	if strings.TrimSpace(channelID) == "" {
 		return fmt.Errorf("invalid slack chat id: %q", msg.ChatID)
 	}
 
-	opts := []slack.MsgOption{slack.MsgOptionText(msg.Content, false)}
-	if threadTS != "" {
-		opts = append(opts, slack.MsgOptionTS(threadTS))
+	if err := channel.CheckAttachments("slack", msg.Attachments, maxUploadBytes); err != nil {
+		return err
 	}
 
-	_, _, err := api.PostMessageContext(ctx, channelID, opts...)
+	if c.cfg.BlockKit && strings.TrimSpace(msg.Content) != "" {
+		sent, err := postBlocks(ctx, api, channelID, threadTS, msg.Content)
+		if err == nil {
+			return c.uploadAttachments(ctx, api, channelID, threadTS, msg.Attachments)
+		}
+		if sent > 0 {
+			return fmt.Errorf("send slack message: %w", err)
+		}
+		slog.Warn("slack block kit send failed, falling back to plain text", "request_id", msg.RequestID, "error", err)
+	}
+
+	if strings.TrimSpace(msg.Content) != "" || len(msg.Attachments) == 0 {
+		for _, chunk := range channel.SplitMessage(msg.Content, maxMessageLength) {
+			opts := []slack.MsgOption{slack.MsgOptionText(chunk, false)}
+			if threadTS != "" {
+				opts = append(opts, slack.MsgOptionTS(threadTS))
+			}
+
+			if _, _, err := api.PostMessageContext(ctx, channelID, opts...); err != nil {
+				return fmt.Errorf("send slack message: %w", err)
+			}
+		}
+	}
+	return c.uploadAttachments(ctx, api, channelID, threadTS, msg.Attachments)
+}
+
+func (c *Channel) uploadAttachments(ctx context.Context, api *slack.Client, channelID, threadTS string, attachments []bus.OutboundAttachment) error {
+	for _, att := range attachments {
+		if err := uploadFile(ctx, api, channelID, threadTS, att); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postBlocks 以 Block Kit 发送正文，返回已成功发送的消息数；第一条即失败时调用方可安全地回退为纯文本。
+func postBlocks(ctx context.Context, api *slack.Client, channelID, threadTS, content string) (int, error) {
+	sent := 0
+	for _, m := range buildBlockMessages(content) {
+		opts := []slack.MsgOption{slack.MsgOptionBlocks(m.blocks...), slack.MsgOptionText(m.text, false)}
+		if threadTS != "" {
+			opts = append(opts, slack.MsgOptionTS(threadTS))
+		}
+		if _, _, err := api.PostMessageContext(ctx, channelID, opts...); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// uploadFile 通过 files.getUploadURLExternal 流程上传附件（旧的 files.upload 接口已停用）。
+func uploadFile(ctx context.Context, api *slack.Client, channelID, threadTS string, att bus.OutboundAttachment) error {
+	size, err := att.Size()
+	if err != nil {
+		return fmt.Errorf("attachment %q: %w", att.Name(), err)
+	}
+	reader, err := att.Open()
+	if err != nil {
+		return fmt.Errorf("open attachment %q: %w", att.Name(), err)
+	}
+	defer reader.Close()
+
+	_, err = api.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Reader:          reader,
+		FileSize:        int(size),
+		Filename:        att.Name(),
+		Channel:         channelID,
+		ThreadTimestamp: threadTS,
+	})
 	if err != nil {
-		return fmt.Errorf("send slack message: %w", err)
+		return fmt.Errorf("upload slack file %q: %w", att.Name(), err)
 	}
 	return nil
 }
@@ -194,9 +375,13 @@ func (c *Channel) handleMessageEvent(ev *slackevents.MessageEvent) {
 	if ev.User == "" || ev.BotID != "" || ev.SubType == "bot_message" {
 		return
 	}
+	// mention 模式下频道消息只经由 app_mention 事件处理，私聊始终响应。
+	if c.cfg.RespondWhen == config.RespondMention && ev.ChannelType != "im" {
+		return
+	}
 
 	senderID := ev.User
-	if !c.IsAllowed(senderID) {
+	if !c.AllowsSender(channel.Sender{ID: senderID, Guild: c.senderTeam(ev.UserTeam)}) {
 		return
 	}
 
@@ -248,6 +433,9 @@ func (c *Channel) handleMessageEvent(ev *slackevents.MessageEvent) {
 		"channel_id": ev.Channel,
 		"thread_ts":  ev.ThreadTimeStamp,
 	}
+	if ev.ThreadTimeStamp != "" {
+		metadata[bus.MetadataThreadID] = ev.ThreadTimeStamp
+	}
 	if transcribedCount > 0 {
 		metadata["transcribed_audio"] = true
 		metadata["transcribed_audio_count"] = transcribedCount
@@ -272,7 +460,7 @@ func (c *Channel) handleMentionEvent(ev *slackevents.AppMentionEvent) {
 	if ev.User == "" {
 		return
 	}
-	if !c.IsAllowed(ev.User) {
+	if !c.AllowsSender(channel.Sender{ID: ev.User, Guild: c.senderTeam(ev.UserTeam)}) {
 		return
 	}
 
@@ -281,11 +469,21 @@ func (c *Channel) handleMentionEvent(ev *slackevents.AppMentionEvent) {
 		return
 	}
 
+	// 频道内的提及在以该消息为根的线程中回复。
+	threadTS := ev.ThreadTimeStamp
+	if threadTS == "" {
+		threadTS = ev.TimeStamp
+	}
 	chatID := ev.Channel
-	if ev.ThreadTimeStamp != "" {
-		chatID = ev.Channel + "/" + ev.ThreadTimeStamp
-	} else if ev.TimeStamp != "" {
-		chatID = ev.Channel + "/" + ev.TimeStamp
+	metadata := map[string]any{
+		"message_ts": ev.TimeStamp,
+		"channel_id": ev.Channel,
+		"thread_ts":  ev.ThreadTimeStamp,
+		"is_mention": true,
+	}
+	if threadTS != "" {
+		chatID = ev.Channel + "/" + threadTS
+		metadata[bus.MetadataThreadID] = threadTS
 	}
 
 	c.PublishInbound(&bus.InboundMessage{
@@ -294,12 +492,7 @@ func (c *Channel) handleMentionEvent(ev *slackevents.AppMentionEvent) {
 		ChatID:    chatID,
 		Content:   content,
 		Timestamp: time.Now(),
-		Metadata: map[string]any{
-			"message_ts": ev.TimeStamp,
-			"channel_id": ev.Channel,
-			"thread_ts":  ev.ThreadTimeStamp,
-			"is_mention": true,
-		},
+		Metadata:  metadata,
 		RequestID: bus.NewRequestID(),
 	})
 }
@@ -315,7 +508,7 @@ func (c *Channel) handleSlashCommand(evt socketmode.Event) {
 	if cmd.UserID == "" {
 		return
 	}
-	if !c.IsAllowed(cmd.UserID) {
+	if !c.AllowsSender(channel.Sender{ID: cmd.UserID, Guild: c.senderTeam(cmd.TeamID)}) {
 		return
 	}
 
@@ -350,6 +543,25 @@ func (c *Channel) stripMention(text string) string {
 	return strings.TrimSpace(text)
 }
 
+// senderTeam 返回发送者所属工作区；事件未携带时（非共享频道）即为机器人所在工作区。
+func (c *Channel) senderTeam(team string) string {
+	if team != "" {
+		return team
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.teamID
+}
+
+// sendTarget 返回出站消息的目标频道与线程：ChatID 未编码线程时使用元数据中的线程（见 bus.MetadataThreadID）。
+func sendTarget(msg *bus.OutboundMessage) (channelID, threadTS string) {
+	channelID, threadTS = parseChatID(msg.ChatID)
+	if threadTS == "" {
+		threadTS = msg.ThreadID()
+	}
+	return channelID, threadTS
+}
+
 func parseChatID(chatID string) (channelID, threadTS string) {
 	parts := strings.SplitN(chatID, "/", 2)
 	channelID = parts[0]
@@ -418,7 +630,7 @@ func (c *Channel) downloadSlackAudio(ctx context.Context, url, fileName, mimeTyp
 		return voice.Input{}, fmt.Errorf("download slack media failed: status %d", resp.StatusCode)
 	}
 
-	data, err := readLimited(resp.Body, maxAudioBytes)
+	data, err := readLimited(resp.Body, voice.MaxAudioBytes)
 	if err != nil {
 		return voice.Input{}, err
 	}
@@ -2,6 +2,9 @@ package slack
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -186,3 +189,85 @@ func TestHandleMessageEvent_AudioOnlyFailureKeepsAudioPlaceholder(t *testing.T)
 		t.Fatal("expected inbound message")
 	}
 }
+
+func TestHandleMessageEvent_MentionModeSkipsChannelMessages(t *testing.T) {
+	msgBus := bus.NewMessageBus(2)
+	ch := New(&config.SlackConfig{RespondWhen: config.RespondMention}, msgBus, nil)
+
+	ch.handleMessageEvent(&slackevents.MessageEvent{User: "U1", Text: "hi all", TimeStamp: "1.1", Channel: "C1", ChannelType: "channel"})
+	select {
+	case in := <-msgBus.Inbound():
+		t.Fatalf("expected channel message to wait for app_mention, got %+v", in)
+	default:
+	}
+
+	ch.handleMessageEvent(&slackevents.MessageEvent{User: "U1", Text: "hello", TimeStamp: "1.2", Channel: "D1", ChannelType: "im"})
+	select {
+	case <-msgBus.Inbound():
+	default:
+		t.Fatal("expected direct message to be delivered")
+	}
+}
+
+func TestSendTarget_FallsBackToThreadMetadata(t *testing.T) {
+	channelID, threadTS := sendTarget(&bus.OutboundMessage{ChatID: "C1", Metadata: map[string]any{bus.MetadataThreadID: "1700000000.1"}})
+	if channelID != "C1" || threadTS != "1700000000.1" {
+		t.Fatalf("unexpected target: channel=%q thread=%q", channelID, threadTS)
+	}
+	channelID, threadTS = sendTarget(&bus.OutboundMessage{ChatID: "C1/1700000000.2"})
+	if channelID != "C1" || threadTS != "1700000000.2" {
+		t.Fatalf("unexpected target: channel=%q thread=%q", channelID, threadTS)
+	}
+}
+
+func TestHandleMentionEvent_RecordsThread(t *testing.T) {
+	msgBus := bus.NewMessageBus(1)
+	ch := New(&config.SlackConfig{}, msgBus, nil)
+
+	ch.handleMentionEvent(&slackevents.AppMentionEvent{User: "U1", Text: "summarize", Channel: "C1", TimeStamp: "1700000000.3"})
+	select {
+	case in := <-msgBus.Inbound():
+		if in.ChatID != "C1/1700000000.3" || in.ThreadID() != "1700000000.3" {
+			t.Fatalf("expected mention to open a thread, got chat=%q metadata=%+v", in.ChatID, in.Metadata)
+		}
+	default:
+		t.Fatal("expected inbound message")
+	}
+}
+
+func TestReact_SwapsOwnReaction(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		calls = append(calls, method+" "+r.Form.Get("name"))
+		w.Header().Set("Content-Type", "application/json")
+		if method == "reactions.get" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "type": "message", "message": map[string]any{
+				"reactions": []map[string]any{
+					{"name": "eyes", "count": 1, "users": []string{"UBOT"}},
+					{"name": "tada", "count": 1, "users": []string{"U1"}},
+				},
+			}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer srv.Close()
+
+	ch := New(&config.SlackConfig{}, bus.NewMessageBus(1), nil)
+	ch.api = slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"))
+	ch.botUserID = "UBOT"
+	ch.running = true
+
+	if err := ch.React(context.Background(), "C1/1.0", "1.1", "👍"); err != nil {
+		t.Fatalf("React: %v", err)
+	}
+	want := []string{"reactions.get ", "reactions.remove eyes", "reactions.add +1"}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %v, got %v", want, calls)
+	}
+	if _, err := slackEmojiName("🦄"); err == nil {
+		t.Fatal("expected an error for an emoji without a known Slack name")
+	}
+}
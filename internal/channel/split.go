@@ -0,0 +1,97 @@
+package channel
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const codeFence = "```"
+
+// SplitMessage 将超过 maxLen（按字符计）的文本拆分为多段，用于适配各平台的单条消息长度上限。
+// 优先在段落边界断开，其次是换行、空格，最后才硬切；拆分点落在代码块内部时，
+// 当前段末尾补上闭合围栏，下一段开头重新打开同一语言的围栏。maxLen <= 0 表示不拆分。
+func SplitMessage(text string, maxLen int) []string {
+	if maxLen <= 0 || utf8.RuneCountInString(text) <= maxLen {
+		return []string{text}
+	}
+
+	var chunks []string
+	openFence := ""
+	rest := text
+	for rest != "" {
+		prefix := ""
+		if openFence != "" {
+			prefix = openFence + "\n"
+			if utf8.RuneCountInString(prefix)+len(codeFence)+1 >= maxLen/2 {
+				prefix = codeFence + "\n"
+			}
+		}
+		// 预留闭合围栏的长度，保证补齐后仍不超限。
+		budget := maxLen - utf8.RuneCountInString(prefix) - len(codeFence) - 1
+		if budget < 1 {
+			budget = 1
+		}
+
+		cut := len(rest)
+		if utf8.RuneCountInString(rest) > budget {
+			cut = splitPoint(rest, budget)
+		}
+		body := strings.TrimRight(rest[:cut], "\n")
+		rest = strings.TrimLeft(rest[cut:], "\n")
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+
+		chunk := prefix + body
+		openFence = unclosedFence(chunk)
+		if openFence != "" && rest != "" {
+			chunk += "\n" + codeFence
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) == 0 {
+		return []string{text}
+	}
+	return chunks
+}
+
+// splitPoint 返回 text 前 budget 个字符内最合适的断开位置（字节偏移）。
+// 只有落在窗口后半段的边界才会被采用，避免产生过短的分段。
+func splitPoint(text string, budget int) int {
+	window := text[:runeOffset(text, budget)]
+	for _, sep := range []string{"\n\n", "\n", " "} {
+		if idx := strings.LastIndex(window, sep); idx > 0 && idx >= len(window)/2 {
+			return idx + len(sep)
+		}
+	}
+	return len(window)
+}
+
+// runeOffset 返回第 n 个字符对应的字节偏移。
+func runeOffset(text string, n int) int {
+	count := 0
+	for i := range text {
+		if count == n {
+			return i
+		}
+		count++
+	}
+	return len(text)
+}
+
+// unclosedFence 返回文本末尾仍未闭合的代码围栏行（如 "```go"），全部闭合时返回空串。
+func unclosedFence(text string) string {
+	open := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, codeFence) {
+			continue
+		}
+		if open == "" {
+			open = trimmed
+		} else if trimmed == codeFence {
+			open = ""
+		}
+	}
+	return open
+}
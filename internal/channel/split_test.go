@@ -0,0 +1,65 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage_ShortTextUnchanged(t *testing.T) {
+	got := SplitMessage("hello", 10)
+	if len(got) != 1 || got[0] != "hello" {
+		t.Fatalf("unexpected split: %q", got)
+	}
+	if got := SplitMessage(strings.Repeat("a", 50), 0); len(got) != 1 {
+		t.Fatalf("expected no split when maxLen is 0, got %d chunks", len(got))
+	}
+}
+
+func TestSplitMessage_PrefersParagraphBoundaries(t *testing.T) {
+	para1 := strings.Repeat("a", 30)
+	para2 := strings.Repeat("b", 30)
+	got := SplitMessage(para1+"\n\n"+para2, 45)
+	if len(got) != 2 || got[0] != para1 || got[1] != para2 {
+		t.Fatalf("expected split at paragraph boundary, got %q", got)
+	}
+}
+
+func TestSplitMessage_HardSplitRespectsRunes(t *testing.T) {
+	text := strings.Repeat("你", 25)
+	got := SplitMessage(text, 10)
+	if strings.Join(got, "") != text {
+		t.Fatalf("hard split lost content: %q", got)
+	}
+	for _, chunk := range got {
+		if !utf8.ValidString(chunk) || utf8.RuneCountInString(chunk) > 10 {
+			t.Fatalf("invalid chunk %q", chunk)
+		}
+	}
+}
+
+func TestSplitMessage_PreservesCodeFences(t *testing.T) {
+	var code []string
+	for i := 0; i < 20; i++ {
+		code = append(code, "fmt.Println(\"line\")")
+	}
+	text := "intro\n```go\n" + strings.Join(code, "\n") + "\n```\noutro"
+	got := SplitMessage(text, 120)
+	if len(got) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(got))
+	}
+	for i, chunk := range got {
+		if utf8.RuneCountInString(chunk) > 120 {
+			t.Fatalf("chunk %d exceeds limit: %d", i, utf8.RuneCountInString(chunk))
+		}
+		if strings.Count(chunk, "```")%2 != 0 {
+			t.Fatalf("chunk %d has unbalanced fences: %q", i, chunk)
+		}
+		if i > 0 && strings.Contains(chunk, "Println") && !strings.HasPrefix(chunk, "```go\n") {
+			t.Fatalf("chunk %d should reopen the go fence: %q", i, chunk)
+		}
+	}
+	if !strings.HasSuffix(got[len(got)-1], "outro") {
+		t.Fatalf("expected final chunk to end with outro, got %q", got[len(got)-1])
+	}
+}
@@ -3,6 +3,7 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -10,11 +11,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf16"
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/channel"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/MEKXH/golem/internal/render"
 	"github.com/MEKXH/golem/internal/voice"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -32,18 +36,43 @@ var (
 
 const (
 	defaultTranscriptionTimeout = 30 * time.Second
-	maxAudioBytes               = 25 * 1024 * 1024 // 允许处理的最大音频文件大小 (25MB)
+	maxTrackedMessages          = 512              // 为编辑而记录的最近已发送消息数量上限
+	maxMessageLength            = 4096             // Telegram 单条消息的字符数上限
+	maxUploadBytes              = 50 * 1024 * 1024 // Bot API 单个文件上传上限 (50MB)
+	pollTimeoutSeconds          = 60               // getUpdates 长轮询的等待时长
+	retryMinBackoff             = time.Second      // 连接或轮询失败后的初始重试间隔
+	retryMaxBackoff             = time.Minute      // 重试间隔上限
 )
 
+// sentMessageRef 记录某个请求最近一次发出的消息位置，供后续编辑使用。
+type sentMessageRef struct {
+	chatID    int64
+	messageID int
+}
+
 // Channel 表示 Telegram 消息通道。
 type Channel struct {
 	channel.BaseChannel
 	cfg                  *config.TelegramConfig
 	bot                  *tgbotapi.BotAPI
+	self                 tgbotapi.User                                                                     // 机器人自身账号，用于识别提及与回复
 	transcriber          voice.Transcriber                                                                 // 语音转文本服务
+	synthesizer          voice.Synthesizer                                                                 // 文本转语音服务，为 nil 时不发送语音回复
 	downloadVoice        func(ctx context.Context, fileID, fileName, mimeType string) (voice.Input, error) // 下载语音回调
 	httpClient           *http.Client
 	transcriptionTimeout time.Duration
+	send                 func(tgbotapi.Chattable) (tgbotapi.Message, error)           // 发送/编辑消息回调
+	request              func(tgbotapi.Chattable) (*tgbotapi.APIResponse, error)      // 不返回消息体的请求（如 ChatAction）
+	newBot               func(token string) (*tgbotapi.BotAPI, error)                 // 连接 Bot API 并获取机器人信息
+	getUpdates           func(tgbotapi.UpdateConfig) ([]tgbotapi.Update, error)       // 单次 getUpdates 长轮询
+	makeRequest          func(string, tgbotapi.Params) (*tgbotapi.APIResponse, error) // 库未封装的 Bot API 方法（如 setMessageReaction）
+
+	runMu  sync.Mutex
+	cancel context.CancelFunc // 结束当前 Start 的轮询循环
+
+	sentMu    sync.Mutex
+	sent      map[string]sentMessageRef // 按请求 ID 记录最近发送的消息
+	sentOrder []string                  // 请求 ID 的记录顺序，用于淘汰最旧条目
 }
 
 // New 创建并返回一个新的 Telegram 通道实例。
@@ -59,46 +88,145 @@ func New(cfg *config.TelegramConfig, msgBus *bus.MessageBus, transcriber voice.T
 		},
 		cfg:                  cfg,
 		transcriber:          transcriber,
-		httpClient:           &http.Client{Timeout: 45 * time.Second},
+		httpClient:           httpclient.New(httpclient.Timeout(45 * time.Second)),
 		transcriptionTimeout: defaultTranscriptionTimeout,
+		sent:                 make(map[string]sentMessageRef),
+		newBot:               tgbotapi.NewBotAPI,
 	}
 	ch.downloadVoice = ch.downloadTelegramVoice
 	return ch
 }
 
+// SetSynthesizer 开启语音回复：请求语音回复的出站消息将合成为语音消息发送，须在 Start 之前调用。
+func (c *Channel) SetSynthesizer(synth voice.Synthesizer) {
+	c.synthesizer = synth
+}
+
 // Name 返回通道名称。
 func (c *Channel) Name() string { return "telegram" }
 
-// Start 启动机器人 Long Polling 循环以接收更新。
+// Start 连接 Bot API 并以 Long Polling 接收更新，直到 ctx 被取消或调用 Stop。
+// 连接与轮询遇到网络错误、5xx 等暂时性故障时按指数退避重试，只有令牌无效时返回错误。
 func (c *Channel) Start(ctx context.Context) error {
-	bot, err := tgbotapi.NewBotAPI(c.cfg.Token)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.runMu.Lock()
+	c.cancel = cancel
+	c.runMu.Unlock()
+
+	bot, err := c.connect(ctx)
 	if err != nil {
-		return fmt.Errorf("telegram init failed: %w", err)
+		return err
+	}
+	if bot == nil {
+		return nil
 	}
 	c.bot = bot
+	c.self = bot.Self
+	c.send = bot.Send
+	c.request = bot.Request
+	c.getUpdates = bot.GetUpdates
+	c.makeRequest = bot.MakeRequest
 
 	slog.Info("telegram bot connected", "username", bot.Self.UserName)
+	c.pollUpdates(ctx)
+	return nil
+}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
+// connect 创建 Bot API 客户端，暂时性故障按指数退避重试；ctx 被取消时返回 nil。
+func (c *Channel) connect(ctx context.Context) (*tgbotapi.BotAPI, error) {
+	backoff := retryMinBackoff
+	for attempt := 1; ; attempt++ {
+		bot, err := c.newBot(c.cfg.Token)
+		if err == nil {
+			return bot, nil
+		}
+		if isPermanentError(err) {
+			return nil, fmt.Errorf("telegram init failed: %w", err)
+		}
+		slog.Warn("telegram connect failed, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		if !waitBackoff(ctx, backoff) {
+			return nil, nil
+		}
+		backoff = min(backoff*2, retryMaxBackoff)
+	}
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case update, ok := <-updates:
-			if !ok {
-				return nil
+// pollUpdates 持续长轮询 getUpdates，请求失败时按指数退避重试并在恢复后重置间隔，直到 ctx 被取消。
+// 偏移量在重试之间保留，已处理的更新不会重复投递。
+func (c *Channel) pollUpdates(ctx context.Context) {
+	cfg := tgbotapi.NewUpdate(0)
+	cfg.Timeout = pollTimeoutSeconds
+	backoff := retryMinBackoff
+	failures := 0
+	for ctx.Err() == nil {
+		updates, err := c.fetchUpdates(ctx, cfg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			slog.Warn("telegram long poll failed, reconnecting", "attempt", failures, "retry_in", backoff, "error", err)
+			if !waitBackoff(ctx, backoff) {
+				return
 			}
-			if update.Message == nil {
+			backoff = min(backoff*2, retryMaxBackoff)
+			continue
+		}
+		if failures > 0 {
+			slog.Info("telegram long poll reconnected", "failed_attempts", failures)
+			failures = 0
+			backoff = retryMinBackoff
+		}
+		for _, update := range updates {
+			if update.UpdateID < cfg.Offset {
 				continue
 			}
-			c.handleMessage(ctx, update.Message)
+			cfg.Offset = update.UpdateID + 1
+			if update.Message != nil {
+				c.handleMessage(ctx, update.Message)
+			}
 		}
 	}
 }
 
+// fetchUpdates 在后台执行一次 getUpdates，ctx 被取消时无需等待长轮询结束即可返回。
+func (c *Channel) fetchUpdates(ctx context.Context, cfg tgbotapi.UpdateConfig) ([]tgbotapi.Update, error) {
+	type result struct {
+		updates []tgbotapi.Update
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		updates, err := c.getUpdates(cfg)
+		done <- result{updates, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-done:
+		return r.updates, r.err
+	}
+}
+
+// isPermanentError 判断 Bot API 错误是否无法通过重试恢复（令牌无效或机器人不存在）。
+func isPermanentError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusNotFound)
+}
+
+// waitBackoff 等待 d 后返回 true；ctx 先被取消时返回 false。
+func waitBackoff(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 func (c *Channel) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	if msg == nil || msg.From == nil || msg.Chat == nil {
 		return
@@ -120,6 +248,16 @@ func (c *Channel) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 		"message_id": msg.MessageID,
 		"username":   msg.From.UserName,
 	}
+	if !msg.Chat.IsPrivate() {
+		addressed := c.addressedToBot(msg)
+		if !addressed && c.cfg.RespondWhen == config.RespondMention {
+			return
+		}
+		if addressed {
+			metadata["is_mention"] = true
+			content = stripBotMention(content, c.self.UserName)
+		}
+	}
 
 	// 尝试处理语音消息转录
 	transcribed, hasAudio, err := c.tryTranscribeAudio(ctx, msg)
@@ -151,9 +289,60 @@ func (c *Channel) handleMessage(ctx context.Context, msg *tgbotapi.Message) {
 	})
 }
 
+// addressedToBot 判断群聊消息是否指向机器人：@用户名、指向机器人的 text_mention、/命令@用户名，或回复了机器人的消息。
+func (c *Channel) addressedToBot(msg *tgbotapi.Message) bool {
+	if c.self.ID == 0 {
+		return false
+	}
+	if reply := msg.ReplyToMessage; reply != nil && reply.From != nil && reply.From.ID == c.self.ID {
+		return true
+	}
+	text, entities := msg.Text, msg.Entities
+	if text == "" {
+		text, entities = msg.Caption, msg.CaptionEntities
+	}
+	mention := "@" + strings.ToLower(c.self.UserName)
+	for _, e := range entities {
+		switch e.Type {
+		case "text_mention":
+			if e.User != nil && e.User.ID == c.self.ID {
+				return true
+			}
+		case "mention", "bot_command":
+			if c.self.UserName != "" && strings.HasSuffix(strings.ToLower(entityText(text, e)), mention) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entityText 返回实体覆盖的文本；Telegram 的偏移与长度以 UTF-16 码元计。
+func entityText(text string, e tgbotapi.MessageEntity) string {
+	units := utf16.Encode([]rune(text))
+	if e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > len(units) {
+		return ""
+	}
+	return string(utf16.Decode(units[e.Offset : e.Offset+e.Length]))
+}
+
+// stripBotMention 去掉内容中对机器人的 @提及（含 /命令@用户名 的后缀），使命令能被正常识别。
+func stripBotMention(content, username string) string {
+	if username == "" {
+		return content
+	}
+	re := regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(username) + `\b`)
+	return strings.TrimSpace(re.ReplaceAllString(content, ""))
+}
+
+// MaxAttachmentBytes 返回 Bot API 的单文件上传上限。
+func (c *Channel) MaxAttachmentBytes() int64 { return maxUploadBytes }
+
 // Send 向 Telegram 聊天发送出站消息。支持 HTML 渲染和思考过程展示。
+// 超过单条长度上限的内容会先按 Markdown 源文本拆分再逐段渲染，保证 HTML 标签不会被截断。
+// 附件在文本之后以文档形式上传。请求语音回复且已开启语音回复时改为发送语音消息，合成或发送失败时回退为文本。元数据 edit=true 时以第一段编辑同一请求先前发送的消息，无法编辑时回退为发送新消息。
 func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
-	if c.bot == nil {
+	if c.send == nil {
 		return fmt.Errorf("bot not initialized")
 	}
 
@@ -161,25 +350,181 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	if err != nil {
 		return fmt.Errorf("invalid chat id %q: %w", msg.ChatID, err)
 	}
-	html := renderMessageHTML(msg.Content)
+	if err := channel.CheckAttachments("telegram", msg.Attachments, maxUploadBytes); err != nil {
+		return err
+	}
+	if speech, ok := channel.SynthesizeVoiceReply(ctx, c.synthesizer, msg); ok {
+		err := c.sendVoice(chatID, speech)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("telegram voice reply failed, sending text", "request_id", msg.RequestID, "error", err)
+	}
+	var chunks []string
+	if strings.TrimSpace(msg.Content) != "" || len(msg.Attachments) == 0 {
+		chunks = channel.SplitMessage(messageSource(msg.Content), maxMessageLength)
+	}
 
-	tgMsg := tgbotapi.NewMessage(chatID, html)
-	tgMsg.ParseMode = "HTML"
+	if msg.IsEdit() && len(chunks) > 0 {
+		if ref, ok := c.lookupSent(msg.RequestID); ok && ref.chatID == chatID {
+			err := c.editMessage(ref, markdownToHTML(chunks[0]), chunks[0])
+			if err == nil {
+				chunks = chunks[1:]
+			} else {
+				slog.Debug("telegram edit failed, sending new message",
+					"request_id", msg.RequestID, "message_id", ref.messageID, "error", err)
+			}
+		}
+	}
 
-	_, err = c.bot.Send(tgMsg)
+	for _, chunk := range chunks {
+		tgMsg := tgbotapi.NewMessage(chatID, markdownToHTML(chunk))
+		tgMsg.ParseMode = "HTML"
+
+		sent, err := c.send(tgMsg)
+		if err != nil {
+			// 回退：如果 HTML 发送失败（可能是格式错误），则尝试以纯文本发送
+			tgMsg.ParseMode = ""
+			tgMsg.Text = chunk
+			sent, err = c.send(tgMsg)
+		}
+		if err != nil {
+			return err
+		}
+		c.rememberSent(msg.RequestID, sentMessageRef{chatID: chatID, messageID: sent.MessageID})
+	}
+	for _, att := range msg.Attachments {
+		if err := c.sendDocument(chatID, att); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendDocument 以文档形式上传附件。
+func (c *Channel) sendDocument(chatID int64, att bus.OutboundAttachment) error {
+	reader, err := att.Open()
 	if err != nil {
-		// 回退：如果 HTML 发送失败（可能是格式错误），则尝试以纯文本发送
-		tgMsg.ParseMode = ""
-		tgMsg.Text = msg.Content
-		_, err = c.bot.Send(tgMsg)
+		return fmt.Errorf("open attachment %q: %w", att.Name(), err)
+	}
+	defer reader.Close()
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileReader{Name: att.Name(), Reader: reader})
+	if _, err := c.send(doc); err != nil {
+		return fmt.Errorf("send telegram document %q: %w", att.Name(), err)
+	}
+	return nil
+}
+
+// sendVoice 以语音消息形式发送合成好的 Ogg/Opus 音频。
+func (c *Channel) sendVoice(chatID int64, att bus.OutboundAttachment) error {
+	reader, err := att.Open()
+	if err != nil {
+		return fmt.Errorf("open voice reply: %w", err)
+	}
+	defer reader.Close()
+
+	if _, err := c.send(tgbotapi.NewVoice(chatID, tgbotapi.FileReader{Name: att.Name(), Reader: reader})); err != nil {
+		return fmt.Errorf("send telegram voice: %w", err)
+	}
+	return nil
+}
+
+// SendTyping 发送“正在输入”聊天动作，Telegram 会显示约 5 秒。
+func (c *Channel) SendTyping(ctx context.Context, chatID string) error {
+	if c.request == nil {
+		return fmt.Errorf("bot not initialized")
+	}
+	id, err := parseInt64(chatID)
+	if err != nil {
+		return fmt.Errorf("invalid chat id %q: %w", chatID, err)
+	}
+	_, err = c.request(tgbotapi.NewChatAction(id, tgbotapi.ChatTyping))
+	return err
+}
+
+// React 通过 setMessageReaction 设置机器人在消息上的表情反应，新反应会替换旧反应，emoji 为空时清除。
+// Telegram 只接受固定范围内的表情，其余表情会被拒绝。
+func (c *Channel) React(ctx context.Context, chatID, messageID, emoji string) error {
+	if c.makeRequest == nil {
+		return fmt.Errorf("bot not initialized")
+	}
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("chat_id", chatID)
+	params.AddNonEmpty("message_id", messageID)
+	reaction := []map[string]string{}
+	if emoji != "" {
+		reaction = append(reaction, map[string]string{"type": "emoji", "emoji": emoji})
+	}
+	if err := params.AddInterface("reaction", reaction); err != nil {
+		return err
+	}
+	if _, err := c.makeRequest("setMessageReaction", params); err != nil {
+		return fmt.Errorf("set telegram reaction: %w", err)
+	}
+	return nil
+}
+
+// editMessage 以 HTML 编辑已发送的消息，失败时以纯文本重试；内容未变化视为成功。
+func (c *Channel) editMessage(ref sentMessageRef, html, plain string) error {
+	edit := tgbotapi.NewEditMessageText(ref.chatID, ref.messageID, html)
+	edit.ParseMode = "HTML"
+	_, err := c.send(edit)
+	if err != nil && !isMessageNotModified(err) {
+		edit.ParseMode = ""
+		edit.Text = plain
+		_, err = c.send(edit)
+	}
+	if err != nil && isMessageNotModified(err) {
+		return nil
 	}
 	return err
 }
 
+func isMessageNotModified(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message is not modified")
+}
+
+func (c *Channel) lookupSent(requestID string) (sentMessageRef, bool) {
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return sentMessageRef{}, false
+	}
+	c.sentMu.Lock()
+	defer c.sentMu.Unlock()
+	ref, ok := c.sent[requestID]
+	return ref, ok
+}
+
+// rememberSent 记录请求最近发送的消息，超过上限时淘汰最早记录的请求。
+func (c *Channel) rememberSent(requestID string, ref sentMessageRef) {
+	requestID = strings.TrimSpace(requestID)
+	if requestID == "" {
+		return
+	}
+	c.sentMu.Lock()
+	defer c.sentMu.Unlock()
+	if c.sent == nil {
+		c.sent = make(map[string]sentMessageRef)
+	}
+	if _, exists := c.sent[requestID]; !exists {
+		c.sentOrder = append(c.sentOrder, requestID)
+	}
+	c.sent[requestID] = ref
+	for len(c.sentOrder) > maxTrackedMessages {
+		delete(c.sent, c.sentOrder[0])
+		c.sentOrder = c.sentOrder[1:]
+	}
+}
+
 // Stop 停止接收更新并关闭通道。
 func (c *Channel) Stop(ctx context.Context) error {
-	if c.bot != nil {
-		c.bot.StopReceivingUpdates()
+	c.runMu.Lock()
+	cancel := c.cancel
+	c.cancel = nil
+	c.runMu.Unlock()
+	if cancel != nil {
+		cancel()
 	}
 	return nil
 }
@@ -189,16 +534,19 @@ func parseInt64(s string) (int64, error) {
 }
 
 func renderMessageHTML(content string) string {
+	return markdownToHTML(messageSource(content))
+}
+
+// messageSource 将 <think> 思考块改写为 "Thinking:" 段落，得到待渲染的 Markdown 源文本。
+func messageSource(content string) string {
 	think, main, hasThink := render.SplitThink(content)
-	if hasThink {
-		thinkHTML := markdownToHTML(think)
-		mainHTML := markdownToHTML(main)
-		if mainHTML == "" {
-			return "Thinking:\n" + thinkHTML
-		}
-		return "Thinking:\n" + thinkHTML + "\n\n" + mainHTML
+	if !hasThink {
+		return content
+	}
+	if main == "" {
+		return "Thinking:\n" + think
 	}
-	return markdownToHTML(content)
+	return "Thinking:\n" + think + "\n\n" + main
 }
 
 func markdownToHTML(text string) string {
@@ -279,7 +627,7 @@ func (c *Channel) downloadTelegramVoice(ctx context.Context, fileID, fileName, m
 		return voice.Input{}, fmt.Errorf("download telegram media failed: status %d", resp.StatusCode)
 	}
 
-	data, err := readLimited(resp.Body, maxAudioBytes)
+	data, err := readLimited(resp.Body, voice.MaxAudioBytes)
 	if err != nil {
 		return voice.Input{}, err
 	}
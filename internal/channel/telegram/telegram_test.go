@@ -2,10 +2,15 @@ package telegram
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/voice"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -186,3 +191,430 @@ func TestHandleMessage_VoiceOnlyTranscriptionFailureKeepsPlaceholder(t *testing.
 		t.Fatal("expected inbound message for voice transcription failure")
 	}
 }
+
+type fakeTelegramSender struct {
+	sent    []tgbotapi.Chattable
+	editErr error
+	nextID  int
+}
+
+func (f *fakeTelegramSender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.sent = append(f.sent, c)
+	if _, ok := c.(tgbotapi.EditMessageTextConfig); ok && f.editErr != nil {
+		return tgbotapi.Message{}, f.editErr
+	}
+	f.nextID++
+	return tgbotapi.Message{MessageID: f.nextID}, nil
+}
+
+func TestSend_EditFlagEditsPreviousMessageForRequest(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{nextID: 100}
+	ch.send = sender.Send
+
+	ctx := context.Background()
+	if err := ch.Send(ctx, &bus.OutboundMessage{ChatID: "42", Content: "step 1", RequestID: "req-1"}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	err := ch.Send(ctx, &bus.OutboundMessage{
+		ChatID:    "42",
+		Content:   "step 2",
+		RequestID: "req-1",
+		Metadata:  map[string]any{bus.MetadataEdit: true},
+	})
+	if err != nil {
+		t.Fatalf("Send(edit) error: %v", err)
+	}
+
+	if len(sender.sent) != 2 {
+		t.Fatalf("expected one send and one edit, got %d calls", len(sender.sent))
+	}
+	edit, ok := sender.sent[1].(tgbotapi.EditMessageTextConfig)
+	if !ok {
+		t.Fatalf("expected second call to edit, got %T", sender.sent[1])
+	}
+	if edit.ChatID != 42 || edit.MessageID != 101 || edit.Text != "step 2" {
+		t.Fatalf("unexpected edit target: %+v", edit)
+	}
+}
+
+func TestSend_EditFallsBackToNewMessage(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{editErr: errors.New("Bad Request: message to edit not found")}
+	ch.send = sender.Send
+
+	ctx := context.Background()
+	editMsg := &bus.OutboundMessage{
+		ChatID:    "42",
+		Content:   "status",
+		RequestID: "req-2",
+		Metadata:  map[string]any{bus.MetadataEdit: true},
+	}
+	// No prior message for the request: sends a new one.
+	if err := ch.Send(ctx, editMsg); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if _, ok := sender.sent[0].(tgbotapi.MessageConfig); !ok {
+		t.Fatalf("expected new message without prior send, got %T", sender.sent[0])
+	}
+
+	// Prior message exists but cannot be edited: falls back to a new message.
+	if err := ch.Send(ctx, editMsg); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	last := sender.sent[len(sender.sent)-1]
+	if _, ok := last.(tgbotapi.MessageConfig); !ok {
+		t.Fatalf("expected fallback to new message, got %T", last)
+	}
+}
+
+func TestSend_EditIgnoresMessageNotModified(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+	ch.rememberSent("req-3", sentMessageRef{chatID: 7, messageID: 9})
+	sender.editErr = errors.New("Bad Request: message is not modified")
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:    "7",
+		Content:   "same",
+		RequestID: "req-3",
+		Metadata:  map[string]any{bus.MetadataEdit: true},
+	})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected a single edit attempt, got %d calls", len(sender.sent))
+	}
+}
+
+func TestRememberSent_EvictsOldestRequests(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	for i := 0; i <= maxTrackedMessages; i++ {
+		ch.rememberSent(fmt.Sprintf("req-%d", i), sentMessageRef{chatID: 1, messageID: i})
+	}
+	if _, ok := ch.lookupSent("req-0"); ok {
+		t.Fatal("expected oldest request to be evicted")
+	}
+	if _, ok := ch.lookupSent(fmt.Sprintf("req-%d", maxTrackedMessages)); !ok {
+		t.Fatal("expected newest request to be tracked")
+	}
+}
+
+func TestSendTyping_SendsChatAction(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	var got tgbotapi.Chattable
+	ch.request = func(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+		got = c
+		return &tgbotapi.APIResponse{Ok: true}, nil
+	}
+
+	if err := ch.SendTyping(context.Background(), "42"); err != nil {
+		t.Fatalf("SendTyping() error: %v", err)
+	}
+	action, ok := got.(tgbotapi.ChatActionConfig)
+	if !ok || action.ChatID != 42 || action.Action != tgbotapi.ChatTyping {
+		t.Fatalf("unexpected chat action: %#v", got)
+	}
+}
+
+func TestReact_SetsAndClearsMessageReaction(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	var calls []tgbotapi.Params
+	ch.makeRequest = func(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+		if endpoint != "setMessageReaction" {
+			t.Fatalf("unexpected endpoint %q", endpoint)
+		}
+		calls = append(calls, params)
+		return &tgbotapi.APIResponse{Ok: true}, nil
+	}
+
+	if err := ch.React(context.Background(), "42", "7", "👀"); err != nil {
+		t.Fatalf("React() error: %v", err)
+	}
+	if err := ch.React(context.Background(), "42", "7", ""); err != nil {
+		t.Fatalf("React() clear error: %v", err)
+	}
+	if len(calls) != 2 || calls[0]["chat_id"] != "42" || calls[0]["message_id"] != "7" {
+		t.Fatalf("unexpected requests: %v", calls)
+	}
+	if calls[0]["reaction"] != `[{"emoji":"👀","type":"emoji"}]` || calls[1]["reaction"] != "[]" {
+		t.Fatalf("unexpected reaction params: %q, %q", calls[0]["reaction"], calls[1]["reaction"])
+	}
+}
+
+func TestSend_SplitsLongMessagesWithoutBreakingTags(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+
+	line := strings.Repeat("x", 60) + " **bold text** and `code`"
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, line)
+	}
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "42", Content: strings.Join(lines, "\n")}); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	if len(sender.sent) < 2 {
+		t.Fatalf("expected long message to be split, got %d sends", len(sender.sent))
+	}
+	for i, c := range sender.sent {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		if !ok {
+			t.Fatalf("send %d: expected MessageConfig, got %T", i, c)
+		}
+		if strings.Count(msg.Text, "<b>") != strings.Count(msg.Text, "</b>") ||
+			strings.Count(msg.Text, "<code>") != strings.Count(msg.Text, "</code>") {
+			t.Fatalf("send %d has unbalanced tags: %q", i, msg.Text)
+		}
+		if strings.Contains(msg.Text, "**") {
+			t.Fatalf("send %d left markdown unrendered: %q", i, msg.Text)
+		}
+	}
+}
+
+func TestSend_UploadsAttachmentsAsDocuments(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:      "42",
+		Attachments: []bus.OutboundAttachment{{Data: []byte("a,b\n"), FileName: "data.csv"}},
+	})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected only the document upload, got %d sends", len(sender.sent))
+	}
+	doc, ok := sender.sent[0].(tgbotapi.DocumentConfig)
+	if !ok || doc.ChatID != 42 {
+		t.Fatalf("expected document for chat 42, got %#v", sender.sent[0])
+	}
+	if file, ok := doc.File.(tgbotapi.FileReader); !ok || file.Name != "data.csv" {
+		t.Fatalf("unexpected document file: %#v", doc.File)
+	}
+}
+
+func TestSend_RejectsOversizedAttachmentBeforeSending(t *testing.T) {
+	ch := New(&config.TelegramConfig{}, bus.NewMessageBus(1), nil)
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:      "42",
+		Content:     "see attached",
+		Attachments: []bus.OutboundAttachment{{Data: make([]byte, maxUploadBytes+1), FileName: "big.bin"}},
+	})
+	var tooLarge *channel.AttachmentTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected AttachmentTooLargeError, got %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected nothing to be sent, got %d sends", len(sender.sent))
+	}
+}
+
+type fakeSynthesizer struct {
+	err  error
+	text string
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string) (voice.Speech, error) {
+	f.text = text
+	if f.err != nil {
+		return voice.Speech{}, f.err
+	}
+	return voice.Speech{Data: []byte("OggS"), FileName: "reply.ogg", MIMEType: "audio/ogg"}, nil
+}
+
+func TestSend_VoiceReplySendsVoiceNote(t *testing.T) {
+	ch := New(&config.TelegramConfig{VoiceReplies: true}, bus.NewMessageBus(1), nil)
+	synth := &fakeSynthesizer{}
+	ch.SetSynthesizer(synth)
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:   "42",
+		Content:  "hello there",
+		Metadata: map[string]any{bus.MetadataVoiceReply: true},
+	})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if synth.text != "hello there" {
+		t.Fatalf("synthesized text = %q", synth.text)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected a single voice send, got %d sends", len(sender.sent))
+	}
+	if v, ok := sender.sent[0].(tgbotapi.VoiceConfig); !ok || v.ChatID != 42 {
+		t.Fatalf("expected voice for chat 42, got %#v", sender.sent[0])
+	}
+}
+
+func TestSend_VoiceReplyFallsBackToTextOnSynthesisFailure(t *testing.T) {
+	ch := New(&config.TelegramConfig{VoiceReplies: true}, bus.NewMessageBus(1), nil)
+	ch.SetSynthesizer(&fakeSynthesizer{err: errors.New("quota exceeded")})
+	sender := &fakeTelegramSender{}
+	ch.send = sender.Send
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:   "42",
+		Content:  "hello there",
+		Metadata: map[string]any{bus.MetadataVoiceReply: true},
+	})
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected a single text send, got %d sends", len(sender.sent))
+	}
+	if m, ok := sender.sent[0].(tgbotapi.MessageConfig); !ok || m.Text != "hello there" {
+		t.Fatalf("expected text fallback, got %#v", sender.sent[0])
+	}
+}
+
+func TestHandleMessage_MentionModeFiltersGroupMessages(t *testing.T) {
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&config.TelegramConfig{RespondWhen: config.RespondMention}, msgBus, nil)
+	ch.self = tgbotapi.User{ID: 999, UserName: "GolemBot", IsBot: true}
+
+	group := &tgbotapi.Chat{ID: -100, Type: "supergroup"}
+	from := &tgbotapi.User{ID: 123, UserName: "alice"}
+	deliver := func(msg *tgbotapi.Message) *bus.InboundMessage {
+		ch.handleMessage(context.Background(), msg)
+		select {
+		case in := <-msgBus.Inbound():
+			return in
+		default:
+			return nil
+		}
+	}
+
+	if in := deliver(&tgbotapi.Message{MessageID: 1, From: from, Chat: group, Text: "just chatting"}); in != nil {
+		t.Fatalf("expected unaddressed group message to be ignored, got %+v", in)
+	}
+
+	in := deliver(&tgbotapi.Message{
+		MessageID: 2, From: from, Chat: group, Text: "@golembot what's the time?",
+		Entities: []tgbotapi.MessageEntity{{Type: "mention", Offset: 0, Length: 9}},
+	})
+	if in == nil || in.Content != "what's the time?" || in.Metadata["is_mention"] != true {
+		t.Fatalf("expected mention to be delivered without the bot name, got %+v", in)
+	}
+
+	in = deliver(&tgbotapi.Message{
+		MessageID: 3, From: from, Chat: group, Text: "/status@GolemBot",
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 16}},
+	})
+	if in == nil || in.Content != "/status" {
+		t.Fatalf("expected addressed command to be delivered as /status, got %+v", in)
+	}
+
+	in = deliver(&tgbotapi.Message{
+		MessageID: 4, From: from, Chat: group, Text: "and tomorrow?",
+		ReplyToMessage: &tgbotapi.Message{MessageID: 2, From: &ch.self},
+	})
+	if in == nil || in.Content != "and tomorrow?" {
+		t.Fatalf("expected reply to the bot to be delivered, got %+v", in)
+	}
+
+	in = deliver(&tgbotapi.Message{MessageID: 5, From: from, Chat: &tgbotapi.Chat{ID: 123, Type: "private"}, Text: "hello"})
+	if in == nil {
+		t.Fatal("expected direct messages to always be delivered")
+	}
+}
+
+func TestEntityText_UsesUTF16Offsets(t *testing.T) {
+	text := "😀 @golembot hi"
+	if got := entityText(text, tgbotapi.MessageEntity{Type: "mention", Offset: 3, Length: 9}); got != "@golembot" {
+		t.Fatalf("expected UTF-16 aware entity text, got %q", got)
+	}
+}
+
+func TestPollUpdates_RetriesAfterFailureAndKeepsOffset(t *testing.T) {
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&config.TelegramConfig{}, msgBus, nil)
+
+	var mu sync.Mutex
+	var offsets []int
+	ch.getUpdates = func(cfg tgbotapi.UpdateConfig) ([]tgbotapi.Update, error) {
+		mu.Lock()
+		offsets = append(offsets, cfg.Offset)
+		call := len(offsets)
+		mu.Unlock()
+		switch call {
+		case 1:
+			return []tgbotapi.Update{{UpdateID: 10, Message: &tgbotapi.Message{
+				MessageID: 1, From: &tgbotapi.User{ID: 1}, Chat: &tgbotapi.Chat{ID: 1, Type: "private"}, Text: "first",
+			}}}, nil
+		case 2:
+			return nil, errors.New("502 bad gateway")
+		case 3:
+			return []tgbotapi.Update{{UpdateID: 11, Message: &tgbotapi.Message{
+				MessageID: 2, From: &tgbotapi.User{ID: 1}, Chat: &tgbotapi.Chat{ID: 1, Type: "private"}, Text: "second",
+			}}}, nil
+		default:
+			time.Sleep(10 * time.Millisecond)
+			return nil, nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		ch.pollUpdates(ctx)
+		close(done)
+	}()
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case in := <-msgBus.Inbound():
+			if in.Content != want {
+				t.Fatalf("expected %q, got %q", want, in.Content)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if offsets[1] != 11 || offsets[2] != 11 || offsets[3] != 12 {
+		t.Fatalf("expected offset to survive the failed poll, got %v", offsets)
+	}
+}
+
+func TestConnect_RetriesTransientErrorsOnly(t *testing.T) {
+	ch := New(&config.TelegramConfig{Token: "t"}, bus.NewMessageBus(1), nil)
+
+	attempts := 0
+	ch.newBot = func(token string) (*tgbotapi.BotAPI, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("dial tcp: connection refused")
+		}
+		return &tgbotapi.BotAPI{Self: tgbotapi.User{ID: 9, UserName: "golembot"}}, nil
+	}
+	bot, err := ch.connect(context.Background())
+	if err != nil || bot == nil || attempts != 2 {
+		t.Fatalf("expected a retry after a network error, got bot=%v err=%v attempts=%d", bot, err, attempts)
+	}
+
+	attempts = 0
+	ch.newBot = func(token string) (*tgbotapi.BotAPI, error) {
+		attempts++
+		return nil, &tgbotapi.Error{Code: 401, Message: "Unauthorized"}
+	}
+	if _, err := ch.connect(context.Background()); err == nil || attempts != 1 {
+		t.Fatalf("expected an invalid token to fail without retrying, got err=%v attempts=%d", err, attempts)
+	}
+}
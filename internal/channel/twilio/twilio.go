@@ -0,0 +1,290 @@
+package twilio
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+const (
+	maxMessageLength  = 1600 // Twilio 单条 SMS 正文上限（超过 160 字符时由运营商按分段计费）
+	defaultAPIBaseURL = "https://api.twilio.com"
+	defaultPath       = "/twilio/sms"
+	maxWebhookBytes   = 64 * 1024
+	emptyTwiML        = `<?xml version="1.0" encoding="UTF-8"?><Response></Response>`
+)
+
+// Channel implements an SMS channel: Twilio webhooks in, Twilio REST API out.
+type Channel struct {
+	channel.BaseChannel
+	cfg        *config.TwilioConfig
+	httpClient *http.Client
+	apiBaseURL string
+
+	mu      sync.RWMutex
+	running bool
+	server  *http.Server
+}
+
+// New creates a Twilio SMS channel instance.
+func New(cfg *config.TwilioConfig, msgBus *bus.MessageBus) *Channel {
+	allowList := make(map[string]bool)
+	for _, id := range cfg.AllowFrom {
+		allowList[id] = true
+	}
+	return &Channel{
+		BaseChannel: channel.BaseChannel{Bus: msgBus, AllowList: allowList},
+		cfg:         cfg,
+		httpClient:  httpclient.New(httpclient.Timeout(30 * time.Second)),
+		apiBaseURL:  defaultAPIBaseURL,
+	}
+}
+
+func (c *Channel) Name() string { return "twilio" }
+
+// Start 启动接收 Twilio 入站短信 webhook 的 HTTP 监听。
+func (c *Channel) Start(ctx context.Context) error {
+	if c.cfg == nil {
+		return fmt.Errorf("missing twilio config")
+	}
+	if strings.TrimSpace(c.cfg.AccountSID) == "" || strings.TrimSpace(c.cfg.AuthToken) == "" {
+		return fmt.Errorf("twilio account_sid/auth_token is empty")
+	}
+	if strings.TrimSpace(c.cfg.FromNumber) == "" {
+		return fmt.Errorf("twilio from_number is empty")
+	}
+	if c.cfg.Port <= 0 {
+		return fmt.Errorf("twilio port must be > 0")
+	}
+
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("start twilio webhook listener: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(c.webhookPath(), c.handleWebhook)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	c.mu.Lock()
+	c.server = server
+	c.running = true
+	c.mu.Unlock()
+
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("twilio webhook server failed", "error", err)
+		}
+	}()
+	slog.Info("twilio webhook listening", "addr", ln.Addr().String(), "path", c.webhookPath())
+	return nil
+}
+
+func (c *Channel) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	server := c.server
+	c.server = nil
+	c.running = false
+	c.mu.Unlock()
+
+	if server != nil {
+		return server.Shutdown(ctx)
+	}
+	return nil
+}
+
+// Send 通过 Messages API 发送短信，超过 1600 字符时拆分为多条依次发送。附件暂不支持。
+func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
+	c.mu.RLock()
+	running := c.running
+	c.mu.RUnlock()
+	if !running {
+		return fmt.Errorf("twilio channel not running")
+	}
+
+	to := strings.TrimSpace(msg.ChatID)
+	if to == "" {
+		return fmt.Errorf("invalid twilio chat id: %q", msg.ChatID)
+	}
+	for _, chunk := range channel.SplitMessage(msg.Content, maxMessageLength) {
+		if err := c.sendSMS(ctx, to, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Channel) sendSMS(ctx context.Context, to, body string) error {
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(c.apiBaseURL, "/"), url.PathEscape(c.cfg.AccountSID))
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", c.cfg.FromNumber)
+	form.Set("Body", body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.cfg.AccountSID, c.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send twilio sms: %w", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("send twilio sms: status %d: %s (code %d)", resp.StatusCode, apiErr.Message, apiErr.Code)
+		}
+		return fmt.Errorf("send twilio sms: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// handleWebhook 校验 X-Twilio-Signature 后将入站短信发布到总线，并回复空 TwiML（回复由 Send 异步发出）。
+func (c *Channel) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBytes)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	signature := r.Header.Get("X-Twilio-Signature")
+	if !validSignature(c.cfg.AuthToken, c.requestURL(r), r.PostForm, signature) {
+		slog.Warn("twilio webhook rejected: invalid signature", "remote", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	_, _ = io.WriteString(w, emptyTwiML)
+
+	from := strings.TrimSpace(r.PostForm.Get("From"))
+	if from == "" || !c.IsAllowed(from) {
+		return
+	}
+
+	content := strings.TrimSpace(r.PostForm.Get("Body"))
+	media := make([]string, 0)
+	numMedia, _ := strconv.Atoi(r.PostForm.Get("NumMedia"))
+	for i := 0; i < numMedia; i++ {
+		mediaURL := strings.TrimSpace(r.PostForm.Get(fmt.Sprintf("MediaUrl%d", i)))
+		if mediaURL == "" {
+			continue
+		}
+		media = append(media, mediaURL)
+		content = appendLine(content, fmt.Sprintf("[attachment: %s]", mediaURL))
+	}
+	if content == "" {
+		return
+	}
+
+	c.PublishInbound(&bus.InboundMessage{
+		Channel:   c.Name(),
+		SenderID:  from,
+		ChatID:    from,
+		Content:   content,
+		Timestamp: time.Now(),
+		Media:     media,
+		Metadata: map[string]any{
+			"message_sid": r.PostForm.Get("MessageSid"),
+			"to":          r.PostForm.Get("To"),
+		},
+		RequestID: bus.NewRequestID(),
+	})
+}
+
+func (c *Channel) webhookPath() string {
+	path := strings.TrimSpace(c.cfg.Path)
+	if path == "" {
+		return defaultPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+// requestURL 返回 Twilio 计算签名时使用的完整 URL。位于反向代理之后时，
+// 本地看到的地址与公网地址不同，需通过 webhook_url 显式配置。
+func (c *Channel) requestURL(r *http.Request) string {
+	if u := strings.TrimSpace(c.cfg.WebhookURL); u != "" {
+		return u
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// validSignature 按 Twilio 规则校验签名：HMAC-SHA1(auth_token, URL + 按键排序拼接的 POST 参数) 的 Base64。
+func validSignature(authToken, requestURL string, params url.Values, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	expected, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, computeSignature(authToken, requestURL, params))
+}
+
+func computeSignature(authToken, requestURL string, params url.Values) []byte {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(requestURL)
+	for _, k := range keys {
+		values := append([]string(nil), params[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return mac.Sum(nil)
+}
+
+func appendLine(base, suffix string) string {
+	if base == "" {
+		return suffix
+	}
+	return base + "\n" + suffix
+}
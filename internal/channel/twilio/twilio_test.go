@@ -0,0 +1,151 @@
+package twilio
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+)
+
+const testWebhookURL = "https://example.com/twilio/sms"
+
+func newTestChannel(allowFrom ...string) (*Channel, *bus.MessageBus) {
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&config.TwilioConfig{
+		AccountSID: "AC123",
+		AuthToken:  "secret",
+		FromNumber: "+15550000000",
+		WebhookURL: testWebhookURL,
+		AllowFrom:  allowFrom,
+	}, msgBus)
+	return ch, msgBus
+}
+
+func signedRequest(t *testing.T, authToken string, form url.Values) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/twilio/sms", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	sig := base64.StdEncoding.EncodeToString(computeSignature(authToken, testWebhookURL, form))
+	req.Header.Set("X-Twilio-Signature", sig)
+	return req
+}
+
+func TestComputeSignature_MatchesTwilioExample(t *testing.T) {
+	// 来自 Twilio 官方文档的签名示例。
+	params := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	got := base64.StdEncoding.EncodeToString(computeSignature("12345", "https://mycompany.com/myapp.php?foo=1&bar=2", params))
+	if got != "0/KCTR6DLpKmkAf8muzZqo1nDgQ=" {
+		t.Fatalf("signature = %q", got)
+	}
+}
+
+func TestHandleWebhook_PublishesSignedMessage(t *testing.T) {
+	ch, msgBus := newTestChannel("+15551234567")
+	form := url.Values{
+		"From":       {"+15551234567"},
+		"To":         {"+15550000000"},
+		"Body":       {"hello"},
+		"MessageSid": {"SM1"},
+	}
+	rec := httptest.NewRecorder()
+	ch.handleWebhook(rec, signedRequest(t, "secret", form))
+
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<Response>") {
+		t.Fatalf("unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Channel != "twilio" || msg.SenderID != "+15551234567" || msg.ChatID != "+15551234567" || msg.Content != "hello" {
+			t.Fatalf("unexpected inbound: %+v", msg)
+		}
+	default:
+		t.Fatal("expected inbound message")
+	}
+}
+
+func TestHandleWebhook_RejectsInvalidSignature(t *testing.T) {
+	ch, msgBus := newTestChannel()
+	form := url.Values{"From": {"+15551234567"}, "Body": {"hello"}}
+	rec := httptest.NewRecorder()
+	ch.handleWebhook(rec, signedRequest(t, "wrong-token", form))
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403", rec.Code)
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("unexpected inbound: %+v", msg)
+	default:
+	}
+}
+
+func TestHandleWebhook_IgnoresUnlistedSender(t *testing.T) {
+	ch, msgBus := newTestChannel("+15551234567")
+	form := url.Values{"From": {"+15559999999"}, "Body": {"hello"}}
+	rec := httptest.NewRecorder()
+	ch.handleWebhook(rec, signedRequest(t, "secret", form))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	select {
+	case msg := <-msgBus.Inbound():
+		t.Fatalf("unexpected inbound: %+v", msg)
+	default:
+	}
+}
+
+func TestSend_SplitsAtSegmentLimit(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []url.Values
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" || user != "AC123" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":20003,"message":"Authenticate"}`))
+			return
+		}
+		_ = r.ParseForm()
+		mu.Lock()
+		bodies = append(bodies, r.PostForm)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM1"}`))
+	}))
+	defer api.Close()
+
+	ch, _ := newTestChannel()
+	ch.apiBaseURL = api.URL
+	ch.running = true
+
+	if err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "+15551234567", Content: strings.Repeat("a", maxMessageLength+1)}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(bodies))
+	}
+	for _, b := range bodies {
+		if b.Get("To") != "+15551234567" || b.Get("From") != "+15550000000" {
+			t.Fatalf("unexpected form: %v", b)
+		}
+	}
+
+	ch.cfg.AuthToken = "bad"
+	err := ch.Send(context.Background(), &bus.OutboundMessage{ChatID: "+15551234567", Content: "hi"})
+	if err == nil || !strings.Contains(err.Error(), "code 20003") {
+		t.Fatalf("expected api error, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/voice"
+)
+
+// voiceReplyTimeout 限制单次语音合成的耗时，超时后回退为文本回复。
+const voiceReplyTimeout = 60 * time.Second
+
+// SynthesizeVoiceReply 在出站消息请求语音回复时将正文合成为音频附件。
+// synth 为 nil、消息未请求语音、消息为编辑或已带附件时返回 false；合成失败时记录日志并返回 false，
+// 由调用方按文本发送。
+func SynthesizeVoiceReply(ctx context.Context, synth voice.Synthesizer, msg *bus.OutboundMessage) (bus.OutboundAttachment, bool) {
+	if synth == nil || msg == nil || !msg.WantsVoiceReply() || msg.IsEdit() || len(msg.Attachments) > 0 {
+		return bus.OutboundAttachment{}, false
+	}
+	sctx, cancel := context.WithTimeout(ctx, voiceReplyTimeout)
+	defer cancel()
+
+	speech, err := synth.Synthesize(sctx, msg.Content)
+	if err != nil {
+		slog.Warn("voice reply synthesis failed, sending text",
+			"request_id", msg.RequestID,
+			"channel", msg.Channel,
+			"chat_id", msg.ChatID,
+			"error", err,
+		)
+		return bus.OutboundAttachment{}, false
+	}
+	return bus.OutboundAttachment{Data: speech.Data, FileName: speech.FileName, MIMEType: speech.MIMEType}, true
+}
@@ -1,10 +1,29 @@
+// Package whatsapp 通过 WebSocket 桥接服务接入 WhatsApp。
+//
+// 桥接协议为 JSON 文本帧：
+//   - 入站消息 {"type":"message","id","from","from_name","chat","content","media":[...]}
+//   - 出站文本 {"type":"message","to","content"}
+//   - 出站媒体 {"type":"media","to","media_type":"image"|"document","file_name","mime_type","data"}，data 为 base64 编码的文件内容
+//   - 已读回执 {"type":"read","chat","id"}，id 为入站消息的 id
+//   - 能力声明 {"type":"capabilities","features":["media","read"]}，由桥接在连接建立后发送
+//   - 请求失败 {"type":"error","request":"media"|"read","error":"..."}
+//
+// 桥接未声明的能力视为不支持：附件降级为文本说明，已读回执被跳过；
+// 桥接对某类请求返回 error 后，该能力在本次连接内同样视为不支持。
+// 配置 token 时，握手请求携带 Authorization: Bearer <token>。
 package whatsapp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +33,13 @@ import (
 	"github.com/gorilla/websocket"
 )
 
+const (
+	featureMedia = "media" // 桥接支持发送图片与文件
+	featureRead  = "read"  // 桥接支持已读回执
+
+	maxMediaBytes = 100 * 1024 * 1024 // WhatsApp 单个文件上限 (100MB)
+)
+
 // Channel implements a WhatsApp bridge channel over websocket.
 type Channel struct {
 	channel.BaseChannel
@@ -22,6 +48,9 @@ type Channel struct {
 	mu        sync.RWMutex
 	running   bool
 	cancelRun context.CancelFunc
+	features  map[string]bool // 桥接声明且未被拒绝的能力
+
+	writeMu sync.Mutex // gorilla/websocket 不支持并发写
 }
 
 // New creates a WhatsApp channel instance.
@@ -49,7 +78,11 @@ func (c *Channel) Start(ctx context.Context) error {
 	dialer := websocket.DefaultDialer
 	dialer.HandshakeTimeout = 10 * time.Second
 
-	conn, _, err := dialer.Dial(c.cfg.BridgeURL, nil)
+	var header http.Header
+	if token := strings.TrimSpace(c.cfg.Token); token != "" {
+		header = http.Header{"Authorization": []string{"Bearer " + token}}
+	}
+	conn, _, err := dialer.Dial(c.cfg.BridgeURL, header)
 	if err != nil {
 		return fmt.Errorf("failed to connect to whatsapp bridge: %w", err)
 	}
@@ -60,6 +93,7 @@ func (c *Channel) Start(ctx context.Context) error {
 	c.conn = conn
 	c.running = true
 	c.cancelRun = cancel
+	c.features = map[string]bool{}
 	c.mu.Unlock()
 
 	go c.listen(runCtx)
@@ -84,6 +118,10 @@ func (c *Channel) Stop(ctx context.Context) error {
 	return nil
 }
 
+// MaxAttachmentBytes 返回 WhatsApp 的单个媒体文件上限。
+func (c *Channel) MaxAttachmentBytes() int64 { return maxMediaBytes }
+
+// Send 发送文本与附件；桥接不支持媒体时附件降级为文本说明。
 func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	c.mu.RLock()
 	conn := c.conn
@@ -92,20 +130,131 @@ func (c *Channel) Send(ctx context.Context, msg *bus.OutboundMessage) error {
 	if !running || conn == nil {
 		return fmt.Errorf("whatsapp channel not running")
 	}
+	if err := channel.CheckAttachments("whatsapp", msg.Attachments, maxMediaBytes); err != nil {
+		return err
+	}
+
+	content := msg.Content
+	attachments := msg.Attachments
+	if len(attachments) > 0 && !c.supports(featureMedia) {
+		slog.Info("whatsapp bridge does not support media, sending attachment names as text", "chat_id", msg.ChatID, "attachments", len(attachments))
+		content = appendAttachmentNotice(content, attachments)
+		attachments = nil
+	}
+
+	if strings.TrimSpace(content) != "" || len(attachments) == 0 {
+		if err := c.writeJSON(conn, map[string]any{
+			"type":    "message",
+			"to":      msg.ChatID,
+			"content": content,
+		}); err != nil {
+			return fmt.Errorf("send whatsapp message: %w", err)
+		}
+	}
+	for _, att := range attachments {
+		if err := c.sendMedia(conn, msg.ChatID, att); err != nil {
+			return fmt.Errorf("send whatsapp attachment %q: %w", att.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (c *Channel) sendMedia(conn *websocket.Conn, chatID string, att bus.OutboundAttachment) error {
+	reader, err := att.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	mimeType := strings.TrimSpace(att.MIMEType)
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(strings.ToLower(filepath.Ext(att.Name())))
+	}
+	mediaType := "document"
+	if strings.HasPrefix(mimeType, "image/") {
+		mediaType = "image"
+	}
+	return c.writeJSON(conn, map[string]any{
+		"type":       "media",
+		"to":         chatID,
+		"media_type": mediaType,
+		"file_name":  att.Name(),
+		"mime_type":  mimeType,
+		"data":       base64.StdEncoding.EncodeToString(data),
+	})
+}
 
-	payload := map[string]any{
-		"type":    "message",
-		"to":      msg.ChatID,
-		"content": msg.Content,
+// markRead 在桥接支持时为入站消息发送已读回执；失败只记录日志，不影响消息处理。
+func (c *Channel) markRead(conn *websocket.Conn, chatID, messageID string) {
+	if !c.cfg.MarkRead || messageID == "" || !c.supports(featureRead) {
+		return
+	}
+	if err := c.writeJSON(conn, map[string]any{
+		"type": "read",
+		"chat": chatID,
+		"id":   messageID,
+	}); err != nil {
+		slog.Warn("whatsapp read receipt failed", "chat_id", chatID, "message_id", messageID, "error", err)
 	}
+}
+
+func (c *Channel) writeJSON(conn *websocket.Conn, payload map[string]any) error {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal whatsapp message: %w", err)
+		return err
 	}
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("send whatsapp message: %w", err)
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *Channel) supports(feature string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.features[feature]
+}
+
+// handleControl 处理桥接的能力声明与请求失败通知。
+func (c *Channel) handleControl(kind string, frame map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch kind {
+	case "capabilities":
+		features := map[string]bool{}
+		if items, ok := frame["features"].([]any); ok {
+			for _, item := range items {
+				if name, ok := item.(string); ok && name != "" {
+					features[name] = true
+				}
+			}
+		}
+		c.features = features
+		slog.Info("whatsapp bridge capabilities", "media", features[featureMedia], "read", features[featureRead])
+	case "error":
+		request, _ := frame["request"].(string)
+		reason, _ := frame["error"].(string)
+		if c.features[request] {
+			delete(c.features, request)
+			slog.Warn("whatsapp bridge rejected request, disabling capability", "request", request, "error", reason)
+		}
 	}
-	return nil
+}
+
+// appendAttachmentNotice 在文本末尾列出未能发送的附件名称。
+func appendAttachmentNotice(content string, attachments []bus.OutboundAttachment) string {
+	names := make([]string, 0, len(attachments))
+	for _, att := range attachments {
+		names = append(names, att.Name())
+	}
+	notice := "[attachments not sent: " + strings.Join(names, ", ") + "]"
+	if strings.TrimSpace(content) == "" {
+		return notice
+	}
+	return content + "\n\n" + notice
 }
 
 func (c *Channel) listen(ctx context.Context) {
@@ -137,7 +286,12 @@ func (c *Channel) listen(ctx context.Context) {
 			continue
 		}
 
-		if t, _ := inbound["type"].(string); t != "message" {
+		switch t, _ := inbound["type"].(string); t {
+		case "message":
+		case "capabilities", "error":
+			c.handleControl(t, inbound)
+			continue
+		default:
 			continue
 		}
 
@@ -155,7 +309,8 @@ func (c *Channel) listen(ctx context.Context) {
 		}
 
 		metadata := map[string]any{}
-		if messageID, ok := inbound["id"].(string); ok && messageID != "" {
+		messageID, _ := inbound["id"].(string)
+		if messageID != "" {
 			metadata["message_id"] = messageID
 		}
 		if userName, ok := inbound["from_name"].(string); ok && userName != "" {
@@ -181,5 +336,8 @@ func (c *Channel) listen(ctx context.Context) {
 			Metadata:  metadata,
 			RequestID: bus.NewRequestID(),
 		})
+		if c.IsAllowed(senderID) {
+			c.markRead(conn, chatID, messageID)
+		}
 	}
 }
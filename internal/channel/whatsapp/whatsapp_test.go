@@ -0,0 +1,182 @@
+package whatsapp
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/gorilla/websocket"
+)
+
+type fakeBridge struct {
+	*httptest.Server
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	auth   string
+	frames chan map[string]any
+}
+
+// newFakeBridge 启动一个 WebSocket 桥接；hello 为连接建立后依次下发的帧。
+func newFakeBridge(t *testing.T, hello ...map[string]any) *fakeBridge {
+	t.Helper()
+	fb := &fakeBridge{frames: make(chan map[string]any, 16)}
+	upgrader := websocket.Upgrader{}
+	fb.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		fb.mu.Lock()
+		fb.conn = conn
+		fb.auth = r.Header.Get("Authorization")
+		for _, frame := range hello {
+			_ = conn.WriteJSON(frame)
+		}
+		fb.mu.Unlock()
+		for {
+			var frame map[string]any
+			if err := conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			fb.frames <- frame
+		}
+	}))
+	t.Cleanup(fb.Close)
+	return fb
+}
+
+func (fb *fakeBridge) push(t *testing.T, frame map[string]any) {
+	t.Helper()
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+	if err := fb.conn.WriteJSON(frame); err != nil {
+		t.Fatalf("bridge write: %v", err)
+	}
+}
+
+func (fb *fakeBridge) next(t *testing.T) map[string]any {
+	t.Helper()
+	select {
+	case frame := <-fb.frames:
+		return frame
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a frame from the channel")
+		return nil
+	}
+}
+
+func startChannel(t *testing.T, fb *fakeBridge, cfg config.WhatsAppConfig) (*Channel, *bus.MessageBus) {
+	t.Helper()
+	cfg.BridgeURL = "ws" + strings.TrimPrefix(fb.URL, "http")
+	msgBus := bus.NewMessageBus(4)
+	ch := New(&cfg, msgBus)
+	if err := ch.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { _ = ch.Stop(context.Background()) })
+	return ch, msgBus
+}
+
+func waitForFeature(t *testing.T, ch *Channel, feature string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !ch.supports(feature) {
+		if time.Now().After(deadline) {
+			t.Fatalf("bridge capability %q was never recorded", feature)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSend_MediaWhenBridgeSupportsIt(t *testing.T) {
+	fb := newFakeBridge(t, map[string]any{"type": "capabilities", "features": []string{"media"}})
+	ch, _ := startChannel(t, fb, config.WhatsAppConfig{Token: "secret"})
+	waitForFeature(t, ch, featureMedia)
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:  "123@s.whatsapp.net",
+		Content: "here is the chart",
+		Attachments: []bus.OutboundAttachment{
+			{Data: []byte("png-bytes"), FileName: "chart.png"},
+			{Data: []byte("%PDF"), FileName: "report.pdf", MIMEType: "application/pdf"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	if text := fb.next(t); text["type"] != "message" || text["content"] != "here is the chart" {
+		t.Fatalf("expected text frame first, got %v", text)
+	}
+	image := fb.next(t)
+	if image["type"] != "media" || image["media_type"] != "image" || image["file_name"] != "chart.png" || image["mime_type"] != "image/png" {
+		t.Fatalf("unexpected image frame: %v", image)
+	}
+	if data, _ := base64.StdEncoding.DecodeString(image["data"].(string)); string(data) != "png-bytes" {
+		t.Fatalf("expected base64 file content, got %q", image["data"])
+	}
+	if doc := fb.next(t); doc["media_type"] != "document" || doc["to"] != "123@s.whatsapp.net" {
+		t.Fatalf("unexpected document frame: %v", doc)
+	}
+
+	fb.mu.Lock()
+	auth := fb.auth
+	fb.mu.Unlock()
+	if auth != "Bearer secret" {
+		t.Fatalf("expected bridge token on handshake, got %q", auth)
+	}
+}
+
+func TestSend_AttachmentDegradesToTextWithoutMediaSupport(t *testing.T) {
+	fb := newFakeBridge(t)
+	ch, _ := startChannel(t, fb, config.WhatsAppConfig{})
+
+	err := ch.Send(context.Background(), &bus.OutboundMessage{
+		ChatID:      "123@s.whatsapp.net",
+		Content:     "done",
+		Attachments: []bus.OutboundAttachment{{Data: []byte("x"), FileName: "report.pdf"}},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	frame := fb.next(t)
+	if frame["type"] != "message" || frame["content"] != "done\n\n[attachments not sent: report.pdf]" {
+		t.Fatalf("expected attachment notice in text, got %v", frame)
+	}
+}
+
+func TestListen_MarksMessagesReadWhenEnabled(t *testing.T) {
+	fb := newFakeBridge(t, map[string]any{"type": "capabilities", "features": []string{"read"}})
+	ch, msgBus := startChannel(t, fb, config.WhatsAppConfig{MarkRead: true})
+	waitForFeature(t, ch, featureRead)
+
+	fb.push(t, map[string]any{"type": "message", "id": "m1", "from": "alice", "chat": "group-1", "content": "hi"})
+	select {
+	case msg := <-msgBus.Inbound():
+		if msg.Content != "hi" || msg.ChatID != "group-1" {
+			t.Fatalf("unexpected inbound message: %+v", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for inbound message")
+	}
+	if frame := fb.next(t); frame["type"] != "read" || frame["chat"] != "group-1" || frame["id"] != "m1" {
+		t.Fatalf("expected read receipt, got %v", frame)
+	}
+
+	// 桥接拒绝已读回执后不再发送。
+	fb.push(t, map[string]any{"type": "error", "request": "read", "error": "not implemented"})
+	deadline := time.Now().Add(2 * time.Second)
+	for ch.supports(featureRead) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ch.supports(featureRead) {
+		t.Fatal("expected read capability to be disabled after a bridge error")
+	}
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/approval"
+)
+
+// ApproveCommand 实现 /approve <id> [note] — 在聊天中批准待审批的工具调用。
+// 与 /reject 一样仅限 policy.admins 中列出的发送者，触发调用的普通用户不能批准自己的请求。
+type ApproveCommand struct{}
+
+func (c *ApproveCommand) Name() string        { return "approve" }
+func (c *ApproveCommand) Description() string { return "Approve a pending tool approval request" }
+
+func (c *ApproveCommand) Execute(ctx context.Context, args string, env Env) Result {
+	return decideApproval(args, env, true)
+}
+
+// RejectCommand 实现 /reject <id> [note] — 在聊天中拒绝待审批的工具调用。
+type RejectCommand struct{}
+
+func (c *RejectCommand) Name() string        { return "reject" }
+func (c *RejectCommand) Description() string { return "Reject a pending tool approval request" }
+
+func (c *RejectCommand) Execute(ctx context.Context, args string, env Env) Result {
+	return decideApproval(args, env, false)
+}
+
+func decideApproval(args string, env Env, approve bool) Result {
+	action := "approve"
+	if !approve {
+		action = "reject"
+	}
+	id, note, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if id == "" {
+		return Result{Content: fmt.Sprintf("Usage: `/%s <id> [note]`", action)}
+	}
+	if env.SenderUnverified {
+		return Result{Content: fmt.Sprintf("Approvals cannot be decided through the gateway API; use `golem approval %s %s --by <name>`.", action, id)}
+	}
+
+	svc := approval.NewService(env.WorkspacePath)
+	reqs, err := svc.List(approval.Query{ID: id})
+	if err != nil {
+		return Result{Content: fmt.Sprintf("Error: %v", err)}
+	}
+	if len(reqs) == 0 {
+		return Result{Content: fmt.Sprintf("Approval `%s` not found.", id)}
+	}
+	// 带有来源信息的请求只能在发起它的聊天中决策，避免其他会话代为批准
+	if req := reqs[0]; req.Channel != "" && (req.Channel != env.Channel || req.ChatID != env.ChatID) {
+		return Result{Content: fmt.Sprintf("Approval `%s` was requested from another chat; decide it there or with `golem approval %s %s --by <name>`.", id, action, id)}
+	}
+	if env.IsAdmin == nil || !env.IsAdmin(env.Channel, env.SenderID) {
+		return Result{Content: fmt.Sprintf("Deciding approvals from chat requires an admin. Add `%s:%s` to `policy.admins`, or use `golem approval %s %s --by <name>`.", env.Channel, env.SenderID, action, id)}
+	}
+
+	decision := approval.DecisionInput{
+		DecidedBy: env.Channel + ":" + env.SenderID,
+		Note:      strings.TrimSpace(note),
+	}
+	if approve {
+		req, err := svc.Approve(id, decision)
+		if err != nil {
+			return Result{Content: fmt.Sprintf("Error: %v", err)}
+		}
+		return Result{Content: fmt.Sprintf("Approval `%s` approved. Ask me to retry `%s` to run it.", req.ID, req.ToolName)}
+	}
+	req, err := svc.Reject(id, decision)
+	if err != nil {
+		return Result{Content: fmt.Sprintf("Error: %v", err)}
+	}
+	return Result{Content: fmt.Sprintf("Approval `%s` rejected; `%s` will not run.", req.ID, req.ToolName)}
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// ClearSessionCommand 实现 /clear 命令 — 清除当前会话的历史与摘要，会话键不变。
+// 与 /new 不同，/clear 只让 Agent 忘记对话内容，保留 /model 等会话内设置。
+type ClearSessionCommand struct{}
+
+// Name 返回命令名称。
+func (c *ClearSessionCommand) Name() string { return "clear" }
+
+// Description 返回命令描述。
+func (c *ClearSessionCommand) Description() string {
+	return "Forget this conversation's history, keeping the session"
+}
+
+// Execute 清除会话历史并回复确认信息。
+func (c *ClearSessionCommand) Execute(_ context.Context, _ string, env Env) Result {
+	cleared, err := env.Sessions.Clear(env.SessionKey)
+	if err != nil {
+		slog.Warn("session clear failed", "session_key", env.SessionKey, "error", err)
+		return Result{Content: fmt.Sprintf("History cleared from memory, but removing the session file failed: %v", err)}
+	}
+	slog.Info("session cleared via /clear", "session_key", env.SessionKey, "channel", env.Channel, "chat_id", env.ChatID, "messages", cleared)
+	if cleared == 0 {
+		return Result{Content: "Nothing to clear: this conversation has no history yet."}
+	}
+	return Result{Content: fmt.Sprintf("Cleared %d messages. The conversation continues in the same session with no memory of earlier turns.", cleared)}
+}
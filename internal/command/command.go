@@ -6,23 +6,41 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/metrics"
+	"github.com/MEKXH/golem/internal/policy"
 	"github.com/MEKXH/golem/internal/session"
 )
 
 // Env 携带斜杠命令执行时的上下文环境信息。
 type Env struct {
-	Channel       string                  // 消息来源通道
-	ChatID        string                  // 聊天 ID
-	SenderID      string                  // 发送者 ID
-	SessionKey    string                  // 唯一的会话标识符
-	Sessions      *session.Manager        // 会话管理器，用于操作聊天历史
-	WorkspacePath string                  // 工作区根路径
-	Config        *config.Config          // 全局配置实例
-	Metrics       *metrics.RuntimeMetrics // 运行时指标记录器
-	ListCommands  func() []Command        // 用于 /help 获取所有可用命令的回调函数
+	Channel          string                  // 消息来源通道
+	ChatID           string                  // 聊天 ID
+	SenderID         string                  // 发送者 ID
+	SenderUnverified bool                    // 通道与发送者由调用方自行声明（如网关 API 请求），不被视为管理员或审批人
+	SessionKey       string                  // 唯一的会话标识符
+	Sessions         *session.Manager        // 会话管理器，用于操作聊天历史
+	WorkspacePath    string                  // 工作区根路径
+	Config           *config.Config          // 全局配置实例
+	Metrics          *metrics.RuntimeMetrics // 运行时指标记录器
+	ListCommands     func() []Command        // 用于 /help 获取所有可用命令的回调函数
+
+	// IsAdmin 报告发送者是否在当前生效的 policy.admins 中；为空时任何发送者都不是管理员。
+	IsAdmin func(channel, sender string) bool
+
+	// PolicyState 返回运行时策略快照，SetPolicyMode 在运行时切换策略模式；为空时 /policy 不可用。
+	PolicyState   func() (policy.State, bool)
+	SetPolicyMode func(ctx context.Context, mode policy.Mode, ttl time.Duration, actor string) (policy.State, error)
+
+	// SessionModel 返回会话当前使用的模型及是否为会话内切换，SetSessionModel 为当前会话切换模型（空名称恢复默认）；
+	// 为空时 /model 不可用。
+	SessionModel    func(sessionKey, channel string) (name string, overridden bool)
+	SetSessionModel func(ctx context.Context, modelName string) error
+
+	// ListTools 返回已注册工具及其在 channel 上是否被 tool_policy 禁用；为空时 /tools 不可用。
+	ListTools func(ctx context.Context, channel string) ([]ToolSummary, error)
 }
 
 // Result 封装了斜杠命令执行后的输出内容。
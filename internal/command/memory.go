@@ -8,10 +8,11 @@ import (
 	"github.com/MEKXH/golem/internal/memory"
 )
 
-// MemoryCommand 实现 /memory 命令 — 用于读取长期记忆 (MEMORY.md) 或查询日记条目。
+// MemoryCommand 实现 /memory 命令 — 用于读取长期记忆分区或查询日记条目。
 // 使用方式:
 //
-//	/memory [read] - 读取长期记忆内容
+//	/memory [read [section]] - 读取长期记忆分区内容（默认分区为 MEMORY.md）
+//	/memory sections - 列出长期记忆分区
 //	/memory diary [date|recent] - 读取指定日期或最近的日记分录
 type MemoryCommand struct{}
 
@@ -31,20 +32,25 @@ func (c *MemoryCommand) Execute(_ context.Context, args string, env Env) Result
 
 	switch sub {
 	case "", "read":
-		return memoryRead(mgr)
+		return memoryRead(mgr, rest)
+	case "sections":
+		return memorySections(mgr)
 	case "diary":
 		return memoryDiary(mgr, rest)
 	default:
-		return Result{Content: "Usage: `/memory [read|diary [YYYY-MM-DD|recent]]`"}
+		return Result{Content: "Usage: `/memory [read [section]|sections|diary [YYYY-MM-DD|recent]]`"}
 	}
 }
 
-func memoryRead(mgr *memory.Manager) Result {
-	content, err := mgr.ReadLongTerm()
+func memoryRead(mgr *memory.Manager, section string) Result {
+	content, err := mgr.ReadLongTerm(section)
 	if err != nil {
 		return Result{Content: fmt.Sprintf("Error: %v", err)}
 	}
 	if content == "" {
+		if section = strings.TrimSpace(section); section != "" && section != memory.DefaultSection {
+			return Result{Content: fmt.Sprintf("Memory section `%s` is empty.", section)}
+		}
 		return Result{Content: "Long-term memory is empty."}
 	}
 	if len(content) > 2000 {
@@ -53,6 +59,19 @@ func memoryRead(mgr *memory.Manager) Result {
 	return Result{Content: content}
 }
 
+func memorySections(mgr *memory.Manager) Result {
+	sections, err := mgr.ListSections()
+	if err != nil {
+		return Result{Content: fmt.Sprintf("Error: %v", err)}
+	}
+	var sb strings.Builder
+	sb.WriteString("**Memory sections:**")
+	for _, s := range sections {
+		sb.WriteString("\n- `" + s + "`")
+	}
+	return Result{Content: sb.String()}
+}
+
 func memoryDiary(mgr *memory.Manager, dateOrRecent string) Result {
 	dateOrRecent = strings.TrimSpace(dateOrRecent)
 
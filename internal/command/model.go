@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/provider"
+)
+
+// ModelCommand 实现 /model [name|reset] — 查看或切换当前会话使用的模型。
+// 切换只作用于当前会话，重启后恢复配置中的模型。
+type ModelCommand struct{}
+
+func (c *ModelCommand) Name() string { return "model" }
+func (c *ModelCommand) Description() string {
+	return "Show or switch this session's model: /model <provider/model>|reset"
+}
+
+func (c *ModelCommand) Execute(ctx context.Context, args string, env Env) Result {
+	if env.SessionModel == nil || env.SetSessionModel == nil {
+		return Result{Content: "Model switching is not available."}
+	}
+
+	name := strings.TrimSpace(args)
+	switch strings.ToLower(name) {
+	case "":
+		current, overridden := env.SessionModel(env.SessionKey, env.Channel)
+		if current == "" {
+			current = "(none)"
+		}
+		content := fmt.Sprintf("**Model:** `%s`", current)
+		if overridden {
+			content += " (switched for this session; `/model reset` restores the default)"
+		}
+		return Result{Content: content + "\n" + availableModelsHint(env)}
+	case "reset", "default":
+		if err := env.SetSessionModel(ctx, ""); err != nil {
+			return Result{Content: fmt.Sprintf("Error: %v", err)}
+		}
+		current, _ := env.SessionModel(env.SessionKey, env.Channel)
+		return Result{Content: fmt.Sprintf("Model reset to the default `%s` for this session.", current)}
+	}
+
+	if len(strings.Fields(name)) != 1 {
+		return Result{Content: "Usage: `/model [<provider/model>|reset]`"}
+	}
+	if err := env.SetSessionModel(ctx, name); err != nil {
+		return Result{Content: fmt.Sprintf("Cannot switch to `%s`: %v\n%s", name, err, availableModelsHint(env))}
+	}
+	return Result{Content: fmt.Sprintf("Switched this session to `%s`.", name)}
+}
+
+// availableModelsHint 列出已配置的供应商前缀以及配置中已使用的模型。
+func availableModelsHint(env Env) string {
+	if env.Config == nil {
+		return ""
+	}
+	providers := provider.ConfiguredProviders(env.Config)
+	if len(providers) == 0 {
+		return "No providers are configured; add an api_key or base_url under `providers`."
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Configured providers:** %s", strings.Join(providers, ", "))
+	seen := make(map[string]bool)
+	var models []string
+	for _, m := range append([]string{env.Config.Agents.Defaults.Model}, sortedValues(env.Config.Agents.Defaults.ChannelModels)...) {
+		if m = strings.TrimSpace(m); m != "" && !seen[m] {
+			seen[m] = true
+			models = append(models, "`"+m+"`")
+		}
+	}
+	if len(models) > 0 {
+		fmt.Fprintf(&sb, "\n**Configured models:** %s", strings.Join(models, ", "))
+	}
+	fmt.Fprintf(&sb, "\nUse `/model %s/<model>` to pick a model from a configured provider.", providers[0])
+	return sb.String()
+}
+
+func sortedValues(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, m[k])
+	}
+	return out
+}
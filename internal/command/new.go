@@ -5,7 +5,7 @@ import (
 	"log/slog"
 )
 
-// NewSessionCommand 实现 /new 命令 — 用于重置当前会话，清除历史上下文。
+// NewSessionCommand 实现 /new 命令 — 用于重置当前会话，清除历史上下文，并恢复 /model 等会话内设置的默认值。
 type NewSessionCommand struct{}
 
 // Name 返回命令名称。
@@ -15,8 +15,11 @@ func (c *NewSessionCommand) Name() string { return "new" }
 func (c *NewSessionCommand) Description() string { return "Start a new conversation session" }
 
 // Execute 执行重置会话逻辑。
-func (c *NewSessionCommand) Execute(_ context.Context, _ string, env Env) Result {
+func (c *NewSessionCommand) Execute(ctx context.Context, _ string, env Env) Result {
 	env.Sessions.Reset(env.SessionKey)
+	if env.SetSessionModel != nil {
+		_ = env.SetSessionModel(ctx, "")
+	}
 	slog.Info("session reset via /new", "session_key", env.SessionKey, "channel", env.Channel, "chat_id", env.ChatID)
 	return Result{Content: "New session started."}
 }
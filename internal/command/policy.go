@@ -0,0 +1,100 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/policy"
+)
+
+// persistPolicyMode 将运行时切换的模式写回配置文件，测试中可替换。
+var persistPolicyMode = savePolicyMode
+
+// PolicyCommand 实现 /policy [strict|relaxed|off [ttl]] — 查看或在运行时切换策略模式。
+// 查看对所有人开放；切换仅限 policy.admins 中列出的发送者。
+type PolicyCommand struct{}
+
+func (c *PolicyCommand) Name() string { return "policy" }
+func (c *PolicyCommand) Description() string {
+	return "Show the policy mode, or switch it: /policy strict|relaxed|off [ttl]"
+}
+
+func (c *PolicyCommand) Execute(ctx context.Context, args string, env Env) Result {
+	if env.PolicyState == nil || env.SetPolicyMode == nil {
+		return Result{Content: "Runtime policy is not available."}
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 || strings.EqualFold(fields[0], "status") {
+		state, ok := env.PolicyState()
+		if !ok {
+			return Result{Content: "Runtime policy is not configured."}
+		}
+		return Result{Content: formatPolicyState(state, time.Now().UTC())}
+	}
+
+	mode := policy.Mode(strings.ToLower(fields[0]))
+	var ttl time.Duration
+	switch {
+	case len(fields) > 2, len(fields) == 2 && mode != policy.ModeOff:
+		return Result{Content: "Usage: `/policy [status|strict|relaxed|off [ttl]]`"}
+	case len(fields) == 2:
+		d, err := time.ParseDuration(fields[1])
+		if err != nil || d <= 0 {
+			return Result{Content: fmt.Sprintf("Invalid ttl %q: use a positive duration such as `30m`.", fields[1])}
+		}
+		ttl = d
+	}
+
+	if env.SenderUnverified {
+		return Result{Content: "Changing the policy mode requires an admin; gateway API requests cannot act as one."}
+	}
+	if env.IsAdmin == nil || !env.IsAdmin(env.Channel, env.SenderID) {
+		return Result{Content: fmt.Sprintf("Changing the policy mode requires an admin. Add `%s:%s` to `policy.admins` to allow it.", env.Channel, env.SenderID)}
+	}
+
+	state, err := env.SetPolicyMode(ctx, mode, ttl, env.Channel+":"+env.SenderID)
+	if err != nil {
+		return Result{Content: fmt.Sprintf("Error: %v", err)}
+	}
+	content := formatPolicyState(state, time.Now().UTC())
+	if err := persistPolicyMode(mode, ttl); err != nil {
+		content += fmt.Sprintf("\n\nWarning: applied to the running agent only; saving config failed: %v", err)
+	}
+	return Result{Content: content}
+}
+
+func formatPolicyState(state policy.State, now time.Time) string {
+	effective, expired := state.Effective(now)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Policy:** `%s`", state.BaseMode)
+	switch {
+	case expired:
+		fmt.Fprintf(&sb, " (off_ttl expired; effective `%s`)", effective)
+	case state.Remaining(now) > 0:
+		fmt.Fprintf(&sb, " (reverts to strict in %s)", state.Remaining(now).Round(time.Second))
+	case effective == policy.ModeOff:
+		sb.WriteString(" (no ttl — guardrails stay off until changed)")
+	}
+	if len(state.RequireApproval) > 0 {
+		fmt.Fprintf(&sb, "\n**Require approval:** %s", strings.Join(state.RequireApproval, ", "))
+	}
+	return sb.String()
+}
+
+// savePolicyMode 将模式与 off_ttl 写入配置文件，使重启后保持运行时切换的结果。
+func savePolicyMode(mode policy.Mode, ttl time.Duration) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.Policy.Mode = string(mode)
+	cfg.Policy.OffTTL = ""
+	if ttl > 0 {
+		cfg.Policy.OffTTL = ttl.String()
+	}
+	return config.Save(cfg)
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ToolSummary 描述一个已注册工具，供 /tools 展示。
+type ToolSummary struct {
+	Name        string
+	Description string
+	Schema      string // 输入参数的 JSON Schema（已缩进），无法生成时为空
+	Disabled    string // 非空时表示该工具在当前通道被 tool_policy 禁用，内容为原因
+}
+
+// ToolsCommand 实现 /tools [--verbose] [name] — 列出已注册的工具，--verbose 时附带输入参数的 JSON Schema。
+// 被当前通道 tool_policy 禁用的工具会单独标出。
+type ToolsCommand struct{}
+
+func (c *ToolsCommand) Name() string { return "tools" }
+func (c *ToolsCommand) Description() string {
+	return "List available tools: /tools [--verbose] [name]"
+}
+
+func (c *ToolsCommand) Execute(ctx context.Context, args string, env Env) Result {
+	if env.ListTools == nil {
+		return Result{Content: "Tool listing is not available."}
+	}
+
+	verbose := false
+	filter := ""
+	for _, field := range strings.Fields(args) {
+		switch strings.ToLower(field) {
+		case "--verbose", "-v":
+			verbose = true
+		default:
+			if filter != "" || strings.HasPrefix(field, "-") {
+				return Result{Content: "Usage: `/tools [--verbose] [name]`"}
+			}
+			filter = field
+		}
+	}
+
+	all, err := env.ListTools(ctx, env.Channel)
+	if err != nil {
+		return Result{Content: fmt.Sprintf("Error: %v", err)}
+	}
+	list := all
+	if filter != "" {
+		list = nil
+		for _, t := range all {
+			if strings.EqualFold(t.Name, filter) {
+				list = append(list, t)
+			}
+		}
+		if len(list) == 0 {
+			return Result{Content: fmt.Sprintf("No tool named `%s`. Use `/tools` to list all tools.", filter)}
+		}
+		// 指定单个工具时总是显示参数 Schema
+		verbose = true
+	}
+	if len(list) == 0 {
+		return Result{Content: "No tools are registered."}
+	}
+
+	disabled := 0
+	var sb strings.Builder
+	if filter == "" {
+		fmt.Fprintf(&sb, "**Tools (%d):**\n\n", len(list))
+	}
+	for _, t := range list {
+		desc := t.Description
+		if !verbose {
+			desc = firstLine(desc)
+		}
+		fmt.Fprintf(&sb, "- `%s` — %s", t.Name, desc)
+		if t.Disabled != "" {
+			disabled++
+			sb.WriteString(" _(disabled on this channel)_")
+		}
+		sb.WriteString("\n")
+		if verbose {
+			if t.Disabled != "" {
+				fmt.Fprintf(&sb, "  Disabled: %s\n", t.Disabled)
+			}
+			if t.Schema != "" {
+				fmt.Fprintf(&sb, "```json\n%s\n```\n", t.Schema)
+			}
+		}
+	}
+	if disabled > 0 && !verbose {
+		fmt.Fprintf(&sb, "\n%d disabled by `channels.%s.tool_policy`.", disabled, env.Channel)
+	}
+	if !verbose {
+		sb.WriteString("\nUse `/tools --verbose` or `/tools <name>` to see input schemas.")
+	}
+	return Result{Content: strings.TrimRight(sb.String(), "\n")}
+}
+
+// firstLine 返回描述的第一行，用于简要列表。
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/session"
+)
+
+// UsageCommand 实现 /usage 命令 — 显示当前会话按模型统计的 Token 用量，并按 agents.defaults.model_prices 估算费用。
+type UsageCommand struct{}
+
+// Name 返回命令名称。
+func (c *UsageCommand) Name() string { return "usage" }
+
+// Description 返回命令描述。
+func (c *UsageCommand) Description() string {
+	return "Show token usage and estimated cost for this session"
+}
+
+// Execute 汇总会话的 Token 用量。
+func (c *UsageCommand) Execute(_ context.Context, _ string, env Env) Result {
+	usage := env.Sessions.GetOrCreate(env.SessionKey).Usage()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Usage for this session** (`%s`)\n\n", env.SessionKey)
+	if len(usage) == 0 {
+		sb.WriteString("No model calls recorded yet.")
+		return Result{Content: sb.String()}
+	}
+
+	var defaults config.AgentDefaults
+	if env.Config != nil {
+		defaults = env.Config.Agents.Defaults
+	}
+	var total session.TokenUsage
+	var cost float64
+	var unpriced []string
+	for _, model := range sortedUsageModels(usage) {
+		u := usage[model]
+		total.PromptTokens += u.PromptTokens
+		total.CompletionTokens += u.CompletionTokens
+		total.Calls += u.Calls
+
+		line := fmt.Sprintf("- `%s`: %d prompt + %d completion = %d tokens (%d calls)",
+			model, u.PromptTokens, u.CompletionTokens, u.Total(), u.Calls)
+		if price, ok := defaults.PriceFor(model); ok {
+			modelCost := price.Cost(u.PromptTokens, u.CompletionTokens)
+			cost += modelCost
+			line += fmt.Sprintf(", ~%s", formatCost(modelCost))
+		} else {
+			unpriced = append(unpriced, model)
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	fmt.Fprintf(&sb, "\n**Total:** %d prompt + %d completion = %d tokens", total.PromptTokens, total.CompletionTokens, total.Total())
+	if len(unpriced) < len(usage) {
+		fmt.Fprintf(&sb, ", estimated cost ~%s", formatCost(cost))
+	}
+	if len(unpriced) > 0 {
+		fmt.Fprintf(&sb, "\nNo price configured for `%s`; add it to `agents.defaults.model_prices` to estimate its cost.", strings.Join(unpriced, "`, `"))
+	}
+	return Result{Content: sb.String()}
+}
+
+func sortedUsageModels(usage map[string]session.TokenUsage) []string {
+	models := make([]string, 0, len(usage))
+	for model := range usage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// formatCost 以四位小数显示估算费用，小额费用不至于显示为 0。
+func formatCost(cost float64) string {
+	return fmt.Sprintf("%.4f", cost)
+}
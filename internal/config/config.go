@@ -2,9 +2,9 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +20,7 @@ type Config struct {
 	Channels  ChannelsConfig  `mapstructure:"channels"`  // 消息通道配置
 	Providers ProvidersConfig `mapstructure:"providers"` // LLM 供应商配置
 	Gateway   GatewayConfig   `mapstructure:"gateway"`   // 网关服务器配置
+	HTTP      HTTPConfig      `mapstructure:"http"`      // 出站 HTTP 客户端配置
 	Log       LogConfig       `mapstructure:"log"`       // 日志配置
 	Policy    PolicyConfig    `mapstructure:"policy"`    // 运行时策略配置
 	MCP       MCPConfig       `mapstructure:"mcp"`       // MCP 服务器配置
@@ -33,6 +34,23 @@ type PolicyConfig struct {
 	OffTTL             string   `mapstructure:"off_ttl"`
 	AllowPersistentOff bool     `mapstructure:"allow_persistent_off"`
 	RequireApproval    []string `mapstructure:"require_approval"`
+	Admins             []string `mapstructure:"admins"` // 允许在聊天中通过 /policy 切换模式、审批工具调用的发送者，格式 channel:sender_id
+}
+
+// IsAdmin 报告 channel 上的 sender 是否在 policy.admins 中；只做精确匹配，不支持通配。
+func (p PolicyConfig) IsAdmin(channel, sender string) bool {
+	channel = strings.ToLower(strings.TrimSpace(channel))
+	sender = strings.TrimSpace(sender)
+	if channel == "" || sender == "" {
+		return false
+	}
+	for _, admin := range p.Admins {
+		adminChannel, adminSender, ok := strings.Cut(strings.TrimSpace(admin), ":")
+		if ok && strings.EqualFold(adminChannel, channel) && adminSender == sender {
+			return true
+		}
+	}
+	return false
 }
 
 // MCPConfig MCP 服务器设置。
@@ -49,8 +67,15 @@ type MCPServerConfig struct {
 	Env       map[string]string `mapstructure:"env"`
 	URL       string            `mapstructure:"url"`
 	Headers   map[string]string `mapstructure:"headers"`
+	// CallTimeoutSeconds 单次 MCP 调用（工具、资源、提示词）的超时秒数，0 使用默认值 60。
+	CallTimeoutSeconds int `mapstructure:"call_timeout_seconds"`
+	// StderrTailBytes stdio 服务器保留的 stderr 尾部字节数，0 使用默认值 4096，上限 1 MiB。
+	StderrTailBytes int `mapstructure:"stderr_tail_bytes"`
 }
 
+// MaxMCPStderrTailBytes 是 stdio MCP 服务器 stderr 尾部缓冲的上限。
+const MaxMCPStderrTailBytes = 1 << 20
+
 // IsMCPServerEnabled 如果服务器未被显式禁用则返回 true。
 func IsMCPServerEnabled(server MCPServerConfig) bool {
 	if server.Enabled == nil {
@@ -63,16 +88,85 @@ func IsMCPServerEnabled(server MCPServerConfig) bool {
 type AgentsConfig struct {
 	Defaults AgentDefaults         `mapstructure:"defaults"`
 	Subagent SubagentRuntimeConfig `mapstructure:"subagent"`
+	Summary  HistorySummaryConfig  `mapstructure:"summary"`
+	Memory   MemoryConfig          `mapstructure:"memory"`
+}
+
+// MemoryConfig 控制记忆召回与日记保留。启用语义召回后，日记与长期记忆按片段向量化并缓存到磁盘，
+// 按与查询的余弦相似度检索，结果与关键词命中合并；向量接口不可用时退回关键词召回。
+type MemoryConfig struct {
+	SemanticRecall bool   `mapstructure:"semantic_recall"`  // 是否启用语义召回（每条消息额外调用一次向量接口）
+	EmbeddingModel string `mapstructure:"embedding_model"`  // 向量模型；带供应商前缀（如 openai/text-embedding-3-small）时使用该供应商，否则使用当前供应商
+	SemanticLimit  int    `mapstructure:"semantic_limit"`   // 每次召回的语义片段上限
+	MaxIndexChunks int    `mapstructure:"max_index_chunks"` // 向量索引的片段上限，超出时丢弃最早的日记片段
+	RetentionDays  int    `mapstructure:"retention_days"`   // 早于该天数的日记归档到 memory/archive/；0 表示不限制
+	MaxDiaries     int    `mapstructure:"max_diaries"`      // 记忆目录中最多保留的日记天数；0 表示不限制
+}
+
+// HistorySummaryConfig 控制会话历史的摘要压缩：消息数超过阈值时用模型把较早的消息总结为摘要。
+type HistorySummaryConfig struct {
+	Enabled         bool `mapstructure:"enabled"`          // 是否启用（每次压缩额外消耗一次模型调用）
+	TriggerMessages int  `mapstructure:"trigger_messages"` // 会话消息数超过该值时触发压缩
+	KeepRecent      int  `mapstructure:"keep_recent"`      // 压缩后保留的最近消息数
 }
 
 // AgentDefaults 默认代理参数
 type AgentDefaults struct {
-	Workspace         string  `mapstructure:"workspace"`
-	WorkspaceMode     string  `mapstructure:"workspace_mode"`
-	Model             string  `mapstructure:"model"`
-	MaxTokens         int     `mapstructure:"max_tokens"`
-	Temperature       float64 `mapstructure:"temperature"`
-	MaxToolIterations int     `mapstructure:"max_tool_iterations"`
+	Workspace         string            `mapstructure:"workspace"`
+	WorkspaceMode     string            `mapstructure:"workspace_mode"`
+	Model             string            `mapstructure:"model"`
+	ChannelModels     map[string]string `mapstructure:"channel_models"` // 按通道覆盖模型，如 {"telegram": "deepseek/deepseek-chat"}
+	MaxTokens         int               `mapstructure:"max_tokens"`
+	Temperature       float64           `mapstructure:"temperature"`
+	MaxToolIterations int               `mapstructure:"max_tool_iterations"`
+	MaxSessionHistory int               `mapstructure:"max_session_history"` // 每个会话持久化保留的最大消息数
+	MaxHistoryTokens  int               `mapstructure:"max_history_tokens"`  // 发送给模型的上下文（系统提示、历史与当前输入）的估算 Token 上限
+
+	// 替换内置身份说明的系统提示词；以 "file:" 开头时从文件读取（相对路径基于工作区），支持 {{date}}、{{workspace}}、{{channel}}
+	SystemPrompt string `mapstructure:"system_prompt"`
+	// 按通道覆盖的系统提示词，格式同 system_prompt
+	ChannelSystemPrompts map[string]string `mapstructure:"channel_system_prompts"`
+
+	// 按模型名配置的价格表，用于 /usage 与 golem usage 估算费用；键可以是 "provider/model" 或不带前缀的模型名
+	ModelPrices map[string]ModelPrice `mapstructure:"model_prices"`
+	// 额外视为支持图片输入的模型名前缀，补充内置列表；图片附件只会发送给支持视觉的模型
+	VisionModels []string `mapstructure:"vision_models"`
+
+	// 同时处理消息的会话数上限；同一会话内的消息始终按顺序处理
+	MaxConcurrentSessions int `mapstructure:"max_concurrent_sessions"`
+	// 单轮对话（含全部模型与工具调用）的总时限（秒），0 表示不限制
+	TurnTimeoutSeconds int `mapstructure:"turn_timeout_seconds"`
+	// 模型单次回复中的工具调用并行执行数上限，超出的调用排队等待空闲名额
+	MaxParallelTools int `mapstructure:"max_parallel_tools"`
+
+	// 模型调用遇到可重试错误（超时、429、5xx）时的重试策略
+	ModelRetryMaxAttempts   int `mapstructure:"model_retry_max_attempts"`    // 最大尝试次数（含首次）
+	ModelRetryBaseBackoffMs int `mapstructure:"model_retry_base_backoff_ms"` // 基础退避毫秒数，按指数增长并加入随机抖动
+	ModelRetryMaxBackoffMs  int `mapstructure:"model_retry_max_backoff_ms"`  // 单次退避上限毫秒数
+}
+
+// ModelPrice 是一个模型每百万 Token 的价格，货币单位由用户自行约定（通常为美元）。
+type ModelPrice struct {
+	InputPerMillion  float64 `mapstructure:"input_per_million"`  // 每百万输入 Token 的价格
+	OutputPerMillion float64 `mapstructure:"output_per_million"` // 每百万输出 Token 的价格
+}
+
+// Cost 按价格估算给定 Token 数的费用。
+func (p ModelPrice) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6
+}
+
+// PriceFor 返回模型的价格：优先精确匹配 "provider/model"，否则匹配不带供应商前缀的模型名。
+func (d AgentDefaults) PriceFor(model string) (ModelPrice, bool) {
+	model = strings.TrimSpace(model)
+	if price, ok := d.ModelPrices[model]; ok {
+		return price, true
+	}
+	if _, name, found := strings.Cut(model, "/"); found {
+		price, ok := d.ModelPrices[name]
+		return price, ok
+	}
+	return ModelPrice{}, false
 }
 
 // SubagentRuntimeConfig 控制委托子代理执行策略。
@@ -80,21 +174,58 @@ type SubagentRuntimeConfig struct {
 	TimeoutSeconds int `mapstructure:"timeout_seconds"`
 	Retry          int `mapstructure:"retry"`
 	MaxConcurrency int `mapstructure:"max_concurrency"`
+	MaxDepth       int `mapstructure:"max_depth"` // 子代理嵌套委派的最大深度，主代理直接委派的子代理深度为 1
 }
 
 // ChannelsConfig 通道设置
 type ChannelsConfig struct {
-	Telegram TelegramConfig        `mapstructure:"telegram"`
-	WhatsApp WhatsAppConfig        `mapstructure:"whatsapp"`
-	Feishu   FeishuConfig          `mapstructure:"feishu"`
-	Discord  DiscordConfig         `mapstructure:"discord"`
-	Slack    SlackConfig           `mapstructure:"slack"`
-	QQ       QQConfig              `mapstructure:"qq"`
-	DingTalk DingTalkConfig        `mapstructure:"dingtalk"`
-	MaixCam  MaixCamConfig         `mapstructure:"maixcam"`
-	Outbound ChannelOutboundConfig `mapstructure:"outbound"`
+	Telegram   TelegramConfig         `mapstructure:"telegram"`
+	WhatsApp   WhatsAppConfig         `mapstructure:"whatsapp"`
+	Feishu     FeishuConfig           `mapstructure:"feishu"`
+	Discord    DiscordConfig          `mapstructure:"discord"`
+	Slack      SlackConfig            `mapstructure:"slack"`
+	QQ         QQConfig               `mapstructure:"qq"`
+	DingTalk   DingTalkConfig         `mapstructure:"dingtalk"`
+	MaixCam    MaixCamConfig          `mapstructure:"maixcam"`
+	Mattermost MattermostConfig       `mapstructure:"mattermost"`
+	Matrix     MatrixConfig           `mapstructure:"matrix"`
+	Twilio     TwilioConfig           `mapstructure:"twilio"`
+	Outbound   ChannelOutboundConfig  `mapstructure:"outbound"`
+	Inbound    ChannelInboundConfig   `mapstructure:"inbound"`
+	Reactions  ChannelReactionsConfig `mapstructure:"reactions"`
+}
+
+// 表情反应的默认值，均在 Telegram 允许的反应表情范围内。
+const (
+	DefaultReactionProcessing = "👀"
+	DefaultReactionDone       = "👍"
+	DefaultReactionFailed     = "👎"
+)
+
+// ChannelReactionsConfig 控制处理入站消息时在原消息上添加的表情反应：开始处理时添加 Processing，
+// 结束后换成 Done 或 Failed。仅对实现了 channel.Reactor 的通道生效。
+type ChannelReactionsConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Processing string `mapstructure:"processing"` // 开始处理时的表情
+	Done       string `mapstructure:"done"`       // 成功回复后的表情
+	Failed     string `mapstructure:"failed"`     // 处理失败后的表情
 }
 
+// ChannelInboundConfig 控制入站消息的防滥用行为。
+type ChannelInboundConfig struct {
+	RateLimitPerMinute int    `mapstructure:"rate_limit_per_minute"` // 每个发送者每分钟可触发的请求数，0 表示不限制
+	RateLimitBurst     int    `mapstructure:"rate_limit_burst"`      // 允许的突发请求数，0 表示等于 rate_limit_per_minute
+	DedupWindowSeconds int    `mapstructure:"dedup_window_seconds"`  // 重复投递事件的去重窗口（秒）
+	MaxMessageChars    int    `mapstructure:"max_message_chars"`     // 单条消息正文（含语音转录与附件标记）的字符数上限，0 表示不限制
+	OversizePolicy     string `mapstructure:"oversize_policy"`       // 超出上限时的处理方式：truncate 或 reject
+}
+
+// 超长入站消息处理策略（oversize_policy）的取值。
+const (
+	OversizeTruncate = "truncate" // 截断到上限并告知用户（默认）
+	OversizeReject   = "reject"   // 拒绝处理并告知用户
+)
+
 // ChannelOutboundConfig 控制出站可靠性行为。
 type ChannelOutboundConfig struct {
 	MaxConcurrentSends int `mapstructure:"max_concurrent_sends"`
@@ -105,67 +236,157 @@ type ChannelOutboundConfig struct {
 	DedupWindowSeconds int `mapstructure:"dedup_window_seconds"`
 }
 
+// ToolPolicyConfig 通道级工具访问控制，在全局 policy 之前生效。
+type ToolPolicyConfig struct {
+	Allow []string `mapstructure:"allow"` // 非空时仅允许列出的工具
+	Deny  []string `mapstructure:"deny"`  // 始终禁止的工具，优先于 allow
+}
+
+// IsEmpty 报告该策略是否未配置任何规则。
+func (p ToolPolicyConfig) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0
+}
+
+// ToolPolicies 返回配置了 tool_policy 的通道及其策略，键为通道名。
+func (c ChannelsConfig) ToolPolicies() map[string]ToolPolicyConfig {
+	all := map[string]ToolPolicyConfig{
+		"telegram":   c.Telegram.ToolPolicy,
+		"whatsapp":   c.WhatsApp.ToolPolicy,
+		"feishu":     c.Feishu.ToolPolicy,
+		"discord":    c.Discord.ToolPolicy,
+		"slack":      c.Slack.ToolPolicy,
+		"qq":         c.QQ.ToolPolicy,
+		"dingtalk":   c.DingTalk.ToolPolicy,
+		"maixcam":    c.MaixCam.ToolPolicy,
+		"mattermost": c.Mattermost.ToolPolicy,
+		"matrix":     c.Matrix.ToolPolicy,
+		"twilio":     c.Twilio.ToolPolicy,
+	}
+	policies := make(map[string]ToolPolicyConfig)
+	for name, p := range all {
+		if !p.IsEmpty() {
+			policies[name] = p
+		}
+	}
+	return policies
+}
+
+// 群聊回复策略（respond_when）的取值。
+const (
+	RespondAlways  = "always"  // 响应所有允许的消息（默认）
+	RespondMention = "mention" // 群聊中仅在提及或回复机器人时响应，私聊始终响应
+)
+
 // TelegramConfig Telegram 机器人设置
 type TelegramConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	Token     string   `mapstructure:"token"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled      bool             `mapstructure:"enabled"`
+	Token        string           `mapstructure:"token"`
+	AllowFrom    []string         `mapstructure:"allow_from"`
+	VoiceReplies bool             `mapstructure:"voice_replies"` // 以语音消息回复语音输入，需开启 tools.voice
+	RespondWhen  string           `mapstructure:"respond_when"`  // always 或 mention，见 RespondMention
+	ToolPolicy   ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // WhatsAppConfig WhatsApp 桥接设置
 type WhatsAppConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	BridgeURL string   `mapstructure:"bridge_url"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled    bool             `mapstructure:"enabled"`
+	BridgeURL  string           `mapstructure:"bridge_url"`
+	Token      string           `mapstructure:"token"`     // 桥接鉴权令牌，非空时握手携带 Authorization: Bearer
+	MarkRead   bool             `mapstructure:"mark_read"` // 为已接收的消息发送已读回执（需桥接支持）
+	AllowFrom  []string         `mapstructure:"allow_from"`
+	ToolPolicy ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // FeishuConfig 飞书机器人设置
 type FeishuConfig struct {
-	Enabled           bool     `mapstructure:"enabled"`
-	AppID             string   `mapstructure:"app_id"`
-	AppSecret         string   `mapstructure:"app_secret"`
-	EncryptKey        string   `mapstructure:"encrypt_key"`
-	VerificationToken string   `mapstructure:"verification_token"`
-	AllowFrom         []string `mapstructure:"allow_from"`
+	Enabled           bool             `mapstructure:"enabled"`
+	AppID             string           `mapstructure:"app_id"`
+	AppSecret         string           `mapstructure:"app_secret"`
+	EncryptKey        string           `mapstructure:"encrypt_key"`
+	VerificationToken string           `mapstructure:"verification_token"`
+	AllowFrom         []string         `mapstructure:"allow_from"`
+	ToolPolicy        ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // DiscordConfig Discord 机器人设置
 type DiscordConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	Token     string   `mapstructure:"token"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled       bool             `mapstructure:"enabled"`
+	Token         string           `mapstructure:"token"`
+	AllowFrom     []string         `mapstructure:"allow_from"`
+	VoiceReplies  bool             `mapstructure:"voice_replies"`  // 以音频文件回复语音输入，需开启 tools.voice
+	SlashCommands bool             `mapstructure:"slash_commands"` // 启动时注册 /ask 与 /status 斜杠命令
+	RespondWhen   string           `mapstructure:"respond_when"`   // always 或 mention，见 RespondMention
+	ToolPolicy    ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // SlackConfig Slack 机器人设置
 type SlackConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	BotToken  string   `mapstructure:"bot_token"`
-	AppToken  string   `mapstructure:"app_token"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled     bool             `mapstructure:"enabled"`
+	BotToken    string           `mapstructure:"bot_token"`
+	AppToken    string           `mapstructure:"app_token"`
+	AllowFrom   []string         `mapstructure:"allow_from"`
+	RespondWhen string           `mapstructure:"respond_when"` // always 或 mention，见 RespondMention
+	BlockKit    bool             `mapstructure:"block_kit"`    // 以 Block Kit 渲染回复中的 Markdown，失败时回退为纯文本
+	ToolPolicy  ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // QQConfig QQ 机器人设置
 type QQConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	AppID     string   `mapstructure:"app_id"`
-	AppSecret string   `mapstructure:"app_secret"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled    bool             `mapstructure:"enabled"`
+	AppID      string           `mapstructure:"app_id"`
+	AppSecret  string           `mapstructure:"app_secret"`
+	AllowFrom  []string         `mapstructure:"allow_from"`
+	ToolPolicy ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // DingTalkConfig DingTalk stream mode settings
 type DingTalkConfig struct {
-	Enabled      bool     `mapstructure:"enabled"`
-	ClientID     string   `mapstructure:"client_id"`
-	ClientSecret string   `mapstructure:"client_secret"`
-	AllowFrom    []string `mapstructure:"allow_from"`
+	Enabled      bool             `mapstructure:"enabled"`
+	ClientID     string           `mapstructure:"client_id"`
+	ClientSecret string           `mapstructure:"client_secret"`
+	AllowFrom    []string         `mapstructure:"allow_from"`
+	ToolPolicy   ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // MaixCamConfig MaixCam bridge settings
 type MaixCamConfig struct {
-	Enabled   bool     `mapstructure:"enabled"`
-	Host      string   `mapstructure:"host"`
-	Port      int      `mapstructure:"port"`
-	AllowFrom []string `mapstructure:"allow_from"`
+	Enabled    bool             `mapstructure:"enabled"`
+	Host       string           `mapstructure:"host"`
+	Port       int              `mapstructure:"port"`
+	AllowFrom  []string         `mapstructure:"allow_from"`
+	ToolPolicy ToolPolicyConfig `mapstructure:"tool_policy"`
+}
+
+// MattermostConfig Mattermost 机器人设置
+type MattermostConfig struct {
+	Enabled    bool             `mapstructure:"enabled"`
+	URL        string           `mapstructure:"url"`   // 服务器地址，如 https://mattermost.example.com
+	Token      string           `mapstructure:"token"` // 机器人账号的访问令牌
+	AllowFrom  []string         `mapstructure:"allow_from"`
+	ToolPolicy ToolPolicyConfig `mapstructure:"tool_policy"`
+}
+
+// MatrixConfig Matrix 客户端设置（暂不支持端到端加密房间）
+type MatrixConfig struct {
+	Enabled     bool             `mapstructure:"enabled"`
+	Homeserver  string           `mapstructure:"homeserver"`   // 服务器地址，如 https://matrix.example.org
+	AccessToken string           `mapstructure:"access_token"` // 机器人账号的访问令牌
+	AllowFrom   []string         `mapstructure:"allow_from"`   // Matrix 用户 ID，如 @alice:example.org
+	ToolPolicy  ToolPolicyConfig `mapstructure:"tool_policy"`
+}
+
+// TwilioConfig Twilio 短信设置：通过 webhook 接收短信，通过 REST API 回复
+type TwilioConfig struct {
+	Enabled    bool             `mapstructure:"enabled"`
+	AccountSID string           `mapstructure:"account_sid"`
+	AuthToken  string           `mapstructure:"auth_token"`  // 同时用于校验 webhook 签名
+	FromNumber string           `mapstructure:"from_number"` // 发送短信使用的 Twilio 号码（E.164 格式）
+	Host       string           `mapstructure:"host"`        // webhook 监听地址
+	Port       int              `mapstructure:"port"`
+	Path       string           `mapstructure:"path"`        // webhook 路径，默认 /twilio/sms
+	WebhookURL string           `mapstructure:"webhook_url"` // 在 Twilio 控制台配置的公网地址，反向代理后必填
+	AllowFrom  []string         `mapstructure:"allow_from"`  // 允许的手机号（E.164 格式）
+	ToolPolicy ToolPolicyConfig `mapstructure:"tool_policy"`
 }
 
 // ProvidersConfig LLM provider settings
@@ -195,18 +416,89 @@ type GatewayConfig struct {
 	Token string `mapstructure:"token"`
 }
 
+// HTTPConfig 是所有出站 HTTP 请求共用的客户端设置，由 internal/httpclient 应用。
+type HTTPConfig struct {
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // 未单独配置超时的组件使用的请求超时（秒），0 表示沿用各组件的内置默认值
+	Proxy          string `mapstructure:"proxy"`           // HTTP 与 HTTPS 请求使用的代理地址，为空时读取 HTTP_PROXY/HTTPS_PROXY
+	NoProxy        string `mapstructure:"no_proxy"`        // 不经代理的主机列表，格式同 NO_PROXY，为空时读取 NO_PROXY
+}
+
 // LogConfig application logging settings
 type LogConfig struct {
-	Level string `mapstructure:"level"`
-	File  string `mapstructure:"file"`
+	Level    string            `mapstructure:"level"`
+	File     string            `mapstructure:"file"`
+	Rotation LogRotationConfig `mapstructure:"rotation"` // log.file 的轮转与保留策略
+	Audit    LogRotationConfig `mapstructure:"audit"`    // <workspace>/state/audit.jsonl 的轮转与保留策略
+}
+
+// LogRotationConfig 日志按大小轮转与保留策略；历史分段命名为 <file>.1、<file>.2.gz 等。
+type LogRotationConfig struct {
+	MaxSizeMB      int  `mapstructure:"max_size_mb"`       // 活动文件达到该大小时轮转
+	MaxBackups     int  `mapstructure:"max_backups"`       // 最多保留的历史分段数
+	MaxAgeDays     int  `mapstructure:"max_age_days"`      // 历史分段最长保留天数
+	MaxTotalSizeMB int  `mapstructure:"max_total_size_mb"` // 活动文件与历史分段的总大小上限
+	Compress       bool `mapstructure:"compress"`          // 是否 gzip 压缩历史分段
+}
+
+// 日志轮转默认值：应用日志与审计日志分别设置。
+var (
+	defaultLogRotation = LogRotationConfig{
+		MaxSizeMB:      20,
+		MaxBackups:     5,
+		MaxAgeDays:     30,
+		MaxTotalSizeMB: 200,
+		Compress:       true,
+	}
+	defaultAuditRotation = LogRotationConfig{
+		MaxSizeMB:      50,
+		MaxBackups:     10,
+		MaxAgeDays:     90,
+		MaxTotalSizeMB: 1000,
+		Compress:       true,
+	}
+)
+
+// normalize 校验轮转配置并为 0 值字段填充默认值。
+func (r *LogRotationConfig) normalize(prefix string, defaults LogRotationConfig) error {
+	fields := []struct {
+		name  string
+		value *int
+		def   int
+	}{
+		{"max_size_mb", &r.MaxSizeMB, defaults.MaxSizeMB},
+		{"max_backups", &r.MaxBackups, defaults.MaxBackups},
+		{"max_age_days", &r.MaxAgeDays, defaults.MaxAgeDays},
+		{"max_total_size_mb", &r.MaxTotalSizeMB, defaults.MaxTotalSizeMB},
+	}
+	for _, f := range fields {
+		if *f.value < 0 {
+			return fmt.Errorf("%s.%s must not be negative, got %d", prefix, f.name, *f.value)
+		}
+		if *f.value == 0 {
+			*f.value = f.def
+		}
+	}
+	if r.MaxTotalSizeMB < r.MaxSizeMB {
+		return fmt.Errorf("%s.max_total_size_mb (%d) must be at least max_size_mb (%d)", prefix, r.MaxTotalSizeMB, r.MaxSizeMB)
+	}
+	return nil
 }
 
 // ToolsConfig tool settings
 type ToolsConfig struct {
-	Web   WebToolsConfig  `mapstructure:"web"`
-	Exec  ExecToolConfig  `mapstructure:"exec"`
-	Voice VoiceToolConfig `mapstructure:"voice"`
-	Geo   GeoToolsConfig  `mapstructure:"geo"`
+	Web            WebToolsConfig       `mapstructure:"web"`
+	Exec           ExecToolConfig       `mapstructure:"exec"`
+	Voice          VoiceToolConfig      `mapstructure:"voice"`
+	Geo            GeoToolsConfig       `mapstructure:"geo"`
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig per-tool circuit breaker settings.
+type CircuitBreakerConfig struct {
+	Enabled          bool `mapstructure:"enabled"`           // 默认关闭
+	FailureThreshold int  `mapstructure:"failure_threshold"` // 窗口内连续多少次依赖故障（超时、MCP 或网络错误）后熔断
+	WindowSeconds    int  `mapstructure:"window_seconds"`    // 连续失败的统计窗口（秒）
+	CooldownSeconds  int  `mapstructure:"cooldown_seconds"`  // 熔断后短路调用的冷却时长（秒），结束后半开试探
 }
 
 // GeoToolsConfig GIS/geospatial tool settings.
@@ -223,27 +515,84 @@ type GeoToolsConfig struct {
 
 // WebToolsConfig web tool settings
 type WebToolsConfig struct {
-	Search WebSearchConfig `mapstructure:"search"`
+	Search       WebSearchConfig `mapstructure:"search"`
+	Fetch        WebFetchConfig  `mapstructure:"fetch"`
+	AllowPrivate bool            `mapstructure:"allow_private"` // 允许 web_fetch 访问回环、链路本地与私有网段地址
 }
 
-// WebSearchConfig brave search settings
+// WebFetchConfig web_fetch settings
+type WebFetchConfig struct {
+	CacheTTLSeconds  int  `mapstructure:"cache_ttl_seconds"`  // 结果缓存有效期（秒）
+	CacheMaxBytes    int  `mapstructure:"cache_max_bytes"`    // 结果缓存总大小上限（字节）
+	RespectRobotsTxt bool `mapstructure:"respect_robots_txt"` // 抓取前检查目标站点的 robots.txt
+	MaxRedirects     int  `mapstructure:"max_redirects"`      // 最多跟随的重定向次数
+}
+
+// WebSearchConfig web search provider settings
 type WebSearchConfig struct {
-	APIKey     string `mapstructure:"api_key"`
+	Provider   string `mapstructure:"provider"`  // brave、duckduckgo、searxng、google；为空时有 api_key 用 Brave，否则 DuckDuckGo
+	Backend    string `mapstructure:"backend"`   // Deprecated: provider 的旧名称，仅在 provider 为空时生效
+	APIKey     string `mapstructure:"api_key"`   // Brave 或 Google 的 API key
+	EngineID   string `mapstructure:"engine_id"` // Google Programmable Search 的搜索引擎 ID（cx）
 	MaxResults int    `mapstructure:"max_results"`
+	SearXNGURL string `mapstructure:"searxng_url"` // SearXNG 实例地址，provider=searxng 时必填
+}
+
+// 支持的 web 搜索服务。
+const (
+	WebSearchProviderBrave      = "brave"
+	WebSearchProviderDuckDuckGo = "duckduckgo"
+	WebSearchProviderSearXNG    = "searxng"
+	WebSearchProviderGoogle     = "google"
+)
+
+// validate 规范化搜索服务名称（兼容旧的 backend 字段），并检查所选服务的必填项。
+func (c *WebSearchConfig) validate() error {
+	provider := strings.ToLower(strings.TrimSpace(c.Provider))
+	if provider == "" {
+		provider = strings.ToLower(strings.TrimSpace(c.Backend))
+	}
+	switch provider {
+	case "", WebSearchProviderDuckDuckGo:
+	case WebSearchProviderBrave:
+		if strings.TrimSpace(c.APIKey) == "" {
+			return fmt.Errorf("tools.web.search.api_key is required when provider is %q", provider)
+		}
+	case WebSearchProviderGoogle:
+		if strings.TrimSpace(c.APIKey) == "" || strings.TrimSpace(c.EngineID) == "" {
+			return fmt.Errorf("tools.web.search.api_key and tools.web.search.engine_id are required when provider is %q", provider)
+		}
+	case WebSearchProviderSearXNG:
+		u, err := url.Parse(strings.TrimSpace(c.SearXNGURL))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tools.web.search.searxng_url must be an http(s) URL when provider is %q; got %q", provider, c.SearXNGURL)
+		}
+	default:
+		return fmt.Errorf("tools.web.search.provider must be one of brave, duckduckgo, searxng, google; got %q", provider)
+	}
+	c.Provider = provider
+	c.Backend = ""
+	return nil
 }
 
 // ExecToolConfig shell exec settings
 type ExecToolConfig struct {
-	Timeout             int  `mapstructure:"timeout"`
-	RestrictToWorkspace bool `mapstructure:"restrict_to_workspace"`
+	Timeout             int      `mapstructure:"timeout"`
+	RestrictToWorkspace bool     `mapstructure:"restrict_to_workspace"`
+	AllowedCommands     []string `mapstructure:"allowed_commands"` // 非空时仅允许列出的命令；为空保持原有行为
+	BlockedCommands     []string `mapstructure:"blocked_commands"` // 始终禁止的命令，优先于 allowed_commands
 }
 
-// VoiceToolConfig speech-to-text settings for inbound audio.
+// VoiceToolConfig speech-to-text settings for inbound audio, and text-to-speech for voice replies.
 type VoiceToolConfig struct {
 	Enabled        bool   `mapstructure:"enabled"`
-	Provider       string `mapstructure:"provider"`
+	Provider       string `mapstructure:"provider"` // openai 或 local
 	Model          string `mapstructure:"model"`
+	BaseURL        string `mapstructure:"base_url"` // local 必填；openai 为空时使用 providers.openai.base_url
+	APIKey         string `mapstructure:"api_key"`  // 可选；openai 为空时使用 providers.openai 凭据
 	TimeoutSeconds int    `mapstructure:"timeout_seconds"`
+	TTSModel       string `mapstructure:"tts_model"` // 语音回复使用的合成模型
+	TTSVoice       string `mapstructure:"tts_voice"` // 语音回复使用的音色
 }
 
 // HeartbeatConfig heartbeat service settings.
@@ -266,14 +615,41 @@ func DefaultConfig() *Config {
 				Workspace:         filepath.Join(homeDir, ".golem", "workspace"),
 				WorkspaceMode:     "default",
 				Model:             "anthropic/claude-sonnet-4-5",
+				ChannelModels:     map[string]string{},
+				ModelPrices:       map[string]ModelPrice{},
 				MaxTokens:         8192,
 				Temperature:       0.7,
 				MaxToolIterations: 20,
+				MaxSessionHistory: 200,
+				MaxHistoryTokens:  64000,
+
+				ChannelSystemPrompts: map[string]string{},
+
+				MaxConcurrentSessions: 4,
+				MaxParallelTools:      8,
+
+				ModelRetryMaxAttempts:   3,
+				ModelRetryBaseBackoffMs: 500,
+				ModelRetryMaxBackoffMs:  8000,
 			},
 			Subagent: SubagentRuntimeConfig{
 				TimeoutSeconds: 300,
 				Retry:          1,
 				MaxConcurrency: 3,
+				MaxDepth:       2,
+			},
+			Summary: HistorySummaryConfig{
+				Enabled:         false,
+				TriggerMessages: 40,
+				KeepRecent:      10,
+			},
+			Memory: MemoryConfig{
+				SemanticRecall: false,
+				EmbeddingModel: "text-embedding-3-small",
+				SemanticLimit:  3,
+				MaxIndexChunks: 2000,
+				RetentionDays:  0,
+				MaxDiaries:     0,
 			},
 		},
 		Channels: ChannelsConfig{
@@ -311,6 +687,21 @@ func DefaultConfig() *Config {
 				Port:      9000,
 				AllowFrom: []string{},
 			},
+			Mattermost: MattermostConfig{
+				Enabled:   false,
+				AllowFrom: []string{},
+			},
+			Matrix: MatrixConfig{
+				Enabled:   false,
+				AllowFrom: []string{},
+			},
+			Twilio: TwilioConfig{
+				Enabled:   false,
+				Host:      "0.0.0.0",
+				Port:      18791,
+				Path:      "/twilio/sms",
+				AllowFrom: []string{},
+			},
 			Outbound: ChannelOutboundConfig{
 				MaxConcurrentSends: 16,
 				RetryMaxAttempts:   3,
@@ -319,6 +710,15 @@ func DefaultConfig() *Config {
 				RateLimitPerSecond: 20,
 				DedupWindowSeconds: 30,
 			},
+			Inbound: ChannelInboundConfig{
+				DedupWindowSeconds: 300,
+				OversizePolicy:     OversizeTruncate,
+			},
+			Reactions: ChannelReactionsConfig{
+				Processing: DefaultReactionProcessing,
+				Done:       DefaultReactionDone,
+				Failed:     DefaultReactionFailed,
+			},
 		},
 		Providers: ProvidersConfig{},
 		Gateway: GatewayConfig{
@@ -327,14 +727,17 @@ func DefaultConfig() *Config {
 			Token: "",
 		},
 		Log: LogConfig{
-			Level: "info",
-			File:  "",
+			Level:    "info",
+			File:     "",
+			Rotation: defaultLogRotation,
+			Audit:    defaultAuditRotation,
 		},
 		Policy: PolicyConfig{
 			Mode:               "strict",
 			OffTTL:             "",
 			AllowPersistentOff: false,
 			RequireApproval:    []string{},
+			Admins:             []string{},
 		},
 		MCP: MCPConfig{
 			Servers: map[string]MCPServerConfig{},
@@ -344,6 +747,11 @@ func DefaultConfig() *Config {
 				Search: WebSearchConfig{
 					MaxResults: 5,
 				},
+				Fetch: WebFetchConfig{
+					CacheTTLSeconds: 300,
+					CacheMaxBytes:   16 * 1024 * 1024,
+					MaxRedirects:    10,
+				},
 			},
 			Exec: ExecToolConfig{
 				Timeout:             60,
@@ -354,6 +762,8 @@ func DefaultConfig() *Config {
 				Provider:       "openai",
 				Model:          "gpt-4o-mini-transcribe",
 				TimeoutSeconds: 30,
+				TTSModel:       "gpt-4o-mini-tts",
+				TTSVoice:       "alloy",
 			},
 			Geo: GeoToolsConfig{
 				Enabled:             true,
@@ -365,6 +775,12 @@ func DefaultConfig() *Config {
 				MaxRows:             200,
 				ReadOnly:            true,
 			},
+			CircuitBreaker: CircuitBreakerConfig{
+				Enabled:          false,
+				FailureThreshold: 5,
+				WindowSeconds:    60,
+				CooldownSeconds:  30,
+			},
 		},
 		Heartbeat: HeartbeatConfig{
 			Enabled:        true,
@@ -380,9 +796,10 @@ func ConfigDir() string {
 	return filepath.Join(homeDir, ".golem")
 }
 
-// ConfigPath returns the config file path
+// ConfigPath 返回配置文件路径：按 config.yaml、config.yml、config.toml、config.json
+// 的顺序取第一个存在的文件，都不存在时返回 config.json。
 func ConfigPath() string {
-	return filepath.Join(ConfigDir(), "config.json")
+	return detectConfigPath(ConfigDir())
 }
 
 // Load loads config from file or returns defaults
@@ -399,7 +816,7 @@ func Load() (*Config, error) {
 
 	v := viper.New()
 	v.SetConfigFile(configPath)
-	v.SetConfigType("json")
+	v.SetConfigType(configType(configPath))
 	v.SetEnvPrefix("GOLEM")
 	v.AutomaticEnv()
 
@@ -430,7 +847,7 @@ func normalizeKey(input string) string {
 	return strings.ToLower(input)
 }
 
-// Save saves config to file
+// Save 将配置写回文件，沿用当前配置文件的格式（JSON/YAML/TOML）。
 func Save(cfg *Config) error {
 	configPath := ConfigPath()
 
@@ -438,7 +855,7 @@ func Save(cfg *Config) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := marshalConfig(cfg, configPath)
 	if err != nil {
 		return err
 	}
@@ -457,6 +874,59 @@ func (c *Config) Validate() error {
 		d.MaxToolIterations = 20
 	}
 
+	if d.MaxSessionHistory < 0 {
+		return fmt.Errorf("agents.defaults.max_session_history must not be negative, got %d", d.MaxSessionHistory)
+	}
+	if d.MaxSessionHistory == 0 {
+		d.MaxSessionHistory = 200
+	}
+	if d.MaxHistoryTokens < 0 {
+		return fmt.Errorf("agents.defaults.max_history_tokens must not be negative, got %d", d.MaxHistoryTokens)
+	}
+	if d.MaxHistoryTokens == 0 {
+		d.MaxHistoryTokens = 64000
+	}
+	if d.MaxConcurrentSessions < 0 {
+		return fmt.Errorf("agents.defaults.max_concurrent_sessions must not be negative, got %d", d.MaxConcurrentSessions)
+	}
+	if d.MaxConcurrentSessions == 0 {
+		d.MaxConcurrentSessions = 4
+	}
+	if d.TurnTimeoutSeconds < 0 {
+		return fmt.Errorf("agents.defaults.turn_timeout_seconds must not be negative, got %d", d.TurnTimeoutSeconds)
+	}
+	if d.MaxParallelTools < 0 {
+		return fmt.Errorf("agents.defaults.max_parallel_tools must not be negative, got %d", d.MaxParallelTools)
+	}
+	if d.MaxParallelTools == 0 {
+		d.MaxParallelTools = 8
+	}
+	if d.MaxParallelTools > 64 {
+		d.MaxParallelTools = 64
+	}
+
+	if d.ModelRetryMaxAttempts < 0 {
+		return fmt.Errorf("agents.defaults.model_retry_max_attempts must not be negative, got %d", d.ModelRetryMaxAttempts)
+	}
+	if d.ModelRetryMaxAttempts == 0 {
+		d.ModelRetryMaxAttempts = 3
+	}
+	if d.ModelRetryBaseBackoffMs < 0 {
+		return fmt.Errorf("agents.defaults.model_retry_base_backoff_ms must not be negative, got %d", d.ModelRetryBaseBackoffMs)
+	}
+	if d.ModelRetryBaseBackoffMs == 0 {
+		d.ModelRetryBaseBackoffMs = 500
+	}
+	if d.ModelRetryMaxBackoffMs < 0 {
+		return fmt.Errorf("agents.defaults.model_retry_max_backoff_ms must not be negative, got %d", d.ModelRetryMaxBackoffMs)
+	}
+	if d.ModelRetryMaxBackoffMs == 0 {
+		d.ModelRetryMaxBackoffMs = 8000
+	}
+	if d.ModelRetryMaxBackoffMs < d.ModelRetryBaseBackoffMs {
+		d.ModelRetryMaxBackoffMs = d.ModelRetryBaseBackoffMs
+	}
+
 	if d.Temperature < 0 || d.Temperature > 2.0 {
 		return fmt.Errorf("agents.defaults.temperature must be between 0 and 2.0, got %f", d.Temperature)
 	}
@@ -465,6 +935,84 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("agents.defaults.max_tokens must be > 0, got %d", d.MaxTokens)
 	}
 
+	for channelName, modelName := range d.ChannelModels {
+		if strings.TrimSpace(channelName) == "" {
+			return fmt.Errorf("agents.defaults.channel_models contains an empty channel name")
+		}
+		if strings.TrimSpace(modelName) == "" {
+			return fmt.Errorf("agents.defaults.channel_models.%s must not be empty", channelName)
+		}
+		d.ChannelModels[channelName] = strings.TrimSpace(modelName)
+	}
+
+	d.SystemPrompt = strings.TrimSpace(d.SystemPrompt)
+	for channelName, prompt := range d.ChannelSystemPrompts {
+		if strings.TrimSpace(channelName) == "" {
+			return fmt.Errorf("agents.defaults.channel_system_prompts contains an empty channel name")
+		}
+		if strings.TrimSpace(prompt) == "" {
+			return fmt.Errorf("agents.defaults.channel_system_prompts.%s must not be empty", channelName)
+		}
+		d.ChannelSystemPrompts[channelName] = strings.TrimSpace(prompt)
+	}
+
+	for i, name := range d.VisionModels {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("agents.defaults.vision_models[%d] must not be empty", i)
+		}
+		d.VisionModels[i] = strings.TrimSpace(name)
+	}
+
+	for modelName, price := range d.ModelPrices {
+		if strings.TrimSpace(modelName) == "" {
+			return fmt.Errorf("agents.defaults.model_prices contains an empty model name")
+		}
+		if price.InputPerMillion < 0 || price.OutputPerMillion < 0 {
+			return fmt.Errorf("agents.defaults.model_prices.%s prices must not be negative", modelName)
+		}
+	}
+
+	summary := &c.Agents.Summary
+	if summary.TriggerMessages < 0 {
+		return fmt.Errorf("agents.summary.trigger_messages must not be negative, got %d", summary.TriggerMessages)
+	}
+	if summary.TriggerMessages == 0 {
+		summary.TriggerMessages = 40
+	}
+	if summary.KeepRecent < 0 {
+		return fmt.Errorf("agents.summary.keep_recent must not be negative, got %d", summary.KeepRecent)
+	}
+	if summary.KeepRecent == 0 {
+		summary.KeepRecent = 10
+	}
+	if summary.KeepRecent >= summary.TriggerMessages {
+		return fmt.Errorf("agents.summary.keep_recent (%d) must be less than trigger_messages (%d)", summary.KeepRecent, summary.TriggerMessages)
+	}
+
+	recall := &c.Agents.Memory
+	recall.EmbeddingModel = strings.TrimSpace(recall.EmbeddingModel)
+	if recall.EmbeddingModel == "" {
+		recall.EmbeddingModel = "text-embedding-3-small"
+	}
+	if recall.SemanticLimit < 0 {
+		return fmt.Errorf("agents.memory.semantic_limit must not be negative, got %d", recall.SemanticLimit)
+	}
+	if recall.SemanticLimit == 0 {
+		recall.SemanticLimit = 3
+	}
+	if recall.MaxIndexChunks < 0 {
+		return fmt.Errorf("agents.memory.max_index_chunks must not be negative, got %d", recall.MaxIndexChunks)
+	}
+	if recall.MaxIndexChunks == 0 {
+		recall.MaxIndexChunks = 2000
+	}
+	if recall.RetentionDays < 0 {
+		return fmt.Errorf("agents.memory.retention_days must not be negative, got %d", recall.RetentionDays)
+	}
+	if recall.MaxDiaries < 0 {
+		return fmt.Errorf("agents.memory.max_diaries must not be negative, got %d", recall.MaxDiaries)
+	}
+
 	if c.Agents.Subagent.TimeoutSeconds < 0 {
 		return fmt.Errorf("agents.subagent.timeout_seconds must not be negative, got %d", c.Agents.Subagent.TimeoutSeconds)
 	}
@@ -483,6 +1031,12 @@ func (c *Config) Validate() error {
 	if c.Agents.Subagent.MaxConcurrency == 0 {
 		c.Agents.Subagent.MaxConcurrency = 3
 	}
+	if c.Agents.Subagent.MaxDepth < 0 {
+		return fmt.Errorf("agents.subagent.max_depth must not be negative, got %d", c.Agents.Subagent.MaxDepth)
+	}
+	if c.Agents.Subagent.MaxDepth == 0 {
+		c.Agents.Subagent.MaxDepth = 2
+	}
 
 	mode := strings.TrimSpace(d.WorkspaceMode)
 	if mode != "" {
@@ -498,9 +1052,85 @@ func (c *Config) Validate() error {
 	if c.Gateway.Port <= 0 || c.Gateway.Port > 65535 {
 		return fmt.Errorf("gateway.port must be between 1 and 65535, got %d", c.Gateway.Port)
 	}
+	if c.HTTP.TimeoutSeconds < 0 {
+		return fmt.Errorf("http.timeout_seconds must not be negative, got %d", c.HTTP.TimeoutSeconds)
+	}
+	c.HTTP.Proxy = strings.TrimSpace(c.HTTP.Proxy)
+	if c.HTTP.Proxy != "" {
+		raw := c.HTTP.Proxy
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			return fmt.Errorf("http.proxy must be a proxy URL such as http://proxy.example.com:8080, got %q", c.HTTP.Proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("http.proxy scheme must be http, https or socks5, got %q", u.Scheme)
+		}
+	}
+	c.HTTP.NoProxy = strings.TrimSpace(c.HTTP.NoProxy)
+	for name, respondWhen := range map[string]*string{
+		"telegram": &c.Channels.Telegram.RespondWhen,
+		"discord":  &c.Channels.Discord.RespondWhen,
+		"slack":    &c.Channels.Slack.RespondWhen,
+	} {
+		mode := strings.ToLower(strings.TrimSpace(*respondWhen))
+		switch mode {
+		case "":
+			mode = RespondAlways
+		case RespondAlways, RespondMention:
+		default:
+			return fmt.Errorf("channels.%s.respond_when must be one of: always, mention; got %q", name, *respondWhen)
+		}
+		*respondWhen = mode
+	}
 	if c.Channels.MaixCam.Port != 0 && (c.Channels.MaixCam.Port < 1 || c.Channels.MaixCam.Port > 65535) {
 		return fmt.Errorf("channels.maixcam.port must be between 1 and 65535, got %d", c.Channels.MaixCam.Port)
 	}
+	if c.Channels.Twilio.Port != 0 && (c.Channels.Twilio.Port < 1 || c.Channels.Twilio.Port > 65535) {
+		return fmt.Errorf("channels.twilio.port must be between 1 and 65535, got %d", c.Channels.Twilio.Port)
+	}
+
+	if c.Channels.Inbound.RateLimitPerMinute < 0 {
+		return fmt.Errorf("channels.inbound.rate_limit_per_minute must not be negative, got %d", c.Channels.Inbound.RateLimitPerMinute)
+	}
+	if c.Channels.Inbound.RateLimitBurst < 0 {
+		return fmt.Errorf("channels.inbound.rate_limit_burst must not be negative, got %d", c.Channels.Inbound.RateLimitBurst)
+	}
+	if c.Channels.Inbound.DedupWindowSeconds < 0 {
+		return fmt.Errorf("channels.inbound.dedup_window_seconds must not be negative, got %d", c.Channels.Inbound.DedupWindowSeconds)
+	}
+	if c.Channels.Inbound.DedupWindowSeconds == 0 {
+		c.Channels.Inbound.DedupWindowSeconds = 300
+	}
+	if c.Channels.Inbound.MaxMessageChars < 0 {
+		return fmt.Errorf("channels.inbound.max_message_chars must not be negative, got %d", c.Channels.Inbound.MaxMessageChars)
+	}
+	switch policy := strings.ToLower(strings.TrimSpace(c.Channels.Inbound.OversizePolicy)); policy {
+	case "":
+		c.Channels.Inbound.OversizePolicy = OversizeTruncate
+	case OversizeTruncate, OversizeReject:
+		c.Channels.Inbound.OversizePolicy = policy
+	default:
+		return fmt.Errorf("channels.inbound.oversize_policy must be one of: truncate, reject; got %q", c.Channels.Inbound.OversizePolicy)
+	}
+
+	reactions := &c.Channels.Reactions
+	reactions.Processing = strings.TrimSpace(reactions.Processing)
+	if reactions.Processing == "" {
+		reactions.Processing = DefaultReactionProcessing
+	}
+	reactions.Done = strings.TrimSpace(reactions.Done)
+	if reactions.Done == "" {
+		reactions.Done = DefaultReactionDone
+	}
+	reactions.Failed = strings.TrimSpace(reactions.Failed)
+	if reactions.Failed == "" {
+		reactions.Failed = DefaultReactionFailed
+	}
 
 	if c.Channels.Outbound.MaxConcurrentSends < 0 {
 		return fmt.Errorf("channels.outbound.max_concurrent_sends must not be negative, got %d", c.Channels.Outbound.MaxConcurrentSends)
@@ -557,6 +1187,12 @@ func (c *Config) Validate() error {
 		}
 		c.Log.Level = level
 	}
+	if err := c.Log.Rotation.normalize("log.rotation", defaultLogRotation); err != nil {
+		return err
+	}
+	if err := c.Log.Audit.normalize("log.audit", defaultAuditRotation); err != nil {
+		return err
+	}
 
 	policyMode := strings.ToLower(strings.TrimSpace(c.Policy.Mode))
 	if policyMode == "" {
@@ -583,6 +1219,14 @@ func (c *Config) Validate() error {
 	if c.Policy.Mode == "off" && offTTL == "" && !c.Policy.AllowPersistentOff {
 		return fmt.Errorf("policy.mode=off without policy.off_ttl requires policy.allow_persistent_off=true")
 	}
+	for i, admin := range c.Policy.Admins {
+		admin = strings.TrimSpace(admin)
+		channel, sender, ok := strings.Cut(admin, ":")
+		if !ok || strings.TrimSpace(channel) == "" || strings.TrimSpace(sender) == "" {
+			return fmt.Errorf("policy.admins[%d] must be channel:sender_id, got %q", i, c.Policy.Admins[i])
+		}
+		c.Policy.Admins[i] = admin
+	}
 
 	for serverName, server := range c.MCP.Servers {
 		name := strings.TrimSpace(serverName)
@@ -593,6 +1237,13 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("mcp.servers.%q has leading or trailing whitespace; use %q", serverName, name)
 		}
 
+		if server.CallTimeoutSeconds < 0 {
+			return fmt.Errorf("mcp.servers.%s.call_timeout_seconds must not be negative, got %d", serverName, server.CallTimeoutSeconds)
+		}
+		if server.StderrTailBytes < 0 || server.StderrTailBytes > MaxMCPStderrTailBytes {
+			return fmt.Errorf("mcp.servers.%s.stderr_tail_bytes must be between 0 and %d, got %d", serverName, MaxMCPStderrTailBytes, server.StderrTailBytes)
+		}
+
 		if !IsMCPServerEnabled(server) {
 			transport := strings.TrimSpace(server.Transport)
 			if transport != "" {
@@ -612,8 +1263,16 @@ func (c *Config) Validate() error {
 			if strings.TrimSpace(server.URL) == "" {
 				return fmt.Errorf("mcp.servers.%s.url is required when transport=http_sse", serverName)
 			}
+		case "websocket":
+			if strings.TrimSpace(server.URL) == "" {
+				return fmt.Errorf("mcp.servers.%s.url is required when transport=websocket", serverName)
+			}
+		case "http_stream":
+			if strings.TrimSpace(server.URL) == "" {
+				return fmt.Errorf("mcp.servers.%s.url is required when transport=http_stream", serverName)
+			}
 		default:
-			return fmt.Errorf("mcp.servers.%s.transport must be one of stdio, http_sse; got %q", serverName, server.Transport)
+			return fmt.Errorf("mcp.servers.%s.transport must be one of stdio, http_sse, websocket, http_stream; got %q", serverName, server.Transport)
 		}
 
 		server.Transport = transport
@@ -637,12 +1296,37 @@ func (c *Config) Validate() error {
 		c.Heartbeat.MaxIdleMinutes = 720
 	}
 
+	if err := c.Tools.Web.Search.validate(); err != nil {
+		return err
+	}
+	if c.Tools.Web.Fetch.CacheTTLSeconds < 0 {
+		return fmt.Errorf("tools.web.fetch.cache_ttl_seconds must not be negative, got %d", c.Tools.Web.Fetch.CacheTTLSeconds)
+	}
+	if c.Tools.Web.Fetch.CacheTTLSeconds == 0 {
+		c.Tools.Web.Fetch.CacheTTLSeconds = 300
+	}
+	if c.Tools.Web.Fetch.CacheMaxBytes < 0 {
+		return fmt.Errorf("tools.web.fetch.cache_max_bytes must not be negative, got %d", c.Tools.Web.Fetch.CacheMaxBytes)
+	}
+	if c.Tools.Web.Fetch.CacheMaxBytes == 0 {
+		c.Tools.Web.Fetch.CacheMaxBytes = 16 * 1024 * 1024
+	}
+	if c.Tools.Web.Fetch.MaxRedirects < 0 {
+		return fmt.Errorf("tools.web.fetch.max_redirects must not be negative, got %d", c.Tools.Web.Fetch.MaxRedirects)
+	}
+	if c.Tools.Web.Fetch.MaxRedirects == 0 {
+		c.Tools.Web.Fetch.MaxRedirects = 10
+	}
+
 	voiceProvider := strings.ToLower(strings.TrimSpace(c.Tools.Voice.Provider))
 	if voiceProvider == "" {
 		voiceProvider = "openai"
 	}
-	if c.Tools.Voice.Enabled && voiceProvider != "openai" {
-		return fmt.Errorf("tools.voice.provider must be \"openai\" when enabled; got %q", c.Tools.Voice.Provider)
+	if c.Tools.Voice.Enabled && voiceProvider != "openai" && voiceProvider != "local" {
+		return fmt.Errorf("tools.voice.provider must be one of: openai, local when enabled; got %q", c.Tools.Voice.Provider)
+	}
+	if c.Tools.Voice.Enabled && voiceProvider == "local" && strings.TrimSpace(c.Tools.Voice.BaseURL) == "" {
+		return fmt.Errorf("tools.voice.base_url must be non-empty when provider is \"local\"")
 	}
 	c.Tools.Voice.Provider = voiceProvider
 
@@ -672,6 +1356,26 @@ func (c *Config) Validate() error {
 		c.Tools.Geo.MaxRows = 200
 	}
 
+	cb := &c.Tools.CircuitBreaker
+	if cb.FailureThreshold < 0 {
+		return fmt.Errorf("tools.circuit_breaker.failure_threshold must not be negative, got %d", cb.FailureThreshold)
+	}
+	if cb.FailureThreshold == 0 {
+		cb.FailureThreshold = 5
+	}
+	if cb.WindowSeconds < 0 {
+		return fmt.Errorf("tools.circuit_breaker.window_seconds must not be negative, got %d", cb.WindowSeconds)
+	}
+	if cb.WindowSeconds == 0 {
+		cb.WindowSeconds = 60
+	}
+	if cb.CooldownSeconds < 0 {
+		return fmt.Errorf("tools.circuit_breaker.cooldown_seconds must not be negative, got %d", cb.CooldownSeconds)
+	}
+	if cb.CooldownSeconds == 0 {
+		cb.CooldownSeconds = 30
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -118,6 +119,98 @@ func TestLoadConfig_PascalCaseKeys(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_YAMLAndTOML(t *testing.T) {
+	cases := map[string]string{
+		"config.yaml": `# comments are welcome here
+agents:
+  defaults:
+    model: openai/gpt-4o-mini
+    max_tool_iterations: 7
+mcp:
+  servers:
+    localfs:
+      transport: stdio
+      command: localfs-mcp
+`,
+		"config.toml": `[agents.defaults]
+model = "openai/gpt-4o-mini"
+max_tool_iterations = 7
+
+[mcp.servers.localfs]
+transport = "stdio"
+command = "localfs-mcp"
+`,
+	}
+
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			t.Setenv("HOME", tmpDir)
+			t.Setenv("USERPROFILE", tmpDir)
+
+			configPath := filepath.Join(ConfigDir(), name)
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				t.Fatalf("MkdirAll: %v", err)
+			}
+			if err := os.WriteFile(configPath, []byte(raw), 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if got := ConfigPath(); got != configPath {
+				t.Fatalf("expected ConfigPath %q, got %q", configPath, got)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error: %v", err)
+			}
+			if cfg.Agents.Defaults.Model != "openai/gpt-4o-mini" || cfg.Agents.Defaults.MaxToolIterations != 7 {
+				t.Fatalf("unexpected agent defaults: %+v", cfg.Agents.Defaults)
+			}
+			if cfg.MCP.Servers["localfs"].Command != "localfs-mcp" {
+				t.Fatalf("expected mcp server loaded, got %+v", cfg.MCP.Servers)
+			}
+
+			// Save writes back in the same format and the result loads again.
+			cfg.Agents.Defaults.MaxToolIterations = 9
+			if err := Save(cfg); err != nil {
+				t.Fatalf("Save() error: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(ConfigDir(), "config.json")); !os.IsNotExist(err) {
+				t.Fatalf("expected Save not to create config.json, stat err=%v", err)
+			}
+			reloaded, err := Load()
+			if err != nil {
+				t.Fatalf("Load() after Save error: %v", err)
+			}
+			if reloaded.Agents.Defaults.MaxToolIterations != 9 || reloaded.MCP.Servers["localfs"].Transport != "stdio" {
+				t.Fatalf("unexpected config after round trip: %+v", reloaded.Agents.Defaults)
+			}
+		})
+	}
+}
+
+func TestConfigPath_DefaultsToJSONAndPrefersYAML(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	if got := ConfigPath(); filepath.Base(got) != "config.json" {
+		t.Fatalf("expected config.json when no config exists, got %q", got)
+	}
+
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for _, name := range []string{"config.json", "config.yaml"} {
+		if err := os.WriteFile(filepath.Join(ConfigDir(), name), []byte("{}"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	if got := ConfigPath(); filepath.Base(got) != "config.yaml" {
+		t.Fatalf("expected config.yaml to take precedence, got %q", got)
+	}
+}
+
 func TestWorkspacePath_Default(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Agents.Defaults.Workspace = ""
@@ -201,6 +294,23 @@ func TestValidate_LogLevel(t *testing.T) {
 	}
 }
 
+func TestValidate_RespondWhen(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channels.Discord.RespondWhen = " Mention "
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected respond_when to be normalized, got error: %v", err)
+	}
+	if cfg.Channels.Discord.RespondWhen != RespondMention || cfg.Channels.Telegram.RespondWhen != RespondAlways {
+		t.Fatalf("unexpected respond_when values: discord=%q telegram=%q", cfg.Channels.Discord.RespondWhen, cfg.Channels.Telegram.RespondWhen)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Channels.Slack.RespondWhen = "sometimes"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "channels.slack.respond_when") {
+		t.Fatalf("expected validation error for invalid respond_when, got %v", err)
+	}
+}
+
 func TestValidate_HeartbeatDefaultsAndClamp(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Heartbeat.Interval = 0
@@ -259,6 +369,17 @@ func TestValidate_VoiceDefaultsAndProvider(t *testing.T) {
 	if err := cfg.Validate(); err == nil {
 		t.Fatal("expected validation error for unsupported voice provider")
 	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Voice.Enabled = true
+	cfg.Tools.Voice.Provider = "local"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for local voice provider without base_url")
+	}
+	cfg.Tools.Voice.BaseURL = "http://127.0.0.1:8080/inference"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for local voice provider: %v", err)
+	}
 }
 
 func TestValidate_VoiceNegativeTimeout(t *testing.T) {
@@ -269,6 +390,91 @@ func TestValidate_VoiceNegativeTimeout(t *testing.T) {
 	}
 }
 
+func TestValidate_WebSearchProvider(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Web.Search.Provider = " SearXNG "
+	cfg.Tools.Web.Search.SearXNGURL = "http://localhost:8888"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tools.Web.Search.Provider != WebSearchProviderSearXNG {
+		t.Fatalf("expected provider to be normalized, got %q", cfg.Tools.Web.Search.Provider)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Web.Search.Backend = "duckduckgo"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tools.Web.Search.Provider != WebSearchProviderDuckDuckGo {
+		t.Fatalf("expected legacy backend to map to provider, got %q", cfg.Tools.Web.Search.Provider)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Web.Search.Provider = "google"
+	cfg.Tools.Web.Search.APIKey = "key"
+	cfg.Tools.Web.Search.EngineID = "cx"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for google provider: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		provider string
+		apiKey   string
+		url      string
+	}{
+		{name: "unknown provider", provider: "bing"},
+		{name: "brave without key", provider: "brave"},
+		{name: "google without engine id", provider: "google", apiKey: "key"},
+		{name: "searxng without url", provider: "searxng"},
+		{name: "searxng with bad scheme", provider: "searxng", url: "ftp://search.local"},
+	}
+	for _, tc := range cases {
+		cfg := DefaultConfig()
+		cfg.Tools.Web.Search.Provider = tc.provider
+		cfg.Tools.Web.Search.APIKey = tc.apiKey
+		cfg.Tools.Web.Search.SearXNGURL = tc.url
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("%s: expected validation error", tc.name)
+		}
+	}
+}
+
+func TestValidate_WebFetchDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tools.Web.Fetch.CacheTTLSeconds = 0
+	cfg.Tools.Web.Fetch.CacheMaxBytes = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Tools.Web.Fetch.CacheTTLSeconds != 300 || cfg.Tools.Web.Fetch.CacheMaxBytes != 16*1024*1024 {
+		t.Fatalf("unexpected web fetch cache defaults: %+v", cfg.Tools.Web.Fetch)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Web.Fetch.CacheTTLSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative cache_ttl_seconds")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Web.Fetch.CacheMaxBytes = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative cache_max_bytes")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Tools.Web.Fetch.MaxRedirects = 0
+	if err := cfg.Validate(); err != nil || cfg.Tools.Web.Fetch.MaxRedirects != 10 {
+		t.Fatalf("expected max_redirects default 10, got %d (err=%v)", cfg.Tools.Web.Fetch.MaxRedirects, err)
+	}
+	cfg.Tools.Web.Fetch.MaxRedirects = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_redirects")
+	}
+}
+
 func TestDefaultConfig_GeoQueryDefaults(t *testing.T) {
 	cfg := DefaultConfig()
 
@@ -336,6 +542,28 @@ func TestValidate_PolicyMode(t *testing.T) {
 	}
 }
 
+func TestValidate_PolicyAdmins(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Policy.Admins = []string{" telegram:alice ", "cli:user"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid admins, got error: %v", err)
+	}
+	if !cfg.Policy.IsAdmin("Telegram", "alice") || !cfg.Policy.IsAdmin("cli", "user") {
+		t.Fatalf("expected configured senders to be admins: %v", cfg.Policy.Admins)
+	}
+	if cfg.Policy.IsAdmin("telegram", "mallory") || cfg.Policy.IsAdmin("cli", "cron") {
+		t.Fatal("expected unlisted senders not to be admins")
+	}
+
+	for _, bad := range []string{"alice", "telegram:", ":alice"} {
+		cfg = DefaultConfig()
+		cfg.Policy.Admins = []string{bad}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for policy.admins entry %q", bad)
+		}
+	}
+}
+
 func TestValidate_PersistentOffGate(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.Policy.Mode = "off"
@@ -401,6 +629,34 @@ func TestValidate_MCPServers(t *testing.T) {
 		t.Fatal("expected validation error for http_sse server without url")
 	}
 
+	cfg = DefaultConfig()
+	cfg.MCP.Servers = map[string]MCPServerConfig{
+		"ws_missing_url": {Transport: "websocket"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for websocket server without url")
+	}
+
+	cfg = DefaultConfig()
+	cfg.MCP.Servers = map[string]MCPServerConfig{
+		"stream_missing_url": {Transport: "http_stream"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for http_stream server without url")
+	}
+
+	cfg = DefaultConfig()
+	cfg.MCP.Servers = map[string]MCPServerConfig{
+		"env_ref": {
+			Transport: "http_stream",
+			URL:       "${GOLEM_TEST_UNSET_MCP_URL}",
+			Headers:   map[string]string{"Authorization": "Bearer ${GOLEM_TEST_UNSET_MCP_TOKEN}"},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected unresolved env references to pass validation, got %v", err)
+	}
+
 	cfg = DefaultConfig()
 	cfg.MCP.Servers = map[string]MCPServerConfig{
 		" localfs ": {
@@ -412,6 +668,24 @@ func TestValidate_MCPServers(t *testing.T) {
 		t.Fatal("expected validation error for MCP server name with surrounding whitespace")
 	}
 
+	cfg = DefaultConfig()
+	cfg.MCP.Servers = map[string]MCPServerConfig{
+		"negative_timeout": {Transport: "stdio", Command: "npx", CallTimeoutSeconds: -1},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative MCP call_timeout_seconds")
+	}
+
+	for _, size := range []int{-1, MaxMCPStderrTailBytes + 1} {
+		cfg = DefaultConfig()
+		cfg.MCP.Servers = map[string]MCPServerConfig{
+			"bad_tail": {Transport: "stdio", Command: "npx", StderrTailBytes: size},
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for stderr_tail_bytes=%d", size)
+		}
+	}
+
 	cfg = DefaultConfig()
 	cfg.MCP.Servers = map[string]MCPServerConfig{
 		"stdio_ok": {
@@ -423,6 +697,14 @@ func TestValidate_MCPServers(t *testing.T) {
 			Transport: "http_sse",
 			URL:       "http://localhost:8080/sse",
 		},
+		"ws_ok": {
+			Transport: "websocket",
+			URL:       "ws://localhost:8080/mcp",
+		},
+		"stream_ok": {
+			Transport: "http_stream",
+			URL:       "http://localhost:8080/mcp",
+		},
 	}
 	if err := cfg.Validate(); err != nil {
 		t.Fatalf("expected valid MCP servers config, got error: %v", err)
@@ -446,10 +728,14 @@ func TestValidate_SubagentDefaultsAndBounds(t *testing.T) {
 	cfg.Agents.Subagent.TimeoutSeconds = 0
 	cfg.Agents.Subagent.Retry = 0
 	cfg.Agents.Subagent.MaxConcurrency = 0
+	cfg.Agents.Subagent.MaxDepth = 0
 
 	if err := cfg.Validate(); err != nil {
 		t.Fatalf("unexpected error applying subagent defaults: %v", err)
 	}
+	if cfg.Agents.Subagent.MaxDepth != 2 {
+		t.Fatalf("expected subagent max_depth default 2, got %d", cfg.Agents.Subagent.MaxDepth)
+	}
 	if cfg.Agents.Subagent.TimeoutSeconds != 300 {
 		t.Fatalf("expected subagent timeout default 300, got %d", cfg.Agents.Subagent.TimeoutSeconds)
 	}
@@ -477,6 +763,12 @@ func TestValidate_SubagentDefaultsAndBounds(t *testing.T) {
 	if err := cfg.Validate(); err == nil {
 		t.Fatal("expected validation error for negative subagent max_concurrency")
 	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Subagent.MaxDepth = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative subagent max_depth")
+	}
 }
 
 func TestValidate_ChannelOutboundDefaultsAndBounds(t *testing.T) {
@@ -536,3 +828,433 @@ func TestValidate_ChannelOutboundDefaultsAndBounds(t *testing.T) {
 		t.Fatal("expected validation error for negative channels.outbound.dedup_window_seconds")
 	}
 }
+
+func TestValidate_ChannelModels(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"telegram": "  deepseek/deepseek-chat "}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Agents.Defaults.ChannelModels["telegram"]; got != "deepseek/deepseek-chat" {
+		t.Fatalf("expected trimmed override model, got %q", got)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"slack": " "}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for empty channel model override")
+	}
+}
+
+func TestValidate_ChannelSystemPrompts(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.SystemPrompt = "  You are Ada.  "
+	cfg.Agents.Defaults.ChannelSystemPrompts = map[string]string{"slack": " file:prompts/slack.md "}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Agents.Defaults.SystemPrompt != "You are Ada." || cfg.Agents.Defaults.ChannelSystemPrompts["slack"] != "file:prompts/slack.md" {
+		t.Fatalf("expected trimmed prompts, got %q / %q", cfg.Agents.Defaults.SystemPrompt, cfg.Agents.Defaults.ChannelSystemPrompts["slack"])
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.ChannelSystemPrompts = map[string]string{"slack": " "}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for empty channel system prompt")
+	}
+}
+
+func TestValidate_ModelPrices(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.ModelPrices = map[string]ModelPrice{
+		"anthropic/claude-sonnet-4-5": {InputPerMillion: 3, OutputPerMillion: 15},
+		"deepseek-chat":               {InputPerMillion: 0.27, OutputPerMillion: 1.1},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	d := cfg.Agents.Defaults
+	price, ok := d.PriceFor("anthropic/claude-sonnet-4-5")
+	if !ok || price.Cost(1_000_000, 100_000) != 4.5 {
+		t.Fatalf("expected exact price match, got %+v (%v)", price, ok)
+	}
+	if _, ok := d.PriceFor("deepseek/deepseek-chat"); !ok {
+		t.Fatal("expected a price keyed by bare model name to match a prefixed model")
+	}
+	if _, ok := d.PriceFor("openai/gpt-4o"); ok {
+		t.Fatal("expected no price for an unlisted model")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.ModelPrices = map[string]ModelPrice{"openai/gpt-4o": {InputPerMillion: -1}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative model price")
+	}
+}
+
+func TestValidate_MaxSessionHistory(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.MaxSessionHistory = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying max_session_history default: %v", err)
+	}
+	if cfg.Agents.Defaults.MaxSessionHistory != 200 {
+		t.Fatalf("expected max_session_history default 200, got %d", cfg.Agents.Defaults.MaxSessionHistory)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.MaxSessionHistory = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_session_history")
+	}
+}
+
+func TestValidate_MaxHistoryTokens(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.MaxHistoryTokens = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying max_history_tokens default: %v", err)
+	}
+	if cfg.Agents.Defaults.MaxHistoryTokens != 64000 {
+		t.Fatalf("expected max_history_tokens default 64000, got %d", cfg.Agents.Defaults.MaxHistoryTokens)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.MaxHistoryTokens = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_history_tokens")
+	}
+}
+
+func TestValidate_HistorySummary(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Summary = HistorySummaryConfig{Enabled: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying summary defaults: %v", err)
+	}
+	if cfg.Agents.Summary.TriggerMessages != 40 || cfg.Agents.Summary.KeepRecent != 10 {
+		t.Fatalf("expected summary defaults 40/10, got %+v", cfg.Agents.Summary)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Summary.TriggerMessages = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative trigger_messages")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Summary = HistorySummaryConfig{TriggerMessages: 10, KeepRecent: 10}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error when keep_recent >= trigger_messages")
+	}
+}
+
+func TestValidate_MemoryRecall(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Memory = MemoryConfig{SemanticRecall: true}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying memory recall defaults: %v", err)
+	}
+	got := cfg.Agents.Memory
+	if got.EmbeddingModel != "text-embedding-3-small" || got.SemanticLimit != 3 || got.MaxIndexChunks != 2000 {
+		t.Fatalf("unexpected memory recall defaults: %+v", got)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Memory.SemanticLimit = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative semantic_limit")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Memory.MaxIndexChunks = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_index_chunks")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Memory.RetentionDays = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative retention_days")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Memory.MaxDiaries = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_diaries")
+	}
+}
+
+func TestValidate_MaxConcurrentSessions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.MaxConcurrentSessions = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying max_concurrent_sessions default: %v", err)
+	}
+	if cfg.Agents.Defaults.MaxConcurrentSessions != 4 {
+		t.Fatalf("expected max_concurrent_sessions default 4, got %d", cfg.Agents.Defaults.MaxConcurrentSessions)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.MaxConcurrentSessions = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_concurrent_sessions")
+	}
+}
+
+func TestValidate_TurnTimeoutSeconds(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Agents.Defaults.TurnTimeoutSeconds != 0 {
+		t.Fatalf("expected no turn timeout by default, got %d", cfg.Agents.Defaults.TurnTimeoutSeconds)
+	}
+	cfg.Agents.Defaults.TurnTimeoutSeconds = 600
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for positive turn_timeout_seconds: %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.TurnTimeoutSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative turn_timeout_seconds")
+	}
+}
+
+func TestValidate_MaxParallelTools(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.MaxParallelTools = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying max_parallel_tools default: %v", err)
+	}
+	if cfg.Agents.Defaults.MaxParallelTools != 8 {
+		t.Fatalf("expected max_parallel_tools default 8, got %d", cfg.Agents.Defaults.MaxParallelTools)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.MaxParallelTools = 1000
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for large max_parallel_tools: %v", err)
+	}
+	if cfg.Agents.Defaults.MaxParallelTools != 64 {
+		t.Fatalf("expected max_parallel_tools clamped to 64, got %d", cfg.Agents.Defaults.MaxParallelTools)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.MaxParallelTools = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_parallel_tools")
+	}
+}
+
+func TestValidate_CircuitBreaker(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Tools.CircuitBreaker.Enabled {
+		t.Fatal("expected tool circuit breaker disabled by default")
+	}
+	cfg.Tools.CircuitBreaker.FailureThreshold = 0
+	cfg.Tools.CircuitBreaker.WindowSeconds = 0
+	cfg.Tools.CircuitBreaker.CooldownSeconds = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying circuit breaker defaults: %v", err)
+	}
+	cb := cfg.Tools.CircuitBreaker
+	if cb.FailureThreshold != 5 || cb.WindowSeconds != 60 || cb.CooldownSeconds != 30 {
+		t.Fatalf("unexpected circuit breaker defaults: %+v", cb)
+	}
+
+	for _, mutate := range []func(*CircuitBreakerConfig){
+		func(c *CircuitBreakerConfig) { c.FailureThreshold = -1 },
+		func(c *CircuitBreakerConfig) { c.WindowSeconds = -1 },
+		func(c *CircuitBreakerConfig) { c.CooldownSeconds = -1 },
+	} {
+		cfg = DefaultConfig()
+		mutate(&cfg.Tools.CircuitBreaker)
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for negative circuit breaker setting: %+v", cfg.Tools.CircuitBreaker)
+		}
+	}
+}
+
+func TestValidate_InboundRateLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Channels.Inbound.RateLimitPerMinute != 0 {
+		t.Fatalf("expected inbound rate limit disabled by default, got %d", cfg.Channels.Inbound.RateLimitPerMinute)
+	}
+	cfg.Channels.Inbound.RateLimitPerMinute = 10
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error for positive rate_limit_per_minute: %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Channels.Inbound.RateLimitPerMinute = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative rate_limit_per_minute")
+	}
+
+	cfg = DefaultConfig()
+	cfg.Channels.Inbound.RateLimitBurst = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative rate_limit_burst")
+	}
+}
+
+func TestValidate_InboundDedupWindow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channels.Inbound.DedupWindowSeconds = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying dedup_window_seconds default: %v", err)
+	}
+	if cfg.Channels.Inbound.DedupWindowSeconds != 300 {
+		t.Fatalf("expected dedup_window_seconds default 300, got %d", cfg.Channels.Inbound.DedupWindowSeconds)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Channels.Inbound.DedupWindowSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative dedup_window_seconds")
+	}
+}
+
+func TestValidate_HTTPProxy(t *testing.T) {
+	for _, proxy := range []string{"", "proxy.corp:3128", " http://proxy.corp:3128 ", "socks5://127.0.0.1:1080"} {
+		cfg := DefaultConfig()
+		cfg.HTTP.Proxy = proxy
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("proxy %q: unexpected error: %v", proxy, err)
+		}
+	}
+	for _, proxy := range []string{"ftp://proxy.corp:21", "http://"} {
+		cfg := DefaultConfig()
+		cfg.HTTP.Proxy = proxy
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected validation error for proxy %q", proxy)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.HTTP.TimeoutSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative http.timeout_seconds")
+	}
+}
+
+func TestValidate_InboundMessageSize(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channels.Inbound.OversizePolicy = ""
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Channels.Inbound.MaxMessageChars != 0 || cfg.Channels.Inbound.OversizePolicy != OversizeTruncate {
+		t.Fatalf("expected no size limit and truncate policy by default, got %+v", cfg.Channels.Inbound)
+	}
+
+	cfg.Channels.Inbound.OversizePolicy = " Reject "
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Channels.Inbound.OversizePolicy != OversizeReject {
+		t.Fatalf("expected normalized reject policy, got %q", cfg.Channels.Inbound.OversizePolicy)
+	}
+
+	cfg.Channels.Inbound.OversizePolicy = "drop"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for unknown oversize_policy")
+	}
+	cfg = DefaultConfig()
+	cfg.Channels.Inbound.MaxMessageChars = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative max_message_chars")
+	}
+}
+
+func TestValidate_ReactionDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	if cfg.Channels.Reactions.Enabled {
+		t.Fatal("expected reactions disabled by default")
+	}
+	cfg.Channels.Reactions = ChannelReactionsConfig{Enabled: true, Done: " ✅ "}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := cfg.Channels.Reactions
+	if got.Processing != DefaultReactionProcessing || got.Done != "✅" || got.Failed != DefaultReactionFailed {
+		t.Fatalf("unexpected normalized reactions: %+v", got)
+	}
+}
+
+func TestValidate_ModelRetryDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Agents.Defaults.ModelRetryMaxAttempts = 0
+	cfg.Agents.Defaults.ModelRetryBaseBackoffMs = 0
+	cfg.Agents.Defaults.ModelRetryMaxBackoffMs = 0
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying model retry defaults: %v", err)
+	}
+	d := cfg.Agents.Defaults
+	if d.ModelRetryMaxAttempts != 3 || d.ModelRetryBaseBackoffMs != 500 || d.ModelRetryMaxBackoffMs != 8000 {
+		t.Fatalf("unexpected model retry defaults: %+v", d)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.ModelRetryBaseBackoffMs = 2000
+	cfg.Agents.Defaults.ModelRetryMaxBackoffMs = 100
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Agents.Defaults.ModelRetryMaxBackoffMs != 2000 {
+		t.Fatalf("expected max backoff to be clamped to base, got %d", cfg.Agents.Defaults.ModelRetryMaxBackoffMs)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Agents.Defaults.ModelRetryMaxAttempts = -1
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected validation error for negative model_retry_max_attempts")
+	}
+}
+
+func TestChannelsToolPolicies_OnlyReturnsConfiguredChannels(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Channels.Discord.ToolPolicy = ToolPolicyConfig{Deny: []string{"exec"}}
+	cfg.Channels.Telegram.ToolPolicy = ToolPolicyConfig{Allow: []string{"read_file"}}
+
+	policies := cfg.Channels.ToolPolicies()
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 channel tool policies, got %+v", policies)
+	}
+	if got := policies["discord"].Deny; len(got) != 1 || got[0] != "exec" {
+		t.Fatalf("unexpected discord policy: %+v", policies["discord"])
+	}
+	if _, ok := policies["slack"]; ok {
+		t.Fatal("expected channels without tool_policy to be omitted")
+	}
+}
+
+func TestValidate_LogRotationDefaultsAndNegativeValues(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Log.Rotation = LogRotationConfig{}
+	cfg.Log.Audit = LogRotationConfig{MaxSizeMB: 5}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error applying log rotation defaults: %v", err)
+	}
+	if cfg.Log.Rotation.MaxSizeMB != 20 || cfg.Log.Rotation.MaxBackups != 5 ||
+		cfg.Log.Rotation.MaxAgeDays != 30 || cfg.Log.Rotation.MaxTotalSizeMB != 200 {
+		t.Fatalf("unexpected log.rotation defaults: %+v", cfg.Log.Rotation)
+	}
+	if cfg.Log.Audit.MaxSizeMB != 5 || cfg.Log.Audit.MaxBackups != 10 ||
+		cfg.Log.Audit.MaxAgeDays != 90 || cfg.Log.Audit.MaxTotalSizeMB != 1000 {
+		t.Fatalf("unexpected log.audit defaults: %+v", cfg.Log.Audit)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Log.Audit.MaxBackups = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "log.audit.max_backups") {
+		t.Fatalf("expected log.audit.max_backups error, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Log.Rotation.MaxSizeMB = 100
+	cfg.Log.Rotation.MaxTotalSizeMB = 50
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "max_total_size_mb") {
+		t.Fatalf("expected max_total_size_mb error, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames 是按优先级排列的候选配置文件名；都不存在时新建 config.json。
+var configFileNames = []string{"config.yaml", "config.yml", "config.toml", "config.json"}
+
+const defaultConfigFileName = "config.json"
+
+// configType 根据扩展名返回 viper 使用的配置格式。
+func configType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return "json"
+	}
+}
+
+// detectConfigPath 返回目录中第一个存在的配置文件，均不存在时返回默认的 config.json 路径。
+func detectConfigPath(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, defaultConfigFileName)
+}
+
+// marshalConfig 按目标文件的格式序列化配置。JSON 保持原有输出；YAML/TOML 使用 mapstructure 键名。
+func marshalConfig(cfg *Config, path string) ([]byte, error) {
+	switch configType(path) {
+	case "yaml":
+		return yaml.Marshal(toConfigMap(reflect.ValueOf(cfg)))
+	case "toml":
+		return toml.Marshal(toConfigMap(reflect.ValueOf(cfg)))
+	default:
+		return json.MarshalIndent(cfg, "", "  ")
+	}
+}
+
+// toConfigMap 将配置结构体递归转换为以 mapstructure 标签为键的 map；nil 指针被省略。
+func toConfigMap(v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]any, v.NumField())
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			key := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			if key == "" || key == "-" {
+				key = strings.ToLower(field.Name)
+			}
+			if value := toConfigMap(v.Field(i)); value != nil {
+				out[key] = value
+			}
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			if value := toConfigMap(iter.Value()); value != nil {
+				out[fmt.Sprint(iter.Key().Interface())] = value
+			}
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]any, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out = append(out, toConfigMap(v.Index(i)))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
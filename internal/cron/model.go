@@ -1,8 +1,11 @@
 package cron
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/adhocore/gronx"
 	"github.com/google/uuid"
 )
 
@@ -23,12 +26,63 @@ type Payload struct {
 	Deliver bool   `json:"deliver"` // 是否直接交付结果而跳过 Agent 思考
 }
 
+// CatchUpPolicy 决定服务启动时如何处理进程停止期间错过的运行。
+type CatchUpPolicy string
+
+const (
+	CatchUpSkip    CatchUpPolicy = "skip"     // 丢弃错过的运行，从下一个计划时间继续
+	CatchUpRunOnce CatchUpPolicy = "run_once" // 无论错过多少次，启动后补跑一次（默认）
+	CatchUpRunAll  CatchUpPolicy = "run_all"  // 每次错过的运行都补跑，最多 maxCatchUpRuns 次
+)
+
+// ParseCatchUpPolicy 解析补跑策略，空字符串表示默认的 run_once。
+func ParseCatchUpPolicy(s string) (CatchUpPolicy, error) {
+	switch p := CatchUpPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return CatchUpRunOnce, nil
+	case CatchUpSkip, CatchUpRunOnce, CatchUpRunAll:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid catch-up policy %q (expected: skip, run_once, run_all)", s)
+	}
+}
+
+// maxRunHistory 是每个任务保留的最近运行记录条数。
+const maxRunHistory = 20
+
+// RunRecord 记录任务的一次运行。
+type RunRecord struct {
+	StartedAtMS int64  `json:"started_at_ms"`   // 开始时间
+	Status      string `json:"status"`          // "ok"、"error" 或 "skipped"
+	DurationMS  int64  `json:"duration_ms"`     // 运行耗时
+	Error       string `json:"error,omitempty"` // 失败时的错误消息
+}
+
 // JobState 维护任务的实时运行状态。
 type JobState struct {
-	NextRunAtMS *int64 `json:"next_run_at_ms,omitempty"` // 下次预定执行时间
-	LastRunAtMS *int64 `json:"last_run_at_ms,omitempty"` // 最近一次执行时间
-	LastStatus  string `json:"last_status,omitempty"`    // 最近执行状态 ("ok", "error")
-	LastError   string `json:"last_error,omitempty"`     // 最近一次执行产生的错误消息
+	NextRunAtMS *int64      `json:"next_run_at_ms,omitempty"` // 下次预定执行时间
+	LastRunAtMS *int64      `json:"last_run_at_ms,omitempty"` // 最近一次执行时间
+	LastStatus  string      `json:"last_status,omitempty"`    // 最近执行状态 ("ok", "error", "skipped")
+	LastError   string      `json:"last_error,omitempty"`     // 最近一次执行产生的错误消息
+	History     []RunRecord `json:"history,omitempty"`        // 最近的运行记录，按时间先后排列，最多 maxRunHistory 条
+}
+
+// recordRun 追加一条运行记录，超出上限时丢弃最早的记录。
+func (s *JobState) recordRun(r RunRecord) {
+	s.History = append(s.History, r)
+	if n := len(s.History) - maxRunHistory; n > 0 {
+		s.History = append([]RunRecord(nil), s.History[n:]...)
+	}
+}
+
+// LastFailure 返回最近一次失败的运行记录。
+func (s *JobState) LastFailure() (RunRecord, bool) {
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].Status == "error" {
+			return s.History[i], true
+		}
+	}
+	return RunRecord{}, false
 }
 
 // Job 表示一个完整的定时任务配置及其状态。
@@ -42,6 +96,16 @@ type Job struct {
 	CreatedAtMS    int64    `json:"created_at_ms"`              // 创建时间
 	UpdatedAtMS    int64    `json:"updated_at_ms"`              // 更新时间
 	DeleteAfterRun bool     `json:"delete_after_run,omitempty"` // 执行完成后是否自动从存储中删除
+
+	CatchUp CatchUpPolicy `json:"catch_up,omitempty"` // 错过运行时的补跑策略，空值等同 run_once
+}
+
+// EffectiveCatchUp 返回任务生效的补跑策略。
+func (j *Job) EffectiveCatchUp() CatchUpPolicy {
+	if j.CatchUp == "" {
+		return CatchUpRunOnce
+	}
+	return j.CatchUp
 }
 
 // NewJob 创建一个新的任务实例，并自动分配 ID 和时间戳。
@@ -66,6 +130,27 @@ func (j *Job) ShortID() string {
 	return j.ID
 }
 
+// Validate 检查调度计划是否完整且可计算下一次运行时间。
+func (s Schedule) Validate() error {
+	switch s.Kind {
+	case "at":
+		if s.AtMS == nil {
+			return fmt.Errorf("at schedule requires a timestamp")
+		}
+	case "every":
+		if s.EveryMS == nil || *s.EveryMS <= 0 {
+			return fmt.Errorf("every schedule requires a positive interval")
+		}
+	case "cron":
+		if !gronx.IsValid(s.Expr) {
+			return fmt.Errorf("invalid cron expression: %q", s.Expr)
+		}
+	default:
+		return fmt.Errorf("unknown schedule kind: %q", s.Kind)
+	}
+	return nil
+}
+
 // ScheduleDescription 返回任务调度计划的人类可读描述。
 func (j *Job) ScheduleDescription() string {
 	switch j.Schedule.Kind {
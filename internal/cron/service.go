@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +15,9 @@ import (
 // JobHandler 定义了定时任务触发时的处理函数。
 type JobHandler func(*Job) error
 
+// maxCatchUpRuns 是 run_all 策略下单个任务在启动时最多补跑的次数，避免长时间停机后集中触发大量运行。
+const maxCatchUpRuns = 10
+
 // Service 负责管理定时任务的生命周期，并使用轮询循环 (Ticker) 进行调度执行。
 type Service struct {
 	store    *Store     // 任务持久化存储
@@ -22,6 +26,7 @@ type Service struct {
 	stopChan chan struct{}
 	stopped  chan struct{}
 	running  bool
+	catchUp  map[string]int // run_all 任务在下次到期时需要额外补跑的次数
 }
 
 // NewService 创建并返回一个由指定文件路径支持的定时任务服务。
@@ -45,6 +50,7 @@ func (s *Service) Start() error {
 			s.store.Put(job)
 		}
 	}
+	s.applyCatchUp(time.Now())
 	if err := s.store.Save(); err != nil {
 		slog.Warn("cron: failed to save after init", "error", err)
 	}
@@ -114,30 +120,112 @@ func (s *Service) tick() {
 		}
 	}
 
-	// 执行到期的任务
+	// 执行到期的任务，run_all 任务连同错过的次数一并补跑
 	for _, j := range due {
-		s.executeJob(j)
+		runs := 1 + s.takeCatchUp(j.ID)
+		for range runs {
+			s.executeJob(j)
+		}
+	}
+}
+
+// applyCatchUp 按各任务的补跑策略处理进程停止期间已过期的任务：
+// skip 直接推进到下一个计划时间（单次任务则停用），run_once 保留到期状态由下一次 tick 执行一次，
+// run_all 额外记录错过的次数。
+func (s *Service) applyCatchUp(now time.Time) {
+	nowMS := now.UnixMilli()
+	for _, job := range s.store.All() {
+		if !job.Enabled || job.State.NextRunAtMS == nil || *job.State.NextRunAtMS > nowMS {
+			continue
+		}
+		missed := missedRuns(job, now)
+		policy := job.EffectiveCatchUp()
+		slog.Info("cron: job missed scheduled runs", "id", job.ID, "name", job.Name, "missed", missed, "catch_up", string(policy))
+
+		switch policy {
+		case CatchUpSkip:
+			if job.Schedule.Kind == "at" {
+				if job.DeleteAfterRun {
+					s.store.Delete(job.ID)
+					continue
+				}
+				job.Enabled = false
+				job.State.NextRunAtMS = nil
+			} else {
+				s.computeNextRun(job)
+			}
+			job.State.LastStatus = "skipped"
+			job.State.recordRun(RunRecord{StartedAtMS: nowMS, Status: "skipped"})
+			job.UpdatedAtMS = nowMS
+			s.store.Put(job)
+		case CatchUpRunAll:
+			if missed > 1 {
+				s.mu.Lock()
+				if s.catchUp == nil {
+					s.catchUp = make(map[string]int)
+				}
+				s.catchUp[job.ID] = missed - 1
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+func (s *Service) takeCatchUp(id string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.catchUp[id]
+	delete(s.catchUp, id)
+	return n
+}
+
+// missedRuns 统计从 NextRunAtMS 到 now 之间错过的运行次数（至少为 1），上限为 maxCatchUpRuns。
+func missedRuns(job *Job, now time.Time) int {
+	next := time.UnixMilli(*job.State.NextRunAtMS)
+	switch job.Schedule.Kind {
+	case "every":
+		if job.Schedule.EveryMS != nil && *job.Schedule.EveryMS > 0 {
+			n := now.Sub(next).Milliseconds() / *job.Schedule.EveryMS
+			return int(min(n+1, maxCatchUpRuns))
+		}
+	case "cron":
+		n := 1
+		for n < maxCatchUpRuns {
+			t, err := gronx.NextTickAfter(job.Schedule.Expr, next, false)
+			if err != nil || t.After(now) {
+				break
+			}
+			next = t
+			n++
+		}
+		return n
 	}
+	return 1
 }
 
 func (s *Service) executeJob(job *Job) {
 	slog.Info("cron: executing job", "id", job.ID, "name", job.Name)
 
-	now := time.Now().UnixMilli()
+	started := time.Now()
+	now := started.UnixMilli()
 	var execErr error
 	if s.onJob != nil {
 		execErr = s.onJob(job)
 	}
 
+	record := RunRecord{StartedAtMS: now, DurationMS: time.Since(started).Milliseconds()}
 	job.State.LastRunAtMS = &now
 	if execErr != nil {
 		job.State.LastStatus = "error"
 		job.State.LastError = execErr.Error()
+		record.Error = execErr.Error()
 		slog.Error("cron: job execution failed", "id", job.ID, "error", execErr)
 	} else {
 		job.State.LastStatus = "ok"
 		job.State.LastError = ""
 	}
+	record.Status = job.State.LastStatus
+	job.State.recordRun(record)
 
 	job.UpdatedAtMS = now
 
@@ -205,17 +293,31 @@ func (s *Service) computeNextRun(job *Job) {
 	}
 }
 
+// AddOptions 描述创建任务时的可选设置。
+type AddOptions struct {
+	Channel string        // 目标回复通道
+	ChatID  string        // 目标聊天 ID
+	Deliver bool          // 是否直接交付结果
+	CatchUp CatchUpPolicy // 错过运行时的补跑策略，空值为 run_once
+}
+
 // AddJob 创建并持久化一个新的定时任务。
 func (s *Service) AddJob(name, message string, schedule Schedule, channel, chatID string, deliver bool) (*Job, error) {
+	return s.AddJobWithOptions(name, message, schedule, AddOptions{Channel: channel, ChatID: chatID, Deliver: deliver})
+}
+
+// AddJobWithOptions 按给定选项创建并持久化一个新的定时任务。
+func (s *Service) AddJobWithOptions(name, message string, schedule Schedule, opts AddOptions) (*Job, error) {
 	payload := Payload{
 		Kind:    "agent_turn",
 		Message: message,
-		Channel: channel,
-		ChatID:  chatID,
-		Deliver: deliver,
+		Channel: opts.Channel,
+		ChatID:  opts.ChatID,
+		Deliver: opts.Deliver,
 	}
 
 	job := NewJob(name, schedule, payload)
+	job.CatchUp = opts.CatchUp
 
 	if schedule.Kind == "at" {
 		job.DeleteAfterRun = true
@@ -232,6 +334,55 @@ func (s *Service) AddJob(name, message string, schedule Schedule, channel, chatI
 	return job, nil
 }
 
+// UpdateOptions 描述编辑任务时要修改的字段，nil 表示保持原值。
+type UpdateOptions struct {
+	Name     *string
+	Message  *string
+	Schedule *Schedule
+}
+
+// UpdateJob 按 ID 修改任务的名称、消息或调度计划，任务 ID 与运行历史保持不变。
+// 新的调度计划在持久化前校验，并据此重新计算下一次运行时间。
+func (s *Service) UpdateJob(id string, opts UpdateOptions) (*Job, error) {
+	job, ok := s.store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	if opts.Name != nil {
+		name := strings.TrimSpace(*opts.Name)
+		if name == "" {
+			return nil, fmt.Errorf("job name must not be empty")
+		}
+		job.Name = name
+	}
+	if opts.Message != nil {
+		if strings.TrimSpace(*opts.Message) == "" {
+			return nil, fmt.Errorf("job message must not be empty")
+		}
+		job.Payload.Message = *opts.Message
+	}
+	if opts.Schedule != nil {
+		if err := opts.Schedule.Validate(); err != nil {
+			return nil, err
+		}
+		job.Schedule = *opts.Schedule
+		job.DeleteAfterRun = job.Schedule.Kind == "at"
+		job.State.NextRunAtMS = nil
+		s.computeNextRun(job)
+
+		s.takeCatchUp(id) // 旧计划下累积的补跑次数不再适用
+	}
+	job.UpdatedAtMS = time.Now().UnixMilli()
+
+	s.store.Put(job)
+	if err := s.store.Save(); err != nil {
+		return nil, fmt.Errorf("save after update: %w", err)
+	}
+
+	slog.Info("cron: job updated", "id", job.ID, "name", job.Name, "schedule", job.ScheduleDescription())
+	return job, nil
+}
+
 // RemoveJob 按 ID 删除指定的任务。
 func (s *Service) RemoveJob(id string) error {
 	if !s.store.Delete(id) {
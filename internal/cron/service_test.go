@@ -1,6 +1,7 @@
 package cron
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sync/atomic"
@@ -221,6 +222,98 @@ func TestCronSchedule(t *testing.T) {
 	}
 }
 
+func TestUpdateJob_ChangesOnlyGivenFieldsAndKeepsID(t *testing.T) {
+	svc := NewService(tempStorePath(t), nil)
+	if err := svc.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Stop()
+
+	every := int64(60000)
+	job, err := svc.AddJob("report", "status report", Schedule{Kind: "every", EveryMS: &every}, "telegram", "42", false)
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	if _, err := svc.RunJob(job.ID); err != nil {
+		t.Fatalf("RunJob: %v", err)
+	}
+
+	schedule := Schedule{Kind: "cron", Expr: "0 9 * * *"}
+	updated, err := svc.UpdateJob(job.ID, UpdateOptions{Schedule: &schedule})
+	if err != nil {
+		t.Fatalf("UpdateJob: %v", err)
+	}
+	if updated.ID != job.ID || updated.Name != "report" || updated.Payload.Message != "status report" || updated.Payload.ChatID != "42" {
+		t.Fatalf("unexpected fields after schedule update: %+v", updated)
+	}
+	if updated.Schedule.Kind != "cron" || updated.State.NextRunAtMS == nil {
+		t.Fatalf("expected cron schedule with next run, got %+v", updated)
+	}
+	next := time.UnixMilli(*updated.State.NextRunAtMS)
+	if next.Minute() != 0 || next.Hour() != 9 {
+		t.Fatalf("expected next run at 09:00, got %s", next)
+	}
+	if len(updated.State.History) != 1 {
+		t.Fatalf("expected run history to be kept, got %d records", len(updated.State.History))
+	}
+
+	message := "daily briefing"
+	if _, err := svc.UpdateJob(job.ID, UpdateOptions{Message: &message}); err != nil {
+		t.Fatalf("UpdateJob message: %v", err)
+	}
+	got, ok := svc.GetJob(job.ID)
+	if !ok || got.Payload.Message != "daily briefing" || got.Schedule.Expr != "0 9 * * *" {
+		t.Fatalf("unexpected job after message update: %+v", got)
+	}
+
+	at := time.Now().Add(time.Hour).UnixMilli()
+	if _, err := svc.UpdateJob(job.ID, UpdateOptions{Schedule: &Schedule{Kind: "at", AtMS: &at}}); err != nil {
+		t.Fatalf("UpdateJob at: %v", err)
+	}
+	got, _ = svc.GetJob(job.ID)
+	if !got.DeleteAfterRun || got.State.NextRunAtMS == nil || *got.State.NextRunAtMS != at {
+		t.Fatalf("expected one-shot schedule at %d, got %+v", at, got)
+	}
+}
+
+func TestUpdateJob_RejectsInvalidSchedule(t *testing.T) {
+	path := tempStorePath(t)
+	svc := NewService(path, nil)
+	if err := svc.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Stop()
+
+	job, err := svc.AddJob("cron-test", "msg", Schedule{Kind: "cron", Expr: "*/5 * * * *"}, "", "", false)
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+
+	zero := int64(0)
+	for _, schedule := range []Schedule{
+		{Kind: "cron", Expr: "not a cron"},
+		{Kind: "every", EveryMS: &zero},
+		{Kind: "at"},
+		{Kind: "weekly"},
+	} {
+		if _, err := svc.UpdateJob(job.ID, UpdateOptions{Schedule: &schedule}); err == nil {
+			t.Fatalf("expected error for schedule %+v", schedule)
+		}
+	}
+	if _, err := svc.UpdateJob("missing", UpdateOptions{}); err == nil {
+		t.Fatal("expected error for unknown job")
+	}
+
+	store := NewStore(path)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	persisted, ok := store.Get(job.ID)
+	if !ok || persisted.Schedule.Expr != "*/5 * * * *" {
+		t.Fatalf("invalid update should not be persisted, got %+v", persisted)
+	}
+}
+
 func TestRunJob_ManualExecutionUpdatesState(t *testing.T) {
 	var fired atomic.Int32
 
@@ -257,6 +350,53 @@ func TestRunJob_ManualExecutionUpdatesState(t *testing.T) {
 	}
 }
 
+func TestRunJob_RecordsBoundedRunHistory(t *testing.T) {
+	var calls atomic.Int32
+	svc := NewService(tempStorePath(t), func(job *Job) error {
+		if calls.Add(1)%2 == 0 {
+			return errors.New("provider timeout")
+		}
+		return nil
+	})
+
+	every := int64(60000)
+	job, err := svc.AddJob("history", "msg", Schedule{Kind: "every", EveryMS: &every}, "cli", "direct", false)
+	if err != nil {
+		t.Fatalf("AddJob: %v", err)
+	}
+	for range maxRunHistory + 5 {
+		if _, err := svc.RunJob(job.ID); err != nil {
+			t.Fatalf("RunJob: %v", err)
+		}
+	}
+
+	updated, _ := svc.GetJob(job.ID)
+	history := updated.State.History
+	if len(history) != maxRunHistory {
+		t.Fatalf("expected %d history entries, got %d", maxRunHistory, len(history))
+	}
+	last := history[len(history)-1]
+	if last.Status != "ok" || last.Error != "" {
+		t.Fatalf("expected last run ok, got %+v", last)
+	}
+	failure, ok := updated.State.LastFailure()
+	if !ok || failure.Status != "error" || failure.Error != "provider timeout" {
+		t.Fatalf("expected last failure to be recorded, got %+v (ok=%v)", failure, ok)
+	}
+	if updated.State.LastError != "" {
+		t.Fatalf("LastError should clear after a successful run, got %q", updated.State.LastError)
+	}
+
+	// 运行记录随任务持久化。
+	reloaded := NewStore(svc.store.path)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if j, ok := reloaded.Get(job.ID); !ok || len(j.State.History) != maxRunHistory {
+		t.Fatalf("expected persisted history, got %+v", j)
+	}
+}
+
 func TestStoreLoadNonexistent(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "nonexistent", "jobs.json")
 	store := NewStore(path)
@@ -278,3 +418,71 @@ func TestStoreLoadCorrupt(t *testing.T) {
 		t.Fatal("expected error for corrupt JSON")
 	}
 }
+
+func TestCatchUpPolicies(t *testing.T) {
+	fired := map[string]int{}
+	svc := NewService(tempStorePath(t), func(job *Job) error {
+		fired[job.Name]++
+		return nil
+	})
+
+	every := int64(60000)
+	overdue := time.Now().Add(-5*time.Minute - 30*time.Second).UnixMilli() // 错过 6 次
+	for _, policy := range []CatchUpPolicy{"", CatchUpSkip, CatchUpRunOnce, CatchUpRunAll} {
+		name := "job-" + string(policy)
+		job, err := svc.AddJobWithOptions(name, "summary", Schedule{Kind: "every", EveryMS: &every}, AddOptions{CatchUp: policy})
+		if err != nil {
+			t.Fatalf("AddJobWithOptions: %v", err)
+		}
+		job.State.NextRunAtMS = &overdue
+		svc.store.Put(job)
+	}
+	at := overdue
+	atJob, _ := svc.AddJobWithOptions("at-skip", "once", Schedule{Kind: "at", AtMS: &at}, AddOptions{CatchUp: CatchUpSkip})
+
+	svc.applyCatchUp(time.Now())
+	svc.tick()
+
+	want := map[string]int{"job-": 1, "job-run_once": 1, "job-run_all": 6}
+	for name, n := range want {
+		if fired[name] != n {
+			t.Fatalf("%s fired %d times, want %d (all: %v)", name, fired[name], n, fired)
+		}
+	}
+	if fired["job-skip"] != 0 || fired["at-skip"] != 0 {
+		t.Fatalf("skip jobs should not fire: %v", fired)
+	}
+	for _, j := range svc.ListJobs(true) {
+		if j.Name == "job-skip" && (j.State.NextRunAtMS == nil || *j.State.NextRunAtMS <= time.Now().UnixMilli()) {
+			t.Fatalf("skip job should be rescheduled into the future, got %v", j.State.NextRunAtMS)
+		}
+	}
+	if _, ok := svc.GetJob(atJob.ID); ok {
+		t.Fatal("missed one-shot job with skip policy should be deleted")
+	}
+}
+
+func TestMissedRuns_CronExpressionIsCapped(t *testing.T) {
+	next := time.Now().Add(-30 * time.Minute).Truncate(time.Minute).UnixMilli()
+	job := &Job{Schedule: Schedule{Kind: "cron", Expr: "* * * * *"}, State: JobState{NextRunAtMS: &next}}
+	if got := missedRuns(job, time.Now()); got != maxCatchUpRuns {
+		t.Fatalf("missedRuns = %d, want cap %d", got, maxCatchUpRuns)
+	}
+
+	next = time.Now().Add(-90 * time.Second).Truncate(time.Minute).UnixMilli()
+	if got := missedRuns(job, time.Now()); got < 2 || got > 3 {
+		t.Fatalf("missedRuns = %d, want 2 or 3", got)
+	}
+}
+
+func TestParseCatchUpPolicy(t *testing.T) {
+	for in, want := range map[string]CatchUpPolicy{"": CatchUpRunOnce, "SKIP": CatchUpSkip, " run_all ": CatchUpRunAll} {
+		got, err := ParseCatchUpPolicy(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseCatchUpPolicy(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseCatchUpPolicy("sometimes"); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
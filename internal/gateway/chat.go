@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// chatRequest 是 POST /chat 的请求体。message 为 content 的旧名称；
+// 未指定 chat_id 时沿用旧行为，以 session_id（默认 "default"）作为 gateway 通道的聊天 ID。
+type chatRequest struct {
+	Channel   string `json:"channel"`
+	ChatID    string `json:"chat_id"`
+	SenderID  string `json:"sender_id"`
+	Content   string `json:"content"`
+	Message   string `json:"message"`
+	SessionID string `json:"session_id"`
+	Stream    bool   `json:"stream"`
+}
+
+// gatewayChannel 是网关请求未指定通道时使用的通道名。
+const gatewayChannel = "gateway"
+
+// channelAllowed 报告请求能否路由到 channel。未配置 gateway.token 时任何网络调用方都能访问网关，
+// 因此只允许 gateway 通道，避免冒充其他通道中的聊天。
+func channelAllowed(token, channel string) bool {
+	return strings.TrimSpace(token) != "" || channel == gatewayChannel
+}
+
+// chatHandler 处理 POST /chat。请求头 Accept 包含 text/event-stream 或请求体 stream 为 true 时，
+// 以 SSE 流式返回增量文本与工具事件，最后以 done 事件给出完整回复；否则返回一次性 JSON。
+func chatHandler(token string, processor ChatProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := getRequestID(r)
+		start := time.Now()
+		if r.Method != http.MethodPost {
+			writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+			return
+		}
+		if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+			writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+			return
+		}
+
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "invalid json request")
+			return
+		}
+		content := strings.TrimSpace(req.Content)
+		if content == "" {
+			content = strings.TrimSpace(req.Message)
+		}
+		if content == "" {
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "message is required")
+			return
+		}
+		channel := strings.TrimSpace(req.Channel)
+		if channel == "" {
+			channel = gatewayChannel
+		}
+		if !channelAllowed(token, channel) {
+			writeError(w, requestID, http.StatusForbidden, "forbidden", "routing to a channel other than gateway requires gateway.token")
+			return
+		}
+		chatID := strings.TrimSpace(req.ChatID)
+		sessionID := strings.TrimSpace(req.SessionID)
+		explicitSession := ""
+		if chatID == "" {
+			if sessionID == "" {
+				sessionID = "default"
+			}
+			chatID = sessionID
+		} else if sessionID != "" {
+			explicitSession = sessionID
+		} else {
+			sessionID = channel + ":" + chatID
+		}
+		senderID := strings.TrimSpace(req.SenderID)
+		if senderID == "" {
+			senderID = "api"
+		}
+		stream := req.Stream || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+		slog.Info("gateway chat request",
+			"request_id", requestID,
+			"channel", channel,
+			"chat_id", chatID,
+			"session_id", sessionID,
+			"sender_id", senderID,
+			"stream", stream,
+		)
+
+		if processor == nil {
+			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "chat processor is not configured")
+			return
+		}
+
+		process := func(ctx context.Context) (string, error) {
+			ctx = bus.WithUnverifiedSender(bus.WithRequestID(ctx, requestID))
+			if explicitSession != "" {
+				if sp, ok := processor.(SessionChatProcessor); ok {
+					return sp.ProcessForChannelWithSession(ctx, channel, chatID, senderID, explicitSession, content)
+				}
+			}
+			return processor.ProcessForChannel(ctx, channel, chatID, senderID, content)
+		}
+
+		if stream {
+			streamChat(w, r, requestID, sessionID, process)
+		} else {
+			outputs := &toolOutputCollector{}
+			resp, err := process(bus.WithStreamObserver(r.Context(), &bus.StreamObserver{OnToolOutput: outputs.add}))
+			var tooLong *bus.MessageTooLongError
+			if errors.As(err, &tooLong) {
+				writeError(w, requestID, http.StatusRequestEntityTooLarge, "message_too_long", tooLong.Error())
+				return
+			}
+			if err != nil {
+				slog.Error("gateway chat failed", "request_id", requestID, "channel", channel, "session_id", sessionID, "error", err)
+				writeError(w, requestID, http.StatusInternalServerError, "internal_error", "failed to process chat request")
+				return
+			}
+			writeJSON(w, http.StatusOK, outputs.attach(map[string]any{
+				"response":   resp,
+				"session_id": sessionID,
+				"request_id": requestID,
+			}))
+		}
+		slog.Info("gateway chat completed",
+			"request_id", requestID,
+			"channel", channel,
+			"session_id", sessionID,
+			"stream", stream,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	}
+}
+
+// streamChat 以 Server-Sent Events 返回处理过程：
+// delta（增量文本）、tool_start、tool_finish、tool_output（结构化工具结果），最后是 done（完整回复）或 error。
+// 客户端断开连接会取消请求上下文，从而中止模型调用与工具执行。
+func streamChat(w http.ResponseWriter, r *http.Request, requestID, sessionID string, process func(context.Context) (string, error)) {
+	sse := &sseWriter{w: w, rc: http.NewResponseController(w)}
+	outputs := &toolOutputCollector{}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	_ = sse.rc.Flush()
+
+	observer := &bus.StreamObserver{
+		OnContentDelta: func(delta string) {
+			sse.send("delta", map[string]any{"content": delta})
+		},
+		OnToolStart: func(name, args string) {
+			sse.send("tool_start", map[string]any{"name": name, "args": args})
+		},
+		OnToolFinish: func(name, result string, err error) {
+			event := map[string]any{"name": name, "result": result}
+			if err != nil {
+				event["error"] = err.Error()
+			}
+			sse.send("tool_finish", event)
+		},
+		OnToolOutput: func(output bus.ToolOutput) {
+			outputs.add(output)
+			sse.send("tool_output", output)
+		},
+	}
+
+	resp, err := process(bus.WithStreamObserver(r.Context(), observer))
+	if err != nil {
+		if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+			slog.Info("gateway chat stream cancelled by client", "request_id", requestID, "session_id", sessionID)
+			return
+		}
+		var tooLong *bus.MessageTooLongError
+		if errors.As(err, &tooLong) {
+			sse.send("error", map[string]any{
+				"code":       "message_too_long",
+				"message":    tooLong.Error(),
+				"request_id": requestID,
+			})
+			return
+		}
+		slog.Error("gateway chat failed", "request_id", requestID, "session_id", sessionID, "error", err)
+		sse.send("error", map[string]any{
+			"code":       "internal_error",
+			"message":    "failed to process chat request",
+			"request_id": requestID,
+		})
+		return
+	}
+	sse.send("done", outputs.attach(map[string]any{
+		"response":   resp,
+		"session_id": sessionID,
+		"request_id": requestID,
+	}))
+}
+
+// toolOutputCollector 收集单次请求中结构化工具的结果；工具可能并发执行，按完成顺序记录。
+type toolOutputCollector struct {
+	mu      sync.Mutex
+	outputs []bus.ToolOutput
+}
+
+func (c *toolOutputCollector) add(output bus.ToolOutput) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outputs = append(c.outputs, output)
+}
+
+// attach 在有结构化结果时向响应体加入 tool_outputs 字段。
+func (c *toolOutputCollector) attach(body map[string]any) map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.outputs) > 0 {
+		body["tool_outputs"] = append([]bus.ToolOutput(nil), c.outputs...)
+	}
+	return body
+}
+
+// sseWriter 串行化 SSE 事件写入，每个事件写完后立即刷新。
+type sseWriter struct {
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *sseWriter) send(event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return
+	}
+	_ = s.rc.Flush()
+}
@@ -0,0 +1,260 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// streamingChatProcessor emits stream events through the observer in ctx and
+// records the routing it was called with.
+type streamingChatProcessor struct {
+	gotChannel string
+	gotChatID  string
+	gotSession string
+	err        error
+}
+
+func (p *streamingChatProcessor) ProcessForChannel(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+	return p.ProcessForChannelWithSession(ctx, channel, chatID, senderID, "", content)
+}
+
+func (p *streamingChatProcessor) ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error) {
+	p.gotChannel, p.gotChatID, p.gotSession = channel, chatID, sessionID
+	if observer := bus.StreamObserverFromContext(ctx); observer != nil {
+		observer.OnToolStart("web_search", `{"query":"go"}`)
+		observer.OnToolFinish("web_search", "results", nil)
+		observer.OnContentDelta("Hel")
+		observer.OnContentDelta("lo")
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return "Hello", nil
+}
+
+type sseEvent struct {
+	name string
+	data map[string]any
+}
+
+func readSSE(t *testing.T, body *bytes.Buffer) []sseEvent {
+	t.Helper()
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &current.data); err != nil {
+				t.Fatalf("decode sse data %q: %v", line, err)
+			}
+		case line == "":
+			events = append(events, current)
+			current = sseEvent{}
+		}
+	}
+	return events
+}
+
+func TestChatStreamEmitsEventsAndFinalMessage(t *testing.T) {
+	processor := &streamingChatProcessor{}
+	h := NewHandler("secret-token", processor)
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi","channel":"web","chat_id":"c1","session_id":"sess-1"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("Accept", "text/event-stream")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if processor.gotChannel != "web" || processor.gotChatID != "c1" || processor.gotSession != "sess-1" {
+		t.Fatalf("unexpected routing: %+v", processor)
+	}
+
+	events := readSSE(t, rr.Body)
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.name)
+	}
+	if got := strings.Join(names, ","); got != "tool_start,tool_finish,delta,delta,done" {
+		t.Fatalf("unexpected event sequence %q", got)
+	}
+	if events[0].data["name"] != "web_search" || events[1].data["result"] != "results" {
+		t.Fatalf("unexpected tool events: %+v %+v", events[0].data, events[1].data)
+	}
+	if events[2].data["content"] != "Hel" {
+		t.Fatalf("unexpected delta: %+v", events[2].data)
+	}
+	done := events[len(events)-1].data
+	if done["response"] != "Hello" || done["session_id"] != "sess-1" || done["request_id"] == "" {
+		t.Fatalf("unexpected done event: %+v", done)
+	}
+}
+
+func TestChatStreamRequiresToken(t *testing.T) {
+	h := NewHandler("secret-token", &streamingChatProcessor{})
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi","stream":true}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+}
+
+func TestChatStreamReportsErrorEvent(t *testing.T) {
+	h := NewHandler("", &streamingChatProcessor{err: errors.New("model down")})
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi","stream":true}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	events := readSSE(t, rr.Body)
+	last := events[len(events)-1]
+	if last.name != "error" || last.data["code"] != "internal_error" {
+		t.Fatalf("expected trailing error event, got %+v", last)
+	}
+}
+
+func TestChatRejectsMessageOverSizeLimit(t *testing.T) {
+	tooLong := &bus.MessageTooLongError{Length: 12, Limit: 5}
+	h := NewHandler("", &mockChatProcessor{err: tooLong})
+
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hello world!"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	if body["code"] != "message_too_long" || body["message"] != tooLong.Error() {
+		t.Fatalf("unexpected error body: %v", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hello world!","stream":true}`))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	events := readSSE(t, rr.Body)
+	last := events[len(events)-1]
+	if last.name != "error" || last.data["code"] != "message_too_long" {
+		t.Fatalf("expected message_too_long error event, got %+v", last)
+	}
+}
+
+func TestChatRoutesChannelAndChatID(t *testing.T) {
+	processor := &mockChatProcessor{resp: "ok"}
+	h := NewHandler("secret-token", processor)
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi","channel":"web","chat_id":"c1"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if processor.gotSession != "web:c1" || processor.gotMessage != "hi" {
+		t.Fatalf("unexpected routing: session=%q message=%q", processor.gotSession, processor.gotMessage)
+	}
+	body := decodeJSON(t, rr.Body)
+	if body["session_id"] != "web:c1" {
+		t.Fatalf("expected session_id=web:c1, got %v", body["session_id"])
+	}
+}
+
+func TestChatWithoutTokenOnlyRoutesToGatewayChannel(t *testing.T) {
+	processor := &mockChatProcessor{resp: "ok"}
+	h := NewHandler("", processor)
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"/policy off","channel":"telegram","chat_id":"42","sender_id":"1"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+	if processor.gotMessage != "" {
+		t.Fatalf("expected the request not to be processed, got %q", processor.gotMessage)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi","channel":"gateway","chat_id":"c1"}`))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || processor.gotSession != "gateway:c1" {
+		t.Fatalf("expected gateway channel to be allowed, got %d session=%q", rr.Code, processor.gotSession)
+	}
+}
+
+// structuredChatProcessor 通过 observer 报告一个结构化工具结果。
+type structuredChatProcessor struct{}
+
+func (p *structuredChatProcessor) ProcessForChannel(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+	if observer := bus.StreamObserverFromContext(ctx); observer != nil && observer.OnToolOutput != nil {
+		observer.OnToolOutput(bus.ToolOutput{CallID: "call_1", Name: "web_search", Data: json.RawMessage(`{"results":[{"title":"Go"}]}`)})
+	}
+	return "found it", nil
+}
+
+func TestChatReturnsStructuredToolOutputs(t *testing.T) {
+	h := NewHandler("", &structuredChatProcessor{})
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"search go"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var body struct {
+		Response    string           `json:"response"`
+		ToolOutputs []bus.ToolOutput `json:"tool_outputs"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Response != "found it" || len(body.ToolOutputs) != 1 {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+	out := body.ToolOutputs[0]
+	if out.CallID != "call_1" || out.Name != "web_search" || string(out.Data) != `{"results":[{"title":"Go"}]}` {
+		t.Fatalf("unexpected tool output: %+v", out)
+	}
+
+	// 没有结构化结果时不返回 tool_outputs 字段。
+	plain := httptest.NewRecorder()
+	NewHandler("", &mockChatProcessor{resp: "ok"}).ServeHTTP(plain, httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"hi"}`)))
+	if strings.Contains(plain.Body.String(), "tool_outputs") {
+		t.Fatalf("expected no tool_outputs field, got %s", plain.Body.String())
+	}
+}
+
+func TestChatStreamEmitsStructuredToolOutputs(t *testing.T) {
+	h := NewHandler("", &structuredChatProcessor{})
+	req := httptest.NewRequest(http.MethodPost, "/chat", bytes.NewBufferString(`{"content":"search go","stream":true}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	events := readSSE(t, rr.Body)
+	if len(events) != 2 || events[0].name != "tool_output" || events[1].name != "done" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if events[0].data["name"] != "web_search" || events[0].data["call_id"] != "call_1" {
+		t.Fatalf("unexpected tool_output event: %+v", events[0].data)
+	}
+	outputs, ok := events[1].data["tool_outputs"].([]any)
+	if !ok || len(outputs) != 1 {
+		t.Fatalf("expected tool_outputs in done event, got %+v", events[1].data)
+	}
+}
@@ -0,0 +1,113 @@
+package gateway
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	// IdempotencyKeyHeader 是客户端声明幂等键的请求头；同一幂等键的重试会返回首次请求的回复。
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 在回复来自幂等缓存时被设置为 "true"。
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL     = 10 * time.Minute
+	idempotencyMaxEntries     = 1000 // 缓存条目上限，超出时淘汰最早的记录
+	idempotencyMaxKeyLength   = 255
+	idempotencyFingerprintSep = "\x00"
+)
+
+// idempotencyEntry 是一个幂等键对应的请求；done 关闭前请求仍在处理中。
+type idempotencyEntry struct {
+	key         string
+	fingerprint string // 请求内容摘要，用于拒绝复用同一幂等键的不同请求
+	createdAt   time.Time
+	done        chan struct{}
+	body        []byte // 成功回复的 JSON；处理失败时为空且条目会被移除
+}
+
+// idempotencyCache 按幂等键缓存 /message 的成功回复，条目数有上限并在 TTL 后过期。
+// 同一幂等键的并发重试会等待首个请求完成后共享其结果，而不会重复处理。
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // 按创建时间排列的 *idempotencyEntry
+	now     func() time.Time
+}
+
+func newIdempotencyCache(ttl time.Duration) *idempotencyCache {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &idempotencyCache{
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// requestFingerprint 由请求的路由字段与内容计算摘要。
+func requestFingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte(idempotencyFingerprintSep))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// begin 查找幂等键对应的条目。owner 为 true 时调用方负责处理请求并调用 finish；
+// 否则返回的条目属于更早的请求，调用方应通过 wait 取得其结果。
+func (c *idempotencyCache) begin(key, fingerprint string) (entry *idempotencyEntry, owner bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for front := c.order.Front(); front != nil; front = c.order.Front() {
+		e := front.Value.(*idempotencyEntry)
+		if now.Sub(e.createdAt) <= c.ttl && c.order.Len() < idempotencyMaxEntries {
+			break
+		}
+		c.order.Remove(front)
+		delete(c.entries, e.key)
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		return elem.Value.(*idempotencyEntry), false
+	}
+	entry = &idempotencyEntry{key: key, fingerprint: fingerprint, createdAt: now, done: make(chan struct{})}
+	c.entries[key] = c.order.PushBack(entry)
+	return entry, true
+}
+
+// finish 记录请求结果并唤醒等待者；body 为空表示处理失败，条目会被移除以便客户端重试。
+func (c *idempotencyCache) finish(entry *idempotencyEntry, body []byte) {
+	c.mu.Lock()
+	entry.body = body
+	if len(body) == 0 {
+		if elem, ok := c.entries[entry.key]; ok && elem.Value.(*idempotencyEntry) == entry {
+			c.order.Remove(elem)
+			delete(c.entries, entry.key)
+		}
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+// wait 等待条目完成并返回其缓存的回复；原请求失败时返回 nil。
+func (c *idempotencyCache) wait(ctx context.Context, entry *idempotencyEntry) ([]byte, error) {
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return entry.body, nil
+}
@@ -0,0 +1,215 @@
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// messageRequest 是 POST /message 的请求体。与 /chat 不同，channel、chat_id 与 content 均为必填。
+type messageRequest struct {
+	Channel   string `json:"channel"`
+	ChatID    string `json:"chat_id"`
+	SenderID  string `json:"sender_id"`
+	Content   string `json:"content"`
+	SessionID string `json:"session_id"`
+}
+
+// toolCallSummary 是 /message 响应中单次工具调用的摘要。
+type toolCallSummary struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// messageHandler 处理 POST /message：以指定通道与聊天 ID 注入一条消息，同步等待处理完成，
+// 返回最终回复与工具调用摘要。供不需要 SSE 流式输出的脚本与集成使用。
+// 请求携带 Idempotency-Key 时，同一幂等键的重试直接返回缓存的回复，不会再次处理。
+func messageHandler(token string, processor ChatProcessor, idempotency *idempotencyCache) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := getRequestID(r)
+		start := time.Now()
+		if r.Method != http.MethodPost {
+			writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+			return
+		}
+		if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+			writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+			return
+		}
+
+		var req messageRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "invalid json request")
+			return
+		}
+		channel := strings.TrimSpace(req.Channel)
+		chatID := strings.TrimSpace(req.ChatID)
+		content := strings.TrimSpace(req.Content)
+		switch {
+		case channel == "":
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "channel is required")
+			return
+		case chatID == "":
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "chat_id is required")
+			return
+		case content == "":
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "content is required")
+			return
+		}
+		if !channelAllowed(token, channel) {
+			writeError(w, requestID, http.StatusForbidden, "forbidden", "routing to a channel other than gateway requires gateway.token")
+			return
+		}
+		senderID := strings.TrimSpace(req.SenderID)
+		if senderID == "" {
+			senderID = "api"
+		}
+		explicitSession := strings.TrimSpace(req.SessionID)
+		sessionID := explicitSession
+		if sessionID == "" {
+			sessionID = channel + ":" + chatID
+		}
+
+		if processor == nil {
+			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "chat processor is not configured")
+			return
+		}
+
+		idemKey := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+		if len(idemKey) > idempotencyMaxKeyLength {
+			writeError(w, requestID, http.StatusBadRequest, "bad_request", "idempotency key is too long")
+			return
+		}
+		var idemEntry *idempotencyEntry
+		if idemKey != "" && idempotency != nil {
+			fingerprint := requestFingerprint(channel, chatID, senderID, explicitSession, content)
+			for {
+				entry, owner := idempotency.begin(idemKey, fingerprint)
+				if entry.fingerprint != fingerprint {
+					writeError(w, requestID, http.StatusUnprocessableEntity, "idempotency_key_reused", "idempotency key was already used for a different request")
+					return
+				}
+				if owner {
+					idemEntry = entry
+					break
+				}
+				body, err := idempotency.wait(r.Context(), entry)
+				if err != nil {
+					return
+				}
+				if body != nil {
+					slog.Info("gateway message replayed", "request_id", requestID, "idempotency_key", idemKey)
+					w.Header().Set(IdempotentReplayedHeader, "true")
+					writeRawJSON(w, http.StatusOK, body)
+					return
+				}
+				// 首个请求处理失败，条目已被移除，重新尝试成为处理者。
+			}
+		}
+		var replayBody []byte
+		if idemEntry != nil {
+			defer func() { idempotency.finish(idemEntry, replayBody) }()
+		}
+
+		slog.Info("gateway message request",
+			"request_id", requestID,
+			"channel", channel,
+			"chat_id", chatID,
+			"session_id", sessionID,
+			"sender_id", senderID,
+		)
+
+		tools := &toolCallRecorder{}
+		outputs := &toolOutputCollector{}
+		ctx := bus.WithUnverifiedSender(bus.WithRequestID(r.Context(), requestID))
+		ctx = bus.WithStreamObserver(ctx, &bus.StreamObserver{
+			OnToolStart:  tools.start,
+			OnToolFinish: tools.finish,
+			OnToolOutput: outputs.add,
+		})
+
+		var resp string
+		var err error
+		if sp, ok := processor.(SessionChatProcessor); ok {
+			resp, err = sp.ProcessForChannelWithSession(ctx, channel, chatID, senderID, explicitSession, content)
+		} else {
+			resp, err = processor.ProcessForChannel(ctx, channel, chatID, senderID, content)
+		}
+		var tooLong *bus.MessageTooLongError
+		if errors.As(err, &tooLong) {
+			writeError(w, requestID, http.StatusRequestEntityTooLarge, "message_too_long", tooLong.Error())
+			return
+		}
+		if err != nil {
+			slog.Error("gateway message failed", "request_id", requestID, "channel", channel, "session_id", sessionID, "error", err)
+			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "failed to process message")
+			return
+		}
+
+		body, err := encodeJSON(outputs.attach(map[string]any{
+			"response":   resp,
+			"channel":    channel,
+			"chat_id":    chatID,
+			"session_id": sessionID,
+			"request_id": requestID,
+			"tools":      tools.summary(),
+		}))
+		if err != nil {
+			slog.Error("gateway message encode failed", "request_id", requestID, "error", err)
+			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "failed to encode response")
+			return
+		}
+		replayBody = body
+		writeRawJSON(w, http.StatusOK, body)
+		slog.Info("gateway message completed",
+			"request_id", requestID,
+			"channel", channel,
+			"session_id", sessionID,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	}
+}
+
+// toolCallRecorder 按完成顺序记录工具调用；同名工具并发执行时按开始顺序配对计时。
+type toolCallRecorder struct {
+	mu      sync.Mutex
+	pending map[string][]time.Time
+	calls   []toolCallSummary
+}
+
+func (c *toolCallRecorder) start(name, _ string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil {
+		c.pending = make(map[string][]time.Time)
+	}
+	c.pending[name] = append(c.pending[name], time.Now())
+}
+
+func (c *toolCallRecorder) finish(name, _ string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call := toolCallSummary{Name: name, OK: err == nil}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	if starts := c.pending[name]; len(starts) > 0 {
+		call.DurationMs = time.Since(starts[0]).Milliseconds()
+		c.pending[name] = starts[1:]
+	}
+	c.calls = append(c.calls, call)
+}
+
+func (c *toolCallRecorder) summary() []toolCallSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]toolCallSummary{}, c.calls...)
+}
@@ -0,0 +1,266 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/bus"
+)
+
+// messageProcessor records the routing and request id it was called with and
+// reports one tool call through the observer in ctx.
+type messageProcessor struct {
+	toolErr       error
+	err           error
+	gotChannel    string
+	gotChatID     string
+	gotSender     string
+	gotSession    string
+	gotRequestID  string
+	gotUnverified bool
+}
+
+func (p *messageProcessor) ProcessForChannel(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+	return p.ProcessForChannelWithSession(ctx, channel, chatID, senderID, "", content)
+}
+
+func (p *messageProcessor) ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error) {
+	p.gotChannel, p.gotChatID, p.gotSender, p.gotSession = channel, chatID, senderID, sessionID
+	p.gotRequestID = bus.RequestIDFromContext(ctx)
+	p.gotUnverified = bus.SenderUnverified(ctx)
+	if observer := bus.StreamObserverFromContext(ctx); observer != nil {
+		observer.OnToolStart("exec", `{"command":"ls"}`)
+		observer.OnToolFinish("exec", "ok", p.toolErr)
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	return "done", nil
+}
+
+func TestMessageRejectsContentOverSizeLimit(t *testing.T) {
+	p := &messageProcessor{err: &bus.MessageTooLongError{Length: 12, Limit: 5}}
+	req := httptest.NewRequest(http.MethodPost, "/message", bytes.NewBufferString(`{"channel":"gateway","chat_id":"c1","content":"hello world!"}`))
+	rr := httptest.NewRecorder()
+	NewHandler("", p).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body["code"] != "message_too_long" {
+		t.Fatalf("unexpected error body: %v", body)
+	}
+}
+
+func TestMessageReturnsResponseAndToolSummary(t *testing.T) {
+	p := &messageProcessor{}
+	h := NewHandler("secret", p)
+	req := httptest.NewRequest(http.MethodPost, "/message", bytes.NewBufferString(`{"channel":"telegram","chat_id":"42","sender_id":"u1","content":"hi"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Response  string            `json:"response"`
+		SessionID string            `json:"session_id"`
+		Tools     []toolCallSummary `json:"tools"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Response != "done" || body.SessionID != "telegram:42" {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+	if len(body.Tools) != 1 || body.Tools[0].Name != "exec" || !body.Tools[0].OK {
+		t.Fatalf("unexpected tool summary: %+v", body.Tools)
+	}
+	if p.gotChannel != "telegram" || p.gotChatID != "42" || p.gotSender != "u1" || p.gotSession != "" {
+		t.Fatalf("unexpected routing: %+v", p)
+	}
+	if !p.gotUnverified {
+		t.Fatal("expected gateway-supplied sender to be marked unverified")
+	}
+}
+
+func TestMessagePropagatesRequestIDAndToolErrors(t *testing.T) {
+	p := &messageProcessor{toolErr: errors.New("exit status 1")}
+	req := httptest.NewRequest(http.MethodPost, "/message", bytes.NewBufferString(`{"channel":"gateway","chat_id":"c1","content":"run"}`))
+	req.Header.Set("X-Request-ID", "req-123")
+	rr := httptest.NewRecorder()
+	NewHandler("", p).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if p.gotRequestID != "req-123" || p.gotSender != "api" {
+		t.Fatalf("expected request id and default sender, got %q %q", p.gotRequestID, p.gotSender)
+	}
+	var body struct {
+		RequestID string            `json:"request_id"`
+		Tools     []toolCallSummary `json:"tools"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.RequestID != "req-123" || len(body.Tools) != 1 || body.Tools[0].OK || body.Tools[0].Error != "exit status 1" {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+func TestMessageValidation(t *testing.T) {
+	h := NewHandler("secret", &mockChatProcessor{resp: "ok"})
+	cases := []struct {
+		name   string
+		method string
+		auth   string
+		body   string
+		want   int
+	}{
+		{"method", http.MethodGet, "Bearer secret", "", http.StatusMethodNotAllowed},
+		{"token", http.MethodPost, "", `{"channel":"cli","chat_id":"1","content":"hi"}`, http.StatusUnauthorized},
+		{"channel", http.MethodPost, "Bearer secret", `{"chat_id":"1","content":"hi"}`, http.StatusBadRequest},
+		{"chat_id", http.MethodPost, "Bearer secret", `{"channel":"cli","content":"hi"}`, http.StatusBadRequest},
+		{"content", http.MethodPost, "Bearer secret", `{"channel":"cli","chat_id":"1"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/message", bytes.NewBufferString(tc.body))
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != tc.want {
+			t.Fatalf("%s: expected status %d, got %d", tc.name, tc.want, rr.Code)
+		}
+	}
+}
+
+func TestMessageWithoutTokenOnlyRoutesToGatewayChannel(t *testing.T) {
+	p := &messageProcessor{}
+	h := NewHandler("", p)
+	req := httptest.NewRequest(http.MethodPost, "/message", bytes.NewBufferString(`{"channel":"telegram","chat_id":"42","sender_id":"admin","content":"approve 1"}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if p.gotChannel != "" {
+		t.Fatalf("expected the request not to be processed, got %+v", p)
+	}
+}
+
+// countingProcessor counts calls and fails while err is set.
+type countingProcessor struct {
+	calls int
+	err   error
+}
+
+func (p *countingProcessor) ProcessForChannel(ctx context.Context, channel, chatID, senderID, content string) (string, error) {
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	return fmt.Sprintf("reply %d", p.calls), nil
+}
+
+func postMessage(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/message", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMessageIdempotencyKeyReplaysResponse(t *testing.T) {
+	p := &countingProcessor{}
+	h := NewHandler("", p)
+	body := `{"channel":"gateway","chat_id":"c1","content":"charge card"}`
+
+	first := postMessage(h, "key-1", body)
+	if first.Code != http.StatusOK || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("unexpected first response: %d %v", first.Code, first.Header())
+	}
+	second := postMessage(h, "key-1", body)
+	if second.Code != http.StatusOK || second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected replayed response, got %d %v", second.Code, second.Header())
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("expected identical body, got %q and %q", first.Body.String(), second.Body.String())
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected one processed request, got %d", p.calls)
+	}
+
+	if rr := postMessage(h, "key-2", body); rr.Code != http.StatusOK || p.calls != 2 {
+		t.Fatalf("expected a new key to be processed, got %d after %d calls", rr.Code, p.calls)
+	}
+	if rr := postMessage(h, "", body); rr.Code != http.StatusOK || p.calls != 3 {
+		t.Fatalf("expected requests without a key to be processed, got %d after %d calls", rr.Code, p.calls)
+	}
+}
+
+func TestMessageIdempotencyKeyRejectsDifferentRequest(t *testing.T) {
+	p := &countingProcessor{}
+	h := NewHandler("", p)
+	postMessage(h, "key-1", `{"channel":"gateway","chat_id":"c1","content":"first"}`)
+
+	rr := postMessage(h, "key-1", `{"channel":"gateway","chat_id":"c1","content":"second"}`)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a reused key, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected the reused key not to be processed, got %d calls", p.calls)
+	}
+}
+
+func TestMessageIdempotencyKeyRetriesAfterFailure(t *testing.T) {
+	p := &countingProcessor{err: errors.New("model unavailable")}
+	h := NewHandler("", p)
+	body := `{"channel":"gateway","chat_id":"c1","content":"hi"}`
+
+	if rr := postMessage(h, "key-1", body); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("expected failure, got %d", rr.Code)
+	}
+	p.err = nil
+	rr := postMessage(h, "key-1", body)
+	if rr.Code != http.StatusOK || rr.Header().Get(IdempotentReplayedHeader) != "" || p.calls != 2 {
+		t.Fatalf("expected a failed request to be reprocessed, got %d %v after %d calls", rr.Code, rr.Header(), p.calls)
+	}
+}
+
+func TestIdempotencyCacheExpiresEntries(t *testing.T) {
+	cache := newIdempotencyCache(time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	entry, owner := cache.begin("k", "fp")
+	if !owner {
+		t.Fatal("expected first caller to own the key")
+	}
+	cache.finish(entry, []byte(`{}`))
+	if _, owner := cache.begin("k", "fp"); owner {
+		t.Fatal("expected cached entry within the ttl")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, owner := cache.begin("k", "fp"); !owner {
+		t.Fatal("expected expired entry to be dropped")
+	}
+}
@@ -2,9 +2,11 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/MEKXH/golem/internal/bus"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/metrics"
 	"github.com/MEKXH/golem/internal/version"
 	"github.com/google/uuid"
 )
@@ -24,15 +27,61 @@ type ChatProcessor interface {
 	ProcessForChannel(ctx context.Context, channel, chatID, senderID, content string) (string, error)
 }
 
+// SessionChatProcessor 是支持显式会话 ID 的 ChatProcessor；
+// 处理器实现该接口时，/chat 请求可同时指定 chat_id 与 session_id。
+type SessionChatProcessor interface {
+	ProcessForChannelWithSession(ctx context.Context, channel, chatID, senderID, sessionID, content string) (string, error)
+}
+
+// ReloadFunc 重新加载配置并返回本次重载的结果摘要。
+type ReloadFunc func(ctx context.Context) (any, error)
+
+// StatusFunc 返回可直接序列化为 JSON 的运行时状态快照。
+type StatusFunc func() any
+
+// MCPReconnectFunc 重连指定 MCP 服务器（all 为 true 时重连所有降级服务器），返回重连后的状态列表。
+type MCPReconnectFunc func(ctx context.Context, server string, all bool) (any, error)
+
+// ComponentStatus 是 /readyz 中单个组件的就绪状态。
+type ComponentStatus struct {
+	Name    string `json:"name"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
+// ReadinessFunc 返回各组件的就绪状态；全部就绪时 /readyz 返回 200，否则返回 503。
+type ReadinessFunc func() []ComponentStatus
+
+// MetricsFunc 以 Prometheus 文本格式写出运行时指标。
+type MetricsFunc func(w io.Writer) error
+
+// HandlerOptions 描述网关可选启用的管理类接口。
+type HandlerOptions struct {
+	Ready        ReadinessFunc    // 就绪检查回调，为空时 /readyz 只反映进程存活
+	Reload       ReloadFunc       // 配置热重载回调，为空时不注册 /reload
+	MCPStatus    StatusFunc       // MCP 服务器状态回调，为空时不注册 /mcp/status
+	MCPReconnect MCPReconnectFunc // MCP 手动重连回调，为空时不注册 /mcp/reconnect
+	MCPLogs      StatusFunc       // stdio MCP 服务器 stderr 尾部回调，为空时不注册 /mcp/logs
+	ToolStatus   StatusFunc       // 工具熔断状态回调，为空时不注册 /tools/status
+	SkillsReload ReloadFunc       // 技能重新加载回调，为空时不注册 /skills/reload
+	Metrics      MetricsFunc      // Prometheus 指标输出回调，为空时不注册 /metrics
+}
+
 // Server 表示网关服务器实例。
 type Server struct {
 	cfg        config.GatewayConfig // 网关配置
 	processor  ChatProcessor        // 聊天处理器
+	opts       HandlerOptions       // 可选的管理类接口
 	httpServer *http.Server         // 底层 HTTP 服务器
 }
 
 // New 创建并返回一个新的网关服务器实例。
 func New(cfg config.GatewayConfig, processor ChatProcessor) *Server {
+	return NewWithOptions(cfg, processor, HandlerOptions{})
+}
+
+// NewWithOptions 创建一个启用了额外管理接口的网关服务器实例。
+func NewWithOptions(cfg config.GatewayConfig, processor ChatProcessor, opts HandlerOptions) *Server {
 	host := strings.TrimSpace(cfg.Host)
 	if host == "" {
 		host = "0.0.0.0"
@@ -47,6 +96,7 @@ func New(cfg config.GatewayConfig, processor ChatProcessor) *Server {
 	return &Server{
 		cfg:       cfg,
 		processor: processor,
+		opts:      opts,
 	}
 }
 
@@ -57,7 +107,7 @@ func (s *Server) Addr() string {
 
 // Start 启动网关服务器并开始监听请求。
 func (s *Server) Start() error {
-	mux := NewHandler(s.cfg.Token, s.processor)
+	mux := NewHandlerWithOptions(s.cfg.Token, s.processor, s.opts)
 	s.httpServer = &http.Server{
 		Addr:              s.Addr(),
 		Handler:           mux,
@@ -80,6 +130,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // NewHandler 创建并配置网关的路由处理器。
 func NewHandler(token string, processor ChatProcessor) http.Handler {
+	return NewHandlerWithOptions(token, processor, HandlerOptions{})
+}
+
+// NewHandlerWithOptions 创建网关路由处理器，并按选项注册管理类接口。
+func NewHandlerWithOptions(token string, processor ChatProcessor, opts HandlerOptions) http.Handler {
 	mux := http.NewServeMux()
 	webUI, webUIErr := webUIFS()
 
@@ -96,86 +151,239 @@ func NewHandler(token string, processor ChatProcessor) http.Handler {
 		})
 	})
 
-	// 版本查询接口
-	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+	// 存活探针：进程能响应请求即视为存活
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		requestID := getRequestID(r)
-		if r.Method != http.MethodGet {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
 			writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
 			return
 		}
 		writeJSON(w, http.StatusOK, map[string]any{
-			"version":    version.Version,
+			"status":     "ok",
 			"request_id": requestID,
 		})
 	})
 
-	// 聊天交互接口
-	mux.HandleFunc("/chat", func(w http.ResponseWriter, r *http.Request) {
+	// 就绪探针：汇总模型、Agent 主循环与通道的状态
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
 		requestID := getRequestID(r)
-		start := time.Now()
-		if r.Method != http.MethodPost {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
 			writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
 			return
 		}
-		if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
-			writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
-			return
-		}
-
-		var req struct {
-			Message   string `json:"message"`
-			SessionID string `json:"session_id"`
-			SenderID  string `json:"sender_id"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeError(w, requestID, http.StatusBadRequest, "bad_request", "invalid json request")
-			return
-		}
-		msg := strings.TrimSpace(req.Message)
-		if msg == "" {
-			writeError(w, requestID, http.StatusBadRequest, "bad_request", "message is required")
-			return
-		}
-		sessionID := strings.TrimSpace(req.SessionID)
-		if sessionID == "" {
-			sessionID = "default"
+		components := []ComponentStatus{}
+		if opts.Ready != nil {
+			components = opts.Ready()
 		}
-		senderID := strings.TrimSpace(req.SenderID)
-		if senderID == "" {
-			senderID = "api"
-		}
-		slog.Info("gateway chat request",
-			"request_id", requestID,
-			"channel", "gateway",
-			"session_id", sessionID,
-			"sender_id", senderID,
-		)
-
-		if processor == nil {
-			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "chat processor is not configured")
-			return
+		status, code := "ready", http.StatusOK
+		for _, c := range components {
+			if !c.Ready {
+				status, code = "not_ready", http.StatusServiceUnavailable
+				break
+			}
 		}
+		writeJSON(w, code, map[string]any{
+			"status":     status,
+			"components": components,
+			"request_id": requestID,
+		})
+	})
 
-		procCtx := bus.WithRequestID(r.Context(), requestID)
-		resp, err := processor.ProcessForChannel(procCtx, "gateway", sessionID, senderID, msg)
-		if err != nil {
-			slog.Error("gateway chat failed", "request_id", requestID, "channel", "gateway", "session_id", sessionID, "error", err)
-			writeError(w, requestID, http.StatusInternalServerError, "internal_error", "failed to process chat request")
+	// 版本查询接口
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		requestID := getRequestID(r)
+		if r.Method != http.MethodGet {
+			writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
 			return
 		}
-		slog.Info("gateway chat completed",
-			"request_id", requestID,
-			"channel", "gateway",
-			"session_id", sessionID,
-			"duration_ms", time.Since(start).Milliseconds(),
-		)
 		writeJSON(w, http.StatusOK, map[string]any{
-			"response":   resp,
-			"session_id": sessionID,
+			"version":    version.Version,
 			"request_id": requestID,
 		})
 	})
 
+	// 聊天交互接口，支持 JSON 与 SSE 流式两种响应
+	mux.HandleFunc("/chat", chatHandler(token, processor))
+
+	// 消息注入接口，同步返回最终回复与工具调用摘要
+	mux.HandleFunc("/message", messageHandler(token, processor, newIdempotencyCache(defaultIdempotencyTTL)))
+
+	// 配置热重载接口
+	if opts.Reload != nil {
+		mux.HandleFunc("/reload", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodPost {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+
+			result, err := opts.Reload(bus.WithRequestID(r.Context(), requestID))
+			if err != nil {
+				slog.Error("gateway config reload failed", "request_id", requestID, "error", err)
+				writeError(w, requestID, http.StatusUnprocessableEntity, "reload_failed", err.Error())
+				return
+			}
+			slog.Info("gateway config reloaded", "request_id", requestID)
+			writeJSON(w, http.StatusOK, map[string]any{
+				"result":     result,
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// MCP 服务器状态接口
+	if opts.MCPStatus != nil {
+		mux.HandleFunc("/mcp/status", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodGet {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"servers":    opts.MCPStatus(),
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// MCP 服务器 stderr 日志接口
+	if opts.MCPLogs != nil {
+		mux.HandleFunc("/mcp/logs", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodGet {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"servers":    opts.MCPLogs(),
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// 工具熔断状态接口
+	if opts.ToolStatus != nil {
+		mux.HandleFunc("/tools/status", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodGet {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"breakers":   opts.ToolStatus(),
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// MCP 手动重连接口
+	if opts.MCPReconnect != nil {
+		mux.HandleFunc("/mcp/reconnect", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodPost {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+
+			var req struct {
+				Server string `json:"server"`
+				All    bool   `json:"all"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, requestID, http.StatusBadRequest, "bad_request", "invalid json request")
+				return
+			}
+			server := strings.TrimSpace(req.Server)
+			if server == "" && !req.All {
+				writeError(w, requestID, http.StatusBadRequest, "bad_request", "server or all is required")
+				return
+			}
+
+			servers, err := opts.MCPReconnect(bus.WithRequestID(r.Context(), requestID), server, req.All)
+			if err != nil {
+				slog.Warn("gateway mcp reconnect failed", "request_id", requestID, "server", server, "error", err)
+				writeError(w, requestID, http.StatusUnprocessableEntity, "reconnect_failed", err.Error())
+				return
+			}
+			slog.Info("gateway mcp reconnect completed", "request_id", requestID, "server", server, "all", req.All)
+			writeJSON(w, http.StatusOK, map[string]any{
+				"servers":    servers,
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// 技能重新加载接口
+	if opts.SkillsReload != nil {
+		mux.HandleFunc("/skills/reload", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodPost {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+
+			skills, err := opts.SkillsReload(bus.WithRequestID(r.Context(), requestID))
+			if err != nil {
+				slog.Warn("gateway skills reload failed", "request_id", requestID, "error", err)
+				writeError(w, requestID, http.StatusUnprocessableEntity, "reload_failed", err.Error())
+				return
+			}
+			slog.Info("gateway skills reloaded", "request_id", requestID)
+			writeJSON(w, http.StatusOK, map[string]any{
+				"skills":     skills,
+				"request_id": requestID,
+			})
+		})
+	}
+
+	// Prometheus 指标抓取接口
+	if opts.Metrics != nil {
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			requestID := getRequestID(r)
+			if r.Method != http.MethodGet {
+				writeError(w, requestID, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+				return
+			}
+			if strings.TrimSpace(token) != "" && !isAuthorized(r, token) {
+				writeError(w, requestID, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+				return
+			}
+			var buf bytes.Buffer
+			if err := opts.Metrics(&buf); err != nil {
+				slog.Error("gateway metrics export failed", "request_id", requestID, "error", err)
+				writeError(w, requestID, http.StatusInternalServerError, "internal_error", "failed to export metrics")
+				return
+			}
+			w.Header().Set("Content-Type", metrics.PrometheusContentType)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(buf.Bytes())
+		})
+	}
+
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		serveWebUI(w, r, webUI, webUIErr)
 	})
@@ -248,3 +456,19 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
+
+// encodeJSON 以与 writeJSON 相同的格式编码 v，供需要保留回复原文的场景使用。
+func encodeJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeRawJSON 写出已编码的 JSON 回复。
+func writeRawJSON(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -58,6 +59,42 @@ func TestHealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestHealthzAndReadyzEndpoints(t *testing.T) {
+	ready := true
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		Ready: func() []ComponentStatus {
+			return []ComponentStatus{
+				{Name: "provider", Ready: true},
+				{Name: "channel:telegram", Ready: ready, Message: "start failed; see logs"},
+			}
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rr.Code != http.StatusOK || decodeJSON(t, rr.Body)["status"] != "ok" {
+		t.Fatalf("expected healthz ok without token, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusOK || decodeJSON(t, rr.Body)["status"] != "ready" {
+		t.Fatalf("expected readyz ready, got %d", rr.Code)
+	}
+
+	ready = false
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readyz 503, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	components, _ := body["components"].([]any)
+	if body["status"] != "not_ready" || len(components) != 2 {
+		t.Fatalf("unexpected readyz body: %v", body)
+	}
+}
+
 func TestVersionEndpoint(t *testing.T) {
 	h := NewHandler("", &mockChatProcessor{})
 	req := httptest.NewRequest(http.MethodGet, "/version", nil)
@@ -180,3 +217,263 @@ func TestChatInternalError(t *testing.T) {
 		t.Fatalf("expected code=internal_error, got %v", body["code"])
 	}
 }
+
+func TestReloadRequiresTokenAndReturnsResult(t *testing.T) {
+	calls := 0
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		Reload: func(ctx context.Context) (any, error) {
+			calls++
+			return map[string]any{"applied": []string{"log"}}, nil
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+	if calls != 0 {
+		t.Fatalf("expected reload not to run without token, got %d calls", calls)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reload", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	if _, ok := body["result"].(map[string]any); !ok {
+		t.Fatalf("expected result object, got %v", body["result"])
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 reload call, got %d", calls)
+	}
+}
+
+func TestReloadFailureReturnsUnprocessable(t *testing.T) {
+	h := NewHandlerWithOptions("", &mockChatProcessor{}, HandlerOptions{
+		Reload: func(ctx context.Context) (any, error) {
+			return nil, errors.New("agents.defaults.max_tool_iterations must not be negative")
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/reload", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	if body["code"] != "reload_failed" {
+		t.Fatalf("expected code=reload_failed, got %v", body["code"])
+	}
+}
+
+func TestMCPStatusReturnsServers(t *testing.T) {
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		MCPStatus: func() any {
+			return []map[string]any{{"name": "localfs", "degraded": true}}
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/status", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp/status", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	servers, ok := body["servers"].([]any)
+	if !ok || len(servers) != 1 {
+		t.Fatalf("expected one server entry, got %v", body["servers"])
+	}
+}
+
+func TestMCPStatusNotRegisteredWithoutCallback(t *testing.T) {
+	h := NewHandler("", &mockChatProcessor{})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/status", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code == http.StatusOK && strings.Contains(rr.Body.String(), `"servers"`) {
+		t.Fatalf("expected /mcp/status to be unavailable without callback, got %s", rr.Body.String())
+	}
+}
+
+func TestToolStatusReturnsBreakers(t *testing.T) {
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		ToolStatus: func() any {
+			return []map[string]any{{"tool": "exec", "state": "open", "consecutive_failures": 5}}
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tools/status", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/tools/status", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	breakers, ok := body["breakers"].([]any)
+	if !ok || len(breakers) != 1 {
+		t.Fatalf("expected one breaker entry, got %v", body["breakers"])
+	}
+}
+
+func TestMCPLogsReturnsStderrTails(t *testing.T) {
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		MCPLogs: func() any {
+			return []map[string]any{{"server": "localfs", "stderr": "ready\n", "live": true}}
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/logs", nil)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp/logs", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	servers, ok := body["servers"].([]any)
+	if !ok || len(servers) != 1 {
+		t.Fatalf("expected one server log entry, got %v", body["servers"])
+	}
+}
+
+func TestMCPReconnectValidatesRequestAndReturnsServers(t *testing.T) {
+	var gotServer string
+	var gotAll bool
+	h := NewHandlerWithOptions("", &mockChatProcessor{}, HandlerOptions{
+		MCPReconnect: func(ctx context.Context, server string, all bool) (any, error) {
+			gotServer, gotAll = server, all
+			if server == "broken" {
+				return nil, errors.New("reconnect failed after 3 attempts")
+			}
+			return []map[string]any{{"name": server, "connected": true}}, nil
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp/reconnect", strings.NewReader(`{}`))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without server or all, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp/reconnect", strings.NewReader(`{"server":"localfs"}`))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	if gotServer != "localfs" || gotAll {
+		t.Fatalf("unexpected reconnect args: server=%q all=%v", gotServer, gotAll)
+	}
+	body := decodeJSON(t, rr.Body)
+	if servers, ok := body["servers"].([]any); !ok || len(servers) != 1 {
+		t.Fatalf("expected one server entry, got %v", body["servers"])
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp/reconnect", strings.NewReader(`{"server":"broken"}`))
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d", rr.Code)
+	}
+	if body := decodeJSON(t, rr.Body); body["code"] != "reconnect_failed" {
+		t.Fatalf("expected code=reconnect_failed, got %v", body["code"])
+	}
+}
+
+func TestSkillsReloadRequiresPostAndTokenAndReturnsSkills(t *testing.T) {
+	calls := 0
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		SkillsReload: func(ctx context.Context) (any, error) {
+			calls++
+			return []map[string]any{{"name": "weather", "source": "workspace"}}, nil
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/skills/reload", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for GET, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/skills/reload", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/skills/reload", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	body := decodeJSON(t, rr.Body)
+	if list, ok := body["skills"].([]any); !ok || len(list) != 1 {
+		t.Fatalf("expected one skill entry, got %v", body["skills"])
+	}
+	if calls != 1 {
+		t.Fatalf("expected reload callback once, got %d", calls)
+	}
+}
+
+func TestMetricsEndpointRequiresTokenAndServesPrometheusText(t *testing.T) {
+	h := NewHandlerWithOptions("secret", &mockChatProcessor{}, HandlerOptions{
+		Metrics: func(w io.Writer) error {
+			_, err := io.WriteString(w, "golem_tool_executions_total 3\n")
+			return err
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if rr.Body.String() != "golem_tool_executions_total 3\n" {
+		t.Fatalf("unexpected body %q", rr.Body.String())
+	}
+}
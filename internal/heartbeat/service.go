@@ -55,14 +55,8 @@ type Service struct {
 
 // NewService 创建并初始化一个新的心跳服务。
 func NewService(cfg Config, probe ProbeFunc, dispatch DispatchFunc, stateMgr *appstate.Manager) *Service {
-	if cfg.Interval <= 0 {
-		cfg.Interval = defaultInterval
-	}
-	if cfg.MaxIdle <= 0 {
-		cfg.MaxIdle = defaultMaxIdle
-	}
 	svc := &Service{
-		cfg:      cfg,
+		cfg:      normalizeConfig(cfg),
 		probe:    probe,
 		dispatch: dispatch,
 		state:    stateMgr,
@@ -126,7 +120,7 @@ func (s *Service) Start() error {
 	s.stopped = make(chan struct{})
 	s.running = true
 
-	go s.loop(s.stopCh, s.stopped)
+	go s.loop(s.cfg.Interval, s.stopCh, s.stopped)
 	slog.Info("heartbeat service started", "interval", s.cfg.Interval.String(), "max_idle", s.cfg.MaxIdle.String())
 	return nil
 }
@@ -150,10 +144,37 @@ func (s *Service) Stop() {
 	slog.Info("heartbeat service stopped")
 }
 
-func (s *Service) loop(stopCh <-chan struct{}, stopped chan<- struct{}) {
+// UpdateConfig 在运行时替换心跳配置，并按新配置重启周期性循环（禁用时保持停止）。
+func (s *Service) UpdateConfig(cfg Config) error {
+	s.Stop()
+
+	s.mu.Lock()
+	s.cfg = normalizeConfig(cfg)
+	s.mu.Unlock()
+
+	return s.Start()
+}
+
+func (s *Service) config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+func normalizeConfig(cfg Config) Config {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.MaxIdle <= 0 {
+		cfg.MaxIdle = defaultMaxIdle
+	}
+	return cfg
+}
+
+func (s *Service) loop(interval time.Duration, stopCh <-chan struct{}, stopped chan<- struct{}) {
 	defer close(stopped)
 
-	ticker := time.NewTicker(s.cfg.Interval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -170,7 +191,8 @@ func (s *Service) loop(stopCh <-chan struct{}, stopped chan<- struct{}) {
 
 // RunOnce 执行单次心跳探测并分发结果。
 func (s *Service) RunOnce(ctx context.Context) error {
-	if !s.cfg.Enabled {
+	cfg := s.config()
+	if !cfg.Enabled {
 		return nil
 	}
 
@@ -180,8 +202,8 @@ func (s *Service) RunOnce(ctx context.Context) error {
 	}
 
 	// 检查会话是否已长时间处于非活跃状态
-	if s.cfg.MaxIdle > 0 && s.now().Sub(target.seenAt) > s.cfg.MaxIdle {
-		slog.Debug("heartbeat skipped stale session", "channel", target.channel, "chat_id", target.chatID, "max_idle", s.cfg.MaxIdle.String())
+	if cfg.MaxIdle > 0 && s.now().Sub(target.seenAt) > cfg.MaxIdle {
+		slog.Debug("heartbeat skipped stale session", "channel", target.channel, "chat_id", target.chatID, "max_idle", cfg.MaxIdle.String())
 		return nil
 	}
 
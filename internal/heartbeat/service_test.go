@@ -218,3 +218,48 @@ func TestTrackActivity_PersistsState(t *testing.T) {
 		t.Fatal("expected non-zero seen_at")
 	}
 }
+
+func TestUpdateConfig_RestartsWithNewSettings(t *testing.T) {
+	var callCount atomic.Int32
+
+	svc := NewService(
+		Config{Enabled: false},
+		nil,
+		func(ctx context.Context, channel, chatID, content, requestID string) error {
+			callCount.Add(1)
+			return nil
+		},
+		nil,
+	)
+	svc.TrackActivity("telegram", "123456")
+
+	if err := svc.Start(); err != nil {
+		t.Fatalf("Start error: %v", err)
+	}
+	if svc.IsRunning() {
+		t.Fatal("expected disabled heartbeat to stay stopped")
+	}
+
+	if err := svc.UpdateConfig(Config{Enabled: true, Interval: 20 * time.Millisecond, MaxIdle: time.Hour}); err != nil {
+		t.Fatalf("UpdateConfig error: %v", err)
+	}
+	defer svc.Stop()
+	if !svc.IsRunning() {
+		t.Fatal("expected heartbeat to start after enabling")
+	}
+
+	deadline := time.Now().Add(600 * time.Millisecond)
+	for callCount.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if callCount.Load() == 0 {
+		t.Fatal("expected periodic heartbeat dispatches after update")
+	}
+
+	if err := svc.UpdateConfig(Config{Enabled: false}); err != nil {
+		t.Fatalf("UpdateConfig error: %v", err)
+	}
+	if svc.IsRunning() {
+		t.Fatal("expected heartbeat to stop after disabling")
+	}
+}
@@ -0,0 +1,118 @@
+// Package httpclient 提供出站 HTTP 请求共用的客户端工厂，超时与代理来自配置的 http 配置块。
+// 代理设置安装在 http.DefaultTransport 与 websocket.DefaultDialer 上，因此未单独指定 Transport 的
+// 第三方 SDK（各聊天平台、模型提供商）同样经由配置的代理访问外网。
+package httpclient
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/gorilla/websocket"
+	"golang.org/x/net/http/httpproxy"
+)
+
+var (
+	mu      sync.RWMutex
+	timeout time.Duration                    // http.timeout_seconds，0 表示未配置
+	proxy   *httpproxy.Config                // 当前生效的代理设置，nil 表示沿用环境变量
+	proxyFn func(*url.URL) (*url.URL, error) // 由 proxy 生成的代理选择函数
+	install sync.Once                        // 只在首次 Configure 时替换默认 Transport 与 Dialer 的代理函数
+	envFunc = httpproxy.FromEnvironment      // 读取代理环境变量，测试中可替换
+)
+
+// Configure 应用 http 配置块，加载配置后调用。代理设置对之后的所有请求生效，
+// 超时只影响之后通过 Timeout 取得默认值的客户端。
+func Configure(cfg config.HTTPConfig) {
+	p := envFunc()
+	if v := strings.TrimSpace(cfg.Proxy); v != "" {
+		p.HTTPProxy = v
+		p.HTTPSProxy = v
+	}
+	if v := strings.TrimSpace(cfg.NoProxy); v != "" {
+		p.NoProxy = v
+	}
+
+	mu.Lock()
+	timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	proxy = p
+	proxyFn = p.ProxyFunc()
+	mu.Unlock()
+
+	install.Do(func() {
+		if t, ok := http.DefaultTransport.(*http.Transport); ok {
+			t.Proxy = Proxy
+		}
+		websocket.DefaultDialer.Proxy = Proxy
+	})
+}
+
+// Timeout 返回组件的默认请求超时：配置了 http.timeout_seconds 时使用该值，否则使用组件的内置默认值 fallback。
+// 组件自己有超时配置（如 tools.voice.timeout_seconds）时应直接使用该配置，不经过 Timeout。
+func Timeout(fallback time.Duration) time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	if timeout > 0 {
+		return timeout
+	}
+	return fallback
+}
+
+// New 创建使用默认 Transport 的客户端，请求经由 Proxy 选择代理；timeout 由调用方决定，
+// 通常为 Timeout(内置默认值)，需要固定时限的组件（如长轮询）可直接传入。
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// Proxy 按当前配置为请求选择代理，签名与 http.Transport.Proxy 相同；未调用 Configure 时读取环境变量。
+func Proxy(req *http.Request) (*url.URL, error) {
+	mu.RLock()
+	fn := proxyFn
+	mu.RUnlock()
+	if fn == nil {
+		return http.ProxyFromEnvironment(req)
+	}
+	return fn(req.URL)
+}
+
+// ProxyAddrs 返回当前配置的 HTTP 与 HTTPS 代理地址（host:port），供需要区分代理连接的拨号器使用。
+func ProxyAddrs() map[string]bool {
+	mu.RLock()
+	p := proxy
+	mu.RUnlock()
+	if p == nil {
+		p = envFunc()
+	}
+
+	addrs := make(map[string]bool)
+	for _, raw := range []string{p.HTTPProxy, p.HTTPSProxy} {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		port := u.Port()
+		if port == "" {
+			switch u.Scheme {
+			case "https":
+				port = "443"
+			case "socks5":
+				port = "1080"
+			default:
+				port = "80"
+			}
+		}
+		addrs[net.JoinHostPort(u.Hostname(), port)] = true
+	}
+	return addrs
+}
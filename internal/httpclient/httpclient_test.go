@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+func configureForTest(t *testing.T, env httpproxy.Config, cfg config.HTTPConfig) {
+	t.Helper()
+	prevEnv := envFunc
+	envFunc = func() *httpproxy.Config { c := env; return &c }
+	t.Cleanup(func() {
+		envFunc = prevEnv
+		mu.Lock()
+		timeout, proxy, proxyFn = 0, nil, nil
+		mu.Unlock()
+	})
+	Configure(cfg)
+}
+
+func proxyFor(t *testing.T, rawURL string) string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	u, err := Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy(%s): %v", rawURL, err)
+	}
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+func TestConfigure_ProxyOverridesEnvironment(t *testing.T) {
+	configureForTest(t,
+		httpproxy.Config{HTTPSProxy: "http://env-proxy:8080", NoProxy: "env.internal"},
+		config.HTTPConfig{Proxy: "http://corp-proxy:3128", NoProxy: "corp.internal,.svc"},
+	)
+
+	if got := proxyFor(t, "https://api.example.com/v1"); got != "http://corp-proxy:3128" {
+		t.Fatalf("expected configured proxy, got %q", got)
+	}
+	for _, target := range []string{"https://wiki.corp.internal/", "http://api.svc/", "http://localhost:18790/health"} {
+		if got := proxyFor(t, target); got != "" {
+			t.Fatalf("expected %s to bypass the proxy, got %q", target, got)
+		}
+	}
+	if got := proxyFor(t, "https://env.internal/"); got != "http://corp-proxy:3128" {
+		t.Fatalf("expected configured no_proxy to replace NO_PROXY, got %q", got)
+	}
+	if http.DefaultTransport.(*http.Transport).Proxy == nil {
+		t.Fatal("expected the default transport to use the configured proxy")
+	}
+
+	addrs := ProxyAddrs()
+	if len(addrs) != 1 || !addrs["corp-proxy:3128"] {
+		t.Fatalf("unexpected proxy addresses: %v", addrs)
+	}
+}
+
+func TestConfigure_FallsBackToEnvironment(t *testing.T) {
+	configureForTest(t, httpproxy.Config{HTTPSProxy: "socks5://env-proxy"}, config.HTTPConfig{})
+
+	if got := proxyFor(t, "https://api.example.com/"); got != "socks5://env-proxy" {
+		t.Fatalf("expected proxy from the environment, got %q", got)
+	}
+	if got := proxyFor(t, "http://api.example.com/"); got != "" {
+		t.Fatalf("expected plain HTTP to go direct without HTTP_PROXY, got %q", got)
+	}
+	if addrs := ProxyAddrs(); !addrs["env-proxy:1080"] {
+		t.Fatalf("expected the default socks5 port, got %v", addrs)
+	}
+}
+
+func TestTimeout_UsesConfiguredValueOverFallback(t *testing.T) {
+	configureForTest(t, httpproxy.Config{}, config.HTTPConfig{})
+	if got := Timeout(45 * time.Second); got != 45*time.Second {
+		t.Fatalf("expected built-in default without http.timeout_seconds, got %s", got)
+	}
+
+	Configure(config.HTTPConfig{TimeoutSeconds: 90})
+	if got := New(Timeout(45 * time.Second)).Timeout; got != 90*time.Second {
+		t.Fatalf("expected configured timeout, got %s", got)
+	}
+}
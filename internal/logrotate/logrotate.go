@@ -0,0 +1,288 @@
+// Package logrotate 实现按大小轮转、按数量/时间/总大小清理的日志文件，
+// 用于应用日志与审计日志，避免长期运行时磁盘占用无限增长。
+package logrotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// now 返回当前时间，测试中可替换。
+var now = time.Now
+
+// Options 定义轮转与保留策略，零值字段表示不限制。
+type Options struct {
+	MaxSize    int64         // 活动文件达到该字节数时轮转
+	MaxBackups int           // 最多保留的历史分段数
+	MaxAge     time.Duration // 历史分段的最长保留时间（按修改时间）
+	MaxTotal   int64         // 活动文件与历史分段的总字节数上限
+	Compress   bool          // 是否 gzip 压缩历史分段
+}
+
+// OptionsFromConfig 将配置中的轮转设置（MB/天）转换为 Options。
+func OptionsFromConfig(c config.LogRotationConfig) Options {
+	return Options{
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxBackups: c.MaxBackups,
+		MaxAge:     time.Duration(c.MaxAgeDays) * 24 * time.Hour,
+		MaxTotal:   int64(c.MaxTotalSizeMB) << 20,
+		Compress:   c.Compress,
+	}
+}
+
+// Backup 描述一个历史分段文件（<path>.N 或 <path>.N.gz，N 越小越新）。
+type Backup struct {
+	Path  string
+	Index int
+}
+
+// Backups 返回 path 的历史分段，按从新到旧排序。
+func Backups(path string) ([]Backup, error) {
+	matches, err := filepath.Glob(globEscape(path) + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var backups []Backup
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, path+"."), ".gz")
+		n, err := strconv.Atoi(suffix)
+		if err != nil || n < 1 {
+			continue
+		}
+		backups = append(backups, Backup{Path: m, Index: n})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Index < backups[j].Index })
+	return backups, nil
+}
+
+// Rotate 将 path 轮转为 <path>.1（可选压缩为 .1.gz），已有分段序号依次后移，
+// 随后按保留策略清理。path 不存在或为空时只执行清理。调用方需保证没有并发写入。
+func Rotate(path string, opts Options) error {
+	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+		backups, err := Backups(path)
+		if err != nil {
+			return err
+		}
+		for i := len(backups) - 1; i >= 0; i-- {
+			b := backups[i]
+			next := path + "." + strconv.Itoa(b.Index+1)
+			if strings.HasSuffix(b.Path, ".gz") {
+				next += ".gz"
+			}
+			if err := os.Rename(b.Path, next); err != nil {
+				return fmt.Errorf("shift log segment: %w", err)
+			}
+		}
+		first := path + ".1"
+		if err := os.Rename(path, first); err != nil {
+			return fmt.Errorf("rotate log file: %w", err)
+		}
+		if opts.Compress {
+			if err := compressFile(first); err != nil {
+				return err
+			}
+		}
+	} else if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return Prune(path, opts)
+}
+
+// Prune 按数量、保留时间与总大小上限删除最旧的历史分段；活动文件不会被删除。
+func Prune(path string, opts Options) error {
+	backups, err := Backups(path)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if info, err := os.Stat(path); err == nil {
+		total = info.Size()
+	}
+	cutoff := time.Time{}
+	if opts.MaxAge > 0 {
+		cutoff = now().Add(-opts.MaxAge)
+	}
+
+	for i, b := range backups {
+		info, err := os.Stat(b.Path)
+		if err != nil {
+			continue
+		}
+		expired := !cutoff.IsZero() && info.ModTime().Before(cutoff)
+		tooMany := opts.MaxBackups > 0 && i >= opts.MaxBackups
+		overCap := opts.MaxTotal > 0 && total+info.Size() > opts.MaxTotal
+		if expired || tooMany || overCap {
+			if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove old log segment: %w", err)
+			}
+			continue
+		}
+		total += info.Size()
+	}
+	return nil
+}
+
+// File 是按大小自动轮转的日志文件，可安全地被多个 goroutine 并发写入。
+type File struct {
+	mu   sync.Mutex
+	path string
+	opts Options
+	file *os.File
+	size int64
+}
+
+// Open 以追加方式打开（必要时创建）日志文件；已有文件超出大小上限时先轮转。
+func Open(path string, opts Options) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	f := &File{path: path, opts: opts}
+	if err := f.openLocked(); err != nil {
+		return nil, err
+	}
+	if opts.MaxSize > 0 && f.size >= opts.MaxSize {
+		if err := f.rotateLocked(); err != nil {
+			_ = f.file.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Name 返回活动日志文件路径。
+func (f *File) Name() string { return f.path }
+
+// Write 追加写入；写入后将超出大小上限时先轮转，单条记录不会被拆分到两个文件。
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize {
+		if err := f.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Sync 将活动文件刷入磁盘。
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close 关闭活动文件。
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *File) openLocked() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *File) rotateLocked() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := Rotate(f.path, f.opts); err != nil {
+		// 轮转失败时继续写入原文件，避免丢日志
+		if openErr := f.openLocked(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return f.openLocked()
+}
+
+// compressFile 将 path 压缩为 path.gz 并删除原文件，保留原修改时间以便按时间清理。
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstPath := path + ".gz"
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("create compressed log segment: %w", err)
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = zw.Close()
+		_ = dst.Close()
+		_ = os.Remove(dstPath)
+		return fmt.Errorf("compress log segment: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		_ = os.Remove(dstPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	_ = os.Chtimes(dstPath, info.ModTime(), info.ModTime())
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// globEscape 转义路径中的 glob 元字符。
+func globEscape(path string) string {
+	var sb strings.Builder
+	for _, r := range path {
+		switch r {
+		case '*', '?', '[', '\\':
+			if r == '\\' && filepath.Separator == '\\' {
+				sb.WriteRune(r)
+				continue
+			}
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,176 @@
+package logrotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("gzip reader %s: %v", path, err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestFile_RotatesBySizeAndCompressesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golem.log")
+	f, err := Open(path, Options{MaxSize: 20, MaxBackups: 5, Compress: true})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	for _, line := range []string{"first line 1\n", "second line\n", "third line\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatalf("Backups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups, got %+v", backups)
+	}
+	if backups[0].Path != path+".1.gz" || backups[1].Path != path+".2.gz" {
+		t.Fatalf("unexpected backup names: %+v", backups)
+	}
+	if got := readAll(t, backups[1].Path); got != "first line 1\n" {
+		t.Fatalf("expected oldest segment to hold the first line, got %q", got)
+	}
+	if got := readAll(t, backups[0].Path); got != "second line\n" {
+		t.Fatalf("expected newest backup to hold the second line, got %q", got)
+	}
+	if got := readAll(t, path); got != "third line\n" {
+		t.Fatalf("expected active file to hold the last line, got %q", got)
+	}
+}
+
+func TestFile_RotatesOversizedFileOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golem.log")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 64)), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	f, err := Open(path, Options{MaxSize: 32})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected oversized file to be rotated to .1: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Fatalf("expected a fresh active file, got size %d", info.Size())
+	}
+}
+
+func TestPrune_EnforcesBackupCountAgeAndTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	write := func(name string, size int, age time.Duration) {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(strings.Repeat("x", size)), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		mt := time.Now().Add(-age)
+		_ = os.Chtimes(p, mt, mt)
+	}
+
+	write("audit.jsonl", 10, 0)
+	write("audit.jsonl.1", 10, time.Hour)
+	write("audit.jsonl.2.gz", 10, 2*time.Hour)
+	write("audit.jsonl.3", 10, 3*time.Hour)
+	write("audit.jsonl.4", 10, 72*time.Hour)
+
+	// 总大小上限 35 字节：活动文件 + .1 + .2.gz 之后再加 .3 会超限
+	if err := Prune(path, Options{MaxTotal: 35}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	backups, _ := Backups(path)
+	if len(backups) != 2 {
+		t.Fatalf("expected total size cap to keep 2 backups, got %+v", backups)
+	}
+
+	write("audit.jsonl.3", 10, 3*time.Hour)
+	write("audit.jsonl.4", 10, 72*time.Hour)
+	if err := Prune(path, Options{MaxAge: 48 * time.Hour}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "audit.jsonl.4")); !os.IsNotExist(err) {
+		t.Fatalf("expected segment older than max age to be removed, stat err=%v", err)
+	}
+
+	if err := Prune(path, Options{MaxBackups: 1}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	backups, _ = Backups(path)
+	if len(backups) != 1 || backups[0].Index != 1 {
+		t.Fatalf("expected only the newest backup to remain, got %+v", backups)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected active file to be kept: %v", err)
+	}
+}
+
+func TestFile_ConcurrentWritesAreNotLost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golem.log")
+	f, err := Open(path, Options{MaxSize: 256, MaxBackups: 1000})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	const writers, lines = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				if _, err := fmt.Fprintf(f, "writer=%d line=%d\n", w, i); err != nil {
+					t.Errorf("Write: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	_ = f.Close()
+
+	backups, _ := Backups(path)
+	total := strings.Count(readAll(t, path), "\n")
+	for _, b := range backups {
+		content := readAll(t, b.Path)
+		if len(content) > 256 {
+			t.Fatalf("segment %s exceeds max size: %d bytes", b.Path, len(content))
+		}
+		total += strings.Count(content, "\n")
+	}
+	if total != writers*lines {
+		t.Fatalf("expected %d lines across segments, got %d", writers*lines, total)
+	}
+}
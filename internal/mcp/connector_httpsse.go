@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
 )
 
 type httpSSEConnector struct {
@@ -53,13 +54,12 @@ func isRetryable(err error) bool {
 
 func newHTTPSSEConnector() Connector {
 	return httpSSEConnector{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client: httpclient.New(httpclient.Timeout(30 * time.Second)),
 	}
 }
 
 func (c httpSSEConnector) Connect(ctx context.Context, serverName string, cfg config.MCPServerConfig) (Client, error) {
+	cfg = resolveServerConfig(serverName, cfg)
 	rawURL := strings.TrimSpace(cfg.URL)
 	if rawURL == "" {
 		return nil, fmt.Errorf("http_sse transport requires url")
@@ -84,9 +84,11 @@ func (c httpSSEConnector) Connect(ctx context.Context, serverName string, cfg co
 		client.messageEndpoints = prependUnique(client.messageEndpoints, endpoint)
 	}
 
-	if err := initializeClient(ctx, client); err != nil {
+	info, err := initializeClient(ctx, client)
+	if err != nil {
 		return nil, err
 	}
+	client.info = info
 	return client, nil
 }
 
@@ -198,11 +200,17 @@ type httpSSEClient struct {
 	sseURL           string
 	messageEndpoints []string
 	headers          map[string]string
+	info             ServerInfo // initialize 握手结果
 
 	mu     sync.Mutex
 	nextID int64
 }
 
+// ServerInfo 返回 initialize 握手中服务器上报的信息。
+func (c *httpSSEClient) ServerInfo() ServerInfo {
+	return c.info
+}
+
 func (c *httpSSEClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
 	result, err := c.invoke(ctx, "tools/list", map[string]any{})
 	if err != nil {
@@ -226,6 +234,22 @@ func (c *httpSSEClient) CallTool(ctx context.Context, toolName, argsJSON string)
 	return decodeCallResult(result)
 }
 
+func (c *httpSSEClient) ListResources(ctx context.Context) ([]ResourceDefinition, error) {
+	return listResources(ctx, c)
+}
+
+func (c *httpSSEClient) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	return readResource(ctx, c, uri)
+}
+
+func (c *httpSSEClient) ListPrompts(ctx context.Context) ([]PromptDefinition, error) {
+	return listPrompts(ctx, c)
+}
+
+func (c *httpSSEClient) GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error) {
+	return getPrompt(ctx, c, name, args)
+}
+
 func (c *httpSSEClient) invoke(ctx context.Context, method string, params any) (any, error) {
 	id := atomic.AddInt64(&c.nextID, 1)
 
@@ -249,6 +273,9 @@ func (c *httpSSEClient) invoke(ctx context.Context, method string, params any) (
 			if err == nil {
 				return result, nil
 			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			lastErr = fmt.Errorf("endpoint=%s attempt=%d/%d: %w", endpoint, attempt+1, httpSSERequestMaxAttempts, err)
 			if !isRetryable(err) || attempt == httpSSERequestMaxAttempts-1 {
 				break
@@ -356,6 +383,11 @@ func (c *httpSSEClient) postAndReadResponse(ctx context.Context, endpoint string
 		return nil, statusErr
 	}
 
+	return readRPCResultFromHTTP(ctx, resp, id)
+}
+
+// readRPCResultFromHTTP 按 Content-Type 从 JSON 或 SSE 响应体中读取指定 id 的 JSON-RPC 结果。
+func readRPCResultFromHTTP(ctx context.Context, resp *http.Response, id int64) (any, error) {
 	contentType := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Type")))
 	if strings.HasPrefix(contentType, "text/event-stream") {
 		return readRPCResultFromSSE(ctx, resp.Body, id)
@@ -363,6 +395,9 @@ func (c *httpSSEClient) postAndReadResponse(ctx context.Context, endpoint string
 
 	payload, err := io.ReadAll(resp.Body)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("read mcp response: %w", err)
 	}
 	result, matched, err := decodeRPCResponse(payload, id)
@@ -411,6 +446,10 @@ func readRPCResultFromSSE(ctx context.Context, body io.Reader, expectedID int64)
 
 		line, err := reader.ReadString('\n')
 		if err != nil {
+			// 请求上下文取消后读取会中断，此时返回上下文错误以便调用方识别超时。
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
 			return nil, fmt.Errorf("read sse response: %w", err)
 		}
 		line = strings.TrimRight(line, "\r\n")
@@ -0,0 +1,262 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+// mcpSessionHeader 是 Streamable HTTP 传输用于关联会话的请求/响应头。
+const mcpSessionHeader = "Mcp-Session-Id"
+
+// errSessionExpired 表示服务器已不再识别当前会话 id（HTTP 404），需要重新连接并初始化。
+var errSessionExpired = errors.New("mcp session expired")
+
+type httpStreamConnector struct {
+	client *http.Client
+}
+
+func newHTTPStreamConnector() Connector {
+	return httpStreamConnector{
+		client: httpclient.New(httpclient.Timeout(30 * time.Second)),
+	}
+}
+
+func (c httpStreamConnector) Connect(ctx context.Context, serverName string, cfg config.MCPServerConfig) (Client, error) {
+	cfg = resolveServerConfig(serverName, cfg)
+	rawURL := strings.TrimSpace(cfg.URL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("http_stream transport requires url")
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid http_stream url %q: %w", rawURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported http_stream url scheme: %q", parsedURL.Scheme)
+	}
+
+	client := &httpStreamClient{
+		httpClient: c.client,
+		endpoint:   parsedURL.String(),
+		headers:    cloneHeaders(cfg.Headers),
+	}
+	info, err := initializeClient(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	client.info = info
+	return client, nil
+}
+
+// httpStreamClient 实现 Streamable HTTP 传输：所有消息 POST 到同一端点，
+// 响应可能是 application/json 或 text/event-stream，会话由 Mcp-Session-Id 头维持。
+type httpStreamClient struct {
+	httpClient *http.Client
+	endpoint   string
+	headers    map[string]string
+	info       ServerInfo // initialize 握手结果
+
+	mu        sync.Mutex
+	nextID    int64
+	sessionID string
+	expired   bool
+}
+
+// ServerInfo 返回 initialize 握手中服务器上报的信息。
+func (c *httpStreamClient) ServerInfo() ServerInfo {
+	return c.info
+}
+
+func (c *httpStreamClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
+	result, err := c.invoke(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	return decodeToolDefinitions(result)
+}
+
+func (c *httpStreamClient) CallTool(ctx context.Context, toolName, argsJSON string) (any, error) {
+	args, err := parseToolArgs(compactJSONOrRaw(argsJSON))
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.invoke(ctx, "tools/call", map[string]any{
+		"name":      strings.TrimSpace(toolName),
+		"arguments": args,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeCallResult(result)
+}
+
+func (c *httpStreamClient) ListResources(ctx context.Context) ([]ResourceDefinition, error) {
+	return listResources(ctx, c)
+}
+
+func (c *httpStreamClient) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	return readResource(ctx, c, uri)
+}
+
+func (c *httpStreamClient) ListPrompts(ctx context.Context) ([]PromptDefinition, error) {
+	return listPrompts(ctx, c)
+}
+
+func (c *httpStreamClient) GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error) {
+	return getPrompt(ctx, c, name, args)
+}
+
+func (c *httpStreamClient) invoke(ctx context.Context, method string, params any) (any, error) {
+	id := atomic.AddInt64(&c.nextID, 1)
+
+	reqBody, err := json.Marshal(map[string]any{
+		"jsonrpc": jsonRPCVersion,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode json-rpc request: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < httpSSERequestMaxAttempts; attempt++ {
+		result, err := c.postAndReadResponse(ctx, reqBody, id)
+		if err == nil {
+			return result, nil
+		}
+		lastErr = fmt.Errorf("attempt=%d/%d: %w", attempt+1, httpSSERequestMaxAttempts, err)
+		if !isRetryable(err) || attempt == httpSSERequestMaxAttempts-1 {
+			break
+		}
+		if err := waitHTTPSSERetry(ctx, attempt+1); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("mcp http_stream invoke %s failed: %w", strings.TrimSpace(method), lastErr)
+}
+
+func (c *httpStreamClient) notify(ctx context.Context, method string, params any) error {
+	reqBody, err := json.Marshal(map[string]any{
+		"jsonrpc": jsonRPCVersion,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("encode json-rpc notification: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, err := c.post(ctx, reqBody)
+	if err != nil {
+		return fmt.Errorf("mcp http_stream notify %s failed: %w", strings.TrimSpace(method), err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+// Close 通知服务器结束当前会话；服务器不支持时忽略错误。
+func (c *httpStreamClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sessionID == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	applyHeaders(req.Header, c.headers)
+	req.Header.Set(mcpSessionHeader, c.sessionID)
+	c.sessionID = ""
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return nil
+}
+
+func (c *httpStreamClient) postAndReadResponse(ctx context.Context, reqBody []byte, id int64) (any, error) {
+	resp, err := c.post(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readRPCResultFromHTTP(ctx, resp, id)
+}
+
+// post 发送一条 JSON-RPC 消息并在成功时返回响应；非 2xx 状态被转换为错误。
+// 调用方需持有 c.mu。
+func (c *httpStreamClient) post(ctx context.Context, reqBody []byte) (*http.Response, error) {
+	if c.expired {
+		return nil, errSessionExpired
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	applyHeaders(req.Header, c.headers)
+	if c.sessionID != "" {
+		req.Header.Set(mcpSessionHeader, c.sessionID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, makeRetryable(err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound && c.sessionID != "" {
+			c.sessionID = ""
+			c.expired = true
+			return nil, errSessionExpired
+		}
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		statusErr := fmt.Errorf("mcp http request failed: %s", msg)
+		if shouldRetryHTTPStatus(resp.StatusCode) {
+			return nil, makeRetryable(statusErr)
+		}
+		return nil, statusErr
+	}
+
+	if sessionID := strings.TrimSpace(resp.Header.Get(mcpSessionHeader)); sessionID != "" {
+		c.sessionID = sessionID
+	}
+	return resp, nil
+}
@@ -24,6 +24,7 @@ func newStdioConnector() Connector {
 }
 
 func (c stdioConnector) Connect(ctx context.Context, serverName string, cfg config.MCPServerConfig) (Client, error) {
+	cfg = resolveServerConfig(serverName, cfg)
 	command := strings.TrimSpace(cfg.Command)
 	if command == "" {
 		return nil, fmt.Errorf("stdio transport requires command")
@@ -54,8 +55,10 @@ func (c stdioConnector) Connect(ctx context.Context, serverName string, cfg conf
 		cmd:        cmd,
 		stdin:      stdin,
 		reader:     bufio.NewReader(stdout),
-		stderr:     newTailBuffer(4096),
+		stderr:     newTailBuffer(stderrTailSize(cfg)),
 		exitDone:   make(chan struct{}),
+		pending:    make(map[string]chan []byte),
+		readDone:   make(chan struct{}),
 	}
 
 	// Drain stderr to avoid blocking and retain a bounded tail for diagnostics.
@@ -63,17 +66,30 @@ func (c stdioConnector) Connect(ctx context.Context, serverName string, cfg conf
 	go func() {
 		client.markExited(cmd.Wait())
 	}()
+	go client.readLoop()
 
-	if err := initializeClient(ctx, client); err != nil {
+	info, err := initializeClient(ctx, client)
+	if err != nil {
 		if cmd.Process != nil {
 			_ = cmd.Process.Kill()
 		}
 		client.waitForExit(500 * time.Millisecond)
 		return nil, client.decorateError(err)
 	}
+	client.info = info
 	return client, nil
 }
 
+// defaultStderrTailBytes 是未配置 stderr_tail_bytes 时保留的 stderr 尾部大小。
+const defaultStderrTailBytes = 4096
+
+func stderrTailSize(cfg config.MCPServerConfig) int {
+	if cfg.StderrTailBytes > 0 {
+		return min(cfg.StderrTailBytes, config.MaxMCPStderrTailBytes)
+	}
+	return defaultStderrTailBytes
+}
+
 func mergeEnv(extra map[string]string) []string {
 	base := os.Environ()
 	if len(extra) == 0 {
@@ -111,14 +127,31 @@ type stdioClient struct {
 	stdin      io.WriteCloser
 	reader     *bufio.Reader
 	stderr     *tailBuffer
+	info       ServerInfo // initialize 握手结果
 
 	exitMu   sync.RWMutex
 	exited   bool
 	exitErr  error
 	exitDone chan struct{}
 
-	mu     sync.Mutex
+	mu     sync.Mutex // 串行化写入
 	nextID int64
+
+	// 后台读循环按 JSON-RPC id 把响应分发给等待中的请求，调用方因此可以在等待响应时被取消。
+	pendingMu sync.Mutex
+	pending   map[string]chan []byte
+	readDone  chan struct{} // 读循环退出时关闭，readErr 记录原因
+	readErr   error
+}
+
+// ServerInfo 返回 initialize 握手中服务器上报的信息。
+func (c *stdioClient) ServerInfo() ServerInfo {
+	return c.info
+}
+
+// StderrTail 返回服务器最近输出到 stderr 的内容，长度不超过配置的尾部大小。
+func (c *stdioClient) StderrTail() string {
+	return c.stderr.String()
 }
 
 func (c *stdioClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
@@ -144,6 +177,22 @@ func (c *stdioClient) CallTool(ctx context.Context, toolName, argsJSON string) (
 	return decodeCallResult(result)
 }
 
+func (c *stdioClient) ListResources(ctx context.Context) ([]ResourceDefinition, error) {
+	return listResources(ctx, c)
+}
+
+func (c *stdioClient) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	return readResource(ctx, c, uri)
+}
+
+func (c *stdioClient) ListPrompts(ctx context.Context) ([]PromptDefinition, error) {
+	return listPrompts(ctx, c)
+}
+
+func (c *stdioClient) GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error) {
+	return getPrompt(ctx, c, name, args)
+}
+
 func (c *stdioClient) invoke(ctx context.Context, method string, params any) (any, error) {
 	if err := c.processExitError(); err != nil {
 		return nil, c.decorateError(err)
@@ -160,32 +209,65 @@ func (c *stdioClient) invoke(ctx context.Context, method string, params any) (an
 		return nil, fmt.Errorf("encode json-rpc request: %w", err)
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	key := normalizeRPCID(id)
+	responses := make(chan []byte, 1)
+	c.pendingMu.Lock()
+	c.pending[key] = responses
+	c.pendingMu.Unlock()
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, key)
+		c.pendingMu.Unlock()
+	}()
 
-	if err := c.writeFramed(payload); err != nil {
+	c.mu.Lock()
+	err = c.writeFramed(payload)
+	c.mu.Unlock()
+	if err != nil {
 		return nil, c.decorateError(err)
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.readDone:
+		return nil, c.decorateError(c.readErr)
+	case responsePayload := <-responses:
+		result, _, err := decodeRPCResponse(responsePayload, id)
+		if err != nil {
+			return nil, err
 		}
+		return result, nil
+	}
+}
 
-		responsePayload, err := c.readFramed()
+// readLoop 持续读取服务器输出的帧，并交给 id 对应的等待者；通知与已取消请求的迟到响应会被丢弃。
+func (c *stdioClient) readLoop() {
+	for {
+		payload, err := c.readFramed()
 		if err != nil {
-			return nil, c.decorateError(err)
+			c.readErr = err
+			close(c.readDone)
+			return
 		}
-		result, matched, err := decodeRPCResponse(responsePayload, id)
-		if err != nil {
-			return nil, err
+
+		var envelope struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
 		}
-		if !matched {
+		// 带 method 的是服务器发起的请求或通知，不是对本端请求的响应。
+		if json.Unmarshal(payload, &envelope) != nil || envelope.ID == nil || envelope.Method != "" {
 			continue
 		}
-		return result, nil
+		c.pendingMu.Lock()
+		responses, ok := c.pending[normalizeRPCID(envelope.ID)]
+		c.pendingMu.Unlock()
+		if ok {
+			select {
+			case responses <- payload:
+			default:
+			}
+		}
 	}
 }
 
@@ -208,6 +290,16 @@ func (c *stdioClient) notify(ctx context.Context, method string, params any) err
 	return c.decorateError(c.writeFramed(payload))
 }
 
+// Close 终止服务器进程；重连或降级时由管理器调用，避免挂起的进程残留。
+func (c *stdioClient) Close() error {
+	_ = c.stdin.Close()
+	if c.cmd != nil && c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	c.waitForExit(500 * time.Millisecond)
+	return nil
+}
+
 func (c *stdioClient) writeFramed(payload []byte) error {
 	header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(payload))
 	if _, err := io.WriteString(c.stdin, header); err != nil {
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+	"github.com/gorilla/websocket"
+)
+
+const websocketDefaultTimeout = 30 * time.Second
+
+type websocketConnector struct {
+	dialer *websocket.Dialer
+}
+
+func newWebSocketConnector() Connector {
+	return websocketConnector{
+		dialer: &websocket.Dialer{
+			Proxy:            httpclient.Proxy,
+			HandshakeTimeout: 15 * time.Second,
+		},
+	}
+}
+
+func (c websocketConnector) Connect(ctx context.Context, serverName string, cfg config.MCPServerConfig) (Client, error) {
+	cfg = resolveServerConfig(serverName, cfg)
+	rawURL := strings.TrimSpace(cfg.URL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("websocket transport requires url")
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket url %q: %w", rawURL, err)
+	}
+	if parsedURL.Scheme != "ws" && parsedURL.Scheme != "wss" {
+		return nil, fmt.Errorf("unsupported websocket url scheme: %q", parsedURL.Scheme)
+	}
+
+	header := http.Header{}
+	applyHeaders(header, cloneHeaders(cfg.Headers))
+
+	conn, resp, err := c.dialer.DialContext(ctx, parsedURL.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("dial mcp websocket server %q: %w (status=%d)", serverName, err, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("dial mcp websocket server %q: %w", serverName, err)
+	}
+
+	client := &websocketClient{serverName: serverName, conn: conn}
+	info, err := initializeClient(ctx, client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	client.info = info
+	return client, nil
+}
+
+// websocketClient 在单个 WebSocket 连接上以文本帧收发 JSON-RPC 消息。
+// 连接一旦读写失败即被关闭，后续调用直接返回错误，由 Manager 的重连逻辑重新拨号。
+type websocketClient struct {
+	serverName string
+	conn       *websocket.Conn
+	info       ServerInfo // initialize 握手结果
+
+	mu      sync.Mutex
+	nextID  int64
+	closed  bool
+	lastErr error
+}
+
+// ServerInfo 返回 initialize 握手中服务器上报的信息。
+func (c *websocketClient) ServerInfo() ServerInfo {
+	return c.info
+}
+
+func (c *websocketClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
+	result, err := c.invoke(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	return decodeToolDefinitions(result)
+}
+
+func (c *websocketClient) CallTool(ctx context.Context, toolName, argsJSON string) (any, error) {
+	args, err := parseToolArgs(compactJSONOrRaw(argsJSON))
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.invoke(ctx, "tools/call", map[string]any{
+		"name":      strings.TrimSpace(toolName),
+		"arguments": args,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeCallResult(result)
+}
+
+func (c *websocketClient) ListResources(ctx context.Context) ([]ResourceDefinition, error) {
+	return listResources(ctx, c)
+}
+
+func (c *websocketClient) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	return readResource(ctx, c, uri)
+}
+
+func (c *websocketClient) ListPrompts(ctx context.Context) ([]PromptDefinition, error) {
+	return listPrompts(ctx, c)
+}
+
+func (c *websocketClient) GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error) {
+	return getPrompt(ctx, c, name, args)
+}
+
+func (c *websocketClient) invoke(ctx context.Context, method string, params any) (any, error) {
+	id := atomic.AddInt64(&c.nextID, 1)
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": jsonRPCVersion,
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode json-rpc request: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.connectionError(); err != nil {
+		return nil, err
+	}
+
+	deadline := websocketDeadline(ctx)
+	// ctx 取消时立即让阻塞中的读操作返回。
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := c.writeMessage(payload, deadline); err != nil {
+		return nil, err
+	}
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return nil, c.fail(fmt.Errorf("set websocket read deadline: %w", err))
+	}
+
+	for {
+		_, responsePayload, err := c.conn.ReadMessage()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				_ = c.fail(ctxErr)
+				return nil, ctxErr
+			}
+			return nil, c.fail(fmt.Errorf("read mcp websocket message: %w", err))
+		}
+		result, matched, err := decodeRPCResponse(responsePayload, id)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		return result, nil
+	}
+}
+
+func (c *websocketClient) notify(ctx context.Context, method string, params any) error {
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": jsonRPCVersion,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("encode json-rpc notification: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.connectionError(); err != nil {
+		return err
+	}
+	return c.writeMessage(payload, websocketDeadline(ctx))
+}
+
+// Close 关闭底层 WebSocket 连接。
+func (c *websocketClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	_ = c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	return c.conn.Close()
+}
+
+func (c *websocketClient) writeMessage(payload []byte, deadline time.Time) error {
+	if err := c.conn.SetWriteDeadline(deadline); err != nil {
+		return c.fail(fmt.Errorf("set websocket write deadline: %w", err))
+	}
+	if err := c.conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+		return c.fail(fmt.Errorf("write mcp websocket message: %w", err))
+	}
+	return nil
+}
+
+// fail 记录错误并关闭连接；调用方需持有 c.mu。
+func (c *websocketClient) fail(err error) error {
+	if !c.closed {
+		c.closed = true
+		c.lastErr = err
+		_ = c.conn.Close()
+	}
+	return err
+}
+
+func (c *websocketClient) connectionError() error {
+	if !c.closed {
+		return nil
+	}
+	if c.lastErr == nil {
+		return fmt.Errorf("mcp websocket server %q connection closed", c.serverName)
+	}
+	return fmt.Errorf("mcp websocket server %q connection closed: %w", c.serverName, c.lastErr)
+}
+
+func websocketDeadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(websocketDefaultTimeout)
+}
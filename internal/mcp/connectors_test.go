@@ -4,16 +4,19 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/gorilla/websocket"
 )
 
 func TestStdioConnector_ConnectAndCall(t *testing.T) {
@@ -33,6 +36,14 @@ func TestStdioConnector_ConnectAndCall(t *testing.T) {
 		t.Fatalf("Connect() error: %v", err)
 	}
 
+	info := client.(ServerInfoProvider).ServerInfo()
+	if info.Name != "test-stdio" || info.Version != "1.0.0" || info.ProtocolVersion != "2024-11-05" {
+		t.Fatalf("unexpected server info: %+v", info)
+	}
+	if strings.Join(info.Capabilities, ",") != "resources,tools" {
+		t.Fatalf("expected sorted capabilities, got %v", info.Capabilities)
+	}
+
 	tools, err := client.ListTools(context.Background())
 	if err != nil {
 		t.Fatalf("ListTools() error: %v", err)
@@ -50,6 +61,53 @@ func TestStdioConnector_ConnectAndCall(t *testing.T) {
 	}
 }
 
+func TestStdioConnector_ResourcesAndPrompts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := newStdioConnector().Connect(ctx, "helper", config.MCPServerConfig{
+		Transport: "stdio",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=TestMCPHelperProcess", "--", "mcp-stdio-helper"},
+		Env: map[string]string{
+			"GO_WANT_HELPER_PROCESS": "1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	resources, err := client.ListResources(ctx)
+	if err != nil {
+		t.Fatalf("ListResources() error: %v", err)
+	}
+	if len(resources) != 1 || resources[0].URI != "file:///README.md" || resources[0].MIMEType != "text/markdown" {
+		t.Fatalf("unexpected resources: %+v", resources)
+	}
+	contents, err := client.ReadResource(ctx, "file:///README.md")
+	if err != nil {
+		t.Fatalf("ReadResource() error: %v", err)
+	}
+	if len(contents) != 1 || contents[0].Text != "# Hello" || contents[0].URI != "file:///README.md" {
+		t.Fatalf("unexpected resource contents: %+v", contents)
+	}
+
+	prompts, err := client.ListPrompts(ctx)
+	if err != nil {
+		t.Fatalf("ListPrompts() error: %v", err)
+	}
+	if len(prompts) != 1 || prompts[0].Name != "review" || len(prompts[0].Arguments) != 1 || !prompts[0].Arguments[0].Required {
+		t.Fatalf("unexpected prompts: %+v", prompts)
+	}
+	prompt, err := client.GetPrompt(ctx, "review", map[string]string{"lang": "Go"})
+	if err != nil {
+		t.Fatalf("GetPrompt() error: %v", err)
+	}
+	if prompt.Description != "Review code" || len(prompt.Messages) != 1 || prompt.Messages[0].Text != "Review this Go code" {
+		t.Fatalf("expected only the text message to be kept, got %+v", prompt)
+	}
+}
+
 func TestHTTPSSEConnector_ConnectDiscoverAndCall(t *testing.T) {
 	var receivedHeader string
 
@@ -197,6 +255,71 @@ func TestHTTPSSEClient_InvokeRetriesTransientFailure(t *testing.T) {
 	}
 }
 
+func TestStdioConnector_CallHonorsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := newStdioConnector().Connect(ctx, "helper", config.MCPServerConfig{
+		Transport: "stdio",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=TestMCPHelperProcess", "--", "mcp-stdio-helper"},
+		Env: map[string]string{
+			"GO_WANT_HELPER_PROCESS": "1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	callCtx, callCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer callCancel()
+	start := time.Now()
+	if _, err := client.CallTool(callCtx, "hang", `{}`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded for unanswered call, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected cancellation mid-read to return promptly, took %s", elapsed)
+	}
+
+	// 超时的请求不应阻塞同一连接上的后续调用。
+	result, err := client.CallTool(context.Background(), "echo", `{"message":"after"}`)
+	if err != nil {
+		t.Fatalf("CallTool() after timeout error: %v", err)
+	}
+	if got := strings.TrimSpace(fmt.Sprint(result)); got != "echo: after" {
+		t.Fatalf("unexpected tool result after timeout: %v", result)
+	}
+}
+
+func TestStdioConnector_StderrTailIsBounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := newStdioConnector().Connect(ctx, "helper", config.MCPServerConfig{
+		Transport:       "stdio",
+		Command:         os.Args[0],
+		Args:            []string{"-test.run=TestMCPHelperProcess", "--", "mcp-stdio-helper"},
+		Env:             map[string]string{"GO_WANT_HELPER_PROCESS": "1"},
+		StderrTailBytes: 17,
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer closeClient(client)
+
+	provider, ok := client.(StderrTailProvider)
+	if !ok {
+		t.Fatal("expected stdio client to expose its stderr tail")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for provider.StderrTail() == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := provider.StderrTail(); got != "mcp helper ready\n" {
+		t.Fatalf("expected stderr tail bounded to the last 17 bytes, got %q", got)
+	}
+}
+
 func TestStdioConnector_ConnectInitFailureIncludesStderr(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
@@ -253,6 +376,7 @@ func TestMCPHelperProcess(t *testing.T) {
 func runMCPHelperProcess() {
 	reader := bufio.NewReader(os.Stdin)
 	writer := os.Stdout
+	_, _ = io.WriteString(os.Stderr, "loading plugins...\nmcp helper ready\n")
 
 	for {
 		contentLength, err := readContentLength(reader)
@@ -279,7 +403,8 @@ func runMCPHelperProcess() {
 		switch method {
 		case "initialize":
 			result = map[string]any{
-				"capabilities": map[string]any{},
+				"protocolVersion": "2024-11-05",
+				"capabilities":    map[string]any{"tools": map[string]any{}, "resources": map[string]any{}},
 				"serverInfo": map[string]any{
 					"name":    "test-stdio",
 					"version": "1.0.0",
@@ -294,9 +419,53 @@ func runMCPHelperProcess() {
 					},
 				},
 			}
+		case "resources/list":
+			result = map[string]any{
+				"resources": []map[string]any{
+					{"uri": "file:///README.md", "name": "README", "mimeType": "text/markdown"},
+				},
+			}
+		case "resources/read":
+			uri := ""
+			if params, ok := req["params"].(map[string]any); ok {
+				uri = stringValue(params["uri"])
+			}
+			result = map[string]any{
+				"contents": []map[string]any{
+					{"uri": uri, "mimeType": "text/markdown", "text": "# Hello"},
+				},
+			}
+		case "prompts/list":
+			result = map[string]any{
+				"prompts": []map[string]any{
+					{
+						"name":        "review",
+						"description": "Review code",
+						"arguments":   []map[string]any{{"name": "lang", "required": true}},
+					},
+				},
+			}
+		case "prompts/get":
+			lang := ""
+			if params, ok := req["params"].(map[string]any); ok {
+				if args, ok := params["arguments"].(map[string]any); ok {
+					lang = stringValue(args["lang"])
+				}
+			}
+			result = map[string]any{
+				"description": "Review code",
+				"messages": []map[string]any{
+					{"role": "user", "content": map[string]any{"type": "text", "text": "Review this " + lang + " code"}},
+					{"role": "user", "content": map[string]any{"type": "image", "data": "AAAA", "mimeType": "image/png"}},
+				},
+			}
 		case "tools/call":
 			text := "echo: "
 			if params, ok := req["params"].(map[string]any); ok {
+				if stringValue(params["name"]) == "hang" {
+					// 模拟永不响应的工具调用。
+					continue
+				}
 				if args, ok := params["arguments"].(map[string]any); ok {
 					text += strings.TrimSpace(stringValue(args["message"]))
 				}
@@ -340,3 +509,276 @@ func TestReadSSEEndpointEvent(t *testing.T) {
 		t.Fatalf("expected /rpc, got %q", endpoint)
 	}
 }
+
+// newWebSocketMCPServer 启动一个 WebSocket MCP 测试服务器；dropCall 返回 true 时在处理 tools/call 前断开连接。
+func newWebSocketMCPServer(t *testing.T, dropCall func(conn int) bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mu      sync.Mutex
+		headers []string
+		conns   int
+	)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = append(headers, r.Header.Get("Authorization"))
+		conns++
+		connIndex := conns
+		mu.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var req map[string]any
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			id, hasID := req["id"]
+			if !hasID {
+				continue
+			}
+
+			var result any
+			switch req["method"] {
+			case "initialize":
+				result = map[string]any{"protocolVersion": "2024-11-05"}
+			case "tools/list":
+				result = map[string]any{
+					"tools": []map[string]any{{"name": "echo", "description": "Echo"}},
+				}
+			case "tools/call":
+				if dropCall != nil && dropCall(connIndex) {
+					return
+				}
+				result = map[string]any{
+					"content": []map[string]any{{"type": "text", "text": fmt.Sprintf("echo: ws-%d", connIndex)}},
+				}
+			default:
+				result = map[string]any{}
+			}
+			if err := conn.WriteJSON(map[string]any{"jsonrpc": "2.0", "id": id, "result": result}); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &headers
+}
+
+func TestWebSocketConnector_ConnectDiscoverAndCall(t *testing.T) {
+	server, headers := newWebSocketMCPServer(t, nil)
+
+	connector := newWebSocketConnector()
+	client, err := connector.Connect(context.Background(), "remote", config.MCPServerConfig{
+		Transport: "websocket",
+		URL:       "ws" + strings.TrimPrefix(server.URL, "http"),
+		Headers: map[string]string{
+			"Authorization": "Bearer abc123",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer client.(*websocketClient).Close()
+
+	if len(*headers) != 1 || (*headers)[0] != "Bearer abc123" {
+		t.Fatalf("expected auth header on websocket handshake, got %v", *headers)
+	}
+
+	tools, err := client.ListTools(context.Background())
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "echo" {
+		t.Fatalf("unexpected tool definitions: %+v", tools)
+	}
+
+	result, err := client.CallTool(context.Background(), "echo", `{}`)
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if got := strings.TrimSpace(fmt.Sprint(result)); got != "echo: ws-1" {
+		t.Fatalf("unexpected tool result: %v", result)
+	}
+}
+
+func TestWebSocketConnector_RejectsNonWebSocketScheme(t *testing.T) {
+	_, err := newWebSocketConnector().Connect(context.Background(), "remote", config.MCPServerConfig{
+		Transport: "websocket",
+		URL:       "http://127.0.0.1:1/mcp",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported websocket url scheme") {
+		t.Fatalf("expected scheme error, got %v", err)
+	}
+}
+
+func TestManager_WebSocketReconnectsAfterConnectionDrop(t *testing.T) {
+	server, headers := newWebSocketMCPServer(t, func(conn int) bool { return conn == 1 })
+
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"remote": {
+				Transport: "websocket",
+				URL:       "ws" + strings.TrimPrefix(server.URL, "http"),
+			},
+		},
+		DefaultConnectors(),
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := mgr.CallTool(ctx, "remote", "echo", `{}`)
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if result != "echo: ws-2" {
+		t.Fatalf("expected result from the re-dialed connection, got %q", result)
+	}
+	if len(*headers) != 2 {
+		t.Fatalf("expected a second websocket handshake, got %d", len(*headers))
+	}
+
+	status := mgr.Statuses()[0]
+	if !status.Connected || status.Degraded {
+		t.Fatalf("expected recovered status, got %+v", status)
+	}
+}
+
+// newHTTPStreamMCPServer 启动一个 Streamable HTTP MCP 测试服务器：initialize 返回 JSON 并分配会话，
+// 其余请求以 SSE 响应；expireAfterList 为 true 时首个会话在 tools/list 之后失效。
+func newHTTPStreamMCPServer(t *testing.T, expireAfterList bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		sessions []string
+		expired  = map[string]bool{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		sessionID := r.Header.Get("Mcp-Session-Id")
+
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		method, _ := req["method"].(string)
+		if method != "initialize" && (sessionID == "" || expired[sessionID]) {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+
+		switch method {
+		case "initialize":
+			sessionID = fmt.Sprintf("session-%d", len(sessions)+1)
+			sessions = append(sessions, sessionID)
+			w.Header().Set("Mcp-Session-Id", sessionID)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jsonrpc": "2.0",
+				"id":      req["id"],
+				"result":  map[string]any{"protocolVersion": "2025-03-26"},
+			})
+			return
+		case "notifications/initialized":
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		var result any
+		switch method {
+		case "tools/list":
+			result = map[string]any{
+				"tools": []map[string]any{{"name": "echo", "description": "Echo"}},
+			}
+			if expireAfterList && sessionID == "session-1" {
+				expired[sessionID] = true
+			}
+		case "tools/call":
+			result = map[string]any{
+				"content": []map[string]any{{"type": "text", "text": "echo: " + sessionID}},
+			}
+		default:
+			result = map[string]any{}
+		}
+		payload, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req["id"], "result": result})
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", payload)
+	}))
+	t.Cleanup(server.Close)
+	return server, &sessions
+}
+
+func TestHTTPStreamConnector_ConnectDiscoverAndCall(t *testing.T) {
+	server, sessions := newHTTPStreamMCPServer(t, false)
+
+	client, err := newHTTPStreamConnector().Connect(context.Background(), "remote", config.MCPServerConfig{
+		Transport: "http_stream",
+		URL:       server.URL + "/mcp",
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	tools, err := client.ListTools(context.Background())
+	if err != nil {
+		t.Fatalf("ListTools() error: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name != "echo" {
+		t.Fatalf("unexpected tool definitions: %+v", tools)
+	}
+
+	result, err := client.CallTool(context.Background(), "echo", `{}`)
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if got := strings.TrimSpace(fmt.Sprint(result)); got != "echo: session-1" {
+		t.Fatalf("expected call to reuse the initialized session, got %v", result)
+	}
+	if len(*sessions) != 1 {
+		t.Fatalf("expected a single session, got %v", *sessions)
+	}
+}
+
+func TestManager_HTTPStreamReconnectsOnExpiredSession(t *testing.T) {
+	server, sessions := newHTTPStreamMCPServer(t, true)
+
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"remote": {
+				Transport: "http_stream",
+				URL:       server.URL + "/mcp",
+			},
+		},
+		DefaultConnectors(),
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	result, err := mgr.CallTool(context.Background(), "remote", "echo", `{}`)
+	if err != nil {
+		t.Fatalf("CallTool() error: %v", err)
+	}
+	if result != "echo: session-2" {
+		t.Fatalf("expected call to succeed on a fresh session, got %q", result)
+	}
+	if len(*sessions) != 2 {
+		t.Fatalf("expected re-initialization after session expiry, got %v", *sessions)
+	}
+}
@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"log/slog"
+	"os"
+	"regexp"
+
+	"github.com/MEKXH/golem/internal/config"
+)
+
+// envPlaceholderPattern 匹配 ${VAR} 形式的环境变量引用；不处理裸 $VAR，避免误伤值中的字面量 $。
+var envPlaceholderPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveServerConfig 在连接时展开服务器配置中 command、url、env 与 headers 里的 ${VAR} 引用。
+// 返回副本，不修改原配置；未设置的变量展开为空字符串并记录警告。
+func resolveServerConfig(serverName string, cfg config.MCPServerConfig) config.MCPServerConfig {
+	cfg.Command = expandEnvPlaceholders(serverName, "command", cfg.Command)
+	cfg.URL = expandEnvPlaceholders(serverName, "url", cfg.URL)
+	cfg.Env = expandEnvMap(serverName, "env", cfg.Env)
+	cfg.Headers = expandEnvMap(serverName, "headers", cfg.Headers)
+	return cfg
+}
+
+func expandEnvMap(serverName, field string, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return src
+	}
+	out := make(map[string]string, len(src))
+	for key, value := range src {
+		out[key] = expandEnvPlaceholders(serverName, field+"."+key, value)
+	}
+	return out
+}
+
+func expandEnvPlaceholders(serverName, field, value string) string {
+	return envPlaceholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := envPlaceholderPattern.FindStringSubmatch(match)[1]
+		resolved, ok := os.LookupEnv(name)
+		if !ok {
+			slog.Warn("mcp config references unset environment variable",
+				"server", serverName,
+				"field", field,
+				"variable", name,
+			)
+		}
+		return resolved
+	})
+}
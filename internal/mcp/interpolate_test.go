@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/config"
+)
+
+func TestResolveServerConfig_ExpandsPlaceholders(t *testing.T) {
+	t.Setenv("GOLEM_TEST_MCP_TOKEN", "secret")
+	t.Setenv("GOLEM_TEST_MCP_HOST", "mcp.example.com")
+
+	original := config.MCPServerConfig{
+		Command: "${GOLEM_TEST_MCP_BIN_UNSET}/server",
+		URL:     "https://${GOLEM_TEST_MCP_HOST}/mcp",
+		Env:     map[string]string{"API_TOKEN": "${GOLEM_TEST_MCP_TOKEN}", "LITERAL": "$HOME-stays"},
+		Headers: map[string]string{"Authorization": "Bearer ${GOLEM_TEST_MCP_TOKEN}"},
+	}
+	resolved := resolveServerConfig("remote", original)
+
+	if resolved.URL != "https://mcp.example.com/mcp" {
+		t.Fatalf("unexpected url: %q", resolved.URL)
+	}
+	if resolved.Command != "/server" {
+		t.Fatalf("expected unset variable to expand to empty string, got %q", resolved.Command)
+	}
+	if resolved.Env["API_TOKEN"] != "secret" || resolved.Env["LITERAL"] != "$HOME-stays" {
+		t.Fatalf("unexpected env: %v", resolved.Env)
+	}
+	if resolved.Headers["Authorization"] != "Bearer secret" {
+		t.Fatalf("unexpected headers: %v", resolved.Headers)
+	}
+	if original.Headers["Authorization"] != "Bearer ${GOLEM_TEST_MCP_TOKEN}" {
+		t.Fatalf("expected original config to stay untouched, got %v", original.Headers)
+	}
+}
+
+func TestWebSocketConnector_InterpolatesHeadersAtConnect(t *testing.T) {
+	t.Setenv("GOLEM_TEST_MCP_TOKEN", "abc123")
+	server, headers := newWebSocketMCPServer(t, nil)
+
+	client, err := newWebSocketConnector().Connect(context.Background(), "remote", config.MCPServerConfig{
+		Transport: "websocket",
+		URL:       "ws" + strings.TrimPrefix(server.URL, "http"),
+		Headers:   map[string]string{"Authorization": "Bearer ${GOLEM_TEST_MCP_TOKEN}"},
+	})
+	if err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	defer client.(*websocketClient).Close()
+
+	if len(*headers) != 1 || (*headers)[0] != "Bearer abc123" {
+		t.Fatalf("expected interpolated auth header, got %v", *headers)
+	}
+}
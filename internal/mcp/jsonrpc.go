@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -177,9 +179,32 @@ func normalizeRPCID(id any) string {
 	}
 }
 
+// clientProtocolVersion 是 initialize 时请求的协议版本。
+const clientProtocolVersion = "2024-11-05"
+
+// supportedProtocolVersions 是客户端能够正确交互的协议版本；服务器协商出其他版本时连接视为降级。
+var supportedProtocolVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// UnsupportedProtocolError 表示服务器在 initialize 中返回了客户端不支持的协议版本。
+type UnsupportedProtocolError struct {
+	Version string
+}
+
+func (e *UnsupportedProtocolError) Error() string {
+	return fmt.Sprintf("unsupported mcp protocol version %q (supported: %s)", e.Version, strings.Join(supportedProtocolVersions, ", "))
+}
+
+// checkProtocolVersion 校验服务器协商的协议版本；未上报版本的旧服务器按兼容处理。
+func checkProtocolVersion(version string) error {
+	if version == "" || slices.Contains(supportedProtocolVersions, version) {
+		return nil
+	}
+	return &UnsupportedProtocolError{Version: version}
+}
+
 func buildInitializeParams() map[string]any {
 	return map[string]any{
-		"protocolVersion": "2024-11-05",
+		"protocolVersion": clientProtocolVersion,
 		"capabilities":    map[string]any{},
 		"clientInfo": map[string]any{
 			"name":    "golem",
@@ -205,12 +230,35 @@ type rpcInvoker interface {
 	notify(ctx context.Context, method string, params any) error
 }
 
-func initializeClient(ctx context.Context, invoker rpcInvoker) error {
-	if _, err := invoker.invoke(ctx, "initialize", buildInitializeParams()); err != nil {
-		return fmt.Errorf("initialize mcp session: %w", err)
+// initializeClient 完成 initialize 握手并返回服务器上报的信息。
+func initializeClient(ctx context.Context, invoker rpcInvoker) (ServerInfo, error) {
+	result, err := invoker.invoke(ctx, "initialize", buildInitializeParams())
+	if err != nil {
+		return ServerInfo{}, fmt.Errorf("initialize mcp session: %w", err)
 	}
 	if err := invoker.notify(ctx, "notifications/initialized", map[string]any{}); err != nil {
-		return fmt.Errorf("send initialized notification: %w", err)
+		return ServerInfo{}, fmt.Errorf("send initialized notification: %w", err)
+	}
+	return decodeServerInfo(result), nil
+}
+
+// decodeServerInfo 从 initialize 结果中提取 serverInfo、protocolVersion 与 capabilities。
+func decodeServerInfo(result any) ServerInfo {
+	obj, ok := result.(map[string]any)
+	if !ok {
+		return ServerInfo{}
+	}
+	info := ServerInfo{ProtocolVersion: strings.TrimSpace(stringValue(obj["protocolVersion"]))}
+	if server, ok := obj["serverInfo"].(map[string]any); ok {
+		info.Name = strings.TrimSpace(stringValue(server["name"]))
+		info.Version = strings.TrimSpace(stringValue(server["version"]))
+	}
+	if caps, ok := obj["capabilities"].(map[string]any); ok && len(caps) > 0 {
+		info.Capabilities = make([]string, 0, len(caps))
+		for name := range caps {
+			info.Capabilities = append(info.Capabilities, name)
+		}
+		sort.Strings(info.Capabilities)
 	}
-	return nil
+	return info
 }
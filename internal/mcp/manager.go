@@ -4,7 +4,10 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +20,8 @@ import (
 const (
 	reconnectMaxAttempts = 3                      // 最大重连尝试次数
 	reconnectBaseBackoff = 250 * time.Millisecond // 基础重连退避时间
+	maxCollisionSuffix   = 20                     // 工具重名时尝试的后缀数量上限
+	defaultCallTimeout   = 60 * time.Second       // 未配置 call_timeout_seconds 时单次调用的超时
 )
 
 type serverState struct {
@@ -24,6 +29,7 @@ type serverState struct {
 	client Client                 // MCP 客户端实例
 	tools  []ToolDefinition       // 从服务器发现的工具定义列表
 	status ServerStatus           // 服务器当前运行状态
+	stderr string                 // 最近一次关闭的 stdio 客户端留下的 stderr 尾部
 }
 
 // Manager 统一管理所有已配置的 MCP 服务器及其动态工具。
@@ -57,11 +63,13 @@ func NewManager(servers map[string]config.MCPServerConfig, connectors Connectors
 	}
 }
 
-// DefaultConnectors 返回用于 stdio 和 HTTP/SSE 传输的生产连接器。
+// DefaultConnectors 返回用于 stdio、HTTP/SSE、WebSocket 和 Streamable HTTP 传输的生产连接器。
 func DefaultConnectors() Connectors {
 	return Connectors{
-		Stdio:   newStdioConnector(),
-		HTTPSSE: newHTTPSSEConnector(),
+		Stdio:      newStdioConnector(),
+		HTTPSSE:    newHTTPSSEConnector(),
+		WebSocket:  newWebSocketConnector(),
+		HTTPStream: newHTTPStreamConnector(),
 	}
 }
 
@@ -92,34 +100,231 @@ func (m *Manager) Connect(ctx context.Context) error {
 }
 
 // RegisterTools 将发现的 MCP 工具注册到给定的注册表中。
+// 已注册的同名工具会被跳过，因此重连后可再次调用以补注册新发现的工具。
+// 有可用服务器时还会注册 read_mcp_resource，用于列出与读取服务器公开的资源。
+// 名称已被其他工具占用时追加数字后缀（如 mcp.fs.read_2）后注册，无法注册时跳过并告警，
+// 不会因单个工具中断其余工具的注册；处理结果记录在对应服务器的 ServerStatus.ToolCollisions 中。
 func (m *Manager) RegisterTools(reg *tools.Registry) error {
 	if reg == nil {
 		return fmt.Errorf("registry is required")
 	}
 
-	toolsToRegister := m.collectRegisteredTools()
-	for _, entry := range toolsToRegister {
-		if err := reg.Register(entry); err != nil {
-			return err
+	reported := m.reportedCollisions()
+	collisions := make(map[string][]ToolCollision)
+	processed := make(map[string]bool)
+	seen := make(map[string]bool) // 本轮已处理的 server/tool，用于识别服务器重复上报的工具
+	for _, entry := range m.collectRegisteredTools() {
+		processed[entry.serverName] = true
+		key := entry.serverName + "\x00" + entry.toolName
+		if seen[key] {
+			collisions[entry.serverName] = append(collisions[entry.serverName], ToolCollision{
+				Tool:       entry.toolName,
+				Name:       entry.fullName,
+				Resolution: CollisionSkipped,
+				Reason:     "duplicate tool name reported by server",
+			})
+			continue
+		}
+		seen[key] = true
+
+		collision, ok := registerAdapter(reg, entry)
+		if !ok {
+			continue
+		}
+		collisions[entry.serverName] = append(collisions[entry.serverName], collision)
+		if reported[entry.serverName+"\x00"+collisionKey(collision)] {
+			continue
+		}
+		slog.Warn("mcp tool name collision",
+			"server", entry.serverName,
+			"tool", entry.toolName,
+			"name", collision.Name,
+			"resolution", collision.Resolution,
+			"registered_as", collision.RegisteredAs,
+			"reason", collision.Reason,
+		)
+	}
+
+	if len(processed) > 0 {
+		if _, exists := reg.Get(ResourceToolName); !exists {
+			resourceTool, err := newResourceTool(m)
+			if err == nil {
+				err = reg.Register(resourceTool)
+			}
+			if err != nil {
+				slog.Warn("register mcp resource tool failed", "error", err)
+			}
 		}
 	}
+
+	// 只更新本轮参与注册的服务器；降级服务器保留上次的记录，其工具仍在注册表中。
+	m.mu.Lock()
+	for name := range processed {
+		if state := m.servers[name]; state != nil {
+			state.status.ToolCollisions = collisions[name]
+		}
+	}
+	m.mu.Unlock()
 	return nil
 }
 
+// reportedCollisions 返回已记录在服务器状态中的冲突，避免重连后重复告警。
+func (m *Manager) reportedCollisions() map[string]bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make(map[string]bool)
+	for name, state := range m.servers {
+		if state == nil {
+			continue
+		}
+		for _, c := range state.status.ToolCollisions {
+			out[name+"\x00"+collisionKey(c)] = true
+		}
+	}
+	return out
+}
+
+func collisionKey(c ToolCollision) string {
+	return strings.Join([]string{c.Tool, c.Resolution, c.RegisteredAs}, "\x00")
+}
+
+// registerAdapter 以 entry.fullName 注册工具，名称被其他工具占用时依次尝试 _2、_3 等后缀。
+// 返回的 ok 为 true 表示发生了冲突，collision 描述处理方式。
+func registerAdapter(reg *tools.Registry, entry toolAdapter) (collision ToolCollision, ok bool) {
+	requested := entry.fullName
+	collision = ToolCollision{Tool: entry.toolName, Name: requested}
+	for i := 1; i <= maxCollisionSuffix; i++ {
+		name := requested
+		if i > 1 {
+			name = fmt.Sprintf("%s_%d", requested, i)
+		}
+		if existing, exists := reg.Get(name); exists {
+			if sameMCPTool(existing, entry) {
+				// 此前已注册（可能带后缀），保持原有名称。
+				if name == requested {
+					return ToolCollision{}, false
+				}
+				collision.Resolution = CollisionRenamed
+				collision.RegisteredAs = name
+				return collision, true
+			}
+			continue
+		}
+
+		entry.fullName = name
+		if err := reg.Register(entry); err != nil {
+			collision.Resolution = CollisionSkipped
+			collision.Reason = err.Error()
+			return collision, true
+		}
+		if name == requested {
+			return ToolCollision{}, false
+		}
+		collision.Resolution = CollisionRenamed
+		collision.RegisteredAs = name
+		return collision, true
+	}
+	collision.Resolution = CollisionSkipped
+	collision.Reason = fmt.Sprintf("no free name after %d attempts", maxCollisionSuffix)
+	return collision, true
+}
+
+// sameMCPTool 报告注册表中的工具是否就是同一服务器上的同一 MCP 工具。
+func sameMCPTool(existing any, entry toolAdapter) bool {
+	adapter, ok := existing.(toolAdapter)
+	return ok && adapter.serverName == entry.serverName && adapter.toolName == entry.toolName
+}
+
+// ListResources 列出指定服务器公开的资源。
+func (m *Manager) ListResources(ctx context.Context, serverName string) ([]ResourceDefinition, error) {
+	client, err := m.ensureConnectedClient(ctx, serverName)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.callContext(ctx, serverName)
+	defer cancel()
+	defs, err := client.ListResources(callCtx)
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s list resources: %w", serverName, err)
+	}
+	return defs, nil
+}
+
+// ReadResource 读取指定服务器上的资源内容。
+func (m *Manager) ReadResource(ctx context.Context, serverName, uri string) ([]ResourceContent, error) {
+	client, err := m.ensureConnectedClient(ctx, serverName)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.callContext(ctx, serverName)
+	defer cancel()
+	contents, err := client.ReadResource(callCtx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s read resource %s: %w", serverName, uri, err)
+	}
+	return contents, nil
+}
+
+// ListPrompts 列出指定服务器提供的提示词模板。
+func (m *Manager) ListPrompts(ctx context.Context, serverName string) ([]PromptDefinition, error) {
+	client, err := m.ensureConnectedClient(ctx, serverName)
+	if err != nil {
+		return nil, err
+	}
+	callCtx, cancel := m.callContext(ctx, serverName)
+	defer cancel()
+	defs, err := client.ListPrompts(callCtx)
+	if err != nil {
+		return nil, fmt.Errorf("mcp server %s list prompts: %w", serverName, err)
+	}
+	return defs, nil
+}
+
+// GetPrompt 按参数展开指定服务器上的提示词模板。
+func (m *Manager) GetPrompt(ctx context.Context, serverName, name string, args map[string]string) (PromptResult, error) {
+	client, err := m.ensureConnectedClient(ctx, serverName)
+	if err != nil {
+		return PromptResult{}, err
+	}
+	callCtx, cancel := m.callContext(ctx, serverName)
+	defer cancel()
+	result, err := client.GetPrompt(callCtx, name, args)
+	if err != nil {
+		return PromptResult{}, fmt.Errorf("mcp server %s get prompt %s: %w", serverName, name, err)
+	}
+	return result, nil
+}
+
 // CallTool 将原始工具调用路由到选定的 MCP 服务器客户端。
 // 当服务器降级或工具调用失败时，CallTool 会尝试带退避的有界重连。
+// 每次调用受服务器的 call_timeout_seconds 限制；超时后服务器被标记为降级并立即重连，
+// 本次调用返回包装了 context.DeadlineExceeded 的错误，不再重试。
 func (m *Manager) CallTool(ctx context.Context, serverName, toolName, argsJSON string) (string, error) {
 	client, err := m.ensureConnectedClient(ctx, serverName)
 	if err != nil {
 		return "", err
 	}
 
-	result, callErr := client.CallTool(ctx, toolName, argsJSON)
+	callCtx, cancel := m.callContext(ctx, serverName)
+	result, callErr := client.CallTool(callCtx, toolName, argsJSON)
+	cancel()
 	if callErr == nil {
 		return normalizeToolResult(result), nil
 	}
+	if ctx.Err() != nil {
+		// 调用方自身取消或超时，服务器未必有问题，不做重连。
+		return "", callErr
+	}
+	if errors.Is(callErr, context.DeadlineExceeded) {
+		return "", m.handleCallTimeout(ctx, serverName, toolName)
+	}
 
-	reconnectErr := m.reconnectServer(ctx, serverName, fmt.Sprintf("tool call failed: %v", callErr))
+	reason := fmt.Sprintf("tool call failed: %v", callErr)
+	if errors.Is(callErr, errSessionExpired) {
+		reason = "session expired"
+	}
+	reconnectErr := m.reconnectServer(ctx, serverName, reason)
 	if reconnectErr != nil {
 		return "", fmt.Errorf("mcp server %s call failed: %v; reconnect failed: %w", serverName, callErr, reconnectErr)
 	}
@@ -128,7 +333,9 @@ func (m *Manager) CallTool(ctx context.Context, serverName, toolName, argsJSON s
 	if err != nil {
 		return "", err
 	}
-	result, callErr = client.CallTool(ctx, toolName, argsJSON)
+	callCtx, cancel = m.callContext(ctx, serverName)
+	result, callErr = client.CallTool(callCtx, toolName, argsJSON)
+	cancel()
 	if callErr != nil {
 		m.markDegraded(serverName, fmt.Sprintf("tool call failed after reconnect: %v", callErr))
 		return "", fmt.Errorf("mcp server %s call failed after reconnect: %w", serverName, callErr)
@@ -158,6 +365,89 @@ func (m *Manager) Statuses() []ServerStatus {
 	return out
 }
 
+// StderrTails 返回各 stdio 服务器最近的 stderr 输出，按服务器名称排序。
+// 已连接的服务器读取实时缓冲，降级的服务器返回断开前保存的快照。
+func (m *Manager) StderrTails() []ServerLogs {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]ServerLogs, 0, len(m.servers))
+	for name, state := range m.servers {
+		if state.cfg.Transport != TransportStdio {
+			continue
+		}
+		logs := ServerLogs{Server: name, Stderr: state.stderr}
+		if provider, ok := state.client.(StderrTailProvider); ok {
+			logs.Stderr = provider.StderrTail()
+			logs.Live = true
+		}
+		out = append(out, logs)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Server < out[j].Server })
+	return out
+}
+
+// Reconnect 立即按有界退避策略重连指定服务器，并返回重连后的状态。
+func (m *Manager) Reconnect(ctx context.Context, serverName string) (ServerStatus, error) {
+	if _, ok := m.serverConfig(serverName); !ok {
+		return ServerStatus{}, fmt.Errorf("mcp server not found: %s", serverName)
+	}
+	err := m.reconnectServer(ctx, serverName, "manual reconnect")
+	status, _ := m.status(serverName)
+	return status, err
+}
+
+// ReconnectDegraded 重连所有处于降级或未连接状态的服务器，返回这些服务器重连后的状态。
+// 单个服务器重连失败不会中断其余服务器，失败原因记录在对应状态的 Message 中。
+func (m *Manager) ReconnectDegraded(ctx context.Context) []ServerStatus {
+	out := make([]ServerStatus, 0)
+	for _, name := range m.serverNames() {
+		current, ok := m.status(name)
+		if !ok || (current.Connected && !current.Degraded) {
+			continue
+		}
+		status, _ := m.Reconnect(ctx, name)
+		out = append(out, status)
+	}
+	return out
+}
+
+func (m *Manager) status(name string) (ServerStatus, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state := m.servers[name]
+	if state == nil {
+		return ServerStatus{}, false
+	}
+	return state.status, true
+}
+
+// callContext 以服务器配置的调用超时包装 ctx。
+func (m *Manager) callContext(ctx context.Context, serverName string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, m.callTimeout(serverName))
+}
+
+func (m *Manager) callTimeout(serverName string) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if state := m.servers[serverName]; state != nil && state.cfg.CallTimeoutSeconds > 0 {
+		return time.Duration(state.cfg.CallTimeoutSeconds) * time.Second
+	}
+	return defaultCallTimeout
+}
+
+// handleCallTimeout 将超时的服务器标记为降级并尝试重连，返回本次调用的超时错误。
+func (m *Manager) handleCallTimeout(ctx context.Context, serverName, toolName string) error {
+	timeout := m.callTimeout(serverName)
+	reason := fmt.Sprintf("tool %s timed out after %s", toolName, timeout)
+	m.markDegraded(serverName, reason)
+	if err := m.reconnectServer(ctx, serverName, reason); err != nil {
+		slog.Warn("mcp reconnect after call timeout failed", "server", serverName, "tool", toolName, "error", err)
+	}
+	return fmt.Errorf("mcp server %s tool %s timed out after %s: %w", serverName, toolName, timeout, context.DeadlineExceeded)
+}
+
 func (m *Manager) ensureConnectedClient(ctx context.Context, serverName string) (Client, error) {
 	m.mu.RLock()
 	state := m.servers[serverName]
@@ -214,18 +504,39 @@ func (m *Manager) reconnectServer(ctx context.Context, serverName, reason string
 
 		client, discovered, err := m.connectAndDiscover(ctx, serverName, cfg)
 		if err == nil {
+			m.recordReconnect(serverName, attempt)
 			recoveredMsg := fmt.Sprintf("recovered after %d reconnect attempt(s)", attempt)
 			m.markConnected(serverName, client, discovered, recoveredMsg)
 			return nil
 		}
 		lastErr = err
+		var unsupported *UnsupportedProtocolError
+		if errors.As(err, &unsupported) {
+			// 协议版本不匹配不会因重试而改变。
+			m.recordReconnect(serverName, attempt)
+			m.markDegraded(serverName, fmt.Sprintf("%s; %v", strings.TrimSpace(reason), err))
+			return err
+		}
 	}
 
+	m.recordReconnect(serverName, reconnectMaxAttempts)
 	degradedMsg := fmt.Sprintf("%s; reconnect failed after %d attempts: %v", strings.TrimSpace(reason), reconnectMaxAttempts, lastErr)
 	m.markDegraded(serverName, degradedMsg)
 	return fmt.Errorf("reconnect failed after %d attempts: %w", reconnectMaxAttempts, lastErr)
 }
 
+func (m *Manager) recordReconnect(name string, attempts int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.servers[name]
+	if state == nil {
+		return
+	}
+	state.status.LastReconnectAt = time.Now().UTC()
+	state.status.ReconnectAttempts = attempts
+}
+
 func waitReconnectBackoff(ctx context.Context, retryIndex int) error {
 	if retryIndex <= 0 {
 		return nil
@@ -253,6 +564,21 @@ func (m *Manager) connectAndDiscover(ctx context.Context, serverName string, cfg
 	if err != nil {
 		return nil, nil, err
 	}
+	if provider, ok := client.(ServerInfoProvider); ok {
+		info := provider.ServerInfo()
+		m.recordServerInfo(serverName, info)
+		slog.Info("mcp server initialized",
+			"server", serverName,
+			"server_name", info.Name,
+			"server_version", info.Version,
+			"protocol_version", info.ProtocolVersion,
+			"capabilities", strings.Join(info.Capabilities, ","),
+		)
+		if err := checkProtocolVersion(info.ProtocolVersion); err != nil {
+			closeClient(client)
+			return nil, nil, err
+		}
+	}
 
 	discovered, err := client.ListTools(ctx)
 	if err != nil {
@@ -261,7 +587,19 @@ func (m *Manager) connectAndDiscover(ctx context.Context, serverName string, cfg
 	return client, discovered, nil
 }
 
+func (m *Manager) recordServerInfo(name string, info ServerInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state := m.servers[name]; state != nil {
+		state.status.ServerInfo = info
+	}
+}
+
 func (m *Manager) markConnected(name string, client Client, discovered []ToolDefinition, message string) {
+	var stale Client
+	defer func() { closeClient(stale) }()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -270,6 +608,10 @@ func (m *Manager) markConnected(name string, client Client, discovered []ToolDef
 		return
 	}
 
+	if state.client != client {
+		stale = state.client
+		state.keepStderr(stale)
+	}
 	state.client = client
 	state.tools = append([]ToolDefinition(nil), discovered...)
 	state.status.Connected = true
@@ -279,6 +621,9 @@ func (m *Manager) markConnected(name string, client Client, discovered []ToolDef
 }
 
 func (m *Manager) markDegraded(name, msg string) {
+	var stale Client
+	defer func() { closeClient(stale) }()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -287,6 +632,8 @@ func (m *Manager) markDegraded(name, msg string) {
 		return
 	}
 
+	stale = state.client
+	state.keepStderr(stale)
 	state.client = nil
 	state.tools = nil
 	state.status.Connected = false
@@ -295,6 +642,21 @@ func (m *Manager) markDegraded(name, msg string) {
 	state.status.Message = strings.TrimSpace(msg)
 }
 
+// closeClient 释放持有长连接的客户端（如 websocket），其他客户端忽略。
+// 调用方不应持有 m.mu，因为关闭可能需要等待进行中的请求结束。
+// keepStderr 在客户端被替换或关闭前保存其 stderr 尾部，便于排查断开原因。
+func (s *serverState) keepStderr(client Client) {
+	if provider, ok := client.(StderrTailProvider); ok {
+		s.stderr = provider.StderrTail()
+	}
+}
+
+func closeClient(client Client) {
+	if closer, ok := client.(io.Closer); ok {
+		_ = closer.Close()
+	}
+}
+
 func (m *Manager) collectRegisteredTools() []toolAdapter {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -327,6 +689,10 @@ func (m *Manager) connectorFor(transport string) Connector {
 		return m.connectors.Stdio
 	case TransportHTTPSSE:
 		return m.connectors.HTTPSSE
+	case TransportWebSocket:
+		return m.connectors.WebSocket
+	case TransportHTTPStream:
+		return m.connectors.HTTPStream
 	default:
 		return nil
 	}
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MEKXH/golem/internal/config"
 	"github.com/MEKXH/golem/internal/tools"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
 )
 
 type fakeConnector struct {
@@ -34,7 +37,15 @@ type fakeClient struct {
 	listErr    error
 	callErr    error
 	callResult any
+	hang       bool // CallTool 永不返回，直到 ctx 被取消
+	info       ServerInfo
 	calls      []fakeCall
+	resources  map[string]string // uri -> text
+	prompts    []PromptDefinition
+}
+
+func (f *fakeClient) ServerInfo() ServerInfo {
+	return f.info
 }
 
 func (f *fakeClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
@@ -44,6 +55,38 @@ func (f *fakeClient) ListTools(ctx context.Context) ([]ToolDefinition, error) {
 	return f.tools, nil
 }
 
+func (f *fakeClient) ListResources(ctx context.Context) ([]ResourceDefinition, error) {
+	if f.resources == nil {
+		return nil, errors.New("Method not found")
+	}
+	defs := make([]ResourceDefinition, 0, len(f.resources))
+	for uri := range f.resources {
+		defs = append(defs, ResourceDefinition{URI: uri, Name: uri})
+	}
+	return defs, nil
+}
+
+func (f *fakeClient) ReadResource(ctx context.Context, uri string) ([]ResourceContent, error) {
+	text, ok := f.resources[uri]
+	if !ok {
+		return nil, errors.New("resource not found")
+	}
+	return []ResourceContent{{URI: uri, Text: text}}, nil
+}
+
+func (f *fakeClient) ListPrompts(ctx context.Context) ([]PromptDefinition, error) {
+	return f.prompts, nil
+}
+
+func (f *fakeClient) GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error) {
+	for _, p := range f.prompts {
+		if p.Name == name {
+			return PromptResult{Messages: []PromptMessage{{Role: "user", Text: p.Description + " " + args["topic"]}}}, nil
+		}
+	}
+	return PromptResult{}, errors.New("prompt not found")
+}
+
 type sequenceConnector struct {
 	results []fakeConnectorResult
 	calls   int
@@ -69,6 +112,10 @@ func (f *fakeClient) CallTool(ctx context.Context, toolName string, argsJSON str
 		toolName: toolName,
 		argsJSON: argsJSON,
 	})
+	if f.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
 	if f.callErr != nil {
 		return nil, f.callErr
 	}
@@ -250,6 +297,91 @@ func TestManager_CallTool_ReconnectsAfterCallFailure(t *testing.T) {
 	if status.Degraded {
 		t.Fatalf("expected recovered status, got degraded: %+v", status)
 	}
+	if status.ReconnectAttempts != 1 || status.LastReconnectAt.IsZero() {
+		t.Fatalf("expected reconnect bookkeeping on status, got %+v", status)
+	}
+}
+
+func TestManager_CallTool_TimesOutNeverRespondingServer(t *testing.T) {
+	hungClient := &fakeClient{
+		tools: []ToolDefinition{{Name: "echo"}},
+		hang:  true,
+	}
+	recoveredClient := &fakeClient{
+		tools:      []ToolDefinition{{Name: "echo"}},
+		callResult: "ok",
+	}
+	connector := &sequenceConnector{
+		results: []fakeConnectorResult{
+			{client: hungClient},
+			{client: recoveredClient},
+		},
+	}
+
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"remote": {
+				Transport:          "http_sse",
+				URL:                "http://127.0.0.1:19001/sse",
+				CallTimeoutSeconds: 1,
+			},
+		},
+		Connectors{
+			Stdio:   &fakeConnector{},
+			HTTPSSE: connector,
+		},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	start := time.Now()
+	_, err := mgr.CallTool(context.Background(), "remote", "echo", `{}`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected call to time out promptly, took %s", elapsed)
+	}
+	if len(recoveredClient.calls) != 0 {
+		t.Fatalf("expected timed-out call not to be retried, got %d retries", len(recoveredClient.calls))
+	}
+	if connector.calls != 2 {
+		t.Fatalf("expected a reconnect after the timeout, got %d connect calls", connector.calls)
+	}
+
+	result, err := mgr.CallTool(context.Background(), "remote", "echo", `{}`)
+	if err != nil || result != "ok" {
+		t.Fatalf("expected reconnected client to serve the next call, got %q, %v", result, err)
+	}
+}
+
+func TestManager_CallTool_CallerCancellationSkipsReconnect(t *testing.T) {
+	connector := &fakeConnector{client: &fakeClient{
+		tools: []ToolDefinition{{Name: "echo"}},
+		hang:  true,
+	}}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"remote": {Transport: "http_sse", URL: "http://127.0.0.1:19001/sse"},
+		},
+		Connectors{Stdio: &fakeConnector{}, HTTPSSE: connector},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := mgr.CallTool(ctx, "remote", "echo", `{}`); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected caller deadline to surface, got %v", err)
+	}
+	if connector.calls != 1 {
+		t.Fatalf("expected no reconnect when the caller cancels, got %d connect calls", connector.calls)
+	}
+	if status := mgr.Statuses()[0]; status.Degraded {
+		t.Fatalf("expected server to stay healthy after caller cancellation, got %+v", status)
+	}
 }
 
 func TestManager_CallTool_RecoversFromStartupDegradedState(t *testing.T) {
@@ -302,6 +434,109 @@ func TestManager_CallTool_RecoversFromStartupDegradedState(t *testing.T) {
 	}
 }
 
+func TestManager_RecordsServerInfo(t *testing.T) {
+	info := ServerInfo{Name: "fs", Version: "2.1.0", ProtocolVersion: "2025-03-26", Capabilities: []string{"tools"}}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"localfs": {Transport: "stdio", Command: "fake"},
+		},
+		Connectors{Stdio: &fakeConnector{client: &fakeClient{
+			tools: []ToolDefinition{{Name: "read"}},
+			info:  info,
+		}}},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	status := mgr.Statuses()[0]
+	if !status.Connected || status.ServerInfo.Name != "fs" || status.ServerInfo.ProtocolVersion != "2025-03-26" {
+		t.Fatalf("expected connected status with server info, got %+v", status)
+	}
+}
+
+func TestManager_UnsupportedProtocolVersionDegrades(t *testing.T) {
+	client := &fakeClient{
+		tools: []ToolDefinition{{Name: "read"}},
+		info:  ServerInfo{Name: "future", ProtocolVersion: "2099-01-01"},
+	}
+	connector := &fakeConnector{client: client}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"future": {Transport: "stdio", Command: "fake"},
+		},
+		Connectors{Stdio: connector},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	status := mgr.Statuses()[0]
+	if !status.Degraded || !strings.Contains(status.Message, `unsupported mcp protocol version "2099-01-01"`) {
+		t.Fatalf("expected degraded status with protocol reason, got %+v", status)
+	}
+	if status.ServerInfo.ProtocolVersion != "2099-01-01" {
+		t.Fatalf("expected reported server info to be kept, got %+v", status.ServerInfo)
+	}
+
+	// 协议不匹配不会因重试而改变，重连只尝试一次。
+	if _, err := mgr.Reconnect(context.Background(), "future"); err == nil {
+		t.Fatal("expected reconnect to fail for unsupported protocol version")
+	}
+	if connector.calls != 2 {
+		t.Fatalf("expected a single reconnect attempt, got %d connect calls", connector.calls)
+	}
+
+	reg := tools.NewRegistry()
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("RegisterTools() error: %v", err)
+	}
+	if _, ok := reg.Get("mcp.future.read"); ok {
+		t.Fatal("expected tools from an unsupported server not to be registered")
+	}
+}
+
+type fakeStdioClient struct {
+	*fakeClient
+	stderr string
+}
+
+func (f *fakeStdioClient) StderrTail() string {
+	return f.stderr
+}
+
+func TestManager_StderrTailsKeepSnapshotAfterDegrade(t *testing.T) {
+	client := &fakeStdioClient{
+		fakeClient: &fakeClient{tools: []ToolDefinition{{Name: "read"}}},
+		stderr:     "warning: cache miss\n",
+	}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"localfs": {Transport: "stdio", Command: "fake"},
+			"remote":  {Transport: "http_sse", URL: "http://127.0.0.1:19001/sse"},
+		},
+		Connectors{
+			Stdio:   &fakeConnector{client: client},
+			HTTPSSE: &fakeConnector{client: &fakeClient{}},
+		},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	logs := mgr.StderrTails()
+	if len(logs) != 1 || logs[0].Server != "localfs" || !logs[0].Live || logs[0].Stderr != "warning: cache miss\n" {
+		t.Fatalf("expected live stderr for the stdio server only, got %+v", logs)
+	}
+
+	client.stderr = "fatal: out of memory\n"
+	mgr.markDegraded("localfs", "process exited")
+	logs = mgr.StderrTails()
+	if len(logs) != 1 || logs[0].Live || logs[0].Stderr != "fatal: out of memory\n" {
+		t.Fatalf("expected stderr snapshot after degrade, got %+v", logs)
+	}
+}
+
 func TestManager_NewManager_SkipsDisabledServers(t *testing.T) {
 	disabled := false
 	mgr := NewManager(
@@ -330,3 +565,192 @@ func TestManager_NewManager_SkipsDisabledServers(t *testing.T) {
 		t.Fatalf("expected enabled server to remain, got %+v", statuses[0])
 	}
 }
+
+func TestManager_ReconnectDegradedOnlyRetriesDegradedServers(t *testing.T) {
+	healthy := &fakeConnector{client: &fakeClient{tools: []ToolDefinition{{Name: "read"}}}}
+	flaky := &sequenceConnector{
+		results: []fakeConnectorResult{
+			{err: errors.New("connect timeout")},
+			{client: &fakeClient{tools: []ToolDefinition{{Name: "echo"}, {Name: "ping"}}}},
+		},
+	}
+
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"local":  {Transport: "stdio", Command: "local-mcp"},
+			"remote": {Transport: "http_sse", URL: "http://127.0.0.1:19003/sse"},
+		},
+		Connectors{Stdio: healthy, HTTPSSE: flaky},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	statuses := mgr.ReconnectDegraded(context.Background())
+	if len(statuses) != 1 || statuses[0].Name != "remote" {
+		t.Fatalf("expected only remote to be reconnected, got %+v", statuses)
+	}
+	if !statuses[0].Connected || statuses[0].ToolCount != 2 || statuses[0].ReconnectAttempts != 1 {
+		t.Fatalf("unexpected status after reconnect: %+v", statuses[0])
+	}
+	if healthy.calls != 1 {
+		t.Fatalf("expected healthy server not to be re-dialed, got %d connects", healthy.calls)
+	}
+
+	reg := tools.NewRegistry()
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("RegisterTools() error: %v", err)
+	}
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("expected repeated RegisterTools to skip existing tools, got %v", err)
+	}
+}
+
+func TestManager_ReconnectUnknownServer(t *testing.T) {
+	mgr := NewManager(map[string]config.MCPServerConfig{}, Connectors{})
+	if _, err := mgr.Reconnect(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for unknown server")
+	}
+}
+
+type namedTool struct{ name string }
+
+func (n namedTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: n.name, Desc: "built-in"}, nil
+}
+
+func (n namedTool) InvokableRun(ctx context.Context, argsJSON string, opts ...tool.Option) (string, error) {
+	return "builtin", nil
+}
+
+func TestManager_RegisterTools_ResolvesNameCollisions(t *testing.T) {
+	client := &fakeClient{
+		tools: []ToolDefinition{
+			{Name: "read", Description: "Read"},
+			{Name: "read", Description: "Read again"},
+			{Name: "write", Description: "Write"},
+		},
+		callResult: "ok",
+	}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{"localfs": {Transport: "stdio", Command: "localfs-mcp"}},
+		Connectors{Stdio: &fakeConnector{client: client}},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	reg := tools.NewRegistry()
+	if err := reg.Register(namedTool{name: "mcp.localfs.read"}); err != nil {
+		t.Fatalf("register built-in: %v", err)
+	}
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("expected collisions not to abort registration, got %v", err)
+	}
+
+	if _, ok := reg.Get("mcp.localfs.read_2"); !ok {
+		t.Fatal("expected colliding tool to be registered with a suffix")
+	}
+	if _, ok := reg.Get("mcp.localfs.write"); !ok {
+		t.Fatal("expected non-colliding tool to be registered")
+	}
+	out, err := reg.Execute(context.Background(), "mcp.localfs.read_2", `{}`)
+	if err != nil || out != "ok" {
+		t.Fatalf("expected renamed tool to route to the MCP server, got %q, %v", out, err)
+	}
+	if len(client.calls) != 1 || client.calls[0].toolName != "read" {
+		t.Fatalf("expected call to original tool name, got %+v", client.calls)
+	}
+
+	collisions := mgr.Statuses()[0].ToolCollisions
+	if len(collisions) != 2 {
+		t.Fatalf("expected rename and duplicate to be reported, got %+v", collisions)
+	}
+	if collisions[0].Resolution != CollisionRenamed || collisions[0].RegisteredAs != "mcp.localfs.read_2" {
+		t.Fatalf("unexpected rename record: %+v", collisions[0])
+	}
+	if collisions[1].Resolution != CollisionSkipped || collisions[1].Reason == "" {
+		t.Fatalf("unexpected duplicate record: %+v", collisions[1])
+	}
+
+	// 重复注册保持原有名称，不会再生成新的后缀。
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("repeated RegisterTools error: %v", err)
+	}
+	if _, ok := reg.Get("mcp.localfs.read_3"); ok {
+		t.Fatal("expected repeated registration to reuse the existing suffix")
+	}
+	if got := mgr.Statuses()[0].ToolCollisions; len(got) != 2 || got[0].RegisteredAs != "mcp.localfs.read_2" {
+		t.Fatalf("expected collisions to stay stable across registrations, got %+v", got)
+	}
+}
+
+func TestManager_RegisterTools_CrossServerDottedNames(t *testing.T) {
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{
+			"a":   {Transport: "stdio", Command: "a-mcp"},
+			"a.b": {Transport: "stdio", Command: "ab-mcp"},
+		},
+		Connectors{Stdio: &sequenceConnector{results: []fakeConnectorResult{
+			{client: &fakeClient{tools: []ToolDefinition{{Name: "b.c"}}}},
+			{client: &fakeClient{tools: []ToolDefinition{{Name: "c"}}}},
+		}}},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+
+	reg := tools.NewRegistry()
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("RegisterTools() error: %v", err)
+	}
+	if _, ok := reg.Get("mcp.a.b.c"); !ok {
+		t.Fatal("expected first tool under its plain name")
+	}
+	if _, ok := reg.Get("mcp.a.b.c_2"); !ok {
+		t.Fatal("expected second server's tool under a suffixed name")
+	}
+	statuses := mgr.Statuses()
+	if len(statuses[0].ToolCollisions) != 0 || len(statuses[1].ToolCollisions) != 1 {
+		t.Fatalf("expected the collision to be reported on server a.b only, got %+v", statuses)
+	}
+}
+
+func TestManager_ResourceToolListsAndReadsResources(t *testing.T) {
+	client := &fakeClient{
+		tools:     []ToolDefinition{{Name: "read"}},
+		resources: map[string]string{"docs://guide": "Guide body"},
+		prompts:   []PromptDefinition{{Name: "summarize", Description: "Summarize"}},
+	}
+	mgr := NewManager(
+		map[string]config.MCPServerConfig{"docs": {Transport: "stdio", Command: "docs-mcp"}},
+		Connectors{Stdio: &fakeConnector{client: client}},
+	)
+	if err := mgr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error: %v", err)
+	}
+	reg := tools.NewRegistry()
+	if err := mgr.RegisterTools(reg); err != nil {
+		t.Fatalf("RegisterTools() error: %v", err)
+	}
+
+	listing, err := reg.Execute(context.Background(), ResourceToolName, `{}`)
+	if err != nil {
+		t.Fatalf("list resources error: %v", err)
+	}
+	if !strings.Contains(listing, "[docs] docs://guide") {
+		t.Fatalf("expected resource listing, got %q", listing)
+	}
+	body, err := reg.Execute(context.Background(), ResourceToolName, `{"server":"docs","uri":"docs://guide"}`)
+	if err != nil || body != "Guide body" {
+		t.Fatalf("expected resource body, got %q, %v", body, err)
+	}
+	if _, err := reg.Execute(context.Background(), ResourceToolName, `{"uri":"docs://guide"}`); err == nil {
+		t.Fatal("expected error when uri is given without server")
+	}
+
+	prompt, err := mgr.GetPrompt(context.Background(), "docs", "summarize", map[string]string{"topic": "MCP"})
+	if err != nil || len(prompt.Messages) != 1 || prompt.Messages[0].Text != "Summarize MCP" {
+		t.Fatalf("unexpected prompt result: %+v, %v", prompt, err)
+	}
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// ResourceToolName 是读取 MCP 资源的内置工具名称。
+const ResourceToolName = "read_mcp_resource"
+
+// ReadResourceInput read_mcp_resource 的参数。
+type ReadResourceInput struct {
+	Server string `json:"server,omitempty" jsonschema:"description=MCP server name; empty lists resources from every connected server"`
+	URI    string `json:"uri,omitempty" jsonschema:"description=Resource URI to read; empty lists the resources the server exposes"`
+}
+
+// newResourceTool 创建 read_mcp_resource 工具：未给出 uri 时列出资源，否则读取资源内容。
+func newResourceTool(m *Manager) (tool.InvokableTool, error) {
+	return utils.InferTool(
+		ResourceToolName,
+		"List or read resources (documents, files, data) exposed by connected MCP servers. Call without uri to list available resources, then call with server and uri to read one",
+		func(ctx context.Context, in ReadResourceInput) (string, error) {
+			server := strings.TrimSpace(in.Server)
+			uri := strings.TrimSpace(in.URI)
+			if uri == "" {
+				return m.describeResources(ctx, server)
+			}
+			if server == "" {
+				return "", fmt.Errorf("server is required when uri is set")
+			}
+			contents, err := m.ReadResource(ctx, server, uri)
+			if err != nil {
+				return "", err
+			}
+			return formatResourceContents(contents), nil
+		},
+	)
+}
+
+// describeResources 列出指定服务器（为空时为全部已连接服务器）公开的资源。
+func (m *Manager) describeResources(ctx context.Context, server string) (string, error) {
+	servers := []string{server}
+	if server == "" {
+		servers = servers[:0]
+		for _, status := range m.Statuses() {
+			if status.Connected && !status.Degraded {
+				servers = append(servers, status.Name)
+			}
+		}
+	}
+
+	var b strings.Builder
+	for _, name := range servers {
+		defs, err := m.ListResources(ctx, name)
+		if err != nil {
+			if server != "" {
+				return "", err
+			}
+			fmt.Fprintf(&b, "[%s] resources unavailable: %v\n", name, err)
+			continue
+		}
+		for _, def := range defs {
+			fmt.Fprintf(&b, "[%s] %s", name, def.URI)
+			if def.Name != "" {
+				fmt.Fprintf(&b, " (%s)", def.Name)
+			}
+			if def.MIMEType != "" {
+				fmt.Fprintf(&b, " [%s]", def.MIMEType)
+			}
+			if def.Description != "" {
+				fmt.Fprintf(&b, ": %s", def.Description)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() == 0 {
+		return "No MCP resources available", nil
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// formatResourceContents 拼接资源的文本内容；二进制内容只给出类型与大小。
+func formatResourceContents(contents []ResourceContent) string {
+	parts := make([]string, 0, len(contents))
+	for _, c := range contents {
+		switch {
+		case c.Text != "":
+			parts = append(parts, c.Text)
+		case c.Blob != "":
+			mime := c.MIMEType
+			if mime == "" {
+				mime = "application/octet-stream"
+			}
+			parts = append(parts, fmt.Sprintf("[binary resource %s (%s), %d base64 bytes omitted]", c.URI, mime, len(c.Blob)))
+		}
+	}
+	if len(parts) == 0 {
+		return "(no output)"
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// listResources 调用 resources/list 并解析资源列表。
+func listResources(ctx context.Context, invoker rpcInvoker) ([]ResourceDefinition, error) {
+	result, err := invoker.invoke(ctx, "resources/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	items, err := resultItems(result, "resources")
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make([]ResourceDefinition, 0, len(items))
+	for _, obj := range items {
+		uri := strings.TrimSpace(stringValue(obj["uri"]))
+		if uri == "" {
+			continue
+		}
+		defs = append(defs, ResourceDefinition{
+			URI:         uri,
+			Name:        strings.TrimSpace(stringValue(obj["name"])),
+			Description: strings.TrimSpace(stringValue(obj["description"])),
+			MIMEType:    strings.TrimSpace(stringValue(obj["mimeType"])),
+		})
+	}
+	return defs, nil
+}
+
+// readResource 调用 resources/read 读取资源内容。
+func readResource(ctx context.Context, invoker rpcInvoker, uri string) ([]ResourceContent, error) {
+	uri = strings.TrimSpace(uri)
+	if uri == "" {
+		return nil, fmt.Errorf("resource uri is required")
+	}
+	result, err := invoker.invoke(ctx, "resources/read", map[string]any{"uri": uri})
+	if err != nil {
+		return nil, err
+	}
+	items, err := resultItems(result, "contents")
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]ResourceContent, 0, len(items))
+	for _, obj := range items {
+		contents = append(contents, ResourceContent{
+			URI:      strings.TrimSpace(stringValue(obj["uri"])),
+			MIMEType: strings.TrimSpace(stringValue(obj["mimeType"])),
+			Text:     stringValue(obj["text"]),
+			Blob:     stringValue(obj["blob"]),
+		})
+	}
+	return contents, nil
+}
+
+// listPrompts 调用 prompts/list 并解析提示词模板列表。
+func listPrompts(ctx context.Context, invoker rpcInvoker) ([]PromptDefinition, error) {
+	result, err := invoker.invoke(ctx, "prompts/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	items, err := resultItems(result, "prompts")
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make([]PromptDefinition, 0, len(items))
+	for _, obj := range items {
+		name := strings.TrimSpace(stringValue(obj["name"]))
+		if name == "" {
+			continue
+		}
+		def := PromptDefinition{
+			Name:        name,
+			Description: strings.TrimSpace(stringValue(obj["description"])),
+		}
+		rawArgs, _ := obj["arguments"].([]any)
+		for _, raw := range rawArgs {
+			arg, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			required, _ := arg["required"].(bool)
+			def.Arguments = append(def.Arguments, PromptArgument{
+				Name:        strings.TrimSpace(stringValue(arg["name"])),
+				Description: strings.TrimSpace(stringValue(arg["description"])),
+				Required:    required,
+			})
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// getPrompt 调用 prompts/get 展开提示词模板；非文本内容（图片、嵌入资源等）会被忽略。
+func getPrompt(ctx context.Context, invoker rpcInvoker, name string, args map[string]string) (PromptResult, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return PromptResult{}, fmt.Errorf("prompt name is required")
+	}
+	if args == nil {
+		args = map[string]string{}
+	}
+	result, err := invoker.invoke(ctx, "prompts/get", map[string]any{"name": name, "arguments": args})
+	if err != nil {
+		return PromptResult{}, err
+	}
+	items, err := resultItems(result, "messages")
+	if err != nil {
+		return PromptResult{}, err
+	}
+
+	out := PromptResult{Messages: make([]PromptMessage, 0, len(items))}
+	if obj, ok := result.(map[string]any); ok {
+		out.Description = strings.TrimSpace(stringValue(obj["description"]))
+	}
+	for _, obj := range items {
+		content, _ := obj["content"].(map[string]any)
+		if strings.ToLower(strings.TrimSpace(stringValue(content["type"]))) != "text" {
+			continue
+		}
+		out.Messages = append(out.Messages, PromptMessage{
+			Role: strings.TrimSpace(stringValue(obj["role"])),
+			Text: stringValue(content["text"]),
+		})
+	}
+	return out, nil
+}
+
+// resultItems 取出 JSON-RPC 结果中 key 对应的对象数组；缺少该字段时视为空列表。
+func resultItems(result any, key string) ([]map[string]any, error) {
+	if result == nil {
+		return nil, nil
+	}
+	obj, ok := result.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s result shape", key)
+	}
+	raw, exists := obj[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s result shape", key)
+	}
+	items := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			items = append(items, m)
+		}
+	}
+	return items, nil
+}
@@ -2,13 +2,16 @@ package mcp
 
 import (
 	"context"
+	"time"
 
 	"github.com/MEKXH/golem/internal/config"
 )
 
 const (
-	TransportStdio   = "stdio"    // 标准输入输出传输协议
-	TransportHTTPSSE = "http_sse" // HTTP SSE 传输协议
+	TransportStdio      = "stdio"       // 标准输入输出传输协议
+	TransportHTTPSSE    = "http_sse"    // HTTP SSE 传输协议
+	TransportWebSocket  = "websocket"   // WebSocket 传输协议
+	TransportHTTPStream = "http_stream" // Streamable HTTP 传输协议（单端点，Mcp-Session-Id 会话）
 )
 
 // ToolDefinition 描述从 MCP 服务器发现的工具元数据。
@@ -17,12 +20,79 @@ type ToolDefinition struct {
 	Description string // 工具功能描述
 }
 
+// ResourceDefinition 描述 MCP 服务器通过 resources/list 公开的资源。
+type ResourceDefinition struct {
+	URI         string `json:"uri"`                   // 资源地址
+	Name        string `json:"name"`                  // 资源名称
+	Description string `json:"description,omitempty"` // 资源描述
+	MIMEType    string `json:"mime_type,omitempty"`   // 内容类型
+}
+
+// ResourceContent 是 resources/read 返回的一段资源内容；文本资源填 Text，二进制资源填 Blob（base64）。
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// PromptArgument 描述提示词模板的一个参数。
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptDefinition 描述 MCP 服务器通过 prompts/list 公开的提示词模板。
+type PromptDefinition struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptMessage 是展开后的提示词中的一条消息，只保留文本内容。
+type PromptMessage struct {
+	Role string `json:"role"` // user 或 assistant
+	Text string `json:"text"`
+}
+
+// PromptResult 是 prompts/get 按参数展开后的提示词。
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
 // Client 定义了与 MCP 服务器交互的客户端接口。
 type Client interface {
 	// ListTools 获取服务器提供的所有工具列表。
 	ListTools(ctx context.Context) ([]ToolDefinition, error)
 	// CallTool 调用指定的工具并返回结果。
 	CallTool(ctx context.Context, toolName, argsJSON string) (any, error)
+	// ListResources 获取服务器公开的资源列表。
+	ListResources(ctx context.Context) ([]ResourceDefinition, error)
+	// ReadResource 读取指定 URI 的资源内容。
+	ReadResource(ctx context.Context, uri string) ([]ResourceContent, error)
+	// ListPrompts 获取服务器提供的提示词模板列表。
+	ListPrompts(ctx context.Context) ([]PromptDefinition, error)
+	// GetPrompt 按参数展开指定的提示词模板。
+	GetPrompt(ctx context.Context, name string, args map[string]string) (PromptResult, error)
+}
+
+// ServerInfoProvider 由保存了 initialize 握手结果的客户端实现，管理器据此记录服务器信息并校验协议版本。
+type ServerInfoProvider interface {
+	ServerInfo() ServerInfo
+}
+
+// StderrTailProvider 由保留了服务器 stderr 尾部输出的客户端（stdio 传输）实现。
+type StderrTailProvider interface {
+	StderrTail() string
+}
+
+// ServerLogs 是一个 stdio MCP 服务器最近的 stderr 输出，供 golem mcp status --logs 展示。
+type ServerLogs struct {
+	Server string `json:"server"`
+	Stderr string `json:"stderr"`
+	Live   bool   `json:"live"` // false 表示进程已关闭，内容来自最近一次断开前的快照
 }
 
 // Connector 定义了建立 MCP 连接并返回客户端实例的接口。
@@ -33,16 +103,45 @@ type Connector interface {
 
 // Connectors 包含了支持的所有传输协议的连接器实现。
 type Connectors struct {
-	Stdio   Connector // stdio 传输连接器
-	HTTPSSE Connector // http_sse 传输连接器
+	Stdio      Connector // stdio 传输连接器
+	HTTPSSE    Connector // http_sse 传输连接器
+	WebSocket  Connector // websocket 传输连接器
+	HTTPStream Connector // http_stream 传输连接器
 }
 
 // ServerStatus 表示 MCP 服务器在管理器中的当前运行状态。
 type ServerStatus struct {
-	Name      string // 服务器名称
-	Transport string // 使用的传输协议
-	Connected bool   // 是否已成功连接
-	Degraded  bool   // 是否处于降级（异常）状态
-	ToolCount int    // 发现的工具数量
-	Message   string // 状态描述消息或错误信息
+	Name              string    `json:"name"`                       // 服务器名称
+	Transport         string    `json:"transport"`                  // 使用的传输协议
+	Connected         bool      `json:"connected"`                  // 是否已成功连接
+	Degraded          bool      `json:"degraded"`                   // 是否处于降级（异常）状态
+	ToolCount         int       `json:"tool_count"`                 // 发现的工具数量
+	Message           string    `json:"message"`                    // 状态描述消息或错误信息
+	LastReconnectAt   time.Time `json:"last_reconnect_at,omitzero"` // 最近一次重连结束的时间，从未重连时为零值
+	ReconnectAttempts int       `json:"reconnect_attempts"`         // 最近一次重连实际尝试的次数
+
+	ToolCollisions []ToolCollision `json:"tool_collisions,omitempty"` // 注册工具时发生的名称冲突及处理方式
+	ServerInfo     ServerInfo      `json:"server_info,omitzero"`      // 最近一次 initialize 握手中服务器上报的信息
+}
+
+// ServerInfo 记录 MCP 服务器在 initialize 响应中上报的身份、协议版本与能力。
+type ServerInfo struct {
+	Name            string   `json:"name,omitempty"`
+	Version         string   `json:"version,omitempty"`
+	ProtocolVersion string   `json:"protocol_version,omitempty"`
+	Capabilities    []string `json:"capabilities,omitempty"` // 能力名称，如 tools、resources、prompts，按字母排序
+}
+
+const (
+	CollisionRenamed = "renamed" // 追加数字后缀后注册
+	CollisionSkipped = "skipped" // 未注册该工具
+)
+
+// ToolCollision 记录一个 MCP 工具在注册时与已有工具重名的情况及处理结果。
+type ToolCollision struct {
+	Tool         string `json:"tool"`                    // 服务器上的工具名称
+	Name         string `json:"name"`                    // 期望注册的名称，如 mcp.<server>.<tool>
+	Resolution   string `json:"resolution"`              // renamed 或 skipped
+	RegisteredAs string `json:"registered_as,omitempty"` // renamed 时实际注册的名称
+	Reason       string `json:"reason,omitempty"`        // skipped 时的原因
 }
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// archiveDirName 是归档日记的目录名（位于记忆目录下），不参与召回扫描。
+const archiveDirName = "archive"
+
+// PruneOptions 定义日记保留策略，零值字段表示不限制。
+type PruneOptions struct {
+	MaxAgeDays int       // 早于该天数的日记归档
+	MaxDiaries int       // 记忆目录中最多保留的日记天数，超出时归档最早的日记
+	Now        time.Time // 当前时间，零值表示 time.Now()
+	DryRun     bool      // 只返回将被归档的日记，不修改文件
+}
+
+// PruneResult 记录一次清理归档的日记。
+type PruneResult struct {
+	Archived []string // 归档的日记日期，从旧到新
+	Kept     int      // 保留在记忆目录中的日记数
+}
+
+// PruneDiaries 按保留策略将旧日记压缩归档到 memory/archive/YYYY-MM-DD.md.gz 并从记忆目录移除。
+// 当天的日记不会被归档；同一日期再次归档时追加为新的 gzip 成员，不覆盖已有内容。
+func (m *Manager) PruneDiaries(opts PruneOptions) (PruneResult, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	diaries, err := m.collectDiaryFiles()
+	if err != nil {
+		return PruneResult{}, err
+	}
+	sort.Slice(diaries, func(i, j int) bool { return diaries[i].date < diaries[j].date })
+
+	today := now.Format("2006-01-02")
+	cutoff := ""
+	if opts.MaxAgeDays > 0 {
+		cutoff = now.AddDate(0, 0, -opts.MaxAgeDays).Format("2006-01-02")
+	}
+	excess := 0
+	if opts.MaxDiaries > 0 && len(diaries) > opts.MaxDiaries {
+		excess = len(diaries) - opts.MaxDiaries
+	}
+
+	var result PruneResult
+	for i, d := range diaries {
+		expired := cutoff != "" && d.date < cutoff
+		if d.date == today || (!expired && i >= excess) {
+			result.Kept++
+			continue
+		}
+		if !opts.DryRun {
+			if err := m.archiveDiary(d); err != nil {
+				return result, fmt.Errorf("archive diary %s: %w", d.date, err)
+			}
+		}
+		result.Archived = append(result.Archived, d.date)
+	}
+	return result, nil
+}
+
+func (m *Manager) archiveDiary(d diaryFile) error {
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(m.memoryDir, archiveDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, d.date+".md.gz"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	zw.Name = d.date + ".md"
+	if _, err := zw.Write(data); err != nil {
+		zw.Close()
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(d.path)
+}
+
+// readArchivedDiary 读取归档日记的全部内容；未归档时返回 os.ErrNotExist。
+func (m *Manager) readArchivedDiary(date string) (string, error) {
+	f, err := os.Open(filepath.Join(m.memoryDir, archiveDirName, date+".md.gz"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -2,7 +2,9 @@
 package memory
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,6 +12,8 @@ import (
 	"time"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/embedding"
 )
 
 const (
@@ -26,7 +30,8 @@ type DiaryEntry struct {
 
 // RecallItem 表示带来源归属的单条回忆片段。
 type RecallItem struct {
-	Source  string // 来源（如 "diary_recent", "long_term"）
+	Source  string // 来源（如 "diary_recent", "long_term", "semantic"）
+	Section string // 长期记忆分区（仅长期记忆片段）
 	Date    string // 关联日期（可选）
 	Path    string // 来源文件路径
 	Excerpt string // 摘录内容
@@ -50,15 +55,35 @@ type Manager struct {
 	workspacePath string
 	memoryDir     string
 	memoryFile    string
+
+	embedder       embedding.Embedder // 为空时不做语义召回
+	embeddingModel string
+	semanticLimit  int
+	maxIndexChunks int
 }
 
 // NewManager 为指定的工作区创建一个记忆管理器。
 func NewManager(workspacePath string) *Manager {
+	return NewManagerWithOptions(workspacePath, Options{})
+}
+
+// NewManagerWithOptions 创建记忆管理器；opts.Embedder 非空时召回额外使用语义相似度检索。
+func NewManagerWithOptions(workspacePath string, opts Options) *Manager {
 	memoryDir := filepath.Join(workspacePath, memoryDirName)
+	if opts.SemanticLimit <= 0 {
+		opts.SemanticLimit = 3
+	}
+	if opts.MaxIndexChunks <= 0 {
+		opts.MaxIndexChunks = 2000
+	}
 	return &Manager{
-		workspacePath: workspacePath,
-		memoryDir:     memoryDir,
-		memoryFile:    filepath.Join(memoryDir, memoryFileName),
+		workspacePath:  workspacePath,
+		memoryDir:      memoryDir,
+		memoryFile:     filepath.Join(memoryDir, memoryFileName),
+		embedder:       opts.Embedder,
+		embeddingModel: opts.EmbeddingModel,
+		semanticLimit:  opts.SemanticLimit,
+		maxIndexChunks: opts.MaxIndexChunks,
 	}
 }
 
@@ -75,9 +100,13 @@ func (m *Manager) Ensure() error {
 	return nil
 }
 
-// ReadLongTerm 读取长期记忆文件的全文内容。
-func (m *Manager) ReadLongTerm() (string, error) {
-	data, err := os.ReadFile(m.memoryFile)
+// ReadLongTerm 读取长期记忆指定分区的全文内容；section 为空表示默认分区 (MEMORY.md)。
+func (m *Manager) ReadLongTerm(section string) (string, error) {
+	_, path, err := m.sectionPath(section)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "", nil
@@ -87,12 +116,19 @@ func (m *Manager) ReadLongTerm() (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
-// WriteLongTerm 覆盖写入长期记忆文件。
-func (m *Manager) WriteLongTerm(content string) error {
+// WriteLongTerm 覆盖写入长期记忆的指定分区，其他分区不受影响；section 为空表示默认分区 (MEMORY.md)。
+func (m *Manager) WriteLongTerm(section, content string) error {
+	_, path, err := m.sectionPath(section)
+	if err != nil {
+		return err
+	}
 	if err := m.Ensure(); err != nil {
 		return err
 	}
-	return os.WriteFile(m.memoryFile, []byte(strings.TrimSpace(content)), 0644)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strings.TrimSpace(content)), 0644)
 }
 
 // AppendDiary 在当前日期的日记文件中追加一条记录。
@@ -124,18 +160,29 @@ func (m *Manager) AppendDiaryAt(ts time.Time, entry string) (string, error) {
 	return path, nil
 }
 
-// ReadDiary 读取指定日期的日记内容。
+// ReadDiary 读取指定日期的日记内容；日记已归档时从 memory/archive/ 读取，
+// 归档后同一天又有新记录时两者按时间顺序合并。
 func (m *Manager) ReadDiary(date string) (string, error) {
 	date = strings.TrimSpace(date)
 	if date == "" {
 		return "", fmt.Errorf("date is required")
 	}
+	if !isValidDate(date) {
+		return "", fmt.Errorf("date must use YYYY-MM-DD format, got %q", date)
+	}
 	path := filepath.Join(m.memoryDir, date+".md")
 	data, err := os.ReadFile(path)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return "", err
 	}
-	return strings.TrimSpace(string(data)), nil
+	archived, archiveErr := m.readArchivedDiary(date)
+	if archiveErr != nil && !os.IsNotExist(archiveErr) {
+		return "", archiveErr
+	}
+	if err != nil && archiveErr != nil {
+		return "", err
+	}
+	return strings.TrimSpace(archived + "\n" + string(data)), nil
 }
 
 // ReadRecentDiaries 读取最近几天的日记分录。
@@ -176,7 +223,8 @@ func (m *Manager) ReadRecentDiaries(limit int) ([]DiaryEntry, error) {
 	return out, nil
 }
 
-// RecallContext 使用“最近优先 + 关键词命中”策略从记忆中检索相关的上下文片段。
+// RecallContext 使用“最近优先 + 关键词命中”策略从记忆中检索相关的上下文片段；
+// 配置了向量模型时再合并语义相似的片段，向量接口失败则只返回关键词结果。
 func (m *Manager) RecallContext(query string, recentLimit, keywordLimit int) (RecallResult, error) {
 	if recentLimit <= 0 {
 		recentLimit = 3
@@ -230,27 +278,15 @@ func (m *Manager) RecallContext(query string, recentLimit, keywordLimit int) (Re
 
 	keywords := extractRecallKeywords(result.Query)
 	if len(keywords) == 0 {
+		m.appendSemanticItems(&result, nil)
 		result.RecallCount = len(result.Items)
 		return result, nil
 	}
 
-	// 3. 检索长期记忆
-	longTerm, err := m.ReadLongTerm()
-	if err != nil {
+	// 3. 检索长期记忆各分区（默认分区在前），最多 keywordLimit 个分区
+	if _, err := m.appendSectionItems(&result, keywords, nil, keywordLimit); err != nil {
 		return RecallResult{}, err
 	}
-	if longTerm != "" {
-		longTermLower := strings.ToLower(longTerm)
-		if containsAnyKeyword(longTermLower, keywords) {
-			result.SourceHits["long_term"]++
-			result.Items = append(result.Items, RecallItem{
-				Source:  "long_term",
-				Date:    "",
-				Path:    m.memoryFile,
-				Excerpt: extractKeywordExcerpt(longTerm, longTermLower, keywords, 380),
-			})
-		}
-	}
 
 	// 4. 对剩余日记进行关键词搜索
 	addedKeywordItems := 0
@@ -288,10 +324,57 @@ func (m *Manager) RecallContext(query string, recentLimit, keywordLimit int) (Re
 		})
 	}
 
+	m.appendSemanticItems(&result, nil)
 	result.RecallCount = len(result.Items)
 	return result, nil
 }
 
+// appendSemanticItems 追加语义召回的片段，跳过已被前面摘录完整包含的片段；accept 非空时只保留其接受的片段。
+func (m *Manager) appendSemanticItems(result *RecallResult, accept func(indexedChunk) bool) {
+	if m.embedder == nil || result.Query == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), semanticTimeout)
+	defer cancel()
+	chunks, err := m.semanticRecall(ctx, result.Query)
+	if err != nil {
+		slog.Warn("semantic memory recall failed; using keyword recall only", "error", err)
+		return
+	}
+
+	added := 0
+	for _, c := range chunks {
+		if added >= m.semanticLimit {
+			break
+		}
+		if (accept != nil && !accept(c)) || excerptCovered(result.Items, c.Text) {
+			continue
+		}
+		section := ""
+		if c.Source == "long_term" {
+			section = sectionFromFile(c.File)
+		}
+		result.SourceHits["semantic"]++
+		result.Items = append(result.Items, RecallItem{
+			Source:  "semantic",
+			Section: section,
+			Date:    c.Date,
+			Path:    filepath.Join(m.memoryDir, filepath.FromSlash(c.File)),
+			Excerpt: clipText(c.Text, 300),
+		})
+		added++
+	}
+}
+
+func excerptCovered(items []RecallItem, text string) bool {
+	for _, item := range items {
+		if strings.Contains(item.Excerpt, text) {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) collectDiaryFiles() ([]diaryFile, error) {
 	entries, err := os.ReadDir(m.memoryDir)
 	if err != nil {
@@ -333,20 +416,67 @@ func extractRecallKeywords(query string) []string {
 	})
 	seen := map[string]bool{}
 	keywords := make([]string, 0, len(parts))
-	for _, p := range parts {
-		p = strings.TrimSpace(p)
-		if p == "" || seen[p] {
-			continue
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keywords = append(keywords, k)
 		}
-		if utf8RuneLen(p) < 2 {
-			continue
+	}
+	for _, p := range parts {
+		for _, run := range splitScriptRuns(strings.TrimSpace(p)) {
+			if !isCJK([]rune(run)[0]) {
+				// 拉丁等以空格分词的文字：保持原有规则，忽略单字符词
+				if utf8RuneLen(run) >= 2 {
+					add(run)
+				}
+				continue
+			}
+			for _, gram := range cjkBigrams(run) {
+				add(gram)
+			}
 		}
-		seen[p] = true
-		keywords = append(keywords, p)
 	}
 	return keywords
 }
 
+// isCJK 报告字符是否属于不以空格分词的中日韩文字。
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// splitScriptRuns 将词切分为连续的 CJK 与非 CJK 片段，如 "api接口" 切为 "api" 与 "接口"。
+func splitScriptRuns(word string) []string {
+	var runs []string
+	start := 0
+	prev := false
+	for i, r := range word {
+		cur := isCJK(r)
+		if i > start && cur != prev {
+			runs = append(runs, word[start:i])
+			start = i
+		}
+		prev = cur
+	}
+	if start < len(word) {
+		runs = append(runs, word[start:])
+	}
+	return runs
+}
+
+// cjkBigrams 对 CJK 片段做二元切分："产品团队" 得到 "产品"、"品团"、"团队"。
+// 单字片段原样保留，避免丢失单字查询。
+func cjkBigrams(run string) []string {
+	runes := []rune(run)
+	if len(runes) <= 2 {
+		return []string{run}
+	}
+	grams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+	return grams
+}
+
 func containsAnyKeyword(contentLower string, keywords []string) bool {
 	for _, keyword := range keywords {
 		if strings.Contains(contentLower, keyword) {
@@ -383,6 +513,13 @@ func extractKeywordExcerpt(content, contentLower string, keywords []string, maxL
 	if end > len(content) {
 		end = len(content)
 	}
+	// 对齐到字符边界，避免截断多字节的中日韩字符
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	for end < len(content) && !utf8.RuneStart(content[end]) {
+		end--
+	}
 	snippet := strings.TrimSpace(content[start:end])
 	if start > 0 {
 		snippet = "..." + snippet
@@ -398,6 +535,9 @@ func clipText(content string, maxLen int) string {
 	if maxLen <= 0 || len(content) <= maxLen {
 		return content
 	}
+	for maxLen > 0 && !utf8.RuneStart(content[maxLen]) {
+		maxLen--
+	}
 	return strings.TrimSpace(content[:maxLen]) + "..."
 }
 
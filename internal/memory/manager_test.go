@@ -1,20 +1,25 @@
 package memory
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/embedding"
 )
 
 func TestReadWriteLongTermMemory(t *testing.T) {
 	mgr := NewManager(t.TempDir())
-	if err := mgr.WriteLongTerm("remember this"); err != nil {
+	if err := mgr.WriteLongTerm("", "remember this"); err != nil {
 		t.Fatalf("WriteLongTerm error: %v", err)
 	}
 
-	got, err := mgr.ReadLongTerm()
+	got, err := mgr.ReadLongTerm("")
 	if err != nil {
 		t.Fatalf("ReadLongTerm error: %v", err)
 	}
@@ -70,7 +75,7 @@ func TestRecallContext_RecentAndKeywordSources(t *testing.T) {
 	workspace := t.TempDir()
 	mgr := NewManager(workspace)
 
-	if err := mgr.WriteLongTerm("payment service timeout mitigation runbook"); err != nil {
+	if err := mgr.WriteLongTerm("", "payment service timeout mitigation runbook"); err != nil {
 		t.Fatalf("WriteLongTerm: %v", err)
 	}
 
@@ -105,7 +110,7 @@ func TestRecallContext_NoKeywordDoesNotInjectLongTerm(t *testing.T) {
 	workspace := t.TempDir()
 	mgr := NewManager(workspace)
 
-	if err := mgr.WriteLongTerm("kernel panic troubleshooting notes"); err != nil {
+	if err := mgr.WriteLongTerm("", "kernel panic troubleshooting notes"); err != nil {
 		t.Fatalf("WriteLongTerm: %v", err)
 	}
 	if _, err := mgr.AppendDiaryAt(time.Date(2026, 2, 15, 8, 0, 0, 0, time.UTC), "daily summary"); err != nil {
@@ -120,3 +125,295 @@ func TestRecallContext_NoKeywordDoesNotInjectLongTerm(t *testing.T) {
 		t.Fatalf("expected no long_term hit for unrelated query, got %+v", recall.SourceHits)
 	}
 }
+
+// conceptEmbedder 把文本映射到少量概念维度，模拟能识别同义改写的向量模型。
+type conceptEmbedder struct {
+	calls [][]string
+	err   error
+}
+
+func (e *conceptEmbedder) EmbedStrings(_ context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.calls = append(e.calls, texts)
+	concepts := [][]string{
+		{"car", "vehicle", "sedan"},
+		{"dentist", "teeth", "dental"},
+		{"deploy", "release", "ship"},
+	}
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		vec := make([]float64, len(concepts)+1)
+		vec[len(concepts)] = 0.1
+		lower := strings.ToLower(text)
+		for dim, words := range concepts {
+			for _, w := range words {
+				if strings.Contains(lower, w) {
+					vec[dim] = 1
+				}
+			}
+		}
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func TestRecallContext_SemanticRecallFindsParaphrases(t *testing.T) {
+	workspace := t.TempDir()
+	embedder := &conceptEmbedder{}
+	mgr := NewManagerWithOptions(workspace, Options{Embedder: embedder, EmbeddingModel: "test"})
+
+	_ = mgr.Ensure()
+	_ = os.WriteFile(filepath.Join(workspace, "memory", "2026-02-10.md"), []byte("- [09:00:00] bought a blue sedan from the dealer\n- [12:00:00] lunch with Ann\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(workspace, "memory", "2026-02-11.md"), []byte("- [10:00:00] weekly sync"), 0o644)
+
+	recall, err := mgr.RecallContext("which vehicle did I get", 1, 3)
+	if err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if recall.SourceHits["diary_keyword"] != 0 {
+		t.Fatalf("expected no keyword hit for paraphrased query, got %+v", recall.SourceHits)
+	}
+	if recall.SourceHits["semantic"] != 1 {
+		t.Fatalf("expected one semantic hit, got %+v", recall.SourceHits)
+	}
+	last := recall.Items[len(recall.Items)-1]
+	if last.Source != "semantic" || last.Date != "2026-02-10" || !strings.Contains(last.Excerpt, "blue sedan") || strings.Contains(last.Excerpt, "lunch") {
+		t.Fatalf("unexpected semantic item: %+v", last)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "memory", indexFileName)); err != nil {
+		t.Fatalf("expected embedding index on disk: %v", err)
+	}
+
+	// 追加日记后只为新增条目调用向量接口。
+	embedder.calls = nil
+	if _, err := mgr.AppendDiaryAt(time.Date(2026, 2, 11, 18, 0, 0, 0, time.UTC), "booked the dentist"); err != nil {
+		t.Fatalf("AppendDiaryAt: %v", err)
+	}
+	if _, err := mgr.RecallContext("teeth appointment", 1, 3); err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if len(embedder.calls) != 2 || len(embedder.calls[0]) != 1 || !strings.Contains(embedder.calls[0][0], "dentist") {
+		t.Fatalf("expected one incremental chunk plus the query, got %v", embedder.calls)
+	}
+}
+
+func TestRecallContext_SemanticFailureFallsBackToKeywords(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManagerWithOptions(workspace, Options{Embedder: &conceptEmbedder{err: errors.New("boom")}})
+
+	if err := mgr.WriteLongTerm("", "payment service timeout mitigation runbook"); err != nil {
+		t.Fatalf("WriteLongTerm: %v", err)
+	}
+	recall, err := mgr.RecallContext("payment timeout", 3, 3)
+	if err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if recall.SourceHits["long_term"] != 1 || recall.SourceHits["semantic"] != 0 {
+		t.Fatalf("expected keyword-only recall, got %+v", recall.SourceHits)
+	}
+}
+
+func TestCollectChunks_BoundsIndexToNewestEntries(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManagerWithOptions(workspace, Options{MaxIndexChunks: 3})
+
+	_ = mgr.WriteLongTerm("", "profile")
+	for _, d := range []string{"2026-02-10", "2026-02-11"} {
+		_ = os.WriteFile(filepath.Join(workspace, "memory", d+".md"), []byte("- [09:00:00] "+d+" first\n- [10:00:00] "+d+" second\n"), 0o644)
+	}
+
+	chunks, err := mgr.collectChunks()
+	if err != nil {
+		t.Fatalf("collectChunks: %v", err)
+	}
+	var texts []string
+	for _, c := range chunks {
+		texts = append(texts, c.Text)
+	}
+	want := []string{"profile", "- [10:00:00] 2026-02-11 second", "- [09:00:00] 2026-02-11 first"}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected chunks: %q", texts)
+	}
+}
+
+func TestLongTermSections_AreIsolated(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManager(workspace)
+
+	if err := mgr.WriteLongTerm("", "core facts"); err != nil {
+		t.Fatalf("WriteLongTerm default: %v", err)
+	}
+	if err := mgr.WriteLongTerm("preferences", "likes dark mode"); err != nil {
+		t.Fatalf("WriteLongTerm preferences: %v", err)
+	}
+	if err := mgr.WriteLongTerm("projects/foo", "foo uses postgres"); err != nil {
+		t.Fatalf("WriteLongTerm projects/foo: %v", err)
+	}
+	if err := mgr.WriteLongTerm("preferences", "likes light mode"); err != nil {
+		t.Fatalf("rewrite preferences: %v", err)
+	}
+
+	if got, _ := mgr.ReadLongTerm(DefaultSection); got != "core facts" {
+		t.Fatalf("default section clobbered: %q", got)
+	}
+	if got, _ := mgr.ReadLongTerm("projects/foo"); got != "foo uses postgres" {
+		t.Fatalf("unexpected projects/foo content: %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "memory", "projects", "foo.md")); err != nil {
+		t.Fatalf("expected section file on disk: %v", err)
+	}
+
+	sections, err := mgr.ListSections()
+	if err != nil {
+		t.Fatalf("ListSections: %v", err)
+	}
+	if strings.Join(sections, ",") != "default,preferences,projects/foo" {
+		t.Fatalf("unexpected sections: %v", sections)
+	}
+
+	for _, bad := range []string{"../x", "a b", "2026-02-11", "a//b"} {
+		if err := mgr.WriteLongTerm(bad, "x"); err == nil {
+			t.Fatalf("expected invalid section error for %q", bad)
+		}
+	}
+}
+
+func TestRecallSection_ScopesToNamespace(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManager(workspace)
+
+	_ = mgr.WriteLongTerm("", "postgres is the default database")
+	_ = mgr.WriteLongTerm("projects/foo", "foo migrated to postgres 16")
+	_ = mgr.WriteLongTerm("projects/bar", "bar still on mysql")
+	_ = mgr.WriteLongTerm("preferences", "prefers postgres")
+
+	recall, err := mgr.RecallSection("postgres version", "projects", 3)
+	if err != nil {
+		t.Fatalf("RecallSection: %v", err)
+	}
+	if recall.RecallCount != 1 || recall.Items[0].Section != "projects/foo" {
+		t.Fatalf("expected only projects/foo, got %+v", recall.Items)
+	}
+
+	all, err := mgr.RecallContext("postgres", 3, 3)
+	if err != nil {
+		t.Fatalf("RecallContext: %v", err)
+	}
+	if all.SourceHits["long_term"] != 3 || all.Items[0].Section != DefaultSection {
+		t.Fatalf("expected default section first and three long-term hits, got %+v", all.Items)
+	}
+}
+
+func TestPruneDiaries_ArchivesOldAndExcessDiaries(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManager(workspace)
+	memDir := filepath.Join(workspace, "memory")
+	_ = mgr.Ensure()
+	for _, d := range []string{"2026-01-01", "2026-02-01", "2026-02-20", "2026-02-25", "2026-02-28"} {
+		_ = os.WriteFile(filepath.Join(memDir, d+".md"), []byte("- [09:00:00] note "+d+"\n"), 0o644)
+	}
+	now := time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC)
+
+	dry, err := mgr.PruneDiaries(PruneOptions{MaxAgeDays: 30, MaxDiaries: 3, Now: now, DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneDiaries dry run: %v", err)
+	}
+	if strings.Join(dry.Archived, ",") != "2026-01-01,2026-02-01" || dry.Kept != 3 {
+		t.Fatalf("unexpected dry run result: %+v", dry)
+	}
+	if _, err := os.Stat(filepath.Join(memDir, "2026-01-01.md")); err != nil {
+		t.Fatalf("dry run must not move files: %v", err)
+	}
+
+	result, err := mgr.PruneDiaries(PruneOptions{MaxDiaries: 2, Now: now})
+	if err != nil {
+		t.Fatalf("PruneDiaries: %v", err)
+	}
+	if strings.Join(result.Archived, ",") != "2026-01-01,2026-02-01,2026-02-20" || result.Kept != 2 {
+		t.Fatalf("unexpected prune result: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(memDir, "archive", "2026-01-01.md.gz")); err != nil {
+		t.Fatalf("expected compressed archive: %v", err)
+	}
+
+	recent, err := mgr.ReadRecentDiaries(10)
+	if err != nil || len(recent) != 2 {
+		t.Fatalf("expected archived diaries to leave the hot scan, got %+v (%v)", recent, err)
+	}
+	sections, _ := mgr.ListSections()
+	if len(sections) != 1 {
+		t.Fatalf("archive must not show up as a section: %v", sections)
+	}
+
+	// 归档后同一天又写入新记录：读取时合并，再次归档时追加而不覆盖。
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-20.md"), []byte("- [18:00:00] late note\n"), 0o644)
+	content, err := mgr.ReadDiary("2026-02-20")
+	if err != nil || !strings.Contains(content, "note 2026-02-20") || !strings.Contains(content, "late note") {
+		t.Fatalf("expected merged archived diary, got %q (%v)", content, err)
+	}
+	if _, err := mgr.PruneDiaries(PruneOptions{MaxDiaries: 2, Now: now}); err != nil {
+		t.Fatalf("PruneDiaries again: %v", err)
+	}
+	content, err = mgr.ReadDiary("2026-02-20")
+	if err != nil || !strings.Contains(content, "note 2026-02-20") || !strings.Contains(content, "late note") {
+		t.Fatalf("expected both archive members, got %q (%v)", content, err)
+	}
+}
+
+func TestExtractRecallKeywords_CJKBigrams(t *testing.T) {
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"investigate payment timeout", []string{"investigate", "payment", "timeout"}},
+		{"a go test", []string{"go", "test"}},
+		{"产品团队", []string{"产品", "品团", "团队"}},
+		{"猫", []string{"猫"}},
+		{"API接口 超时", []string{"api", "接口", "超时"}},
+		{"会議の議事録を確認", []string{"会議", "議の", "の議", "議事", "事録", "録を", "を確", "確認"}},
+	}
+	for _, tc := range cases {
+		got := extractRecallKeywords(tc.query)
+		if strings.Join(got, "|") != strings.Join(tc.want, "|") {
+			t.Fatalf("extractRecallKeywords(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestRecallContext_MatchesChineseAndMixedQueries(t *testing.T) {
+	workspace := t.TempDir()
+	mgr := NewManager(workspace)
+	memDir := filepath.Join(workspace, "memory")
+	_ = mgr.Ensure()
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-10.md"), []byte("- [09:00:00] 和产品团队讨论了支付接口超时的问题"), 0o644)
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-11.md"), []byte("- [09:00:00] weekly sync"), 0o644)
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-12.md"), []byte("- [09:00:00] deploy finished"), 0o644)
+
+	for _, query := range []string{"上次支付超时是怎么回事", "payment 接口超时 follow-up"} {
+		recall, err := mgr.RecallContext(query, 1, 3)
+		if err != nil {
+			t.Fatalf("RecallContext(%q): %v", query, err)
+		}
+		if recall.SourceHits["diary_keyword"] != 1 {
+			t.Fatalf("expected keyword hit for %q, got %+v", query, recall.SourceHits)
+		}
+		if last := recall.Items[len(recall.Items)-1]; last.Date != "2026-02-10" {
+			t.Fatalf("expected 2026-02-10 diary for %q, got %+v", query, last)
+		}
+	}
+}
+
+func TestRecallExcerpts_DoNotSplitMultibyteRunes(t *testing.T) {
+	content := strings.Repeat("记忆召回", 100) + "支付超时" + strings.Repeat("日记内容", 100)
+	keywords := extractRecallKeywords("支付超时")
+	for maxLen := 280; maxLen < 290; maxLen++ {
+		if excerpt := extractKeywordExcerpt(content, strings.ToLower(content), keywords, maxLen); !utf8.ValidString(excerpt) || !strings.Contains(excerpt, "支付超时") {
+			t.Fatalf("invalid excerpt for maxLen %d: %q", maxLen, excerpt)
+		}
+		if clipped := clipText(content, maxLen); !utf8.ValidString(clipped) {
+			t.Fatalf("invalid clipped text for maxLen %d: %q", maxLen, clipped)
+		}
+	}
+}
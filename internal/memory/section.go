@@ -0,0 +1,184 @@
+package memory
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultSection 是默认长期记忆分区，对应 memory/MEMORY.md。
+	DefaultSection = "default"
+
+	maxSectionDepth       = 4  // 分区名最多包含的层级数
+	maxSectionSegmentRune = 64 // 分区名每一级的最大长度
+)
+
+// NormalizeSection 校验并规范化长期记忆分区名。分区名由 "/" 分隔的若干级组成（如 projects/foo），
+// 每级只能包含字母、数字、- 与 _；空字符串、default 与 MEMORY 均表示默认分区。
+func NormalizeSection(section string) (string, error) {
+	section = strings.Trim(strings.TrimSpace(section), "/")
+	if section == "" || section == DefaultSection || strings.EqualFold(section, "memory") {
+		return DefaultSection, nil
+	}
+	segments := strings.Split(section, "/")
+	if len(segments) > maxSectionDepth {
+		return "", fmt.Errorf("invalid memory section %q: at most %d levels", section, maxSectionDepth)
+	}
+	for _, seg := range segments {
+		if seg == "" || len([]rune(seg)) > maxSectionSegmentRune {
+			return "", fmt.Errorf("invalid memory section %q", section)
+		}
+		for _, r := range seg {
+			if !isSectionRune(r) {
+				return "", fmt.Errorf("invalid memory section %q: use letters, digits, '-' and '_' separated by '/'", section)
+			}
+		}
+	}
+	if len(segments) == 1 && isValidDate(section) {
+		return "", fmt.Errorf("invalid memory section %q: date names are reserved for diaries", section)
+	}
+	if segments[0] == archiveDirName {
+		return "", fmt.Errorf("invalid memory section %q: %s/ is reserved for archived diaries", section, archiveDirName)
+	}
+	return section, nil
+}
+
+func isSectionRune(r rune) bool {
+	return r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// sectionPath 返回分区的规范名与文件路径：默认分区为 memory/MEMORY.md，其余为 memory/<section>.md。
+func (m *Manager) sectionPath(section string) (string, string, error) {
+	name, err := NormalizeSection(section)
+	if err != nil {
+		return "", "", err
+	}
+	if name == DefaultSection {
+		return name, m.memoryFile, nil
+	}
+	return name, filepath.Join(m.memoryDir, filepath.FromSlash(name)+".md"), nil
+}
+
+// sectionFromFile 将记忆目录下的相对文件名（斜杠分隔）映射为分区名；不是分区文件时返回空字符串。
+func sectionFromFile(rel string) string {
+	if rel == memoryFileName {
+		return DefaultSection
+	}
+	name, ok := strings.CutSuffix(rel, ".md")
+	if !ok {
+		return ""
+	}
+	if normalized, err := NormalizeSection(name); err != nil || normalized != name {
+		return ""
+	}
+	return name
+}
+
+// ListSections 列出已有的长期记忆分区，默认分区在前，其余按名称排序。
+func (m *Manager) ListSections() ([]string, error) {
+	var sections []string
+	err := filepath.WalkDir(m.memoryDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.memoryDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path == filepath.Join(m.memoryDir, archiveDirName) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(m.memoryDir, path)
+		if err != nil {
+			return nil
+		}
+		if name := sectionFromFile(filepath.ToSlash(rel)); name != "" && name != DefaultSection {
+			sections = append(sections, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(sections)
+	return append([]string{DefaultSection}, sections...), nil
+}
+
+// inNamespace 报告分区是否属于命名空间 ns（等于 ns 或以 "ns/" 开头）。
+func inNamespace(section, ns string) bool {
+	return section == ns || strings.HasPrefix(section, ns+"/")
+}
+
+// appendSectionItems 按关键词检索长期记忆分区，最多追加 limit 条；inScope 非空时只检索其接受的分区。
+// keywords 为空时直接摘录分区开头。返回追加的条数。
+func (m *Manager) appendSectionItems(result *RecallResult, keywords []string, inScope func(string) bool, limit int) (int, error) {
+	sections, err := m.ListSections()
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, section := range sections {
+		if added >= limit {
+			break
+		}
+		if inScope != nil && !inScope(section) {
+			continue
+		}
+		_, path, _ := m.sectionPath(section)
+		content, err := m.ReadLongTerm(section)
+		if err != nil {
+			return added, err
+		}
+		if content == "" {
+			continue
+		}
+		excerpt := clipText(content, 380)
+		if len(keywords) > 0 {
+			contentLower := strings.ToLower(content)
+			if !containsAnyKeyword(contentLower, keywords) {
+				continue
+			}
+			excerpt = extractKeywordExcerpt(content, contentLower, keywords, 380)
+		}
+		result.SourceHits["long_term"]++
+		result.Items = append(result.Items, RecallItem{
+			Source:  "long_term",
+			Section: section,
+			Path:    path,
+			Excerpt: excerpt,
+		})
+		added++
+	}
+	return added, nil
+}
+
+// RecallSection 只在命名空间 section 内检索长期记忆：section 为 projects 时同时检索 projects/foo 等子分区。
+// 查询没有可用关键词时按分区名顺序摘录；配置了向量模型时再合并该命名空间内语义相似的片段。
+func (m *Manager) RecallSection(query, section string, limit int) (RecallResult, error) {
+	ns, err := NormalizeSection(section)
+	if err != nil {
+		return RecallResult{}, err
+	}
+	if limit <= 0 {
+		limit = 3
+	}
+	result := RecallResult{
+		Query:      strings.TrimSpace(query),
+		SourceHits: map[string]int{},
+	}
+	inScope := func(s string) bool { return inNamespace(s, ns) }
+	if _, err := m.appendSectionItems(&result, extractRecallKeywords(result.Query), inScope, limit); err != nil {
+		return RecallResult{}, err
+	}
+	m.appendSemanticItems(&result, func(c indexedChunk) bool {
+		return c.Source == "long_term" && inScope(sectionFromFile(c.File))
+	})
+	result.RecallCount = len(result.Items)
+	return result, nil
+}
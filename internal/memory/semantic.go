@@ -0,0 +1,289 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+const (
+	indexFileName = "embeddings.json" // 向量索引缓存文件名（位于记忆目录下）
+
+	semanticTimeout       = 15 * time.Second // 单次语义召回（含增量向量化）的时限
+	semanticMinScore      = 0.2              // 低于该余弦相似度的片段不召回
+	semanticBatchSize     = 64               // 每次向量接口请求的片段数
+	semanticMaxChunkRunes = 1000             // 送入向量接口的单个片段最大字符数
+)
+
+// indexMu 串行化索引的同步与落盘；loadedIndexes 缓存已读取的索引，避免每次召回都解析磁盘文件。
+var (
+	indexMu       sync.Mutex
+	loadedIndexes = map[string]*embeddingIndex{}
+)
+
+// Options 配置记忆管理器的可选能力。
+type Options struct {
+	Embedder       embedding.Embedder // 向量模型；为空时只使用关键词召回
+	EmbeddingModel string             // 向量模型标识，变化时重建索引
+	SemanticLimit  int                // 每次召回的语义片段上限，默认 3
+	MaxIndexChunks int                // 索引片段上限，默认 2000
+}
+
+// embeddingIndex 是向量索引在磁盘上的格式。
+type embeddingIndex struct {
+	Model  string         `json:"model"`
+	Chunks []indexedChunk `json:"chunks"`
+}
+
+// indexedChunk 是索引中的单个片段：长期记忆分区的一个段落或日记中的一条记录。
+type indexedChunk struct {
+	Source string    `json:"source"` // long_term 或 diary
+	Date   string    `json:"date,omitempty"`
+	File   string    `json:"file"` // 记忆目录下的相对文件名（斜杠分隔）
+	Hash   string    `json:"hash"`
+	Text   string    `json:"text"`
+	Vector []float32 `json:"vector"`
+}
+
+// semanticRecall 同步向量索引并返回与查询最相似的片段（按相似度降序）。
+func (m *Manager) semanticRecall(ctx context.Context, query string) ([]indexedChunk, error) {
+	chunks, err := m.collectChunks()
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	indexed, err := m.syncIndex(ctx, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := m.embedder.EmbedStrings(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("embed query: expected 1 vector, got %d", len(vectors))
+	}
+	queryVec := toFloat32(vectors[0])
+
+	type scored struct {
+		chunk indexedChunk
+		score float64
+	}
+	hits := make([]scored, 0, len(indexed))
+	for _, c := range indexed {
+		if score := cosineSimilarity(queryVec, c.Vector); score >= semanticMinScore {
+			hits = append(hits, scored{chunk: c, score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].score > hits[j].score })
+
+	out := make([]indexedChunk, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.chunk)
+	}
+	return out, nil
+}
+
+// syncIndex 让索引与当前记忆文件一致：复用文本未变片段的向量，只为新增片段调用向量接口，
+// 已删除的片段随之移出索引。有变化时写回磁盘。
+func (m *Manager) syncIndex(ctx context.Context, chunks []indexedChunk) ([]indexedChunk, error) {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	path := filepath.Join(m.memoryDir, indexFileName)
+	idx := loadedIndexes[path]
+	if idx == nil {
+		idx = readIndex(path)
+	}
+	cached := make(map[string][]float32, len(idx.Chunks))
+	if idx.Model == m.embeddingModel {
+		for _, c := range idx.Chunks {
+			cached[c.Hash] = c.Vector
+		}
+	}
+
+	var missing []int
+	for i := range chunks {
+		if vec, ok := cached[chunks[i].Hash]; ok {
+			chunks[i].Vector = vec
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	for start := 0; start < len(missing); start += semanticBatchSize {
+		batch := missing[start:min(start+semanticBatchSize, len(missing))]
+		texts := make([]string, len(batch))
+		for i, ci := range batch {
+			texts[i] = chunks[ci].Text
+		}
+		vectors, err := m.embedder.EmbedStrings(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embed memory chunks: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return nil, fmt.Errorf("embed memory chunks: expected %d vectors, got %d", len(batch), len(vectors))
+		}
+		for i, ci := range batch {
+			chunks[ci].Vector = toFloat32(vectors[i])
+		}
+	}
+
+	if len(missing) > 0 || len(chunks) != len(idx.Chunks) || idx.Model != m.embeddingModel {
+		idx = &embeddingIndex{Model: m.embeddingModel, Chunks: chunks}
+		if err := writeIndex(path, idx); err != nil {
+			return nil, err
+		}
+	}
+	loadedIndexes[path] = idx
+	return idx.Chunks, nil
+}
+
+// collectChunks 切分记忆文件：长期记忆各分区按空行分段，日记按条目切分。长期记忆在前，日记从新到旧，
+// 总数不超过 maxIndexChunks，超出时丢弃最早的日记片段。
+func (m *Manager) collectChunks() ([]indexedChunk, error) {
+	var chunks []indexedChunk
+	add := func(source, date, file, text string) bool {
+		if len(chunks) >= m.maxIndexChunks {
+			return false
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return true
+		}
+		if runes := []rune(text); len(runes) > semanticMaxChunkRunes {
+			text = string(runes[:semanticMaxChunkRunes])
+		}
+		sum := sha256.Sum256([]byte(text))
+		chunks = append(chunks, indexedChunk{
+			Source: source,
+			Date:   date,
+			File:   file,
+			Hash:   hex.EncodeToString(sum[:]),
+			Text:   text,
+		})
+		return true
+	}
+
+	sections, err := m.ListSections()
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		_, path, _ := m.sectionPath(section)
+		rel, _ := filepath.Rel(m.memoryDir, path)
+		longTerm, err := m.ReadLongTerm(section)
+		if err != nil {
+			return nil, err
+		}
+		for _, para := range strings.Split(strings.ReplaceAll(longTerm, "\r\n", "\n"), "\n\n") {
+			if !add("long_term", "", filepath.ToSlash(rel), para) {
+				return chunks, nil
+			}
+		}
+	}
+
+	diaries, err := m.collectDiaryFiles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(diaries, func(i, j int) bool { return diaries[i].date > diaries[j].date })
+	for _, d := range diaries {
+		data, err := os.ReadFile(d.path)
+		if err != nil {
+			continue
+		}
+		entries := splitDiaryEntries(string(data))
+		for i := len(entries) - 1; i >= 0; i-- {
+			if !add("diary", d.date, filepath.Base(d.path), entries[i]) {
+				return chunks, nil
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// splitDiaryEntries 按 AppendDiaryAt 写入的 "- [HH:MM:SS] ..." 行切分日记；续行归入上一条。
+func splitDiaryEntries(content string) []string {
+	var entries []string
+	var current strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			entries = append(entries, s)
+		}
+		current.Reset()
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, "- [") {
+			flush()
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+	}
+	flush()
+	return entries
+}
+
+func readIndex(path string) *embeddingIndex {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &embeddingIndex{}
+	}
+	var idx embeddingIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return &embeddingIndex{}
+	}
+	return &idx
+}
+
+func writeIndex(path string, idx *embeddingIndex) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func toFloat32(v []float64) []float32 {
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(f)
+	}
+	return out
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// PrometheusContentType 是 Prometheus 文本格式（0.0.4）的 Content-Type。
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus 以 Prometheus 文本格式输出当前指标：工具执行计数与延迟直方图、通道发送计数、
+// 记忆召回计数以及模型 Token 用量。计数自进程启动起累计。
+func (m *RuntimeMetrics) WritePrometheus(w io.Writer) error {
+	var snap RuntimeSnapshot
+	buckets := make([]int64, len(latencyBucketUpperBoundsMs)+1)
+	if m != nil {
+		m.mu.Lock()
+		snap = m.snap
+		copy(buckets, m.buckets)
+		m.mu.Unlock()
+	}
+
+	bw := bufio.NewWriter(w)
+	counter := func(name, help string, value int64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+	}
+	gauge := func(name, help string, value int64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, value)
+	}
+
+	counter("golem_tool_executions_total", "Tool executions.", snap.Tool.Total)
+	counter("golem_tool_errors_total", "Tool executions that returned an error.", snap.Tool.Errors)
+	counter("golem_tool_timeouts_total", "Tool executions that timed out.", snap.Tool.Timeouts)
+	counter("golem_tool_invalid_args_total", "Tool executions rejected because of invalid arguments.", snap.Tool.InvalidArgs)
+
+	const histogram = "golem_tool_latency_seconds"
+	fmt.Fprintf(bw, "# HELP %s Tool execution latency.\n# TYPE %s histogram\n", histogram, histogram)
+	var cumulative int64
+	for i, upper := range latencyBucketUpperBoundsMs {
+		cumulative += buckets[i]
+		fmt.Fprintf(bw, "%s_bucket{le=\"%s\"} %d\n", histogram, formatSeconds(upper), cumulative)
+	}
+	fmt.Fprintf(bw, "%s_bucket{le=\"+Inf\"} %d\n", histogram, snap.Tool.Total)
+	fmt.Fprintf(bw, "%s_sum %s\n", histogram, formatSeconds(snap.Tool.TotalLatencyMs))
+	fmt.Fprintf(bw, "%s_count %d\n", histogram, snap.Tool.Total)
+
+	counter("golem_channel_send_attempts_total", "Outbound channel send attempts, including retries.", snap.Channel.SendAttempts)
+	counter("golem_channel_send_failures_total", "Outbound channel send attempts that failed.", snap.Channel.SendFailures)
+
+	counter("golem_memory_recalls_total", "Memory recall requests.", snap.Memory.Recalls)
+	counter("golem_memory_empty_recalls_total", "Memory recalls that found nothing.", snap.Memory.EmptyRecalls)
+	counter("golem_memory_recalled_items_total", "Memory items returned by recalls.", snap.Memory.TotalItems)
+
+	counter("golem_model_calls_total", "Model calls that reported token usage.", snap.Model.Calls)
+	counter("golem_model_prompt_tokens_total", "Prompt tokens consumed.", snap.Model.PromptTokens)
+	counter("golem_model_completion_tokens_total", "Completion tokens generated.", snap.Model.CompletionTokens)
+	counter("golem_model_tokens_total", "Prompt and completion tokens.", snap.Model.TotalTokens)
+
+	updated := int64(0)
+	if !snap.UpdatedAt.IsZero() {
+		updated = snap.UpdatedAt.Unix()
+	}
+	gauge("golem_metrics_updated_timestamp_seconds", "Unix time of the last recorded metric.", updated)
+
+	return bw.Flush()
+}
+
+// formatSeconds 将毫秒转换为秒的十进制字符串，如 250 → "0.25"。
+func formatSeconds(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+}
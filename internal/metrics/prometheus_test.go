@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuntimeMetrics_WritePrometheus(t *testing.T) {
+	recorder := NewRuntimeMetrics(t.TempDir())
+	defer recorder.Close()
+
+	_, _ = recorder.RecordToolExecution(20*time.Millisecond, "", nil, false)
+	_, _ = recorder.RecordToolExecution(300*time.Millisecond, "", context.DeadlineExceeded, true)
+	_, _ = recorder.RecordToolExecution(45*time.Second, "", nil, false)
+	_, _ = recorder.RecordChannelSend(true)
+	_, _ = recorder.RecordChannelSend(false)
+	_, _ = recorder.RecordModelUsage(100, 40)
+
+	var sb strings.Builder
+	if err := recorder.WritePrometheus(&sb); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"# TYPE golem_tool_executions_total counter\ngolem_tool_executions_total 3\n",
+		"golem_tool_errors_total 1\n",
+		"golem_tool_timeouts_total 1\n",
+		"golem_tool_invalid_args_total 0\n",
+		"# TYPE golem_tool_latency_seconds histogram\n",
+		`golem_tool_latency_seconds_bucket{le="0.01"} 0` + "\n",
+		`golem_tool_latency_seconds_bucket{le="0.025"} 1` + "\n",
+		`golem_tool_latency_seconds_bucket{le="0.5"} 2` + "\n",
+		`golem_tool_latency_seconds_bucket{le="30"} 2` + "\n",
+		`golem_tool_latency_seconds_bucket{le="+Inf"} 3` + "\n",
+		"golem_tool_latency_seconds_sum 45.32\n",
+		"golem_tool_latency_seconds_count 3\n",
+		"golem_channel_send_attempts_total 2\n",
+		"golem_channel_send_failures_total 1\n",
+		"golem_model_prompt_tokens_total 100\n",
+		"golem_model_completion_tokens_total 40\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestRuntimeMetrics_WritePrometheusNilRecorder(t *testing.T) {
+	var recorder *RuntimeMetrics
+	var sb strings.Builder
+	if err := recorder.WritePrometheus(&sb); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	if !strings.Contains(sb.String(), "golem_tool_executions_total 0\n") {
+		t.Fatalf("expected zero-valued metrics, got:\n%s", sb.String())
+	}
+}
@@ -2,7 +2,6 @@
 package metrics
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,12 +19,13 @@ var latencyBucketUpperBoundsMs = []int64{
 	10, 25, 50, 100, 250, 500, 1000, 2000, 5000, 10000, 30000,
 }
 
-// RuntimeSnapshot 包含工具、通道及记忆模块的聚合运行时指标快照。
+// RuntimeSnapshot 包含工具、通道、记忆及模型用量的聚合运行时指标快照。
 type RuntimeSnapshot struct {
 	UpdatedAt time.Time    `json:"updated_at"` // 指标最后更新时间
 	Tool      ToolStats    `json:"tool"`       // 工具执行统计
 	Channel   ChannelStats `json:"channel"`    // 消息通道发送统计
 	Memory    MemoryStats  `json:"memory"`     // 记忆召回统计
+	Model     ModelStats   `json:"model"`      // 模型 Token 用量统计
 }
 
 // ToolStats 跟踪工具执行的各项关键指标。
@@ -33,6 +33,7 @@ type ToolStats struct {
 	Total             int64 `json:"total"`                // 总调用次数
 	Errors            int64 `json:"errors"`               // 失败次数
 	Timeouts          int64 `json:"timeouts"`             // 超时次数
+	InvalidArgs       int64 `json:"invalid_args"`         // 因模型给出非法参数而失败的次数
 	TotalLatencyMs    int64 `json:"total_latency_ms"`     // 累计延迟（毫秒）
 	MaxLatencyMs      int64 `json:"max_latency_ms"`       // 最大延迟（毫秒）
 	LastLatencyMs     int64 `json:"last_latency_ms"`      // 最近一次执行延迟
@@ -78,6 +79,25 @@ type MemoryStats struct {
 	LongTermHits     int64 `json:"long_term_hits"`     // 命中长期记忆的次数
 	DiaryRecentHits  int64 `json:"diary_recent_hits"`  // 命中最近日记的次数
 	DiaryKeywordHits int64 `json:"diary_keyword_hits"` // 通过关键词命中日记的次数
+	SemanticHits     int64 `json:"semantic_hits"`      // 通过语义相似度命中的片段数
+}
+
+// ModelStats 跟踪 LLM 调用的 Token 消耗。
+type ModelStats struct {
+	Calls                int64 `json:"calls"`                  // 上报了用量的模型调用次数
+	PromptTokens         int64 `json:"prompt_tokens"`          // 累计输入 Token 数
+	CompletionTokens     int64 `json:"completion_tokens"`      // 累计输出 Token 数
+	TotalTokens          int64 `json:"total_tokens"`           // 累计 Token 总数
+	LastPromptTokens     int64 `json:"last_prompt_tokens"`     // 最近一次调用的输入 Token 数
+	LastCompletionTokens int64 `json:"last_completion_tokens"` // 最近一次调用的输出 Token 数
+}
+
+// AvgTokensPerCall 返回每次模型调用的平均 Token 数。
+func (m ModelStats) AvgTokensPerCall() float64 {
+	if m.Calls <= 0 {
+		return 0
+	}
+	return float64(m.TotalTokens) / float64(m.Calls)
 }
 
 // FailureRatio 返回通道发送的失败率，范围为 [0, 1]。
@@ -90,7 +110,7 @@ func (c ChannelStats) FailureRatio() float64 {
 
 // HasData 报告快照中是否包含任何已记录的数据。
 func (s RuntimeSnapshot) HasData() bool {
-	return s.Tool.Total > 0 || s.Channel.SendAttempts > 0
+	return s.Tool.Total > 0 || s.Channel.SendAttempts > 0 || s.Model.Calls > 0
 }
 
 // RuntimeMetrics 负责在内存中记录并定期持久化运行时指标。
@@ -171,7 +191,8 @@ func (m *RuntimeMetrics) Snapshot() RuntimeSnapshot {
 }
 
 // RecordToolExecution 记录一次工具执行的耗时与结果，并更新统计指标。
-func (m *RuntimeMetrics) RecordToolExecution(duration time.Duration, result string, runErr error) (RuntimeSnapshot, error) {
+// timedOut 由调用方根据工具上下文是否超过截止时间给出，仅在执行失败时计入超时次数。
+func (m *RuntimeMetrics) RecordToolExecution(duration time.Duration, result string, runErr error, timedOut bool) (RuntimeSnapshot, error) {
 	if m == nil {
 		return RuntimeSnapshot{}, nil
 	}
@@ -194,7 +215,9 @@ func (m *RuntimeMetrics) RecordToolExecution(duration time.Duration, result stri
 	}
 	if runErr != nil || strings.HasPrefix(strings.TrimSpace(result), "Error:") {
 		m.snap.Tool.Errors++
-		if isTimeoutError(runErr, result) {
+		if isInvalidArgsError(runErr) {
+			m.snap.Tool.InvalidArgs++
+		} else if timedOut {
 			m.snap.Tool.Timeouts++
 		}
 	}
@@ -250,6 +273,35 @@ func (m *RuntimeMetrics) RecordMemoryRecall(itemCount int, sourceHits map[string
 	m.snap.Memory.LongTermHits += int64(sourceHits["long_term"])
 	m.snap.Memory.DiaryRecentHits += int64(sourceHits["diary_recent"])
 	m.snap.Memory.DiaryKeywordHits += int64(sourceHits["diary_keyword"])
+	m.snap.Memory.SemanticHits += int64(sourceHits["semantic"])
+
+	m.dirty = true
+	return m.snap, nil
+}
+
+// RecordModelUsage 记录一次模型调用返回的 Token 用量。
+func (m *RuntimeMetrics) RecordModelUsage(promptTokens, completionTokens int) (RuntimeSnapshot, error) {
+	if m == nil {
+		return RuntimeSnapshot{}, nil
+	}
+	if promptTokens < 0 {
+		promptTokens = 0
+	}
+	if completionTokens < 0 {
+		completionTokens = 0
+	}
+	now := time.Now().UTC()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.snap.UpdatedAt = now
+	m.snap.Model.Calls++
+	m.snap.Model.PromptTokens += int64(promptTokens)
+	m.snap.Model.CompletionTokens += int64(completionTokens)
+	m.snap.Model.TotalTokens += int64(promptTokens + completionTokens)
+	m.snap.Model.LastPromptTokens = int64(promptTokens)
+	m.snap.Model.LastCompletionTokens = int64(completionTokens)
 
 	m.dirty = true
 	return m.snap, nil
@@ -333,68 +385,8 @@ func p95ProxyFromBuckets(buckets []int64, total int64) int64 {
 	return latencyBucketUpperBoundsMs[len(latencyBucketUpperBoundsMs)-1]
 }
 
-// containsIgnoreCase is a fast, zero-allocation helper that checks if a string
-// contains a specific lowercase English substring, avoiding strings.ToLower allocations.
-func containsIgnoreCase(s, substr string) bool {
-	n := len(substr)
-	if n == 0 {
-		return true
-	}
-	if len(s) < n {
-		return false
-	}
-
-	c0 := substr[0]
-	c0Upper := c0 - ('a' - 'A')
-	if c0 == ' ' {
-		c0Upper = ' '
-	}
-
-	for i := 0; i <= len(s)-n; i++ {
-		c := s[i]
-		if c != c0 && c != c0Upper {
-			continue
-		}
-
-		match := true
-		for j := 1; j < n; j++ {
-			c = s[i+j]
-			if c >= 'A' && c <= 'Z' {
-				c += 'a' - 'A'
-			}
-			if c != substr[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return true
-		}
-	}
-	return false
-}
-
-func isTimeoutError(runErr error, result string) bool {
-	if errors.Is(runErr, context.DeadlineExceeded) {
-		return true
-	}
-
-	if runErr != nil {
-		errStr := runErr.Error()
-		if containsIgnoreCase(errStr, "deadline exceeded") ||
-			containsIgnoreCase(errStr, "timeout") ||
-			containsIgnoreCase(errStr, "timed out") {
-			return true
-		}
-	}
-
-	if result != "" {
-		if containsIgnoreCase(result, "deadline exceeded") ||
-			containsIgnoreCase(result, "timeout") ||
-			containsIgnoreCase(result, "timed out") {
-			return true
-		}
-	}
-
-	return false
+// isInvalidArgsError 识别工具参数错误（tools.ArgumentError），通过接口判断以避免依赖 tools 包。
+func isInvalidArgsError(runErr error) bool {
+	var argErr interface{ InvalidArguments() bool }
+	return errors.As(runErr, &argErr) && argErr.InvalidArguments()
 }
@@ -3,7 +3,7 @@ package metrics
 import (
 	"context"
 	"errors"
-	"strings"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -13,7 +13,7 @@ func TestRuntimeMetrics_AggregatesToolAndChannelStats(t *testing.T) {
 	recorder := NewRuntimeMetrics(workspace)
 	defer recorder.Close()
 
-	snap, err := recorder.RecordToolExecution(120*time.Millisecond, "", nil)
+	snap, err := recorder.RecordToolExecution(120*time.Millisecond, "", nil, false)
 	if err != nil {
 		t.Fatalf("RecordToolExecution success error: %v", err)
 	}
@@ -21,9 +21,9 @@ func TestRuntimeMetrics_AggregatesToolAndChannelStats(t *testing.T) {
 		t.Fatalf("unexpected first tool snapshot: %+v", snap.Tool)
 	}
 
-	_, _ = recorder.RecordToolExecution(250*time.Millisecond, "", errors.New("exec failed"))
-	_, _ = recorder.RecordToolExecution(2*time.Second, "", context.DeadlineExceeded)
-	snap, _ = recorder.RecordToolExecution(1500*time.Millisecond, "", errors.New("request timed out"))
+	_, _ = recorder.RecordToolExecution(250*time.Millisecond, "", errors.New("exec failed"), false)
+	_, _ = recorder.RecordToolExecution(2*time.Second, "", context.DeadlineExceeded, true)
+	snap, _ = recorder.RecordToolExecution(1500*time.Millisecond, "", errors.New("request timed out"), true)
 
 	if snap.Tool.Total != 4 {
 		t.Fatalf("expected 4 tool executions, got %d", snap.Tool.Total)
@@ -56,10 +56,33 @@ func TestRuntimeMetrics_AggregatesToolAndChannelStats(t *testing.T) {
 	}
 }
 
+type invalidArgsError struct{}
+
+func (invalidArgsError) Error() string          { return "invalid arguments for read_file: malformed JSON" }
+func (invalidArgsError) InvalidArguments() bool { return true }
+
+func TestRuntimeMetrics_CountsInvalidArgumentsSeparately(t *testing.T) {
+	recorder := NewRuntimeMetrics(t.TempDir())
+	defer recorder.Close()
+
+	_, _ = recorder.RecordToolExecution(time.Millisecond, "", fmt.Errorf("wrapped: %w", invalidArgsError{}), false)
+	snap, _ := recorder.RecordToolExecution(time.Millisecond, "", errors.New("exec failed"), false)
+
+	if snap.Tool.Errors != 2 {
+		t.Fatalf("expected 2 tool errors, got %d", snap.Tool.Errors)
+	}
+	if snap.Tool.InvalidArgs != 1 {
+		t.Fatalf("expected 1 invalid-arguments error, got %d", snap.Tool.InvalidArgs)
+	}
+	if snap.Tool.Timeouts != 0 {
+		t.Fatalf("expected no timeouts, got %d", snap.Tool.Timeouts)
+	}
+}
+
 func TestRuntimeMetrics_ReadRuntimeSnapshot(t *testing.T) {
 	workspace := t.TempDir()
 	recorder := NewRuntimeMetrics(workspace)
-	if _, err := recorder.RecordToolExecution(99*time.Millisecond, "", nil); err != nil {
+	if _, err := recorder.RecordToolExecution(99*time.Millisecond, "", nil, false); err != nil {
 		t.Fatalf("RecordToolExecution error: %v", err)
 	}
 	if _, err := recorder.RecordChannelSend(false); err != nil {
@@ -110,22 +133,54 @@ func TestRuntimeMetrics_RecordMemoryRecall(t *testing.T) {
 	}
 }
 
-func TestIsTimeoutError(t *testing.T) {
-	if !isTimeoutError(context.DeadlineExceeded, "") {
-		t.Error("Expected true for DeadlineExceeded")
+func TestRuntimeMetrics_RecordModelUsage(t *testing.T) {
+	workspace := t.TempDir()
+	recorder := NewRuntimeMetrics(workspace)
+
+	_, _ = recorder.RecordModelUsage(100, 20)
+	snap, err := recorder.RecordModelUsage(50, -1)
+	if err != nil {
+		t.Fatalf("RecordModelUsage error: %v", err)
 	}
-	if !isTimeoutError(errors.New("some timeout occurred"), "") {
-		t.Error("Expected true for timeout error")
+	if snap.Model.Calls != 2 || snap.Model.PromptTokens != 150 || snap.Model.CompletionTokens != 20 || snap.Model.TotalTokens != 170 {
+		t.Fatalf("unexpected model usage summary: %+v", snap.Model)
 	}
-	if !isTimeoutError(nil, "Error: connection timed out") {
-		t.Error("Expected true for timed out result")
+	if snap.Model.LastPromptTokens != 50 || snap.Model.LastCompletionTokens != 0 {
+		t.Fatalf("unexpected last call usage: %+v", snap.Model)
 	}
-	if isTimeoutError(nil, "Error: standard error") {
-		t.Error("Expected false for standard error")
+	if !snap.HasData() {
+		t.Fatal("expected snapshot with model usage to report data")
 	}
 
-	largeResult := "Error: something went wrong\n" + strings.Repeat("x", 1000) + "\ntimeout in the end"
-	if !isTimeoutError(nil, largeResult) {
-		t.Error("Expected true for large result with timeout at the end")
+	recorder.Close()
+
+	loaded, err := ReadRuntimeSnapshot(workspace)
+	if err != nil {
+		t.Fatalf("ReadRuntimeSnapshot error: %v", err)
+	}
+	if loaded.Model.TotalTokens != 170 {
+		t.Fatalf("expected persisted total tokens=170, got %+v", loaded.Model)
+	}
+}
+
+func TestRuntimeMetrics_TimeoutRatioUsesExplicitFlag(t *testing.T) {
+	recorder := NewRuntimeMetrics(t.TempDir())
+	defer recorder.Close()
+
+	// An error that mentions a timeout is not a timeout unless the caller flags it.
+	_, _ = recorder.RecordToolExecution(time.Millisecond, "Error: upstream said timeout", errors.New("upstream said timeout"), false)
+	_, _ = recorder.RecordToolExecution(time.Second, "", context.DeadlineExceeded, true)
+	// A successful call never counts as a timeout.
+	_, _ = recorder.RecordToolExecution(time.Millisecond, "done", nil, true)
+	snap, _ := recorder.RecordToolExecution(time.Millisecond, "done", nil, false)
+
+	if snap.Tool.Timeouts != 1 {
+		t.Fatalf("expected 1 timeout, got %d", snap.Tool.Timeouts)
+	}
+	if got := snap.Tool.TimeoutRatio(); got != 0.25 {
+		t.Fatalf("expected timeout ratio 0.25, got %.4f", got)
+	}
+	if got := snap.Tool.ErrorRatio(); got != 0.5 {
+		t.Fatalf("expected error ratio 0.5, got %.4f", got)
 	}
 }
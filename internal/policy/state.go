@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const stateFileName = "policy_state.json"
+
+// State 是运行中 Agent 当前生效策略的快照，写入工作区供 CLI 查询。
+// off_ttl 的截止时间在 Agent 加载策略时才确定，仅凭配置文件无法得知。
+type State struct {
+	BaseMode        Mode      `json:"base_mode"`           // 配置（或运行时切换）的基础模式
+	OffUntil        time.Time `json:"off_until,omitzero"`  // off 模式的截止时间，零值表示无 TTL
+	RequireApproval []string  `json:"require_approval"`    // 需要审批的工具列表
+	UpdatedAt       time.Time `json:"updated_at,omitzero"` // 快照写入时间
+}
+
+// EffectiveMode 计算 now 时刻实际生效的模式：off 模式超过截止时间后回落为 strict，
+// 第二个返回值表示是否因 TTL 到期而回落。
+func EffectiveMode(base Mode, offUntil, now time.Time) (Mode, bool) {
+	if normalizeMode(base) == ModeOff && !offUntil.IsZero() && !now.Before(offUntil) {
+		return ModeStrict, true
+	}
+	return base, false
+}
+
+// Effective 返回快照在 now 时刻实际生效的模式，语义同 EffectiveMode。
+func (s State) Effective(now time.Time) (Mode, bool) {
+	return EffectiveMode(s.BaseMode, s.OffUntil, now)
+}
+
+// Remaining 返回 off 模式距截止时间的剩余时长；非 off 模式、无 TTL 或已到期时返回 0。
+func (s State) Remaining(now time.Time) time.Duration {
+	if normalizeMode(s.BaseMode) != ModeOff || s.OffUntil.IsZero() || !now.Before(s.OffUntil) {
+		return 0
+	}
+	return s.OffUntil.Sub(now)
+}
+
+// StatePath 返回工作区策略快照文件路径。
+func StatePath(workspace string) string {
+	return filepath.Join(workspace, "state", stateFileName)
+}
+
+// WriteState 原子地写入策略快照。
+func WriteState(workspace string, state State) error {
+	path := StatePath(workspace)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create policy state dir: %w", err)
+	}
+	payload, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode policy state: %w", err)
+	}
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return fmt.Errorf("write policy state temp file: %w", err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return fmt.Errorf("rename policy state file: %w", err)
+	}
+	return nil
+}
+
+// ReadState 读取策略快照；文件不存在（Agent 尚未运行过）时第二个返回值为 false。
+func ReadState(workspace string) (State, bool, error) {
+	raw, err := os.ReadFile(StatePath(workspace))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return State{}, false, nil
+		}
+		return State{}, false, fmt.Errorf("read policy state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return State{}, false, fmt.Errorf("decode policy state: %w", err)
+	}
+	return state, true, nil
+}
@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEffectiveMode_OffRevertsToStrictAfterDeadline(t *testing.T) {
+	deadline := time.Date(2026, 2, 15, 1, 0, 0, 0, time.UTC)
+
+	if mode, expired := EffectiveMode(ModeOff, deadline, deadline.Add(-time.Minute)); mode != ModeOff || expired {
+		t.Fatalf("before deadline: got %q expired=%v", mode, expired)
+	}
+	if mode, expired := EffectiveMode(ModeOff, deadline, deadline); mode != ModeStrict || !expired {
+		t.Fatalf("at deadline: got %q expired=%v", mode, expired)
+	}
+	if mode, expired := EffectiveMode(ModeOff, time.Time{}, deadline); mode != ModeOff || expired {
+		t.Fatalf("without ttl: got %q expired=%v", mode, expired)
+	}
+	if mode, expired := EffectiveMode(ModeRelaxed, deadline, deadline.Add(time.Hour)); mode != ModeRelaxed || expired {
+		t.Fatalf("relaxed: got %q expired=%v", mode, expired)
+	}
+}
+
+func TestState_Remaining(t *testing.T) {
+	deadline := time.Date(2026, 2, 15, 1, 0, 0, 0, time.UTC)
+	state := State{BaseMode: ModeOff, OffUntil: deadline}
+
+	if got := state.Remaining(deadline.Add(-90 * time.Second)); got != 90*time.Second {
+		t.Fatalf("expected 90s remaining, got %s", got)
+	}
+	if got := state.Remaining(deadline.Add(time.Second)); got != 0 {
+		t.Fatalf("expected 0 after deadline, got %s", got)
+	}
+	if got := (State{BaseMode: ModeStrict, OffUntil: deadline}).Remaining(deadline.Add(-time.Hour)); got != 0 {
+		t.Fatalf("expected 0 for strict mode, got %s", got)
+	}
+}
+
+func TestWriteReadState_RoundTrip(t *testing.T) {
+	workspace := t.TempDir()
+
+	if _, ok, err := ReadState(workspace); err != nil || ok {
+		t.Fatalf("expected missing state, got ok=%v err=%v", ok, err)
+	}
+
+	want := State{
+		BaseMode:        ModeOff,
+		OffUntil:        time.Date(2026, 2, 15, 1, 0, 0, 0, time.UTC),
+		RequireApproval: []string{"exec"},
+		UpdatedAt:       time.Date(2026, 2, 15, 0, 30, 0, 0, time.UTC),
+	}
+	if err := WriteState(workspace, want); err != nil {
+		t.Fatalf("WriteState() error: %v", err)
+	}
+	got, ok, err := ReadState(workspace)
+	if err != nil || !ok {
+		t.Fatalf("ReadState() ok=%v err=%v", ok, err)
+	}
+	if got.BaseMode != want.BaseMode || !got.OffUntil.Equal(want.OffUntil) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+		t.Fatalf("round trip mismatch: got %+v want %+v", got, want)
+	}
+	if len(got.RequireApproval) != 1 || got.RequireApproval[0] != "exec" {
+		t.Fatalf("unexpected require_approval: %v", got.RequireApproval)
+	}
+}
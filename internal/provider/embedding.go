@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/httpclient"
+	"github.com/cloudwego/eino/components/embedding"
+)
+
+// embeddingHTTPClient 是调用向量接口使用的 HTTP 客户端，测试中可替换；为 nil 时按 http 配置块创建。
+var embeddingHTTPClient *http.Client
+
+// NewEmbedder 根据 agents.memory.embedding_model 创建向量模型，调用供应商的 OpenAI 兼容 /embeddings 接口。
+// 模型名带供应商前缀时使用该供应商（前缀不发送给接口），否则使用与聊天模型相同的供应商。
+func NewEmbedder(ctx context.Context, cfg *config.Config) (embedding.Embedder, error) {
+	modelName := strings.TrimSpace(cfg.Agents.Memory.EmbeddingModel)
+	if modelName == "" {
+		return nil, fmt.Errorf("agents.memory.embedding_model is required")
+	}
+
+	var (
+		name providerName
+		pcfg config.ProviderConfig
+		err  error
+	)
+	if name = providerFromModel(modelName); name != "" {
+		name, pcfg, err = resolveOverrideProvider(cfg, modelName)
+		modelName = modelName[strings.Index(modelName, "/")+1:]
+	} else {
+		name, pcfg, err = resolveProvider(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := embeddingBaseURL(name, pcfg)
+	if err != nil {
+		return nil, err
+	}
+	return &openAIEmbedder{
+		provider: name,
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		apiKey:   strings.TrimSpace(pcfg.APIKey),
+		model:    modelName,
+	}, nil
+}
+
+// embeddingBaseURL 返回供应商 OpenAI 兼容接口的根地址，与对应聊天模型使用的地址一致。
+func embeddingBaseURL(name providerName, p config.ProviderConfig) (string, error) {
+	baseURL := strings.TrimSpace(p.BaseURL)
+	switch name {
+	case providerOpenRouter:
+		return "https://openrouter.ai/api/v1", nil
+	case providerClaude:
+		return "", fmt.Errorf("provider %s does not offer an embeddings endpoint; set agents.memory.embedding_model to another provider's model", name)
+	case providerOpenAI:
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+	case providerDeepSeek:
+		return "https://api.deepseek.com/v1", nil
+	case providerGemini:
+		if baseURL == "" {
+			baseURL = "https://generativelanguage.googleapis.com/v1beta/openai"
+		}
+	case providerArk, providerQianfan:
+		if baseURL == "" {
+			return "", fmt.Errorf("%s provider requires base_url", name)
+		}
+	case providerQwen:
+		if baseURL == "" {
+			baseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
+		}
+	case providerOllama:
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		baseURL = strings.TrimRight(baseURL, "/") + "/v1"
+	default:
+		return "", fmt.Errorf("unsupported provider selected: %s", name)
+	}
+	return baseURL, nil
+}
+
+// openAIEmbedder 实现 eino 的 embedding.Embedder，请求 OpenAI 兼容的 POST {base_url}/embeddings。
+type openAIEmbedder struct {
+	provider providerName
+	baseURL  string
+	apiKey   string
+	model    string
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (e *openAIEmbedder) EmbedStrings(ctx context.Context, texts []string, _ ...embedding.Option) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(embeddingRequest{Model: e.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	client := embeddingHTTPClient
+	if client == nil {
+		client = httpclient.New(httpclient.Timeout(30 * time.Second))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s embeddings request failed: %w", e.provider, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, fmt.Errorf("read %s embeddings response: %w", e.provider, err)
+	}
+
+	var parsed embeddingResponse
+	decodeErr := json.Unmarshal(raw, &parsed)
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(raw))
+		if decodeErr == nil && parsed.Error != nil && parsed.Error.Message != "" {
+			msg = parsed.Error.Message
+		}
+		if len(msg) > 300 {
+			msg = msg[:300] + "..."
+		}
+		return nil, fmt.Errorf("%s embeddings request failed: status %d: %s", e.provider, resp.StatusCode, msg)
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("decode %s embeddings response: %w", e.provider, decodeErr)
+	}
+	if len(parsed.Data) != len(texts) {
+		return nil, fmt.Errorf("%s embeddings response has %d vectors for %d inputs", e.provider, len(parsed.Data), len(texts))
+	}
+
+	sort.SliceStable(parsed.Data, func(i, j int) bool { return parsed.Data[i].Index < parsed.Data[j].Index })
+	vectors := make([][]float64, len(parsed.Data))
+	for i, d := range parsed.Data {
+		if len(d.Embedding) == 0 {
+			return nil, fmt.Errorf("%s embeddings response has an empty vector at index %d", e.provider, d.Index)
+		}
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -26,24 +26,119 @@ const (
 	providerOllama     providerName = "ollama"
 )
 
+// providerFallbackOrder 是未通过模型前缀指定供应商时的回退顺序。
+var providerFallbackOrder = []providerName{
+	providerOpenRouter,
+	providerClaude,
+	providerOpenAI,
+	providerDeepSeek,
+	providerGemini,
+	providerArk,
+	providerQianfan,
+	providerQwen,
+	providerOllama,
+}
+
+// oauthRefreshConfigs 列出支持刷新 OAuth 令牌的供应商；新增供应商在此登记其 OAuth 配置即可启用自动刷新。
+var oauthRefreshConfigs = map[providerName]func() auth.OAuthProviderConfig{
+	providerOpenAI: auth.OpenAIOAuthConfig,
+	providerClaude: auth.AnthropicOAuthConfig,
+}
+
 // refreshProviderCredential 定义了刷新特定供应商凭据的回调函数。
 var refreshProviderCredential = func(name providerName, cred *auth.Credential) (*auth.Credential, error) {
-	switch name {
-	case providerOpenAI:
-		return auth.RefreshAccessToken(cred, auth.OpenAIOAuthConfig())
-	default:
+	oauthConfig, ok := oauthRefreshConfigs[name]
+	if !ok {
 		return nil, fmt.Errorf("refresh is not supported for provider %s", name)
 	}
+	return auth.RefreshAccessToken(cred, oauthConfig())
 }
 
 // NewChatModel 根据全局配置自动解析并创建一个合适的聊天模型实例。
 func NewChatModel(ctx context.Context, cfg *config.Config) (model.ChatModel, error) {
+	setRefreshAudit(cfg)
 	selected, pcfg, err := resolveProvider(cfg)
 	if err != nil {
 		return nil, err
 	}
+	return newRefreshingModelFor(ctx, cfg, selected, pcfg, cfg.Agents.Defaults)
+}
 
+// NewChannelChatModels 为 agents.defaults.channel_models 中的每个通道覆盖创建独立的聊天模型。
+// 覆盖模型必须带有已知的供应商前缀且该供应商已配置，否则返回错误。
+func NewChannelChatModels(ctx context.Context, cfg *config.Config) (map[string]model.ChatModel, error) {
+	overrides := cfg.Agents.Defaults.ChannelModels
+	if len(overrides) == 0 {
+		return nil, nil
+	}
+
+	setRefreshAudit(cfg)
+	models := make(map[string]model.ChatModel, len(overrides))
+	for channel, modelName := range overrides {
+		selected, pcfg, err := resolveOverrideProvider(cfg, modelName)
+		if err != nil {
+			return nil, fmt.Errorf("agents.defaults.channel_models.%s: %w", channel, err)
+		}
+		d := cfg.Agents.Defaults
+		d.Model = modelName
+		chatModel, err := newRefreshingModelFor(ctx, cfg, selected, pcfg, d)
+		if err != nil {
+			return nil, fmt.Errorf("agents.defaults.channel_models.%s: %w", channel, err)
+		}
+		models[channel] = chatModel
+	}
+	return models, nil
+}
+
+// NewChatModelFor 为运行时切换的模型创建聊天模型。模型名带有已知供应商前缀时该供应商必须已配置；
+// 不带前缀时与 agents.defaults.model 一样按回退顺序选择供应商。
+func NewChatModelFor(ctx context.Context, cfg *config.Config, modelName string) (model.ChatModel, error) {
+	modelName = strings.TrimSpace(modelName)
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+
+	setRefreshAudit(cfg)
+	var (
+		selected providerName
+		pcfg     config.ProviderConfig
+		err      error
+	)
+	if providerFromModel(modelName) != "" {
+		selected, pcfg, err = resolveOverrideProvider(cfg, modelName)
+	} else {
+		selected, pcfg, err = resolveProvider(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
 	d := cfg.Agents.Defaults
+	d.Model = modelName
+	return newRefreshingModelFor(ctx, cfg, selected, pcfg, d)
+}
+
+// ConfiguredProviders 按回退顺序返回已配置（有 API Key、OAuth 凭据或 Ollama 地址）的供应商名称，即可用的模型前缀。
+func ConfiguredProviders(cfg *config.Config) []string {
+	var names []string
+	for _, name := range providerFallbackOrder {
+		if pcfg, ok := providerConfigByName(cfg.Providers, name); ok && providerIsConfigured(name, pcfg) {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
+
+// ValidateChannelModels 检查每个通道模型覆盖是否引用了已配置的供应商。
+func ValidateChannelModels(cfg *config.Config) error {
+	for channel, modelName := range cfg.Agents.Defaults.ChannelModels {
+		if _, _, err := resolveOverrideProvider(cfg, modelName); err != nil {
+			return fmt.Errorf("agents.defaults.channel_models.%s: %w", channel, err)
+		}
+	}
+	return nil
+}
+
+func newModelFor(ctx context.Context, selected providerName, pcfg config.ProviderConfig, d config.AgentDefaults) (model.ChatModel, error) {
 	switch selected {
 	case providerOpenRouter:
 		return newOpenRouterModel(ctx, pcfg, d)
@@ -68,6 +163,20 @@ func NewChatModel(ctx context.Context, cfg *config.Config) (model.ChatModel, err
 	}
 }
 
+// resolveOverrideProvider 解析通道覆盖模型的供应商。与 resolveProvider 不同，这里不做回退：
+// 模型名必须显式指定供应商前缀，且对应供应商已配置。
+func resolveOverrideProvider(cfg *config.Config, modelName string) (providerName, config.ProviderConfig, error) {
+	name := providerFromModel(modelName)
+	if name == "" {
+		return "", config.ProviderConfig{}, fmt.Errorf("model %q must start with a known provider prefix (e.g. openai/gpt-4o)", modelName)
+	}
+	pcfg, ok := providerConfigByName(cfg.Providers, name)
+	if !ok || !providerIsConfigured(name, pcfg) {
+		return "", config.ProviderConfig{}, fmt.Errorf("provider %q referenced by model %q is not configured", name, modelName)
+	}
+	return name, withResolvedProviderToken(name, pcfg), nil
+}
+
 // resolveProvider 确定最终使用的供应商及其配置。优先基于模型名称匹配，其次按优先级回退。
 func resolveProvider(cfg *config.Config) (providerName, config.ProviderConfig, error) {
 	p := cfg.Providers
@@ -81,19 +190,7 @@ func resolveProvider(cfg *config.Config) (providerName, config.ProviderConfig, e
 	}
 
 	// 2. 按默认顺序回退到第一个已配置的供应商
-	fallbackOrder := []providerName{
-		providerOpenRouter,
-		providerClaude,
-		providerOpenAI,
-		providerDeepSeek,
-		providerGemini,
-		providerArk,
-		providerQianfan,
-		providerQwen,
-		providerOllama,
-	}
-
-	for _, name := range fallbackOrder {
+	for _, name := range providerFallbackOrder {
 		pcfg, ok := providerConfigByName(p, name)
 		if !ok {
 			continue
@@ -194,6 +291,12 @@ func withResolvedProviderToken(name providerName, p config.ProviderConfig) confi
 }
 
 func lookupCredential(name providerName) *auth.Credential {
+	_, cred := lookupCredentialEntry(name)
+	return cred
+}
+
+// lookupCredentialEntry 返回供应商凭据及其在凭据存储中的键名；OAuth 凭据即将过期时先尝试刷新。
+func lookupCredentialEntry(name providerName) (string, *auth.Credential) {
 	keys := []string{string(name)}
 	if name == providerClaude {
 		keys = append(keys, "anthropic")
@@ -201,21 +304,15 @@ func lookupCredential(name providerName) *auth.Credential {
 	for _, key := range keys {
 		cred, err := auth.GetCredential(key)
 		if err == nil && cred != nil && strings.TrimSpace(cred.AccessToken) != "" {
-			// 如果是 OAuth 凭据且即将过期，则尝试自动刷新
-			if cred.AuthMethod == "oauth" && cred.NeedsRefresh() && strings.TrimSpace(cred.RefreshToken) != "" {
-				if refreshed, err := refreshProviderCredential(name, cred); err == nil && refreshed != nil && strings.TrimSpace(refreshed.AccessToken) != "" {
-					refreshed.Provider = key
-					if strings.TrimSpace(refreshed.AuthMethod) == "" {
-						refreshed.AuthMethod = "oauth"
-					}
-					_ = auth.SetCredential(key, refreshed)
+			if refreshable(cred) && cred.NeedsRefresh() {
+				if refreshed, err := refreshCredential(name, key, cred, "expiring"); err == nil {
 					cred = refreshed
 				}
 			}
-			return cred
+			return key, cred
 		}
 	}
-	return nil
+	return "", nil
 }
 
 func newOpenRouterModel(ctx context.Context, p config.ProviderConfig, d config.AgentDefaults) (model.ChatModel, error) {
@@ -1,11 +1,21 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/MEKXH/golem/internal/audit"
 	"github.com/MEKXH/golem/internal/auth"
 	"github.com/MEKXH/golem/internal/config"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
 )
 
 func TestNewChatModel_NoProvider(t *testing.T) {
@@ -177,3 +187,286 @@ func TestResolveProvider_RefreshesOAuthCredentialWhenNeeded(t *testing.T) {
 		t.Fatalf("expected refreshed token injected, got %q", pcfg.APIKey)
 	}
 }
+
+func TestValidateChannelModels_RejectsUnconfiguredProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Providers.OpenAI.APIKey = "openai-key"
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"slack": "openai/gpt-4o"}
+	if err := ValidateChannelModels(cfg); err != nil {
+		t.Fatalf("expected configured override to pass, got %v", err)
+	}
+
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"telegram": "deepseek/deepseek-chat"}
+	if err := ValidateChannelModels(cfg); err == nil {
+		t.Fatal("expected error for override referencing unconfigured provider")
+	}
+
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"telegram": "gpt-4o-mini"}
+	if err := ValidateChannelModels(cfg); err == nil {
+		t.Fatal("expected error for override without provider prefix")
+	}
+}
+
+func TestNewChannelChatModels_BuildsOverrideModels(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Providers.DeepSeek.APIKey = "deepseek-key"
+	cfg.Agents.Defaults.ChannelModels = map[string]string{"telegram": "deepseek/deepseek-chat"}
+
+	models, err := NewChannelChatModels(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewChannelChatModels returned error: %v", err)
+	}
+	if len(models) != 1 || models["telegram"] == nil {
+		t.Fatalf("expected telegram override model, got %v", models)
+	}
+}
+
+func TestNewChatModelFor_ValidatesProviderPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Providers.DeepSeek.APIKey = "deepseek-key"
+	cfg.Providers.Ollama.BaseURL = "http://localhost:11434"
+
+	if got := ConfiguredProviders(cfg); !reflect.DeepEqual(got, []string{"deepseek", "ollama"}) {
+		t.Fatalf("unexpected configured providers: %v", got)
+	}
+	if m, err := NewChatModelFor(context.Background(), cfg, "deepseek/deepseek-reasoner"); err != nil || m == nil {
+		t.Fatalf("expected deepseek model, got %v (%v)", m, err)
+	}
+	if m, err := NewChatModelFor(context.Background(), cfg, "deepseek-chat"); err != nil || m == nil {
+		t.Fatalf("expected unprefixed model to use the fallback provider, got %v (%v)", m, err)
+	}
+	if _, err := NewChatModelFor(context.Background(), cfg, "openai/gpt-4o"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("expected unconfigured provider error, got %v", err)
+	}
+	if _, err := NewChatModelFor(context.Background(), cfg, " "); err == nil {
+		t.Fatal("expected error for empty model name")
+	}
+}
+
+type tokenModel struct {
+	token string
+	err   error
+	calls int
+	tools []*schema.ToolInfo
+}
+
+func (m *tokenModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return schema.AssistantMessage("token="+m.token, nil), nil
+}
+
+func (m *tokenModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *tokenModel) BindTools(tools []*schema.ToolInfo) error {
+	m.tools = tools
+	return nil
+}
+
+func newTestRefreshingModel(t *testing.T, cred *auth.Credential, inner *tokenModel) (*refreshingModel, map[string]*tokenModel) {
+	t.Helper()
+	built := map[string]*tokenModel{}
+	return &refreshingModel{
+		name:  providerOpenAI,
+		key:   "openai",
+		cred:  cred,
+		inner: inner,
+		build: func(ctx context.Context, token string) (model.ChatModel, error) {
+			m := &tokenModel{token: token}
+			built[token] = m
+			return m, nil
+		},
+	}, built
+}
+
+func TestRefreshingModel_RefreshesBeforeExpiryAndAudits(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	workspace := t.TempDir()
+	refreshAudit.Store(audit.NewWriter(workspace))
+	t.Cleanup(func() { refreshAudit.Store(nil) })
+
+	orig := refreshProviderCredential
+	refreshProviderCredential = func(name providerName, cred *auth.Credential) (*auth.Credential, error) {
+		return &auth.Credential{AccessToken: "fresh", RefreshToken: cred.RefreshToken, ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	t.Cleanup(func() { refreshProviderCredential = orig })
+
+	cred := &auth.Credential{AccessToken: "stale", RefreshToken: "r", AuthMethod: "oauth", ExpiresAt: time.Now().Add(time.Minute)}
+	m, built := newTestRefreshingModel(t, cred, &tokenModel{token: "stale"})
+	tools := []*schema.ToolInfo{{Name: "exec"}}
+	if err := m.BindTools(tools); err != nil {
+		t.Fatalf("BindTools: %v", err)
+	}
+
+	resp, err := m.Generate(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Content != "token=fresh" {
+		t.Fatalf("expected refreshed model to answer, got %q", resp.Content)
+	}
+	if fresh := built["fresh"]; fresh == nil || len(fresh.tools) != 1 {
+		t.Fatalf("expected tools rebound on rebuilt model, got %+v", fresh)
+	}
+	if stored, err := auth.GetCredential("openai"); err != nil || stored == nil || stored.AccessToken != "fresh" {
+		t.Fatalf("expected refreshed credential saved, got %+v err=%v", stored, err)
+	}
+
+	events, err := audit.Read(workspace, audit.Query{Type: "oauth_refresh"})
+	if err != nil {
+		t.Fatalf("audit.Read: %v", err)
+	}
+	if len(events) != 1 || events[0].Tool != "openai" || !strings.Contains(events[0].Result, "ok reason=expiring") {
+		t.Fatalf("unexpected refresh audit events: %+v", events)
+	}
+}
+
+func TestRefreshingModel_RetriesOnceAfterUnauthorized(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	refreshes := 0
+	orig := refreshProviderCredential
+	refreshProviderCredential = func(name providerName, cred *auth.Credential) (*auth.Credential, error) {
+		refreshes++
+		return &auth.Credential{AccessToken: "fresh", RefreshToken: cred.RefreshToken, ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	t.Cleanup(func() { refreshProviderCredential = orig })
+
+	cred := &auth.Credential{AccessToken: "revoked", RefreshToken: "r", AuthMethod: "oauth", ExpiresAt: time.Now().Add(time.Hour)}
+	inner := &tokenModel{token: "revoked", err: errors.New("error, status code: 401, message: token expired")}
+	m, _ := newTestRefreshingModel(t, cred, inner)
+
+	resp, err := m.Generate(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if resp.Content != "token=fresh" || refreshes != 1 || inner.calls != 1 {
+		t.Fatalf("expected one refresh and retry, got resp=%q refreshes=%d calls=%d", resp.Content, refreshes, inner.calls)
+	}
+
+	// 非 401 错误不触发刷新
+	m.inner = &tokenModel{err: errors.New("status code: 500")}
+	if _, err := m.Generate(context.Background(), nil); err == nil || refreshes != 1 {
+		t.Fatalf("expected 500 to pass through without refresh, err=%v refreshes=%d", err, refreshes)
+	}
+}
+
+func TestRefreshProviderCredential_UnsupportedProvider(t *testing.T) {
+	if _, err := refreshProviderCredential(providerGemini, &auth.Credential{RefreshToken: "r"}); err == nil {
+		t.Fatal("expected refresh to be unsupported for gemini")
+	}
+}
+
+func TestNewEmbedder_CallsOpenAICompatibleEndpoint(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	var gotAuth string
+	var gotReq embeddingRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+		// 乱序返回，验证按 index 排序。
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Providers.Claude.APIKey = "claude-key"
+	cfg.Providers.OpenAI.APIKey = "openai-key"
+	cfg.Providers.OpenAI.BaseURL = srv.URL + "/v1"
+	cfg.Agents.Memory.EmbeddingModel = "openai/text-embedding-3-small"
+
+	embedder, err := NewEmbedder(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewEmbedder returned error: %v", err)
+	}
+	vectors, err := embedder.EmbedStrings(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedStrings returned error: %v", err)
+	}
+	if gotAuth != "Bearer openai-key" || gotReq.Model != "text-embedding-3-small" || len(gotReq.Input) != 2 {
+		t.Fatalf("unexpected request: auth=%q body=%+v", gotAuth, gotReq)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Fatalf("unexpected vectors: %v", vectors)
+	}
+}
+
+func TestNewEmbedder_RejectsProviderWithoutEmbeddings(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	cfg := config.DefaultConfig()
+	cfg.Providers.Claude.APIKey = "claude-key"
+	cfg.Agents.Defaults.Model = "anthropic/claude-sonnet-4"
+
+	if _, err := NewEmbedder(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "embeddings") {
+		t.Fatalf("expected embeddings error for claude, got %v", err)
+	}
+}
+
+func TestOpenAIEmbedder_ReportsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"invalid api key"}}`))
+	}))
+	defer srv.Close()
+
+	embedder := &openAIEmbedder{provider: providerOpenAI, baseURL: srv.URL, model: "m"}
+	if _, err := embedder.EmbedStrings(context.Background(), []string{"a"}); err == nil || !strings.Contains(err.Error(), "invalid api key") {
+		t.Fatalf("expected api error, got %v", err)
+	}
+}
+
+func TestSupportsVision(t *testing.T) {
+	tests := []struct {
+		model string
+		extra []string
+		want  bool
+	}{
+		{model: "openai/gpt-4o-mini", want: true},
+		{model: "anthropic/claude-sonnet-4-5", want: true},
+		{model: "gemini/gemini-2.0-flash", want: true},
+		{model: "qwen/qwen-vl-max", want: true},
+		{model: "ollama/llama3.2-vision", want: true},
+		{model: "deepseek/deepseek-chat", want: false},
+		{model: "ollama/llama3.1", want: false},
+		{model: "ollama/my-captioner", extra: []string{"my-caption"}, want: true},
+		{model: "ark/doubao-seed", extra: []string{"ark/doubao"}, want: true},
+		{model: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := SupportsVision(tt.model, tt.extra); got != tt.want {
+			t.Fatalf("SupportsVision(%q, %v)=%v want %v", tt.model, tt.extra, got, tt.want)
+		}
+	}
+}
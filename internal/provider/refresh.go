@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MEKXH/golem/internal/audit"
+	"github.com/MEKXH/golem/internal/auth"
+	"github.com/MEKXH/golem/internal/config"
+	"github.com/MEKXH/golem/internal/logrotate"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// refreshRetryInterval 是主动刷新失败后的重试间隔，避免每次模型调用都请求令牌端点。
+const refreshRetryInterval = time.Minute
+
+// refreshAudit 记录令牌刷新事件的审计写入器，由 NewChatModel 根据配置设置；为空时不记录。
+var refreshAudit atomic.Pointer[audit.Writer]
+
+var unauthorizedPattern = regexp.MustCompile(`(?i)(?:status(?:\s*code)?\s*[:=]?\s*|":\s*)401\b|unauthorized|invalid[_ ]api[_ ]key|token (?:has )?expired`)
+
+func setRefreshAudit(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	workspace, err := cfg.WorkspacePathChecked()
+	if err != nil {
+		return
+	}
+	refreshAudit.Store(audit.NewWriterWithOptions(workspace, logrotate.OptionsFromConfig(cfg.Log.Audit)))
+}
+
+// refreshable 报告凭据能否通过刷新令牌续期。
+func refreshable(cred *auth.Credential) bool {
+	return cred != nil && cred.AuthMethod == "oauth" && strings.TrimSpace(cred.RefreshToken) != ""
+}
+
+// refreshCredential 刷新 OAuth 凭据并写回凭据存储，结果写入审计日志。reason 说明触发原因（expiring、unauthorized）。
+func refreshCredential(name providerName, key string, cred *auth.Credential, reason string) (*auth.Credential, error) {
+	refreshed, err := refreshProviderCredential(name, cred)
+	if err == nil && (refreshed == nil || strings.TrimSpace(refreshed.AccessToken) == "") {
+		err = fmt.Errorf("refresh returned no access token")
+	}
+	if err != nil {
+		appendRefreshAudit(name, fmt.Sprintf("error reason=%s: %v", reason, err))
+		slog.Warn("oauth token refresh failed", "provider", name, "reason", reason, "error", err)
+		return nil, err
+	}
+
+	refreshed.Provider = key
+	if strings.TrimSpace(refreshed.AuthMethod) == "" {
+		refreshed.AuthMethod = "oauth"
+	}
+	if err := auth.SetCredential(key, refreshed); err != nil {
+		slog.Warn("failed to save refreshed oauth token", "provider", name, "error", err)
+	}
+	expires := "none"
+	if !refreshed.ExpiresAt.IsZero() {
+		expires = refreshed.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	appendRefreshAudit(name, fmt.Sprintf("ok reason=%s expires_at=%s", reason, expires))
+	slog.Info("oauth token refreshed", "provider", name, "reason", reason, "expires_at", expires)
+	return refreshed, nil
+}
+
+func appendRefreshAudit(name providerName, result string) {
+	writer := refreshAudit.Load()
+	if writer == nil {
+		return
+	}
+	_ = writer.Append(audit.Event{
+		Time:   time.Now().UTC(),
+		Type:   "oauth_refresh",
+		Tool:   string(name),
+		Result: result,
+	})
+}
+
+// isUnauthorizedError 判断模型错误是否由访问令牌失效（401）引起。
+func isUnauthorizedError(err error) bool {
+	return err != nil && unauthorizedPattern.MatchString(err.Error())
+}
+
+// newRefreshingModelFor 创建聊天模型；访问令牌来自可刷新的 OAuth 凭据（而非配置中的 api_key）时，
+// 用 refreshingModel 包装，以便长会话中令牌过期前主动刷新。
+func newRefreshingModelFor(ctx context.Context, cfg *config.Config, name providerName, pcfg config.ProviderConfig, d config.AgentDefaults) (model.ChatModel, error) {
+	chatModel, err := newModelFor(ctx, name, pcfg, d)
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := providerConfigByName(cfg.Providers, name); !ok || strings.TrimSpace(raw.APIKey) != "" {
+		return chatModel, nil
+	}
+	key, cred := lookupCredentialEntry(name)
+	if !refreshable(cred) {
+		return chatModel, nil
+	}
+	return &refreshingModel{
+		name:  name,
+		key:   key,
+		cred:  cred,
+		inner: chatModel,
+		build: func(ctx context.Context, token string) (model.ChatModel, error) {
+			p := pcfg
+			p.APIKey = token
+			return newModelFor(ctx, name, p, d)
+		},
+	}, nil
+}
+
+// refreshingModel 包装使用 OAuth 凭据的聊天模型：每次调用前令牌临近过期（5 分钟内）时主动刷新，
+// 调用返回 401 时强制刷新并重试一次。刷新后以新令牌重建底层模型并重新绑定工具。
+type refreshingModel struct {
+	name  providerName
+	key   string
+	build func(ctx context.Context, token string) (model.ChatModel, error)
+
+	mu        sync.Mutex
+	inner     model.ChatModel
+	cred      *auth.Credential
+	tools     []*schema.ToolInfo
+	nextRetry time.Time // 主动刷新失败后，下次重试的最早时间
+}
+
+func (m *refreshingModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	inner := m.current(ctx)
+	resp, err := inner.Generate(ctx, input, opts...)
+	if isUnauthorizedError(err) {
+		if next, ok := m.refreshAfterUnauthorized(ctx, inner); ok {
+			return next.Generate(ctx, input, opts...)
+		}
+	}
+	return resp, err
+}
+
+func (m *refreshingModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	inner := m.current(ctx)
+	reader, err := inner.Stream(ctx, input, opts...)
+	if isUnauthorizedError(err) {
+		if next, ok := m.refreshAfterUnauthorized(ctx, inner); ok {
+			return next.Stream(ctx, input, opts...)
+		}
+	}
+	return reader, err
+}
+
+func (m *refreshingModel) BindTools(tools []*schema.ToolInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tools = tools
+	return m.inner.BindTools(tools)
+}
+
+// current 返回当前底层模型；令牌即将过期时先尝试刷新，刷新失败则继续使用旧令牌。
+func (m *refreshingModel) current(ctx context.Context) model.ChatModel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cred.NeedsRefresh() && !time.Now().Before(m.nextRetry) {
+		if err := m.refreshLocked(ctx, "expiring"); err != nil {
+			m.nextRetry = time.Now().Add(refreshRetryInterval)
+		}
+	}
+	return m.inner
+}
+
+// refreshAfterUnauthorized 在 used 返回 401 后刷新令牌；若其他调用已完成刷新则直接返回新模型。
+func (m *refreshingModel) refreshAfterUnauthorized(ctx context.Context, used model.ChatModel) (model.ChatModel, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inner != used {
+		return m.inner, true
+	}
+	if err := m.refreshLocked(ctx, "unauthorized"); err != nil {
+		return nil, false
+	}
+	return m.inner, true
+}
+
+func (m *refreshingModel) refreshLocked(ctx context.Context, reason string) error {
+	refreshed, err := refreshCredential(m.name, m.key, m.cred, reason)
+	if err != nil {
+		return err
+	}
+	inner, err := m.build(ctx, strings.TrimSpace(refreshed.AccessToken))
+	if err != nil {
+		return fmt.Errorf("rebuild model after token refresh: %w", err)
+	}
+	if m.tools != nil {
+		if err := inner.BindTools(m.tools); err != nil {
+			return fmt.Errorf("rebind tools after token refresh: %w", err)
+		}
+	}
+	m.inner = inner
+	m.cred = refreshed
+	return nil
+}
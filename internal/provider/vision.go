@@ -0,0 +1,38 @@
+package provider
+
+import "strings"
+
+// visionModelPrefixes 是已知支持图片输入的模型名前缀（不含 provider 前缀，如 "openai/"）。
+var visionModelPrefixes = []string{
+	"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4",
+	"claude-3", "claude-sonnet-4", "claude-opus-4", "claude-haiku-4",
+	"gemini", "llava", "pixtral",
+}
+
+// SupportsVision 报告模型是否接受图片输入。除内置列表外，模型名包含 "vision" 或 "-vl"
+// （如 qwen-vl-max）也视为支持；extra 对应 agents.defaults.vision_models，按相同的前缀规则匹配。
+func SupportsVision(modelName string, extra []string) bool {
+	name := strings.ToLower(strings.TrimSpace(modelName))
+	if name == "" {
+		return false
+	}
+	bare := name
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		bare = name[idx+1:]
+	}
+	for _, e := range extra {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e != "" && (strings.HasPrefix(name, e) || strings.HasPrefix(bare, e)) {
+			return true
+		}
+	}
+	if strings.Contains(bare, "vision") || strings.Contains(bare, "-vl") {
+		return true
+	}
+	for _, prefix := range visionModelPrefixes {
+		if strings.HasPrefix(bare, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ package session
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -22,9 +23,12 @@ type Message struct {
 
 // Session 表示一个完整的对话会话，包含唯一的标识符和消息序列。
 type Session struct {
-	Key      string       // 会话的唯一键值
-	Messages []*Message   // 消息历史列表
-	mu       sync.RWMutex // 保护 Messages 列表的并发安全
+	Key      string                // 会话的唯一键值
+	Messages []*Message            // 消息历史列表
+	limit    int                   // 保留的最大消息数，0 表示不限制
+	summary  string                // 已被压缩的早期对话摘要
+	usage    map[string]TokenUsage // 按模型名统计的 Token 用量
+	mu       sync.RWMutex          // 保护 Messages 列表、摘要与用量的并发安全
 }
 
 // AddMessage 向会话中追加一条新消息。
@@ -36,7 +40,7 @@ func (s *Session) AddMessage(role, content string) *Message {
 		Content:   content,
 		Timestamp: time.Now(),
 	}
-	s.Messages = append(s.Messages, msg)
+	s.Messages = trimMessages(append(s.Messages, msg), s.limit)
 	return msg
 }
 
@@ -58,22 +62,55 @@ func (s *Session) GetHistory(limit int) []*Message {
 	return result
 }
 
+// Summary 返回早期对话的摘要；尚未压缩过时为空。
+func (s *Session) Summary() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.summary
+}
+
 // Manager 负责管理内存中的活跃会话，并将其持久化到磁盘。
+// 每个会话保存为 <baseDir>/sessions/<key>.json，写入时先写临时文件再原子替换。
 type Manager struct {
-	dir      string              // 会话文件存储目录
-	sessions map[string]*Session // 内存缓存的会话映射
-	mu       sync.RWMutex
+	dir         string              // 会话文件存储目录
+	maxMessages int                 // 每个会话保留的最大消息数
+	sessions    map[string]*Session // 内存缓存的会话映射
+	mu          sync.RWMutex
+	fileMu      sync.Mutex // 串行化会话文件的读改写
 }
 
-const maxSessionLineBytes = 4 * 1024 * 1024 // 单行消息的最大字节数 (4MB)
+const (
+	maxSessionLineBytes       = 4 * 1024 * 1024 // 旧版 .jsonl 单行消息的最大字节数 (4MB)
+	defaultMaxSessionMessages = 200             // 默认每个会话保留的消息数
+	sessionFileVersion        = 1               // 会话文件格式版本
+)
 
-// NewManager 在指定的基础目录下创建一个新的会话管理器。
+// sessionFile 是会话在磁盘上的 JSON 结构。
+type sessionFile struct {
+	Version   int                   `json:"version"`
+	Key       string                `json:"key"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Summary   string                `json:"summary,omitempty"` // 已被压缩的早期对话摘要
+	Usage     map[string]TokenUsage `json:"usage,omitempty"`   // 按模型名统计的 Token 用量
+	Messages  []*Message            `json:"messages"`
+}
+
+// NewManager 在指定的基础目录下创建一个使用默认历史上限的会话管理器。
 func NewManager(baseDir string) *Manager {
+	return NewManagerWithLimit(baseDir, defaultMaxSessionMessages)
+}
+
+// NewManagerWithLimit 创建一个会话管理器，每个会话最多保留 maxMessages 条消息，超出时裁剪最早的消息。
+func NewManagerWithLimit(baseDir string, maxMessages int) *Manager {
+	if maxMessages <= 0 {
+		maxMessages = defaultMaxSessionMessages
+	}
 	dir := filepath.Join(baseDir, "sessions")
 	os.MkdirAll(dir, 0755)
 	return &Manager{
-		dir:      dir,
-		sessions: make(map[string]*Session),
+		dir:         dir,
+		maxMessages: maxMessages,
+		sessions:    make(map[string]*Session),
 	}
 }
 
@@ -94,10 +131,16 @@ func (m *Manager) GetOrCreate(key string) *Session {
 		return sess
 	}
 
-	sess := &Session{Key: key}
-	if err := m.loadFromDisk(sess); err != nil {
+	sess := &Session{Key: key, limit: m.maxMessages}
+	m.fileMu.Lock()
+	doc, err := m.loadMessages(key)
+	m.fileMu.Unlock()
+	if err != nil {
 		slog.Warn("failed to load session from disk", "session_key", key, "error", err)
 	}
+	sess.Messages = trimMessages(doc.Messages, m.maxMessages)
+	sess.summary = doc.Summary
+	sess.usage = doc.Usage
 	m.sessions[key] = sess
 	return sess
 }
@@ -105,87 +148,227 @@ func (m *Manager) GetOrCreate(key string) *Session {
 // Save 将指定的会话内容完整覆盖写入到磁盘文件中。
 func (m *Manager) Save(sess *Session) error {
 	sess.mu.RLock()
-	defer sess.mu.RUnlock()
+	msgs := append([]*Message(nil), sess.Messages...)
+	summary := sess.summary
+	usage := copyUsage(sess.usage)
+	sess.mu.RUnlock()
 
-	if len(sess.Messages) == 0 {
+	if len(msgs) == 0 && summary == "" && len(usage) == 0 {
 		return nil
 	}
 
-	path := m.sessionPath(sess.Key)
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+	return m.writeMessages(sess.Key, msgs, summary, usage)
+}
 
-	enc := json.NewEncoder(f)
-	for _, msg := range sess.Messages {
-		if err := enc.Encode(msg); err != nil {
-			return err
+// Compact 用摘要替换会话中截至 through（含）的早期消息，并写回磁盘；新摘要应涵盖旧摘要的内容。
+// through 已不在会话中（例如会话被重置）时不做修改并返回 false。
+func (m *Manager) Compact(sess *Session, summary string, through *Message) (bool, error) {
+	sess.mu.Lock()
+	idx := -1
+	for i, msg := range sess.Messages {
+		if msg == through {
+			idx = i
+			break
 		}
 	}
-	return nil
+	if idx < 0 {
+		sess.mu.Unlock()
+		return false, nil
+	}
+	sess.Messages = append([]*Message(nil), sess.Messages[idx+1:]...)
+	sess.summary = summary
+	msgs := append([]*Message(nil), sess.Messages...)
+	usage := copyUsage(sess.usage)
+	sess.mu.Unlock()
+
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+	return true, m.writeMessages(sess.Key, msgs, summary, usage)
 }
 
-// Append 将一组新消息增量追加到指定的会话持久化文件中。
+// Append 将一组新消息追加到指定会话的持久化文件中，并按历史上限裁剪最早的消息。
+// 会话已加载到内存时，同时写入内存中累计的 Token 用量。
 func (m *Manager) Append(key string, msgs ...*Message) error {
-	path := m.sessionPath(key)
-	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	m.mu.RLock()
+	cached, ok := m.sessions[key]
+	m.mu.RUnlock()
+
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+
+	doc, err := m.loadMessages(key)
 	if err != nil {
-		return err
+		slog.Warn("failed to read session before append; rewriting with recovered history", "session_key", key, "error", err)
 	}
-	defer f.Close()
+	usage := doc.Usage
+	if ok {
+		usage = cached.Usage()
+	}
+	return m.writeMessages(key, append(doc.Messages, msgs...), doc.Summary, usage)
+}
 
-	// Use a buffered writer to minimize disk I/O syscalls during multiple small appends.
-	bw := bufio.NewWriter(f)
-	enc := json.NewEncoder(bw)
-	for _, msg := range msgs {
-		if err := enc.Encode(msg); err != nil {
-			return err
+// Reset 清除会话在内存中的历史记录，并从磁盘中永久删除对应的会话文件。
+func (m *Manager) Reset(key string) {
+	_, _ = m.Clear(key)
+}
+
+// Clear 清除会话的历史、摘要与 Token 用量并删除会话文件，会话键保持不变，之后的消息继续写入同一会话。
+// 返回被清除的消息数；删除文件失败时返回错误，内存中的历史仍已清空。
+func (m *Manager) Clear(key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+
+	cleared := 0
+	if sess, ok := m.sessions[key]; ok {
+		sess.mu.Lock()
+		cleared = len(sess.Messages)
+		sess.Messages = nil
+		sess.summary = ""
+		sess.usage = nil
+		sess.mu.Unlock()
+	} else if doc, err := m.loadMessages(key); err == nil {
+		cleared = len(doc.Messages)
+	}
+
+	var errs []error
+	for _, path := range []string{m.sessionPath(key), m.legacySessionPath(key)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
 		}
 	}
-	if err := bw.Flush(); err != nil {
-		return err
+	return cleared, errors.Join(errs...)
+}
+
+// loadMessages 读取会话历史、摘要与 Token 用量。优先读取 .json 文件；若不存在则迁移旧版 .jsonl 文件。
+// 损坏或截断的 .json 文件会被重命名为 .corrupt 并跳过，不会中断调用方。
+// 调用方需持有 fileMu。
+func (m *Manager) loadMessages(key string) (sessionFile, error) {
+	path := m.sessionPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return sessionFile{}, err
 	}
-	return nil
+	if err == nil {
+		var doc sessionFile
+		decodeErr := json.Unmarshal(data, &doc)
+		if decodeErr == nil {
+			doc.Messages = compactMessages(doc.Messages)
+			return doc, nil
+		}
+		corruptPath := path + ".corrupt"
+		_ = os.Rename(path, corruptPath)
+		slog.Warn("session file is corrupt; moved aside", "session_key", key, "path", corruptPath, "error", decodeErr)
+	}
+
+	msgs, legacyErr := m.loadLegacyMessages(key)
+	if len(msgs) == 0 {
+		return sessionFile{}, legacyErr
+	}
+	if err := m.writeMessages(key, msgs, "", nil); err != nil {
+		return sessionFile{Messages: msgs}, fmt.Errorf("migrate legacy session file: %w", err)
+	}
+	_ = os.Remove(m.legacySessionPath(key))
+	slog.Info("migrated legacy session file", "session_key", key, "messages", len(msgs))
+	return sessionFile{Messages: msgs}, legacyErr
 }
 
-func (m *Manager) loadFromDisk(sess *Session) error {
-	path := m.sessionPath(sess.Key)
+// loadLegacyMessages 逐行读取旧版 .jsonl 会话文件，跳过无法解析的行（例如写入中断产生的截断行）。
+func (m *Manager) loadLegacyMessages(key string) ([]*Message, error) {
+	path := m.legacySessionPath(key)
 	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil
+			return nil, nil
 		}
-		return err
+		return nil, err
 	}
 	defer f.Close()
 
+	var msgs []*Message
 	scanner := bufio.NewScanner(f)
 	scanner.Buffer(make([]byte, 0, 64*1024), maxSessionLineBytes)
 	for scanner.Scan() {
 		var msg Message
 		if err := json.Unmarshal(scanner.Bytes(), &msg); err == nil {
-			sess.Messages = append(sess.Messages, &msg)
+			msgs = append(msgs, &msg)
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scan session file %s: %w", path, err)
+		return msgs, fmt.Errorf("scan session file %s: %w", path, err)
+	}
+	return msgs, nil
+}
+
+// writeMessages 按历史上限裁剪后，以临时文件加重命名的方式原子写入会话文件。调用方需持有 fileMu。
+func (m *Manager) writeMessages(key string, msgs []*Message, summary string, usage map[string]TokenUsage) error {
+	payload, err := json.MarshalIndent(sessionFile{
+		Version:   sessionFileVersion,
+		Key:       key,
+		UpdatedAt: time.Now().UTC(),
+		Summary:   summary,
+		Usage:     usage,
+		Messages:  trimMessages(compactMessages(msgs), m.maxMessages),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode session file: %w", err)
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("create session dir: %w", err)
+	}
+	path := m.sessionPath(key)
+	tmpFile, err := os.CreateTemp(m.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp session file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(payload); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("write temp session file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("close temp session file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		// Windows 上目标文件存在时 Rename 可能失败，删除后重试。
+		if removeErr := os.Remove(path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+			return fmt.Errorf("replace session file: rename failed (%v), remove failed (%v)", err, removeErr)
+		}
+		if retryErr := os.Rename(tmpPath, path); retryErr != nil {
+			return fmt.Errorf("replace session file after remove: %w", retryErr)
+		}
 	}
 	return nil
 }
 
-// Reset 清除会话在内存中的历史记录，并从磁盘中永久删除对应的会话文件。
-func (m *Manager) Reset(key string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// compactMessages 丢弃 nil 条目，兼容手工编辑或部分损坏的文件。
+func compactMessages(msgs []*Message) []*Message {
+	out := make([]*Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if msg != nil {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
 
-	if sess, ok := m.sessions[key]; ok {
-		sess.mu.Lock()
-		sess.Messages = nil
-		sess.mu.Unlock()
+// trimMessages 仅保留最近的 limit 条消息。
+func trimMessages(msgs []*Message, limit int) []*Message {
+	if limit <= 0 || len(msgs) <= limit {
+		return msgs
 	}
-	os.Remove(m.sessionPath(key))
+	return append([]*Message(nil), msgs[len(msgs)-limit:]...)
 }
 
 // sessionPathReplacer is cached globally to avoid O(N) allocation and
@@ -194,6 +377,9 @@ var sessionPathReplacer = strings.NewReplacer(":", "_", "/", "_", "\\", "_")
 
 func (m *Manager) sessionPath(key string) string {
 	// 转换键值中的敏感字符以生成安全的文件名
-	safeKey := sessionPathReplacer.Replace(key)
-	return filepath.Join(m.dir, safeKey+".jsonl")
+	return filepath.Join(m.dir, sessionPathReplacer.Replace(key)+".json")
+}
+
+func (m *Manager) legacySessionPath(key string) string {
+	return filepath.Join(m.dir, sessionPathReplacer.Replace(key)+".jsonl")
 }
@@ -1,6 +1,8 @@
 package session
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -151,3 +153,215 @@ func TestSession_AppendAndLoad(t *testing.T) {
 		t.Errorf("msg 2 content mismatch")
 	}
 }
+
+func TestManager_SaveWritesJSONDocument(t *testing.T) {
+	baseDir := t.TempDir()
+	mgr := NewManager(baseDir)
+	sess := mgr.GetOrCreate("telegram:42")
+	sess.AddMessage("user", "hello")
+
+	if err := mgr.Save(sess); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(baseDir, "sessions", "telegram_42.json"))
+	if err != nil {
+		t.Fatalf("expected session json file: %v", err)
+	}
+	var doc sessionFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("session file is not valid json: %v", err)
+	}
+	if doc.Version != sessionFileVersion || doc.Key != "telegram:42" || len(doc.Messages) != 1 {
+		t.Fatalf("unexpected session document: %+v", doc)
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(baseDir, "sessions"))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Fatalf("expected temp file to be cleaned up, found %s", entry.Name())
+		}
+	}
+}
+
+func TestManager_HistoryCapTrimsOldestMessages(t *testing.T) {
+	baseDir := t.TempDir()
+	mgr := NewManagerWithLimit(baseDir, 3)
+	sess := mgr.GetOrCreate("capped")
+	for i := 1; i <= 5; i++ {
+		msg := sess.AddMessage("user", fmt.Sprintf("m%d", i))
+		if err := mgr.Append(sess.Key, msg); err != nil {
+			t.Fatalf("Append error: %v", err)
+		}
+	}
+
+	if got := sess.GetHistory(0); len(got) != 3 || got[0].Content != "m3" {
+		t.Fatalf("expected in-memory history trimmed to m3..m5, got %d messages", len(got))
+	}
+
+	loaded := NewManagerWithLimit(baseDir, 3).GetOrCreate("capped").GetHistory(0)
+	if len(loaded) != 3 || loaded[0].Content != "m3" || loaded[2].Content != "m5" {
+		t.Fatalf("expected persisted history trimmed to m3..m5, got %+v", loaded)
+	}
+}
+
+func TestManager_MigratesLegacyJSONLWithTruncatedLine(t *testing.T) {
+	baseDir := t.TempDir()
+	sessDir := filepath.Join(baseDir, "sessions")
+	if err := os.MkdirAll(sessDir, 0755); err != nil {
+		t.Fatalf("MkdirAll error: %v", err)
+	}
+	legacy := `{"role":"user","content":"old question"}
+{"role":"assistant","content":"old answer"}
+{"role":"user","content":"trunc`
+	legacyPath := filepath.Join(sessDir, "cli_direct.jsonl")
+	if err := os.WriteFile(legacyPath, []byte(legacy), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	history := NewManager(baseDir).GetOrCreate("cli:direct").GetHistory(50)
+	if len(history) != 2 || history[1].Content != "old answer" {
+		t.Fatalf("expected 2 recovered legacy messages, got %+v", history)
+	}
+	if _, err := os.Stat(filepath.Join(sessDir, "cli_direct.json")); err != nil {
+		t.Fatalf("expected migrated json file: %v", err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Fatalf("expected legacy file to be removed after migration, got %v", err)
+	}
+}
+
+func TestManager_CorruptJSONStartsEmptyAndKeepsBackup(t *testing.T) {
+	baseDir := t.TempDir()
+	sessDir := filepath.Join(baseDir, "sessions")
+	if err := os.MkdirAll(sessDir, 0755); err != nil {
+		t.Fatalf("MkdirAll error: %v", err)
+	}
+	path := filepath.Join(sessDir, "broken.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"messages":[{"role":"user","con`), 0644); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	mgr := NewManager(baseDir)
+	sess := mgr.GetOrCreate("broken")
+	if got := len(sess.GetHistory(0)); got != 0 {
+		t.Fatalf("expected empty history for corrupt file, got %d", got)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Fatalf("expected corrupt file to be kept as backup: %v", err)
+	}
+
+	msg := sess.AddMessage("user", "fresh start")
+	if err := mgr.Append(sess.Key, msg); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if got := NewManager(baseDir).GetOrCreate("broken").GetHistory(0); len(got) != 1 {
+		t.Fatalf("expected 1 message after recovery, got %d", len(got))
+	}
+}
+
+func TestManager_CompactReplacesOldMessagesWithSummary(t *testing.T) {
+	baseDir := t.TempDir()
+	mgr := NewManager(baseDir)
+	sess := mgr.GetOrCreate("compact-test")
+
+	var msgs []*Message
+	for i := 0; i < 4; i++ {
+		msgs = append(msgs, sess.AddMessage("user", fmt.Sprintf("q%d", i)), sess.AddMessage("assistant", fmt.Sprintf("a%d", i)))
+	}
+	if err := mgr.Append(sess.Key, msgs...); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+
+	ok, err := mgr.Compact(sess, "user asked q0-q2", msgs[5])
+	if err != nil || !ok {
+		t.Fatalf("Compact = %v, %v", ok, err)
+	}
+	if history := sess.GetHistory(0); len(history) != 2 || history[0].Content != "q3" {
+		t.Fatalf("expected only the last turn kept, got %+v", history)
+	}
+
+	// 压缩后追加的消息保留摘要
+	next := sess.AddMessage("user", "q4")
+	if err := mgr.Append(sess.Key, next); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	loaded := NewManager(baseDir).GetOrCreate("compact-test")
+	if loaded.Summary() != "user asked q0-q2" || len(loaded.GetHistory(0)) != 3 {
+		t.Fatalf("expected summary and 3 messages after reload, got %q %d", loaded.Summary(), len(loaded.GetHistory(0)))
+	}
+
+	// 已不在会话中的消息不会触发压缩
+	if ok, err := mgr.Compact(sess, "stale", msgs[1]); ok || err != nil {
+		t.Fatalf("expected stale compaction to be skipped, got %v, %v", ok, err)
+	}
+
+	mgr.Reset(sess.Key)
+	if sess.Summary() != "" {
+		t.Fatalf("expected reset to clear summary, got %q", sess.Summary())
+	}
+}
+
+func TestManager_ClearDropsHistoryAndKeepsKey(t *testing.T) {
+	baseDir := t.TempDir()
+	mgr := NewManager(baseDir)
+	sess := mgr.GetOrCreate("telegram:42")
+	msgs := []*Message{sess.AddMessage("user", "hi"), sess.AddMessage("assistant", "hello")}
+	if err := mgr.Append(sess.Key, msgs...); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+
+	// 未缓存在内存中的会话从磁盘统计被清除的消息数
+	cleared, err := NewManager(baseDir).Clear("telegram:42")
+	if err != nil || cleared != 2 {
+		t.Fatalf("Clear = %d, %v; want 2, nil", cleared, err)
+	}
+	if _, err := os.Stat(mgr.sessionPath("telegram:42")); !os.IsNotExist(err) {
+		t.Fatalf("expected session file to be removed, stat err = %v", err)
+	}
+
+	if cleared, err := mgr.Clear("telegram:42"); err != nil || cleared != 2 || len(sess.GetHistory(0)) != 0 {
+		t.Fatalf("expected cached history to be cleared, got %d, %v, %d left", cleared, err, len(sess.GetHistory(0)))
+	}
+
+	next := sess.AddMessage("user", "again")
+	if err := mgr.Append(sess.Key, next); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if loaded := NewManager(baseDir).GetOrCreate("telegram:42"); len(loaded.GetHistory(0)) != 1 {
+		t.Fatalf("expected the same session key to keep receiving messages, got %d", len(loaded.GetHistory(0)))
+	}
+}
+
+func TestManager_UsagePersistsWithSession(t *testing.T) {
+	dir := t.TempDir()
+	mgr := NewManager(dir)
+	sess := mgr.GetOrCreate("telegram:1")
+	sess.AddUsage("anthropic/claude-sonnet-4-5", 100, 20)
+	sess.AddUsage("anthropic/claude-sonnet-4-5", 50, 10)
+	sess.AddUsage("deepseek/deepseek-chat", 7, 3)
+	if err := mgr.Append(sess.Key, &Message{Role: "user", Content: "hi"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	reloaded := NewManager(dir).GetOrCreate("telegram:1").Usage()
+	want := TokenUsage{PromptTokens: 150, CompletionTokens: 30, Calls: 2}
+	if got := reloaded["anthropic/claude-sonnet-4-5"]; got != want {
+		t.Fatalf("expected %+v after reload, got %+v", want, got)
+	}
+	if got := reloaded["deepseek/deepseek-chat"].Total(); got != 10 {
+		t.Fatalf("expected 10 deepseek tokens, got %d", got)
+	}
+
+	list, err := mgr.ListUsage()
+	if err != nil || len(list) != 1 || list[0].Key != "telegram:1" || len(list[0].Usage) != 2 {
+		t.Fatalf("unexpected usage list %+v (%v)", list, err)
+	}
+
+	if _, err := mgr.Clear("telegram:1"); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if usage := mgr.GetOrCreate("telegram:1").Usage(); len(usage) != 0 {
+		t.Fatalf("expected usage to be cleared, got %+v", usage)
+	}
+}
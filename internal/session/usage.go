@@ -0,0 +1,89 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TokenUsage 是会话内某个模型累计的 Token 用量。
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`     // 累计输入 Token 数
+	CompletionTokens int64 `json:"completion_tokens"` // 累计输出 Token 数
+	Calls            int64 `json:"calls"`             // 模型调用次数
+}
+
+// Total 返回输入与输出 Token 之和。
+func (u TokenUsage) Total() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// AddUsage 累加一次模型调用的 Token 用量，按模型名分别统计；随下一次写盘（追加消息、压缩等）持久化。
+func (s *Session) AddUsage(model string, promptTokens, completionTokens int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage == nil {
+		s.usage = make(map[string]TokenUsage)
+	}
+	u := s.usage[model]
+	u.PromptTokens += int64(promptTokens)
+	u.CompletionTokens += int64(completionTokens)
+	u.Calls++
+	s.usage[model] = u
+}
+
+// Usage 返回会话按模型名统计的 Token 用量副本。
+func (s *Session) Usage() map[string]TokenUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyUsage(s.usage)
+}
+
+// SessionUsage 是某个会话的 Token 用量，供 golem usage 汇总。
+type SessionUsage struct {
+	Key   string
+	Usage map[string]TokenUsage
+}
+
+// ListUsage 读取磁盘上所有会话文件中记录的 Token 用量，按会话键排序；无法解析的文件会被跳过。
+func (m *Manager) ListUsage() ([]SessionUsage, error) {
+	m.fileMu.Lock()
+	defer m.fileMu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(m.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var list []SessionUsage
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read session file %s: %w", path, err)
+		}
+		var doc sessionFile
+		if err := json.Unmarshal(data, &doc); err != nil || len(doc.Usage) == 0 {
+			continue
+		}
+		key := doc.Key
+		if key == "" {
+			key = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		list = append(list, SessionUsage{Key: key, Usage: doc.Usage})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Key < list[j].Key })
+	return list, nil
+}
+
+func copyUsage(usage map[string]TokenUsage) map[string]TokenUsage {
+	if len(usage) == 0 {
+		return nil
+	}
+	out := make(map[string]TokenUsage, len(usage))
+	for model, u := range usage {
+		out[model] = u
+	}
+	return out
+}
@@ -8,13 +8,19 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
+
+	"github.com/MEKXH/golem/internal/httpclient"
 )
 
 const defaultSkillsIndexURL = "https://raw.githubusercontent.com/MEKXH/golem-skills/main/skills.json"
 const defaultGitHubRawBaseURL = "https://raw.githubusercontent.com"
 
+// skillNamePattern 约束本地新建技能的目录名。
+var skillNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
 // AvailableSkill 表示远程技能索引中的一个可用技能条目。
 type AvailableSkill struct {
 	Name        string   `json:"name"`        // 技能名称
@@ -35,10 +41,8 @@ type Installer struct {
 // NewInstaller 为指定的工作区创建一个新的技能安装器。
 func NewInstaller(workspacePath string) *Installer {
 	return &Installer{
-		skillsDir: filepath.Join(workspacePath, "skills"),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		skillsDir:      filepath.Join(workspacePath, "skills"),
+		httpClient:     httpclient.New(httpclient.Timeout(30 * time.Second)),
 		skillsIndexURL: resolveSkillsIndexURL(),
 		githubRawBase:  resolveGitHubRawBaseURL(),
 	}
@@ -140,6 +144,57 @@ func (i *Installer) Uninstall(name string) error {
 	return os.RemoveAll(skillDir)
 }
 
+// Create 在工作区技能目录下创建名为 name 的新技能，写入带 frontmatter 的 SKILL.md 模板，返回文件路径。
+// 技能已存在时返回错误，不覆盖已有内容。
+func (i *Installer) Create(name, description string) (string, error) {
+	name = strings.TrimSpace(name)
+	if !skillNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid skill name %q: use letters, digits, '-' or '_'", name)
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		description = "Describe when the agent should use this skill."
+	}
+
+	skillDir := filepath.Join(i.skillsDir, name)
+	if _, err := os.Stat(skillDir); err == nil {
+		return "", fmt.Errorf("skill already exists: %s", name)
+	}
+	if err := os.MkdirAll(skillDir, 0755); err != nil {
+		return "", fmt.Errorf("create skill dir: %w", err)
+	}
+
+	path := filepath.Join(skillDir, "SKILL.md")
+	if err := os.WriteFile(path, []byte(skillTemplate(name, description)), 0644); err != nil {
+		return "", fmt.Errorf("write skill file: %w", err)
+	}
+	return path, nil
+}
+
+// skillTemplate 生成新技能的 SKILL.md 模板。
+func skillTemplate(name, description string) string {
+	return fmt.Sprintf(`---
+name: %s
+description: %q
+---
+
+# %s
+
+## When to use
+
+Describe the requests or situations this skill applies to.
+
+## Steps
+
+1. First step the agent should take.
+2. Next step.
+
+## Notes
+
+- Constraints, preferred tools, or output format.
+`, name, description, name)
+}
+
 // Search 从配置的索引地址获取所有可用的远程技能列表。
 func (i *Installer) Search(ctx context.Context) ([]AvailableSkill, error) {
 	if strings.TrimSpace(i.skillsIndexURL) == "" {
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -163,3 +164,34 @@ func TestInstallerSearch_Non200ReturnsError(t *testing.T) {
 		t.Fatal("expected error for non-200 response")
 	}
 }
+
+func TestInstallerCreate_WritesLoadableTemplate(t *testing.T) {
+	workspace := t.TempDir()
+	installer := NewInstaller(workspace)
+
+	path, err := installer.Create("release-notes", "Draft release notes from merged changes")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if want := filepath.Join(workspace, "skills", "release-notes", "SKILL.md"); path != want {
+		t.Fatalf("expected path %s, got %s", want, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	name, desc := parseSkillFrontmatter("release-notes", string(data))
+	if name != "release-notes" || desc != "Draft release notes from merged changes" {
+		t.Fatalf("unexpected frontmatter: name=%q description=%q", name, desc)
+	}
+
+	if _, err := installer.Create("release-notes", ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected already exists error, got %v", err)
+	}
+	for _, bad := range []string{"", "../escape", "a/b", "-lead"} {
+		if _, err := installer.Create(bad, ""); err == nil {
+			t.Fatalf("expected invalid name error for %q", bad)
+		}
+	}
+}
@@ -77,6 +77,17 @@ func (l *Loader) LoadSkill(name string) (string, error) {
 	return "", fmt.Errorf("skill not found: %s", name)
 }
 
+// FindSkill 按名称查找技能的元数据，名称可以是 frontmatter 中的 name 或技能目录名。
+func (l *Loader) FindSkill(name string) (SkillInfo, bool) {
+	name = strings.TrimSpace(name)
+	for _, s := range l.ListSkills() {
+		if s.Name == name || filepath.Base(filepath.Dir(s.Path)) == name {
+			return s, true
+		}
+	}
+	return SkillInfo{}, false
+}
+
 // BuildSkillsSummary 生成所有已安装技能的格式化摘要字符串，通常用于注入到系统提示词 (System Prompt) 中。
 func (l *Loader) BuildSkillsSummary() string {
 	return BuildSkillsSummaryFor(l.ListSkills())
@@ -129,18 +140,16 @@ func parseSkillFrontmatter(dirName, content string) (name, description string) {
 	name = dirName
 	description = "(no description)"
 
-	content = strings.TrimSpace(content)
-	if !strings.HasPrefix(content, "---") {
+	frontmatter, ok := splitFrontmatter(content)
+	if !ok {
 		return
 	}
 
-	end := strings.Index(content[3:], "---")
-	if end < 0 {
-		return
-	}
-
-	frontmatter := content[3 : 3+end]
 	for _, line := range strings.Split(frontmatter, "\n") {
+		// 只读取顶层键，忽略 tools 等嵌套结构中的同名字段
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '-' {
+			continue
+		}
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, "name:") {
 			name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
@@ -0,0 +1,214 @@
+package skills
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultToolTimeoutSeconds = 30
+
+var (
+	validToolNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	placeholderPattern   = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_]+)\s*\}\}`)
+)
+
+// ToolParameter 描述技能工具的一个输入参数。
+type ToolParameter struct {
+	Type        string `yaml:"type"`        // string | number | integer | boolean | array | object，默认 string
+	Description string `yaml:"description"` // 参数说明
+	Required    bool   `yaml:"required"`    // 是否必填
+}
+
+// ExecHandler 以 shell 命令实现技能工具；参数通过环境变量 GOLEM_ARG_<NAME> 与标准输入的 JSON 传入。
+type ExecHandler struct {
+	Command string `yaml:"command"`
+}
+
+// HTTPHandler 以 HTTP 请求实现技能工具；URL 中的 {{param}} 以转义后的参数值替换，
+// 非 GET 请求以参数 JSON 作为请求体。
+type HTTPHandler struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// ToolSpec 是技能清单 frontmatter 中 tools 列表的一项，校验后附带所属技能的信息。
+type ToolSpec struct {
+	Name           string                   `yaml:"name"`
+	Description    string                   `yaml:"description"`
+	Parameters     map[string]ToolParameter `yaml:"parameters"`
+	Exec           *ExecHandler             `yaml:"exec"`
+	HTTP           *HTTPHandler             `yaml:"http"`
+	TimeoutSeconds int                      `yaml:"timeout_seconds"`
+
+	Skill    string `yaml:"-"` // 声明该工具的技能名称
+	SkillDir string `yaml:"-"` // 技能目录，exec 命令在此目录下运行
+}
+
+// LoadTools 读取所有已发现技能在清单中声明的工具。单个技能的清单无效时跳过该技能并在 errs 中报告，
+// 不影响其他技能；同名工具只保留优先级更高（工作区 > 全局 > 内置）的声明。
+func (l *Loader) LoadTools() (specs []ToolSpec, errs []error) {
+	seen := make(map[string]string)
+	for _, info := range l.ListSkills() {
+		data, err := os.ReadFile(info.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("skill %s: %w", info.Name, err))
+			continue
+		}
+		declared, err := parseSkillTools(string(data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("skill %s: %w", info.Name, err))
+			continue
+		}
+		for _, spec := range declared {
+			if owner, ok := seen[spec.Name]; ok {
+				errs = append(errs, fmt.Errorf("skill %s: tool %q already declared by skill %s", info.Name, spec.Name, owner))
+				continue
+			}
+			seen[spec.Name] = info.Name
+			spec.Skill = info.Name
+			spec.SkillDir = filepath.Dir(info.Path)
+			specs = append(specs, spec)
+		}
+	}
+	return specs, errs
+}
+
+// parseSkillTools 解析并校验 frontmatter 中的 tools 列表；没有 frontmatter 或未声明工具时返回空列表。
+func parseSkillTools(content string) ([]ToolSpec, error) {
+	frontmatter, ok := splitFrontmatter(content)
+	if !ok {
+		return nil, nil
+	}
+	var manifest struct {
+		Tools []ToolSpec `yaml:"tools"`
+	}
+	if err := yaml.Unmarshal([]byte(frontmatter), &manifest); err != nil {
+		return nil, fmt.Errorf("parse frontmatter: %w", err)
+	}
+
+	specs := make([]ToolSpec, 0, len(manifest.Tools))
+	for _, raw := range manifest.Tools {
+		spec, err := validateToolSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// validateToolSpec 规范化并校验单个工具声明。
+func validateToolSpec(spec ToolSpec) (ToolSpec, error) {
+	spec.Name = strings.TrimSpace(spec.Name)
+	if !validToolNamePattern.MatchString(spec.Name) {
+		return ToolSpec{}, fmt.Errorf("tool name %q must match %s", spec.Name, validToolNamePattern.String())
+	}
+	spec.Description = strings.TrimSpace(spec.Description)
+	if spec.Description == "" {
+		return ToolSpec{}, fmt.Errorf("tool %q description is required", spec.Name)
+	}
+
+	params := make(map[string]ToolParameter, len(spec.Parameters))
+	for key, param := range spec.Parameters {
+		name := strings.TrimSpace(key)
+		if !validToolNamePattern.MatchString(name) {
+			return ToolSpec{}, fmt.Errorf("tool %q parameter name %q must match %s", spec.Name, key, validToolNamePattern.String())
+		}
+		param.Type = strings.ToLower(strings.TrimSpace(param.Type))
+		if param.Type == "" {
+			param.Type = "string"
+		}
+		switch param.Type {
+		case "string", "number", "integer", "boolean", "array", "object":
+		default:
+			return ToolSpec{}, fmt.Errorf("tool %q parameter %q has unsupported type %q", spec.Name, name, param.Type)
+		}
+		param.Description = strings.TrimSpace(param.Description)
+		params[name] = param
+	}
+	spec.Parameters = params
+
+	if spec.TimeoutSeconds < 0 {
+		return ToolSpec{}, fmt.Errorf("tool %q timeout_seconds must be >= 0", spec.Name)
+	}
+	if spec.TimeoutSeconds == 0 {
+		spec.TimeoutSeconds = defaultToolTimeoutSeconds
+	}
+
+	switch {
+	case spec.Exec != nil && spec.HTTP != nil:
+		return ToolSpec{}, fmt.Errorf("tool %q must declare only one of exec or http", spec.Name)
+	case spec.Exec != nil:
+		spec.Exec.Command = strings.TrimSpace(spec.Exec.Command)
+		if spec.Exec.Command == "" {
+			return ToolSpec{}, fmt.Errorf("tool %q exec.command is required", spec.Name)
+		}
+	case spec.HTTP != nil:
+		if err := validateHTTPHandler(spec.Name, spec.HTTP, params); err != nil {
+			return ToolSpec{}, err
+		}
+	default:
+		return ToolSpec{}, fmt.Errorf("tool %q must declare an exec or http handler", spec.Name)
+	}
+	return spec, nil
+}
+
+func validateHTTPHandler(toolName string, h *HTTPHandler, params map[string]ToolParameter) error {
+	h.Method = strings.ToUpper(strings.TrimSpace(h.Method))
+	if h.Method == "" {
+		h.Method = "GET"
+	}
+	switch h.Method {
+	case "GET", "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return fmt.Errorf("tool %q http.method %q is not supported", toolName, h.Method)
+	}
+
+	h.URL = strings.TrimSpace(h.URL)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(h.URL, -1) {
+		if _, ok := params[m[1]]; !ok {
+			return fmt.Errorf("tool %q http.url references undeclared parameter %q", toolName, m[1])
+		}
+	}
+	parsed, err := url.Parse(placeholderPattern.ReplaceAllString(h.URL, "x"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("tool %q http.url must be an absolute http(s) URL", toolName)
+	}
+	return nil
+}
+
+// ExpandURL 以 args 中的参数值替换 URL 模板中的 {{param}}，值经过转义，不能改变 URL 的结构；缺失的参数替换为空串。
+func (h *HTTPHandler) ExpandURL(args map[string]any) string {
+	return placeholderPattern.ReplaceAllStringFunc(h.URL, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := args[name]
+		if !ok || value == nil {
+			return ""
+		}
+		text, isString := value.(string)
+		if !isString {
+			text = fmt.Sprint(value)
+		}
+		return strings.ReplaceAll(url.QueryEscape(text), "+", "%20")
+	})
+}
+
+// splitFrontmatter 返回技能 Markdown 开头两行 --- 之间的 YAML 内容。
+func splitFrontmatter(content string) (string, bool) {
+	content = strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n"))
+	if !strings.HasPrefix(content, "---") {
+		return "", false
+	}
+	end := strings.Index(content[3:], "\n---")
+	if end < 0 {
+		return "", false
+	}
+	return content[3 : 3+end], true
+}
@@ -0,0 +1,124 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const skillWithTools = `---
+name: weather
+description: "Weather lookups"
+tools:
+  - name: weather_now
+    description: Current weather for a city
+    parameters:
+      city:
+        type: string
+        description: City name
+        required: true
+    http:
+      url: "https://wttr.in/{{city}}?format=3"
+  - name: weather_log
+    description: Append a reading to the local log
+    parameters:
+      reading:
+        required: true
+    exec:
+      command: echo "$GOLEM_ARG_READING" >> weather.log
+    timeout_seconds: 5
+---
+
+# weather
+`
+
+func writeSkillFile(t *testing.T, baseDir, dirName, content string) {
+	t.Helper()
+	dir := filepath.Join(baseDir, dirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll(%s): %v", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile(%s): %v", dirName, err)
+	}
+}
+
+func TestLoaderLoadTools_ParsesHandlersAndKeepsSkillName(t *testing.T) {
+	tmp := t.TempDir()
+	workspaceSkills := filepath.Join(tmp, "workspace", "skills")
+	writeSkillFile(t, workspaceSkills, "weather", skillWithTools)
+
+	loader := &Loader{
+		workspaceSkills: workspaceSkills,
+		globalSkills:    filepath.Join(tmp, "global"),
+		builtinSkills:   filepath.Join(tmp, "builtin"),
+	}
+
+	skills := loader.ListSkills()
+	if len(skills) != 1 || skills[0].Name != "weather" || skills[0].Description != "Weather lookups" {
+		t.Fatalf("nested tool fields must not override the skill manifest, got %+v", skills)
+	}
+
+	specs, errs := loader.LoadTools()
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(specs))
+	}
+
+	now := specs[0]
+	if now.Name != "weather_now" || now.HTTP == nil || now.HTTP.Method != "GET" || now.TimeoutSeconds != defaultToolTimeoutSeconds {
+		t.Fatalf("unexpected http tool: %+v", now)
+	}
+	if now.Skill != "weather" || now.SkillDir != filepath.Join(workspaceSkills, "weather") {
+		t.Fatalf("expected owning skill to be recorded, got skill=%q dir=%q", now.Skill, now.SkillDir)
+	}
+	if got := now.HTTP.ExpandURL(map[string]any{"city": "New York?x=1&y"}); got != "https://wttr.in/New%20York%3Fx%3D1%26y?format=3" {
+		t.Fatalf("expected escaped placeholder, got %s", got)
+	}
+
+	logTool := specs[1]
+	if logTool.Exec == nil || logTool.TimeoutSeconds != 5 || logTool.Parameters["reading"].Type != "string" {
+		t.Fatalf("unexpected exec tool: %+v", logTool)
+	}
+}
+
+func TestLoaderLoadTools_SkipsInvalidSkillsAndDuplicates(t *testing.T) {
+	tmp := t.TempDir()
+	workspaceSkills := filepath.Join(tmp, "workspace", "skills")
+	globalSkills := filepath.Join(tmp, "global", "skills")
+
+	writeSkillFile(t, workspaceSkills, "weather", skillWithTools)
+	writeSkillFile(t, workspaceSkills, "broken", "---\nname: broken\ntools:\n  - name: Bad-Name\n    description: x\n    exec:\n      command: true\n---\n")
+	writeSkillFile(t, workspaceSkills, "nohandler", "---\nname: nohandler\ntools:\n  - name: lonely\n    description: no handler\n---\n")
+	writeSkillFile(t, workspaceSkills, "badurl", "---\nname: badurl\ntools:\n  - name: fetcher\n    description: x\n    http:\n      url: \"https://example.com/{{missing}}\"\n---\n")
+	writeSkillFile(t, globalSkills, "weather-copy", strings.Replace(skillWithTools, "name: weather\n", "name: weather-copy\n", 1))
+
+	loader := &Loader{
+		workspaceSkills: workspaceSkills,
+		globalSkills:    globalSkills,
+		builtinSkills:   filepath.Join(tmp, "builtin"),
+	}
+	specs, errs := loader.LoadTools()
+	if len(specs) != 2 {
+		t.Fatalf("expected only the valid weather tools, got %+v", specs)
+	}
+	for _, spec := range specs {
+		if spec.Skill != "weather" {
+			t.Fatalf("expected workspace skill to own %s, got %s", spec.Name, spec.Skill)
+		}
+	}
+
+	joined := make([]string, 0, len(errs))
+	for _, err := range errs {
+		joined = append(joined, err.Error())
+	}
+	all := strings.Join(joined, "\n")
+	for _, want := range []string{"Bad-Name", "exec or http handler", "undeclared parameter \"missing\"", "already declared by skill weather"} {
+		if !strings.Contains(all, want) {
+			t.Fatalf("expected error containing %q, got:\n%s", want, all)
+		}
+	}
+}
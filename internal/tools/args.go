@@ -0,0 +1,174 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+// ArgumentError 表示模型给出的工具参数不是合法 JSON 或与工具的参数 Schema 不符。
+// 错误文本会原样回传给模型，因此需要说明具体问题与期望的字段，便于模型自行修正。
+type ArgumentError struct {
+	Tool     string // 工具名称
+	Reason   string // 具体问题，如 "malformed JSON: unexpected end of JSON input"
+	Expected string // 期望的参数字段说明，如 "path (string, required), content (string)"
+}
+
+func (e *ArgumentError) Error() string {
+	msg := fmt.Sprintf("invalid arguments for %s: %s", e.Tool, e.Reason)
+	if e.Expected != "" {
+		msg += "; expected a JSON object with fields: " + e.Expected
+	}
+	return msg + ". Fix the arguments and call the tool again"
+}
+
+// InvalidArguments 标记该错误源于非法参数，供 metrics 等包在不依赖本包的情况下识别。
+func (e *ArgumentError) InvalidArguments() bool { return true }
+
+// IsArgumentError 报告 err 是否为（或包装了）工具参数错误。
+func IsArgumentError(err error) bool {
+	var argErr *ArgumentError
+	return errors.As(err, &argErr)
+}
+
+// paramSchema 是工具参数 JSON Schema 中做参数校验所需的部分。
+type paramSchema struct {
+	Properties map[string]struct {
+		Type string `json:"type"`
+	} `json:"properties"`
+	Required []string `json:"required"`
+}
+
+// toolParamSchema 返回工具声明的参数 Schema；工具未声明参数或 Schema 无法解析时返回 nil。
+func toolParamSchema(ctx context.Context, t tool.InvokableTool) *paramSchema {
+	info, err := t.Info(ctx)
+	if err != nil || info == nil || info.ParamsOneOf == nil {
+		return nil
+	}
+	js, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || js == nil {
+		return nil
+	}
+	raw, err := json.Marshal(js)
+	if err != nil {
+		return nil
+	}
+	var ps paramSchema
+	if json.Unmarshal(raw, &ps) != nil {
+		return nil
+	}
+	return &ps
+}
+
+// describe 按字段名排序列出参数及其类型，必填字段标注 required。
+func (s *paramSchema) describe() string {
+	if s == nil || len(s.Properties) == 0 {
+		return ""
+	}
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		var attrs []string
+		if typ := s.Properties[name].Type; typ != "" {
+			attrs = append(attrs, typ)
+		}
+		if required[name] {
+			attrs = append(attrs, "required")
+		}
+		if len(attrs) > 0 {
+			parts = append(parts, fmt.Sprintf("%s (%s)", name, strings.Join(attrs, ", ")))
+		} else {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// validateArguments 在执行前检查参数：必须是合法 JSON；工具声明了 Schema 时还需为对象、
+// 包含全部必填字段且基础类型匹配。空参数交给工具自行处理。
+func validateArguments(ctx context.Context, name string, t tool.InvokableTool, argsJSON string) error {
+	trimmed := strings.TrimSpace(argsJSON)
+	if trimmed == "" {
+		return nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return &ArgumentError{
+			Tool:     name,
+			Reason:   "malformed JSON: " + err.Error(),
+			Expected: toolParamSchema(ctx, t).describe(),
+		}
+	}
+
+	ps := toolParamSchema(ctx, t)
+	if ps == nil {
+		return nil
+	}
+	fields, ok := decoded.(map[string]any)
+	if !ok {
+		return &ArgumentError{Tool: name, Reason: "arguments must be a JSON object", Expected: ps.describe()}
+	}
+	for _, field := range ps.Required {
+		if _, present := fields[field]; !present {
+			return &ArgumentError{Tool: name, Reason: fmt.Sprintf("missing required field %q", field), Expected: ps.describe()}
+		}
+	}
+	for field, value := range fields {
+		prop, declared := ps.Properties[field]
+		if !declared || value == nil || jsonTypeMatches(prop.Type, value) {
+			continue
+		}
+		return &ArgumentError{
+			Tool:     name,
+			Reason:   fmt.Sprintf("field %q must be of type %s", field, prop.Type),
+			Expected: ps.describe(),
+		}
+	}
+	return nil
+}
+
+// jsonTypeMatches 报告解码后的 JSON 值是否符合 Schema 中声明的基础类型；未知类型视为匹配。
+func jsonTypeMatches(typ string, value any) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	default:
+		return true
+	}
+}
+
+// isUnmarshalFailure 识别 InferTool 生成的工具在解析参数失败时返回的错误。
+func isUnmarshalFailure(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "failed to unmarshal arguments")
+}
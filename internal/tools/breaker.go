@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BreakerState 是单个工具熔断器的状态。
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行
+	BreakerOpen     BreakerState = "open"      // 冷却中，调用直接短路
+	BreakerHalfOpen BreakerState = "half_open" // 冷却结束，放行一次试探调用
+)
+
+// BreakerConfig 描述工具熔断策略：窗口期内连续的依赖故障达到阈值后熔断，冷却结束后半开试探。
+type BreakerConfig struct {
+	FailureThreshold int           // 触发熔断的连续失败次数，<= 0 时关闭熔断
+	Window           time.Duration // 连续失败需落在该时间窗口内，距上次失败超过窗口时重新计数
+	Cooldown         time.Duration // 熔断后的冷却时长
+}
+
+// BreakerStatus 是单个工具熔断器的状态快照，供 status 展示。
+type BreakerStatus struct {
+	Tool                string       `json:"tool"`
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"` // 熔断时下一次允许试探的时间
+}
+
+// UnavailableError 表示工具因连续失败被熔断，在冷却期内不会实际执行。
+type UnavailableError struct {
+	Tool       string
+	Failures   int
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("tool %s is temporarily unavailable after %d consecutive failures; retry in %s or use a different approach",
+		e.Tool, e.Failures, e.RetryAfter.Round(time.Second))
+}
+
+// toolBreaker 记录单个工具的熔断状态。
+type toolBreaker struct {
+	state       BreakerState
+	failures    int
+	lastFailure time.Time
+	openedAt    time.Time
+	probing     bool // 半开状态下已有试探调用在执行
+}
+
+// breakerSet 按工具名称管理熔断器。
+type breakerSet struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	breakers map[string]*toolBreaker
+	now      func() time.Time
+}
+
+func newBreakerSet(cfg BreakerConfig) *breakerSet {
+	return &breakerSet{cfg: cfg, breakers: make(map[string]*toolBreaker), now: time.Now}
+}
+
+// allow 判断工具当前能否执行；熔断中返回 *UnavailableError。冷却结束后只放行一次试探调用。
+func (s *breakerSet) allow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[name]
+	if !ok || b.state == BreakerClosed {
+		return nil
+	}
+	now := s.now()
+	if b.state == BreakerOpen && now.Sub(b.openedAt) >= s.cfg.Cooldown {
+		b.state = BreakerHalfOpen
+		b.probing = false
+	}
+	if b.state == BreakerHalfOpen && !b.probing {
+		b.probing = true
+		return nil
+	}
+	retryAfter := s.cfg.Cooldown - now.Sub(b.openedAt)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return &UnavailableError{Tool: name, Failures: b.failures, RetryAfter: retryAfter}
+}
+
+// IsDependencyFailure 报告一次工具失败是否源于外部依赖：超时、MCP 工具的错误以及网络传输错误。
+// 工具自身的普通错误（文件不存在、命令退出码非零等）不属于依赖故障。
+func IsDependencyFailure(name string, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || strings.HasPrefix(name, "mcp.") {
+		return true
+	}
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
+
+// record 根据一次调用的结果更新熔断器；只有依赖故障（见 IsDependencyFailure）计为失败，
+// 普通执行错误、拒绝、参数错误等不影响计数。
+// 半开试探未遇到依赖故障也未成功（如被拒绝）时释放试探名额，下一次调用可重新试探。
+func (s *breakerSet) record(name string, outcome Outcome, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[name]
+	switch {
+	case outcome == OutcomeSuccess:
+	case outcome == OutcomeUnavailable:
+		return
+	case (outcome == OutcomeError || outcome == OutcomeTimeout) && IsDependencyFailure(name, err):
+	default:
+		if ok && b.state == BreakerHalfOpen {
+			b.probing = false
+		}
+		return
+	}
+	if outcome == OutcomeSuccess {
+		if ok {
+			delete(s.breakers, name)
+		}
+		return
+	}
+	if !ok {
+		b = &toolBreaker{state: BreakerClosed}
+		s.breakers[name] = b
+	}
+
+	now := s.now()
+	if b.state == BreakerClosed && !b.lastFailure.IsZero() && now.Sub(b.lastFailure) > s.cfg.Window {
+		b.failures = 0
+	}
+	b.failures++
+	b.lastFailure = now
+	// 熔断期间迟到的失败结果只累计次数，不延长冷却。
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= s.cfg.FailureThreshold) {
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.probing = false
+	}
+}
+
+// statuses 返回有失败记录的工具熔断状态，按工具名称排序。
+func (s *breakerSet) statuses() []BreakerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	out := make([]BreakerStatus, 0, len(s.breakers))
+	for name, b := range s.breakers {
+		st := BreakerStatus{Tool: name, State: b.state, ConsecutiveFailures: b.failures}
+		if b.state == BreakerOpen && now.Sub(b.openedAt) >= s.cfg.Cooldown {
+			st.State = BreakerHalfOpen
+		}
+		if b.state != BreakerClosed {
+			openedAt := b.openedAt
+			retryAt := b.openedAt.Add(s.cfg.Cooldown)
+			st.OpenedAt = &openedAt
+			st.RetryAt = &retryAt
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tool < out[j].Tool })
+	return out
+}
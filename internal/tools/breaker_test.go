@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+type flakyTool struct {
+	calls int
+	fail  bool
+	err   error // 失败时返回的错误，默认为连接被拒绝的传输错误
+}
+
+func (f *flakyTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: "flaky", Desc: "fails on demand"}, nil
+}
+
+func (f *flakyTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	f.calls++
+	if f.fail {
+		if f.err != nil {
+			return "", f.err
+		}
+		return "", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	return "ok", nil
+}
+
+func newBreakerRegistry(t *testing.T, ft *flakyTool, clock *time.Time) *Registry {
+	t.Helper()
+	r := NewRegistry()
+	if err := r.Register(ft); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	r.SetBreaker(BreakerConfig{FailureThreshold: 3, Window: time.Minute, Cooldown: 30 * time.Second})
+	r.breakers.now = func() time.Time { return *clock }
+	return r
+}
+
+func runAndRecord(r *Registry, name string) error {
+	res, err := r.ExecuteResult(context.Background(), name, "{}")
+	r.RecordOutcome(name, Classify(res, err), err)
+	return err
+}
+
+func TestRegistry_BreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	ft := &flakyTool{fail: true}
+	r := newBreakerRegistry(t, ft, &clock)
+
+	for i := 0; i < 3; i++ {
+		if err := runAndRecord(r, "flaky"); err == nil {
+			t.Fatalf("call %d: expected tool failure", i)
+		}
+	}
+	err := runAndRecord(r, "flaky")
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) {
+		t.Fatalf("expected UnavailableError once the breaker opens, got %v", err)
+	}
+	if ft.calls != 3 {
+		t.Fatalf("expected open breaker to skip execution, tool ran %d times", ft.calls)
+	}
+
+	statuses := r.BreakerStatuses()
+	if len(statuses) != 1 || statuses[0].State != BreakerOpen || statuses[0].ConsecutiveFailures != 3 {
+		t.Fatalf("unexpected breaker status: %+v", statuses)
+	}
+	if statuses[0].RetryAt == nil || !statuses[0].RetryAt.Equal(clock.Add(30*time.Second)) {
+		t.Fatalf("expected retry_at at the end of the cool-down, got %+v", statuses[0].RetryAt)
+	}
+}
+
+func TestRegistry_BreakerHalfOpensAfterCooldown(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	ft := &flakyTool{fail: true}
+	r := newBreakerRegistry(t, ft, &clock)
+	for i := 0; i < 3; i++ {
+		_ = runAndRecord(r, "flaky")
+	}
+
+	// 冷却结束后放行一次试探；试探失败立即重新熔断。
+	clock = clock.Add(31 * time.Second)
+	if err := runAndRecord(r, "flaky"); err == nil || IsArgumentError(err) {
+		t.Fatalf("expected probe call to run and fail, got %v", err)
+	}
+	if ft.calls != 4 {
+		t.Fatalf("expected a single probe call, tool ran %d times", ft.calls)
+	}
+	var unavailable *UnavailableError
+	if err := runAndRecord(r, "flaky"); !errors.As(err, &unavailable) {
+		t.Fatalf("expected failed probe to reopen the breaker, got %v", err)
+	}
+
+	// 下一次试探成功后恢复正常。
+	clock = clock.Add(31 * time.Second)
+	ft.fail = false
+	if err := runAndRecord(r, "flaky"); err != nil {
+		t.Fatalf("expected successful probe, got %v", err)
+	}
+	if err := runAndRecord(r, "flaky"); err != nil {
+		t.Fatalf("expected closed breaker after recovery, got %v", err)
+	}
+	if statuses := r.BreakerStatuses(); len(statuses) != 0 {
+		t.Fatalf("expected no breaker state after recovery, got %+v", statuses)
+	}
+}
+
+func TestRegistry_BreakerResetsOutsideWindow(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	ft := &flakyTool{fail: true}
+	r := newBreakerRegistry(t, ft, &clock)
+
+	_ = runAndRecord(r, "flaky")
+	_ = runAndRecord(r, "flaky")
+	clock = clock.Add(2 * time.Minute)
+	_ = runAndRecord(r, "flaky")
+
+	statuses := r.BreakerStatuses()
+	if len(statuses) != 1 || statuses[0].State != BreakerClosed || statuses[0].ConsecutiveFailures != 1 {
+		t.Fatalf("expected failure count to restart outside the window, got %+v", statuses)
+	}
+}
+
+func TestRegistry_BreakerIgnoresNonDependencyFailures(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	r := newBreakerRegistry(t, &flakyTool{}, &clock)
+	for i := 0; i < 5; i++ {
+		r.RecordOutcome("flaky", OutcomeDenied, &DeniedError{Message: "blocked"})
+		r.RecordOutcome("flaky", OutcomeInvalidArgs, &ArgumentError{Tool: "flaky", Reason: "bad"})
+	}
+	if statuses := r.BreakerStatuses(); len(statuses) != 0 {
+		t.Fatalf("expected denied and invalid-argument calls not to trip the breaker, got %+v", statuses)
+	}
+
+	// 工具自身的普通执行错误不是依赖故障，不应让工具被熔断。
+	ft := &flakyTool{fail: true, err: errors.New("open notes.md: no such file or directory")}
+	r = newBreakerRegistry(t, ft, &clock)
+	for i := 0; i < 5; i++ {
+		if err := runAndRecord(r, "flaky"); err == nil {
+			t.Fatalf("call %d: expected tool failure", i)
+		}
+	}
+	if ft.calls != 5 {
+		t.Fatalf("expected ordinary errors to keep running the tool, ran %d times", ft.calls)
+	}
+	if statuses := r.BreakerStatuses(); len(statuses) != 0 {
+		t.Fatalf("expected ordinary tool errors not to trip the breaker, got %+v", statuses)
+	}
+}
+
+func TestIsDependencyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		err  error
+		want bool
+	}{
+		{name: "nil", tool: "web_fetch", err: nil, want: false},
+		{name: "ordinary error", tool: "exec", err: errors.New("exit status 1"), want: false},
+		{name: "timeout", tool: "exec", err: fmt.Errorf("run: %w", context.DeadlineExceeded), want: true},
+		{name: "mcp error", tool: "mcp.localfs.read", err: errors.New("server returned an error"), want: true},
+		{name: "transport error", tool: "web_fetch", err: &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("connection reset")}, want: true},
+		{name: "dns error", tool: "web_search", err: &net.DNSError{Err: "no such host", Name: "example.invalid"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDependencyFailure(tt.tool, tt.err); got != tt.want {
+				t.Fatalf("IsDependencyFailure(%q, %v) = %v, want %v", tt.tool, tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_BreakerDisabledByDefault(t *testing.T) {
+	ft := &flakyTool{fail: true}
+	r := NewRegistry()
+	if err := r.Register(ft); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		_ = runAndRecord(r, "flaky")
+	}
+	if ft.calls != 10 {
+		t.Fatalf("expected every call to run without a breaker, got %d", ft.calls)
+	}
+	if statuses := r.BreakerStatuses(); len(statuses) != 0 {
+		t.Fatalf("expected no breaker statuses, got %+v", statuses)
+	}
+}
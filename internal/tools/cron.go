@@ -22,6 +22,9 @@ type CronToolInput struct {
 	AtTimestamp  string `json:"at_timestamp,omitempty" jsonschema:"description=RFC3339 timestamp for one-shot (for add with at schedule)"`
 	JobID        string `json:"job_id,omitempty" jsonschema:"description=Job ID (required for remove/enable/disable)"`
 	Deliver      bool   `json:"deliver,omitempty" jsonschema:"description=If true deliver response directly without agent processing"`
+	Channel      string `json:"channel,omitempty" jsonschema:"description=Channel to deliver job output to (e.g. telegram slack); only honored from the CLI. Chat conversations always deliver to themselves"`
+	ChatID       string `json:"chat_id,omitempty" jsonschema:"description=Chat ID to deliver job output to; only honored from the CLI. Chat conversations always deliver to themselves"`
+	CatchUp      string `json:"catch_up,omitempty" jsonschema:"description=What to do with runs missed while Golem was not running: skip them; run_once (default) runs once on startup; run_all replays each missed run (up to 10),enum=skip,enum=run_once,enum=run_all"`
 }
 
 type cronToolImpl struct {
@@ -31,7 +34,7 @@ type cronToolImpl struct {
 func (t *cronToolImpl) execute(ctx context.Context, input *CronToolInput) (string, error) {
 	switch strings.ToLower(strings.TrimSpace(input.Action)) {
 	case "add":
-		return t.add(input)
+		return t.add(ctx, input)
 	case "list":
 		return t.list()
 	case "remove":
@@ -45,7 +48,7 @@ func (t *cronToolImpl) execute(ctx context.Context, input *CronToolInput) (strin
 	}
 }
 
-func (t *cronToolImpl) add(input *CronToolInput) (string, error) {
+func (t *cronToolImpl) add(ctx context.Context, input *CronToolInput) (string, error) {
 	if strings.TrimSpace(input.Name) == "" {
 		return "", fmt.Errorf("name is required for add action")
 	}
@@ -53,6 +56,11 @@ func (t *cronToolImpl) add(input *CronToolInput) (string, error) {
 		return "", fmt.Errorf("message is required for add action")
 	}
 
+	catchUp, err := cron.ParseCatchUpPolicy(input.CatchUp)
+	if err != nil {
+		return "", err
+	}
+
 	var schedule cron.Schedule
 	switch {
 	case input.EverySeconds > 0:
@@ -71,12 +79,47 @@ func (t *cronToolImpl) add(input *CronToolInput) (string, error) {
 		return "", fmt.Errorf("one of every_seconds, cron_expr, or at_timestamp is required")
 	}
 
-	job, err := t.service.AddJob(input.Name, input.Message, schedule, "", "", input.Deliver)
+	channel, chatID, err := cronTarget(ctx, input)
+	if err != nil {
+		return "", err
+	}
+	job, err := t.service.AddJobWithOptions(input.Name, input.Message, schedule, cron.AddOptions{
+		Channel: channel,
+		ChatID:  chatID,
+		Deliver: input.Deliver,
+		CatchUp: catchUp,
+	})
 	if err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("Job created: id=%s name=%s schedule=%s", job.ShortID(), job.Name, job.ScheduleDescription()), nil
+	result := fmt.Sprintf("Job created: id=%s name=%s schedule=%s", job.ShortID(), job.Name, job.ScheduleDescription())
+	if channel != "" && chatID != "" {
+		result += fmt.Sprintf(" deliver_to=%s:%s", channel, chatID)
+	}
+	return result, nil
+}
+
+// cronTarget 返回任务结果的投递目标。来自聊天的调用只能投递到当前对话，指定其他目标会被拒绝，
+// 避免聊天用户在其他会话中运行并投递任务；显式目标只在 CLI 中生效。
+// CLI 与定时任务自身的调用没有可投递的通道，未显式指定时不设置目标。
+func cronTarget(ctx context.Context, input *CronToolInput) (channel, chatID string, err error) {
+	channel = strings.TrimSpace(input.Channel)
+	chatID = strings.TrimSpace(input.ChatID)
+	meta := InvocationFromContext(ctx)
+	switch meta.Channel {
+	case "", "cli":
+		return channel, chatID, nil
+	case "cron":
+		if channel != "" || chatID != "" {
+			return "", "", fmt.Errorf("channel and chat_id can only be set from the CLI")
+		}
+		return "", "", nil
+	}
+	if (channel != "" && channel != meta.Channel) || (chatID != "" && chatID != meta.ChatID) {
+		return "", "", fmt.Errorf("jobs created from a chat deliver to that chat; channel and chat_id can only point elsewhere from the CLI")
+	}
+	return meta.Channel, meta.ChatID, nil
 }
 
 func (t *cronToolImpl) list() (string, error) {
@@ -90,6 +133,8 @@ func (t *cronToolImpl) list() (string, error) {
 		Name     string `json:"name"`
 		Enabled  bool   `json:"enabled"`
 		Schedule string `json:"schedule"`
+		CatchUp  string `json:"catch_up"`
+		Target   string `json:"deliver_to,omitempty"`
 		NextRun  string `json:"next_run,omitempty"`
 	}
 
@@ -100,6 +145,10 @@ func (t *cronToolImpl) list() (string, error) {
 			Name:     j.Name,
 			Enabled:  j.Enabled,
 			Schedule: j.ScheduleDescription(),
+			CatchUp:  string(j.EffectiveCatchUp()),
+		}
+		if j.Payload.Channel != "" && j.Payload.ChatID != "" {
+			v.Target = j.Payload.Channel + ":" + j.Payload.ChatID
 		}
 		if j.State.NextRunAtMS != nil {
 			v.NextRun = time.UnixMilli(*j.State.NextRunAtMS).Format(time.RFC3339)
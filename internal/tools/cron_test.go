@@ -46,6 +46,9 @@ func TestCronTool_AddAndList(t *testing.T) {
 	if !strings.Contains(result, "test-cron") {
 		t.Fatalf("expected job name in list, got: %s", result)
 	}
+	if !strings.Contains(result, `"catch_up": "run_once"`) {
+		t.Fatalf("expected default catch-up policy in list, got: %s", result)
+	}
 }
 
 func TestCronTool_RemoveJob(t *testing.T) {
@@ -145,6 +148,7 @@ func TestCronTool_Validations(t *testing.T) {
 		{"add missing name", `{"action":"add","message":"hi","every_seconds":60}`},
 		{"add missing message", `{"action":"add","name":"test","every_seconds":60}`},
 		{"add no schedule", `{"action":"add","name":"test","message":"hi"}`},
+		{"add bad catch_up", `{"action":"add","name":"test","message":"hi","every_seconds":60,"catch_up":"later"}`},
 		{"remove missing id", `{"action":"remove"}`},
 		{"enable missing id", `{"action":"enable"}`},
 	}
@@ -158,3 +162,62 @@ func TestCronTool_Validations(t *testing.T) {
 		})
 	}
 }
+
+func TestCronTool_AddTargetsInvokingConversation(t *testing.T) {
+	svc := newTestCronService(t)
+	cronTool, _ := NewCronTool(svc)
+
+	ctx := WithInvocationContext(context.Background(), InvocationContext{Channel: "telegram", ChatID: "42"})
+	result, err := cronTool.InvokableRun(ctx, `{"action":"add","name":"morning","message":"brief me","cron_expr":"0 8 * * *"}`)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if !strings.Contains(result, "deliver_to=telegram:42") {
+		t.Fatalf("expected delivery target in result, got: %s", result)
+	}
+
+	cliCtx := WithInvocationContext(context.Background(), InvocationContext{Channel: "cli", ChatID: "direct"})
+	if _, err := cronTool.InvokableRun(cliCtx, `{"action":"add","name":"local","message":"hi","every_seconds":60}`); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := cronTool.InvokableRun(cliCtx, `{"action":"add","name":"explicit","message":"hi","every_seconds":60,"channel":"slack","chat_id":"C1"}`); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	targets := map[string]string{}
+	for _, j := range svc.ListJobs(true) {
+		targets[j.Name] = j.Payload.Channel + ":" + j.Payload.ChatID
+	}
+	want := map[string]string{"morning": "telegram:42", "local": ":", "explicit": "slack:C1"}
+	for name, target := range want {
+		if targets[name] != target {
+			t.Fatalf("job %s target = %q, want %q", name, targets[name], target)
+		}
+	}
+}
+
+func TestCronTool_AddRejectsOtherTargetsFromChat(t *testing.T) {
+	svc := newTestCronService(t)
+	cronTool, _ := NewCronTool(svc)
+
+	ctx := WithInvocationContext(context.Background(), InvocationContext{Channel: "telegram", ChatID: "42"})
+	for _, args := range []string{
+		`{"action":"add","name":"hijack","message":"hi","every_seconds":60,"channel":"slack","chat_id":"C1"}`,
+		`{"action":"add","name":"hijack","message":"hi","every_seconds":60,"chat_id":"99"}`,
+	} {
+		if _, err := cronTool.InvokableRun(ctx, args); err == nil || !strings.Contains(err.Error(), "from the CLI") {
+			t.Fatalf("expected target outside the conversation to be rejected, got %v", err)
+		}
+	}
+	cronCtx := WithInvocationContext(context.Background(), InvocationContext{Channel: "cron", ChatID: "job-1"})
+	if _, err := cronTool.InvokableRun(cronCtx, `{"action":"add","name":"hijack","message":"hi","every_seconds":60,"channel":"slack","chat_id":"C1"}`); err == nil {
+		t.Fatal("expected explicit target from a cron run to be rejected")
+	}
+	if jobs := svc.ListJobs(true); len(jobs) != 0 {
+		t.Fatalf("expected no jobs to be created, got %d", len(jobs))
+	}
+
+	if _, err := cronTool.InvokableRun(ctx, `{"action":"add","name":"self","message":"hi","every_seconds":60,"channel":"telegram","chat_id":"42"}`); err != nil {
+		t.Fatalf("expected the invoking conversation to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// diffContextLines 是统一 diff 中变更前后保留的上下文行数。
+	diffContextLines = 3
+	// maxDiffLines 限制 diff 正文行数，避免大段替换撑爆上下文。
+	maxDiffLines = 200
+	// maxDiffBytes 限制 diff 正文总字节数。
+	maxDiffBytes = 16 * 1024
+)
+
+// unifiedDiff 生成 before 与 after 之间的统一 diff（单个 hunk）。
+// 公共前缀与后缀之外的部分视为一处连续变更，适用于 edit_file 这类单点替换；
+// 输出按行数与字节数截断。内容相同时返回空字符串。
+func unifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	a := splitDiffLines(before)
+	b := splitDiffLines(after)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	start := max(0, prefix-diffContextLines)
+	aEnd, bEnd := len(a)-suffix, len(b)-suffix
+	trailing := min(suffix, diffContextLines)
+
+	var body []string
+	for _, line := range a[start:prefix] {
+		body = append(body, " "+line)
+	}
+	for _, line := range a[prefix:aEnd] {
+		body = append(body, "-"+line)
+	}
+	for _, line := range b[prefix:bEnd] {
+		body = append(body, "+"+line)
+	}
+	for _, line := range a[aEnd : aEnd+trailing] {
+		body = append(body, " "+line)
+	}
+
+	var sb strings.Builder
+	if filepath.IsAbs(path) {
+		fmt.Fprintf(&sb, "--- %s\n+++ %s\n", path, path)
+	} else {
+		fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	}
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+		diffRange(start, aEnd+trailing-start),
+		diffRange(start, bEnd+trailing-start))
+	written := 0
+	for i, line := range body {
+		if i >= maxDiffLines || written+len(line)+1 > maxDiffBytes {
+			fmt.Fprintf(&sb, "... (diff truncated, %d more lines)\n", len(body)-i)
+			break
+		}
+		sb.WriteString(line)
+		sb.WriteByte('\n')
+		written += len(line) + 1
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// diffRange 按统一 diff 约定格式化 hunk 范围：start 为 0 起始的首行下标。
+func diffRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -2,8 +2,10 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
@@ -12,7 +14,7 @@ import (
 
 // EditFileInput 定义了 edit_file 工具的输入参数。
 type EditFileInput struct {
-	Path    string `json:"path" jsonschema:"required,description=Absolute path to the file"`
+	Path    string `json:"path" jsonschema:"required,description=Path to the file (absolute or relative to the workspace)"`
 	OldText string `json:"old_text" jsonschema:"required,description=Exact existing text to replace"`
 	NewText string `json:"new_text" jsonschema:"required,description=Replacement text"`
 }
@@ -22,43 +24,79 @@ type editFileToolImpl struct {
 }
 
 func (t *editFileToolImpl) execute(ctx context.Context, input *EditFileInput) (string, error) {
-	if err := validatePath(input.Path, t.workspacePath); err != nil {
+	path, content, updated, err := planEdit(input, t.workspacePath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
 		return "", err
 	}
+	diff := unifiedDiff(diffName(path, t.workspacePath), content, updated)
+	if diff == "" {
+		return "File edited successfully (no changes)", nil
+	}
+	return "File edited successfully\n\n" + diff, nil
+}
+
+// planEdit 校验并计算一次 edit_file 替换，返回解析后的路径、原内容与替换后的内容，不写入文件。
+func planEdit(input *EditFileInput, workspacePath string) (path, content, updated string, err error) {
+	path, err = resolveToolPath(input.Path, workspacePath)
+	if err != nil {
+		return "", "", "", err
+	}
 	if input.OldText == "" {
-		return "", fmt.Errorf("old_text must not be empty")
+		return "", "", "", fmt.Errorf("old_text must not be empty")
 	}
 
-	data, err := os.ReadFile(input.Path)
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return "", "", "", err
 	}
-	content := string(data)
+	content = string(data)
 	occurrences := strings.Count(content, input.OldText)
 	if occurrences == 0 {
-		return "", fmt.Errorf("old_text not found in file")
+		return "", "", "", fmt.Errorf("old_text not found in file")
 	}
 	// 为了安全，仅当匹配到唯一一处时才允许替换
 	if occurrences > 1 {
-		return "", fmt.Errorf("old_text matches multiple locations (%d); provide a unique snippet", occurrences)
+		return "", "", "", fmt.Errorf("old_text matches multiple locations (%d); provide a unique snippet", occurrences)
 	}
+	return path, content, strings.Replace(content, input.OldText, input.NewText, 1), nil
+}
 
-	updated := strings.Replace(content, input.OldText, input.NewText, 1)
-	if err := os.WriteFile(input.Path, []byte(updated), 0644); err != nil {
+// PreviewEditFile 以统一 diff 形式预览一次 edit_file 调用将产生的改动，但不修改文件。
+// 用于审批流程中向审批人展示待执行的编辑。
+func PreviewEditFile(workspacePath, argsJSON string) (string, error) {
+	var input EditFileInput
+	if err := json.Unmarshal([]byte(argsJSON), &input); err != nil {
+		return "", fmt.Errorf("invalid edit_file arguments: %w", err)
+	}
+	path, content, updated, err := planEdit(&input, workspacePath)
+	if err != nil {
 		return "", err
 	}
-	return "File edited successfully", nil
+	return unifiedDiff(diffName(path, workspacePath), content, updated), nil
+}
+
+// diffName 返回 diff 头中展示的文件名：工作区内的文件使用相对路径。
+func diffName(path, workspacePath string) string {
+	if workspacePath != "" {
+		if rel, err := filepath.Rel(workspacePath, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
 }
 
 // NewEditFileTool 创建 edit_file 工具实例，用于精确替换文件中的特定片段。
 func NewEditFileTool(workspacePath string) (tool.InvokableTool, error) {
 	impl := &editFileToolImpl{workspacePath: workspacePath}
-	return utils.InferTool("edit_file", "Edit one exact snippet in a file via old_text -> new_text replacement", impl.execute)
+	return utils.InferTool("edit_file", "Edit one exact snippet in a file via old_text -> new_text replacement. Returns a unified diff of the change", impl.execute)
 }
 
 // AppendFileInput 定义了 append_file 工具的输入参数。
 type AppendFileInput struct {
-	Path    string `json:"path" jsonschema:"required,description=Absolute path to the file"`
+	Path    string `json:"path" jsonschema:"required,description=Path to the file (absolute or relative to the workspace)"`
 	Content string `json:"content" jsonschema:"required,description=Content to append to file end"`
 }
 
@@ -67,14 +105,15 @@ type appendFileToolImpl struct {
 }
 
 func (t *appendFileToolImpl) execute(ctx context.Context, input *AppendFileInput) (string, error) {
-	if err := validatePath(input.Path, t.workspacePath); err != nil {
+	path, err := resolveToolPath(input.Path, t.workspacePath)
+	if err != nil {
 		return "", err
 	}
 	if strings.TrimSpace(input.Content) == "" {
 		return "", fmt.Errorf("content must not be empty")
 	}
 
-	f, err := os.OpenFile(input.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return "", err
 	}
@@ -126,3 +126,79 @@ func TestAppendFileTool_BlocksPathTraversal(t *testing.T) {
 		t.Fatalf("expected access denied, got: %v", err)
 	}
 }
+
+func TestEditFileTool_ReturnsUnifiedDiff(t *testing.T) {
+	workspace := t.TempDir()
+	target := filepath.Join(workspace, "main.go")
+	original := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"
+	if err := os.WriteFile(target, []byte(original), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	tool, err := NewEditFileTool(workspace)
+	if err != nil {
+		t.Fatalf("NewEditFileTool error: %v", err)
+	}
+	argsJSON := fmt.Sprintf(`{"path": %q, "old_text": %q, "new_text": %q}`, target, `fmt.Println("hello")`, "fmt.Println(\"hi\")\n\tfmt.Println(\"bye\")")
+	result, err := tool.InvokableRun(context.Background(), argsJSON)
+	if err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -3,5 +3,6 @@",
+		" import \"fmt\"",
+		" ",
+		" func main() {",
+		"-\tfmt.Println(\"hello\")",
+		"+\tfmt.Println(\"hi\")",
+		"+\tfmt.Println(\"bye\")",
+		" }",
+	}, "\n")
+	if !strings.Contains(result, want) {
+		t.Fatalf("expected unified diff in result, got:\n%s", result)
+	}
+}
+
+func TestPreviewEditFile_DoesNotModifyFile(t *testing.T) {
+	workspace := t.TempDir()
+	target := filepath.Join(workspace, "a.txt")
+	if err := os.WriteFile(target, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	preview, err := PreviewEditFile(workspace, `{"path":"a.txt","old_text":"two","new_text":"three"}`)
+	if err != nil {
+		t.Fatalf("PreviewEditFile error: %v", err)
+	}
+	if !strings.Contains(preview, "-two") || !strings.Contains(preview, "+three") {
+		t.Fatalf("unexpected preview:\n%s", preview)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "one\ntwo\n" {
+		t.Fatalf("expected file unchanged, got %q", data)
+	}
+
+	if _, err := PreviewEditFile(workspace, `{"path":"a.txt","old_text":"missing","new_text":"x"}`); err == nil {
+		t.Fatal("expected error when old_text is not found")
+	}
+}
+
+func TestUnifiedDiff_TruncatesLargeChanges(t *testing.T) {
+	var before, after strings.Builder
+	for i := 0; i < maxDiffLines; i++ {
+		fmt.Fprintf(&before, "old %d\n", i)
+		fmt.Fprintf(&after, "new %d\n", i)
+	}
+	diff := unifiedDiff("big.txt", before.String(), after.String())
+	if !strings.Contains(diff, "@@ -1,200 +1,200 @@") {
+		t.Fatalf("expected full hunk header, got:\n%s", diff[:200])
+	}
+	if !strings.Contains(diff, "(diff truncated, 200 more lines)") {
+		t.Fatalf("expected truncation marker, got tail:\n%s", diff[len(diff)-200:])
+	}
+	if got := unifiedDiff("same.txt", "x\n", "x\n"); got != "" {
+		t.Fatalf("expected empty diff for identical content, got %q", got)
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+var (
+	// ErrNotText 表示 FetchText 抓取到的内容不是可提取文本的类型（如图片、音频）。
+	ErrNotText = errors.New("content is not text")
+	// ErrNotImage 表示 FetchImage 抓取到的内容不是图片。
+	ErrNotImage = errors.New("content is not an image")
+)
+
+// FetchOptions 配置 FetchText 与 FetchImage。
+type FetchOptions struct {
+	Timeout      time.Duration // 请求超时，<=0 使用 web_fetch 的默认超时
+	MaxBytes     int           // 读取的最大字节数，<=0 使用 web_fetch 的默认上限
+	AllowPrivate bool          // 允许访问非公网地址，对应 tools.web.allow_private
+}
+
+// FetchText 抓取 URL 并按 web_fetch 的规则提取文本（HTML 剥离标签、PDF 提取文本）。
+// 内容不是文本时返回 ErrNotText；truncated 表示响应超过 MaxBytes 被截断。
+func FetchText(ctx context.Context, rawURL string, opts FetchOptions) (text string, truncated bool, err error) {
+	body, contentType, truncated, err := fetchBody(ctx, rawURL, opts)
+	if err != nil {
+		return "", false, err
+	}
+	if !isExtractableMediaType(fetchedMediaType(contentType, body)) {
+		return "", false, ErrNotText
+	}
+	return strings.TrimSpace(extractFetchedContent(contentType, body)), truncated, nil
+}
+
+// FetchImage 抓取 URL 上的图片，返回图片数据与 MIME 类型。内容不是图片时返回 ErrNotImage；
+// 图片超过 MaxBytes 时返回错误，而不是截断后的数据。
+func FetchImage(ctx context.Context, rawURL string, opts FetchOptions) ([]byte, string, error) {
+	body, contentType, truncated, err := fetchBody(ctx, rawURL, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	mediaType := fetchedMediaType(contentType, body)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, "", ErrNotImage
+	}
+	if truncated {
+		return nil, "", fmt.Errorf("image is larger than %d bytes", opts.MaxBytes)
+	}
+	return body, mediaType, nil
+}
+
+// fetchBody 以 GET 请求读取 URL 的响应体，最多读取 MaxBytes 字节；默认拒绝非公网地址，与 web_fetch 一致。
+func fetchBody(ctx context.Context, rawURL string, opts FetchOptions) (body []byte, contentType string, truncated bool, err error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, "", false, fmt.Errorf("unsupported url scheme: %s", parsed.Scheme)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = httpclient.Timeout(defaultWebTimeout)
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultWebFetchMaxBytes
+	}
+	if !opts.AllowPrivate {
+		if err := checkPublicHost(ctx, parsed.Hostname()); err != nil {
+			return nil, "", false, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+	resp, err := newFetchClient(opts.Timeout, 0, !opts.AllowPrivate).Do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, "", false, fmt.Errorf("fetch failed with status %d", resp.StatusCode)
+	}
+
+	body, err = io.ReadAll(io.LimitReader(resp.Body, int64(opts.MaxBytes+1)))
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(body) > opts.MaxBytes {
+		body = body[:opts.MaxBytes]
+		truncated = true
+	}
+	return body, resp.Header.Get("Content-Type"), truncated, nil
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchText_ExtractsTextAndRejectsBinary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html><body><p>Hello</p><script>x()</script></body></html>"))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG\r\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	text, truncated, err := FetchText(context.Background(), srv.URL+"/page", FetchOptions{AllowPrivate: true, MaxBytes: 1024})
+	if err != nil || text != "Hello" || truncated {
+		t.Fatalf("expected extracted text, got %q truncated=%v err=%v", text, truncated, err)
+	}
+	if _, _, err := FetchText(context.Background(), srv.URL+"/image", FetchOptions{AllowPrivate: true}); !errors.Is(err, ErrNotText) {
+		t.Fatalf("expected ErrNotText for image, got %v", err)
+	}
+	if _, _, err := FetchText(context.Background(), srv.URL+"/missing", FetchOptions{AllowPrivate: true}); err == nil {
+		t.Fatal("expected error for 404")
+	}
+
+	var private *PrivateAddressError
+	if _, _, err := FetchText(context.Background(), srv.URL+"/page", FetchOptions{}); !errors.As(err, &private) {
+		t.Fatalf("expected PrivateAddressError by default, got %v", err)
+	}
+}
+
+func TestFetchImage_ReturnsDataAndRejectsOversized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n"))
+		case "/page":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<p>not an image</p>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	data, mimeType, err := FetchImage(context.Background(), srv.URL+"/image", FetchOptions{AllowPrivate: true, MaxBytes: 1024})
+	if err != nil || mimeType != "image/png" || string(data) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("expected image data, got %q %q err=%v", data, mimeType, err)
+	}
+	if _, _, err := FetchImage(context.Background(), srv.URL+"/image", FetchOptions{AllowPrivate: true, MaxBytes: 4}); err == nil {
+		t.Fatal("expected error for image larger than MaxBytes")
+	}
+	if _, _, err := FetchImage(context.Background(), srv.URL+"/page", FetchOptions{AllowPrivate: true}); !errors.Is(err, ErrNotImage) {
+		t.Fatalf("expected ErrNotImage for html, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// MoveFileInput 定义了 move_file 工具的输入参数。
+type MoveFileInput struct {
+	Source      string `json:"source" jsonschema:"required,description=Path of the file or directory to move (absolute or relative to the workspace)"`
+	Destination string `json:"destination" jsonschema:"required,description=Destination path (absolute or relative to the workspace); must not already exist"`
+}
+
+type moveFileToolImpl struct {
+	workspacePath string
+}
+
+func (t *moveFileToolImpl) execute(ctx context.Context, input *MoveFileInput) (string, error) {
+	if strings.TrimSpace(input.Source) == "" || strings.TrimSpace(input.Destination) == "" {
+		return "", fmt.Errorf("source and destination are required")
+	}
+	src, err := resolveToolPath(input.Source, t.workspacePath)
+	if err != nil {
+		return "", err
+	}
+	dst, err := resolveToolPath(input.Destination, t.workspacePath)
+	if err != nil {
+		return "", err
+	}
+	if err := refuseWorkspaceRoot(src, t.workspacePath); err != nil {
+		return "", err
+	}
+	if _, err = os.Lstat(src); err != nil {
+		return "", err
+	}
+	// 不覆盖已有文件，避免误操作导致数据丢失
+	if _, err := os.Lstat(dst); err == nil {
+		return "", fmt.Errorf("destination %q already exists", dst)
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return "", err
+	}
+	return "File moved successfully", nil
+}
+
+// NewMoveFileTool 创建 move_file 工具实例，用于在工作区内移动或重命名文件与目录。
+func NewMoveFileTool(workspacePath string) (tool.InvokableTool, error) {
+	impl := &moveFileToolImpl{workspacePath: workspacePath}
+	return utils.InferTool("move_file", "Move or rename a file or directory within the workspace", impl.execute)
+}
+
+// DeleteFileInput 定义了 delete_file 工具的输入参数。
+type DeleteFileInput struct {
+	Path      string `json:"path" jsonschema:"required,description=Path of the file or directory to delete (absolute or relative to the workspace)"`
+	Recursive bool   `json:"recursive,omitempty" jsonschema:"description=Required to delete a directory and everything in it"`
+}
+
+type deleteFileToolImpl struct {
+	workspacePath string
+}
+
+func (t *deleteFileToolImpl) execute(ctx context.Context, input *DeleteFileInput) (string, error) {
+	if strings.TrimSpace(input.Path) == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	path, err := resolveToolPath(input.Path, t.workspacePath)
+	if err != nil {
+		return "", err
+	}
+	if err := refuseWorkspaceRoot(path, t.workspacePath); err != nil {
+		return "", err
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		if !input.Recursive {
+			return "", fmt.Errorf("%q is a directory; set recursive=true to delete it", path)
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return "", err
+		}
+		return "Directory deleted successfully", nil
+	}
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return "File deleted successfully", nil
+}
+
+// NewDeleteFileTool 创建 delete_file 工具实例，用于删除工作区内的文件（目录需显式 recursive）。
+func NewDeleteFileTool(workspacePath string) (tool.InvokableTool, error) {
+	impl := &deleteFileToolImpl{workspacePath: workspacePath}
+	return utils.InferTool("delete_file", "Delete a file, or a directory when recursive is true, within the workspace", impl.execute)
+}
+
+// refuseWorkspaceRoot 拒绝对工作区根目录本身执行移动或删除。
+func refuseWorkspaceRoot(path, workspacePath string) error {
+	if workspacePath == "" {
+		return nil
+	}
+	targetAbs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	rootAbs, err := filepath.Abs(workspacePath)
+	if err != nil {
+		return err
+	}
+	target, err := resolvePathBestEffort(targetAbs)
+	if err != nil {
+		return err
+	}
+	root, err := resolvePathBestEffort(rootAbs)
+	if err != nil {
+		return err
+	}
+	if target == root {
+		return fmt.Errorf("refusing to modify the workspace root %q", workspacePath)
+	}
+	return nil
+}
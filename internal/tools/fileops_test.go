@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveFileTool(t *testing.T) {
+	ws := t.TempDir()
+	src := filepath.Join(ws, "a.txt")
+	os.WriteFile(src, []byte("hello"), 0644)
+
+	mt, err := NewMoveFileTool(ws)
+	if err != nil {
+		t.Fatalf("NewMoveFileTool error: %v", err)
+	}
+	dst := filepath.Join(ws, "sub", "b.txt")
+	if _, err := mt.InvokableRun(context.Background(), fmt.Sprintf(`{"source":%q,"destination":%q}`, src, dst)); err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("expected source to be gone, stat err=%v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "hello" {
+		t.Fatalf("expected moved content, got %q err=%v", data, err)
+	}
+
+	// 目标已存在时拒绝覆盖
+	other := filepath.Join(ws, "c.txt")
+	os.WriteFile(other, []byte("keep"), 0644)
+	if _, err := mt.InvokableRun(context.Background(), fmt.Sprintf(`{"source":%q,"destination":%q}`, dst, other)); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected already exists error, got %v", err)
+	}
+}
+
+func TestMoveFile_PathTraversal(t *testing.T) {
+	ws := t.TempDir()
+	src := filepath.Join(ws, "a.txt")
+	os.WriteFile(src, []byte("hello"), 0644)
+
+	mt, _ := NewMoveFileTool(ws)
+	outside := filepath.Join(ws, "..", "escaped.txt")
+	_, err := mt.InvokableRun(context.Background(), fmt.Sprintf(`{"source":%q,"destination":%q}`, src, outside))
+	if err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected outside workspace error, got %v", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("expected source to remain, got %v", err)
+	}
+}
+
+func TestMoveFile_SymlinkEscapeBlocked(t *testing.T) {
+	ws := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(ws, "out")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	src := filepath.Join(ws, "a.txt")
+	os.WriteFile(src, []byte("hello"), 0644)
+
+	mt, _ := NewMoveFileTool(ws)
+	_, err := mt.InvokableRun(context.Background(), fmt.Sprintf(`{"source":%q,"destination":%q}`, src, filepath.Join(ws, "out", "a.txt")))
+	if err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected symlink escape to be blocked, got %v", err)
+	}
+}
+
+func TestDeleteFileTool(t *testing.T) {
+	ws := t.TempDir()
+	file := filepath.Join(ws, "a.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	dt, err := NewDeleteFileTool(ws)
+	if err != nil {
+		t.Fatalf("NewDeleteFileTool error: %v", err)
+	}
+	if _, err := dt.InvokableRun(context.Background(), fmt.Sprintf(`{"path":%q}`, file)); err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be deleted, stat err=%v", err)
+	}
+}
+
+func TestDeleteFile_DirectoryRequiresRecursive(t *testing.T) {
+	ws := t.TempDir()
+	dir := filepath.Join(ws, "build")
+	os.MkdirAll(filepath.Join(dir, "nested"), 0755)
+	os.WriteFile(filepath.Join(dir, "nested", "out.txt"), []byte("x"), 0644)
+
+	dt, _ := NewDeleteFileTool(ws)
+	_, err := dt.InvokableRun(context.Background(), fmt.Sprintf(`{"path":%q}`, dir))
+	if err == nil || !strings.Contains(err.Error(), "recursive") {
+		t.Fatalf("expected recursive error, got %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected directory to remain, got %v", err)
+	}
+
+	if _, err := dt.InvokableRun(context.Background(), fmt.Sprintf(`{"path":%q,"recursive":true}`, dir)); err != nil {
+		t.Fatalf("recursive delete error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected directory to be deleted, stat err=%v", err)
+	}
+}
+
+func TestDeleteFile_RefusesEscapesAndWorkspaceRoot(t *testing.T) {
+	ws := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	os.WriteFile(secret, []byte("x"), 0644)
+
+	dt, _ := NewDeleteFileTool(ws)
+	for _, path := range []string{filepath.Join(ws, "..", filepath.Base(outside), "secret.txt"), secret} {
+		_, err := dt.InvokableRun(context.Background(), fmt.Sprintf(`{"path":%q}`, path))
+		if err == nil || !strings.Contains(err.Error(), "outside workspace") {
+			t.Fatalf("expected outside workspace error for %s, got %v", path, err)
+		}
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Fatalf("expected outside file to remain, got %v", err)
+	}
+
+	_, err := dt.InvokableRun(context.Background(), fmt.Sprintf(`{"path":%q,"recursive":true}`, ws))
+	if err == nil || !strings.Contains(err.Error(), "workspace root") {
+		t.Fatalf("expected workspace root refusal, got %v", err)
+	}
+}
@@ -11,39 +11,75 @@ import (
 	"github.com/cloudwego/eino/components/tool/utils"
 )
 
-// validatePath 验证给定路径是否在允许的工作区范围内，防止路径穿越攻击。
-// 如果 workspacePath 为空，则跳过验证（仅用于向后兼容）。
-func validatePath(path, workspacePath string) error {
-	if workspacePath == "" {
-		return nil
+// userHomeDir 返回当前用户主目录，测试中可替换。
+var userHomeDir = os.UserHomeDir
+
+// ResolveWithinWorkspace 将工具传入的路径解析为工作区内的绝对路径。
+// 相对路径基于工作区解析，"~" 与 "~/" 前缀展开为用户主目录；
+// 绝对路径必须位于工作区内。解析后的路径（包括其中已存在部分的符号链接）
+// 一旦落在工作区之外（如 "../../etc/passwd" 或指向外部的符号链接目录）即返回错误。
+func ResolveWithinWorkspace(workspace, rel string) (string, error) {
+	if strings.TrimSpace(workspace) == "" {
+		return "", fmt.Errorf("workspace is required")
+	}
+	path := strings.TrimSpace(rel)
+	if path == "" {
+		return "", fmt.Errorf("path is required")
 	}
 
-	workspaceAbs, err := filepath.Abs(workspacePath)
+	workspaceAbs, err := filepath.Abs(workspace)
 	if err != nil {
-		return fmt.Errorf("failed to resolve workspace path: %w", err)
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
 	}
 	workspaceAbs = filepath.Clean(workspaceAbs)
-
 	workspaceResolved, err := resolvePathBestEffort(workspaceAbs)
 	if err != nil {
-		return fmt.Errorf("failed to resolve workspace path: %w", err)
+		return "", fmt.Errorf("failed to resolve workspace path: %w", err)
 	}
 
+	path, err = expandHome(path)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workspaceAbs, path)
+	}
 	targetAbs, err := filepath.Abs(path)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
+		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
 	targetAbs = filepath.Clean(targetAbs)
 
 	targetResolved, err := resolvePathBestEffort(targetAbs)
 	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
+		return "", fmt.Errorf("failed to resolve path: %w", err)
 	}
 
 	if !isWithinWorkspace(targetResolved, workspaceResolved) {
-		return fmt.Errorf("access denied: path %q is outside workspace %q", targetResolved, workspaceResolved)
+		return "", fmt.Errorf("access denied: path %q is outside workspace %q", targetResolved, workspaceResolved)
 	}
-	return nil
+	return targetAbs, nil
+}
+
+// resolveToolPath 解析文件类工具的路径参数。workspacePath 为空时不做限制
+// （仅用于向后兼容），否则委托 ResolveWithinWorkspace 校验。
+func resolveToolPath(path, workspacePath string) (string, error) {
+	if workspacePath == "" {
+		return expandHome(strings.TrimSpace(path))
+	}
+	return ResolveWithinWorkspace(workspacePath, path)
+}
+
+// expandHome 将 "~" 与 "~/..." 展开为用户主目录；其他路径原样返回。
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := userHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to expand ~: %w", err)
+	}
+	return filepath.Join(home, path[1:]), nil
 }
 
 func isWithinWorkspace(target, workspace string) bool {
@@ -93,9 +129,9 @@ func resolvePathBestEffort(path string) (string, error) {
 
 // ReadFileInput 定义了 read_file 工具的输入参数。
 type ReadFileInput struct {
-	Path   string `json:"path" jsonschema:"required,description=Absolute path to the file"`
-	Offset int    `json:"offset" jsonschema:"description=Starting line number (0-based)"`
-	Limit  int    `json:"limit" jsonschema:"description=Maximum number of lines to read"`
+	Path   string `json:"path" jsonschema:"required,description=Path to the file (absolute or relative to the workspace)"`
+	Offset int    `json:"offset,omitempty" jsonschema:"description=Starting line number (0-based)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"description=Maximum number of lines to read"`
 }
 
 // ReadFileOutput 定义了 read_file 工具的执行结果。
@@ -109,11 +145,12 @@ type readFileToolImpl struct {
 }
 
 func (t *readFileToolImpl) execute(ctx context.Context, input *ReadFileInput) (*ReadFileOutput, error) {
-	if err := validatePath(input.Path, t.workspacePath); err != nil {
+	path, err := resolveToolPath(input.Path, t.workspacePath)
+	if err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(input.Path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +185,7 @@ func NewReadFileTool(workspacePath string) (tool.InvokableTool, error) {
 
 // WriteFileInput 定义了 write_file 工具的输入参数。
 type WriteFileInput struct {
-	Path    string `json:"path" jsonschema:"required,description=Absolute path to the file"`
+	Path    string `json:"path" jsonschema:"required,description=Path to the file (absolute or relative to the workspace)"`
 	Content string `json:"content" jsonschema:"required,description=Content to write"`
 }
 
@@ -157,12 +194,12 @@ type writeFileToolImpl struct {
 }
 
 func (t *writeFileToolImpl) execute(ctx context.Context, input *WriteFileInput) (string, error) {
-	if err := validatePath(input.Path, t.workspacePath); err != nil {
+	path, err := resolveToolPath(input.Path, t.workspacePath)
+	if err != nil {
 		return "", err
 	}
 
-	err := os.WriteFile(input.Path, []byte(input.Content), 0644)
-	if err != nil {
+	if err := os.WriteFile(path, []byte(input.Content), 0644); err != nil {
 		return "", err
 	}
 	return "File written successfully", nil
@@ -184,11 +221,12 @@ type listDirToolImpl struct {
 }
 
 func (t *listDirToolImpl) execute(ctx context.Context, input *ListDirInput) ([]string, error) {
-	if err := validatePath(input.Path, t.workspacePath); err != nil {
+	path, err := resolveToolPath(input.Path, t.workspacePath)
+	if err != nil {
 		return nil, err
 	}
 
-	entries, err := os.ReadDir(input.Path)
+	entries, err := os.ReadDir(path)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
 )
 
 func TestWriteFileTool(t *testing.T) {
@@ -219,3 +221,119 @@ func TestWriteFile_SymlinkEscapeBlocked(t *testing.T) {
 		t.Fatalf("expected access denied, got: %v", err)
 	}
 }
+
+func TestResolveWithinWorkspace(t *testing.T) {
+	ws := t.TempDir()
+	os.MkdirAll(filepath.Join(ws, "docs"), 0755)
+
+	cases := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "relative", path: "docs/a.md", want: filepath.Join(ws, "docs", "a.md")},
+		{name: "absolute inside", path: filepath.Join(ws, "docs"), want: filepath.Join(ws, "docs")},
+		{name: "workspace root", path: ".", want: ws},
+		{name: "dotdot inside", path: "docs/../b.txt", want: filepath.Join(ws, "b.txt")},
+		{name: "traversal", path: "../../etc/passwd", wantErr: true},
+		{name: "nested traversal", path: "docs/../../x", wantErr: true},
+		{name: "absolute outside", path: filepath.Join(filepath.Dir(ws), "x"), wantErr: true},
+		{name: "empty", path: "  ", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveWithinWorkspace(ws, tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got path %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := ResolveWithinWorkspace("", "a.txt"); err == nil {
+		t.Fatal("expected error for empty workspace")
+	}
+}
+
+func TestResolveWithinWorkspace_SymlinkedDirectory(t *testing.T) {
+	ws := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(ws, "real"), 0755)
+	if err := os.Symlink(outside, filepath.Join(ws, "escape")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(ws, "real"), filepath.Join(ws, "alias")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	// 指向工作区外部的符号链接目录，即便目标文件尚不存在也应拒绝
+	for _, p := range []string{"escape", "escape/secret.txt", "escape/new/dir/file.txt"} {
+		if _, err := ResolveWithinWorkspace(ws, p); err == nil || !strings.Contains(err.Error(), "outside workspace") {
+			t.Fatalf("expected %q to be rejected, got %v", p, err)
+		}
+	}
+	// 指向工作区内部的符号链接目录可以使用
+	if _, err := ResolveWithinWorkspace(ws, "alias/file.txt"); err != nil {
+		t.Fatalf("expected in-workspace symlink to be allowed, got %v", err)
+	}
+}
+
+func TestResolveWithinWorkspace_ExpandsHome(t *testing.T) {
+	ws := t.TempDir()
+	home := filepath.Join(ws, "home")
+	os.MkdirAll(home, 0755)
+
+	orig := userHomeDir
+	t.Cleanup(func() { userHomeDir = orig })
+
+	userHomeDir = func() (string, error) { return home, nil }
+	got, err := ResolveWithinWorkspace(ws, "~/notes.md")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != filepath.Join(home, "notes.md") {
+		t.Fatalf("expected ~ to expand to %q, got %q", filepath.Join(home, "notes.md"), got)
+	}
+
+	// 主目录位于工作区外时，"~" 路径被拒绝；"~name" 不展开，按普通相对路径处理
+	userHomeDir = func() (string, error) { return t.TempDir(), nil }
+	if _, err := ResolveWithinWorkspace(ws, "~/.ssh/id_rsa"); err == nil {
+		t.Fatal("expected ~ path outside workspace to be rejected")
+	}
+	if _, err := ResolveWithinWorkspace(ws, "~"); err == nil {
+		t.Fatal("expected bare ~ outside workspace to be rejected")
+	}
+	got, err = ResolveWithinWorkspace(ws, "~draft.txt")
+	if err != nil || got != filepath.Join(ws, "~draft.txt") {
+		t.Fatalf("expected ~draft.txt to stay relative, got %q err=%v", got, err)
+	}
+}
+
+func TestFileTools_ResolveRelativePathsAgainstWorkspace(t *testing.T) {
+	ws := t.TempDir()
+	wt, _ := NewWriteFileTool(ws)
+	if _, err := wt.InvokableRun(context.Background(), `{"path":"notes.txt","content":"hi"}`); err != nil {
+		t.Fatalf("write_file error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(ws, "notes.txt")); err != nil || string(data) != "hi" {
+		t.Fatalf("expected file written inside workspace, got %q err=%v", data, err)
+	}
+
+	for _, newTool := range []func(string) (tool.InvokableTool, error){NewReadFileTool, NewEditFileTool, NewAppendFileTool, NewDeleteFileTool} {
+		it, _ := newTool(ws)
+		_, err := it.InvokableRun(context.Background(), `{"path":"../../etc/passwd","old_text":"a","new_text":"b","content":"x"}`)
+		if err == nil || !strings.Contains(err.Error(), "outside workspace") {
+			info, _ := it.Info(context.Background())
+			t.Fatalf("%s: expected traversal to be rejected, got %v", info.Name, err)
+		}
+	}
+}
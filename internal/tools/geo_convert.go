@@ -6,14 +6,13 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 // GeoFormatConvertInput defines the input for the geo_format_convert tool.
 type GeoFormatConvertInput struct {
 	InputPath    string `json:"input_path" jsonschema:"required,description=Source geospatial file path"`
 	OutputPath   string `json:"output_path" jsonschema:"required,description=Destination file path"`
-	OutputFormat string `json:"output_format" jsonschema:"description=GDAL driver name for output format (auto-detected from extension if empty)"`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"description=GDAL driver name for output format (auto-detected from extension if empty)"`
 }
 
 // GeoFormatConvertOutput defines the output of the geo_format_convert tool.
@@ -132,7 +131,7 @@ func NewGeoFormatConvertTool(gdalBinDir, workspaceDir string, timeoutSec int, re
 		timeoutSec:          timeoutSec,
 		restrictToWorkspace: restrictToWorkspace,
 	}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_format_convert",
 		"Convert a geospatial file between formats (e.g. Shapefile → GeoJSON, GeoTIFF → PNG). "+
 			"Auto-detects raster vs vector and uses gdal_translate or ogr2ogr accordingly.",
@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 // GeoCrsDetectInput defines the input for the geo_crs_detect tool.
@@ -147,7 +146,7 @@ func NewGeoCrsDetectTool(gdalBinDir, workspaceDir string, restrictToWorkspace bo
 		workspaceDir:        workspaceDir,
 		restrictToWorkspace: restrictToWorkspace,
 	}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_crs_detect",
 		"Detect the Coordinate Reference System (CRS) of a geospatial file. "+
 			"Returns EPSG code, projection name, whether it is geographic or projected, and the unit.",
@@ -13,8 +13,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 const (
@@ -25,11 +25,11 @@ const (
 // GeoDataCatalogInput defines the input for the geo_data_catalog tool.
 type GeoDataCatalogInput struct {
 	Action      string            `json:"action" jsonschema:"required,description=Catalog action: local_scan, overpass_search, or stac_search"`
-	Path        string            `json:"path" jsonschema:"description=Local path to scan for action=local_scan"`
-	BBox        []float64         `json:"bbox" jsonschema:"description=Bounding box as [minLon,minLat,maxLon,maxLat] for remote searches"`
-	Tags        map[string]string `json:"tags" jsonschema:"description=OSM tag filters for action=overpass_search"`
-	Collections []string          `json:"collections" jsonschema:"description=STAC collections for action=stac_search"`
-	Limit       int               `json:"limit" jsonschema:"description=Maximum number of results to return"`
+	Path        string            `json:"path,omitempty" jsonschema:"description=Local path to scan for action=local_scan"`
+	BBox        []float64         `json:"bbox,omitempty" jsonschema:"description=Bounding box as [minLon,minLat,maxLon,maxLat] for remote searches"`
+	Tags        map[string]string `json:"tags,omitempty" jsonschema:"description=OSM tag filters for action=overpass_search"`
+	Collections []string          `json:"collections,omitempty" jsonschema:"description=STAC collections for action=stac_search"`
+	Limit       int               `json:"limit,omitempty" jsonschema:"description=Maximum number of results to return"`
 }
 
 // GeoDataCatalogItem describes one catalog result.
@@ -277,9 +277,9 @@ func (t *geoDataCatalogToolImpl) client() *http.Client {
 	}
 	timeout := time.Duration(t.timeoutSec) * time.Second
 	if timeout <= 0 {
-		timeout = 15 * time.Second
+		timeout = httpclient.Timeout(15 * time.Second)
 	}
-	return &http.Client{Timeout: timeout}
+	return httpclient.New(timeout)
 }
 
 func resolveGeoCatalogLimit(limit int) int {
@@ -332,7 +332,7 @@ func NewGeoDataCatalogTool(workspaceDir string, restrictToWorkspace bool, timeou
 		overpassEndpoint:    defaultOverpassEndpoint,
 		stacEndpoint:        defaultSTACEndpoint,
 	}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_data_catalog",
 		"Discover geospatial datasets from the local workspace, OpenStreetMap Overpass, or a STAC API.",
 		impl.execute,
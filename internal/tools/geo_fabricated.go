@@ -117,7 +117,7 @@ func (t *geoFabricatedTool) InvokableRun(ctx context.Context, argumentsInJSON st
 
 	if err := cmd.Run(); err != nil {
 		if timeoutCtx.Err() == context.DeadlineExceeded {
-			return "", fmt.Errorf("fabricated geo tool %q timed out after %ds", t.def.Name, t.def.TimeoutSeconds)
+			return "", fmt.Errorf("fabricated geo tool %q timed out after %s: %w", t.def.Name, invocation.timeout, context.DeadlineExceeded)
 		}
 		msg := strings.TrimSpace(stderr.String())
 		if msg == "" {
@@ -164,14 +164,15 @@ func validateGeoFabricatedArguments(input map[string]any, parameters map[string]
 			}
 			continue
 		}
-		if !matchesGeoFabricatedType(value, param.Type) {
+		if !matchesParameterType(value, param.Type) {
 			return fmt.Errorf("fabricated geo tool argument %q must be %s", name, param.Type)
 		}
 	}
 	return nil
 }
 
-func matchesGeoFabricatedType(value any, expected string) bool {
+// matchesParameterType 报告 JSON 解码后的参数值是否符合声明的参数类型。
+func matchesParameterType(value any, expected string) bool {
 	switch expected {
 	case "string":
 		_, ok := value.(string)
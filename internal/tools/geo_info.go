@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 // GeoInfoInput defines the input for the geo_info tool.
@@ -140,7 +139,7 @@ func NewGeoInfoTool(gdalBinDir, workspaceDir string, restrictToWorkspace bool) (
 		workspaceDir:        workspaceDir,
 		restrictToWorkspace: restrictToWorkspace,
 	}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_info",
 		"Inspect a geospatial file (raster or vector) and return metadata: format, CRS, extent, and size. Uses gdalinfo/ogrinfo.",
 		impl.execute,
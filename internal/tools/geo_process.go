@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 // GeoProcessInput defines the input for the geo_process tool.
@@ -121,7 +120,7 @@ func NewGeoProcessTool(gdalBinDir, workspaceDir string, timeoutSec int, restrict
 		timeoutSec:          timeoutSec,
 		restrictToWorkspace: restrictToWorkspace,
 	}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_process",
 		"Execute a whitelisted GDAL command (e.g. gdal_translate, gdalwarp, ogr2ogr) with given arguments. "+
 			"Use for format conversion, reprojection, clipping, and other raster/vector processing.",
@@ -9,14 +9,13 @@ import (
 	"time"
 
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
 // GeoSpatialQueryInput defines the input for the geo_spatial_query tool.
 type GeoSpatialQueryInput struct {
 	Action string `json:"action" jsonschema:"required,description=Operation mode: schema or query"`
-	SQL    string `json:"sql" jsonschema:"description=Read-only SQL to execute when action is query"`
+	SQL    string `json:"sql,omitempty" jsonschema:"description=Read-only SQL to execute when action is query"`
 }
 
 // GeoSpatialQueryOutput defines the output for the geo_spatial_query tool.
@@ -303,7 +302,7 @@ func NewGeoSpatialQueryTool(postGISDSN string, timeoutSec, maxRows int, readonly
 		},
 	}
 
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_spatial_query",
 		"Inspect visible PostGIS schema metadata or execute a single read-only spatial SQL query. "+
 			"Use action=schema to list tables and geometry columns, or action=query to run a SELECT/WITH/EXPLAIN query.",
@@ -7,16 +7,15 @@ import (
 
 	"github.com/MEKXH/golem/internal/geocodebook"
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 // GeoSQLCodebookInput defines the input for the geo_sql_codebook tool.
 type GeoSQLCodebookInput struct {
 	Action  string            `json:"action" jsonschema:"required,description=Codebook operation: list or render"`
-	Intent  string            `json:"intent" jsonschema:"description=Freeform intent used to rank matching patterns"`
-	Pattern string            `json:"pattern" jsonschema:"description=Named codebook pattern to render"`
-	Values  map[string]string `json:"values" jsonschema:"description=Variable values for template rendering"`
-	Limit   int               `json:"limit" jsonschema:"description=Maximum number of matches to return for action=list"`
+	Intent  string            `json:"intent,omitempty" jsonschema:"description=Freeform intent used to rank matching patterns"`
+	Pattern string            `json:"pattern,omitempty" jsonschema:"description=Named codebook pattern to render"`
+	Values  map[string]string `json:"values,omitempty" jsonschema:"description=Variable values for template rendering"`
+	Limit   int               `json:"limit,omitempty" jsonschema:"description=Maximum number of matches to return for action=list"`
 }
 
 // GeoSQLCodebookOutput defines the output for the geo_sql_codebook tool.
@@ -74,7 +73,7 @@ func (t *geoSQLCodebookToolImpl) execute(ctx context.Context, input *GeoSQLCodeb
 // NewGeoSQLCodebookTool creates the geo_sql_codebook tool for ranked codebook lookup and SQL rendering.
 func NewGeoSQLCodebookTool(workspacePath string) (tool.InvokableTool, error) {
 	impl := &geoSQLCodebookToolImpl{loader: geocodebook.NewLoader(workspacePath)}
-	return utils.InferTool(
+	return inferStructuredTool(
 		"geo_sql_codebook",
 		"Look up verified spatial SQL patterns from the workspace geo-codebook and render them with variables before using geo_spatial_query.",
 		impl.execute,
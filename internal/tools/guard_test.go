@@ -47,6 +47,9 @@ func TestRegistry_Execute_DeniedByGuard(t *testing.T) {
 	if !strings.Contains(err.Error(), "blocked by policy") {
 		t.Fatalf("expected deny message in error, got: %v", err)
 	}
+	if Classify(Result{}, err) != OutcomeDenied {
+		t.Fatalf("expected denied outcome, got %q", Classify(Result{}, err))
+	}
 	if mock.runs != 0 {
 		t.Fatalf("expected tool not to run, ran %d times", mock.runs)
 	}
@@ -7,10 +7,13 @@ import (
 
 type invocationContextKey struct{}
 
+type subagentDepthKey struct{}
+
 // InvocationContext 携带工具执行时的调用者元数据，如通道、聊天 ID 等。
 type InvocationContext struct {
 	Channel   string // 调用来源通道（如 telegram, cli）
 	ChatID    string // 调用来源聊天会话 ID
+	ThreadID  string // 调用来源消息所在线程（见 bus.MetadataThreadID），不在线程中时为空
 	SenderID  string // 发起调用的发送者 ID
 	RequestID string // 关联的请求追踪 ID
 	SessionID string // 关联的会话 ID
@@ -31,8 +34,20 @@ func InvocationFromContext(ctx context.Context) InvocationContext {
 	// 确保返回的字符串经过修整，无多余空格
 	meta.Channel = strings.TrimSpace(meta.Channel)
 	meta.ChatID = strings.TrimSpace(meta.ChatID)
+	meta.ThreadID = strings.TrimSpace(meta.ThreadID)
 	meta.SenderID = strings.TrimSpace(meta.SenderID)
 	meta.RequestID = strings.TrimSpace(meta.RequestID)
 	meta.SessionID = strings.TrimSpace(meta.SessionID)
 	return meta
 }
+
+// WithSubagentDepth 记录当前执行所处的子代理嵌套深度（主代理为 0），供委派类工具计算下一层深度。
+func WithSubagentDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, subagentDepthKey{}, depth)
+}
+
+// SubagentDepthFromContext 返回当前的子代理嵌套深度，未设置时为 0。
+func SubagentDepthFromContext(ctx context.Context) int {
+	depth, _ := ctx.Value(subagentDepthKey{}).(int)
+	return depth
+}
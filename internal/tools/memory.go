@@ -2,18 +2,27 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/MEKXH/golem/internal/memory"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
 )
 
-// ReadMemoryInput 定义了 read_memory 工具的输入参数（无参数）。
-type ReadMemoryInput struct{}
+// ReadMemoryInput 定义了 read_memory 工具的输入参数。
+type ReadMemoryInput struct {
+	Section string `json:"section,omitempty" jsonschema:"description=Memory section such as preferences or projects/foo; empty reads the default section (MEMORY.md)"`
+}
 
 // ReadMemoryOutput 定义了 read_memory 工具的执行结果。
 type ReadMemoryOutput struct {
-	Content string `json:"content"` // 长期记忆文件的完整内容
+	Section  string   `json:"section"`  // 读取的分区名
+	Content  string   `json:"content"`  // 分区的完整内容
+	Sections []string `json:"sections"` // 已有的全部分区
 }
 
 type readMemoryToolImpl struct {
@@ -21,22 +30,33 @@ type readMemoryToolImpl struct {
 }
 
 func (t *readMemoryToolImpl) execute(ctx context.Context, input *ReadMemoryInput) (*ReadMemoryOutput, error) {
-	content, err := t.manager.ReadLongTerm()
+	section, err := memory.NormalizeSection(input.Section)
+	if err != nil {
+		return nil, err
+	}
+	content, err := t.manager.ReadLongTerm(section)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := t.manager.ListSections()
 	if err != nil {
 		return nil, err
 	}
-	return &ReadMemoryOutput{Content: content}, nil
+	return &ReadMemoryOutput{Section: section, Content: content, Sections: sections}, nil
 }
 
-// NewReadMemoryTool 创建 read_memory 工具实例，用于从 memory/MEMORY.md 读取长期记忆。
+// NewReadMemoryTool 创建 read_memory 工具实例，用于读取长期记忆分区（默认为 memory/MEMORY.md）并列出已有分区。
 func NewReadMemoryTool(workspacePath string) (tool.InvokableTool, error) {
 	impl := &readMemoryToolImpl{manager: memory.NewManager(workspacePath)}
-	return utils.InferTool("read_memory", "Read long-term memory from memory/MEMORY.md", impl.execute)
+	return utils.InferTool("read_memory",
+		"Read a long-term memory section (default: memory/MEMORY.md; named sections live in memory/<section>.md) and list the existing sections",
+		impl.execute)
 }
 
 // WriteMemoryInput 定义了 write_memory 工具的输入参数。
 type WriteMemoryInput struct {
-	Content string `json:"content" jsonschema:"required,description=Content to store as long-term memory"`
+	Content string `json:"content" jsonschema:"required,description=Content to store as long-term memory; replaces the whole section"`
+	Section string `json:"section,omitempty" jsonschema:"description=Memory section such as preferences or projects/foo; empty writes the default section (MEMORY.md)"`
 }
 
 type writeMemoryToolImpl struct {
@@ -44,16 +64,25 @@ type writeMemoryToolImpl struct {
 }
 
 func (t *writeMemoryToolImpl) execute(ctx context.Context, input *WriteMemoryInput) (string, error) {
-	if err := t.manager.WriteLongTerm(input.Content); err != nil {
+	section, err := memory.NormalizeSection(input.Section)
+	if err != nil {
+		return "", err
+	}
+	if err := t.manager.WriteLongTerm(section, input.Content); err != nil {
 		return "", err
 	}
-	return "Memory updated successfully", nil
+	if section == memory.DefaultSection {
+		return "Memory updated successfully", nil
+	}
+	return fmt.Sprintf("Memory section %s updated successfully", section), nil
 }
 
-// NewWriteMemoryTool 创建 write_memory 工具实例，用于将内容写入 memory/MEMORY.md 作为长期记忆。
+// NewWriteMemoryTool 创建 write_memory 工具实例，用于覆盖写入一个长期记忆分区，其他分区不受影响。
 func NewWriteMemoryTool(workspacePath string) (tool.InvokableTool, error) {
 	impl := &writeMemoryToolImpl{manager: memory.NewManager(workspacePath)}
-	return utils.InferTool("write_memory", "Write long-term memory to memory/MEMORY.md", impl.execute)
+	return utils.InferTool("write_memory",
+		"Write (replace) a long-term memory section; other sections are untouched. Default section is memory/MEMORY.md",
+		impl.execute)
 }
 
 // AppendDiaryInput 定义了 append_diary 工具的输入参数。
@@ -83,3 +112,130 @@ func NewAppendDiaryTool(workspacePath string) (tool.InvokableTool, error) {
 	impl := &appendDiaryToolImpl{manager: memory.NewManager(workspacePath)}
 	return utils.InferTool("append_diary", "Append a diary entry under memory/YYYY-MM-DD.md", impl.execute)
 }
+
+// recallMemoryMaxLimit 是 recall_memory 单类召回数量的上限。
+const recallMemoryMaxLimit = 10
+
+// RecallMemoryInput 定义了 recall_memory 工具的输入参数。
+type RecallMemoryInput struct {
+	Query        string `json:"query" jsonschema:"required,description=What to look for in memory; keywords or a question"`
+	RecentLimit  int    `json:"recent_limit,omitempty" jsonschema:"description=Number of most recent diary days to include (default 3, max 10)"`
+	KeywordLimit int    `json:"keyword_limit,omitempty" jsonschema:"description=Maximum older diary days matched by keyword (default 3, max 10)"`
+	Section      string `json:"section,omitempty" jsonschema:"description=Only search this long-term memory namespace (e.g. projects also covers projects/foo); diaries are skipped"`
+}
+
+// RecallMemoryItem 是 recall_memory 返回的单条片段，附带来源归属。
+type RecallMemoryItem struct {
+	Source  string `json:"source"`            // diary_recent、diary_keyword、long_term 或 semantic
+	Section string `json:"section,omitempty"` // 长期记忆分区
+	Date    string `json:"date,omitempty"`    // 日记日期
+	Path    string `json:"path"`              // 相对工作区的来源文件路径
+	Excerpt string `json:"excerpt"`
+}
+
+// RecallMemoryOutput 定义了 recall_memory 工具的执行结果。
+type RecallMemoryOutput struct {
+	Query       string             `json:"query"`
+	RecallCount int                `json:"recall_count"`
+	SourceHits  map[string]int     `json:"source_hits"`
+	Items       []RecallMemoryItem `json:"items"`
+}
+
+type recallMemoryToolImpl struct {
+	workspacePath string
+	manager       *memory.Manager
+}
+
+func (t *recallMemoryToolImpl) execute(ctx context.Context, input *RecallMemoryInput) (*RecallMemoryOutput, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+	if input.RecentLimit < 0 || input.KeywordLimit < 0 {
+		return nil, fmt.Errorf("recent_limit and keyword_limit must not be negative")
+	}
+	var result memory.RecallResult
+	var err error
+	if strings.TrimSpace(input.Section) != "" {
+		result, err = t.manager.RecallSection(query, input.Section, min(input.KeywordLimit, recallMemoryMaxLimit))
+	} else {
+		result, err = t.manager.RecallContext(query, min(input.RecentLimit, recallMemoryMaxLimit), min(input.KeywordLimit, recallMemoryMaxLimit))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := &RecallMemoryOutput{
+		Query:       result.Query,
+		RecallCount: result.RecallCount,
+		SourceHits:  result.SourceHits,
+		Items:       make([]RecallMemoryItem, 0, len(result.Items)),
+	}
+	for _, item := range result.Items {
+		path := item.Path
+		if rel, err := filepath.Rel(t.workspacePath, path); err == nil {
+			path = filepath.ToSlash(rel)
+		}
+		out.Items = append(out.Items, RecallMemoryItem{
+			Source:  item.Source,
+			Section: item.Section,
+			Date:    item.Date,
+			Path:    path,
+			Excerpt: item.Excerpt,
+		})
+	}
+	return out, nil
+}
+
+// NewRecallMemoryTool 创建 recall_memory 工具实例，按查询从日记与长期记忆中检索相关片段。
+func NewRecallMemoryTool(workspacePath string) (tool.InvokableTool, error) {
+	return NewRecallMemoryToolWithOptions(workspacePath, memory.Options{})
+}
+
+// NewRecallMemoryToolWithOptions 创建 recall_memory 工具实例；opts 配置了向量模型时同时返回语义召回结果。
+func NewRecallMemoryToolWithOptions(workspacePath string, opts memory.Options) (tool.InvokableTool, error) {
+	impl := &recallMemoryToolImpl{
+		workspacePath: workspacePath,
+		manager:       memory.NewManagerWithOptions(workspacePath, opts),
+	}
+	return utils.InferTool("recall_memory",
+		"Search memory for context relevant to a query: recent diary days, older diary days and long-term memory matching its keywords (and semantically similar entries when enabled). Each item names its source",
+		impl.execute)
+}
+
+// ReadDiaryInput 定义了 read_diary 工具的输入参数。
+type ReadDiaryInput struct {
+	Date string `json:"date" jsonschema:"required,description=Diary date in YYYY-MM-DD format"`
+}
+
+// ReadDiaryOutput 定义了 read_diary 工具的执行结果。
+type ReadDiaryOutput struct {
+	Date    string `json:"date"`
+	Path    string `json:"path"` // 相对工作区的日记文件路径
+	Content string `json:"content"`
+}
+
+type readDiaryToolImpl struct {
+	manager *memory.Manager
+}
+
+func (t *readDiaryToolImpl) execute(ctx context.Context, input *ReadDiaryInput) (*ReadDiaryOutput, error) {
+	date := strings.TrimSpace(input.Date)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, fmt.Errorf("date must use YYYY-MM-DD format, got %q", input.Date)
+	}
+	content, err := t.manager.ReadDiary(date)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no diary entries for %s", date)
+		}
+		return nil, err
+	}
+	return &ReadDiaryOutput{Date: date, Path: "memory/" + date + ".md", Content: content}, nil
+}
+
+// NewReadDiaryTool 创建 read_diary 工具实例，用于读取 memory/YYYY-MM-DD.md 中指定日期的日记。
+func NewReadDiaryTool(workspacePath string) (tool.InvokableTool, error) {
+	impl := &readDiaryToolImpl{manager: memory.NewManager(workspacePath)}
+	return utils.InferTool("read_diary", "Read the diary entries of one day from memory/YYYY-MM-DD.md", impl.execute)
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -65,3 +66,106 @@ func TestAppendDiaryTool(t *testing.T) {
 		t.Fatal("expected a diary markdown file to be created")
 	}
 }
+
+func TestRecallMemoryTool_ReturnsAttributedItems(t *testing.T) {
+	workspace := t.TempDir()
+	memDir := filepath.Join(workspace, "memory")
+	if err := os.MkdirAll(memDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(memDir, "MEMORY.md"), []byte("prefers postgres for new services"), 0o644)
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-10.md"), []byte("- [09:00:00] migrated billing to postgres"), 0o644)
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-11.md"), []byte("- [09:00:00] weekly sync"), 0o644)
+
+	recallTool, err := NewRecallMemoryTool(workspace)
+	if err != nil {
+		t.Fatalf("NewRecallMemoryTool error: %v", err)
+	}
+	result, err := recallTool.InvokableRun(context.Background(), `{"query":"postgres migration","recent_limit":1}`)
+	if err != nil {
+		t.Fatalf("recall_memory error: %v", err)
+	}
+
+	var out RecallMemoryOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if out.RecallCount != 3 || out.SourceHits["diary_recent"] != 1 || out.SourceHits["long_term"] != 1 || out.SourceHits["diary_keyword"] != 1 {
+		t.Fatalf("unexpected recall result: %+v", out)
+	}
+	last := out.Items[len(out.Items)-1]
+	if last.Source != "diary_keyword" || last.Date != "2026-02-10" || last.Path != "memory/2026-02-10.md" || !strings.Contains(last.Excerpt, "billing") {
+		t.Fatalf("unexpected keyword item: %+v", last)
+	}
+
+	if _, err := recallTool.InvokableRun(context.Background(), `{"query":"  "}`); err == nil {
+		t.Fatal("expected error for empty query")
+	}
+}
+
+func TestReadDiaryTool(t *testing.T) {
+	workspace := t.TempDir()
+	memDir := filepath.Join(workspace, "memory")
+	if err := os.MkdirAll(memDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	_ = os.WriteFile(filepath.Join(memDir, "2026-02-10.md"), []byte("- [09:00:00] shipped v1.2\n"), 0o644)
+
+	readTool, err := NewReadDiaryTool(workspace)
+	if err != nil {
+		t.Fatalf("NewReadDiaryTool error: %v", err)
+	}
+	ctx := context.Background()
+	result, err := readTool.InvokableRun(ctx, `{"date":"2026-02-10"}`)
+	if err != nil {
+		t.Fatalf("read_diary error: %v", err)
+	}
+	if !strings.Contains(result, "shipped v1.2") || !strings.Contains(result, "memory/2026-02-10.md") {
+		t.Fatalf("unexpected read_diary output: %s", result)
+	}
+
+	if _, err := readTool.InvokableRun(ctx, `{"date":"2026-02-09"}`); err == nil || !strings.Contains(err.Error(), "no diary entries") {
+		t.Fatalf("expected missing diary error, got %v", err)
+	}
+	if _, err := readTool.InvokableRun(ctx, `{"date":"../MEMORY"}`); err == nil || !strings.Contains(err.Error(), "YYYY-MM-DD") {
+		t.Fatalf("expected date format error, got %v", err)
+	}
+}
+
+func TestMemoryTools_Sections(t *testing.T) {
+	workspace := t.TempDir()
+	writeTool, _ := NewWriteMemoryTool(workspace)
+	readTool, _ := NewReadMemoryTool(workspace)
+	recallTool, _ := NewRecallMemoryTool(workspace)
+	ctx := context.Background()
+
+	if _, err := writeTool.InvokableRun(ctx, `{"content":"core facts"}`); err != nil {
+		t.Fatalf("write default: %v", err)
+	}
+	if _, err := writeTool.InvokableRun(ctx, `{"section":"projects/foo","content":"foo deploys on fridays"}`); err != nil {
+		t.Fatalf("write section: %v", err)
+	}
+	if _, err := writeTool.InvokableRun(ctx, `{"section":"../etc","content":"x"}`); err == nil {
+		t.Fatal("expected invalid section error")
+	}
+
+	result, err := readTool.InvokableRun(ctx, `{"section":"projects/foo"}`)
+	if err != nil {
+		t.Fatalf("read section: %v", err)
+	}
+	var out ReadMemoryOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.Content != "foo deploys on fridays" || strings.Join(out.Sections, ",") != "default,projects/foo" {
+		t.Fatalf("unexpected read_memory output: %+v", out)
+	}
+
+	result, err = recallTool.InvokableRun(ctx, `{"query":"deploys","section":"projects"}`)
+	if err != nil {
+		t.Fatalf("recall section: %v", err)
+	}
+	if !strings.Contains(result, `"section":"projects/foo"`) || strings.Contains(result, "core facts") {
+		t.Fatalf("unexpected scoped recall output: %s", result)
+	}
+}
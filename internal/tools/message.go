@@ -3,9 +3,12 @@ package tools
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/MEKXH/golem/internal/bus"
+	"github.com/MEKXH/golem/internal/channel"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
 )
@@ -28,13 +31,33 @@ func (t *messageToolImpl) execute(ctx context.Context, input *MessageInput) (str
 	if content == "" {
 		return "", fmt.Errorf("content is required")
 	}
+	channel, chatID, reqID, err := t.resolveTarget(ctx, input.Channel, input.ChatID)
+	if err != nil {
+		return "", err
+	}
+
+	t.publisher.PublishOutbound(&bus.OutboundMessage{
+		Channel:   channel,
+		ChatID:    chatID,
+		Content:   content,
+		RequestID: reqID,
+		Metadata: map[string]any{
+			"via_tool": "message",
+		},
+	})
+
+	return fmt.Sprintf("Message sent to %s:%s", channel, chatID), nil
+}
+
+// resolveTarget 确定出站消息的目标通道、聊天 ID 与请求 ID。
+func (t *messageToolImpl) resolveTarget(ctx context.Context, channel, chatID string) (string, string, string, error) {
 	if t.publisher == nil {
-		return "", fmt.Errorf("message publisher is not configured")
+		return "", "", "", fmt.Errorf("message publisher is not configured")
 	}
 
 	meta := InvocationFromContext(ctx)
-	channel := strings.TrimSpace(input.Channel)
-	chatID := strings.TrimSpace(input.ChatID)
+	channel = strings.TrimSpace(channel)
+	chatID = strings.TrimSpace(chatID)
 	// 如果未指定通道或聊天 ID，则回退到当前调用的上下文
 	if channel == "" {
 		channel = meta.Channel
@@ -43,25 +66,14 @@ func (t *messageToolImpl) execute(ctx context.Context, input *MessageInput) (str
 		chatID = meta.ChatID
 	}
 	if channel == "" || chatID == "" {
-		return "", fmt.Errorf("channel/chat_id is required when no invocation context is available")
+		return "", "", "", fmt.Errorf("channel/chat_id is required when no invocation context is available")
 	}
 
 	reqID := meta.RequestID
 	if reqID == "" {
 		reqID = bus.NewRequestID()
 	}
-
-	t.publisher.PublishOutbound(&bus.OutboundMessage{
-		Channel:   channel,
-		ChatID:    chatID,
-		Content:   content,
-		RequestID: reqID,
-		Metadata: map[string]any{
-			"via_tool": "message",
-		},
-	})
-
-	return fmt.Sprintf("Message sent to %s:%s", channel, chatID), nil
+	return channel, chatID, reqID, nil
 }
 
 // NewMessageTool 创建一个允许 Agent 发送主动消息的工具实例。
@@ -75,3 +87,80 @@ func NewMessageTool(publisher interface {
 		impl.execute,
 	)
 }
+
+// SendFileInput 定义了 send_file 工具的输入参数。
+type SendFileInput struct {
+	Path    string `json:"path" jsonschema:"required,description=Path of the workspace file to send (relative paths resolve against the workspace)"`
+	Caption string `json:"caption,omitempty" jsonschema:"description=Optional text sent before the file"`
+	Channel string `json:"channel,omitempty" jsonschema:"description=Target channel (optional; defaults to current channel)"`
+	ChatID  string `json:"chat_id,omitempty" jsonschema:"description=Target chat/session id (optional; defaults to current chat)"`
+}
+
+type sendFileToolImpl struct {
+	messageToolImpl
+	workspacePath   string
+	attachmentLimit func(channel string) (int64, bool) // 目标通道的单文件上传上限，可为空
+}
+
+func (t *sendFileToolImpl) execute(ctx context.Context, input *SendFileInput) (string, error) {
+	path := strings.TrimSpace(input.Path)
+	if path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	path, err := resolveToolPath(path, t.workspacePath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("path %q is not a regular file", input.Path)
+	}
+
+	target, chatID, reqID, err := t.resolveTarget(ctx, input.Channel, input.ChatID)
+	if err != nil {
+		return "", err
+	}
+	// 发送是异步的，超限只会在通道发送时失败；在这里先按目标通道的上限检查，让模型拿到明确的错误。
+	attachments := []bus.OutboundAttachment{{Path: path}}
+	if t.attachmentLimit != nil {
+		if limit, ok := t.attachmentLimit(target); ok {
+			if err := channel.CheckAttachments(target, attachments, limit); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	t.publisher.PublishOutbound(&bus.OutboundMessage{
+		Channel:     target,
+		ChatID:      chatID,
+		Content:     strings.TrimSpace(input.Caption),
+		RequestID:   reqID,
+		Attachments: attachments,
+		Metadata: map[string]any{
+			"via_tool": "send_file",
+		},
+	})
+
+	return fmt.Sprintf("File %s (%d bytes) sent to %s:%s", filepath.Base(path), info.Size(), target, chatID), nil
+}
+
+// NewSendFileTool 创建 send_file 工具实例，允许 Agent 将工作区内的文件作为附件发送。
+// 单文件大小上限由目标通道决定：attachmentLimit 返回通道的上限时，超限文件直接返回错误而不发送；
+// attachmentLimit 为空或不知道通道上限时，由通道在发送时校验。
+func NewSendFileTool(publisher interface {
+	PublishOutbound(msg *bus.OutboundMessage)
+}, workspacePath string, attachmentLimit func(channel string) (int64, bool)) (tool.InvokableTool, error) {
+	impl := &sendFileToolImpl{
+		messageToolImpl: messageToolImpl{publisher: publisher},
+		workspacePath:   workspacePath,
+		attachmentLimit: attachmentLimit,
+	}
+	return utils.InferTool(
+		"send_file",
+		"Send a workspace file (e.g. a generated chart or CSV) as an attachment to a channel/chat. Defaults to the current conversation when channel/chat_id is omitted.",
+		impl.execute,
+	)
+}
@@ -2,6 +2,9 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -82,3 +85,93 @@ func TestMessageTool_MissingTargetReturnsError(t *testing.T) {
 		t.Fatal("expected error when no channel/chat can be resolved")
 	}
 }
+
+func TestSendFileTool_PublishesWorkspaceAttachment(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "report.csv"), []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	pub := &capturePublisher{}
+	sendFile, err := NewSendFileTool(pub, workspace, nil)
+	if err != nil {
+		t.Fatalf("NewSendFileTool: %v", err)
+	}
+
+	ctx := WithInvocationContext(context.Background(), InvocationContext{
+		Channel:   "telegram",
+		ChatID:    "123",
+		RequestID: "req-1",
+	})
+	result, err := sendFile.InvokableRun(ctx, `{"path":"report.csv","caption":"here you go"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	if !strings.Contains(result, "report.csv") {
+		t.Fatalf("expected file name in result, got: %s", result)
+	}
+	if len(pub.msgs) != 1 {
+		t.Fatalf("expected 1 outbound message, got %d", len(pub.msgs))
+	}
+	msg := pub.msgs[0]
+	if msg.Channel != "telegram" || msg.ChatID != "123" || msg.Content != "here you go" {
+		t.Fatalf("unexpected outbound message: %+v", msg)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Name() != "report.csv" {
+		t.Fatalf("unexpected attachments: %+v", msg.Attachments)
+	}
+}
+
+func TestSendFileTool_RejectsPathsOutsideWorkspace(t *testing.T) {
+	workspace := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	pub := &capturePublisher{}
+	sendFile, err := NewSendFileTool(pub, workspace, nil)
+	if err != nil {
+		t.Fatalf("NewSendFileTool: %v", err)
+	}
+
+	ctx := WithInvocationContext(context.Background(), InvocationContext{Channel: "telegram", ChatID: "123"})
+	args, _ := json.Marshal(map[string]string{"path": outside})
+	if _, err := sendFile.InvokableRun(ctx, string(args)); err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected workspace error, got %v", err)
+	}
+	if len(pub.msgs) != 0 {
+		t.Fatalf("expected nothing to be published, got %d", len(pub.msgs))
+	}
+}
+
+func TestSendFileTool_RejectsFilesOverChannelLimit(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspace, "video.mp4"), make([]byte, 2048), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	pub := &capturePublisher{}
+	sendFile, err := NewSendFileTool(pub, workspace, func(channel string) (int64, bool) {
+		if channel == "discord" {
+			return 1024, true
+		}
+		return 0, false
+	})
+	if err != nil {
+		t.Fatalf("NewSendFileTool: %v", err)
+	}
+
+	ctx := WithInvocationContext(context.Background(), InvocationContext{Channel: "discord", ChatID: "c1"})
+	_, err = sendFile.InvokableRun(ctx, `{"path":"video.mp4"}`)
+	if err == nil || !strings.Contains(err.Error(), "exceeds discord upload limit") {
+		t.Fatalf("expected upload limit error, got %v", err)
+	}
+	if len(pub.msgs) != 0 {
+		t.Fatalf("expected nothing to be published, got %d", len(pub.msgs))
+	}
+
+	if _, err := sendFile.InvokableRun(ctx, `{"path":"video.mp4","channel":"telegram","chat_id":"1"}`); err != nil {
+		t.Fatalf("expected channel without a known limit to be left to the channel, got %v", err)
+	}
+	if len(pub.msgs) != 1 {
+		t.Fatalf("expected one published message, got %d", len(pub.msgs))
+	}
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"errors"
+)
+
+// Outcome 是一次工具调用结果的分类，供审计、指标与界面区分失败原因，而不必匹配结果文本。
+type Outcome string
+
+const (
+	OutcomeSuccess         Outcome = "success"          // 执行成功
+	OutcomeError           Outcome = "error"            // 工具执行失败
+	OutcomeDenied          Outcome = "denied"           // 被策略或命令黑白名单拒绝
+	OutcomePendingApproval Outcome = "pending_approval" // 等待人工审批，工具未执行
+	OutcomeTimeout         Outcome = "timeout"          // 超过时限被取消
+	OutcomeInvalidArgs     Outcome = "invalid_args"     // 模型给出的参数非法
+	OutcomeUnavailable     Outcome = "unavailable"      // 工具连续失败被熔断，未执行
+)
+
+// Failed 报告该结果是否应视为失败；等待审批不算失败。
+func (o Outcome) Failed() bool {
+	return o != OutcomeSuccess && o != OutcomePendingApproval
+}
+
+// DeniedError 表示守卫拒绝执行工具。
+type DeniedError struct {
+	Message string // 拒绝原因
+}
+
+func (e *DeniedError) Error() string {
+	return "tool execution denied: " + e.Message
+}
+
+// Classify 根据执行结果与错误类型判断工具调用的结果分类。
+func Classify(res Result, err error) Outcome {
+	if err == nil {
+		if res.PendingApproval {
+			return OutcomePendingApproval
+		}
+		return OutcomeSuccess
+	}
+
+	var denied *DeniedError
+	var notAllowed *CommandNotAllowedError
+	var unavailable *UnavailableError
+	switch {
+	case IsArgumentError(err):
+		return OutcomeInvalidArgs
+	case errors.As(err, &unavailable):
+		return OutcomeUnavailable
+	case errors.As(err, &denied), errors.As(err, &notAllowed):
+		return OutcomeDenied
+	case errors.Is(err, context.DeadlineExceeded):
+		return OutcomeTimeout
+	default:
+		return OutcomeError
+	}
+}
+
+// ModelContent 返回交给模型的工具结果文本：失败时为 "Error: <原因>"，否则为工具输出。
+func ModelContent(res Result, err error) string {
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return res.Content
+}
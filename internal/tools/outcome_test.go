@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		res  Result
+		err  error
+		want Outcome
+	}{
+		{name: "success", res: Result{Content: "ok"}, want: OutcomeSuccess},
+		{name: "pending approval", res: Result{Content: "pending approval", PendingApproval: true}, want: OutcomePendingApproval},
+		{name: "failure", err: errors.New("exit status 1"), want: OutcomeError},
+		{name: "denied by guard", err: &DeniedError{Message: "blocked"}, want: OutcomeDenied},
+		{name: "command not allowed", err: fmt.Errorf("run: %w", &CommandNotAllowedError{Command: "rm", Reason: "blocked"}), want: OutcomeDenied},
+		{name: "timeout", err: fmt.Errorf("invoke: %w", context.DeadlineExceeded), want: OutcomeTimeout},
+		{name: "invalid arguments", err: &ArgumentError{Tool: "read_file", Reason: "malformed JSON"}, want: OutcomeInvalidArgs},
+		{name: "circuit open", err: &UnavailableError{Tool: "exec", Failures: 5}, want: OutcomeUnavailable},
+		{name: "timeout text is not a timeout", err: errors.New("remote said: timed out"), want: OutcomeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.res, tt.err); got != tt.want {
+				t.Fatalf("Classify() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutcomeFailed(t *testing.T) {
+	if OutcomeSuccess.Failed() || OutcomePendingApproval.Failed() {
+		t.Fatal("expected success and pending approval not to count as failures")
+	}
+	for _, o := range []Outcome{OutcomeError, OutcomeDenied, OutcomeTimeout, OutcomeInvalidArgs} {
+		if !o.Failed() {
+			t.Fatalf("expected %q to count as a failure", o)
+		}
+	}
+}
+
+func TestModelContentKeepsErrorPrefix(t *testing.T) {
+	if got := ModelContent(Result{Content: "ok"}, nil); got != "ok" {
+		t.Fatalf("unexpected success content %q", got)
+	}
+	if got := ModelContent(Result{}, &DeniedError{Message: "blocked"}); got != "Error: tool execution denied: blocked" {
+		t.Fatalf("unexpected error content %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -34,6 +35,7 @@ type Registry struct {
 	mu          sync.RWMutex
 	tools       map[string]tool.InvokableTool // 工具名称到实例的映射
 	guard       GuardFunc                     // 执行前置守卫逻辑
+	breakers    *breakerSet                   // 工具熔断器，为空时不熔断
 	cachedInfos []*schema.ToolInfo
 }
 
@@ -85,6 +87,39 @@ func (r *Registry) getGuard() GuardFunc {
 	return r.guard
 }
 
+// SetBreaker 启用按工具的熔断器并清空已有的熔断状态；FailureThreshold <= 0 时关闭熔断。
+func (r *Registry) SetBreaker(cfg BreakerConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cfg.FailureThreshold <= 0 {
+		r.breakers = nil
+		return
+	}
+	r.breakers = newBreakerSet(cfg)
+}
+
+func (r *Registry) getBreakers() *breakerSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.breakers
+}
+
+// RecordOutcome 将一次工具调用的结果与错误反馈给熔断器；由调用方在执行结束后调用。
+func (r *Registry) RecordOutcome(name string, outcome Outcome, err error) {
+	if breakers := r.getBreakers(); breakers != nil {
+		breakers.record(name, outcome, err)
+	}
+}
+
+// BreakerStatuses 返回有失败记录的工具熔断状态；未启用熔断时返回空列表。
+func (r *Registry) BreakerStatuses() []BreakerStatus {
+	breakers := r.getBreakers()
+	if breakers == nil {
+		return []BreakerStatus{}
+	}
+	return breakers.statuses()
+}
+
 // GetToolInfos 返回所有已注册工具的元数据（Schema），通常用于 LLM 的工具绑定。
 func (r *Registry) GetToolInfos(ctx context.Context) ([]*schema.ToolInfo, error) {
 	r.mu.RLock()
@@ -123,17 +158,33 @@ func (r *Registry) GetToolInfos(ctx context.Context) ([]*schema.ToolInfo, error)
 	return infos, nil
 }
 
-// Execute 根据名称运行指定的工具。在执行前会自动触发守卫函数进行检查。
+// Execute 根据名称运行指定的工具。在执行前会校验参数（失败时返回 *ArgumentError）、检查熔断状态
+// （熔断中返回 *UnavailableError）并触发守卫函数进行检查。
 func (r *Registry) Execute(ctx context.Context, name string, argsJSON string) (string, error) {
+	res, err := r.ExecuteResult(ctx, name, argsJSON)
+	return res.Content, err
+}
+
+// ExecuteResult 与 Execute 相同，但对结构化工具（见 AsStructured）额外返回校验过的 JSON 数据。
+// 结构化工具的输出不是合法 JSON 时按普通文本返回。
+func (r *Registry) ExecuteResult(ctx context.Context, name string, argsJSON string) (Result, error) {
 	t, ok := r.Get(name)
 	if !ok {
-		return "", fmt.Errorf("tool not found: %s", name)
+		return Result{}, fmt.Errorf("tool not found: %s", name)
+	}
+	if err := validateArguments(ctx, name, t, argsJSON); err != nil {
+		return Result{}, err
+	}
+	if breakers := r.getBreakers(); breakers != nil {
+		if err := breakers.allow(name); err != nil {
+			return Result{}, err
+		}
 	}
 
 	if guard := r.getGuard(); guard != nil {
 		result, err := guard(ctx, name, argsJSON)
 		if err != nil {
-			return "", err
+			return Result{}, err
 		}
 
 		switch result.Action {
@@ -144,19 +195,34 @@ func (r *Registry) Execute(ctx context.Context, name string, argsJSON string) (s
 			if msg == "" {
 				msg = "tool execution denied"
 			}
-			return "", fmt.Errorf("tool execution denied: %s", msg)
+			return Result{}, &DeniedError{Message: msg}
 		case GuardRequireApproval:
 			msg := strings.TrimSpace(result.Message)
 			if msg == "" {
-				return "pending approval", nil
+				return Result{Content: "pending approval", PendingApproval: true}, nil
 			}
-			return "pending approval: " + msg, nil
+			return Result{Content: "pending approval: " + msg, PendingApproval: true}, nil
 		default:
-			return "", fmt.Errorf("unknown guard action: %s", result.Action)
+			return Result{}, fmt.Errorf("unknown guard action: %s", result.Action)
 		}
 	}
 
-	return t.InvokableRun(ctx, argsJSON)
+	out, err := t.InvokableRun(ctx, argsJSON)
+	if isUnmarshalFailure(err) {
+		return Result{Content: out}, &ArgumentError{
+			Tool:     name,
+			Reason:   "could not decode arguments: " + err.Error(),
+			Expected: toolParamSchema(ctx, t).describe(),
+		}
+	}
+	if err != nil {
+		return Result{Content: out}, err
+	}
+	res := Result{Content: out}
+	if isStructured(t) && json.Valid([]byte(out)) {
+		res.Data = json.RawMessage(out)
+	}
+	return res, nil
 }
 
 // Names 返回所有已注册工具的名称列表。
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
 	"github.com/cloudwego/eino/schema"
 )
 
@@ -99,3 +100,191 @@ func TestExecTool(t *testing.T) {
 		t.Errorf("expected result to contain 'hello', got: %s", result)
 	}
 }
+
+type namedOutputTool struct {
+	name   string
+	output string
+}
+
+func (m *namedOutputTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: m.name, Desc: "returns a fixed output"}, nil
+}
+
+func (m *namedOutputTool) InvokableRun(ctx context.Context, args string, opts ...tool.Option) (string, error) {
+	return m.output, nil
+}
+
+func TestRegistry_ExecuteResultReturnsStructuredData(t *testing.T) {
+	reg := NewRegistry()
+	for _, tl := range []tool.InvokableTool{
+		AsStructured(&namedOutputTool{name: "structured", output: `{"results":[{"title":"Go"}]}`}),
+		AsStructured(&namedOutputTool{name: "broken", output: "not json"}),
+		&namedOutputTool{name: "plain", output: `{"looks":"like json"}`},
+	} {
+		if err := reg.Register(tl); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	}
+
+	res, err := reg.ExecuteResult(context.Background(), "structured", `{}`)
+	if err != nil {
+		t.Fatalf("ExecuteResult: %v", err)
+	}
+	if res.Content != `{"results":[{"title":"Go"}]}` || string(res.Data) != res.Content {
+		t.Fatalf("unexpected structured result: %+v", res)
+	}
+
+	for _, name := range []string{"broken", "plain"} {
+		res, err := reg.ExecuteResult(context.Background(), name, `{}`)
+		if err != nil {
+			t.Fatalf("ExecuteResult %s: %v", name, err)
+		}
+		if res.Data != nil || res.Content == "" {
+			t.Fatalf("%s: expected text-only result, got %+v", name, res)
+		}
+	}
+
+	// Execute 仍返回与之前相同的文本。
+	if out, err := reg.Execute(context.Background(), "structured", `{}`); err != nil || out != `{"results":[{"title":"Go"}]}` {
+		t.Fatalf("Execute = %q, %v", out, err)
+	}
+}
+
+func TestRegistry_ExecuteResultGuardShortCircuitHasNoData(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(AsStructured(&namedOutputTool{name: "structured", output: `{}`})); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	reg.SetGuard(func(ctx context.Context, name, argsJSON string) (GuardResult, error) {
+		return GuardResult{Action: GuardRequireApproval, Message: "needs review"}, nil
+	})
+
+	res, err := reg.ExecuteResult(context.Background(), "structured", `{}`)
+	if err != nil {
+		t.Fatalf("ExecuteResult: %v", err)
+	}
+	if res.Content != "pending approval: needs review" || res.Data != nil || !res.PendingApproval {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestStructuredBuiltinTools(t *testing.T) {
+	search, err := NewWebSearchTool("", 5)
+	if err != nil {
+		t.Fatalf("NewWebSearchTool: %v", err)
+	}
+	if !isStructured(search) {
+		t.Fatal("web_search should be a structured tool")
+	}
+	readFile, err := NewReadFileTool(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewReadFileTool: %v", err)
+	}
+	if isStructured(readFile) {
+		t.Fatal("read_file should stay a plain text tool")
+	}
+}
+
+type argsEchoInput struct {
+	Path  string `json:"path" jsonschema:"required"`
+	Limit int    `json:"limit"`
+}
+
+func newArgsEchoTool(t *testing.T) tool.InvokableTool {
+	t.Helper()
+	tl, err := utils.InferTool("args_echo", "Echo the path argument", func(ctx context.Context, in argsEchoInput) (string, error) {
+		return in.Path, nil
+	})
+	if err != nil {
+		t.Fatalf("InferTool error: %v", err)
+	}
+	return tl
+}
+
+func TestRegistry_ExecuteRejectsInvalidArguments(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(newArgsEchoTool(t)); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{name: "truncated", args: `{"path": "notes.md"`, want: "malformed JSON"},
+		{name: "not json", args: `path=notes.md`, want: "malformed JSON"},
+		{name: "not an object", args: `["notes.md"]`, want: "must be a JSON object"},
+		{name: "missing required", args: `{"limit": 3}`, want: `missing required field "path"`},
+		{name: "wrong type", args: `{"path": "notes.md", "limit": "3"}`, want: `field "limit" must be of type integer`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reg.Execute(context.Background(), "args_echo", tt.args)
+			if !IsArgumentError(err) {
+				t.Fatalf("expected argument error, got %v", err)
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, tt.want) {
+				t.Fatalf("expected %q in error, got %q", tt.want, msg)
+			}
+			if !strings.Contains(msg, "path (string, required)") {
+				t.Fatalf("expected error to list the expected fields, got %q", msg)
+			}
+		})
+	}
+
+	out, err := reg.Execute(context.Background(), "args_echo", `{"path": "notes.md", "limit": 3}`)
+	if err != nil || out != "notes.md" {
+		t.Fatalf("expected valid arguments to run the tool, got %q, %v", out, err)
+	}
+}
+
+func TestRegistry_ExecuteAllowsOmittedOptionalFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("line1\nline2"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	reg := NewRegistry()
+	execTool, err := NewExecTool(60, false, "")
+	if err != nil {
+		t.Fatalf("NewExecTool error: %v", err)
+	}
+	readFile, err := NewReadFileTool("")
+	if err != nil {
+		t.Fatalf("NewReadFileTool error: %v", err)
+	}
+	for _, tl := range []tool.InvokableTool{execTool, readFile} {
+		if err := reg.Register(tl); err != nil {
+			t.Fatalf("Register error: %v", err)
+		}
+	}
+
+	if out, err := reg.Execute(context.Background(), "exec", `{"command": "echo hello"}`); err != nil || !strings.Contains(out, "hello") {
+		t.Fatalf("expected exec without working_dir to run, got %q, %v", out, err)
+	}
+	if out, err := reg.Execute(context.Background(), "read_file", fmt.Sprintf(`{"path": %q}`, testFile)); err != nil || !strings.Contains(out, "line1") {
+		t.Fatalf("expected read_file without offset/limit to run, got %q, %v", out, err)
+	}
+}
+
+func TestRegistry_ExecuteSkipsGuardForInvalidArguments(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(newArgsEchoTool(t)); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	guarded := false
+	reg.SetGuard(func(ctx context.Context, name, argsJSON string) (GuardResult, error) {
+		guarded = true
+		return GuardResult{Action: GuardRequireApproval}, nil
+	})
+
+	if _, err := reg.Execute(context.Background(), "args_echo", `{"path":`); !IsArgumentError(err) {
+		t.Fatalf("expected argument error, got %v", err)
+	}
+	if guarded {
+		t.Fatal("expected invalid arguments to be rejected before the guard runs")
+	}
+}
@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+const (
+	// defaultSearchMaxResults 是未指定 max_results 时返回的最大匹配数。
+	defaultSearchMaxResults = 100
+	// maxSearchMaxResults 是 max_results 的上限。
+	maxSearchMaxResults = 500
+	// maxSearchOutputBytes 限制所有匹配片段的总字节数，避免结果撑爆上下文。
+	maxSearchOutputBytes = 64 * 1024
+	// maxSearchSnippetChars 限制单个匹配行片段的长度（按字符计）。
+	maxSearchSnippetChars = 200
+	// maxSearchFileSize 超过该大小的文件不参与搜索。
+	maxSearchFileSize = 1 << 20
+)
+
+// searchSkipDirs 是搜索时跳过的目录名。
+var searchSkipDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+}
+
+// errSearchLimit 表示已达到匹配数或输出大小上限，用于提前结束遍历。
+var errSearchLimit = errors.New("search limit reached")
+
+// SearchFilesInput 定义了 search_files 工具的输入参数。
+type SearchFilesInput struct {
+	Pattern         string `json:"pattern" jsonschema:"required,description=Regular expression (RE2 syntax) to search for; set literal=true to match it as plain text"`
+	Path            string `json:"path,omitempty" jsonschema:"description=File or directory to search, absolute or relative to the workspace (default: workspace root)"`
+	Glob            string `json:"glob,omitempty" jsonschema:"description=Only search files whose name or relative path matches this glob (e.g. *.go)"`
+	Literal         bool   `json:"literal,omitempty" jsonschema:"description=Treat pattern as a literal string instead of a regular expression"`
+	CaseInsensitive bool   `json:"case_insensitive,omitempty" jsonschema:"description=Match case-insensitively"`
+	MaxResults      int    `json:"max_results,omitempty" jsonschema:"description=Maximum number of matches to return (default 100; max 500)"`
+}
+
+// SearchMatch 是一条匹配结果。
+type SearchMatch struct {
+	Path string `json:"path"` // 文件路径（相对搜索根目录）
+	Line int    `json:"line"` // 行号（从 1 开始）
+	Text string `json:"text"` // 匹配行内容（过长时截断）
+}
+
+// SearchFilesOutput 定义了 search_files 工具的执行结果。
+type SearchFilesOutput struct {
+	Root         string        `json:"root"`                // 搜索根目录
+	Matches      []SearchMatch `json:"matches"`             // 匹配结果
+	FilesScanned int           `json:"files_scanned"`       // 实际扫描的文件数
+	Truncated    bool          `json:"truncated,omitempty"` // 是否因数量或大小上限截断
+}
+
+type searchFilesToolImpl struct {
+	workspacePath string
+}
+
+func (t *searchFilesToolImpl) execute(ctx context.Context, input *SearchFilesInput) (*SearchFilesOutput, error) {
+	if strings.TrimSpace(input.Pattern) == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	if input.MaxResults < 0 {
+		return nil, fmt.Errorf("max_results must be >= 0")
+	}
+	maxResults := input.MaxResults
+	if maxResults == 0 {
+		maxResults = defaultSearchMaxResults
+	}
+	maxResults = min(maxResults, maxSearchMaxResults)
+
+	expr := input.Pattern
+	if input.Literal {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if input.CaseInsensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	glob := strings.TrimSpace(input.Glob)
+	if glob != "" {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob: %w", err)
+		}
+	}
+
+	root := strings.TrimSpace(input.Path)
+	if root == "" {
+		root = t.workspacePath
+	}
+	if root == "" {
+		return nil, fmt.Errorf("path is required when no workspace is configured")
+	}
+	root, err = resolveToolPath(root, t.workspacePath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &SearchFilesOutput{Root: root, Matches: []SearchMatch{}}
+	outputBytes := 0
+	searchFile := func(path, rel string) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		out.FilesScanned++
+		matches, err := searchFileLines(path, re)
+		if err != nil {
+			return nil
+		}
+		for _, m := range matches {
+			m.Path = rel
+			size := len(m.Path) + len(m.Text) + 16
+			if len(out.Matches) >= maxResults || outputBytes+size > maxSearchOutputBytes {
+				out.Truncated = true
+				return errSearchLimit
+			}
+			outputBytes += size
+			out.Matches = append(out.Matches, m)
+		}
+		return nil
+	}
+
+	if !info.IsDir() {
+		err = searchFile(root, filepath.Base(root))
+	} else {
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				if path == root {
+					return walkErr
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && searchSkipDirs[d.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// 符号链接可能指向工作区外部或目录，仅搜索仍在工作区内的普通文件。
+			if d.Type()&fs.ModeSymlink != 0 {
+				if _, err := resolveToolPath(path, t.workspacePath); err != nil {
+					return nil
+				}
+				if st, err := os.Stat(path); err != nil || !st.Mode().IsRegular() {
+					return nil
+				}
+			} else if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				rel = path
+			}
+			rel = filepath.ToSlash(rel)
+			if glob != "" && !matchSearchGlob(glob, rel) {
+				return nil
+			}
+			return searchFile(path, rel)
+		})
+	}
+	if err != nil && !errors.Is(err, errSearchLimit) {
+		return nil, err
+	}
+	return out, nil
+}
+
+// matchSearchGlob 判断文件的相对路径或文件名是否匹配 glob。
+func matchSearchGlob(glob, rel string) bool {
+	if ok, _ := filepath.Match(glob, rel); ok {
+		return true
+	}
+	ok, _ := filepath.Match(glob, filepath.Base(rel))
+	return ok
+}
+
+// searchFileLines 返回文件中匹配 re 的行；过大的文件与二进制文件会被跳过。
+func searchFileLines(path string, re *regexp.Regexp) ([]SearchMatch, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() > maxSearchFileSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	var matches []SearchMatch
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSearchFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if !re.MatchString(text) {
+			continue
+		}
+		matches = append(matches, SearchMatch{Line: line, Text: truncateSnippet(strings.TrimRight(text, "\r"))})
+	}
+	return matches, scanner.Err()
+}
+
+func truncateSnippet(s string) string {
+	if utf8.RuneCountInString(s) <= maxSearchSnippetChars {
+		return s
+	}
+	return string([]rune(s)[:maxSearchSnippetChars]) + "..."
+}
+
+// NewSearchFilesTool 创建 search_files 工具实例，用于在工作区文件中按正则或字面量搜索内容。
+func NewSearchFilesTool(workspacePath string) (tool.InvokableTool, error) {
+	impl := &searchFilesToolImpl{workspacePath: workspacePath}
+	return inferStructuredTool("search_files",
+		"Search file contents under the workspace (or a given path) for a regular expression or literal string. Returns matching file paths with line numbers and line snippets; results are capped by max_results.",
+		impl.execute)
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runSearchFiles(t *testing.T, workspace, args string) SearchFilesOutput {
+	t.Helper()
+	st, err := NewSearchFilesTool(workspace)
+	if err != nil {
+		t.Fatalf("NewSearchFilesTool error: %v", err)
+	}
+	result, err := st.InvokableRun(context.Background(), args)
+	if err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	var out SearchFilesOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("unmarshal result: %v (%s)", err, result)
+	}
+	return out
+}
+
+func TestSearchFiles_FindsMatchesWithLineNumbers(t *testing.T) {
+	ws := t.TempDir()
+	os.MkdirAll(filepath.Join(ws, "pkg"), 0755)
+	os.MkdirAll(filepath.Join(ws, ".git"), 0755)
+	os.WriteFile(filepath.Join(ws, "main.go"), []byte("package main\n\nfunc main() {\n\tTODO()\n}\n"), 0644)
+	os.WriteFile(filepath.Join(ws, "pkg", "util.go"), []byte("package pkg\n// todo: later\n"), 0644)
+	os.WriteFile(filepath.Join(ws, "notes.txt"), []byte("TODO in notes\n"), 0644)
+	os.WriteFile(filepath.Join(ws, ".git", "HEAD"), []byte("TODO\n"), 0644)
+	os.WriteFile(filepath.Join(ws, "blob.bin"), []byte("TODO\x00\x01"), 0644)
+
+	out := runSearchFiles(t, ws, `{"pattern":"TODO","glob":"*.go","case_insensitive":true}`)
+	if len(out.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", out.Matches)
+	}
+	if out.Matches[0].Path != "main.go" || out.Matches[0].Line != 4 || out.Matches[0].Text != "\tTODO()" {
+		t.Fatalf("unexpected first match: %+v", out.Matches[0])
+	}
+	if out.Matches[1].Path != "pkg/util.go" || out.Matches[1].Line != 2 {
+		t.Fatalf("unexpected second match: %+v", out.Matches[1])
+	}
+
+	out = runSearchFiles(t, ws, `{"pattern":"TODO"}`)
+	for _, m := range out.Matches {
+		if strings.HasPrefix(m.Path, ".git") || m.Path == "blob.bin" {
+			t.Fatalf("expected .git and binary files to be skipped, got %+v", m)
+		}
+	}
+	if len(out.Matches) != 2 {
+		t.Fatalf("expected case-sensitive matches in main.go and notes.txt, got %+v", out.Matches)
+	}
+}
+
+func TestSearchFiles_LiteralPattern(t *testing.T) {
+	ws := t.TempDir()
+	os.WriteFile(filepath.Join(ws, "a.txt"), []byte("cost is $1.00\ncost is 1x00\n"), 0644)
+
+	out := runSearchFiles(t, ws, `{"pattern":"$1.00","literal":true}`)
+	if len(out.Matches) != 1 || out.Matches[0].Line != 1 {
+		t.Fatalf("expected one literal match on line 1, got %+v", out.Matches)
+	}
+}
+
+func TestSearchFiles_CapsResults(t *testing.T) {
+	ws := t.TempDir()
+	var b strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, "match %d\n", i)
+	}
+	os.WriteFile(filepath.Join(ws, "many.txt"), []byte(b.String()), 0644)
+
+	out := runSearchFiles(t, ws, `{"pattern":"match","max_results":5}`)
+	if len(out.Matches) != 5 || !out.Truncated {
+		t.Fatalf("expected 5 truncated matches, got %d truncated=%v", len(out.Matches), out.Truncated)
+	}
+}
+
+func TestSearchFiles_PathTraversal(t *testing.T) {
+	ws := t.TempDir()
+	st, err := NewSearchFilesTool(ws)
+	if err != nil {
+		t.Fatalf("NewSearchFilesTool error: %v", err)
+	}
+	args := fmt.Sprintf(`{"pattern":"x","path":%q}`, filepath.Join(ws, "..", ".."))
+	if _, err := st.InvokableRun(context.Background(), args); err == nil || !strings.Contains(err.Error(), "outside workspace") {
+		t.Fatalf("expected outside workspace error, got %v", err)
+	}
+}
+
+func TestSearchFiles_SkipsSymlinkEscape(t *testing.T) {
+	ws := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	os.WriteFile(secret, []byte("password=hunter2\n"), 0644)
+	if err := os.Symlink(secret, filepath.Join(ws, "link.txt")); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	out := runSearchFiles(t, ws, `{"pattern":"password"}`)
+	if len(out.Matches) != 0 {
+		t.Fatalf("expected symlink escaping workspace to be skipped, got %+v", out.Matches)
+	}
+}
+
+func TestSearchFiles_IsStructured(t *testing.T) {
+	st, err := NewSearchFilesTool(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSearchFilesTool error: %v", err)
+	}
+	if !isStructured(st) {
+		t.Fatal("expected search_files to declare structured output")
+	}
+}
@@ -2,12 +2,16 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/components/tool/utils"
@@ -16,7 +20,7 @@ import (
 // ExecInput parameters for exec tool
 type ExecInput struct {
 	Command    string `json:"command" jsonschema:"required,description=Shell command to execute"`
-	WorkingDir string `json:"working_dir" jsonschema:"description=Working directory for the command"`
+	WorkingDir string `json:"working_dir,omitempty" jsonschema:"description=Working directory for the command"`
 }
 
 // ExecOutput result of exec tool
@@ -56,10 +60,31 @@ func isDangerous(cmd string) (bool, string) {
 	return false, ""
 }
 
+// CommandNotAllowedError 表示命令未通过 exec 工具的允许/禁止命令列表检查。
+type CommandNotAllowedError struct {
+	Command string // 被拦截的命令名（argv[0]）或无法校验的 shell 结构
+	Reason  string // 拦截原因
+}
+
+func (e *CommandNotAllowedError) Error() string {
+	return fmt.Sprintf("command %q is not allowed: %s", e.Command, e.Reason)
+}
+
+// ExecToolOptions exec 工具的完整配置。
+type ExecToolOptions struct {
+	TimeoutSeconds      int
+	RestrictToWorkspace bool
+	WorkspaceDir        string
+	AllowedCommands     []string // 非空时仅允许列出的命令（按 argv[0] 的文件名匹配）
+	BlockedCommands     []string // 始终禁止的命令，优先于 AllowedCommands
+}
+
 type execToolImpl struct {
 	timeout             time.Duration
 	restrictToWorkspace bool
 	workspaceDir        string
+	allowedCommands     map[string]struct{}
+	blockedCommands     map[string]struct{}
 }
 
 func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutput, error) {
@@ -69,17 +94,22 @@ func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutp
 			ExitCode: 1,
 		}, nil
 	}
+	if err := e.checkCommandLists(input.Command); err != nil {
+		return nil, err
+	}
 
 	// Determine working directory and enforce workspace restriction
 	workDir := input.WorkingDir
 	if e.restrictToWorkspace && e.workspaceDir != "" {
 		if workDir != "" {
-			if err := validatePath(workDir, e.workspaceDir); err != nil {
+			resolved, err := ResolveWithinWorkspace(e.workspaceDir, workDir)
+			if err != nil {
 				return &ExecOutput{
 					Stderr:   fmt.Sprintf("Working directory rejected: %s", err.Error()),
 					ExitCode: 1,
 				}, nil
 			}
+			workDir = resolved
 		} else {
 			workDir = e.workspaceDir
 		}
@@ -87,25 +117,43 @@ func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutp
 		workDir = e.workspaceDir
 	}
 
+	return e.run(ctx, input.Command, workDir, nil, "")
+}
+
+// run 在 workDir 下以系统 shell 执行命令；env 追加到当前进程环境变量之后，stdin 非空时作为标准输入。
+func (e *execToolImpl) run(ctx context.Context, command, workDir string, env []string, stdin string) (*ExecOutput, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, e.timeout)
 	defer cancel()
 
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
-		cmd = exec.CommandContext(timeoutCtx, "cmd", "/C", input.Command)
+		cmd = exec.CommandContext(timeoutCtx, "cmd", "/C", command)
 	} else {
-		cmd = exec.CommandContext(timeoutCtx, "sh", "-c", input.Command)
+		cmd = exec.CommandContext(timeoutCtx, "sh", "-c", command)
 	}
 
 	if workDir != "" {
 		cmd.Dir = workDir
 	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
 
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	err := cmd.Run()
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return &ExecOutput{
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			ExitCode: -1,
+		}, fmt.Errorf("command timed out after %s: %w", e.timeout, context.DeadlineExceeded)
+	}
 	exitCode := 0
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
@@ -125,12 +173,239 @@ func (e *execToolImpl) execute(ctx context.Context, input *ExecInput) (*ExecOutp
 	}, nil
 }
 
+// checkCommandLists 校验命令中每个子命令的 argv[0] 是否满足允许/禁止列表；两个列表都为空时不做限制。
+func (e *execToolImpl) checkCommandLists(command string) error {
+	if len(e.allowedCommands) == 0 && len(e.blockedCommands) == 0 {
+		return nil
+	}
+
+	names, err := parseCommandNames(command)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		key := normalizeCommandName(name)
+		if _, blocked := e.blockedCommands[key]; blocked {
+			return &CommandNotAllowedError{Command: name, Reason: "listed in tools.exec.blocked_commands"}
+		}
+		if _, wrapper := commandWrappers[key]; wrapper && len(e.blockedCommands) > 0 {
+			return &CommandNotAllowedError{Command: name, Reason: "runs another command that cannot be checked against tools.exec.blocked_commands"}
+		}
+		if len(e.allowedCommands) > 0 {
+			if _, allowed := e.allowedCommands[key]; !allowed {
+				return &CommandNotAllowedError{Command: name, Reason: "not listed in tools.exec.allowed_commands"}
+			}
+		}
+	}
+	return nil
+}
+
+// shellReservedWords 是后面紧跟命令的 shell 保留字，出现在段首时其后的单词才是真正的命令名。
+var shellReservedWords = map[string]struct{}{
+	"!": {}, "{": {}, "if": {}, "then": {}, "else": {}, "elif": {}, "while": {}, "until": {}, "do": {}, "time": {},
+}
+
+// shellClosingWords 是结束复合命令的保留字，本身不执行命令。
+var shellClosingWords = map[string]struct{}{
+	"}": {}, "fi": {}, "done": {}, "esac": {},
+}
+
+// commandWrappers 是以参数形式执行另一个命令的程序；其选项各不相同，无法可靠找出被执行的命令，
+// 因此设置了禁止列表时直接拒绝。
+var commandWrappers = map[string]struct{}{
+	"command": {}, "exec": {}, "builtin": {}, "nohup": {}, "nice": {}, "env": {}, "xargs": {},
+}
+
+// parseCommandNames 按未加引号的 ; & | 换行和括号切分 shell 命令，返回每段的 argv[0]（跳过前置的 VAR=value 赋值）。
+// 段首未加引号的保留字（if、then、do、!、{、time 等）不算命令，其后的单词才是命令名；fi、done、} 等结束词被忽略。
+// 引号与转义按 shell 的方式去除（POSIX 为反斜杠，Windows cmd 为 ^），使 r\m、"r"m 之类的写法得到真实命令名。
+// 命令替换（$(...)、`...`、<(...)）中的命令无法可靠校验，直接拒绝；命令名中含变量展开（$x、$'...'、%x%）
+// 或通配符时，实际执行的命令同样无法静态确定，也直接拒绝。
+func parseCommandNames(command string) ([]string, error) {
+	var names []string
+	var word strings.Builder
+	var quote rune
+	atSegmentStart := true
+	inWord := false
+	quoted := false    // 当前单词含有引号或转义，不可能是保留字
+	afterTime := false // 段首刚出现 time，其后的 -p 等选项不是命令名
+	dynamic := false   // 当前单词含有变量展开或通配符
+	bracket := false   // 当前单词含有未加引号的 [，单独的 [ 与 [[ 是 test 命令而非通配
+	escape := '\\'
+	if runtime.GOOS == "windows" {
+		escape = '^'
+	}
+
+	flushWord := func() error {
+		if !inWord {
+			return nil
+		}
+		w := word.String()
+		wasDynamic := dynamic || bracket && w != "[" && w != "[["
+		wasQuoted := quoted
+		word.Reset()
+		inWord = false
+		quoted = false
+		dynamic = false
+		bracket = false
+		if !atSegmentStart {
+			return nil
+		}
+		if isEnvAssignment(w) {
+			return nil
+		}
+		if !wasQuoted {
+			if _, ok := shellReservedWords[w]; ok {
+				afterTime = w == "time"
+				return nil
+			}
+			if _, ok := shellClosingWords[w]; ok {
+				return nil
+			}
+			if afterTime && strings.HasPrefix(w, "-") {
+				return nil
+			}
+		}
+		afterTime = false
+		if wasDynamic {
+			return &CommandNotAllowedError{Command: w, Reason: "command names that use shell expansion cannot be checked against the command lists"}
+		}
+		names = append(names, w)
+		atSegmentStart = false
+		return nil
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if quote != '\'' && r == '`' {
+			return nil, &CommandNotAllowedError{Command: "`", Reason: "command substitution cannot be checked against the command lists"}
+		}
+		if quote != '\'' && r == '$' && next == '(' {
+			return nil, &CommandNotAllowedError{Command: "$(", Reason: "command substitution cannot be checked against the command lists"}
+		}
+		if quote == 0 && (r == '<' || r == '>') && next == '(' {
+			return nil, &CommandNotAllowedError{Command: string(r) + "(", Reason: "process substitution cannot be checked against the command lists"}
+		}
+
+		switch {
+		case quote == '\'':
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == quote:
+				quote = 0
+			case r == escape && escape == '\\' && strings.ContainsRune("$`\"\\\n", next):
+				// 双引号内的反斜杠只转义 $ ` " \ 与换行，其余情况保持原样
+				if next != '\n' {
+					word.WriteRune(next)
+				}
+				i++
+			default:
+				if isShellExpansion(r, next) {
+					dynamic = true
+				}
+				word.WriteRune(r)
+			}
+		case r == escape:
+			// 未加引号的转义字符使下一个字符失去特殊含义；转义换行表示续行
+			if next != 0 && next != '\n' {
+				word.WriteRune(next)
+				inWord = true
+				quoted = true
+			}
+			i++
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+			quoted = true
+		case r == ';' || r == '&' || r == '|' || r == '\n' || r == '(' || r == ')':
+			if err := flushWord(); err != nil {
+				return nil, err
+			}
+			atSegmentStart = true
+			afterTime = false
+		case r == ' ' || r == '\t' || r == '\r':
+			if err := flushWord(); err != nil {
+				return nil, err
+			}
+		default:
+			switch {
+			case isShellExpansion(r, next) || r == '*' || r == '?':
+				dynamic = true
+			case r == '[':
+				bracket = true
+			}
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if err := flushWord(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// isShellExpansion 报告 r 是否开始一次变量展开：POSIX shell 的 $name、${...}、$'...'，或 Windows cmd 的 %name%。
+func isShellExpansion(r, next rune) bool {
+	if runtime.GOOS == "windows" {
+		return r == '%'
+	}
+	return r == '$' && next != 0 && !unicode.IsSpace(next)
+}
+
+var envAssignmentPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+func isEnvAssignment(word string) bool {
+	return envAssignmentPattern.MatchString(word)
+}
+
+// normalizeCommandName 取命令路径的文件名部分；Windows 下忽略大小写与 .exe 后缀。
+func normalizeCommandName(name string) string {
+	base := filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	if runtime.GOOS == "windows" {
+		base = strings.TrimSuffix(strings.ToLower(base), ".exe")
+	}
+	return base
+}
+
+func commandNameSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		set[normalizeCommandName(name)] = struct{}{}
+	}
+	return set
+}
+
 // NewExecTool creates the exec tool
 func NewExecTool(timeoutSec int, restrictToWorkspace bool, workspaceDir string) (tool.InvokableTool, error) {
+	return NewExecToolWithOptions(ExecToolOptions{
+		TimeoutSeconds:      timeoutSec,
+		RestrictToWorkspace: restrictToWorkspace,
+		WorkspaceDir:        workspaceDir,
+	})
+}
+
+// NewExecToolWithOptions 使用完整配置创建 exec 工具，支持允许/禁止命令列表。
+func NewExecToolWithOptions(opts ExecToolOptions) (tool.InvokableTool, error) {
 	impl := &execToolImpl{
-		timeout:             time.Duration(timeoutSec) * time.Second,
-		restrictToWorkspace: restrictToWorkspace,
-		workspaceDir:        workspaceDir,
+		timeout:             time.Duration(opts.TimeoutSeconds) * time.Second,
+		restrictToWorkspace: opts.RestrictToWorkspace,
+		workspaceDir:        opts.WorkspaceDir,
+		allowedCommands:     commandNameSet(opts.AllowedCommands),
+		blockedCommands:     commandNameSet(opts.BlockedCommands),
 	}
 	return utils.InferTool("exec", "Execute a shell command", impl.execute)
 }
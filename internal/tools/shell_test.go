@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -154,3 +156,227 @@ func TestExecTool_RestrictToWorkspace(t *testing.T) {
 		t.Errorf("expected rejection message in stderr, got: %s", out.Stderr)
 	}
 }
+
+func TestParseCommandNames(t *testing.T) {
+	cases := []struct {
+		command string
+		want    []string
+	}{
+		{"echo hi", []string{"echo"}},
+		{"FOO=1 BAR=2 /usr/bin/git status", []string{"/usr/bin/git"}},
+		{"ls -la && cat a.txt | grep x; echo done", []string{"ls", "cat", "grep", "echo"}},
+		{`echo "a; rm b" && (cd sub; make)`, []string{"echo", "cd", "make"}},
+		{`'my tool' --flag`, []string{"my tool"}},
+	}
+	for _, tc := range cases {
+		got, err := parseCommandNames(tc.command)
+		if err != nil {
+			t.Fatalf("parseCommandNames(%q) error: %v", tc.command, err)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("parseCommandNames(%q) = %v, want %v", tc.command, got, tc.want)
+		}
+	}
+
+	for _, command := range []string{"echo $(whoami)", "echo `id`", "diff <(ls a) <(ls b)"} {
+		if _, err := parseCommandNames(command); err == nil {
+			t.Errorf("expected substitution in %q to be rejected", command)
+		}
+	}
+	if _, err := parseCommandNames(`echo '$(not substituted)'`); err != nil {
+		t.Errorf("expected single-quoted text to be accepted, got %v", err)
+	}
+}
+
+func TestParseCommandNames_ShellEscapesAndExpansion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell quoting")
+	}
+	cases := []struct {
+		command string
+		want    []string
+	}{
+		{`r\m -rf /tmp/x`, []string{"rm"}},
+		{`"r\m" x; \rm y`, []string{`r\m`, "rm"}},
+		{"ec\\\nho hi", []string{"echo"}},
+		{`"r"'m' x && echo "$HOME" *.txt`, []string{"rm", "echo"}},
+		{`[ -f a ] && [[ -d b ]]`, []string{"[", "[["}},
+	}
+	for _, tc := range cases {
+		got, err := parseCommandNames(tc.command)
+		if err != nil {
+			t.Fatalf("parseCommandNames(%q) error: %v", tc.command, err)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("parseCommandNames(%q) = %v, want %v", tc.command, got, tc.want)
+		}
+	}
+
+	for _, command := range []string{`$'rm' -rf x`, `$"rm" x`, `r${x}m x`, `echo ok; $CMD x`, `/bin/r? x`, `/bin/r* x`, `[r]m x`} {
+		var notAllowed *CommandNotAllowedError
+		if _, err := parseCommandNames(command); !errors.As(err, &notAllowed) {
+			t.Errorf("expected expansion in command name %q to be rejected, got %v", command, err)
+		}
+	}
+}
+
+func TestParseCommandNames_ReservedWords(t *testing.T) {
+	cases := []struct {
+		command string
+		want    []string
+	}{
+		{"! rm -rf x", []string{"rm"}},
+		{"if true; then rm -rf x; fi", []string{"true", "rm"}},
+		{"if false; then echo a; elif true; then echo b; else rm x; fi", []string{"false", "echo", "true", "echo", "rm"}},
+		{"while false; do rm x; done", []string{"false", "rm"}},
+		{"until true; do rm x; done", []string{"true", "rm"}},
+		{"{ rm -rf x; }", []string{"rm"}},
+		{"time rm x", []string{"rm"}},
+		{"time -p rm x", []string{"rm"}},
+		{"echo if then do; 'if' x", []string{"echo", "if"}},
+	}
+	for _, tc := range cases {
+		got, err := parseCommandNames(tc.command)
+		if err != nil {
+			t.Fatalf("parseCommandNames(%q) error: %v", tc.command, err)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("parseCommandNames(%q) = %v, want %v", tc.command, got, tc.want)
+		}
+	}
+}
+
+func TestExecTool_BlockedCommandsSeeThroughKeywordsAndWrappers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell syntax")
+	}
+	impl := &execToolImpl{blockedCommands: commandNameSet([]string{"rm"})}
+	for _, command := range []string{
+		"! rm -rf x",
+		"if true; then rm -rf x; fi",
+		"while false; do rm x; done",
+		"until true; do rm x; done",
+		"{ rm -rf x; }",
+		"time rm x",
+		"command rm x",
+		"exec rm x",
+		"builtin rm x",
+		"nohup rm x",
+		"nice -n 5 rm x",
+		"env FOO=1 rm x",
+		"echo x | xargs rm",
+	} {
+		var notAllowed *CommandNotAllowedError
+		if err := impl.checkCommandLists(command); !errors.As(err, &notAllowed) {
+			t.Errorf("expected %q to be rejected by blocked_commands, got %v", command, err)
+		}
+	}
+	if err := impl.checkCommandLists("if true; then echo ok; fi"); err != nil {
+		t.Errorf("expected compound command without blocked names to pass, got %v", err)
+	}
+
+	// 只有允许列表时，包装程序本身必须在列表中。
+	impl = &execToolImpl{allowedCommands: commandNameSet([]string{"echo", "true"})}
+	if err := impl.checkCommandLists("if true; then echo ok; fi"); err != nil {
+		t.Errorf("expected keywords not to need allowed_commands entries, got %v", err)
+	}
+	if err := impl.checkCommandLists("nohup echo ok"); err == nil {
+		t.Error("expected unlisted wrapper to be rejected by allowed_commands")
+	}
+}
+
+func TestExecTool_AllowedAndBlockedCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	tool, err := NewExecToolWithOptions(ExecToolOptions{
+		TimeoutSeconds:  10,
+		AllowedCommands: []string{"echo", "ls", "rm"},
+		BlockedCommands: []string{"rm"},
+	})
+	if err != nil {
+		t.Fatalf("NewExecToolWithOptions error: %v", err)
+	}
+	ctx := context.Background()
+
+	result, err := tool.InvokableRun(ctx, `{"command": "echo allowed && ls /"}`)
+	if err != nil {
+		t.Fatalf("expected allowed command to run, got %v", err)
+	}
+	if !strings.Contains(result, "allowed") {
+		t.Fatalf("unexpected output: %s", result)
+	}
+
+	for _, command := range []string{"echo ok; curl http://example.com", "rm -f notes.txt", "echo $(cat /etc/passwd)", `r\m -f notes.txt`, `$'rm' -f notes.txt`} {
+		_, err := tool.InvokableRun(ctx, fmt.Sprintf(`{"command": %q}`, command))
+		var notAllowed *CommandNotAllowedError
+		if !errors.As(err, &notAllowed) {
+			t.Fatalf("expected CommandNotAllowedError for %q, got %v", command, err)
+		}
+	}
+}
+
+func TestExecTool_EmptyCommandListsKeepCurrentBehavior(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+	tool, err := NewExecToolWithOptions(ExecToolOptions{TimeoutSeconds: 10})
+	if err != nil {
+		t.Fatalf("NewExecToolWithOptions error: %v", err)
+	}
+	if _, err := tool.InvokableRun(context.Background(), `{"command": "echo $(echo nested)"}`); err != nil {
+		t.Fatalf("expected unrestricted exec without command lists, got %v", err)
+	}
+}
+
+func TestExecTool_RestrictToWorkspaceResolvesRelativeWorkingDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses pwd")
+	}
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	tool, err := NewExecTool(60, true, tmpDir)
+	if err != nil {
+		t.Fatalf("NewExecTool error: %v", err)
+	}
+
+	result, err := tool.InvokableRun(context.Background(), `{"command": "pwd", "working_dir": "sub"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun error: %v", err)
+	}
+	var out ExecOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("failed to unmarshal result: %v, raw: %s", err, result)
+	}
+	if out.ExitCode != 0 || strings.TrimSpace(out.Stdout) != sub {
+		t.Fatalf("expected command to run in %q, got %+v", sub, out)
+	}
+
+	result, _ = tool.InvokableRun(context.Background(), `{"command": "pwd", "working_dir": "../.."}`)
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("failed to unmarshal result: %v, raw: %s", err, result)
+	}
+	if out.ExitCode == 0 || !strings.Contains(out.Stderr, "outside workspace") {
+		t.Fatalf("expected relative traversal to be rejected, got %+v", out)
+	}
+}
+
+func TestExecTool_TimeoutReportsDeadlineExceeded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX sleep")
+	}
+	tool, err := NewExecToolWithOptions(ExecToolOptions{TimeoutSeconds: 1})
+	if err != nil {
+		t.Fatalf("NewExecToolWithOptions error: %v", err)
+	}
+	_, err = tool.InvokableRun(context.Background(), `{"command": "sleep 3"}`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded error, got %v", err)
+	}
+	if got := Classify(Result{}, err); got != OutcomeTimeout {
+		t.Fatalf("expected timeout outcome, got %q", got)
+	}
+}
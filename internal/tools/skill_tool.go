@@ -0,0 +1,210 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/skills"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+)
+
+// skillToolMaxResponseBytes 是 http 处理器保留的最大响应字节数。
+const skillToolMaxResponseBytes = 256 * 1024
+
+// SkillToolOptions 配置技能声明工具的沙箱。
+type SkillToolOptions struct {
+	Exec         ExecToolOptions // exec 处理器沿用 exec 工具的危险命令拦截与允许/禁止命令列表
+	AllowPrivate bool            // http 处理器是否允许访问非公网地址，对应 tools.web.allow_private
+}
+
+// SkillHTTPOutput 是 http 处理器的返回结果。
+type SkillHTTPOutput struct {
+	Status    int    `json:"status"`
+	Body      string `json:"body"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+type skillTool struct {
+	spec   skills.ToolSpec
+	exec   *execToolImpl
+	client *http.Client
+}
+
+// NewSkillTool 将技能清单中声明的工具包装为可调用工具。
+func NewSkillTool(spec skills.ToolSpec, opts SkillToolOptions) (tool.InvokableTool, error) {
+	timeout := time.Duration(spec.TimeoutSeconds) * time.Second
+	switch {
+	case spec.Exec != nil:
+		return &skillTool{
+			spec: spec,
+			exec: &execToolImpl{
+				timeout:             timeout,
+				restrictToWorkspace: opts.Exec.RestrictToWorkspace,
+				workspaceDir:        opts.Exec.WorkspaceDir,
+				allowedCommands:     commandNameSet(opts.Exec.AllowedCommands),
+				blockedCommands:     commandNameSet(opts.Exec.BlockedCommands),
+			},
+		}, nil
+	case spec.HTTP != nil:
+		return &skillTool{
+			spec:   spec,
+			client: newFetchClient(timeout, 0, !opts.AllowPrivate),
+		}, nil
+	default:
+		return nil, fmt.Errorf("skill tool %q has no handler", spec.Name)
+	}
+}
+
+func (t *skillTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	_ = ctx
+	params := make(map[string]*schema.ParameterInfo, len(t.spec.Parameters))
+	for name, param := range t.spec.Parameters {
+		params[name] = &schema.ParameterInfo{
+			Type:     schema.DataType(param.Type),
+			Desc:     param.Description,
+			Required: param.Required,
+		}
+	}
+	return &schema.ToolInfo{
+		Name:        t.spec.Name,
+		Desc:        fmt.Sprintf("%s (from skill %s)", t.spec.Description, t.spec.Skill),
+		ParamsOneOf: schema.NewParamsOneOfByParams(params),
+	}, nil
+}
+
+func (t *skillTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	_ = opts
+
+	payload := strings.TrimSpace(argumentsInJSON)
+	if payload == "" {
+		payload = "{}"
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(payload), &args); err != nil {
+		return "", fmt.Errorf("parse skill tool arguments: %w", err)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	for name, param := range t.spec.Parameters {
+		value, exists := args[name]
+		if !exists || value == nil {
+			if param.Required {
+				return "", fmt.Errorf("skill tool argument %q is required", name)
+			}
+			continue
+		}
+		if !matchesParameterType(value, param.Type) {
+			return "", fmt.Errorf("skill tool argument %q must be %s", name, param.Type)
+		}
+	}
+
+	var (
+		out any
+		err error
+	)
+	if t.exec != nil {
+		out, err = t.runExec(ctx, args, payload)
+	} else {
+		out, err = t.runHTTP(ctx, args, payload)
+	}
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// runExec 在工作区（或未设置工作区时的当前目录）执行声明的命令。参数以 GOLEM_ARG_<NAME> 环境变量传入，
+// 不拼接进命令文本，避免 shell 注入；完整参数 JSON 写入标准输入。
+func (t *skillTool) runExec(ctx context.Context, args map[string]any, payload string) (*ExecOutput, error) {
+	command := t.spec.Exec.Command
+	if dangerous, pattern := isDangerous(command); dangerous {
+		return &ExecOutput{
+			Stderr:   fmt.Sprintf("Blocked dangerous command matching pattern: %s", pattern),
+			ExitCode: 1,
+		}, nil
+	}
+	if err := t.exec.checkCommandLists(command); err != nil {
+		return nil, err
+	}
+
+	env := []string{"GOLEM_SKILL_DIR=" + t.spec.SkillDir}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		if _, declared := t.spec.Parameters[name]; declared {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, "GOLEM_ARG_"+strings.ToUpper(name)+"="+skillArgString(args[name]))
+	}
+	return t.exec.run(ctx, command, t.exec.workspaceDir, env, payload)
+}
+
+// runHTTP 发送声明的 HTTP 请求；默认拒绝解析到非公网地址的目标，与 web_fetch 一致。
+func (t *skillTool) runHTTP(ctx context.Context, args map[string]any, payload string) (*SkillHTTPOutput, error) {
+	h := t.spec.HTTP
+	target, err := url.Parse(h.ExpandURL(args))
+	if err != nil {
+		return nil, fmt.Errorf("skill tool %q: invalid url: %w", t.spec.Name, err)
+	}
+
+	var body io.Reader
+	if h.Method != http.MethodGet && h.Method != http.MethodDelete {
+		body = bytes.NewReader([]byte(payload))
+	}
+	req, err := http.NewRequestWithContext(ctx, h.Method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+	for key, value := range h.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("skill tool %q request failed: %w", t.spec.Name, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, skillToolMaxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("skill tool %q: read response: %w", t.spec.Name, err)
+	}
+	out := &SkillHTTPOutput{Status: resp.StatusCode}
+	if len(raw) > skillToolMaxResponseBytes {
+		raw = raw[:skillToolMaxResponseBytes]
+		out.Truncated = true
+	}
+	out.Body = string(raw)
+	return out, nil
+}
+
+// skillArgString 将参数值转换为环境变量文本：字符串原样传入，其他类型使用 JSON 编码。
+func skillArgString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
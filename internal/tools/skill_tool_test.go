@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/MEKXH/golem/internal/skills"
+)
+
+func TestSkillTool_ExecPassesArgumentsThroughEnvAndStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	workspace := t.TempDir()
+	spec := skills.ToolSpec{
+		Name:        "echo_city",
+		Description: "Echo the city",
+		Parameters: map[string]skills.ToolParameter{
+			"city": {Type: "string", Required: true},
+		},
+		Exec:           &skills.ExecHandler{Command: `printf '%s|' "$GOLEM_ARG_CITY"; cat; pwd`},
+		TimeoutSeconds: 10,
+		Skill:          "weather",
+		SkillDir:       "/skills/weather",
+	}
+	st, err := NewSkillTool(spec, SkillToolOptions{Exec: ExecToolOptions{WorkspaceDir: workspace}})
+	if err != nil {
+		t.Fatalf("NewSkillTool: %v", err)
+	}
+
+	info, err := st.Info(context.Background())
+	if err != nil || !strings.Contains(info.Desc, "from skill weather") {
+		t.Fatalf("expected description to name the skill, got %+v (%v)", info, err)
+	}
+
+	result, err := st.InvokableRun(context.Background(), `{"city":"Paris; rm -rf /"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	var out ExecOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	want := `Paris; rm -rf /|{"city":"Paris; rm -rf /"}` + workspace
+	if strings.TrimSpace(out.Stdout) != want || out.ExitCode != 0 {
+		t.Fatalf("expected stdout %q, got %+v", want, out)
+	}
+
+	if _, err := st.InvokableRun(context.Background(), `{}`); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Fatalf("expected missing argument error, got %v", err)
+	}
+}
+
+func TestSkillTool_ExecHonorsBlockedCommands(t *testing.T) {
+	spec := skills.ToolSpec{
+		Name:           "fetch",
+		Description:    "Fetch",
+		Exec:           &skills.ExecHandler{Command: "curl https://example.com"},
+		TimeoutSeconds: 10,
+	}
+	st, err := NewSkillTool(spec, SkillToolOptions{Exec: ExecToolOptions{BlockedCommands: []string{"curl"}}})
+	if err != nil {
+		t.Fatalf("NewSkillTool: %v", err)
+	}
+	_, err = st.InvokableRun(context.Background(), `{}`)
+	var notAllowed *CommandNotAllowedError
+	if !errors.As(err, &notAllowed) {
+		t.Fatalf("expected CommandNotAllowedError, got %v", err)
+	}
+}
+
+func TestSkillTool_HTTPExpandsURLAndBlocksPrivateByDefault(t *testing.T) {
+	var gotPath, gotBody, gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath() + "?" + r.URL.RawQuery
+		gotHeader = r.Header.Get("X-Skill")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte("sunny"))
+	}))
+	defer srv.Close()
+
+	spec := skills.ToolSpec{
+		Name:        "weather_now",
+		Description: "Weather",
+		Parameters: map[string]skills.ToolParameter{
+			"city": {Type: "string", Required: true},
+		},
+		HTTP: &skills.HTTPHandler{
+			Method:  "POST",
+			URL:     srv.URL + "/v1/{{city}}?lang=en",
+			Headers: map[string]string{"X-Skill": "weather"},
+		},
+		TimeoutSeconds: 10,
+	}
+
+	st, err := NewSkillTool(spec, SkillToolOptions{AllowPrivate: true})
+	if err != nil {
+		t.Fatalf("NewSkillTool: %v", err)
+	}
+	result, err := st.InvokableRun(context.Background(), `{"city":"New York"}`)
+	if err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	var out SkillHTTPOutput
+	if err := json.Unmarshal([]byte(result), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if out.Status != http.StatusOK || out.Body != "sunny" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if gotPath != "/v1/New%20York?lang=en" || gotHeader != "weather" || gotBody != `{"city":"New York"}` {
+		t.Fatalf("unexpected request: path=%q header=%q body=%q", gotPath, gotHeader, gotBody)
+	}
+
+	blocked, err := NewSkillTool(spec, SkillToolOptions{})
+	if err != nil {
+		t.Fatalf("NewSkillTool: %v", err)
+	}
+	_, err = blocked.InvokableRun(context.Background(), `{"city":"Paris"}`)
+	var private *PrivateAddressError
+	if !errors.As(err, &private) {
+		t.Fatalf("expected PrivateAddressError for loopback target, got %v", err)
+	}
+}
@@ -15,8 +15,10 @@ type SubagentRequest struct {
 	Label          string // 任务的可选描述性标签
 	OriginChannel  string // 原始请求通道
 	OriginChatID   string // 原始聊天 ID
+	OriginThreadID string // 原始消息所在线程，异步结果回复到该线程
 	OriginSenderID string // 原始发送者 ID
 	RequestID      string // 请求追踪 ID
+	Depth          int    // 被委派子代理的嵌套深度，主代理直接委派时为 1
 }
 
 // SubagentExecutor 定义了派生或同步运行子代理任务的接口。
@@ -93,9 +95,12 @@ func buildSubagentRequest(ctx context.Context, task, label, channel, chatID stri
 		channel = "cli"
 	}
 
+	// 只有继承了来源聊天时才继承其线程，显式指定的聊天回复到频道根部
+	threadID := ""
 	chatID = strings.TrimSpace(chatID)
 	if chatID == "" {
 		chatID = meta.ChatID
+		threadID = meta.ThreadID
 	}
 	if chatID == "" {
 		chatID = "direct"
@@ -111,8 +116,10 @@ func buildSubagentRequest(ctx context.Context, task, label, channel, chatID stri
 		Label:          strings.TrimSpace(label),
 		OriginChannel:  channel,
 		OriginChatID:   chatID,
+		OriginThreadID: threadID,
 		OriginSenderID: sender,
 		RequestID:      meta.RequestID,
+		Depth:          SubagentDepthFromContext(ctx) + 1,
 	}, nil
 }
 
@@ -121,7 +128,7 @@ func NewSpawnTool(executor SubagentExecutor) (tool.InvokableTool, error) {
 	impl := &spawnToolImpl{executor: executor}
 	return utils.InferTool(
 		"spawn",
-		"Run a delegated subagent task in background and report completion asynchronously.",
+		"Run a delegated subagent task in background and report completion asynchronously. Nested delegation is limited by agents.subagent.max_depth.",
 		impl.execute,
 	)
 }
@@ -131,7 +138,7 @@ func NewSubagentTool(executor SubagentExecutor) (tool.InvokableTool, error) {
 	impl := &subagentToolImpl{executor: executor}
 	return utils.InferTool(
 		"subagent",
-		"Run a delegated subagent task synchronously and return the result.",
+		"Run a delegated subagent task synchronously and return the result. Nested delegation is limited by agents.subagent.max_depth.",
 		impl.execute,
 	)
 }
@@ -58,6 +58,33 @@ func TestSpawnTool_UsesInvocationDefaults(t *testing.T) {
 	}
 }
 
+func TestSpawnTool_InheritsThreadOnlyWithInheritedChat(t *testing.T) {
+	exec := &fakeSubagentExecutor{}
+	tool, err := NewSpawnTool(exec)
+	if err != nil {
+		t.Fatalf("NewSpawnTool: %v", err)
+	}
+	ctx := WithInvocationContext(context.Background(), InvocationContext{
+		Channel:  "slack",
+		ChatID:   "C1/1700000000.1",
+		ThreadID: "1700000000.1",
+	})
+
+	if _, err := tool.InvokableRun(ctx, `{"task":"collect logs"}`); err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	if exec.lastSpawnReq.OriginThreadID != "1700000000.1" {
+		t.Fatalf("expected origin thread to be inherited, got %+v", exec.lastSpawnReq)
+	}
+
+	if _, err := tool.InvokableRun(ctx, `{"task":"collect logs","chat_id":"C2"}`); err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	if exec.lastSpawnReq.OriginChatID != "C2" || exec.lastSpawnReq.OriginThreadID != "" {
+		t.Fatalf("expected explicit chat to drop the origin thread, got %+v", exec.lastSpawnReq)
+	}
+}
+
 func TestSubagentTool_RunSyncReturnsResult(t *testing.T) {
 	exec := &fakeSubagentExecutor{syncResult: "analysis complete"}
 	tool, err := NewSubagentTool(exec)
@@ -76,3 +103,30 @@ func TestSubagentTool_RunSyncReturnsResult(t *testing.T) {
 		t.Fatalf("unexpected sync request payload: %+v", exec.lastSyncReq)
 	}
 }
+
+func TestSubagentTools_IncrementDepthFromContext(t *testing.T) {
+	exec := &fakeSubagentExecutor{}
+	spawnTool, err := NewSpawnTool(exec)
+	if err != nil {
+		t.Fatalf("NewSpawnTool: %v", err)
+	}
+	syncTool, err := NewSubagentTool(exec)
+	if err != nil {
+		t.Fatalf("NewSubagentTool: %v", err)
+	}
+
+	if _, err := syncTool.InvokableRun(context.Background(), `{"task":"top level"}`); err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	if exec.lastSyncReq.Depth != 1 {
+		t.Fatalf("expected depth 1 from the main agent, got %d", exec.lastSyncReq.Depth)
+	}
+
+	nested := WithSubagentDepth(context.Background(), 2)
+	if _, err := spawnTool.InvokableRun(nested, `{"task":"nested"}`); err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	if exec.lastSpawnReq.Depth != 3 {
+		t.Fatalf("expected depth 3 inside a depth-2 subagent, got %d", exec.lastSpawnReq.Depth)
+	}
+}
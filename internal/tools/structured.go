@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"encoding/json"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/components/tool/utils"
+)
+
+// Result 是一次工具调用的结果。Content 是交给模型的文本；
+// 结构化工具成功执行时 Data 为其 JSON 输出（与 Content 内容相同），其余情况为空。
+// PendingApproval 为 true 表示守卫要求人工审批，工具尚未执行。
+type Result struct {
+	Content         string
+	Data            json.RawMessage
+	PendingApproval bool
+}
+
+// StructuredTool 标记输出为稳定 JSON 结构的工具（通常由 InferTool 基于输出结构体生成）。
+type StructuredTool interface {
+	tool.InvokableTool
+	StructuredOutput() bool
+}
+
+type structuredTool struct {
+	tool.InvokableTool
+}
+
+func (structuredTool) StructuredOutput() bool { return true }
+
+// AsStructured 将工具标记为结构化输出工具，Registry.ExecuteResult 会将其结果作为 JSON 数据返回。
+func AsStructured(t tool.InvokableTool) tool.InvokableTool {
+	return structuredTool{InvokableTool: t}
+}
+
+// isStructured 报告工具是否声明了结构化输出。
+func isStructured(t tool.InvokableTool) bool {
+	st, ok := t.(StructuredTool)
+	return ok && st.StructuredOutput()
+}
+
+// inferStructuredTool 基于函数签名推断工具（输出为结构体）并标记为结构化输出。
+func inferStructuredTool[T, D any](name, desc string, fn utils.InvokeFunc[T, D]) (tool.InvokableTool, error) {
+	t, err := utils.InferTool(name, desc, fn)
+	if err != nil {
+		return nil, err
+	}
+	return AsStructured(t), nil
+}
@@ -2,27 +2,25 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"html"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/MEKXH/golem/internal/httpclient"
 	"github.com/cloudwego/eino/components/tool"
-	"github.com/cloudwego/eino/components/tool/utils"
 )
 
 const (
-	defaultBraveSearchEndpoint = "https://api.search.brave.com/res/v1/web/search"
-	defaultDuckSearchEndpoint  = "https://duckduckgo.com/html/"
-	defaultWebTimeout          = 15 * time.Second
-	defaultWebFetchMaxBytes    = 256 * 1024
-	maxWebFetchBytes           = 1024 * 1024
-	maxWebSearchResults        = 20
+	defaultWebTimeout       = 15 * time.Second
+	defaultWebFetchMaxBytes = 256 * 1024
+	maxWebFetchBytes        = 1024 * 1024
+	maxWebSearchResults     = 20
 )
 
 var (
@@ -32,227 +30,17 @@ var (
 	ddgResultLinkRe = regexp.MustCompile(`(?is)<a[^>]*class="[^"]*result__a[^"]*"[^>]*href="([^"]+)"[^>]*>(.*?)</a>`)
 )
 
-// WebSearchInput 定义了 web_search 工具的输入参数。
-type WebSearchInput struct {
-	Query      string `json:"query" jsonschema:"required,description=The search query"`
-	MaxResults int    `json:"max_results" jsonschema:"description=Optional per-request result limit"`
-}
-
-// WebSearchResult 表示单条搜索结果。
-type WebSearchResult struct {
-	Title       string `json:"title"`
-	URL         string `json:"url"`
-	Description string `json:"description"`
-}
-
-// WebSearchOutput 定义了 web_search 工具的执行结果。
-type WebSearchOutput struct {
-	Query   string            `json:"query"`
-	Results []WebSearchResult `json:"results"`
-}
-
-type webSearchToolImpl struct {
-	apiKey        string
-	maxResults    int
-	braveEndpoint string
-	duckEndpoint  string
-	client        *http.Client
-}
-
-func (w *webSearchToolImpl) execute(ctx context.Context, input *WebSearchInput) (*WebSearchOutput, error) {
-	query := strings.TrimSpace(input.Query)
-	if query == "" {
-		return nil, fmt.Errorf("query is required")
-	}
-
-	limit := resolveWebSearchLimit(input.MaxResults, w.maxResults)
-	apiKey := strings.TrimSpace(w.apiKey)
-
-	// 优先尝试 Brave Search API（如果已配置 API Key）
-	if apiKey != "" {
-		out, err := w.searchWithBrave(ctx, query, limit)
-		if err == nil {
-			return out, nil
-		}
-	}
-
-	// 回退到 DuckDuckGo HTML 搜索
-	return w.searchWithDuckDuckGo(ctx, query, limit)
-}
-
-func resolveWebSearchLimit(requested, defaultLimit int) int {
-	limit := requested
-	if limit <= 0 {
-		limit = defaultLimit
-	}
-	if limit <= 0 {
-		limit = 5
-	}
-	if limit > maxWebSearchResults {
-		limit = maxWebSearchResults
-	}
-	return limit
-}
-
-func (w *webSearchToolImpl) searchWithBrave(ctx context.Context, query string, limit int) (*WebSearchOutput, error) {
-	u, err := url.Parse(w.braveEndpoint)
-	if err != nil {
-		return nil, fmt.Errorf("invalid search endpoint: %w", err)
-	}
-	q := u.Query()
-	q.Set("q", query)
-	q.Set("count", fmt.Sprintf("%d", limit))
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Subscription-Token", strings.TrimSpace(w.apiKey))
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("web search failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	var brave struct {
-		Web struct {
-			Results []struct {
-				Title       string `json:"title"`
-				URL         string `json:"url"`
-				Description string `json:"description"`
-			} `json:"results"`
-		} `json:"web"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&brave); err != nil {
-		return nil, fmt.Errorf("failed to parse search response: %w", err)
-	}
-
-	out := &WebSearchOutput{
-		Query:   query,
-		Results: make([]WebSearchResult, 0, len(brave.Web.Results)),
-	}
-	for _, item := range brave.Web.Results {
-		out.Results = append(out.Results, WebSearchResult{
-			Title:       item.Title,
-			URL:         item.URL,
-			Description: item.Description,
-		})
-	}
-	return out, nil
-}
-
-func (w *webSearchToolImpl) searchWithDuckDuckGo(ctx context.Context, query string, limit int) (*WebSearchOutput, error) {
-	u, err := url.Parse(w.duckEndpoint)
-	if err != nil {
-		return nil, fmt.Errorf("invalid duck search endpoint: %w", err)
-	}
-	q := u.Query()
-	q.Set("q", query)
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "text/html")
-	req.Header.Set("User-Agent", "golem-web-search/1.0")
-
-	resp, err := w.client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return nil, fmt.Errorf("duckduckgo search failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-
-	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebFetchBytes))
-	if err != nil {
-		return nil, err
-	}
-	htmlBody := string(body)
-
-	matches := ddgResultLinkRe.FindAllStringSubmatch(htmlBody, limit)
-	results := make([]WebSearchResult, 0, len(matches))
-	for _, m := range matches {
-		if len(m) < 3 {
-			continue
-		}
-		rawURL := strings.TrimSpace(html.UnescapeString(m[1]))
-		title := strings.TrimSpace(htmlToText(html.UnescapeString(m[2])))
-		if rawURL == "" || title == "" {
-			continue
-		}
-		finalURL := decodeDuckRedirect(rawURL, u)
-		results = append(results, WebSearchResult{
-			Title:       title,
-			URL:         finalURL,
-			Description: "",
-		})
-	}
-
-	return &WebSearchOutput{
-		Query:   query,
-		Results: results,
-	}, nil
-}
-
-func decodeDuckRedirect(rawURL string, base *url.URL) string {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return rawURL
-	}
-	if parsed.IsAbs() {
-		return parsed.String()
-	}
-	if strings.HasPrefix(parsed.Path, "/l/") {
-		uddg := parsed.Query().Get("uddg")
-		if decoded, err := url.QueryUnescape(uddg); err == nil && strings.TrimSpace(decoded) != "" {
-			return decoded
-		}
-	}
-	if base != nil {
-		return base.ResolveReference(parsed).String()
-	}
-	return rawURL
-}
-
-// NewWebSearchTool 创建 web_search 工具实例，用于在互联网上搜索最新信息。
-func NewWebSearchTool(apiKey string, maxResults int) (tool.InvokableTool, error) {
-	if maxResults <= 0 {
-		maxResults = 5
-	}
-	impl := &webSearchToolImpl{
-		apiKey:        apiKey,
-		maxResults:    maxResults,
-		braveEndpoint: defaultBraveSearchEndpoint,
-		duckEndpoint:  defaultDuckSearchEndpoint,
-		client: &http.Client{
-			Timeout: defaultWebTimeout,
-		},
-	}
-	return utils.InferTool("web_search", "Search the web for up-to-date information", impl.execute)
-}
-
 // WebFetchInput 定义了 web_fetch 工具的输入参数。
 type WebFetchInput struct {
 	URL      string `json:"url" jsonschema:"required,description=The target URL to fetch"`
-	MaxBytes int    `json:"max_bytes" jsonschema:"description=Optional maximum response bytes to keep"`
+	MaxBytes int    `json:"max_bytes,omitempty" jsonschema:"description=Optional maximum response bytes to keep"`
 }
 
 // WebFetchOutput 定义了 web_fetch 工具的执行结果。
 type WebFetchOutput struct {
-	URL         string `json:"url"`
+	URL         string `json:"url"`       // 请求的 URL
+	FinalURL    string `json:"final_url"` // 跟随重定向后实际到达的 URL
+	Redirects   int    `json:"redirects"` // 经历的重定向次数
 	Status      int    `json:"status"`
 	ContentType string `json:"content_type"`
 	Content     string `json:"content"`
@@ -260,8 +48,11 @@ type WebFetchOutput struct {
 }
 
 type webFetchToolImpl struct {
-	client   *http.Client
-	maxBytes int
+	client       *http.Client
+	maxBytes     int
+	cache        *webFetchCache // 为空时不缓存
+	blockPrivate bool           // 拒绝访问回环、链路本地与私有网段地址
+	robots       *robotsChecker // 为空时不检查 robots.txt
 }
 
 func (w *webFetchToolImpl) execute(ctx context.Context, input *WebFetchInput) (*WebFetchOutput, error) {
@@ -289,11 +80,29 @@ func (w *webFetchToolImpl) execute(ctx context.Context, input *WebFetchInput) (*
 		maxBytes = maxWebFetchBytes
 	}
 
+	if w.blockPrivate {
+		if err := checkPublicHost(ctx, parsed.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+
+	cacheKey := webFetchCacheKey(rawURL, maxBytes)
+	if w.cache != nil {
+		if out, ok := w.cache.get(cacheKey); ok {
+			return out, nil
+		}
+	}
+	if w.robots != nil {
+		if err := w.robots.check(ctx, w.client, parsed); err != nil {
+			return nil, err
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", "golem-web-fetch/1.0")
+	req.Header.Set("User-Agent", webFetchUserAgent)
 
 	resp, err := w.client.Do(req)
 	if err != nil {
@@ -312,36 +121,60 @@ func (w *webFetchToolImpl) execute(ctx context.Context, input *WebFetchInput) (*
 		truncated = true
 	}
 
-	contentType := strings.ToLower(resp.Header.Get("Content-Type"))
-	content := string(body)
-	// 如果是 HTML 页面，则尝试剥离标签提取纯文本
-	if strings.Contains(contentType, "text/html") {
-		content = htmlToText(content)
-	}
+	// 先按字节上限截断再提取，限制解析时的内存占用。
+	content := extractFetchedContent(resp.Header.Get("Content-Type"), body)
 
 	out := &WebFetchOutput{
 		URL:         rawURL,
+		FinalURL:    resp.Request.URL.String(),
+		Redirects:   redirectCount(resp),
 		Status:      resp.StatusCode,
 		ContentType: resp.Header.Get("Content-Type"),
 		Content:     strings.TrimSpace(content),
 		Truncated:   truncated,
 	}
 
+	if out.Redirects > 0 {
+		slog.Debug("web_fetch followed redirects",
+			"url", rawURL, "final_url", out.FinalURL, "redirects", out.Redirects)
+	}
 	if resp.StatusCode >= 400 {
 		return out, fmt.Errorf("web fetch failed with status %d", resp.StatusCode)
 	}
+	if w.cache != nil && cacheableResponse(resp) {
+		w.cache.put(cacheKey, out)
+	}
 	return out, nil
 }
 
+// WebFetchOptions 定义了 web_fetch 工具的构造参数。
+type WebFetchOptions struct {
+	CacheTTL         time.Duration // 缓存有效期，<=0 使用默认值 5 分钟
+	CacheMaxBytes    int           // 缓存总大小上限，<=0 使用默认值 16MB
+	AllowPrivate     bool          // 允许访问回环、链路本地与私有网段地址
+	RespectRobotsTxt bool          // 抓取前检查目标站点的 robots.txt
+	MaxRedirects     int           // 最多跟随的重定向次数，<=0 使用默认值 10
+}
+
 // NewWebFetchTool 创建 web_fetch 工具实例，用于抓取并提取指定 URL 的文本内容。
 func NewWebFetchTool() (tool.InvokableTool, error) {
+	return NewWebFetchToolWithOptions(WebFetchOptions{})
+}
+
+// NewWebFetchToolWithOptions 创建带结果缓存的 web_fetch 工具实例，
+// 同一 URL 与 max_bytes 在有效期内重复抓取时直接返回缓存结果。
+// 默认拒绝访问非公网地址以防止 SSRF，可通过 AllowPrivate 放开。
+func NewWebFetchToolWithOptions(opts WebFetchOptions) (tool.InvokableTool, error) {
 	impl := &webFetchToolImpl{
-		client: &http.Client{
-			Timeout: defaultWebTimeout,
-		},
-		maxBytes: defaultWebFetchMaxBytes,
+		client:       newFetchClient(httpclient.Timeout(defaultWebTimeout), opts.MaxRedirects, !opts.AllowPrivate),
+		maxBytes:     defaultWebFetchMaxBytes,
+		cache:        newWebFetchCache(opts.CacheTTL, opts.CacheMaxBytes),
+		blockPrivate: !opts.AllowPrivate,
+	}
+	if opts.RespectRobotsTxt {
+		impl.robots = newRobotsChecker()
 	}
-	return utils.InferTool("web_fetch", "Fetch content from a URL", impl.execute)
+	return inferStructuredTool("web_fetch", "Fetch content from a URL; HTML and PDF are converted to plain text", impl.execute)
 }
 
 func htmlToText(input string) string {
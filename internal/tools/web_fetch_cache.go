@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebFetchCacheTTL      = 5 * time.Minute
+	defaultWebFetchCacheMaxBytes = 16 * 1024 * 1024
+)
+
+// webFetchCache 是按 URL+max_bytes 索引的 LRU 缓存，总大小受 maxBytes 约束，条目在 ttl 后过期。
+// 工具调用可能在多个 goroutine 中并发执行，所有操作都在互斥锁内完成。
+type webFetchCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxBytes int
+	size     int
+	order    *list.List               // 最近使用的条目在前
+	items    map[string]*list.Element // key -> *webFetchCacheEntry
+	now      func() time.Time
+}
+
+type webFetchCacheEntry struct {
+	key     string
+	out     WebFetchOutput
+	size    int
+	expires time.Time
+}
+
+func newWebFetchCache(ttl time.Duration, maxBytes int) *webFetchCache {
+	if ttl <= 0 {
+		ttl = defaultWebFetchCacheTTL
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultWebFetchCacheMaxBytes
+	}
+	return &webFetchCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+func webFetchCacheKey(rawURL string, maxBytes int) string {
+	return fmt.Sprintf("%d|%s", maxBytes, rawURL)
+}
+
+// get 返回未过期的缓存结果副本。
+func (c *webFetchCache) get(key string) (*WebFetchOutput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*webFetchCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	out := entry.out
+	return &out, true
+}
+
+// put 写入结果并按 LRU 淘汰超出总大小的条目；单条超过上限时不缓存。
+func (c *webFetchCache) put(key string, out *WebFetchOutput) {
+	size := len(key) + len(out.URL) + len(out.ContentType) + len(out.Content)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	entry := &webFetchCacheEntry{key: key, out: *out, size: size, expires: c.now().Add(c.ttl)}
+	c.items[key] = c.order.PushFront(entry)
+	c.size += size
+	for c.size > c.maxBytes {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *webFetchCache) removeElement(elem *list.Element) {
+	entry := c.order.Remove(elem).(*webFetchCacheEntry)
+	delete(c.items, entry.key)
+	c.size -= entry.size
+}
+
+// cacheableResponse 判断响应是否允许缓存：仅缓存成功响应，且遵守 Cache-Control: no-store。
+func cacheableResponse(resp *http.Response) bool {
+	if resp.StatusCode >= 400 {
+		return false
+	}
+	for _, value := range resp.Header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebFetch_CachesRepeatedFetches(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		_, _ = w.Write([]byte("page body"))
+	}))
+	defer server.Close()
+
+	impl := &webFetchToolImpl{client: server.Client(), maxBytes: 1024, cache: newWebFetchCache(time.Minute, 1024)}
+
+	for i := 0; i < 3; i++ {
+		out, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL})
+		if err != nil {
+			t.Fatalf("fetch %d: %v", i, err)
+		}
+		if out.Content != "page body" {
+			t.Fatalf("unexpected content: %q", out.Content)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Fatalf("expected one upstream request, got %d", got)
+	}
+
+	// max_bytes 不同视为不同的缓存键。
+	if _, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL, MaxBytes: 4}); err != nil {
+		t.Fatalf("fetch with max_bytes: %v", err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Fatalf("expected different max_bytes to refetch, got %d requests", got)
+	}
+}
+
+func TestWebFetch_RespectsNoStoreAndErrors(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "private, no-store")
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+
+	impl := &webFetchToolImpl{client: server.Client(), maxBytes: 1024, cache: newWebFetchCache(time.Minute, 1024)}
+
+	for i := 0; i < 2; i++ {
+		if _, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL}); err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		_, _ = impl.execute(context.Background(), &WebFetchInput{URL: server.URL + "/missing"})
+	}
+	if got := hits.Load(); got != 4 {
+		t.Fatalf("expected no-store and error responses to bypass the cache, got %d requests", got)
+	}
+}
+
+func TestWebFetchCache_ExpiresAndEvictsBySize(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := newWebFetchCache(time.Minute, 64)
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", &WebFetchOutput{Content: "0123456789012345678901234"})
+	cache.put("b", &WebFetchOutput{Content: "0123456789012345678901234"})
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	// 写入 c 超出总大小，淘汰最久未使用的 b。
+	cache.put("c", &WebFetchOutput{Content: "0123456789012345678901234"})
+	if _, ok := cache.get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected recently used a to survive eviction")
+	}
+	if cache.size > 64 {
+		t.Fatalf("cache size %d exceeds limit", cache.size)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("a"); ok {
+		t.Fatal("expected a to expire after ttl")
+	}
+
+	cache.put("huge", &WebFetchOutput{Content: string(make([]byte, 100))})
+	if _, ok := cache.get("huge"); ok {
+		t.Fatal("expected oversized entry not to be cached")
+	}
+}
+
+func TestWebFetchCache_ConcurrentAccess(t *testing.T) {
+	cache := newWebFetchCache(time.Minute, 4096)
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("k%d", i%4)
+			for j := 0; j < 100; j++ {
+				cache.put(key, &WebFetchOutput{Content: "body"})
+				cache.get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if len(cache.items) != cache.order.Len() {
+		t.Fatalf("index and list out of sync: %d vs %d", len(cache.items), cache.order.Len())
+	}
+}
@@ -0,0 +1,509 @@
+package tools
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxPDFDecodedBytes 限制单个 PDF 解压后的总字节数，防止压缩炸弹。
+const maxPDFDecodedBytes = 8 * 1024 * 1024
+
+var errPDFNoText = errors.New("no extractable text found")
+
+// extractFetchedContent 按 Content-Type 将响应体转换为可读文本：
+// HTML 剥离标签，PDF 提取文本，其他文本类型原样返回；
+// 无法提取的二进制内容返回一条说明，而不是原始字节。
+// 缺少 Content-Type 时根据内容嗅探类型。
+func extractFetchedContent(contentType string, body []byte) string {
+	mediaType := fetchedMediaType(contentType, body)
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return htmlToText(strings.ToValidUTF8(string(body), ""))
+	case mediaType == "application/pdf":
+		text, err := extractPDFText(body)
+		if err != nil {
+			return binaryContentNote(mediaType, len(body), err.Error())
+		}
+		return text
+	case isTextMediaType(mediaType):
+		return strings.ToValidUTF8(string(body), "")
+	default:
+		return binaryContentNote(mediaType, len(body), "")
+	}
+}
+
+// fetchedMediaType 返回规范化的媒体类型，缺少 Content-Type 时根据内容嗅探。
+func fetchedMediaType(contentType string, body []byte) string {
+	mediaType := strings.ToLower(strings.TrimSpace(contentType))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(body)
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	return mediaType
+}
+
+// isExtractableMediaType 报告 extractFetchedContent 能否从该媒体类型中得到文本。
+func isExtractableMediaType(mediaType string) bool {
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml" ||
+		mediaType == "application/pdf" || isTextMediaType(mediaType)
+}
+
+// isTextMediaType 报告媒体类型是否可以直接作为文本返回。
+func isTextMediaType(mediaType string) bool {
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript",
+		"application/ecmascript", "application/x-yaml", "application/yaml",
+		"application/toml", "application/x-ndjson", "application/csv":
+		return true
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+func binaryContentNote(mediaType string, size int, reason string) string {
+	if reason != "" {
+		return fmt.Sprintf("[binary content not extractable: %s, %d bytes; %s]", mediaType, size, reason)
+	}
+	return fmt.Sprintf("[binary content not extractable: %s, %d bytes]", mediaType, size)
+}
+
+// extractPDFText 从 PDF 的内容流中提取文本。
+// 仅支持未压缩与 FlateDecode 压缩的流，按 Tj/TJ/'/" 文本操作符收集字符串；
+// 依赖 ToUnicode 映射的复合字体（如 Identity-H 编码的中文）无法还原。
+// 被截断的 PDF 尽量提取已读取部分中的文本。
+func extractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return "", errors.New("not a PDF document")
+	}
+
+	var out strings.Builder
+	decoded := 0
+	rest := data
+	for {
+		start, end, dict, ok := nextPDFStream(rest)
+		if !ok {
+			break
+		}
+		raw := rest[start:end]
+		rest = rest[end:]
+
+		if bytes.Contains(dict, []byte("/Image")) || bytes.Contains(dict, []byte("/Length1")) ||
+			bytes.Contains(dict, []byte("/XRef")) {
+			continue
+		}
+		var content []byte
+		switch {
+		case bytes.Contains(dict, []byte("/FlateDecode")):
+			zr, err := zlib.NewReader(bytes.NewReader(raw))
+			if err != nil {
+				continue
+			}
+			// 截断的流会返回 ErrUnexpectedEOF，保留已解压部分。
+			content, _ = io.ReadAll(io.LimitReader(zr, int64(maxPDFDecodedBytes-decoded)))
+			_ = zr.Close()
+		case bytes.Contains(dict, []byte("/Filter")):
+			continue // 其他编码（DCT、LZW 等）不支持
+		default:
+			content = raw
+		}
+		decoded += len(content)
+		writePDFContentText(&out, content)
+		if decoded >= maxPDFDecodedBytes {
+			break
+		}
+	}
+
+	text := normalizePDFText(out.String())
+	if text == "" {
+		return "", errPDFNoText
+	}
+	return text, nil
+}
+
+// nextPDFStream 在 data 中查找下一个流对象，返回流数据的起止偏移与流字典。
+// 缺少 endstream 时（如文档被截断）流延伸到 data 末尾。
+func nextPDFStream(data []byte) (start, end int, dict []byte, ok bool) {
+	offset := 0
+	for {
+		idx := bytes.Index(data[offset:], []byte("stream"))
+		if idx < 0 {
+			return 0, 0, nil, false
+		}
+		idx += offset
+		offset = idx + len("stream")
+		// 跳过 endstream 以及不以换行结束的匹配。
+		if idx >= 3 && string(data[idx-3:idx]) == "end" {
+			continue
+		}
+		start = offset
+		if start < len(data) && data[start] == '\r' {
+			start++
+		}
+		if start >= len(data) || data[start] != '\n' {
+			continue
+		}
+		start++
+
+		dictStart := bytes.LastIndex(data[:idx], []byte("obj"))
+		if dictStart < 0 {
+			dictStart = 0
+		}
+		dict = data[dictStart:idx]
+
+		end = len(data)
+		if e := bytes.Index(data[start:], []byte("endstream")); e >= 0 {
+			end = start + e
+		}
+		return start, end, dict, true
+	}
+}
+
+// writePDFContentText 解析内容流，将 BT/ET 文本对象中显示的字符串写入 out。
+func writePDFContentText(out *strings.Builder, content []byte) {
+	lex := &pdfLexer{data: content}
+	var operands []pdfToken
+	inText := false
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			return
+		}
+		if tok.kind != pdfTokenOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "BT":
+			inText = true
+		case "ET":
+			if inText {
+				out.WriteString("\n")
+			}
+			inText = false
+		case "Td", "TD":
+			if !inText {
+				break
+			}
+			// 纵向偏移非零视为换行，否则视为同一行内的间隔。
+			if ty, err := strconv.ParseFloat(lastPDFOperand(operands), 64); err == nil && ty != 0 {
+				out.WriteString("\n")
+			} else {
+				out.WriteString(" ")
+			}
+		case "T*":
+			if inText {
+				out.WriteString("\n")
+			}
+		case "Tj", "TJ":
+			if inText {
+				out.WriteString(lastPDFString(operands))
+			}
+		case "'", "\"":
+			if inText {
+				out.WriteString("\n")
+				out.WriteString(lastPDFString(operands))
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+func lastPDFOperand(operands []pdfToken) string {
+	if len(operands) == 0 {
+		return ""
+	}
+	return operands[len(operands)-1].text
+}
+
+func lastPDFString(operands []pdfToken) string {
+	if len(operands) == 0 || operands[len(operands)-1].kind != pdfTokenString {
+		return ""
+	}
+	return operands[len(operands)-1].text
+}
+
+// normalizePDFText 去除不可打印字符并压缩每行内的空白，丢弃空行。
+func normalizePDFText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Map(func(r rune) rune {
+			if r == utf8.RuneError || (!unicode.IsPrint(r) && !unicode.IsSpace(r)) {
+				return -1
+			}
+			return r
+		}, line)
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type pdfTokenKind int
+
+const (
+	pdfTokenOperator pdfTokenKind = iota
+	pdfTokenOperand
+	pdfTokenString // 字符串或 TJ 数组
+)
+
+type pdfToken struct {
+	kind pdfTokenKind
+	text string // 字符串与 TJ 数组为解码后的文本，其他操作数为原始字面量
+}
+
+// pdfLexer 是内容流的最小词法分析器，只识别提取文本所需的记号。
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func (l *pdfLexer) next() (pdfToken, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return pdfToken{}, false
+	}
+	switch c := l.data[l.pos]; {
+	case c == '(':
+		return pdfToken{kind: pdfTokenString, text: decodePDFString(l.literalString())}, true
+	case c == '<' && l.peek(1) == '<':
+		l.pos += 2
+		return pdfToken{kind: pdfTokenOperand, text: "<<"}, true
+	case c == '>' && l.peek(1) == '>':
+		l.pos += 2
+		return pdfToken{kind: pdfTokenOperand, text: ">>"}, true
+	case c == '<':
+		return pdfToken{kind: pdfTokenString, text: decodePDFString(l.hexString())}, true
+	case c == '[':
+		l.pos++
+		return pdfToken{kind: pdfTokenString, text: l.array()}, true
+	case c == ']' || c == '>' || c == '{' || c == '}' || c == ')':
+		l.pos++
+		return pdfToken{kind: pdfTokenOperand, text: string(c)}, true
+	case c == '/':
+		start := l.pos
+		l.pos++
+		l.word()
+		return pdfToken{kind: pdfTokenOperand, text: string(l.data[start:l.pos])}, true
+	default:
+		word := l.word()
+		if word == "" {
+			l.pos++
+			return pdfToken{kind: pdfTokenOperand}, true
+		}
+		if isPDFNumber(word) {
+			return pdfToken{kind: pdfTokenOperand, text: word}, true
+		}
+		return pdfToken{kind: pdfTokenOperator, text: word}, true
+	}
+}
+
+func (l *pdfLexer) peek(n int) byte {
+	if l.pos+n < len(l.data) {
+		return l.data[l.pos+n]
+	}
+	return 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case ' ', '\t', '\r', '\n', '\f', 0:
+			l.pos++
+		case '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// word 读取到下一个空白或分隔符为止的普通记号。
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case ' ', '\t', '\r', '\n', '\f', 0, '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+			return string(l.data[start:l.pos])
+		}
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// literalString 读取 (...) 字符串，处理转义与嵌套括号。
+func (l *pdfLexer) literalString() []byte {
+	l.pos++ // (
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return buf
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					buf = append(buf, byte(v))
+				} else {
+					buf = append(buf, e)
+				}
+			}
+			continue
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+// hexString 读取 <...> 十六进制字符串，奇数个数字时末尾补 0。
+func (l *pdfLexer) hexString() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; isHexDigit(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	buf := make([]byte, len(digits)/2)
+	for i := range buf {
+		buf[i] = hexValue(digits[2*i])<<4 | hexValue(digits[2*i+1])
+	}
+	return buf
+}
+
+// array 读取 TJ 数组，拼接其中的字符串；较大的负向字距视为单词间隔。
+// 嵌套数组只计深度、展开到同一层，不递归调用 next，避免恶意内容流以大量 "[" 耗尽栈空间。
+func (l *pdfLexer) array() string {
+	var sb strings.Builder
+	depth := 1
+	for {
+		l.skipSpace()
+		if l.pos < len(l.data) {
+			switch l.data[l.pos] {
+			case '[':
+				l.pos++
+				depth++
+				continue
+			case ']':
+				l.pos++
+				if depth--; depth == 0 {
+					return sb.String()
+				}
+				continue
+			}
+		}
+		tok, ok := l.next()
+		if !ok {
+			return sb.String()
+		}
+		if tok.kind == pdfTokenString {
+			sb.WriteString(tok.text)
+		} else if v, err := strconv.ParseFloat(tok.text, 64); err == nil && v <= -200 {
+			sb.WriteString(" ")
+		}
+	}
+}
+
+// decodePDFString 将字符串字节转为文本：带 BOM 的按 UTF-16BE 解码，否则按 Latin-1 处理。
+func decodePDFString(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		units := make([]uint16, 0, (len(b)-2)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func isPDFNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits := 0
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+		case (c == '-' || c == '+') && i == 0:
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
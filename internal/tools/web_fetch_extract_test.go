@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// buildTestPDF 生成只含一个内容流的最小 PDF，compress 为 true 时使用 FlateDecode。
+func buildTestPDF(t *testing.T, content string, compress bool) []byte {
+	t.Helper()
+	stream := []byte(content)
+	dict := fmt.Sprintf("<< /Length %d >>", len(stream))
+	if compress {
+		var buf bytes.Buffer
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(stream); err != nil {
+			t.Fatalf("compress: %v", err)
+		}
+		_ = zw.Close()
+		stream = buf.Bytes()
+		dict = fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>", len(stream))
+	}
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n4 0 obj\n")
+	pdf.WriteString(dict)
+	pdf.WriteString("\nstream\n")
+	pdf.Write(stream)
+	pdf.WriteString("\nendstream\nendobj\ntrailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestExtractPDFText(t *testing.T) {
+	content := "BT /F1 12 Tf 72 720 Td (Hello \\(PDF\\) world) Tj 0 -14 Td [(Sec) 10 (ond) -300 (line)] TJ ET\n" +
+		"BT <FEFF00E9007400E9> Tj T* (caf\\351) Tj ET"
+
+	for _, compress := range []bool{false, true} {
+		text, err := extractPDFText(buildTestPDF(t, content, compress))
+		if err != nil {
+			t.Fatalf("compress=%v: %v", compress, err)
+		}
+		want := "Hello (PDF) world\nSecond line\nété\ncafé"
+		if text != want {
+			t.Fatalf("compress=%v: got %q, want %q", compress, text, want)
+		}
+	}
+}
+
+func TestExtractPDFText_TruncatedDocument(t *testing.T) {
+	pdf := buildTestPDF(t, "BT (first page) Tj ET BT (second page) Tj ET", false)
+	cut := bytes.Index(pdf, []byte("second"))
+
+	text, err := extractPDFText(pdf[:cut])
+	if err != nil {
+		t.Fatalf("extract truncated pdf: %v", err)
+	}
+	if text != "first page" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+}
+
+func TestExtractPDFText_NoText(t *testing.T) {
+	if _, err := extractPDFText(buildTestPDF(t, "0 0 m 10 10 l S", true)); err == nil {
+		t.Fatal("expected error for pdf without text")
+	}
+	if _, err := extractPDFText([]byte("not a pdf")); err == nil {
+		t.Fatal("expected error for non-pdf data")
+	}
+}
+
+func TestExtractFetchedContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		want        string
+	}{
+		{"html", "text/html; charset=utf-8", []byte("<p>Hi <b>there</b></p>"), "Hi there"},
+		{"plain text", "text/plain", []byte("line one\nline two"), "line one\nline two"},
+		{"json", "application/problem+json", []byte(`{"ok":true}`), `{"ok":true}`},
+		{"sniffed text", "", []byte("just text"), "just text"},
+		{"invalid utf8", "text/plain", []byte("ok\xff\xfe"), "ok"},
+		{"binary", "application/octet-stream", []byte{0x00, 0x01, 0x02}, "[binary content not extractable: application/octet-stream, 3 bytes]"},
+		{"sniffed binary", "", []byte("\x89PNG\r\n\x1a\n\x00\x00"), "[binary content not extractable: image/png, 10 bytes]"},
+		{"pdf without text", "application/pdf", []byte("%PDF-1.4\n%%EOF"), "[binary content not extractable: application/pdf, 14 bytes; no extractable text found]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractFetchedContent(tt.contentType, tt.body); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebFetch_ExtractsPDF(t *testing.T) {
+	pdf := buildTestPDF(t, "BT (Quarterly report) Tj ET", true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		_, _ = w.Write(pdf)
+	}))
+	defer server.Close()
+
+	impl := &webFetchToolImpl{client: server.Client(), maxBytes: 1024}
+	out, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if out.Content != "Quarterly report" {
+		t.Fatalf("unexpected content: %q", out.Content)
+	}
+	if !strings.HasPrefix(out.ContentType, "application/pdf") {
+		t.Fatalf("unexpected content type: %q", out.ContentType)
+	}
+}
+
+func TestExtractPDFText_DeeplyNestedArrays(t *testing.T) {
+	content := "BT " + strings.Repeat("[", 2_000_000) + "(deep)" + strings.Repeat("]", 2_000_000) + " TJ ET"
+
+	text, err := extractPDFText(buildTestPDF(t, content, true))
+	if err != nil {
+		t.Fatalf("extract nested arrays: %v", err)
+	}
+	if text != "deep" {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if _, err := extractPDFText(buildTestPDF(t, "BT "+strings.Repeat("[", 2_000_000), true)); err == nil {
+		t.Fatal("expected error for unterminated nested arrays without text")
+	}
+}
@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+const (
+	webFetchUserAgent           = "golem-web-fetch/1.0"
+	robotsCacheTTL              = time.Hour
+	maxRobotsBytes              = 512 * 1024
+	defaultWebFetchMaxRedirects = 10
+)
+
+// PrivateAddressError 表示 web_fetch 的目标解析到了回环、链路本地或私有网段地址。
+type PrivateAddressError struct {
+	Host string
+	IP   net.IP
+}
+
+func (e *PrivateAddressError) Error() string {
+	return fmt.Sprintf("web_fetch blocked: %s resolves to non-public address %s (set tools.web.allow_private to allow)", e.Host, e.IP)
+}
+
+// isPrivateIP 报告 ip 是否属于回环、链路本地、RFC1918/ULA 私有或未指定地址。
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// checkPublicHost 解析 host 并在任一地址为非公网地址时返回 *PrivateAddressError。
+func checkPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateIP(ip) {
+			return &PrivateAddressError{Host: host, IP: ip}
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return &PrivateAddressError{Host: host, IP: addr.IP}
+		}
+	}
+	return nil
+}
+
+// newFetchClient 创建 web_fetch 使用的 HTTP 客户端，重定向次数受 maxRedirects 限制。
+// blockPrivate 为 true 时拒绝访问非公网地址：每次重定向前检查目标主机，
+// 并在建立连接时再次校验实际拨号的 IP，防止 DNS 重绑定。发往代理地址（http.proxy 或环境变量）的连接不受限制。
+func newFetchClient(timeout time.Duration, maxRedirects int, blockPrivate bool) *http.Client {
+	if maxRedirects <= 0 {
+		maxRedirects = defaultWebFetchMaxRedirects
+	}
+	checkRedirect := func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("web_fetch stopped after %d redirects (limit tools.web.fetch.max_redirects=%d)", len(via)-1, maxRedirects)
+		}
+		if blockPrivate {
+			return checkPublicHost(req.Context(), req.URL.Hostname())
+		}
+		return nil
+	}
+	if !blockPrivate {
+		return &http.Client{Timeout: timeout, CheckRedirect: checkRedirect}
+	}
+
+	proxies := httpclient.ProxyAddrs()
+	guarded := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+				return &PrivateAddressError{Host: host, IP: ip}
+			}
+			return nil
+		},
+	}
+	direct := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = httpclient.Proxy
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if proxies[addr] {
+			return direct.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// redirectCount 返回得到 resp 之前经历的重定向次数。
+func redirectCount(resp *http.Response) int {
+	n := 0
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		n++
+	}
+	return n
+}
+
+// RobotsDisallowedError 表示目标路径被站点的 robots.txt 禁止抓取。
+type RobotsDisallowedError struct {
+	URL string
+}
+
+func (e *RobotsDisallowedError) Error() string {
+	return fmt.Sprintf("web_fetch blocked: robots.txt disallows fetching %s", e.URL)
+}
+
+// robotsRules 是 robots.txt 中适用于本工具的 Allow/Disallow 规则。
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+// allowed 按最长匹配原则判断路径是否允许抓取，长度相同时 Allow 优先。
+func (r *robotsRules) allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	longestAllow, longestDisallow := -1, -1
+	for _, prefix := range r.allow {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longestAllow {
+			longestAllow = len(prefix)
+		}
+	}
+	for _, prefix := range r.disallow {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longestDisallow {
+			longestDisallow = len(prefix)
+		}
+	}
+	return longestDisallow < 0 || longestAllow >= longestDisallow
+}
+
+// parseRobots 解析 robots.txt，优先使用针对 golem 的分组，否则使用 "*" 分组。
+// 不支持通配符与 $ 结尾匹配，规则按前缀处理。
+func parseRobots(r io.Reader) *robotsRules {
+	var golem, wildcard *robotsRules
+	var current []*robotsRules
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				current = nil
+				inRules = false
+			}
+			agent := strings.ToLower(value)
+			switch {
+			case agent == "*":
+				if wildcard == nil {
+					wildcard = &robotsRules{}
+				}
+				current = append(current, wildcard)
+			case strings.HasPrefix(agent, "golem"):
+				if golem == nil {
+					golem = &robotsRules{}
+				}
+				current = append(current, golem)
+			default:
+				current = append(current, nil)
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			for _, rules := range current {
+				if rules == nil {
+					continue
+				}
+				if key == "allow" {
+					rules.allow = append(rules.allow, value)
+				} else {
+					rules.disallow = append(rules.disallow, value)
+				}
+			}
+		}
+	}
+	if golem != nil {
+		return golem
+	}
+	return wildcard
+}
+
+type robotsEntry struct {
+	rules   *robotsRules
+	expires time.Time
+}
+
+// robotsChecker 按站点缓存 robots.txt 规则。
+type robotsChecker struct {
+	mu    sync.Mutex
+	hosts map[string]robotsEntry
+	now   func() time.Time
+}
+
+func newRobotsChecker() *robotsChecker {
+	return &robotsChecker{hosts: make(map[string]robotsEntry), now: time.Now}
+}
+
+// check 在 robots.txt 禁止抓取 target 时返回 *RobotsDisallowedError。
+// robots.txt 不存在或无法获取时视为允许。
+func (c *robotsChecker) check(ctx context.Context, client *http.Client, target *url.URL) error {
+	origin := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	entry, ok := c.hosts[origin]
+	c.mu.Unlock()
+	if !ok || !c.now().Before(entry.expires) {
+		entry = robotsEntry{rules: fetchRobots(ctx, client, origin), expires: c.now().Add(robotsCacheTTL)}
+		c.mu.Lock()
+		c.hosts[origin] = entry
+		c.mu.Unlock()
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	if !entry.rules.allowed(path) {
+		return &RobotsDisallowedError{URL: target.String()}
+	}
+	return nil
+}
+
+func fetchRobots(ctx context.Context, client *http.Client, origin string) *robotsRules {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("User-Agent", webFetchUserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	return parseRobots(io.LimitReader(resp.Body, maxRobotsBytes))
+}
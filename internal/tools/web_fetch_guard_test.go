@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPrivateIP(t *testing.T) {
+	private := []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"}
+	for _, raw := range private {
+		if !isPrivateIP(net.ParseIP(raw)) {
+			t.Fatalf("expected %s to be treated as private", raw)
+		}
+	}
+	public := []string{"8.8.8.8", "1.1.1.1", "2001:4860:4860::8888"}
+	for _, raw := range public {
+		if isPrivateIP(net.ParseIP(raw)) {
+			t.Fatalf("expected %s to be treated as public", raw)
+		}
+	}
+}
+
+func TestWebFetch_BlocksPrivateAddressesByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	fetchTool, err := NewWebFetchToolWithOptions(WebFetchOptions{})
+	if err != nil {
+		t.Fatalf("NewWebFetchToolWithOptions: %v", err)
+	}
+	_, err = fetchTool.InvokableRun(context.Background(), `{"url":"`+server.URL+`"}`)
+	if err == nil || !strings.Contains(err.Error(), "tools.web.allow_private") {
+		t.Fatalf("expected private address error, got %v", err)
+	}
+
+	allowed, err := NewWebFetchToolWithOptions(WebFetchOptions{AllowPrivate: true})
+	if err != nil {
+		t.Fatalf("NewWebFetchToolWithOptions: %v", err)
+	}
+	out, err := allowed.InvokableRun(context.Background(), `{"url":"`+server.URL+`"}`)
+	if err != nil || !strings.Contains(out, "internal") {
+		t.Fatalf("expected allow_private to permit fetch, got out=%q err=%v", out, err)
+	}
+}
+
+func TestGuardedFetchClient_RejectsPrivateDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	client := newFetchClient(5*time.Second, 0, true)
+	_, err := client.Get(server.URL)
+	var privateErr *PrivateAddressError
+	if !errors.As(err, &privateErr) {
+		t.Fatalf("expected dial to be rejected with PrivateAddressError, got %v", err)
+	}
+}
+
+func TestParseRobots_PrefersGolemGroup(t *testing.T) {
+	rules := parseRobots(strings.NewReader(`
+User-agent: *
+Disallow: /
+
+# golem may read docs only
+User-agent: golem
+Disallow: /docs/private
+Allow: /docs
+`))
+	if !rules.allowed("/docs/intro") {
+		t.Fatal("expected /docs/intro to be allowed for golem")
+	}
+	if rules.allowed("/docs/private/x") {
+		t.Fatal("expected longer disallow rule to win")
+	}
+
+	wildcard := parseRobots(strings.NewReader("User-agent: *\nDisallow: /admin\n"))
+	if wildcard.allowed("/admin/panel") || !wildcard.allowed("/public") {
+		t.Fatal("unexpected wildcard rule evaluation")
+	}
+}
+
+func TestWebFetch_HonorsRobotsTxt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\n"))
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	impl := &webFetchToolImpl{client: server.Client(), maxBytes: 1024, robots: newRobotsChecker()}
+
+	if _, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL + "/public"}); err != nil {
+		t.Fatalf("expected public path to be fetched, got %v", err)
+	}
+	_, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL + "/private/page"})
+	var robotsErr *RobotsDisallowedError
+	if !errors.As(err, &robotsErr) {
+		t.Fatalf("expected RobotsDisallowedError, got %v", err)
+	}
+}
+
+func TestWebFetch_ReportsFinalURLAndRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/b", http.StatusFound) })
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("landed")) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	impl := &webFetchToolImpl{client: newFetchClient(5*time.Second, 5, false), maxBytes: 1024}
+	out, err := impl.execute(context.Background(), &WebFetchInput{URL: server.URL + "/a"})
+	if err != nil {
+		t.Fatalf("web fetch error: %v", err)
+	}
+	if out.URL != server.URL+"/a" || out.FinalURL != server.URL+"/final" || out.Redirects != 2 {
+		t.Fatalf("unexpected redirect reporting: url=%s final=%s redirects=%d", out.URL, out.FinalURL, out.Redirects)
+	}
+
+	limited := &webFetchToolImpl{client: newFetchClient(5*time.Second, 1, false), maxBytes: 1024}
+	if _, err := limited.execute(context.Background(), &WebFetchInput{URL: server.URL + "/a"}); err == nil || !strings.Contains(err.Error(), "max_redirects") {
+		t.Fatalf("expected redirect limit error, got %v", err)
+	}
+}
+
+func TestFetchClient_RevalidatesRedirectTargets(t *testing.T) {
+	client := newFetchClient(5*time.Second, 0, true)
+	origin, _ := http.NewRequest(http.MethodGet, "https://example.com/start", nil)
+	next, _ := http.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data", nil)
+
+	err := client.CheckRedirect(next, []*http.Request{origin})
+	var privateErr *PrivateAddressError
+	if !errors.As(err, &privateErr) {
+		t.Fatalf("expected redirect into private network to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,480 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MEKXH/golem/internal/httpclient"
+	"github.com/cloudwego/eino/components/tool"
+)
+
+const (
+	defaultBraveSearchEndpoint  = "https://api.search.brave.com/res/v1/web/search"
+	defaultDuckSearchEndpoint   = "https://duckduckgo.com/html/"
+	defaultGoogleSearchEndpoint = "https://www.googleapis.com/customsearch/v1"
+	defaultDuckAttempts         = 3                      // DuckDuckGo 抓取的最大尝试次数
+	defaultDuckBackoff          = 500 * time.Millisecond // DuckDuckGo 重试的初始退避
+	maxGoogleSearchResults      = 10                     // Google Custom Search 单次请求的结果上限
+)
+
+// 支持的搜索服务名称。
+const (
+	searchProviderBrave      = "brave"
+	searchProviderDuckDuckGo = "duckduckgo"
+	searchProviderSearXNG    = "searxng"
+	searchProviderGoogle     = "google"
+)
+
+// WebSearchInput 定义了 web_search 工具的输入参数。
+type WebSearchInput struct {
+	Query      string `json:"query" jsonschema:"required,description=The search query"`
+	MaxResults int    `json:"max_results,omitempty" jsonschema:"description=Optional per-request result limit"`
+}
+
+// WebSearchResult 表示单条搜索结果。
+type WebSearchResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// WebSearchOutput 定义了 web_search 工具的执行结果。
+type WebSearchOutput struct {
+	Query   string            `json:"query"`
+	Results []WebSearchResult `json:"results"`
+}
+
+// searchProvider 是单个搜索服务的实现，所有实现返回统一的 WebSearchOutput。
+type searchProvider interface {
+	name() string
+	search(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error)
+}
+
+type webSearchToolImpl struct {
+	provider   searchProvider // 主搜索服务
+	fallback   searchProvider // 主服务失败时的兜底服务（可为空）
+	maxResults int
+	client     *http.Client
+}
+
+func (w *webSearchToolImpl) execute(ctx context.Context, input *WebSearchInput) (*WebSearchOutput, error) {
+	query := strings.TrimSpace(input.Query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	limit := resolveWebSearchLimit(input.MaxResults, w.maxResults)
+	out, err := w.provider.search(ctx, w.client, query, limit)
+	if err == nil || w.fallback == nil {
+		return out, err
+	}
+	slog.Debug("web search provider failed, falling back",
+		"provider", w.provider.name(), "fallback", w.fallback.name(), "error", err)
+	return w.fallback.search(ctx, w.client, query, limit)
+}
+
+func resolveWebSearchLimit(requested, defaultLimit int) int {
+	limit := requested
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+	if limit > maxWebSearchResults {
+		limit = maxWebSearchResults
+	}
+	return limit
+}
+
+// webSearchStatusError 表示搜索服务返回了非 200 状态码。
+type webSearchStatusError struct {
+	Backend string
+	Status  int
+	Body    string
+}
+
+func (e *webSearchStatusError) Error() string {
+	return fmt.Sprintf("%s search failed with status %d: %s", e.Backend, e.Status, e.Body)
+}
+
+// getSearchJSON 发送 GET 请求并将 JSON 响应解码到 out。
+func getSearchJSON(ctx context.Context, client *http.Client, provider, rawURL string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "golem-web-search/1.0")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &webSearchStatusError{Backend: provider, Status: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxWebFetchBytes)).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", provider, err)
+	}
+	return nil
+}
+
+// braveProvider 调用 Brave Search API。
+type braveProvider struct {
+	endpoint string
+	apiKey   string
+}
+
+func (p *braveProvider) name() string { return searchProviderBrave }
+
+func (p *braveProvider) search(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid search endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("count", fmt.Sprintf("%d", limit))
+	u.RawQuery = q.Encode()
+
+	var brave struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	header := http.Header{"X-Subscription-Token": []string{strings.TrimSpace(p.apiKey)}}
+	if err := getSearchJSON(ctx, client, p.name(), u.String(), header, &brave); err != nil {
+		return nil, err
+	}
+
+	out := &WebSearchOutput{
+		Query:   query,
+		Results: make([]WebSearchResult, 0, len(brave.Web.Results)),
+	}
+	for _, item := range brave.Web.Results {
+		out.Results = append(out.Results, WebSearchResult{
+			Title:       item.Title,
+			URL:         item.URL,
+			Description: item.Description,
+		})
+	}
+	return out, nil
+}
+
+// googleProvider 调用 Google Programmable Search（Custom Search JSON API）。
+type googleProvider struct {
+	endpoint string
+	apiKey   string
+	engineID string // 搜索引擎 ID（cx）
+}
+
+func (p *googleProvider) name() string { return searchProviderGoogle }
+
+func (p *googleProvider) search(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid google search endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("key", strings.TrimSpace(p.apiKey))
+	q.Set("cx", strings.TrimSpace(p.engineID))
+	q.Set("q", query)
+	q.Set("num", fmt.Sprintf("%d", min(limit, maxGoogleSearchResults)))
+	u.RawQuery = q.Encode()
+
+	var google struct {
+		Items []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"items"`
+	}
+	if err := getSearchJSON(ctx, client, p.name(), u.String(), nil, &google); err != nil {
+		return nil, err
+	}
+
+	out := &WebSearchOutput{
+		Query:   query,
+		Results: make([]WebSearchResult, 0, len(google.Items)),
+	}
+	for _, item := range google.Items {
+		out.Results = append(out.Results, WebSearchResult{
+			Title:       item.Title,
+			URL:         item.Link,
+			Description: item.Snippet,
+		})
+	}
+	return out, nil
+}
+
+// searxngProvider 调用自托管 SearXNG 实例的 JSON 接口（需在实例中启用 json 格式）。
+type searxngProvider struct {
+	endpoint string
+}
+
+func (p *searxngProvider) name() string { return searchProviderSearXNG }
+
+func (p *searxngProvider) search(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid searxng endpoint: %q", p.endpoint)
+	}
+	u = u.JoinPath("search")
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("format", "json")
+	u.RawQuery = q.Encode()
+
+	var searx struct {
+		Results []struct {
+			Title   string `json:"title"`
+			URL     string `json:"url"`
+			Content string `json:"content"`
+		} `json:"results"`
+	}
+	if err := getSearchJSON(ctx, client, p.name(), u.String(), nil, &searx); err != nil {
+		return nil, err
+	}
+
+	out := &WebSearchOutput{
+		Query:   query,
+		Results: make([]WebSearchResult, 0, min(limit, len(searx.Results))),
+	}
+	for _, item := range searx.Results {
+		if len(out.Results) >= limit {
+			break
+		}
+		out.Results = append(out.Results, WebSearchResult{
+			Title:       item.Title,
+			URL:         item.URL,
+			Description: item.Content,
+		})
+	}
+	return out, nil
+}
+
+// duckDuckGoProvider 抓取 DuckDuckGo HTML 搜索页，在限流（202/429/5xx）、网络错误
+// 或返回空结果时按指数退避重试。
+type duckDuckGoProvider struct {
+	endpoint string
+	attempts int
+	backoff  time.Duration
+}
+
+func (p *duckDuckGoProvider) name() string { return searchProviderDuckDuckGo }
+
+func (p *duckDuckGoProvider) search(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error) {
+	attempts := p.attempts
+	if attempts <= 0 {
+		attempts = 1
+	}
+	backoff := p.backoff
+	for attempt := 1; ; attempt++ {
+		out, err := p.searchOnce(ctx, client, query, limit)
+		if err == nil && len(out.Results) > 0 {
+			return out, nil
+		}
+		if attempt >= attempts || (err != nil && !isRetryableDuckError(ctx, err)) {
+			return out, err
+		}
+
+		reason := "empty results"
+		if err != nil {
+			reason = err.Error()
+		}
+		slog.Debug("duckduckgo search retrying", "attempt", attempt, "max_attempts", attempts, "reason", reason)
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+	}
+}
+
+func isRetryableDuckError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *webSearchStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status == http.StatusAccepted ||
+			statusErr.Status == http.StatusTooManyRequests ||
+			statusErr.Status >= 500
+	}
+	// 非状态码错误多为连接/超时问题，可以重试。
+	return true
+}
+
+func (p *duckDuckGoProvider) searchOnce(ctx context.Context, client *http.Client, query string, limit int) (*WebSearchOutput, error) {
+	u, err := url.Parse(p.endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duck search endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("q", query)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "golem-web-search/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &webSearchStatusError{Backend: p.name(), Status: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebFetchBytes))
+	if err != nil {
+		return nil, err
+	}
+	htmlBody := string(body)
+
+	matches := ddgResultLinkRe.FindAllStringSubmatch(htmlBody, limit)
+	results := make([]WebSearchResult, 0, len(matches))
+	for _, m := range matches {
+		if len(m) < 3 {
+			continue
+		}
+		rawURL := strings.TrimSpace(html.UnescapeString(m[1]))
+		title := strings.TrimSpace(htmlToText(html.UnescapeString(m[2])))
+		if rawURL == "" || title == "" {
+			continue
+		}
+		finalURL := decodeDuckRedirect(rawURL, u)
+		results = append(results, WebSearchResult{
+			Title:       title,
+			URL:         finalURL,
+			Description: "",
+		})
+	}
+
+	return &WebSearchOutput{
+		Query:   query,
+		Results: results,
+	}, nil
+}
+
+func decodeDuckRedirect(rawURL string, base *url.URL) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if parsed.IsAbs() {
+		return parsed.String()
+	}
+	if strings.HasPrefix(parsed.Path, "/l/") {
+		uddg := parsed.Query().Get("uddg")
+		if decoded, err := url.QueryUnescape(uddg); err == nil && strings.TrimSpace(decoded) != "" {
+			return decoded
+		}
+	}
+	if base != nil {
+		return base.ResolveReference(parsed).String()
+	}
+	return rawURL
+}
+
+// WebSearchOptions 定义了 web_search 工具的构造参数。
+type WebSearchOptions struct {
+	Provider   string // brave、duckduckgo、searxng、google；为空时有 APIKey 用 Brave，否则 DuckDuckGo
+	APIKey     string // Brave 或 Google 的 API key
+	EngineID   string // Google Programmable Search 的搜索引擎 ID（cx）
+	MaxResults int
+	SearXNGURL string // SearXNG 实例地址
+
+	// Endpoints 按服务名覆盖默认接口地址（主要用于测试）。
+	Endpoints map[string]string
+}
+
+func (o WebSearchOptions) endpoint(provider, fallback string) string {
+	if ep := strings.TrimSpace(o.Endpoints[provider]); ep != "" {
+		return ep
+	}
+	return fallback
+}
+
+// NewWebSearchTool 创建 web_search 工具实例，用于在互联网上搜索最新信息。
+func NewWebSearchTool(apiKey string, maxResults int) (tool.InvokableTool, error) {
+	return NewWebSearchToolWithOptions(WebSearchOptions{APIKey: apiKey, MaxResults: maxResults})
+}
+
+// NewWebSearchToolWithOptions 按指定搜索服务创建 web_search 工具实例。
+// Brave 与 Google 失败时回退到 DuckDuckGo；显式选择 DuckDuckGo 或 SearXNG 时不回退。
+func NewWebSearchToolWithOptions(opts WebSearchOptions) (tool.InvokableTool, error) {
+	maxResults := opts.MaxResults
+	if maxResults <= 0 {
+		maxResults = 5
+	}
+
+	duck := &duckDuckGoProvider{
+		endpoint: opts.endpoint(searchProviderDuckDuckGo, defaultDuckSearchEndpoint),
+		attempts: defaultDuckAttempts,
+		backoff:  defaultDuckBackoff,
+	}
+	impl := &webSearchToolImpl{
+		maxResults: maxResults,
+		client:     httpclient.New(httpclient.Timeout(defaultWebTimeout)),
+	}
+
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	if provider == "" && strings.TrimSpace(opts.APIKey) != "" {
+		provider = searchProviderBrave
+	}
+	switch provider {
+	case "", searchProviderDuckDuckGo:
+		impl.provider = duck
+	case searchProviderBrave:
+		impl.provider = &braveProvider{
+			endpoint: opts.endpoint(searchProviderBrave, defaultBraveSearchEndpoint),
+			apiKey:   opts.APIKey,
+		}
+		impl.fallback = duck
+	case searchProviderGoogle:
+		impl.provider = &googleProvider{
+			endpoint: opts.endpoint(searchProviderGoogle, defaultGoogleSearchEndpoint),
+			apiKey:   opts.APIKey,
+			engineID: opts.EngineID,
+		}
+		impl.fallback = duck
+	case searchProviderSearXNG:
+		impl.provider = &searxngProvider{endpoint: opts.endpoint(searchProviderSearXNG, strings.TrimSpace(opts.SearXNGURL))}
+	default:
+		return nil, fmt.Errorf("unknown web search provider %q", opts.Provider)
+	}
+	return inferStructuredTool("web_search", "Search the web for up-to-date information", impl.execute)
+}
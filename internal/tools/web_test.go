@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -23,11 +24,9 @@ func TestWebSearch_NoAPIKeyFallbackToDuckDuckGo(t *testing.T) {
 	defer server.Close()
 
 	impl := &webSearchToolImpl{
-		apiKey:        "",
-		maxResults:    5,
-		braveEndpoint: "https://brave.invalid/search",
-		duckEndpoint:  server.URL,
-		client:        server.Client(),
+		provider:   &duckDuckGoProvider{endpoint: server.URL},
+		maxResults: 5,
+		client:     server.Client(),
 	}
 
 	out, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem"})
@@ -65,11 +64,10 @@ func TestWebSearch_BraveSuccess(t *testing.T) {
 	defer server.Close()
 
 	impl := &webSearchToolImpl{
-		apiKey:        "test-key",
-		maxResults:    5,
-		braveEndpoint: server.URL,
-		duckEndpoint:  "https://duck.invalid/html/",
-		client:        server.Client(),
+		provider:   &braveProvider{endpoint: server.URL, apiKey: "test-key"},
+		fallback:   &duckDuckGoProvider{endpoint: "https://duck.invalid/html/"},
+		maxResults: 5,
+		client:     server.Client(),
 	}
 
 	out, err := impl.execute(context.Background(), &WebSearchInput{
@@ -106,11 +104,10 @@ func TestWebSearch_BraveFailureFallsBackToDuckDuckGo(t *testing.T) {
 	defer duck.Close()
 
 	impl := &webSearchToolImpl{
-		apiKey:        "test-key",
-		maxResults:    5,
-		braveEndpoint: brave.URL,
-		duckEndpoint:  duck.URL,
-		client:        &http.Client{Timeout: 5 * time.Second},
+		provider:   &braveProvider{endpoint: brave.URL, apiKey: "test-key"},
+		fallback:   &duckDuckGoProvider{endpoint: duck.URL},
+		maxResults: 5,
+		client:     &http.Client{Timeout: 5 * time.Second},
 	}
 
 	out, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem"})
@@ -125,6 +122,77 @@ func TestWebSearch_BraveFailureFallsBackToDuckDuckGo(t *testing.T) {
 	}
 }
 
+func TestWebSearch_GoogleProviderViaOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("key") != "g-key" || q.Get("cx") != "engine-1" || q.Get("q") != "golem" {
+			t.Fatalf("unexpected google query: %s", r.URL.RawQuery)
+		}
+		if q.Get("num") != "10" {
+			t.Fatalf("expected num capped at 10, got %s", q.Get("num"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"items":[{"title":"Golem","link":"https://example.com/g","snippet":"from google"}]}`))
+	}))
+	defer server.Close()
+
+	searchTool, err := NewWebSearchToolWithOptions(WebSearchOptions{
+		Provider:  "google",
+		APIKey:    "g-key",
+		EngineID:  "engine-1",
+		Endpoints: map[string]string{"google": server.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewWebSearchToolWithOptions: %v", err)
+	}
+
+	raw, err := searchTool.InvokableRun(context.Background(), `{"query":"golem","max_results":15}`)
+	if err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+	var out WebSearchOutput
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if out.Query != "golem" || len(out.Results) != 1 {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if out.Results[0].URL != "https://example.com/g" || out.Results[0].Description != "from google" {
+		t.Fatalf("unexpected result mapping: %+v", out.Results[0])
+	}
+}
+
+func TestWebSearch_GoogleFailureFallsBackToDuckDuckGo(t *testing.T) {
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"quota exceeded"}}`, http.StatusForbidden)
+	}))
+	defer google.Close()
+	duck := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<a class="result__a" href="https://example.com/duck">Duck</a>`))
+	}))
+	defer duck.Close()
+
+	impl := &webSearchToolImpl{
+		provider: &googleProvider{endpoint: google.URL, apiKey: "g-key", engineID: "engine-1"},
+		fallback: &duckDuckGoProvider{endpoint: duck.URL},
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+
+	out, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem"})
+	if err != nil {
+		t.Fatalf("web search fallback error: %v", err)
+	}
+	if len(out.Results) != 1 || out.Results[0].URL != "https://example.com/duck" {
+		t.Fatalf("unexpected fallback results: %+v", out.Results)
+	}
+}
+
+func TestNewWebSearchToolWithOptions_UnknownProvider(t *testing.T) {
+	if _, err := NewWebSearchToolWithOptions(WebSearchOptions{Provider: "altavista"}); err == nil {
+		t.Fatal("expected error for unknown provider")
+	}
+}
+
 func TestWebFetch_HTMLToText(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -172,3 +240,90 @@ func TestWebFetch_TruncatesLargeContent(t *testing.T) {
 		t.Fatalf("expected content length <= 64, got %d", len(out.Content))
 	}
 }
+
+func TestWebSearch_DuckDuckGoRetriesRateLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<a class="result__a" href="https://example.com/retry">Retry Result</a>`))
+	}))
+	defer server.Close()
+
+	impl := &webSearchToolImpl{
+		provider:   &duckDuckGoProvider{endpoint: server.URL, attempts: 3},
+		maxResults: 5,
+		client:     server.Client(),
+	}
+
+	out, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem"})
+	if err != nil {
+		t.Fatalf("web search error: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected one retry, got %d calls", calls)
+	}
+	if len(out.Results) != 1 || out.Results[0].URL != "https://example.com/retry" {
+		t.Fatalf("unexpected results: %+v", out.Results)
+	}
+}
+
+func TestWebSearch_DuckDuckGoDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	impl := &webSearchToolImpl{
+		provider: &duckDuckGoProvider{endpoint: server.URL, attempts: 3},
+		client:   server.Client(),
+	}
+
+	if _, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem"}); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if calls != 1 {
+		t.Fatalf("expected no retries for 400, got %d calls", calls)
+	}
+}
+
+func TestWebSearch_SearXNGBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/searx/search" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("q") != "golem" || r.URL.Query().Get("format") != "json" {
+			t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"results":[
+  {"title":"One","url":"https://example.com/1","content":"first"},
+  {"title":"Two","url":"https://example.com/2","content":"second"},
+  {"title":"Three","url":"https://example.com/3","content":"third"}
+]}`))
+	}))
+	defer server.Close()
+
+	impl := &webSearchToolImpl{
+		provider:   &searxngProvider{endpoint: server.URL + "/searx"},
+		maxResults: 5,
+		client:     server.Client(),
+	}
+
+	out, err := impl.execute(context.Background(), &WebSearchInput{Query: "golem", MaxResults: 2})
+	if err != nil {
+		t.Fatalf("searxng search error: %v", err)
+	}
+	if len(out.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(out.Results))
+	}
+	if out.Results[0].Description != "first" {
+		t.Fatalf("expected content to map to description, got %+v", out.Results[0])
+	}
+}
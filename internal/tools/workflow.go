@@ -9,16 +9,26 @@ import (
 	"github.com/cloudwego/eino/components/tool/utils"
 )
 
+// WorkflowStep 描述 dag 模式下的一个子任务及其依赖的前置步骤。
+type WorkflowStep struct {
+	ID        string   `json:"id" jsonschema:"required,description=Unique step id referenced by depends_on"`
+	Task      string   `json:"task" jsonschema:"required,description=Subtask instruction for this step"`
+	DependsOn []string `json:"depends_on,omitempty" jsonschema:"description=Ids of steps that must succeed before this step runs"`
+}
+
 // WorkflowRequest 描述结构化的子代理工作流运行请求。
 type WorkflowRequest struct {
-	Goal           string   // 整个工作流的最终目标
-	Mode           string   // 执行模式：sequential (顺序) 或 parallel (并行)
-	Subtasks       []string // 预定义的子任务列表
-	Label          string   // 用于追踪的工作流标签
-	OriginChannel  string   // 原始请求通道
-	OriginChatID   string   // 原始聊天 ID
-	OriginSenderID string   // 原始发送者 ID
-	RequestID      string   // 请求追踪 ID
+	Goal           string         // 整个工作流的最终目标
+	Mode           string         // 执行模式：sequential (顺序)、parallel (并行) 或 dag (依赖图)
+	Subtasks       []string       // 预定义的子任务列表
+	Steps          []WorkflowStep // dag 模式下带依赖关系的子任务，非空时忽略 Subtasks
+	PassOutputs    bool           // 是否将前序步骤输出注入后续步骤（sequential 或 dag 模式）
+	Label          string         // 用于追踪的工作流标签
+	OriginChannel  string         // 原始请求通道
+	OriginChatID   string         // 原始聊天 ID
+	OriginSenderID string         // 原始发送者 ID
+	RequestID      string         // 请求追踪 ID
+	Depth          int            // 工作流步骤子代理的嵌套深度，主代理直接发起时为 1
 }
 
 // WorkflowExecutor 定义了执行子代理工作流的接口。
@@ -29,10 +39,12 @@ type WorkflowExecutor interface {
 
 // WorkflowInput 定义了 workflow 工具的输入参数。
 type WorkflowInput struct {
-	Goal     string   `json:"goal" jsonschema:"required,description=Overall workflow goal for decomposition and execution"`
-	Mode     string   `json:"mode,omitempty" jsonschema:"description=Execution mode: sequential or parallel"`
-	Subtasks []string `json:"subtasks,omitempty" jsonschema:"description=Optional predefined subtasks"`
-	Label    string   `json:"label,omitempty" jsonschema:"description=Optional workflow label for tracking"`
+	Goal        string         `json:"goal" jsonschema:"required,description=Overall workflow goal for decomposition and execution"`
+	Mode        string         `json:"mode,omitempty" jsonschema:"description=Execution mode: sequential, parallel or dag (the default when steps are given)"`
+	Subtasks    []string       `json:"subtasks,omitempty" jsonschema:"description=Optional predefined subtasks"`
+	Label       string         `json:"label,omitempty" jsonschema:"description=Optional workflow label for tracking"`
+	Steps       []WorkflowStep `json:"steps,omitempty" jsonschema:"description=Optional subtasks with ids and depends_on for dag mode; independent steps run in parallel"`
+	PassOutputs bool           `json:"pass_outputs,omitempty" jsonschema:"description=Inject earlier step outputs into later steps (sequential or dag mode). Use {{step_N_output}} (sequential; N is 1-based) or {{step_<id>_output}} (dag) to place an output; without placeholders the outputs of earlier steps (dag: direct dependencies) are appended"`
 }
 
 type workflowToolImpl struct {
@@ -81,15 +93,29 @@ func buildWorkflowRequest(ctx context.Context, input *WorkflowInput) (WorkflowRe
 		}
 	}
 
+	steps := make([]WorkflowStep, 0, len(input.Steps))
+	for _, raw := range input.Steps {
+		step := WorkflowStep{ID: strings.TrimSpace(raw.ID), Task: strings.TrimSpace(raw.Task)}
+		for _, dep := range raw.DependsOn {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				step.DependsOn = append(step.DependsOn, dep)
+			}
+		}
+		steps = append(steps, step)
+	}
+
 	return WorkflowRequest{
 		Goal:           goal,
 		Mode:           strings.ToLower(strings.TrimSpace(input.Mode)),
 		Subtasks:       subtasks,
+		Steps:          steps,
+		PassOutputs:    input.PassOutputs,
 		Label:          strings.TrimSpace(input.Label),
 		OriginChannel:  channel,
 		OriginChatID:   chatID,
 		OriginSenderID: senderID,
 		RequestID:      strings.TrimSpace(meta.RequestID),
+		Depth:          SubagentDepthFromContext(ctx) + 1,
 	}, nil
 }
 
@@ -98,7 +124,11 @@ func NewWorkflowTool(executor WorkflowExecutor) (tool.InvokableTool, error) {
 	impl := &workflowToolImpl{executor: executor}
 	return utils.InferTool(
 		"workflow",
-		"Split a goal into subtasks, execute via subagents in sequential/parallel mode, and return aggregated results.",
+		"Split a goal into subtasks, execute via subagents in sequential/parallel mode, and return aggregated results. "+
+			"For dependent work, pass steps with id and depends_on (dag mode): each step starts once its dependencies succeed, independent steps run in parallel, and cycles are rejected. "+
+			"Set pass_outputs to build a pipeline: {{step_N_output}} (sequential, 1-based) or {{step_<id>_output}} (dag) in a task is replaced by that step's output; "+
+			"a placeholder may only name an earlier step (dag: a dependency). Without placeholders, earlier outputs are appended to the task. "+
+			"Each injected output is capped at 4000 characters and 12000 in total per step.",
 		impl.execute,
 	)
 }
@@ -67,3 +67,33 @@ func TestWorkflowTool_RequiresGoal(t *testing.T) {
 		t.Fatal("expected goal validation error")
 	}
 }
+
+func TestWorkflowTool_PassesDependencySteps(t *testing.T) {
+	exec := &fakeWorkflowExecutor{out: "workflow summary"}
+	tool, err := NewWorkflowTool(exec)
+	if err != nil {
+		t.Fatalf("NewWorkflowTool: %v", err)
+	}
+
+	_, err = tool.InvokableRun(context.Background(), `{"goal":"vendor review","steps":[`+
+		`{"id":" a ","task":" research a "},`+
+		`{"id":"b","task":"research b"},`+
+		`{"id":"c","task":"compare","depends_on":["a"," b ",""]}],"pass_outputs":true}`)
+	if err != nil {
+		t.Fatalf("InvokableRun: %v", err)
+	}
+
+	if !exec.lastReq.PassOutputs {
+		t.Fatalf("expected pass_outputs to be forwarded: %+v", exec.lastReq)
+	}
+	steps := exec.lastReq.Steps
+	if len(steps) != 3 {
+		t.Fatalf("expected three steps, got %+v", steps)
+	}
+	if steps[0].ID != "a" || steps[0].Task != "research a" {
+		t.Fatalf("expected trimmed step, got %+v", steps[0])
+	}
+	if got := steps[2].DependsOn; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("unexpected depends_on: %+v", got)
+	}
+}
@@ -0,0 +1,320 @@
+package voice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxSegmentDuration 是单段音频的时长上限：转录接口对单次请求的时长也有限制（约 25 分钟），
+// 超过该时长的音频即使体积未超限也会分段。
+const maxSegmentDuration = 10 * time.Minute
+
+// errUnsupportedFormat 表示无法识别音频容器，因而无法按时间分段。
+var errUnsupportedFormat = errors.New("unsupported audio container")
+
+// AudioInfo 是探测得到的音频容器信息。
+type AudioInfo struct {
+	Format   string // "wav"、"mp3" 或 "ogg"
+	Duration time.Duration
+}
+
+// audioUnit 是可独立切分的最小单元（WAV 采样块、MP3 帧、Ogg 页）。
+type audioUnit struct {
+	offset   int
+	length   int
+	duration time.Duration
+}
+
+// audioLayout 描述音频的切分方式：每段以 header 开头，后接连续的若干 unit。
+type audioLayout struct {
+	format string
+	header []byte
+	units  []audioUnit
+	// buildHeader 为包含 dataLen 字节 unit 数据的分段生成头部；为 nil 时原样复用 header。
+	buildHeader func(dataLen int) []byte
+}
+
+// Probe 识别音频容器并估算时长，支持 WAV（PCM）、MP3（Layer III）与 Ogg（Opus/Vorbis）。
+func Probe(data []byte) (AudioInfo, error) {
+	layout, err := parseAudio(data)
+	if err != nil {
+		return AudioInfo{}, err
+	}
+	return AudioInfo{Format: layout.format, Duration: layout.duration()}, nil
+}
+
+func parseAudio(data []byte) (*audioLayout, error) {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return parseWAV(data)
+	case len(data) >= 4 && string(data[0:4]) == "OggS":
+		return parseOgg(data)
+	case len(data) >= 3 && string(data[0:3]) == "ID3", len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return parseMP3(data)
+	default:
+		return nil, errUnsupportedFormat
+	}
+}
+
+func (l *audioLayout) duration() time.Duration {
+	var total time.Duration
+	for _, u := range l.units {
+		total += u.duration
+	}
+	return total
+}
+
+// splitAudio 将音频按时间切分为若干段，每段不超过 maxBytes 字节且不超过 maxDuration。
+func splitAudio(data []byte, maxBytes int, maxDuration time.Duration) ([][]byte, error) {
+	layout, err := parseAudio(data)
+	if err != nil {
+		return nil, err
+	}
+	budget := maxBytes - len(layout.header)
+	if budget <= 0 {
+		return nil, fmt.Errorf("%s header exceeds segment size", layout.format)
+	}
+
+	var segments [][]byte
+	start, size := 0, 0
+	var elapsed time.Duration
+	flush := func(end int) {
+		if end == start {
+			return
+		}
+		first, last := layout.units[start], layout.units[end-1]
+		payload := data[first.offset : last.offset+last.length]
+		header := layout.header
+		if layout.buildHeader != nil {
+			header = layout.buildHeader(len(payload))
+		}
+		seg := make([]byte, 0, len(header)+len(payload))
+		seg = append(append(seg, header...), payload...)
+		segments = append(segments, seg)
+		start, size, elapsed = end, 0, 0
+	}
+	for i, u := range layout.units {
+		if u.length > budget {
+			return nil, fmt.Errorf("%s frame of %d bytes exceeds segment size", layout.format, u.length)
+		}
+		if size+u.length > budget || (maxDuration > 0 && elapsed+u.duration > maxDuration) {
+			flush(i)
+		}
+		size += u.length
+		elapsed += u.duration
+	}
+	flush(len(layout.units))
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("%s contains no audio data", layout.format)
+	}
+	return segments, nil
+}
+
+// parseWAV 解析 PCM WAV：按 block 对齐切分 data 块，并为每段重写 RIFF 头。
+func parseWAV(data []byte) (*audioLayout, error) {
+	var fmtChunk []byte
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		body := off + 8
+		switch id {
+		case "fmt ":
+			if body+size > len(data) || size < 16 {
+				return nil, fmt.Errorf("wav: invalid fmt chunk")
+			}
+			fmtChunk = data[body : body+size]
+		case "data":
+			if fmtChunk == nil {
+				return nil, fmt.Errorf("wav: data chunk before fmt chunk")
+			}
+			if body+size > len(data) {
+				size = len(data) - body // 流式录音常见的未回填长度
+			}
+			return wavLayout(fmtChunk, body, size)
+		}
+		off = body + size + size%2
+	}
+	return nil, fmt.Errorf("wav: missing data chunk")
+}
+
+func wavLayout(fmtChunk []byte, dataOffset, dataLen int) (*audioLayout, error) {
+	byteRate := int(binary.LittleEndian.Uint32(fmtChunk[8:12]))
+	blockAlign := int(binary.LittleEndian.Uint16(fmtChunk[12:14]))
+	if byteRate <= 0 || blockAlign <= 0 {
+		return nil, fmt.Errorf("wav: invalid byte rate or block align")
+	}
+
+	// 每个 unit 约 100ms，保证切点落在采样块边界上。
+	unitLen := max(byteRate/10/blockAlign, 1) * blockAlign
+	units := make([]audioUnit, 0, dataLen/unitLen+1)
+	for off := 0; off+blockAlign <= dataLen; off += unitLen {
+		n := min(unitLen, dataLen-off)
+		n -= n % blockAlign
+		units = append(units, audioUnit{
+			offset:   dataOffset + off,
+			length:   n,
+			duration: time.Duration(n) * time.Second / time.Duration(byteRate),
+		})
+	}
+
+	buildHeader := func(n int) []byte {
+		var h bytes.Buffer
+		h.WriteString("RIFF")
+		_ = binary.Write(&h, binary.LittleEndian, uint32(4+8+len(fmtChunk)+len(fmtChunk)%2+8+n))
+		h.WriteString("WAVEfmt ")
+		_ = binary.Write(&h, binary.LittleEndian, uint32(len(fmtChunk)))
+		h.Write(fmtChunk)
+		if len(fmtChunk)%2 == 1 {
+			h.WriteByte(0)
+		}
+		h.WriteString("data")
+		_ = binary.Write(&h, binary.LittleEndian, uint32(n))
+		return h.Bytes()
+	}
+	return &audioLayout{format: "wav", header: buildHeader(0), units: units, buildHeader: buildHeader}, nil
+}
+
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Rates      = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// parseMP3 逐帧解析 MPEG Layer III 流，跳过 ID3v2 标签；每段直接由完整帧拼接而成。
+func parseMP3(data []byte) (*audioLayout, error) {
+	off := 0
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		off = 10 + size
+		if data[5]&0x10 != 0 {
+			off += 10 // footer
+		}
+	}
+
+	var units []audioUnit
+	for off+4 <= len(data) {
+		length, duration, ok := mp3Frame(data[off : off+4])
+		if !ok || off+length > len(data) {
+			if len(units) > 0 {
+				break // 尾部的 ID3v1 标签或截断的帧
+			}
+			off++ // 帧同步前的垃圾数据
+			continue
+		}
+		units = append(units, audioUnit{offset: off, length: length, duration: duration})
+		off += length
+	}
+	if len(units) == 0 {
+		return nil, fmt.Errorf("mp3: no MPEG Layer III frames found")
+	}
+	return &audioLayout{format: "mp3", units: units}, nil
+}
+
+// mp3Frame 解析 4 字节帧头，返回帧长与帧时长。
+func mp3Frame(h []byte) (length int, duration time.Duration, ok bool) {
+	if h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return 0, 0, false
+	}
+	version := (h[1] >> 3) & 0x03
+	layer := (h[1] >> 1) & 0x03
+	bitrateIdx := h[2] >> 4
+	rateIdx := (h[2] >> 2) & 0x03
+	padding := int((h[2] >> 1) & 0x01)
+	rates, known := mp3Rates[version]
+	if !known || layer != 1 || rateIdx == 3 {
+		return 0, 0, false
+	}
+
+	sampleRate := rates[rateIdx]
+	bitrate := mp3BitratesV1[bitrateIdx]
+	samples, coef := 1152, 144
+	if version != 3 {
+		bitrate = mp3BitratesV2[bitrateIdx]
+		samples, coef = 576, 72
+	}
+	if bitrate == 0 {
+		return 0, 0, false // 自由码率无法推算帧长
+	}
+	length = coef*bitrate*1000/sampleRate + padding
+	duration = time.Duration(samples) * time.Second / time.Duration(sampleRate)
+	return length, duration, true
+}
+
+// parseOgg 按页解析单一逻辑流的 Ogg Opus/Vorbis：granule 为 0 的前导页是编解码头，
+// 在每段开头重复；之后的音频页按 granule 位置计算时长。
+func parseOgg(data []byte) (*audioLayout, error) {
+	type page struct {
+		offset, length int
+		granule        int64
+		serial         uint32
+	}
+	var pages []page
+	for off := 0; off < len(data); {
+		if off+27 > len(data) || string(data[off:off+4]) != "OggS" {
+			return nil, fmt.Errorf("ogg: invalid page at offset %d", off)
+		}
+		nsegs := int(data[off+26])
+		if off+27+nsegs > len(data) {
+			return nil, fmt.Errorf("ogg: truncated page at offset %d", off)
+		}
+		length := 27 + nsegs
+		for _, s := range data[off+27 : off+27+nsegs] {
+			length += int(s)
+		}
+		if off+length > len(data) {
+			return nil, fmt.Errorf("ogg: truncated page at offset %d", off)
+		}
+		pages = append(pages, page{
+			offset:  off,
+			length:  length,
+			granule: int64(binary.LittleEndian.Uint64(data[off+6 : off+14])),
+			serial:  binary.LittleEndian.Uint32(data[off+14 : off+18]),
+		})
+		off += length
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("ogg: no pages")
+	}
+
+	first := pages[0]
+	payload := data[first.offset+27+int(data[first.offset+26]) : first.offset+first.length]
+	var rate int64
+	switch {
+	case bytes.HasPrefix(payload, []byte("OpusHead")):
+		rate = 48000 // Opus 的 granule 始终以 48kHz 计
+	case bytes.HasPrefix(payload, []byte("\x01vorbis")) && len(payload) >= 16:
+		rate = int64(binary.LittleEndian.Uint32(payload[12:16]))
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("ogg: unsupported codec: %w", errUnsupportedFormat)
+	}
+
+	headerEnd := 0
+	for headerEnd < len(pages) && pages[headerEnd].granule == 0 {
+		headerEnd++
+	}
+	if headerEnd == 0 {
+		return nil, fmt.Errorf("ogg: missing codec header page")
+	}
+	layout := &audioLayout{format: "ogg", header: data[:pages[headerEnd-1].offset+pages[headerEnd-1].length]}
+	prev := int64(0)
+	for _, p := range pages[headerEnd:] {
+		if p.serial != first.serial {
+			return nil, fmt.Errorf("ogg: multiplexed streams are not supported: %w", errUnsupportedFormat)
+		}
+		var d time.Duration
+		if p.granule > prev { // granule 为 -1 表示该页没有结束的包
+			d = time.Duration(p.granule-prev) * time.Second / time.Duration(rate)
+			prev = p.granule
+		}
+		layout.units = append(layout.units, audioUnit{offset: p.offset, length: p.length, duration: d})
+	}
+	return layout, nil
+}
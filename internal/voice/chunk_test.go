@@ -0,0 +1,200 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testWAV 生成 8kHz、16 位单声道、指定时长的 PCM WAV。
+func testWAV(d time.Duration) []byte {
+	const rate, blockAlign = 8000, 2
+	n := int(d.Seconds()*rate) * blockAlign
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+n))
+	b.WriteString("WAVEfmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))          // PCM
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))          // 声道数
+	_ = binary.Write(&b, binary.LittleEndian, uint32(rate))       // 采样率
+	_ = binary.Write(&b, binary.LittleEndian, uint32(rate*2))     // byte rate
+	_ = binary.Write(&b, binary.LittleEndian, uint16(blockAlign)) // block align
+	_ = binary.Write(&b, binary.LittleEndian, uint16(16))         // 位深
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(n))
+	b.Write(make([]byte, n))
+	return b.Bytes()
+}
+
+// testMP3 生成带 ID3v2 标签的 MPEG-1 Layer III（128kbps、44.1kHz）帧序列。
+func testMP3(frames int) []byte {
+	var b bytes.Buffer
+	b.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 5})
+	b.Write(make([]byte, 5))
+	for range frames {
+		frame := make([]byte, 417)
+		copy(frame, []byte{0xFF, 0xFB, 0x90, 0x00})
+		b.Write(frame)
+	}
+	b.WriteString("TAG")
+	b.Write(make([]byte, 125))
+	return b.Bytes()
+}
+
+func testOggPage(granule int64, seq uint32, payload []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("OggS")
+	b.Write([]byte{0, 0})
+	_ = binary.Write(&b, binary.LittleEndian, granule)
+	_ = binary.Write(&b, binary.LittleEndian, uint32(7)) // serial
+	_ = binary.Write(&b, binary.LittleEndian, seq)
+	b.Write(make([]byte, 4)) // CRC（解析时不校验）
+	var segs []byte
+	for rest := len(payload); ; rest -= 255 {
+		if rest < 255 {
+			segs = append(segs, byte(rest))
+			break
+		}
+		segs = append(segs, 255)
+	}
+	b.WriteByte(byte(len(segs)))
+	b.Write(segs)
+	b.Write(payload)
+	return b.Bytes()
+}
+
+// testOpus 生成头部两页加 pages 个各 1 秒的 Ogg Opus 音频页。
+func testOpus(pages int) []byte {
+	var b bytes.Buffer
+	b.Write(testOggPage(0, 0, append([]byte("OpusHead"), make([]byte, 11)...)))
+	b.Write(testOggPage(0, 1, []byte("OpusTags")))
+	for i := 1; i <= pages; i++ {
+		b.Write(testOggPage(int64(i)*48000, uint32(i+1), make([]byte, 100)))
+	}
+	return b.Bytes()
+}
+
+func TestProbe_DetectsFormatAndDuration(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   []byte
+		format string
+		want   time.Duration
+	}{
+		{"wav", testWAV(3 * time.Second), "wav", 3 * time.Second},
+		{"mp3", testMP3(100), "mp3", 100 * (1152 * time.Second / 44100)},
+		{"ogg", testOpus(5), "ogg", 5 * time.Second},
+	}
+	for _, tc := range cases {
+		info, err := Probe(tc.data)
+		if err != nil {
+			t.Fatalf("%s: Probe: %v", tc.name, err)
+		}
+		if info.Format != tc.format || info.Duration != tc.want {
+			t.Fatalf("%s: got %+v, want %s %v", tc.name, info, tc.format, tc.want)
+		}
+	}
+
+	if _, err := Probe([]byte("not audio at all")); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestSplitAudio_WAVSegmentsHaveValidHeaders(t *testing.T) {
+	data := testWAV(10 * time.Second) // 160000 字节 PCM
+	segments, err := splitAudio(data, 50000, 0)
+	if err != nil {
+		t.Fatalf("splitAudio: %v", err)
+	}
+	if len(segments) < 4 {
+		t.Fatalf("expected at least 4 segments, got %d", len(segments))
+	}
+	var total time.Duration
+	for i, seg := range segments {
+		if len(seg) > 50000 {
+			t.Fatalf("segment %d is %d bytes", i, len(seg))
+		}
+		info, err := Probe(seg)
+		if err != nil {
+			t.Fatalf("segment %d: Probe: %v", i, err)
+		}
+		if got := binary.LittleEndian.Uint32(seg[40:44]); int(got) != len(seg)-44 {
+			t.Fatalf("segment %d data size = %d, want %d", i, got, len(seg)-44)
+		}
+		total += info.Duration
+	}
+	if total != 10*time.Second {
+		t.Fatalf("segments cover %v, want 10s", total)
+	}
+}
+
+func TestSplitAudio_RespectsMaxDuration(t *testing.T) {
+	segments, err := splitAudio(testOpus(25), 1<<20, 10*time.Second)
+	if err != nil {
+		t.Fatalf("splitAudio: %v", err)
+	}
+	if len(segments) != 3 {
+		t.Fatalf("expected 3 segments, got %d", len(segments))
+	}
+	for i, seg := range segments {
+		if !bytes.Contains(seg[:100], []byte("OpusHead")) {
+			t.Fatalf("segment %d does not start with the Opus header", i)
+		}
+	}
+
+	segments, err = splitAudio(testMP3(1000), 417*300, 0)
+	if err != nil {
+		t.Fatalf("splitAudio mp3: %v", err)
+	}
+	if len(segments) != 4 {
+		t.Fatalf("expected 4 mp3 segments, got %d", len(segments))
+	}
+}
+
+func TestTranscribe_ChunksLargeAudio(t *testing.T) {
+	old := maxInputBytes
+	maxInputBytes = 50000
+	defer func() { maxInputBytes = old }()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if len(data) > 50000 {
+			t.Errorf("segment of %d bytes exceeds limit", len(data))
+		}
+		n := calls.Add(1)
+		_, _ = fmt.Fprintf(w, `{"text":"part%d"}`, n)
+	}))
+	defer srv.Close()
+
+	tr, err := NewOpenAITranscriber("k", srv.URL, "", 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewOpenAITranscriber: %v", err)
+	}
+	text, err := tr.Transcribe(context.Background(), Input{FileName: "long.wav", MIMEType: "audio/wav", Data: testWAV(10 * time.Second)})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if calls.Load() < 4 || !strings.HasPrefix(text, "part1 part2 part3 part4") {
+		t.Fatalf("unexpected result after %d calls: %q", calls.Load(), text)
+	}
+
+	_, err = tr.Transcribe(context.Background(), Input{FileName: "long.m4a", Data: make([]byte, 60000)})
+	if err == nil || !strings.Contains(err.Error(), "cannot be split") || !strings.Contains(err.Error(), "convert it to") {
+		t.Fatalf("expected actionable error, got %v", err)
+	}
+}
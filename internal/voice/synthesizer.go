@@ -0,0 +1,179 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MEKXH/golem/internal/httpclient"
+)
+
+const (
+	defaultSpeechModel = "gpt-4o-mini-tts"
+	defaultSpeechVoice = "alloy"
+	maxSpeechChars     = 4096             // OpenAI /audio/speech 单次输入的字符上限
+	maxSpeechBytes     = 25 * 1024 * 1024 // 合成音频的读取上限
+)
+
+var (
+	mdLinkRe   = regexp.MustCompile(`\[([^\]]+)\]\([^)]+\)`)
+	mdFenceRe  = regexp.MustCompile("(?s)```.*?```")
+	mdHeaderRe = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	mdBulletRe = regexp.MustCompile(`(?m)^\s*[-*+]\s+`)
+
+	mdMarkerReplacer = strings.NewReplacer("**", "", "__", "", "`", "", "~~", "")
+)
+
+// Speech 是一段合成好的音频。
+type Speech struct {
+	Data     []byte
+	FileName string
+	MIMEType string
+}
+
+// Synthesizer 将文本转换为语音。
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string) (Speech, error)
+}
+
+// SpeechOptions 描述语音合成客户端的构建参数，由 NewSynthesizer 按 Provider 分派。
+type SpeechOptions struct {
+	Provider string        // "openai"（默认）或 "local"
+	APIKey   string        // openai 必填；local 可选
+	BaseURL  string        // openai 可选；local 必填
+	Model    string        // 模型名，local 为空时不发送
+	Voice    string        // 音色，为空时使用 alloy
+	Timeout  time.Duration // 单次请求超时，<= 0 时使用默认值
+}
+
+// NewSynthesizer 按 Provider 构建语音合成客户端。local 需提供 OpenAI 兼容的 /audio/speech 接口。
+func NewSynthesizer(opts SpeechOptions) (Synthesizer, error) {
+	provider := strings.ToLower(strings.TrimSpace(opts.Provider))
+	baseURL := strings.TrimRight(strings.TrimSpace(opts.BaseURL), "/")
+	model := strings.TrimSpace(opts.Model)
+	apiKey := strings.TrimSpace(opts.APIKey)
+
+	switch provider {
+	case "", "openai":
+		if apiKey == "" {
+			return nil, fmt.Errorf("api key is required for speech synthesis")
+		}
+		if baseURL == "" {
+			baseURL = defaultBaseURL
+		}
+		if model == "" {
+			model = defaultSpeechModel
+		}
+	case "local":
+		if baseURL == "" {
+			return nil, fmt.Errorf("base_url is required for local speech synthesis")
+		}
+		// base_url 通常指向转录接口，合成接口位于同一 API 根路径下。
+		baseURL = strings.TrimSuffix(baseURL, "/audio/transcriptions")
+		if strings.HasSuffix(baseURL, "/inference") {
+			return nil, fmt.Errorf("local speech synthesis requires an OpenAI-compatible base_url, got %q", opts.BaseURL)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported speech synthesis provider: %q", opts.Provider)
+	}
+
+	endpoint := baseURL
+	if !strings.HasSuffix(endpoint, "/audio/speech") {
+		endpoint += "/audio/speech"
+	}
+	voiceName := strings.TrimSpace(opts.Voice)
+	if voiceName == "" {
+		voiceName = defaultSpeechVoice
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = httpclient.Timeout(defaultTimeout)
+	}
+
+	return &httpSynthesizer{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		model:    model,
+		voice:    voiceName,
+		client:   httpclient.New(timeout),
+	}, nil
+}
+
+// httpSynthesizer 调用 OpenAI 风格的 /audio/speech 接口，输出 Ogg/Opus 音频（Telegram 语音消息要求的格式）。
+type httpSynthesizer struct {
+	endpoint string
+	apiKey   string // 为空时不发送 Authorization 头
+	model    string // 为空时不发送 model 字段
+	voice    string
+	client   *http.Client
+}
+
+func (s *httpSynthesizer) Synthesize(ctx context.Context, text string) (Speech, error) {
+	text = SpeechText(text)
+	if text == "" {
+		return Speech{}, fmt.Errorf("speech text must not be empty")
+	}
+	if n := utf8.RuneCountInString(text); n > maxSpeechChars {
+		return Speech{}, fmt.Errorf("speech text too long: %d characters (max %d)", n, maxSpeechChars)
+	}
+
+	payload := map[string]string{
+		"input":           text,
+		"voice":           s.voice,
+		"response_format": "opus",
+	}
+	if s.model != "" {
+		payload["model"] = s.model
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Speech{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Speech{}, err
+	}
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Speech{}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxSpeechBytes+1))
+	if err != nil {
+		return Speech{}, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Speech{}, fmt.Errorf("speech request failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if len(raw) == 0 {
+		return Speech{}, fmt.Errorf("speech response returned no audio")
+	}
+	if len(raw) > maxSpeechBytes {
+		return Speech{}, fmt.Errorf("speech response too large (max %d bytes)", maxSpeechBytes)
+	}
+	return Speech{Data: raw, FileName: "reply.ogg", MIMEType: "audio/ogg"}, nil
+}
+
+// SpeechText 将 Markdown 回复整理为适合朗读的纯文本：去掉代码块、标题与列表标记和强调符号，链接只保留文字。
+func SpeechText(markdown string) string {
+	text := mdFenceRe.ReplaceAllString(markdown, "")
+	text = mdLinkRe.ReplaceAllString(text, "$1")
+	text = mdHeaderRe.ReplaceAllString(text, "")
+	text = mdBulletRe.ReplaceAllString(text, "")
+	text = mdMarkerReplacer.Replace(text)
+	return strings.TrimSpace(text)
+}
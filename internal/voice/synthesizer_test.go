@@ -0,0 +1,83 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAISynthesizer_SynthesizeSuccess(t *testing.T) {
+	var gotAuth, gotPath string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write([]byte("OggS-audio"))
+	}))
+	defer srv.Close()
+
+	synth, err := NewSynthesizer(SpeechOptions{APIKey: "k", BaseURL: srv.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("NewSynthesizer: %v", err)
+	}
+	speech, err := synth.Synthesize(context.Background(), "**Hello** [docs](https://example.com)")
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	if gotAuth != "Bearer k" || gotPath != "/v1/audio/speech" {
+		t.Fatalf("unexpected request: auth=%q path=%q", gotAuth, gotPath)
+	}
+	if gotBody["model"] != defaultSpeechModel || gotBody["voice"] != defaultSpeechVoice || gotBody["response_format"] != "opus" {
+		t.Fatalf("unexpected payload: %v", gotBody)
+	}
+	if gotBody["input"] != "Hello docs" {
+		t.Fatalf("input = %q, want markdown stripped", gotBody["input"])
+	}
+	if string(speech.Data) != "OggS-audio" || speech.FileName != "reply.ogg" || speech.MIMEType != "audio/ogg" {
+		t.Fatalf("unexpected speech: %+v", speech)
+	}
+}
+
+func TestSynthesizer_RejectsEmptyAndTooLongText(t *testing.T) {
+	synth, err := NewSynthesizer(SpeechOptions{APIKey: "k", BaseURL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("NewSynthesizer: %v", err)
+	}
+	if _, err := synth.Synthesize(context.Background(), "```go\nfmt.Println()\n```"); err == nil {
+		t.Fatal("expected error for text with nothing to speak")
+	}
+	if _, err := synth.Synthesize(context.Background(), strings.Repeat("a", maxSpeechChars+1)); err == nil || !strings.Contains(err.Error(), "too long") {
+		t.Fatalf("expected too long error, got %v", err)
+	}
+}
+
+func TestNewSynthesizer_LocalEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"http://localhost:8000/v1":                      "http://localhost:8000/v1/audio/speech",
+		"http://localhost:8000/v1/audio/transcriptions": "http://localhost:8000/v1/audio/speech",
+		"http://localhost:8880/v1/audio/speech":         "http://localhost:8880/v1/audio/speech",
+	}
+	for base, want := range cases {
+		synth, err := NewSynthesizer(SpeechOptions{Provider: "local", BaseURL: base})
+		if err != nil {
+			t.Fatalf("NewSynthesizer(%q): %v", base, err)
+		}
+		hs := synth.(*httpSynthesizer)
+		if hs.endpoint != want || hs.apiKey != "" || hs.model != "" {
+			t.Fatalf("base %q: endpoint=%q apiKey=%q model=%q", base, hs.endpoint, hs.apiKey, hs.model)
+		}
+	}
+	if _, err := NewSynthesizer(SpeechOptions{Provider: "local", BaseURL: "http://localhost:8080/inference"}); err == nil {
+		t.Fatal("expected error for whisper.cpp inference endpoint")
+	}
+	if _, err := NewSynthesizer(SpeechOptions{Provider: "openai"}); err == nil {
+		t.Fatal("expected error for missing api key")
+	}
+}
@@ -11,15 +11,22 @@ import (
 	"net/textproto"
 	"strings"
 	"time"
+
+	"github.com/MEKXH/golem/internal/httpclient"
 )
 
 const (
 	defaultModel   = "gpt-4o-mini-transcribe"
 	defaultBaseURL = "https://api.openai.com/v1"
 	defaultTimeout = 30 * time.Second
-	maxInputBytes  = 25 * 1024 * 1024
+
+	// MaxAudioBytes 是可转录音频的总大小上限，供通道限制下载大小。
+	MaxAudioBytes = 200 * 1024 * 1024
 )
 
+// maxInputBytes 是转录接口单次请求的音频上限，超出时分段转录；测试中可调小。
+var maxInputBytes = 25 * 1024 * 1024
+
 // Input 是一个要转录的音频负载。
 type Input struct {
 	FileName string
@@ -32,10 +39,33 @@ type Transcriber interface {
 	Transcribe(ctx context.Context, input Input) (string, error)
 }
 
-type openAITranscriber struct {
+// Options 描述转录客户端的构建参数，由 New 按 Provider 分派。
+type Options struct {
+	Provider string        // "openai"（默认）或 "local"
+	APIKey   string        // openai 必填；local 可选
+	BaseURL  string        // openai 可选；local 必填
+	Model    string        // 模型名，local 为空时不发送
+	Timeout  time.Duration // 单次请求超时，<= 0 时使用默认值
+}
+
+// New 按 Provider 构建转录客户端。
+func New(opts Options) (Transcriber, error) {
+	switch provider := strings.ToLower(strings.TrimSpace(opts.Provider)); provider {
+	case "", "openai":
+		return NewOpenAITranscriber(opts.APIKey, opts.BaseURL, opts.Model, opts.Timeout)
+	case "local":
+		return NewLocalTranscriber(opts.BaseURL, opts.APIKey, opts.Model, opts.Timeout)
+	default:
+		return nil, fmt.Errorf("unsupported voice transcription provider: %q", opts.Provider)
+	}
+}
+
+// httpTranscriber 通过 multipart 表单调用 OpenAI 风格的转录接口。
+type httpTranscriber struct {
 	endpoint string
-	apiKey   string
-	model    string
+	apiKey   string            // 为空时不发送 Authorization 头
+	model    string            // 为空时不发送 model 字段
+	fields   map[string]string // 额外的表单字段
 	client   *http.Client
 }
 
@@ -54,26 +84,88 @@ func NewOpenAITranscriber(apiKey, baseURL, model string, timeout time.Duration)
 		model = defaultModel
 	}
 	if timeout <= 0 {
-		timeout = defaultTimeout
+		timeout = httpclient.Timeout(defaultTimeout)
 	}
 
-	return &openAITranscriber{
+	return &httpTranscriber{
 		endpoint: strings.TrimRight(baseURL, "/") + "/audio/transcriptions",
 		apiKey:   apiKey,
 		model:    model,
-		client:   &http.Client{Timeout: timeout},
+		client:   httpclient.New(timeout),
+	}, nil
+}
+
+// NewLocalTranscriber 构建本地转录服务（whisper.cpp server、faster-whisper 等）的客户端。
+// baseURL 以 /inference 或 /audio/transcriptions 结尾时直接作为接口地址，否则追加 /audio/transcriptions。
+func NewLocalTranscriber(baseURL, apiKey, model string, timeout time.Duration) (Transcriber, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("base_url is required for local voice transcription")
+	}
+	endpoint := baseURL
+	if !strings.HasSuffix(baseURL, "/inference") && !strings.HasSuffix(baseURL, "/audio/transcriptions") {
+		endpoint = baseURL + "/audio/transcriptions"
+	}
+	if timeout <= 0 {
+		timeout = httpclient.Timeout(defaultTimeout)
+	}
+
+	return &httpTranscriber{
+		endpoint: endpoint,
+		apiKey:   strings.TrimSpace(apiKey),
+		model:    strings.TrimSpace(model),
+		fields:   map[string]string{"response_format": "json"},
+		client:   httpclient.New(timeout),
 	}, nil
 }
 
-func (t *openAITranscriber) Transcribe(ctx context.Context, input Input) (string, error) {
+// Transcribe 转录音频。超过单次请求上限或时长上限的 WAV、MP3、Ogg 音频会按时间分段依次转录，再拼接结果。
+func (t *httpTranscriber) Transcribe(ctx context.Context, input Input) (string, error) {
 	if len(input.Data) == 0 {
 		return "", fmt.Errorf("audio data must not be empty")
 	}
-	if len(input.Data) > maxInputBytes {
-		return "", fmt.Errorf("audio data too large: %d bytes (max %d)", len(input.Data), maxInputBytes)
+	if len(input.Data) > MaxAudioBytes {
+		return "", fmt.Errorf("audio data too large: %d bytes (max %d)", len(input.Data), MaxAudioBytes)
 	}
 
-	body, contentType, err := createMultipartForm(input, t.model)
+	segments, err := segmentAudio(input.Data)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) == 1 {
+		return t.transcribeOnce(ctx, input)
+	}
+
+	texts := make([]string, 0, len(segments))
+	for i, data := range segments {
+		text, err := t.transcribeOnce(ctx, Input{FileName: input.FileName, MIMEType: input.MIMEType, Data: data})
+		if err != nil {
+			return "", fmt.Errorf("transcribe segment %d/%d: %w", i+1, len(segments), err)
+		}
+		texts = append(texts, text)
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// segmentAudio 返回需要逐段转录的音频。未超过单次请求上限且时长不长（或格式无法识别）的音频原样返回；
+// 超过上限又无法分段时返回说明如何处理的错误。
+func segmentAudio(data []byte) ([][]byte, error) {
+	if len(data) <= maxInputBytes {
+		info, err := Probe(data)
+		if err != nil || info.Duration <= maxSegmentDuration {
+			return [][]byte{data}, nil
+		}
+	}
+	segments, err := splitAudio(data, maxInputBytes, maxSegmentDuration)
+	if err != nil {
+		return nil, fmt.Errorf("audio data too large: %d bytes (max %d per request) and cannot be split (%v); "+
+			"convert it to WAV, MP3 or Ogg Opus, or send a shorter recording", len(data), maxInputBytes, err)
+	}
+	return segments, nil
+}
+
+func (t *httpTranscriber) transcribeOnce(ctx context.Context, input Input) (string, error) {
+	body, contentType, err := createMultipartForm(input, t.model, t.fields)
 	if err != nil {
 		return "", err
 	}
@@ -82,7 +174,9 @@ func (t *openAITranscriber) Transcribe(ctx context.Context, input Input) (string
 	if err != nil {
 		return "", err
 	}
-	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
 	req.Header.Set("Content-Type", contentType)
 
 	resp, err := t.client.Do(req)
@@ -112,7 +206,7 @@ func (t *openAITranscriber) Transcribe(ctx context.Context, input Input) (string
 	return out.Text, nil
 }
 
-func createMultipartForm(input Input, model string) (*bytes.Buffer, string, error) {
+func createMultipartForm(input Input, model string, fields map[string]string) (*bytes.Buffer, string, error) {
 	fileName := strings.TrimSpace(input.FileName)
 	if fileName == "" {
 		fileName = "audio.bin"
@@ -135,8 +229,15 @@ func createMultipartForm(input Input, model string) (*bytes.Buffer, string, erro
 	if _, err := part.Write(input.Data); err != nil {
 		return nil, "", err
 	}
-	if err := writer.WriteField("model", strings.TrimSpace(model)); err != nil {
-		return nil, "", err
+	if model = strings.TrimSpace(model); model != "" {
+		if err := writer.WriteField("model", model); err != nil {
+			return nil, "", err
+		}
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, "", err
+		}
 	}
 	if err := writer.Close(); err != nil {
 		return nil, "", err
@@ -144,7 +144,7 @@ func TestCreateMultipartForm_IncludesAudioFile(t *testing.T) {
 		FileName: "audio.mp3",
 		MIMEType: "audio/mpeg",
 		Data:     []byte("abc"),
-	}, "model-x")
+	}, "model-x", nil)
 	if err != nil {
 		t.Fatalf("createMultipartForm error: %v", err)
 	}
@@ -161,3 +161,53 @@ func TestCreateMultipartForm_IncludesAudioFile(t *testing.T) {
 		t.Fatalf("expected first part file, got %q", part.FormName())
 	}
 }
+
+func TestLocalTranscriber_NoAuthAndInferenceEndpoint(t *testing.T) {
+	var gotPath, gotAuth, gotFormat string
+	var hasModel bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		if err := r.ParseMultipartForm(4 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		gotFormat = r.FormValue("response_format")
+		_, hasModel = r.MultipartForm.Value["model"]
+		_ = json.NewEncoder(w).Encode(map[string]any{"text": " local text "})
+	}))
+	defer srv.Close()
+
+	tr, err := New(Options{Provider: "local", BaseURL: srv.URL + "/inference"})
+	if err != nil {
+		t.Fatalf("New(local) error: %v", err)
+	}
+	text, err := tr.Transcribe(context.Background(), Input{FileName: "a.wav", Data: []byte("audio")})
+	if err != nil {
+		t.Fatalf("Transcribe error: %v", err)
+	}
+	if text != "local text" {
+		t.Fatalf("unexpected text %q", text)
+	}
+	if gotPath != "/inference" || gotAuth != "" || gotFormat != "json" || hasModel {
+		t.Fatalf("unexpected request: path=%q auth=%q format=%q model=%v", gotPath, gotAuth, gotFormat, hasModel)
+	}
+}
+
+func TestNew_DispatchesOverProvider(t *testing.T) {
+	if _, err := New(Options{Provider: "local"}); err == nil {
+		t.Fatal("expected error for local provider without base_url")
+	}
+	if _, err := New(Options{Provider: "unknown", APIKey: "k"}); err == nil {
+		t.Fatal("expected error for unsupported provider")
+	}
+	tr, err := New(Options{Provider: "local", BaseURL: "http://127.0.0.1:8000/v1/"})
+	if err != nil {
+		t.Fatalf("New(local) error: %v", err)
+	}
+	if got := tr.(*httpTranscriber).endpoint; got != "http://127.0.0.1:8000/v1/audio/transcriptions" {
+		t.Fatalf("unexpected endpoint %q", got)
+	}
+	if _, err := New(Options{APIKey: "k"}); err != nil {
+		t.Fatalf("expected default provider openai, got %v", err)
+	}
+}